@@ -0,0 +1,13 @@
+// Package api ships the OpenAPI document describing every HTTP route and
+// the protobuf contract of the notify endpoints, generated into notifypb
+package api
+
+//go:generate protoc --go_out=notifypb --go_opt=paths=source_relative notify.proto
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3.1 document served at /openapi.json; its schemas
+// are JSON Schema 2020-12, so they validate requests as they are
+//
+//go:embed openapi.json
+var OpenAPI []byte
@@ -0,0 +1,730 @@
+// Protobuf bodies of the notify endpoints, sent with
+// Content-Type: application/x-protobuf. Fields mean what their JSON
+// counterparts of the same name mean, see openapi.json, and are validated
+// by the same rules.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: notify.proto
+
+package notifypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// NotifyRequest is the body of POST /api/v1.0/recipient/{recipient}/notify
+// and POST /api/v1.0/recipient/{recipient}/notify/dry-run
+type NotifyRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	To        string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Title     string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Message   string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	ThreadKey string                 `protobuf:"bytes,5,opt,name=thread_key,json=threadKey,proto3" json:"thread_key,omitempty"`
+	// high, normal or low
+	Priority string `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	// transactional, marketing or reminder
+	Category    string            `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	Locale      string            `protobuf:"bytes,8,opt,name=locale,proto3" json:"locale,omitempty"`
+	TitleKey    string            `protobuf:"bytes,9,opt,name=title_key,json=titleKey,proto3" json:"title_key,omitempty"`
+	MessageKey  string            `protobuf:"bytes,10,opt,name=message_key,json=messageKey,proto3" json:"message_key,omitempty"`
+	Params      map[string]string `protobuf:"bytes,11,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Html        string            `protobuf:"bytes,12,opt,name=html,proto3" json:"html,omitempty"`
+	Attachments []*Attachment     `protobuf:"bytes,13,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	DeepLink    string            `protobuf:"bytes,14,opt,name=deep_link,json=deepLink,proto3" json:"deep_link,omitempty"`
+	ImageUrl    string            `protobuf:"bytes,15,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Metadata    map[string]string `protobuf:"bytes,16,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// admin template whose active version gives the title and message
+	Template      string `protobuf:"bytes,17,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyRequest) Reset() {
+	*x = NotifyRequest{}
+	mi := &file_notify_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyRequest) ProtoMessage() {}
+
+func (x *NotifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyRequest.ProtoReflect.Descriptor instead.
+func (*NotifyRequest) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NotifyRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetThreadKey() string {
+	if x != nil {
+		return x.ThreadKey
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetTitleKey() string {
+	if x != nil {
+		return x.TitleKey
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetMessageKey() string {
+	if x != nil {
+		return x.MessageKey
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetDeepLink() string {
+	if x != nil {
+		return x.DeepLink
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *NotifyRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+// Attachment carries either a URL or content, never both
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Content       []byte                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_notify_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Attachment) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// NotifyRequestV2 is the body of POST /api/v2.0/recipient/{recipient}/notify
+type NotifyRequestV2 struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	To        string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Email, PushNotification or InApp
+	Channels  []string `protobuf:"bytes,3,rep,name=channels,proto3" json:"channels,omitempty"`
+	ThreadKey string   `protobuf:"bytes,4,opt,name=thread_key,json=threadKey,proto3" json:"thread_key,omitempty"`
+	Priority  string   `protobuf:"bytes,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	Category  string   `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	// Exactly one of content and template gives the title and message
+	Content       *Content          `protobuf:"bytes,7,opt,name=content,proto3" json:"content,omitempty"`
+	Template      *Template         `protobuf:"bytes,8,opt,name=template,proto3" json:"template,omitempty"`
+	Rich          *Rich             `protobuf:"bytes,9,opt,name=rich,proto3" json:"rich,omitempty"`
+	Metadata      map[string]string `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyRequestV2) Reset() {
+	*x = NotifyRequestV2{}
+	mi := &file_notify_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyRequestV2) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyRequestV2) ProtoMessage() {}
+
+func (x *NotifyRequestV2) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyRequestV2.ProtoReflect.Descriptor instead.
+func (*NotifyRequestV2) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NotifyRequestV2) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *NotifyRequestV2) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *NotifyRequestV2) GetChannels() []string {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *NotifyRequestV2) GetThreadKey() string {
+	if x != nil {
+		return x.ThreadKey
+	}
+	return ""
+}
+
+func (x *NotifyRequestV2) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *NotifyRequestV2) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *NotifyRequestV2) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *NotifyRequestV2) GetTemplate() *Template {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+func (x *NotifyRequestV2) GetRich() *Rich {
+	if x != nil {
+		return x.Rich
+	}
+	return nil
+}
+
+func (x *NotifyRequestV2) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type Content struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Content) Reset() {
+	*x = Content{}
+	mi := &file_notify_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Content) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Content) ProtoMessage() {}
+
+func (x *Content) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Content.ProtoReflect.Descriptor instead.
+func (*Content) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Content) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Content) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Template names either an admin template or the translation keys
+type Template struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TitleKey      string                 `protobuf:"bytes,1,opt,name=title_key,json=titleKey,proto3" json:"title_key,omitempty"`
+	MessageKey    string                 `protobuf:"bytes,2,opt,name=message_key,json=messageKey,proto3" json:"message_key,omitempty"`
+	Locale        string                 `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
+	Params        map[string]string      `protobuf:"bytes,4,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Template) Reset() {
+	*x = Template{}
+	mi := &file_notify_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Template) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Template) ProtoMessage() {}
+
+func (x *Template) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Template.ProtoReflect.Descriptor instead.
+func (*Template) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Template) GetTitleKey() string {
+	if x != nil {
+		return x.TitleKey
+	}
+	return ""
+}
+
+func (x *Template) GetMessageKey() string {
+	if x != nil {
+		return x.MessageKey
+	}
+	return ""
+}
+
+func (x *Template) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *Template) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *Template) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Rich struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Html          string                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	Attachments   []*Attachment          `protobuf:"bytes,2,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	DeepLink      string                 `protobuf:"bytes,3,opt,name=deep_link,json=deepLink,proto3" json:"deep_link,omitempty"`
+	ImageUrl      string                 `protobuf:"bytes,4,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rich) Reset() {
+	*x = Rich{}
+	mi := &file_notify_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rich) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rich) ProtoMessage() {}
+
+func (x *Rich) ProtoReflect() protoreflect.Message {
+	mi := &file_notify_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rich.ProtoReflect.Descriptor instead.
+func (*Rich) Descriptor() ([]byte, []int) {
+	return file_notify_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Rich) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *Rich) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+func (x *Rich) GetDeepLink() string {
+	if x != nil {
+		return x.DeepLink
+	}
+	return ""
+}
+
+func (x *Rich) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+var File_notify_proto protoreflect.FileDescriptor
+
+const file_notify_proto_rawDesc = "" +
+	"\n" +
+	"\fnotify.proto\x12\x0fnotification.v1\"\xca\x05\n" +
+	"\rNotifyRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"thread_key\x18\x05 \x01(\tR\tthreadKey\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\tR\bpriority\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x16\n" +
+	"\x06locale\x18\b \x01(\tR\x06locale\x12\x1b\n" +
+	"\ttitle_key\x18\t \x01(\tR\btitleKey\x12\x1f\n" +
+	"\vmessage_key\x18\n" +
+	" \x01(\tR\n" +
+	"messageKey\x12B\n" +
+	"\x06params\x18\v \x03(\v2*.notification.v1.NotifyRequest.ParamsEntryR\x06params\x12\x12\n" +
+	"\x04html\x18\f \x01(\tR\x04html\x12=\n" +
+	"\vattachments\x18\r \x03(\v2\x1b.notification.v1.AttachmentR\vattachments\x12\x1b\n" +
+	"\tdeep_link\x18\x0e \x01(\tR\bdeepLink\x12\x1b\n" +
+	"\timage_url\x18\x0f \x01(\tR\bimageUrl\x12H\n" +
+	"\bmetadata\x18\x10 \x03(\v2,.notification.v1.NotifyRequest.MetadataEntryR\bmetadata\x12\x1a\n" +
+	"\btemplate\x18\x11 \x01(\tR\btemplate\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"w\n" +
+	"\n" +
+	"Attachment\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\fR\acontent\"\xd2\x03\n" +
+	"\x0fNotifyRequestV2\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x1a\n" +
+	"\bchannels\x18\x03 \x03(\tR\bchannels\x12\x1d\n" +
+	"\n" +
+	"thread_key\x18\x04 \x01(\tR\tthreadKey\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\tR\bpriority\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x122\n" +
+	"\acontent\x18\a \x01(\v2\x18.notification.v1.ContentR\acontent\x125\n" +
+	"\btemplate\x18\b \x01(\v2\x19.notification.v1.TemplateR\btemplate\x12)\n" +
+	"\x04rich\x18\t \x01(\v2\x15.notification.v1.RichR\x04rich\x12J\n" +
+	"\bmetadata\x18\n" +
+	" \x03(\v2..notification.v1.NotifyRequestV2.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"9\n" +
+	"\aContent\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xee\x01\n" +
+	"\bTemplate\x12\x1b\n" +
+	"\ttitle_key\x18\x01 \x01(\tR\btitleKey\x12\x1f\n" +
+	"\vmessage_key\x18\x02 \x01(\tR\n" +
+	"messageKey\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\x12=\n" +
+	"\x06params\x18\x04 \x03(\v2%.notification.v1.Template.ParamsEntryR\x06params\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x01\n" +
+	"\x04Rich\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\tR\x04html\x12=\n" +
+	"\vattachments\x18\x02 \x03(\v2\x1b.notification.v1.AttachmentR\vattachments\x12\x1b\n" +
+	"\tdeep_link\x18\x03 \x01(\tR\bdeepLink\x12\x1b\n" +
+	"\timage_url\x18\x04 \x01(\tR\bimageUrlBDZBgithub.com/koungkub/fw-challenge-notification-service/api/notifypbb\x06proto3"
+
+var (
+	file_notify_proto_rawDescOnce sync.Once
+	file_notify_proto_rawDescData []byte
+)
+
+func file_notify_proto_rawDescGZIP() []byte {
+	file_notify_proto_rawDescOnce.Do(func() {
+		file_notify_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_notify_proto_rawDesc), len(file_notify_proto_rawDesc)))
+	})
+	return file_notify_proto_rawDescData
+}
+
+var file_notify_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_notify_proto_goTypes = []any{
+	(*NotifyRequest)(nil),   // 0: notification.v1.NotifyRequest
+	(*Attachment)(nil),      // 1: notification.v1.Attachment
+	(*NotifyRequestV2)(nil), // 2: notification.v1.NotifyRequestV2
+	(*Content)(nil),         // 3: notification.v1.Content
+	(*Template)(nil),        // 4: notification.v1.Template
+	(*Rich)(nil),            // 5: notification.v1.Rich
+	nil,                     // 6: notification.v1.NotifyRequest.ParamsEntry
+	nil,                     // 7: notification.v1.NotifyRequest.MetadataEntry
+	nil,                     // 8: notification.v1.NotifyRequestV2.MetadataEntry
+	nil,                     // 9: notification.v1.Template.ParamsEntry
+}
+var file_notify_proto_depIdxs = []int32{
+	6, // 0: notification.v1.NotifyRequest.params:type_name -> notification.v1.NotifyRequest.ParamsEntry
+	1, // 1: notification.v1.NotifyRequest.attachments:type_name -> notification.v1.Attachment
+	7, // 2: notification.v1.NotifyRequest.metadata:type_name -> notification.v1.NotifyRequest.MetadataEntry
+	3, // 3: notification.v1.NotifyRequestV2.content:type_name -> notification.v1.Content
+	4, // 4: notification.v1.NotifyRequestV2.template:type_name -> notification.v1.Template
+	5, // 5: notification.v1.NotifyRequestV2.rich:type_name -> notification.v1.Rich
+	8, // 6: notification.v1.NotifyRequestV2.metadata:type_name -> notification.v1.NotifyRequestV2.MetadataEntry
+	9, // 7: notification.v1.Template.params:type_name -> notification.v1.Template.ParamsEntry
+	1, // 8: notification.v1.Rich.attachments:type_name -> notification.v1.Attachment
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_notify_proto_init() }
+func file_notify_proto_init() {
+	if File_notify_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_notify_proto_rawDesc), len(file_notify_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_notify_proto_goTypes,
+		DependencyIndexes: file_notify_proto_depIdxs,
+		MessageInfos:      file_notify_proto_msgTypes,
+	}.Build()
+	File_notify_proto = out.File
+	file_notify_proto_goTypes = nil
+	file_notify_proto_depIdxs = nil
+}
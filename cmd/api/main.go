@@ -1,34 +1,194 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/alert"
+	"github.com/koungkub/fw-challenge-notification-service/internal/analytics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/buildinfo"
 	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/config"
+	"github.com/koungkub/fw-challenge-notification-service/internal/digest"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
 	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/health"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ingest"
+	"github.com/koungkub/fw-challenge-notification-service/internal/inspect"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
 	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/preflight"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+	"github.com/koungkub/fw-challenge-notification-service/internal/realtime"
 	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/retry"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
 	"github.com/koungkub/fw-challenge-notification-service/internal/server"
 	"github.com/koungkub/fw-challenge-notification-service/internal/service"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
 func main() {
-	logger, _ := zap.NewProduction()
+	// LOG_LEVEL is applied once the settings are read, and again on reload
+	level := zap.NewAtomicLevel()
+	// An invalid sampling or redaction setting fails the configuration once
+	// it is read, so it is not reported twice
+	logConfig, _ := logging.NewConfig()
+	logger := newLogger(level, logConfig)
 	defer logger.Sync()
 
+	if len(os.Args) > 1 && (os.Args[1] == "preflight" || os.Args[1] == "--validate") {
+		os.Exit(runPreflight(logger))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		os.Exit(runInspect(logger))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		os.Exit(runReencrypt(logger))
+	}
+
+	logger.Info("starting notification service", buildinfo.Get().Fields()...)
+
 	fx.New(
 		fx.Provide(func() *zap.Logger { return logger }),
+		fx.Supply(level),
 		fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
 			return &fxevent.ZapLogger{Logger: log}
 		}),
+		config.Module,
 		metrics.Module,
 		server.Module,
 		handler.Module,
 		service.Module,
 		repository.Module,
 		client.Module,
+		idgen.Module,
+		clock.Module,
+		event.Module,
+		dispatch.Module,
+		ingest.Module,
+		secret.Module,
+		health.Module,
+		batch.Module,
+		realtime.Module,
+		digest.Module,
+		retry.Module,
+		quota.Module,
+		ratelimit.Module,
+		alert.Module,
+		errortracking.Module,
+		analytics.Module,
 		fx.Invoke(func(*server.HTTPServer) {}),
 	).Run()
 }
+
+func newLogger(level zap.AtomicLevel, logConfig logging.Config) *zap.Logger {
+	config := zap.NewProductionConfig()
+	config.Level = level
+	// Sampling is left to logging.NewCore, which spares warnings and errors
+	config.Sampling = nil
+
+	logger, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return logging.NewCore(core, logConfig)
+	}))
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger
+}
+
+// runPreflight validates config, cache, database, schema and providers
+// without starting the server, printing a JSON report with the effective
+// configuration and returning the exit code
+func runPreflight(logger *zap.Logger) int {
+	defer logger.Sync()
+
+	report := preflight.New(preflight.NewPreflightConfig(), logger).Run(context.Background())
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Error("failed to write preflight report", zap.Error(err))
+		return 1
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+// runInspect renders the admin API status of a running instance, refreshing
+// until interrupted when INSPECT_INTERVAL is set
+func runInspect(logger *zap.Logger) int {
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := inspect.New(inspect.NewInspectConfig()).Run(ctx, os.Stdout); err != nil {
+		logger.Error("failed to inspect service", zap.Error(err))
+		return 1
+	}
+	return 0
+}
+
+// runReencrypt rewrites every notification_preferences.secret_key with the
+// current SECRET_KEY_ENCRYPTION_KEY, encrypting plaintext rows and rows
+// written with a previous key
+func runReencrypt(logger *zap.Logger) int {
+	defer logger.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("invalid configuration", zap.Error(err))
+		return 1
+	}
+
+	secrets := secret.NewResolver(secret.ResolverParams{
+		Config: cfg.Secret,
+		Clock:  clock.NewRealClock(),
+		Logger: logger,
+	})
+	cipher, err := secret.NewCipher(secret.CipherParams{
+		Config:  cfg.Cipher,
+		Secrets: secrets,
+	})
+	if err != nil {
+		logger.Error("invalid encryption key", zap.Error(err))
+		return 1
+	}
+
+	conn, err := repository.Open(cfg.Persistent, secrets)
+	if err != nil {
+		logger.Error("failed to connect to database", zap.Error(err))
+		return 1
+	}
+	defer func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	updated, err := repository.ReencryptSecretKeys(context.Background(), conn, cipher)
+	if err != nil {
+		logger.Error("failed to re-encrypt secret keys", zap.Int("updated", updated), zap.Error(err))
+		return 1
+	}
+
+	logger.Info("secret keys re-encrypted", zap.Int("updated", updated))
+	return 0
+}
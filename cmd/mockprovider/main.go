@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/mockprovider"
+	"go.uber.org/zap"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// shutdownTimeout bounds how long in-flight slow requests may delay exit
+const shutdownTimeout = 5 * time.Second
+
+func main() {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	config := mockprovider.NewMockProviderConfig()
+	srv := &http.Server{
+		Addr:    config.Addr,
+		Handler: mockprovider.NewServer(config, clock.NewRealClock(), nil, logger),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("mock provider listening", zap.String("addr", config.Addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("mock provider stopped", zap.Error(err))
+		os.Exit(1)
+	}
+}
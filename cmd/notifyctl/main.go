@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/notifyctl"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run returns 2 for a command line notifyctl does not understand and 1 for
+// a command that failed
+func run() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := notifyctl.New(notifyctl.NewNotifyctlConfig()).Run(ctx, os.Args[1:], os.Stdout)
+	if err == nil {
+		return 0
+	}
+
+	fmt.Fprintln(os.Stderr, "notifyctl:", err)
+	if errors.Is(err, notifyctl.ErrUsage) {
+		fmt.Fprint(os.Stderr, "\n"+notifyctl.Usage)
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,278 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Webhook formats: the JSON of Alert, a Slack incoming webhook message or a
+// PagerDuty Events API v2 trigger
+const (
+	FormatJSON      = "json"
+	FormatSlack     = "slack"
+	FormatPagerDuty = "pagerduty"
+)
+
+const TypeChannelDown = "channel.down"
+
+// pagerDutyDedupKeyPrefix keys one incident per channel, however many
+// notifications fail during the outage
+const pagerDutyDedupKeyPrefix = "notification-channel-down-"
+
+var (
+	errUnknownFormat     = errors.New("alert webhook format: use json, slack or pagerduty")
+	errMissingRoutingKey = errors.New("alert webhook: pagerduty needs ALERT_PAGERDUTY_ROUTING_KEY")
+	errMissingWebhookURL = errors.New("alert webhook: ALERT_WEBHOOK_FORMAT is set without ALERT_WEBHOOK_URL")
+)
+
+var Module = fx.Module("alert",
+	fx.Provide(
+		fx.Annotate(
+			NewWebhookAlerter,
+			fx.As(new(Alerter)),
+		),
+	),
+)
+
+type AlertConfig struct {
+	// WebhookURL receives an alert when every provider of a channel failed;
+	// alerts are only logged when empty
+	WebhookURL     string        `envconfig:"ALERT_WEBHOOK_URL" secret:"true"`
+	WebhookFormat  string        `envconfig:"ALERT_WEBHOOK_FORMAT" default:"json"`
+	WebhookTimeout time.Duration `envconfig:"ALERT_WEBHOOK_TIMEOUT" default:"5s"`
+	// PagerDutyRoutingKey is the integration key of the PagerDuty service,
+	// required by the pagerduty format
+	PagerDutyRoutingKey string `envconfig:"ALERT_PAGERDUTY_ROUTING_KEY" secret:"true"`
+	// Cooldown is the least time between two webhook calls for one channel,
+	// so an outage pages once rather than once per notification
+	Cooldown time.Duration `envconfig:"ALERT_COOLDOWN" default:"5m"`
+}
+
+// Outage is a notification no provider of its channel accepted
+type Outage struct {
+	Channel        string
+	RecipientType  string
+	NotificationID string
+	// Providers is the number of providers tried
+	Providers int
+	Causes    []error
+	// Metadata is the caller metadata of the notification, only logged
+	Metadata map[string]string
+}
+
+// Alert is the body posted by the json format
+type Alert struct {
+	Type           string    `json:"type"`
+	Channel        string    `json:"channel"`
+	RecipientType  string    `json:"recipient_type"`
+	NotificationID string    `json:"notification_id"`
+	Providers      int       `json:"providers"`
+	Causes         []string  `json:"causes"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+//go:generate mockgen -package mockalert -destination ./mock/mockalert.go . Alerter
+type Alerter interface {
+	// ChannelDown reports that every provider of a channel failed; it
+	// never blocks on the webhook
+	ChannelDown(ctx context.Context, outage Outage)
+}
+
+var _ Alerter = (*WebhookAlerter)(nil)
+
+// WebhookAlerter logs every outage as critical and posts it to the alert
+// webhook in the background, at most once per Cooldown and channel
+type WebhookAlerter struct {
+	config     AlertConfig
+	httpclient *http.Client
+	clock      clock.Clock
+	logger     *zap.Logger
+	inflight   sync.WaitGroup
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+}
+
+type WebhookAlerterParams struct {
+	fx.In
+
+	Config AlertConfig
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+func NewWebhookAlerter(lc fx.Lifecycle, params WebhookAlerterParams) (*WebhookAlerter, error) {
+	switch params.Config.WebhookFormat {
+	case FormatJSON, FormatSlack:
+	case FormatPagerDuty:
+		if params.Config.PagerDutyRoutingKey == "" {
+			return nil, errMissingRoutingKey
+		}
+	default:
+		return nil, errUnknownFormat
+	}
+	if params.Config.WebhookURL == "" && params.Config.WebhookFormat != FormatJSON {
+		return nil, errMissingWebhookURL
+	}
+
+	alerter := &WebhookAlerter{
+		config: params.Config,
+		httpclient: &http.Client{
+			Timeout: params.Config.WebhookTimeout,
+		},
+		clock:    params.Clock,
+		logger:   params.Logger,
+		lastSent: make(map[string]time.Time),
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return alerter.wait(ctx)
+		},
+	})
+
+	return alerter, nil
+}
+
+func (a *WebhookAlerter) ChannelDown(ctx context.Context, outage Outage) {
+	alert := Alert{
+		Type:           TypeChannelDown,
+		Channel:        outage.Channel,
+		RecipientType:  outage.RecipientType,
+		NotificationID: outage.NotificationID,
+		Providers:      outage.Providers,
+		Causes:         make([]string, 0, len(outage.Causes)),
+		OccurredAt:     a.clock.Now().UTC(),
+	}
+	for _, cause := range outage.Causes {
+		alert.Causes = append(alert.Causes, cause.Error())
+	}
+
+	a.logger.Error("every provider of the channel failed",
+		zap.String("severity", "critical"),
+		zap.String("alert", TypeChannelDown),
+		zap.String("channel", alert.Channel),
+		zap.String("recipient_type", alert.RecipientType),
+		zap.String("notification_id", alert.NotificationID),
+		zap.Int("providers", alert.Providers),
+		zap.Errors("causes", outage.Causes),
+		zap.Any("metadata", outage.Metadata),
+	)
+
+	if a.config.WebhookURL == "" || !a.due(alert.Channel, alert.OccurredAt) {
+		return
+	}
+
+	body, err := a.encode(alert)
+	if err != nil {
+		a.logger.Error("failed to marshal alert",
+			zap.String("channel", alert.Channel),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Deliveries outlive the alerting call, so only the values of ctx are kept
+	ctx = context.WithoutCancel(ctx)
+	a.inflight.Add(1)
+	go func() {
+		defer a.inflight.Done()
+
+		if err := a.deliver(ctx, body); err != nil {
+			a.logger.Error("failed to deliver alert",
+				zap.String("channel", alert.Channel),
+				zap.String("format", a.config.WebhookFormat),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// due tells whether the cooldown of the channel has passed, and starts a
+// new one when it has
+func (a *WebhookAlerter) due(channel string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.lastSent[channel]; ok && now.Sub(last) < a.config.Cooldown {
+		return false
+	}
+	a.lastSent[channel] = now
+	return true
+}
+
+func (a *WebhookAlerter) encode(alert Alert) ([]byte, error) {
+	summary := fmt.Sprintf("All %d providers of the %s channel failed for %s notification %s",
+		alert.Providers, alert.Channel, alert.RecipientType, alert.NotificationID)
+
+	switch a.config.WebhookFormat {
+	case FormatSlack:
+		text := ":rotating_light: " + summary
+		if len(alert.Causes) > 0 {
+			text += ": " + strings.Join(alert.Causes, "; ")
+		}
+		return json.Marshal(map[string]string{"text": text})
+	case FormatPagerDuty:
+		return json.Marshal(map[string]any{
+			"routing_key":  a.config.PagerDutyRoutingKey,
+			"event_action": "trigger",
+			"dedup_key":    pagerDutyDedupKeyPrefix + alert.Channel,
+			"payload": map[string]any{
+				"summary":        summary,
+				"source":         "notification-service",
+				"severity":       "critical",
+				"timestamp":      alert.OccurredAt.Format(time.RFC3339),
+				"component":      alert.Channel,
+				"custom_details": alert,
+			},
+		})
+	default:
+		return json.Marshal(alert)
+	}
+}
+
+func (a *WebhookAlerter) deliver(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("alert webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// wait blocks until in-flight deliveries finish or ctx is done
+func (a *WebhookAlerter) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
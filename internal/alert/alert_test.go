@@ -0,0 +1,200 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var testOutage = Outage{
+	Channel:        "Email",
+	RecipientType:  "buyer",
+	NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+	Providers:      2,
+	Causes:         []error{errors.New("provider responded with status code 503")},
+	Metadata:       map[string]string{"order_id": "42"},
+}
+
+// newTestWebhook records the bodies posted to it
+func newTestWebhook(t *testing.T) (*httptest.Server, func() []map[string]any) {
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies
+	}
+}
+
+func TestNewWebhookAlerter(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        AlertConfig
+		expectedError error
+	}{
+		{"logs only without a webhook", AlertConfig{WebhookFormat: FormatJSON}, nil},
+		{"posts to slack", AlertConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X", WebhookFormat: FormatSlack}, nil},
+		{"rejects an unknown format", AlertConfig{WebhookURL: "https://alerts.example.com", WebhookFormat: "teams"}, errUnknownFormat},
+		{"requires the pagerduty routing key", AlertConfig{WebhookURL: "https://events.pagerduty.com/v2/enqueue", WebhookFormat: FormatPagerDuty}, errMissingRoutingKey},
+		{"requires a url for a chat format", AlertConfig{WebhookFormat: FormatSlack}, errMissingWebhookURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookAlerter(fxtest.NewLifecycle(t), WebhookAlerterParams{
+				Config: tt.config,
+				Logger: zap.NewNop(),
+			})
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+func TestWebhookAlerter_ChannelDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockClock := mockclock.NewMockClock(ctrl)
+	gomock.InOrder(
+		mockClock.EXPECT().Now().Return(now),
+		mockClock.EXPECT().Now().Return(now.Add(time.Minute)),
+		mockClock.EXPECT().Now().Return(now.Add(2*time.Minute)),
+		mockClock.EXPECT().Now().Return(now.Add(6*time.Minute)),
+	)
+
+	server, bodies := newTestWebhook(t)
+	core, logs := observer.New(zapcore.ErrorLevel)
+
+	lc := fxtest.NewLifecycle(t)
+	alerter, err := NewWebhookAlerter(lc, WebhookAlerterParams{
+		Config: AlertConfig{
+			WebhookURL:     server.URL,
+			WebhookFormat:  FormatJSON,
+			WebhookTimeout: time.Second,
+			Cooldown:       5 * time.Minute,
+		},
+		Clock:  mockClock,
+		Logger: zap.New(core),
+	})
+	require.NoError(t, err)
+	lc.RequireStart()
+
+	ctx := context.Background()
+	alerter.ChannelDown(ctx, testOutage)
+	alerter.ChannelDown(ctx, testOutage)
+	alerter.ChannelDown(ctx, Outage{Channel: "PushNotification", RecipientType: "seller", Providers: 1})
+	alerter.ChannelDown(ctx, testOutage)
+	lc.RequireStop()
+
+	assert.Equal(t, 4, logs.FilterMessage("every provider of the channel failed").Len(), "every outage is logged")
+	assert.Equal(t, "critical", logs.All()[0].ContextMap()["severity"])
+	assert.Equal(t, map[string]string{"order_id": "42"}, logs.All()[0].ContextMap()["metadata"])
+
+	received := bodies()
+	require.Len(t, received, 3, "the webhook is called once per cooldown and channel")
+	channels := map[string]int{}
+	for _, body := range received {
+		assert.Equal(t, TypeChannelDown, body["type"])
+		channels[body["channel"].(string)]++
+	}
+	assert.Equal(t, map[string]int{"Email": 2, "PushNotification": 1}, channels)
+}
+
+func TestWebhookAlerter_Formats(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   AlertConfig
+		expected map[string]any
+	}{
+		{
+			name:   "slack",
+			config: AlertConfig{WebhookFormat: FormatSlack},
+			expected: map[string]any{
+				"text": ":rotating_light: All 2 providers of the Email channel failed for buyer notification 01JB8Z5XK3M4N5P6Q7R8S9T0VW: provider responded with status code 503",
+			},
+		},
+		{
+			name:   "pagerduty",
+			config: AlertConfig{WebhookFormat: FormatPagerDuty, PagerDutyRoutingKey: "routing-key"},
+			expected: map[string]any{
+				"routing_key":  "routing-key",
+				"event_action": "trigger",
+				"dedup_key":    "notification-channel-down-Email",
+				"payload": map[string]any{
+					"summary":   "All 2 providers of the Email channel failed for buyer notification 01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					"source":    "notification-service",
+					"severity":  "critical",
+					"timestamp": "2025-06-01T12:00:00Z",
+					"component": "Email",
+					"custom_details": map[string]any{
+						"type":            TypeChannelDown,
+						"channel":         "Email",
+						"recipient_type":  "buyer",
+						"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+						"providers":       float64(2),
+						"causes":          []any{"provider responded with status code 503"},
+						"occurred_at":     "2025-06-01T12:00:00Z",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClock := mockclock.NewMockClock(ctrl)
+			mockClock.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+
+			server, bodies := newTestWebhook(t)
+			tt.config.WebhookURL = server.URL
+			tt.config.WebhookTimeout = time.Second
+
+			lc := fxtest.NewLifecycle(t)
+			alerter, err := NewWebhookAlerter(lc, WebhookAlerterParams{
+				Config: tt.config,
+				Clock:  mockClock,
+				Logger: zap.NewNop(),
+			})
+			require.NoError(t, err)
+			lc.RequireStart()
+
+			alerter.ChannelDown(context.Background(), testOutage)
+			lc.RequireStop()
+
+			require.Len(t, bodies(), 1)
+			assert.Equal(t, tt.expected, bodies()[0])
+		})
+	}
+}
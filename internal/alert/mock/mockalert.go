@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/alert (interfaces: Alerter)
+//
+// Generated by this command:
+//
+//	mockgen -package mockalert -destination ./mock/mockalert.go . Alerter
+//
+
+// Package mockalert is a generated GoMock package.
+package mockalert
+
+import (
+	context "context"
+	reflect "reflect"
+
+	alert "github.com/koungkub/fw-challenge-notification-service/internal/alert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAlerter is a mock of Alerter interface.
+type MockAlerter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlerterMockRecorder
+	isgomock struct{}
+}
+
+// MockAlerterMockRecorder is the mock recorder for MockAlerter.
+type MockAlerterMockRecorder struct {
+	mock *MockAlerter
+}
+
+// NewMockAlerter creates a new mock instance.
+func NewMockAlerter(ctrl *gomock.Controller) *MockAlerter {
+	mock := &MockAlerter{ctrl: ctrl}
+	mock.recorder = &MockAlerterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlerter) EXPECT() *MockAlerterMockRecorder {
+	return m.recorder
+}
+
+// ChannelDown mocks base method.
+func (m *MockAlerter) ChannelDown(ctx context.Context, outage alert.Outage) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ChannelDown", ctx, outage)
+}
+
+// ChannelDown indicates an expected call of ChannelDown.
+func (mr *MockAlerterMockRecorder) ChannelDown(ctx, outage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChannelDown", reflect.TypeOf((*MockAlerter)(nil).ChannelDown), ctx, outage)
+}
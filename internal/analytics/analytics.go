@@ -0,0 +1,297 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	SinkS3       = "s3"
+	SinkBigQuery = "bigquery"
+	SinkKafka    = "kafka"
+)
+
+var Module = fx.Module("analytics",
+	fx.Provide(
+		event.AsSubscriber(NewExporter),
+	),
+)
+
+type AnalyticsConfig struct {
+	// Sink is where events are exported: s3, bigquery or kafka; empty
+	// disables the export
+	Sink       string        `envconfig:"ANALYTICS_SINK"`
+	Interval   time.Duration `envconfig:"ANALYTICS_INTERVAL" default:"1m"`
+	BatchSize  int           `envconfig:"ANALYTICS_BATCH_SIZE" default:"500"`
+	BufferSize int           `envconfig:"ANALYTICS_BUFFER_SIZE" default:"10000"`
+	Timeout    time.Duration `envconfig:"ANALYTICS_TIMEOUT" default:"30s"`
+
+	S3Bucket   string `envconfig:"ANALYTICS_S3_BUCKET"`
+	S3Prefix   string `envconfig:"ANALYTICS_S3_PREFIX" default:"notification-events"`
+	S3Region   string `envconfig:"ANALYTICS_S3_REGION"`
+	S3Endpoint string `envconfig:"ANALYTICS_S3_ENDPOINT"`
+
+	BigQueryProject  string `envconfig:"ANALYTICS_BIGQUERY_PROJECT"`
+	BigQueryDataset  string `envconfig:"ANALYTICS_BIGQUERY_DATASET"`
+	BigQueryTable    string `envconfig:"ANALYTICS_BIGQUERY_TABLE"`
+	BigQueryEndpoint string `envconfig:"ANALYTICS_BIGQUERY_ENDPOINT" default:"https://bigquery.googleapis.com"`
+	BigQueryTokenURL string `envconfig:"ANALYTICS_BIGQUERY_TOKEN_URL" default:"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"`
+
+	KafkaRESTURL string `envconfig:"ANALYTICS_KAFKA_REST_URL"`
+	KafkaTopic   string `envconfig:"ANALYTICS_KAFKA_TOPIC"`
+}
+
+// Record is one exported lifecycle event of a notification
+type Record struct {
+	Type           string    `json:"type"`
+	NotificationID string    `json:"notification_id"`
+	RecipientType  string    `json:"recipient_type"`
+	Channels       []string  `json:"channels"`
+	Attempts       int       `json:"attempts"`
+	Error          string    `json:"error,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func newRecord(evt event.NotificationEvent) Record {
+	record := Record{
+		Type:           evt.Type,
+		NotificationID: evt.NotificationID,
+		RecipientType:  evt.RecipientType,
+		Channels:       evt.Channels,
+		Attempts:       evt.Attempts,
+		OccurredAt:     evt.OccurredAt.UTC(),
+	}
+	if record.Channels == nil {
+		record.Channels = []string{}
+	}
+	if evt.Err != nil {
+		record.Error = evt.Err.Error()
+	}
+	return record
+}
+
+// Sink ships one batch of records to the data warehouse
+type Sink interface {
+	Export(ctx context.Context, records []Record) error
+}
+
+var _ event.Subscriber = (*Exporter)(nil)
+
+// Exporter buffers lifecycle events in memory and ships them to the sink in
+// batches, every interval or as soon as a batch is full. A batch the sink
+// refuses is kept for the next flush; events arriving while the buffer is
+// full are dropped, as are those still buffered when the process dies
+type Exporter struct {
+	config AnalyticsConfig
+	sink   Sink
+	clock  clock.Clock
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	buffer  []Record
+	dropped int
+	full    chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type ExporterParams struct {
+	fx.In
+
+	Config      AnalyticsConfig
+	Clock       clock.Clock
+	IDGenerator idgen.Generator
+	Logger      *zap.Logger
+}
+
+// NewExporter builds the sink named by ANALYTICS_SINK and exports with the
+// application; without a sink it ignores every event
+func NewExporter(lc fx.Lifecycle, params ExporterParams) (*Exporter, error) {
+	if params.Config.Sink == "" {
+		return &Exporter{}, nil
+	}
+	if err := params.Config.validate(); err != nil {
+		return nil, err
+	}
+
+	httpclient := &http.Client{
+		Timeout: params.Config.Timeout,
+	}
+
+	var sink Sink
+	switch params.Config.Sink {
+	case SinkS3:
+		sink = NewS3Sink(params.Config, httpclient, params.Clock, params.IDGenerator)
+	case SinkBigQuery:
+		sink = NewBigQuerySink(params.Config, httpclient, params.Clock)
+	case SinkKafka:
+		sink = NewKafkaSink(params.Config, httpclient)
+	}
+
+	exporter := NewExporterWithSink(params.Config, sink, params.Clock, params.Logger)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			exporter.Start()
+			return nil
+		},
+		OnStop: exporter.Stop,
+	})
+
+	return exporter, nil
+}
+
+func NewExporterWithSink(config AnalyticsConfig, sink Sink, clock clock.Clock, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		config: config,
+		sink:   sink,
+		clock:  clock,
+		logger: logger,
+		full:   make(chan struct{}, 1),
+	}
+}
+
+func (c AnalyticsConfig) validate() error {
+	switch c.Sink {
+	case SinkS3:
+		if c.S3Bucket == "" {
+			return errors.New("analytics: ANALYTICS_S3_BUCKET is required by the s3 sink")
+		}
+	case SinkBigQuery:
+		if c.BigQueryProject == "" || c.BigQueryDataset == "" || c.BigQueryTable == "" {
+			return errors.New("analytics: ANALYTICS_BIGQUERY_PROJECT, ANALYTICS_BIGQUERY_DATASET and ANALYTICS_BIGQUERY_TABLE are required by the bigquery sink")
+		}
+	case SinkKafka:
+		if c.KafkaRESTURL == "" || c.KafkaTopic == "" {
+			return errors.New("analytics: ANALYTICS_KAFKA_REST_URL and ANALYTICS_KAFKA_TOPIC are required by the kafka sink")
+		}
+	default:
+		return fmt.Errorf("analytics: unknown sink '%s', use %s, %s or %s", c.Sink, SinkS3, SinkBigQuery, SinkKafka)
+	}
+
+	if c.Interval <= 0 {
+		return errors.New("analytics: ANALYTICS_INTERVAL must be positive")
+	}
+	if c.BatchSize < 1 || c.BufferSize < c.BatchSize {
+		return errors.New("analytics: ANALYTICS_BATCH_SIZE must be at least 1 and at most ANALYTICS_BUFFER_SIZE")
+	}
+	return nil
+}
+
+// HandleEvent buffers the event, waking the exporter once a batch is full
+func (e *Exporter) HandleEvent(_ context.Context, evt event.NotificationEvent) {
+	if e.sink == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.buffer) >= e.config.BufferSize {
+		e.dropped++
+		return
+	}
+	e.buffer = append(e.buffer, newRecord(evt))
+
+	if len(e.buffer) >= e.config.BatchSize {
+		select {
+		case e.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start flushes every interval, or once a batch is full, until Stop
+func (e *Exporter) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.clock.After(e.config.Interval):
+			case <-e.full:
+			}
+
+			e.Flush(ctx)
+		}
+	}()
+}
+
+// Stop ends the periodic flushes and ships what is still buffered, until
+// ctx ends
+func (e *Exporter) Stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	e.wg.Wait()
+
+	e.Flush(ctx)
+	return ctx.Err()
+}
+
+// Flush ships the buffered records batch by batch. The first batch the sink
+// refuses goes back to the front of the buffer with those after it
+func (e *Exporter) Flush(ctx context.Context) {
+	e.mu.Lock()
+	records := e.buffer
+	e.buffer = nil
+	dropped := e.dropped
+	e.dropped = 0
+	e.mu.Unlock()
+
+	if dropped > 0 {
+		e.logger.Warn("dropped analytics events, the export buffer was full",
+			zap.Int("dropped", dropped),
+			zap.Int("buffer_size", e.config.BufferSize),
+		)
+	}
+
+	for len(records) > 0 {
+		batch := records[:min(e.config.BatchSize, len(records))]
+
+		exportCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+		err := e.sink.Export(exportCtx, batch)
+		cancel()
+		if err != nil {
+			e.logger.Error("failed to export analytics events, retrying on the next flush",
+				zap.String("sink", e.config.Sink),
+				zap.Int("records", len(records)),
+				zap.Error(err),
+			)
+			e.requeue(records)
+			return
+		}
+
+		records = records[len(batch):]
+	}
+}
+
+// requeue puts unexported records back ahead of those buffered meanwhile,
+// dropping the newest beyond the buffer size
+func (e *Exporter) requeue(records []Record) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	buffer := append(records, e.buffer...)
+	if len(buffer) > e.config.BufferSize {
+		e.dropped += len(buffer) - e.config.BufferSize
+		buffer = buffer[:e.config.BufferSize]
+	}
+	e.buffer = buffer
+}
@@ -0,0 +1,189 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var testConfig = AnalyticsConfig{
+	Sink:       SinkKafka,
+	Interval:   time.Minute,
+	BatchSize:  2,
+	BufferSize: 4,
+	Timeout:    time.Second,
+}
+
+// fakeSink records the batches it is given and refuses the calls listed in
+// errs, in order
+type fakeSink struct {
+	batches [][]string
+	errs    []error
+}
+
+func (s *fakeSink) Export(_ context.Context, records []Record) error {
+	s.batches = append(s.batches, ids(records))
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func sentEvent(id string) event.NotificationEvent {
+	return event.NotificationEvent{
+		Type:           event.TypeNotificationSent,
+		NotificationID: id,
+		RecipientType:  "seller",
+		Channels:       []string{"Email"},
+		Attempts:       1,
+		OccurredAt:     time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func ids(records []Record) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.NotificationID
+	}
+	return ids
+}
+
+func TestExporter_Flush(t *testing.T) {
+	t.Run("ships the buffer batch by batch", func(t *testing.T) {
+		sink := &fakeSink{}
+
+		exporter := NewExporterWithSink(testConfig, sink, clock.NewRealClock(), zap.NewNop())
+		for _, id := range []string{"a", "b", "c"} {
+			exporter.HandleEvent(context.Background(), sentEvent(id))
+		}
+		exporter.Flush(context.Background())
+
+		assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, sink.batches)
+		assert.Empty(t, exporter.buffer)
+	})
+
+	t.Run("keeps a refused batch and those after it for the next flush", func(t *testing.T) {
+		sink := &fakeSink{errs: []error{nil, errors.New("warehouse down")}}
+
+		exporter := NewExporterWithSink(testConfig, sink, clock.NewRealClock(), zap.NewNop())
+		for _, id := range []string{"a", "b", "c", "d"} {
+			exporter.HandleEvent(context.Background(), sentEvent(id))
+		}
+		exporter.Flush(context.Background())
+
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, sink.batches)
+		assert.Equal(t, []string{"c", "d"}, ids(exporter.buffer))
+	})
+
+	t.Run("drops events beyond the buffer and logs them on the next flush", func(t *testing.T) {
+		sink := &fakeSink{}
+		core, logs := observer.New(zap.WarnLevel)
+
+		exporter := NewExporterWithSink(testConfig, sink, clock.NewRealClock(), zap.New(core))
+		for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+			exporter.HandleEvent(context.Background(), sentEvent(id))
+		}
+		exporter.Flush(context.Background())
+
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, sink.batches)
+		require.Equal(t, 1, logs.FilterMessage("dropped analytics events, the export buffer was full").Len())
+		assert.Equal(t, int64(2), logs.All()[0].ContextMap()["dropped"])
+	})
+}
+
+func TestExporter_HandleEvent(t *testing.T) {
+	t.Run("wakes the exporter once a batch is full", func(t *testing.T) {
+		exporter := NewExporterWithSink(testConfig, &fakeSink{}, clock.NewRealClock(), zap.NewNop())
+
+		exporter.HandleEvent(context.Background(), sentEvent("a"))
+		assert.Empty(t, exporter.full)
+
+		exporter.HandleEvent(context.Background(), sentEvent("b"))
+		assert.Len(t, exporter.full, 1)
+	})
+
+	t.Run("records the event as exported", func(t *testing.T) {
+		exporter := NewExporterWithSink(testConfig, &fakeSink{}, clock.NewRealClock(), zap.NewNop())
+
+		evt := sentEvent("a")
+		evt.Type = event.TypeNotificationFailed
+		evt.Err = errors.New("provider down")
+		exporter.HandleEvent(context.Background(), evt)
+
+		assert.Equal(t, []Record{{
+			Type:           event.TypeNotificationFailed,
+			NotificationID: "a",
+			RecipientType:  "seller",
+			Channels:       []string{"Email"},
+			Attempts:       1,
+			Error:          "provider down",
+			OccurredAt:     time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		}}, exporter.buffer)
+	})
+
+	t.Run("ignores events without a sink", func(t *testing.T) {
+		exporter, err := NewExporter(fxtest.NewLifecycle(t), ExporterParams{Config: AnalyticsConfig{}})
+		require.NoError(t, err)
+
+		assert.NotPanics(t, func() {
+			exporter.HandleEvent(context.Background(), sentEvent("a"))
+		})
+	})
+}
+
+func TestExporter_Stop(t *testing.T) {
+	sink := &fakeSink{}
+
+	exporter := NewExporterWithSink(testConfig, sink, clock.NewRealClock(), zap.NewNop())
+	exporter.Start()
+	exporter.HandleEvent(context.Background(), sentEvent("a"))
+
+	require.NoError(t, exporter.Stop(context.Background()))
+	assert.Equal(t, [][]string{{"a"}}, sink.batches)
+}
+
+func TestAnalyticsConfig_validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*AnalyticsConfig)
+		expectError bool
+	}{
+		{name: "accepts a kafka sink", modify: func(c *AnalyticsConfig) {
+			c.KafkaRESTURL, c.KafkaTopic = "http://kafka-rest:8082", "notification-events"
+		}},
+		{name: "accepts an s3 sink", modify: func(c *AnalyticsConfig) { c.Sink, c.S3Bucket = SinkS3, "analytics" }},
+		{name: "rejects an unknown sink", modify: func(c *AnalyticsConfig) { c.Sink = "redshift" }, expectError: true},
+		{name: "rejects a kafka sink without topic", modify: func(c *AnalyticsConfig) { c.KafkaRESTURL = "http://kafka-rest:8082" }, expectError: true},
+		{name: "rejects a bigquery sink without table", modify: func(c *AnalyticsConfig) {
+			c.Sink, c.BigQueryProject, c.BigQueryDataset = SinkBigQuery, "acme", "events"
+		}, expectError: true},
+		{name: "rejects a batch larger than the buffer", modify: func(c *AnalyticsConfig) { c.Sink, c.S3Bucket, c.BatchSize = SinkS3, "analytics", 5 }, expectError: true},
+		{name: "rejects an interval of zero", modify: func(c *AnalyticsConfig) { c.Sink, c.S3Bucket, c.Interval = SinkS3, "analytics", 0 }, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			tt.modify(&config)
+
+			err := config.validate()
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
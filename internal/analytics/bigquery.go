@@ -0,0 +1,155 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+)
+
+var _ Sink = (*BigQuerySink)(nil)
+
+// BigQuerySink streams records into a table with the insertAll API,
+// authenticating with an access token of the instance service account
+// from the GCP metadata server. Insert IDs let BigQuery drop the rows of a
+// batch sent twice
+type BigQuerySink struct {
+	target     string
+	tokenURL   string
+	httpclient *http.Client
+	clock      clock.Clock
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewBigQuerySink(config AnalyticsConfig, httpclient *http.Client, clock clock.Clock) *BigQuerySink {
+	return &BigQuerySink{
+		target: fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+			strings.TrimRight(config.BigQueryEndpoint, "/"),
+			url.PathEscape(config.BigQueryProject),
+			url.PathEscape(config.BigQueryDataset),
+			url.PathEscape(config.BigQueryTable),
+		),
+		tokenURL:   config.BigQueryTokenURL,
+		httpclient: httpclient,
+		clock:      clock,
+	}
+}
+
+type bigQueryRow struct {
+	InsertID string `json:"insertId"`
+	JSON     Record `json:"json"`
+}
+
+type bigQueryResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+func (s *BigQuerySink) Export(ctx context.Context, records []Record) error {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	insert := struct {
+		Rows []bigQueryRow `json:"rows"`
+	}{Rows: make([]bigQueryRow, len(records))}
+	for i, record := range records {
+		insertID := fmt.Sprintf("%s-%s-%d", record.NotificationID, record.Type, record.OccurredAt.UnixNano())
+		insert.Rows[i] = bigQueryRow{InsertID: insertID, JSON: record}
+	}
+
+	payload, err := json.Marshal(insert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("bigquery returned status %d: %s", resp.StatusCode, body)
+	}
+
+	// Rows are rejected individually with 200; the whole batch is resent,
+	// the insert IDs keeping the accepted rows from being duplicated
+	var inserted bigQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&inserted); err != nil {
+		return fmt.Errorf("bigquery response: %w", err)
+	}
+	if len(inserted.InsertErrors) > 0 {
+		first := inserted.InsertErrors[0]
+		reason := ""
+		if len(first.Errors) > 0 {
+			reason = first.Errors[0].Reason + ": " + first.Errors[0].Message
+		}
+		return fmt.Errorf("bigquery rejected %d rows, row %d: %s", len(inserted.InsertErrors), first.Index, reason)
+	}
+	return nil
+}
+
+// accessToken returns the cached token of the metadata server, fetching a
+// new one a minute before it expires
+func (s *BigQuerySink) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.clock.Now().Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.httpclient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("bigquery access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bigquery access token: metadata server returned status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("bigquery access token: %w", err)
+	}
+
+	s.token = token.AccessToken
+	s.expiresAt = s.clock.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBigQuerySink_Export(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedError string
+	}{
+		{
+			name:   "streams every record with an insert id",
+			status: http.StatusOK,
+			body:   `{"kind":"bigquery#tableDataInsertAllResponse"}`,
+		},
+		{
+			name:          "fails when rows were rejected",
+			status:        http.StatusOK,
+			body:          `{"insertErrors":[{"index":1,"errors":[{"reason":"invalid","message":"no such field: channel"}]}]}`,
+			expectedError: "bigquery rejected 1 rows, row 1: invalid: no such field: channel",
+		},
+		{
+			name:          "fails on an API error",
+			status:        http.StatusNotFound,
+			body:          `{"error":{"code":404,"message":"Not found: Table acme:events.notifications"}}`,
+			expectedError: `bigquery returned status 404: {"error":{"code":404,"message":"Not found: Table acme:events.notifications"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var tokenRequests int
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+				tokenRequests++
+				assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+				_, _ = io.WriteString(w, `{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`)
+			})
+			mux.HandleFunc("POST /bigquery/v2/projects/acme/datasets/events/tables/notifications/insertAll", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+
+				var insert struct {
+					Rows []struct {
+						InsertID string `json:"insertId"`
+						JSON     Record `json:"json"`
+					} `json:"rows"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&insert))
+				if assert.Len(t, insert.Rows, 2) {
+					assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW-notification.sent-1748779200000000000", insert.Rows[0].InsertID)
+					assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW", insert.Rows[0].JSON.NotificationID)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			clk := mockclock.NewMockClock(ctrl)
+			clk.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).AnyTimes()
+
+			sink := NewBigQuerySink(AnalyticsConfig{
+				BigQueryProject:  "acme",
+				BigQueryDataset:  "events",
+				BigQueryTable:    "notifications",
+				BigQueryEndpoint: server.URL,
+				BigQueryTokenURL: server.URL + "/token",
+			}, server.Client(), clk)
+
+			records := []Record{
+				newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VW")),
+				newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VX")),
+			}
+			err := sink.Export(context.Background(), records)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, sink.Export(context.Background(), records))
+			assert.Equal(t, 1, tokenRequests, "the token is reused until it expires")
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var _ Sink = (*KafkaSink)(nil)
+
+// KafkaSink produces each record to a topic through a Kafka REST Proxy (v2
+// API), keyed by notification ID so the events of a notification share a
+// partition
+type KafkaSink struct {
+	target     string
+	httpclient *http.Client
+}
+
+func NewKafkaSink(config AnalyticsConfig, httpclient *http.Client) *KafkaSink {
+	return &KafkaSink{
+		target:     strings.TrimRight(config.KafkaRESTURL, "/") + "/topics/" + url.PathEscape(config.KafkaTopic),
+		httpclient: httpclient,
+	}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Record `json:"value"`
+}
+
+type kafkaResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (s *KafkaSink) Export(ctx context.Context, records []Record) error {
+	produce := struct {
+		Records []kafkaRecord `json:"records"`
+	}{Records: make([]kafkaRecord, len(records))}
+	for i, record := range records {
+		produce.Records[i] = kafkaRecord{Key: record.NotificationID, Value: record}
+	}
+
+	payload, err := json.Marshal(produce)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kafka rest proxy returned status %d: %s", resp.StatusCode, body)
+	}
+
+	// The proxy answers 200 even when some records were not produced; the
+	// whole batch is resent, as the proxy offers no way to resend a part
+	var produced kafkaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("kafka rest proxy response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rest proxy failed to produce a record: %d %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaSink_Export(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedError string
+	}{
+		{
+			name:   "produces every record keyed by notification",
+			status: http.StatusOK,
+			body:   `{"offsets":[{"partition":0,"offset":41},{"partition":0,"offset":42}]}`,
+		},
+		{
+			name:          "fails when a record was not produced",
+			status:        http.StatusOK,
+			body:          `{"offsets":[{"partition":0,"offset":41},{"error_code":50003,"error":"Leader not available"}]}`,
+			expectedError: "kafka rest proxy failed to produce a record: 50003 Leader not available",
+		},
+		{
+			name:          "fails on a proxy error",
+			status:        http.StatusNotFound,
+			body:          `{"error_code":40401,"message":"Topic not found"}`,
+			expectedError: `kafka rest proxy returned status 404: {"error_code":40401,"message":"Topic not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/topics/notification-events", r.URL.Path)
+				assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+
+				var produce struct {
+					Records []struct {
+						Key   string `json:"key"`
+						Value Record `json:"value"`
+					} `json:"records"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&produce))
+				if assert.Len(t, produce.Records, 2) {
+					assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW", produce.Records[0].Key)
+					assert.Equal(t, "notification.sent", produce.Records[0].Value.Type)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			sink := NewKafkaSink(AnalyticsConfig{KafkaRESTURL: server.URL + "/", KafkaTopic: "notification-events"}, server.Client())
+
+			err := sink.Export(context.Background(), []Record{
+				newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VW")),
+				newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VX")),
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+)
+
+var _ Sink = (*S3Sink)(nil)
+
+// S3Sink writes each batch as a newline delimited JSON object under
+// <prefix>/dt=<date>/, signing requests with credentials from the default
+// AWS chain. ANALYTICS_S3_ENDPOINT addresses S3 compatible stores path-style
+type S3Sink struct {
+	bucket      string
+	prefix      string
+	region      string
+	endpoint    string
+	httpclient  *http.Client
+	signer      *v4.Signer
+	clock       clock.Clock
+	idGenerator idgen.Generator
+
+	mu  sync.Mutex
+	aws *aws.Config
+}
+
+func NewS3Sink(config AnalyticsConfig, httpclient *http.Client, clock clock.Clock, idGenerator idgen.Generator) *S3Sink {
+	return &S3Sink{
+		bucket:      config.S3Bucket,
+		prefix:      strings.Trim(config.S3Prefix, "/"),
+		region:      config.S3Region,
+		endpoint:    strings.TrimRight(config.S3Endpoint, "/"),
+		httpclient:  httpclient,
+		signer:      v4.NewSigner(),
+		clock:       clock,
+		idGenerator: idGenerator,
+	}
+}
+
+func (s *S3Sink) Export(ctx context.Context, records []Record) error {
+	cfg, err := s.awsConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	// Object names are unique across instances and sort by time
+	id, err := s.idGenerator.NewID()
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("dt=%s/%s.ndjson", s.clock.Now().UTC().Format("2006-01-02"), id)
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	target := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, cfg.Region, key)
+	if s.endpoint != "" {
+		target = fmt.Sprintf("%s/%s/%s", s.endpoint, url.PathEscape(s.bucket), key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(payload.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	credentials, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("aws credentials: %w", err)
+	}
+	hash := sha256.Sum256(payload.Bytes())
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(hash[:]))
+	if err := s.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "s3", cfg.Region, s.clock.Now()); err != nil {
+		return err
+	}
+
+	resp, err := s.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 returned status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// awsConfig resolves the AWS configuration on first use
+func (s *S3Sink) awsConfig(ctx context.Context) (aws.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.aws != nil {
+		return *s.aws, nil
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if s.region != "" {
+		opts = append(opts, awsconfig.WithRegion(s.region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("aws config: %w", err)
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, errors.New("aws region is not configured")
+	}
+
+	s.aws = &cfg
+	return cfg, nil
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestS3Sink_Export(t *testing.T) {
+	records := []Record{
+		newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VW")),
+		newRecord(sentEvent("01JB8Z5XK3M4N5P6Q7R8S9T0VX")),
+	}
+
+	tests := []struct {
+		name          string
+		prefix        string
+		status        int
+		expectedPath  string
+		expectedError string
+	}{
+		{
+			name:         "writes the batch as one object per call",
+			prefix:       "/notification-events/",
+			status:       http.StatusOK,
+			expectedPath: "/analytics/notification-events/dt=2025-06-01/01JB9000000000000000000000.ndjson",
+		},
+		{
+			name:         "writes at the bucket root without prefix",
+			status:       http.StatusOK,
+			expectedPath: "/analytics/dt=2025-06-01/01JB9000000000000000000000.ndjson",
+		},
+		{
+			name:          "fails on an S3 error",
+			status:        http.StatusForbidden,
+			expectedPath:  "/analytics/dt=2025-06-01/01JB9000000000000000000000.ndjson",
+			expectedError: "s3 returned status 403: <Error><Code>AccessDenied</Code></Error>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+			t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, tt.expectedPath, r.URL.Path)
+				assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250601/eu-west-1/s3/"))
+				assert.NotEmpty(t, r.Header.Get("X-Amz-Content-Sha256"))
+
+				var lines []string
+				scanner := bufio.NewScanner(r.Body)
+				for scanner.Scan() {
+					var record Record
+					assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+					lines = append(lines, record.NotificationID)
+				}
+				assert.Equal(t, []string{"01JB8Z5XK3M4N5P6Q7R8S9T0VW", "01JB8Z5XK3M4N5P6Q7R8S9T0VX"}, lines)
+
+				w.WriteHeader(tt.status)
+				if tt.status != http.StatusOK {
+					_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+				}
+			}))
+			defer server.Close()
+
+			clk := mockclock.NewMockClock(ctrl)
+			clk.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).AnyTimes()
+			idGenerator := mockidgen.NewMockGenerator(ctrl)
+			idGenerator.EXPECT().NewID().Return("01JB9000000000000000000000", nil)
+
+			sink := NewS3Sink(AnalyticsConfig{
+				S3Bucket:   "analytics",
+				S3Prefix:   tt.prefix,
+				S3Region:   "eu-west-1",
+				S3Endpoint: server.URL,
+			}, server.Client(), clk, idGenerator)
+
+			err := sink.Export(context.Background(), records)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,715 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Module("batch",
+	fx.Provide(
+		fx.Annotate(
+			NewBatchProcessor,
+			fx.As(new(Processor)),
+		),
+	),
+)
+
+// maxRecordErrors caps the record errors kept on a job
+const maxRecordErrors = 100
+
+// ErrTooManyJobs is returned when BATCH_MAX_JOBS jobs are still running
+var ErrTooManyJobs = errors.New("too many batch jobs running, retry later")
+
+// ErrTooManyNotifyJobs is returned when BATCH_MAX_NOTIFY_JOBS notify jobs
+// are still delivering
+var ErrTooManyNotifyJobs = errors.New("too many notify jobs queued, retry later")
+
+type BatchConfig struct {
+	// Workers is the number of records of one job delivered concurrently;
+	// reading stops while all of them are busy
+	Workers       int `envconfig:"BATCH_WORKERS" default:"10"`
+	MaxJobs       int `envconfig:"BATCH_MAX_JOBS" default:"10"`
+	MaxRecords    int `envconfig:"BATCH_MAX_RECORDS" default:"100000"`
+	MaxRecordSize int `envconfig:"BATCH_MAX_RECORD_SIZE" default:"65536"`
+	// MaxNotifyJobs bounds the notify jobs delivering at once on an instance
+	MaxNotifyJobs int `envconfig:"BATCH_MAX_NOTIFY_JOBS" default:"1000"`
+	// JobLease is how long a job may go without a heartbeat from the
+	// instance delivering it before it is taken for abandoned; running jobs
+	// are heartbeated three times per lease
+	JobLease time.Duration `envconfig:"BATCH_JOB_LEASE" default:"1m"`
+}
+
+// Status is the progress of a batch job as seen by the upload; Pending
+// records were received but are not delivered yet. State is one of the
+// repository job states
+type Status struct {
+	ID            string        `json:"id"`
+	RecipientType string        `json:"recipient_type"`
+	State         string        `json:"state"`
+	Received      int           `json:"received"`
+	Rejected      int           `json:"rejected"`
+	Sent          int           `json:"sent"`
+	Failed        int           `json:"failed"`
+	Pending       int           `json:"pending"`
+	Errors        []RecordError `json:"errors"`
+	CreatedAt     time.Time     `json:"created_at"`
+	FinishedAt    *time.Time    `json:"finished_at,omitempty"`
+}
+
+// RecordError reports a record that was rejected or failed to deliver, by
+// its line in the uploaded stream
+type RecordError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+//go:generate mockgen -package mockbatch -destination ./mock/mockbatch.go . Processor
+type Processor interface {
+	// Start creates a job delivering to the recipient type; records are
+	// added with Submit and the job must be closed once the input ends
+	Start(ctx context.Context, recipientType string) (*Job, error)
+	// Enqueue delivers one notification to the recipient type in the
+	// background, as a job of its own
+	Enqueue(ctx context.Context, recipientType string, notification service.Notification) (*Job, error)
+}
+
+var _ Processor = (*BatchProcessor)(nil)
+
+// BatchProcessor runs batch jobs. Every job has its own workers fed through
+// an unbuffered channel, so a caller submitting faster than the providers
+// accept is held back instead of buffering the batch. Jobs and the outcome
+// of every record are persisted, so their status is served by any instance
+// and survives restarts
+type BatchProcessor struct {
+	service     service.NotificationProvider
+	jobs        repository.JobProvider
+	idGenerator idgen.Generator
+	clock       clock.Clock
+	config      BatchConfig
+	logger      *zap.Logger
+
+	mu        sync.Mutex
+	running   int
+	notifying int
+
+	// active holds the jobs of this instance until they complete, for their
+	// heartbeats
+	activeMu sync.Mutex
+	active   map[string]*Job
+
+	// ctx outlives the upload requests; it is cancelled when shutdown gives
+	// up waiting for the jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// stopSupervising ends the heartbeats and recovery of abandoned jobs
+	stopSupervising context.CancelFunc
+	supervising     sync.WaitGroup
+}
+
+type BatchProcessorParams struct {
+	fx.In
+
+	Lifecycle   fx.Lifecycle
+	Config      BatchConfig
+	Service     service.NotificationProvider
+	Jobs        repository.JobProvider
+	IDGenerator idgen.Generator
+	Clock       clock.Clock
+	Logger      *zap.Logger
+}
+
+func NewBatchProcessor(params BatchProcessorParams) (*BatchProcessor, error) {
+	if params.Config.Workers < 1 {
+		return nil, fmt.Errorf("batch workers: %d must be at least 1", params.Config.Workers)
+	}
+	if params.Config.MaxJobs < 1 {
+		return nil, fmt.Errorf("batch max jobs: %d must be at least 1", params.Config.MaxJobs)
+	}
+	if params.Config.MaxNotifyJobs < 1 {
+		return nil, fmt.Errorf("batch max notify jobs: %d must be at least 1", params.Config.MaxNotifyJobs)
+	}
+	if params.Config.JobLease <= 0 {
+		return nil, fmt.Errorf("batch job lease: %s must be positive", params.Config.JobLease)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	processor := &BatchProcessor{
+		service:     params.Service,
+		jobs:        params.Jobs,
+		idGenerator: params.IDGenerator,
+		clock:       params.Clock,
+		config:      params.Config,
+		logger:      params.Logger,
+		active:      make(map[string]*Job),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			processor.supervise()
+			return nil
+		},
+		OnStop: processor.Stop,
+	})
+
+	return processor, nil
+}
+
+func (p *BatchProcessor) Start(ctx context.Context, recipientType string) (*Job, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running >= p.config.MaxJobs {
+		return nil, ErrTooManyJobs
+	}
+
+	id, err := p.idGenerator.NewID()
+	if err != nil {
+		return nil, err
+	}
+	job, err := p.newJob(ctx, repository.Job{ID: id, Kind: repository.JobKindBatch, RecipientType: recipientType})
+	if err != nil {
+		return nil, err
+	}
+	p.running++
+
+	p.run(job, p.config.Workers, func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	})
+
+	return job, nil
+}
+
+// Enqueue is bounded by BATCH_MAX_NOTIFY_JOBS rather than BATCH_MAX_JOBS:
+// its single record is held back by the dispatch queues like a notification
+// sent while the caller waits. The notification is stored with the job,
+// under a message id defaulting to the job, so when the instance dies
+// another one resends it without delivering it twice
+func (p *BatchProcessor) Enqueue(ctx context.Context, recipientType string, notification service.Notification) (*Job, error) {
+	if !p.reserveNotify() {
+		return nil, ErrTooManyNotifyJobs
+	}
+
+	job, err := p.newNotifyJob(ctx, recipientType, &notification)
+	if err != nil {
+		p.releaseNotify()
+		return nil, err
+	}
+	p.deliver(job, notification)
+	return job, nil
+}
+
+// newNotifyJob persists a notify job holding the notification, after
+// defaulting its message id
+func (p *BatchProcessor) newNotifyJob(ctx context.Context, recipientType string, notification *service.Notification) (*Job, error) {
+	id, err := p.idGenerator.NewID()
+	if err != nil {
+		return nil, err
+	}
+	if notification.MessageID == "" {
+		notification.MessageID = "job:" + id
+	}
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.newJob(ctx, repository.Job{
+		ID:            id,
+		Kind:          repository.JobKindNotify,
+		RecipientType: recipientType,
+		Notification:  payload,
+	})
+}
+
+// deliver runs the single record of a notify job
+func (p *BatchProcessor) deliver(job *Job, notification service.Notification) {
+	p.run(job, 1, p.releaseNotify)
+	defer job.Close()
+
+	// The worker is idle, so the record is taken at once
+	_ = job.Submit(context.Background(), 1, notification)
+}
+
+func (p *BatchProcessor) reserveNotify() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.notifying >= p.config.MaxNotifyJobs {
+		return false
+	}
+	p.notifying++
+	return true
+}
+
+func (p *BatchProcessor) releaseNotify() {
+	p.mu.Lock()
+	p.notifying--
+	p.mu.Unlock()
+}
+
+// newJob persists a queued job charged to the quota subject of ctx
+func (p *BatchProcessor) newJob(ctx context.Context, stored repository.Job) (*Job, error) {
+	createdAt := p.clock.Now()
+	stored.State = repository.JobStateQueued
+	stored.Subject = quota.SubjectFrom(ctx)
+	stored.CreatedAt = createdAt
+	stored.UpdatedAt = createdAt
+	stored.HeartbeatAt = createdAt
+	if err := p.jobs.CreateJob(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return p.job(stored), nil
+}
+
+// job is the running form of a persisted job
+func (p *BatchProcessor) job(stored repository.Job) *Job {
+	return &Job{
+		id:            stored.ID,
+		kind:          stored.Kind,
+		recipientType: stored.RecipientType,
+		subject:       stored.Subject,
+		records:       make(chan record),
+		done:          make(chan struct{}),
+		store:         p.jobs,
+		clock:         p.clock,
+		logger:        p.logger,
+		state:         stored.State,
+		createdAt:     stored.CreatedAt,
+	}
+}
+
+// run starts the workers of the job, completing it once they delivered
+// every record, then calls finished
+func (p *BatchProcessor) run(job *Job, workers int, finished func()) {
+	p.activeMu.Lock()
+	p.active[job.id] = job
+	p.activeMu.Unlock()
+
+	var running sync.WaitGroup
+	for range workers {
+		running.Add(1)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer running.Done()
+			p.work(job)
+		}()
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		running.Wait()
+		suspended := job.isSuspended()
+		if !suspended {
+			job.complete()
+		}
+		finished()
+
+		p.activeMu.Lock()
+		delete(p.active, job.id)
+		p.activeMu.Unlock()
+
+		if suspended {
+			p.logger.Info("notify job left for another attempt",
+				zap.String("job_id", job.id),
+				zap.String("recipient_type", job.recipientType),
+			)
+			return
+		}
+
+		status := job.Status()
+		p.logger.Info("batch job completed",
+			zap.String("job_id", status.ID),
+			zap.String("kind", job.kind),
+			zap.String("recipient_type", status.RecipientType),
+			zap.String("state", status.State),
+			zap.Int("sent", status.Sent),
+			zap.Int("failed", status.Failed),
+			zap.Int("rejected", status.Rejected),
+		)
+	}()
+}
+
+func (p *BatchProcessor) work(job *Job) {
+	for record := range job.records {
+		report, err := p.service.Send(quota.WithSubject(p.ctx, job.subject), job.recipientType, record.notification)
+		if job.kind == repository.JobKindNotify && p.resendable(err) {
+			job.suspend()
+			continue
+		}
+		job.delivered(record.line, report.ID, err)
+	}
+}
+
+// resendable tells a notify job is better left unfinished, for the sweep of
+// abandoned jobs to send it again: shutdown cut the delivery short, or
+// another delivery of its message is still in flight
+func (p *BatchProcessor) resendable(err error) bool {
+	var inProgressErr *service.MessageInProgressError
+	return err != nil && (p.ctx.Err() != nil || errors.As(err, &inProgressErr))
+}
+
+// supervise heartbeats the jobs of this instance and finishes those other
+// instances abandoned, first at once, then three times per lease, until
+// Stop
+func (p *BatchProcessor) supervise() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.stopSupervising = cancel
+
+	p.supervising.Add(1)
+	go func() {
+		defer p.supervising.Done()
+		for {
+			p.heartbeat(ctx)
+			p.recoverAbandoned(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.clock.After(p.config.JobLease / 3):
+			}
+		}
+	}()
+}
+
+// heartbeat tells the jobs of this instance are alive; a failed write is
+// logged by the repository, and made up for by the next heartbeat
+func (p *BatchProcessor) heartbeat(ctx context.Context) {
+	p.activeMu.Lock()
+	ids := make([]string, 0, len(p.active))
+	for id := range p.active {
+		ids = append(ids, id)
+	}
+	p.activeMu.Unlock()
+
+	_ = p.jobs.HeartbeatJobs(ctx, ids)
+}
+
+// recoverAbandoned finishes every job left unfinished by an instance that
+// died. The records of a batch not delivered yet were never stored, so the
+// job ends with the outcomes it has
+func (p *BatchProcessor) recoverAbandoned(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, claimed, err := p.jobs.ClaimStaleJob(ctx, p.config.JobLease)
+		if err != nil || !claimed {
+			return
+		}
+
+		p.interrupt(ctx, job)
+	}
+}
+
+// interrupt ends an abandoned job with the outcomes of its items; a notify
+// job without an outcome is sent again instead
+func (p *BatchProcessor) interrupt(ctx context.Context, job repository.Job) {
+	detail, err := p.jobs.FindJob(ctx, job.ID, job.Subject, repository.JobItemFilter{Limit: 1})
+	if err != nil {
+		return
+	}
+
+	update := repository.JobUpdate{State: repository.InterruptedJobState(detail.Counts)}
+	if job.Kind == repository.JobKindNotify {
+		if detail.Counts == (repository.JobItemCounts{}) && p.resume(job) {
+			return
+		}
+		total := 1
+		update = repository.JobUpdate{State: repository.CompletedJobState(detail.Counts), Total: &total}
+	}
+
+	finishedAt := p.clock.Now()
+	update.FinishedAt = &finishedAt
+	if err := p.jobs.UpdateJob(ctx, job.ID, update); err != nil {
+		return
+	}
+
+	p.logger.Warn("abandoned job finished",
+		zap.String("job_id", job.ID),
+		zap.String("kind", job.Kind),
+		zap.String("recipient_type", job.RecipientType),
+		zap.String("state", update.State),
+		zap.Int("sent", detail.Counts.Sent),
+		zap.Int("failed", detail.Counts.Failed),
+		zap.Int("rejected", detail.Counts.Rejected),
+	)
+}
+
+// resume sends a notify job abandoned before its delivery had an outcome,
+// reporting false when its notification cannot be read. Past
+// BATCH_MAX_NOTIFY_JOBS the job is left for a later sweep
+func (p *BatchProcessor) resume(stored repository.Job) bool {
+	var notification service.Notification
+	if err := json.Unmarshal(stored.Notification, &notification); err != nil {
+		p.logger.Error("failed to read the notification of a notify job",
+			zap.String("job_id", stored.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	if !p.reserveNotify() {
+		return true
+	}
+
+	p.logger.Info("resending abandoned notify job",
+		zap.String("job_id", stored.ID),
+		zap.String("recipient_type", stored.RecipientType),
+	)
+	p.deliver(p.job(stored), notification)
+	return true
+}
+
+// Stop waits for the jobs to deliver their records; when ctx ends first the
+// deliveries in flight are cancelled
+func (p *BatchProcessor) Stop(ctx context.Context) error {
+	if p.stopSupervising != nil {
+		p.stopSupervising()
+		p.supervising.Wait()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+type record struct {
+	line         int
+	notification service.Notification
+}
+
+// Job is one batch upload and the delivery of its records
+type Job struct {
+	id            string
+	kind          string
+	recipientType string
+	// subject carries the quotas of the uploader to the records
+	subject string
+	records chan record
+	// done is closed once the job completed
+	done      chan struct{}
+	store     repository.JobProvider
+	clock     clock.Clock
+	logger    *zap.Logger
+	closeOnce sync.Once
+
+	mu    sync.Mutex
+	state string
+	// suspended is set when the record of a notify job was not delivered,
+	// leaving the job unfinished for another attempt
+	suspended  bool
+	received   int
+	rejected   int
+	sent       int
+	failed     int
+	errors     []RecordError
+	createdAt  time.Time
+	finishedAt time.Time
+}
+
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Done is closed once every record of the job has an outcome
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Submit hands the record to a worker, blocking while all of them are busy;
+// it fails when ctx ends first, e.g. when the uploader went away
+func (j *Job) Submit(ctx context.Context, line int, notification service.Notification) error {
+	j.begin()
+
+	// Counted first, so a fast worker never makes pending negative
+	j.mu.Lock()
+	j.received++
+	j.mu.Unlock()
+
+	select {
+	case j.records <- record{line: line, notification: notification}:
+		return nil
+	case <-ctx.Done():
+		j.mu.Lock()
+		j.received--
+		j.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Reject counts a record that could not be read as a notification
+func (j *Job) Reject(line int, err error) {
+	j.begin()
+
+	j.mu.Lock()
+	j.received++
+	j.rejected++
+	j.addError(line, err)
+	j.mu.Unlock()
+
+	j.recordItem(line, repository.JobItemRejected, "", err)
+}
+
+// Close ends the input, fixing the total of the job; the job completes once
+// the submitted records are delivered. It is safe to call more than once
+func (j *Job) Close() {
+	j.closeOnce.Do(func() {
+		j.mu.Lock()
+		total := j.received
+		j.mu.Unlock()
+
+		// Persisted before the workers can drain and complete the job
+		j.update(repository.JobUpdate{Total: &total})
+		close(j.records)
+	})
+}
+
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := Status{
+		ID:            j.id,
+		RecipientType: j.recipientType,
+		State:         j.state,
+		Received:      j.received,
+		Rejected:      j.rejected,
+		Sent:          j.sent,
+		Failed:        j.failed,
+		Pending:       j.received - j.rejected - j.sent - j.failed,
+		Errors:        append([]RecordError{}, j.errors...),
+		CreatedAt:     j.createdAt,
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		status.FinishedAt = &finishedAt
+	}
+	return status
+}
+
+// begin moves the job in progress with its first record
+func (j *Job) begin() {
+	j.mu.Lock()
+	queued := j.state == repository.JobStateQueued
+	if queued {
+		j.state = repository.JobStateInProgress
+	}
+	j.mu.Unlock()
+
+	if queued {
+		j.update(repository.JobUpdate{State: repository.JobStateInProgress})
+	}
+}
+
+func (j *Job) delivered(line int, notificationID string, err error) {
+	j.mu.Lock()
+	if err != nil {
+		j.failed++
+		j.addError(line, err)
+	} else {
+		j.sent++
+	}
+	j.mu.Unlock()
+
+	status := repository.JobItemSent
+	if err != nil {
+		status = repository.JobItemFailed
+	}
+	j.recordItem(line, status, notificationID, err)
+}
+
+func (j *Job) suspend() {
+	j.mu.Lock()
+	j.suspended = true
+	j.mu.Unlock()
+}
+
+func (j *Job) isSuspended() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.suspended
+}
+
+func (j *Job) complete() {
+	j.mu.Lock()
+	j.state = repository.CompletedJobState(repository.JobItemCounts{
+		Sent:     j.sent,
+		Failed:   j.failed,
+		Rejected: j.rejected,
+	})
+	j.finishedAt = j.clock.Now()
+	update := repository.JobUpdate{State: j.state, FinishedAt: &j.finishedAt}
+	j.mu.Unlock()
+
+	j.update(update)
+	close(j.done)
+}
+
+// recordItem persists the outcome of a record. The record has already been
+// handled, so a failed write is logged rather than failing the job. Writes
+// are detached from the upload and from shutdown, so outcomes are still
+// recorded once either ends
+func (j *Job) recordItem(line int, status string, notificationID string, err error) {
+	item := repository.JobItem{
+		JobID:          j.id,
+		Item:           line,
+		Status:         status,
+		NotificationID: notificationID,
+		CreatedAt:      j.clock.Now(),
+	}
+	if err != nil {
+		item.Error = err.Error()
+	}
+
+	if err := j.store.RecordJobItem(context.Background(), item); err != nil {
+		j.logger.Warn("failed to record batch job item",
+			zap.String("job_id", j.id),
+			zap.Int("line", line),
+			zap.Error(err),
+		)
+	}
+}
+
+func (j *Job) update(update repository.JobUpdate) {
+	if err := j.store.UpdateJob(context.Background(), j.id, update); err != nil {
+		j.logger.Warn("failed to update batch job",
+			zap.String("job_id", j.id),
+			zap.Error(err),
+		)
+	}
+}
+
+// addError keeps the first maxRecordErrors errors; the caller holds mu
+func (j *Job) addError(line int, err error) {
+	if len(j.errors) < maxRecordErrors {
+		j.errors = append(j.errors, RecordError{Line: line, Message: err.Error()})
+	}
+}
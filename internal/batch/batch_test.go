@@ -0,0 +1,609 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+var testBatchConfig = BatchConfig{
+	Workers:       2,
+	MaxJobs:       1,
+	MaxRecords:    100,
+	MaxRecordSize: 1024,
+	MaxNotifyJobs: 1,
+	JobLease:      time.Minute,
+}
+
+func newTestIDGenerator(ctrl *gomock.Controller) *mockidgen.MockGenerator {
+	var next atomic.Int64
+	idGenerator := mockidgen.NewMockGenerator(ctrl)
+	idGenerator.EXPECT().NewID().DoAndReturn(func() (string, error) {
+		return "job-" + strconv.FormatInt(next.Add(1), 10), nil
+	}).AnyTimes()
+	return idGenerator
+}
+
+// testJobStore records what a processor persists
+type testJobStore struct {
+	mu      sync.Mutex
+	jobs    []repository.Job
+	updates []repository.JobUpdate
+	items   []repository.JobItem
+}
+
+func newTestJobs(ctrl *gomock.Controller, store *testJobStore) *mockrepository.MockJobProvider {
+	jobs := mockrepository.NewMockJobProvider(ctrl)
+	jobs.EXPECT().CreateJob(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, job repository.Job) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.jobs = append(store.jobs, job)
+		return nil
+	}).AnyTimes()
+	jobs.EXPECT().UpdateJob(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, update repository.JobUpdate) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.updates = append(store.updates, update)
+		return nil
+	}).AnyTimes()
+	jobs.EXPECT().RecordJobItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, item repository.JobItem) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.items = append(store.items, item)
+		return nil
+	}).AnyTimes()
+	return jobs
+}
+
+func newTestProcessor(t *testing.T, params BatchProcessorParams) *BatchProcessor {
+	params.Lifecycle = fxtest.NewLifecycle(t)
+	params.Logger = zap.NewNop()
+	if params.Clock == nil {
+		params.Clock = clock.NewRealClock()
+	}
+
+	processor, err := NewBatchProcessor(params)
+	require.NoError(t, err)
+	return processor
+}
+
+func waitCompleted(t *testing.T, job *Job) Status {
+	var status Status
+	require.Eventually(t, func() bool {
+		status = job.Status()
+		return status.FinishedAt != nil
+	}, time.Second, 5*time.Millisecond)
+	return status
+}
+
+func TestNewBatchProcessor(t *testing.T) {
+	tests := []struct {
+		name   string
+		config BatchConfig
+	}{
+		{"no workers", BatchConfig{Workers: 0, MaxJobs: 1, MaxNotifyJobs: 1, JobLease: time.Minute}},
+		{"no jobs", BatchConfig{Workers: 1, MaxJobs: 0, MaxNotifyJobs: 1, JobLease: time.Minute}},
+		{"no notify jobs", BatchConfig{Workers: 1, MaxJobs: 1, MaxNotifyJobs: 0, JobLease: time.Minute}},
+		{"no lease", BatchConfig{Workers: 1, MaxJobs: 1, MaxNotifyJobs: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBatchProcessor(BatchProcessorParams{
+				Lifecycle: fxtest.NewLifecycle(t),
+				Config:    tt.config,
+				Logger:    zap.NewNop(),
+			})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBatchProcessor_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, notification service.Notification) (service.DeliveryReport, error) {
+			if notification.To == "down@example.com" {
+				return service.DeliveryReport{ID: "n-" + notification.To}, errors.New("failure to sent the notifications")
+			}
+			return service.DeliveryReport{ID: "n-" + notification.To}, nil
+		}).Times(3)
+
+	store := &testJobStore{}
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, store),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Start(context.Background(), "buyer")
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", job.ID())
+
+	ctx := context.Background()
+	require.NoError(t, job.Submit(ctx, 1, service.Notification{To: "a@example.com"}))
+	job.Reject(2, errors.New("invalid character 'x'"))
+	require.NoError(t, job.Submit(ctx, 3, service.Notification{To: "down@example.com"}))
+	require.NoError(t, job.Submit(ctx, 4, service.Notification{To: "b@example.com"}))
+	job.Close()
+	job.Close()
+
+	status := waitCompleted(t, job)
+
+	assert.Equal(t, repository.JobStatePartial, status.State)
+	assert.Equal(t, "buyer", status.RecipientType)
+	assert.Equal(t, 4, status.Received)
+	assert.Equal(t, 1, status.Rejected)
+	assert.Equal(t, 2, status.Sent)
+	assert.Equal(t, 1, status.Failed)
+	assert.Equal(t, 0, status.Pending)
+	assert.ElementsMatch(t, []RecordError{
+		{Line: 2, Message: "invalid character 'x'"},
+		{Line: 3, Message: "failure to sent the notifications"},
+	}, status.Errors)
+	assert.NotNil(t, status.FinishedAt)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	require.Len(t, store.jobs, 1)
+	assert.Equal(t, "job-1", store.jobs[0].ID)
+	assert.Equal(t, repository.JobKindBatch, store.jobs[0].Kind)
+	assert.Equal(t, repository.JobStateQueued, store.jobs[0].State)
+
+	for i := range store.items {
+		store.items[i].CreatedAt = time.Time{}
+	}
+	assert.ElementsMatch(t, []repository.JobItem{
+		{JobID: "job-1", Item: 1, Status: repository.JobItemSent, NotificationID: "n-a@example.com"},
+		{JobID: "job-1", Item: 2, Status: repository.JobItemRejected, Error: "invalid character 'x'"},
+		{JobID: "job-1", Item: 3, Status: repository.JobItemFailed, NotificationID: "n-down@example.com", Error: "failure to sent the notifications"},
+		{JobID: "job-1", Item: 4, Status: repository.JobItemSent, NotificationID: "n-b@example.com"},
+	}, store.items)
+
+	total := 4
+	require.Len(t, store.updates, 3)
+	assert.Equal(t, repository.JobUpdate{State: repository.JobStateInProgress}, store.updates[0])
+	assert.Equal(t, repository.JobUpdate{Total: &total}, store.updates[1])
+	assert.Equal(t, repository.JobStatePartial, store.updates[2].State)
+	assert.NotNil(t, store.updates[2].FinishedAt)
+}
+
+func TestBatchProcessor_StartFailsWhenJobNotCreated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobs := mockrepository.NewMockJobProvider(ctrl)
+	jobs.EXPECT().CreateJob(gomock.Any(), gomock.Any()).Return(errors.New("database down")).Times(2)
+
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     mockservice.NewMockNotificationProvider(ctrl),
+		Jobs:        jobs,
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	_, err := processor.Start(context.Background(), "buyer")
+	require.Error(t, err)
+
+	_, err = processor.Start(context.Background(), "buyer")
+	assert.NotErrorIs(t, err, ErrTooManyJobs, "a job that was never created does not hold a slot")
+}
+
+func TestBatchProcessor_Backpressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+			<-release
+			return service.DeliveryReport{}, nil
+		}).Times(2)
+
+	config := testBatchConfig
+	config.Workers = 1
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      config,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, &testJobStore{}),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Start(context.Background(), "buyer")
+	require.NoError(t, err)
+	require.NoError(t, job.Submit(context.Background(), 1, service.Notification{To: "a@example.com"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = job.Submit(ctx, 2, service.Notification{To: "b@example.com"})
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, job.Status().Received, "the record the worker never took is not counted")
+
+	submitted := make(chan error)
+	go func() {
+		submitted <- job.Submit(context.Background(), 2, service.Notification{To: "b@example.com"})
+	}()
+	close(release)
+	require.NoError(t, <-submitted)
+	job.Close()
+
+	assert.Equal(t, 2, waitCompleted(t, job).Sent)
+}
+
+func TestBatchProcessor_TooManyJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     mockservice.NewMockNotificationProvider(ctrl),
+		Jobs:        newTestJobs(ctrl, &testJobStore{}),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Start(context.Background(), "buyer")
+	require.NoError(t, err)
+
+	_, err = processor.Start(context.Background(), "seller")
+	require.ErrorIs(t, err, ErrTooManyJobs)
+
+	job.Close()
+	assert.Equal(t, repository.JobStateDone, waitCompleted(t, job).State)
+
+	require.Eventually(t, func() bool {
+		_, err = processor.Start(context.Background(), "seller")
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBatchProcessor_Enqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	// The message id defaults to the job, deduplicating a resend
+	svc.EXPECT().Send(gomock.Any(), "buyer", service.Notification{MessageID: "job:job-2", To: "a@example.com"}).
+		Return(service.DeliveryReport{ID: "n-1"}, nil)
+
+	store := &testJobStore{}
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, store),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	// Running batch jobs do not hold notify jobs back
+	batchJob, err := processor.Start(context.Background(), "seller")
+	require.NoError(t, err)
+	defer batchJob.Close()
+
+	job, err := processor.Enqueue(context.Background(), "buyer", service.Notification{To: "a@example.com"})
+	require.NoError(t, err)
+
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second):
+		t.Fatal("job not completed")
+	}
+
+	status := job.Status()
+	assert.Equal(t, repository.JobStateDone, status.State)
+	assert.Equal(t, 1, status.Received)
+	assert.Equal(t, 1, status.Sent)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	require.Len(t, store.jobs, 2)
+	assert.Equal(t, repository.JobKindNotify, store.jobs[1].Kind)
+	assert.Equal(t, "buyer", store.jobs[1].RecipientType)
+	assert.JSONEq(t, `{"MessageID":"job:job-2","To":"a@example.com"}`, notificationFields(t, store.jobs[1].Notification))
+	require.Len(t, store.items, 1)
+	assert.Equal(t, "n-1", store.items[0].NotificationID)
+}
+
+// notificationFields drops the empty fields of a stored notification
+func notificationFields(t *testing.T, payload []byte) string {
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(payload, &fields))
+	for name, value := range fields {
+		if value == nil || value == "" || value == false {
+			delete(fields, name)
+		}
+	}
+	out, err := json.Marshal(fields)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestBatchProcessor_TooManyNotifyJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+			<-release
+			return service.DeliveryReport{}, nil
+		})
+
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, &testJobStore{}),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Enqueue(context.Background(), "buyer", service.Notification{To: "a@example.com"})
+	require.NoError(t, err)
+
+	_, err = processor.Enqueue(context.Background(), "buyer", service.Notification{To: "b@example.com"})
+	assert.ErrorIs(t, err, ErrTooManyNotifyJobs)
+
+	close(release)
+	waitCompleted(t, job)
+}
+
+func TestBatchProcessor_EnqueueMessageInProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		Return(service.DeliveryReport{}, &service.MessageInProgressError{MessageID: "m-1"})
+
+	store := &testJobStore{}
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, store),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Enqueue(context.Background(), "buyer", service.Notification{MessageID: "m-1", To: "a@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, processor.Stop(context.Background()))
+
+	// Left unfinished for the sweep of abandoned jobs to send it again
+	assert.Nil(t, job.Status().FinishedAt)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Empty(t, store.items)
+	for _, update := range store.updates {
+		assert.Nil(t, update.FinishedAt)
+	}
+}
+
+func TestBatchProcessor_Stop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ service.Notification) (service.DeliveryReport, error) {
+			<-ctx.Done()
+			return service.DeliveryReport{}, ctx.Err()
+		})
+
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        newTestJobs(ctrl, &testJobStore{}),
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Start(context.Background(), "buyer")
+	require.NoError(t, err)
+	require.NoError(t, job.Submit(context.Background(), 1, service.Notification{To: "a@example.com"}))
+	job.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, processor.Stop(ctx), context.DeadlineExceeded)
+	status := waitCompleted(t, job)
+	assert.Equal(t, 1, status.Failed)
+	assert.Equal(t, repository.JobStateFailed, status.State)
+}
+
+func TestBatchProcessor_RecoverAbandoned(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts repository.JobItemCounts
+		state  string
+	}{
+		{"nothing sent", repository.JobItemCounts{Failed: 1}, repository.JobStateFailed},
+		{"some sent", repository.JobItemCounts{Sent: 2, Rejected: 1}, repository.JobStatePartial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			updated := make(chan repository.JobUpdate, 1)
+			jobs := mockrepository.NewMockJobProvider(ctrl)
+			jobs.EXPECT().HeartbeatJobs(gomock.Any(), gomock.Len(0)).Return(nil)
+			gomock.InOrder(
+				jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).
+					Return(repository.Job{ID: "job-1", Kind: repository.JobKindBatch}, true, nil),
+				jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).
+					Return(repository.Job{}, false, nil),
+			)
+			jobs.EXPECT().FindJob(gomock.Any(), "job-1", "", repository.JobItemFilter{Limit: 1}).
+				Return(repository.JobDetail{Counts: tt.counts}, nil)
+			jobs.EXPECT().UpdateJob(gomock.Any(), "job-1", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, update repository.JobUpdate) error {
+					updated <- update
+					return nil
+				})
+
+			processor := newTestProcessor(t, BatchProcessorParams{
+				Config:      testBatchConfig,
+				Jobs:        jobs,
+				IDGenerator: newTestIDGenerator(ctrl),
+			})
+			processor.supervise()
+
+			update := <-updated
+			require.NoError(t, processor.Stop(context.Background()))
+			assert.Equal(t, tt.state, update.State)
+			assert.NotNil(t, update.FinishedAt)
+		})
+	}
+}
+
+func TestBatchProcessor_RecoverAbandonedNotify(t *testing.T) {
+	abandoned := repository.Job{
+		ID:            "job-1",
+		Kind:          repository.JobKindNotify,
+		RecipientType: "buyer",
+		State:         repository.JobStateInProgress,
+		Notification:  []byte(`{"MessageID":"job:job-1","To":"a@example.com"}`),
+		Subject:       "key-1",
+	}
+
+	t.Run("resends a notification without an outcome", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := &testJobStore{}
+		jobs := newTestJobs(ctrl, store)
+		jobs.EXPECT().HeartbeatJobs(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		// Stop may end the sweep before it looks for another job
+		gomock.InOrder(
+			jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).Return(abandoned, true, nil),
+			jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).Return(repository.Job{}, false, nil).MaxTimes(1),
+		)
+		jobs.EXPECT().FindJob(gomock.Any(), "job-1", "key-1", repository.JobItemFilter{Limit: 1}).
+			Return(repository.JobDetail{Job: abandoned}, nil)
+
+		sent := make(chan string, 1)
+		svc := mockservice.NewMockNotificationProvider(ctrl)
+		svc.EXPECT().Send(gomock.Any(), "buyer", service.Notification{MessageID: "job:job-1", To: "a@example.com"}).
+			DoAndReturn(func(ctx context.Context, _ string, _ service.Notification) (service.DeliveryReport, error) {
+				sent <- quota.SubjectFrom(ctx)
+				return service.DeliveryReport{ID: "n-1"}, nil
+			})
+
+		processor := newTestProcessor(t, BatchProcessorParams{
+			Config:      testBatchConfig,
+			Service:     svc,
+			Jobs:        jobs,
+			IDGenerator: newTestIDGenerator(ctrl),
+		})
+		processor.supervise()
+
+		assert.Equal(t, "key-1", <-sent)
+		require.NoError(t, processor.Stop(context.Background()))
+
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		require.Len(t, store.items, 1)
+		assert.Equal(t, "n-1", store.items[0].NotificationID)
+		last := store.updates[len(store.updates)-1]
+		assert.Equal(t, repository.JobStateDone, last.State)
+	})
+
+	t.Run("finishes a notification with an outcome", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		updated := make(chan repository.JobUpdate, 1)
+		jobs := mockrepository.NewMockJobProvider(ctrl)
+		jobs.EXPECT().HeartbeatJobs(gomock.Any(), gomock.Any()).Return(nil)
+		gomock.InOrder(
+			jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).Return(abandoned, true, nil),
+			jobs.EXPECT().ClaimStaleJob(gomock.Any(), time.Minute).Return(repository.Job{}, false, nil),
+		)
+		jobs.EXPECT().FindJob(gomock.Any(), "job-1", "key-1", repository.JobItemFilter{Limit: 1}).
+			Return(repository.JobDetail{Job: abandoned, Counts: repository.JobItemCounts{Sent: 1}}, nil)
+		jobs.EXPECT().UpdateJob(gomock.Any(), "job-1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, update repository.JobUpdate) error {
+				updated <- update
+				return nil
+			})
+
+		processor := newTestProcessor(t, BatchProcessorParams{
+			Config:      testBatchConfig,
+			Jobs:        jobs,
+			IDGenerator: newTestIDGenerator(ctrl),
+		})
+		processor.supervise()
+
+		update := <-updated
+		require.NoError(t, processor.Stop(context.Background()))
+		assert.Equal(t, repository.JobStateDone, update.State)
+		require.NotNil(t, update.Total)
+		assert.Equal(t, 1, *update.Total)
+		assert.NotNil(t, update.FinishedAt)
+	})
+}
+
+func TestBatchProcessor_Heartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	svc := mockservice.NewMockNotificationProvider(ctrl)
+	svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+			<-release
+			return service.DeliveryReport{ID: "n-1"}, nil
+		})
+
+	jobs := newTestJobs(ctrl, &testJobStore{})
+	heartbeats := make(chan []string, 1)
+	jobs.EXPECT().HeartbeatJobs(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ids []string) error {
+		heartbeats <- ids
+		return nil
+	})
+	jobs.EXPECT().ClaimStaleJob(gomock.Any(), gomock.Any()).Return(repository.Job{}, false, nil)
+
+	processor := newTestProcessor(t, BatchProcessorParams{
+		Config:      testBatchConfig,
+		Service:     svc,
+		Jobs:        jobs,
+		IDGenerator: newTestIDGenerator(ctrl),
+	})
+
+	job, err := processor.Start(context.Background(), "buyer")
+	require.NoError(t, err)
+	require.NoError(t, job.Submit(context.Background(), 1, service.Notification{To: "a@example.com"}))
+	job.Close()
+
+	processor.supervise()
+	assert.Equal(t, []string{job.ID()}, <-heartbeats)
+
+	close(release)
+	waitCompleted(t, job)
+	require.NoError(t, processor.Stop(context.Background()))
+}
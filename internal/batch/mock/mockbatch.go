@@ -0,0 +1,73 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/batch (interfaces: Processor)
+//
+// Generated by this command:
+//
+//	mockgen -package mockbatch -destination ./mock/mockbatch.go . Processor
+//
+
+// Package mockbatch is a generated GoMock package.
+package mockbatch
+
+import (
+	context "context"
+	reflect "reflect"
+
+	batch "github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	service "github.com/koungkub/fw-challenge-notification-service/internal/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProcessor is a mock of Processor interface.
+type MockProcessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockProcessorMockRecorder
+	isgomock struct{}
+}
+
+// MockProcessorMockRecorder is the mock recorder for MockProcessor.
+type MockProcessorMockRecorder struct {
+	mock *MockProcessor
+}
+
+// NewMockProcessor creates a new mock instance.
+func NewMockProcessor(ctrl *gomock.Controller) *MockProcessor {
+	mock := &MockProcessor{ctrl: ctrl}
+	mock.recorder = &MockProcessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProcessor) EXPECT() *MockProcessorMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockProcessor) Enqueue(ctx context.Context, recipientType string, notification service.Notification) (*batch.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", ctx, recipientType, notification)
+	ret0, _ := ret[0].(*batch.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockProcessorMockRecorder) Enqueue(ctx, recipientType, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockProcessor)(nil).Enqueue), ctx, recipientType, notification)
+}
+
+// Start mocks base method.
+func (m *MockProcessor) Start(ctx context.Context, recipientType string) (*batch.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, recipientType)
+	ret0, _ := ret[0].(*batch.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockProcessorMockRecorder) Start(ctx, recipientType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockProcessor)(nil).Start), ctx, recipientType)
+}
@@ -0,0 +1,78 @@
+// Package buildinfo tells exactly which build is running. The values are
+// set at build time, e.g.
+//
+//	go build -ldflags "\
+//	  -X github.com/koungkub/fw-challenge-notification-service/internal/buildinfo.version=v1.4.0 \
+//	  -X github.com/koungkub/fw-challenge-notification-service/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X github.com/koungkub/fw-challenge-notification-service/internal/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//	  ./cmd/api
+//
+// and fall back to what the Go toolchain embeds when they are not
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// Unknown stands for a value neither set at build time nor embedded by the
+// toolchain
+const Unknown = "unknown"
+
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the values set at build time, falling back to the module
+// version and the VCS revision and commit time the toolchain embeds
+func Get() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	for _, value := range []*string{&info.Version, &info.Commit, &info.BuildTime} {
+		if *value == "" {
+			*value = Unknown
+		}
+	}
+	return info
+}
+
+// Fields are the log fields of the build
+func (i Info) Fields() []zap.Field {
+	return []zap.Field{
+		zap.String("version", i.Version),
+		zap.String("commit", i.Commit),
+		zap.String("build_time", i.BuildTime),
+		zap.String("go_version", i.GoVersion),
+	}
+}
@@ -0,0 +1,52 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		commit    string
+		buildTime string
+		expected  Info
+	}{
+		{
+			name:      "reports the values set at build time",
+			version:   "v1.4.0",
+			commit:    "3f9c2a1d",
+			buildTime: "2025-06-01T12:00:00Z",
+			expected:  Info{Version: "v1.4.0", Commit: "3f9c2a1d", BuildTime: "2025-06-01T12:00:00Z", GoVersion: runtime.Version()},
+		},
+		{
+			// Test binaries embed neither a module version nor VCS settings
+			name:     "reports unknown values without build time values",
+			expected: Info{Version: Unknown, Commit: Unknown, BuildTime: Unknown, GoVersion: runtime.Version()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { version, commit, buildTime = "", "", "" })
+			version, commit, buildTime = tt.version, tt.commit, tt.buildTime
+
+			assert.Equal(t, tt.expected, Get())
+		})
+	}
+}
+
+func TestInfo_Fields(t *testing.T) {
+	info := Info{Version: "v1.4.0", Commit: "3f9c2a1d", BuildTime: "2025-06-01T12:00:00Z", GoVersion: "go1.25.0"}
+
+	assert.Equal(t, []zap.Field{
+		zap.String("version", "v1.4.0"),
+		zap.String("commit", "3f9c2a1d"),
+		zap.String("build_time", "2025-06-01T12:00:00Z"),
+		zap.String("go_version", "go1.25.0"),
+	}, info.Fields())
+}
@@ -1,10 +1,19 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
 	"github.com/sony/gobreaker/v2"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -13,36 +22,129 @@ import (
 type CircuitBreakerRegistry struct {
 	breakers *sync.Map
 	settings gobreaker.Settings
-	logger   *zap.Logger
+	// thresholds is read on every trip decision so it can change at runtime
+	thresholds atomic.Pointer[TripThresholds]
+	logger     *zap.Logger
+
+	// trips persists open breakers across restarts; nil unless PersistState
+	// or SharedState
+	trips   repository.CircuitBreakerTripProvider
+	clock   clock.Clock
+	events  event.Publisher
+	tracker errortracking.Tracker
+	// held keeps the hosts of restored trips open until the time stored,
+	// since a breaker cannot be created in the open state
+	held *sync.Map
+
+	// tallies counts the outcomes of each host since the last sync, only
+	// with SharedState
+	tallies      *sync.Map
+	sharedWindow time.Duration
+	cancelSync   context.CancelFunc
+	syncing      sync.WaitGroup
+
+	// changes queues the state changes to persist, so the breaker does not
+	// wait on the database while it decides the outcome of a request
+	changes       chan stateChange
+	cancelPersist context.CancelFunc
+	persisting    sync.WaitGroup
+	// generations counts the resets of each host; a queued change of an
+	// earlier generation is dropped, so a trip queued before a reset is not
+	// recorded after it. resetting orders those writes with Reset
+	generations *sync.Map
+	resetting   sync.Mutex
+}
+
+const (
+	// persistTimeout bounds a write of breaker state
+	persistTimeout = 2 * time.Second
+	// stateChangeBuffer bounds the state changes waiting to be persisted
+	stateChangeBuffer = 256
+)
+
+// stateChange is a breaker of host that opened or closed at a time, in a
+// generation of the host
+type stateChange struct {
+	host       string
+	to         gobreaker.State
+	at         time.Time
+	generation uint64
+}
+
+// TripThresholds decide when a closed breaker opens
+type TripThresholds struct {
+	MinRequests         uint32
+	FailureRatioPercent float64
 }
 
 type CircuitBreakerResponse struct {
 	Body       []byte
 	StatusCode int
+	Header     http.Header
 }
 
 type CircuitBreakerRegistryParams struct {
 	fx.In
 
 	Config CircuitBreakerRegistryConfig
-	Logger *zap.Logger
+	Events event.Publisher
+	Trips  repository.CircuitBreakerTripProvider
+	Clock  clock.Clock
+	// Tracker is told of the hosts whose breaker opens on repeated
+	// failures
+	Tracker errortracking.Tracker
+	Logger  *zap.Logger
 }
 
 func NewCircuitBreakerRegistry(params CircuitBreakerRegistryParams) *CircuitBreakerRegistry {
-	return &CircuitBreakerRegistry{
+	registry := &CircuitBreakerRegistry{
 		breakers: &sync.Map{},
-		settings: gobreaker.Settings{
-			MaxRequests: params.Config.MaxHalfOpenRequests,
-			Timeout:     params.Config.OpenStateTimeout,
-			ReadyToTrip: func(counts gobreaker.Counts) bool {
-				failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-
-				return counts.Requests >= params.Config.MinRequestsBeforeTrip &&
-					failureRatio >= (params.Config.FailureThresholdPercent/100)
-			},
+		logger:   params.Logger,
+		clock:    params.Clock,
+		events:   params.Events,
+		tracker:  params.Tracker,
+		held:     &sync.Map{},
+
+		generations: &sync.Map{},
+	}
+	if params.Config.PersistState || params.Config.SharedState {
+		registry.trips = params.Trips
+		registry.changes = make(chan stateChange, stateChangeBuffer)
+	}
+	if params.Config.SharedState {
+		registry.tallies = &sync.Map{}
+		registry.sharedWindow = params.Config.SharedWindow
+	}
+	registry.SetTripThresholds(params.Config.TripThresholds())
+	registry.settings = gobreaker.Settings{
+		MaxRequests:  params.Config.MaxHalfOpenRequests,
+		Timeout:      params.Config.OpenStateTimeout,
+		ReadyToTrip:  registry.readyToTrip,
+		IsSuccessful: isSuccessful,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			publishStateChange(params.Events, name, from, to)
+			trackStateChange(params.Tracker, name, from, to)
+			registry.persistStateChange(name, to)
 		},
-		logger: params.Logger,
 	}
+
+	return registry
+}
+
+// readyToTrip applies the current thresholds to the counts of a breaker, or
+// to those of every instance with SharedState
+func (r *CircuitBreakerRegistry) readyToTrip(counts gobreaker.Counts) bool {
+	thresholds := r.thresholds.Load()
+	failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+
+	return counts.Requests >= thresholds.MinRequests &&
+		failureRatio >= (thresholds.FailureRatioPercent/100)
+}
+
+// SetTripThresholds applies to every breaker, including existing ones, from
+// their next failed request
+func (r *CircuitBreakerRegistry) SetTripThresholds(thresholds TripThresholds) {
+	r.thresholds.Store(&thresholds)
 }
 
 type CircuitBreakerRegistryConfig struct {
@@ -50,13 +152,242 @@ type CircuitBreakerRegistryConfig struct {
 	OpenStateTimeout        time.Duration `envconfig:"CIRCUIT_BREAKER_OPEN_STATE_TIMEOUT" default:"60s"`
 	MinRequestsBeforeTrip   uint32        `envconfig:"CIRCUIT_BREAKER_MIN_REQUESTS_BEFORE_TRIP" default:"3"`
 	FailureThresholdPercent float64       `envconfig:"CIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT" default:"60"`
+	// PersistState stores when breakers open, so instances started within
+	// OpenStateTimeout of a trip keep the host open instead of probing it
+	PersistState bool `envconfig:"CIRCUIT_BREAKER_PERSIST_STATE" default:"false"`
+	// SharedState adds up the outcomes of every instance each SyncInterval
+	// and trips a host fleet-wide once they reach the trip thresholds
+	// within SharedWindow; trips are persisted as with PersistState
+	SharedState  bool          `envconfig:"CIRCUIT_BREAKER_SHARED_STATE" default:"false"`
+	SyncInterval time.Duration `envconfig:"CIRCUIT_BREAKER_SYNC_INTERVAL" default:"1s"`
+	SharedWindow time.Duration `envconfig:"CIRCUIT_BREAKER_SHARED_WINDOW" default:"10s"`
+}
+
+func (c CircuitBreakerRegistryConfig) TripThresholds() TripThresholds {
+	return TripThresholds{
+		MinRequests:         c.MinRequestsBeforeTrip,
+		FailureRatioPercent: c.FailureThresholdPercent,
+	}
 }
 
-func NewCircuitBreakerRegistryConfig() CircuitBreakerRegistryConfig {
-	var cfg CircuitBreakerRegistryConfig
-	envconfig.MustProcess("", &cfg)
+// isSuccessful decides which outcomes count against a provider. Transport
+// errors and 5xx responses do; rejected requests, throttling and requests the
+// caller cancelled do not, since the provider itself is healthy
+func isSuccessful(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return true
+	}
 
-	return cfg
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Throttled || providerErr.StatusCode < http.StatusInternalServerError
+	}
+	return false
+}
+
+// publishStateChange emits a platform event when a breaker opens or recovers;
+// half-open probing is transient and not reported
+func publishStateChange(events event.Publisher, host string, from gobreaker.State, to gobreaker.State) {
+	if events == nil {
+		return
+	}
+
+	var eventType string
+	switch to {
+	case gobreaker.StateOpen:
+		eventType = event.TypeCircuitBreakerOpened
+	case gobreaker.StateClosed:
+		eventType = event.TypeCircuitBreakerClosed
+	default:
+		return
+	}
+
+	events.Publish(context.Background(), eventType, map[string]string{
+		"host":       host,
+		"from_state": from.String(),
+		"to_state":   to.String(),
+	})
+}
+
+// trackStateChange captures a breaker opening, as the provider behind it
+// failed repeatedly
+func trackStateChange(tracker errortracking.Tracker, host string, from gobreaker.State, to gobreaker.State) {
+	if tracker == nil || to != gobreaker.StateOpen {
+		return
+	}
+
+	tracker.Capture(context.Background(), errortracking.Event{
+		Level:   errortracking.LevelWarning,
+		Message: "circuit breaker opened after repeated provider failures",
+		Tags: map[string]string{
+			"host":       host,
+			"from_state": from.String(),
+		},
+	})
+}
+
+// persistStateChange queues a trip to record when a breaker opens and to
+// forget when the breaker closes; half-open probing keeps the trip. It never
+// blocks: a change arriving while the queue is full is dropped and logged,
+// as the breaker works the same without it
+func (r *CircuitBreakerRegistry) persistStateChange(host string, to gobreaker.State) {
+	if r.trips == nil || to == gobreaker.StateHalfOpen {
+		return
+	}
+
+	select {
+	case r.changes <- stateChange{host: host, to: to, at: r.clock.Now(), generation: r.generation(host).Load()}:
+	default:
+		r.logger.Warn("dropped circuit breaker state change, the persist queue is full",
+			zap.String("host", host),
+			zap.String("state", to.String()),
+		)
+	}
+}
+
+// generation returns the reset count of host
+func (r *CircuitBreakerRegistry) generation(host string) *atomic.Uint64 {
+	if value, ok := r.generations.Load(host); ok {
+		return value.(*atomic.Uint64)
+	}
+	value, _ := r.generations.LoadOrStore(host, &atomic.Uint64{})
+	return value.(*atomic.Uint64)
+}
+
+// writeStateChange persists a queued state change, unless the host was
+// reset since it was queued. A failed write is logged, as the breaker works
+// the same without it
+func (r *CircuitBreakerRegistry) writeStateChange(change stateChange) {
+	r.resetting.Lock()
+	defer r.resetting.Unlock()
+	if change.generation != r.generation(change.host).Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	var err error
+	if change.to == gobreaker.StateOpen {
+		err = r.trips.RecordCircuitBreakerTrip(ctx, change.host, change.at)
+	} else {
+		err = r.trips.ClearCircuitBreakerTrip(ctx, change.host)
+	}
+	if err != nil {
+		logging.From(ctx, r.logger).Warn("failed to persist circuit breaker state",
+			zap.String("host", change.host),
+			zap.String("state", change.to.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// StartPersisting writes the queued state changes in order until
+// StopPersisting
+func (r *CircuitBreakerRegistry) StartPersisting() {
+	if r.trips == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancelPersist = cancel
+
+	r.persisting.Add(1)
+	go func() {
+		defer r.persisting.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case change := <-r.changes:
+				r.writeStateChange(change)
+			}
+		}
+	}()
+}
+
+// StopPersisting ends the writer and writes the changes still queued, until
+// ctx ends
+func (r *CircuitBreakerRegistry) StopPersisting(ctx context.Context) error {
+	if r.cancelPersist == nil {
+		return nil
+	}
+	r.cancelPersist()
+	r.persisting.Wait()
+
+	for {
+		select {
+		case change := <-r.changes:
+			r.writeStateChange(change)
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			return nil
+		}
+	}
+}
+
+// Restore holds open the hosts whose breaker opened within OpenStateTimeout,
+// on this or another instance, until the timeout of that trip ends, and
+// releases the held hosts whose trip was reset since
+func (r *CircuitBreakerRegistry) Restore(ctx context.Context) error {
+	if r.trips == nil {
+		return nil
+	}
+
+	now := r.clock.Now()
+	trips, err := r.trips.FindCircuitBreakerTrips(ctx, now.Add(-r.settings.Timeout))
+	if err != nil {
+		return err
+	}
+
+	tripped := make(map[string]bool, len(trips))
+	for _, trip := range trips {
+		tripped[trip.Host] = true
+		until := trip.OpenedAt.Add(r.settings.Timeout)
+		if !until.After(now) {
+			continue
+		}
+		if held, ok := r.held.Load(trip.Host); ok && !until.After(held.(time.Time)) {
+			continue
+		}
+
+		logging.From(ctx, r.logger).Info("holding circuit breaker open after a recorded trip",
+			zap.String("host", trip.Host),
+			zap.Time("opened_at", trip.OpenedAt),
+			zap.Time("until", until),
+		)
+		r.held.Store(trip.Host, until)
+		r.GetOrCreate(trip.Host)
+	}
+
+	// A host held open without a trip left was reset on another instance
+	r.held.Range(func(key, value any) bool {
+		host := key.(string)
+		if tripped[host] || !r.held.CompareAndDelete(host, value) {
+			return true
+		}
+
+		logging.From(ctx, r.logger).Info("releasing circuit breaker whose recorded trip was reset",
+			zap.String("host", host),
+		)
+		return true
+	})
+
+	return nil
+}
+
+// heldOpen tells whether host is still held open by a restored trip
+func (r *CircuitBreakerRegistry) heldOpen(host string) bool {
+	value, ok := r.held.Load(host)
+	if !ok {
+		return false
+	}
+	if r.clock.Now().Before(value.(time.Time)) {
+		return true
+	}
+
+	r.held.CompareAndDelete(host, value)
+	return false
 }
 
 func (r *CircuitBreakerRegistry) GetOrCreate(host string) *gobreaker.CircuitBreaker[CircuitBreakerResponse] {
@@ -71,11 +402,130 @@ func (r *CircuitBreakerRegistry) GetOrCreate(host string) *gobreaker.CircuitBrea
 		zap.String("host", host),
 	)
 
-	settings := r.settings
-	settings.Name = host
-
-	cb := gobreaker.NewCircuitBreaker[CircuitBreakerResponse](settings)
+	cb := gobreaker.NewCircuitBreaker[CircuitBreakerResponse](r.settingsFor(host))
 
 	actual, _ := r.breakers.LoadOrStore(host, cb)
 	return actual.(*gobreaker.CircuitBreaker[CircuitBreakerResponse])
 }
+
+// Reset replaces the breaker of host with a closed one without counts and
+// returns the state it had; ok is false when host has no breaker. Clearing
+// the recorded trip releases the host on other instances at their next Sync;
+// state changes still queued for host are dropped
+func (r *CircuitBreakerRegistry) Reset(host string) (previous metrics.CircuitBreakerSnapshot, ok bool) {
+	value, ok := r.breakers.Load(host)
+	if !ok {
+		return metrics.CircuitBreakerSnapshot{}, false
+	}
+
+	// Waits for a write in progress, and moves past the changes queued
+	// before the new breaker can queue its own
+	r.resetting.Lock()
+	defer r.resetting.Unlock()
+	r.generation(host).Add(1)
+
+	r.logger.Info("resetting circuit breaker",
+		zap.String("host", host),
+	)
+
+	previous = r.snapshot(host, value.(*gobreaker.CircuitBreaker[CircuitBreakerResponse]))
+
+	r.breakers.Store(host, gobreaker.NewCircuitBreaker[CircuitBreakerResponse](r.settingsFor(host)))
+	r.held.Delete(host)
+
+	if r.trips != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+		defer cancel()
+
+		if err := r.trips.ClearCircuitBreakerTrip(ctx, host); err != nil {
+			logging.From(ctx, r.logger).Warn("failed to clear persisted circuit breaker trip",
+				zap.String("host", host),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return previous, true
+}
+
+// Snapshot returns the current state and counts of every registered breaker
+func (r *CircuitBreakerRegistry) Snapshot() []metrics.CircuitBreakerSnapshot {
+	var snapshots []metrics.CircuitBreakerSnapshot
+	r.breakers.Range(func(key, value any) bool {
+		snapshots = append(snapshots, r.snapshot(key.(string), value.(*gobreaker.CircuitBreaker[CircuitBreakerResponse])))
+		return true
+	})
+
+	return snapshots
+}
+
+// snapshot reports a host held open by a restored trip as open
+func (r *CircuitBreakerRegistry) snapshot(host string, cb *gobreaker.CircuitBreaker[CircuitBreakerResponse]) metrics.CircuitBreakerSnapshot {
+	counts := cb.Counts()
+
+	state := cb.State()
+	if r.heldOpen(host) {
+		state = gobreaker.StateOpen
+	}
+
+	return metrics.CircuitBreakerSnapshot{
+		Host:                 host,
+		State:                state.String(),
+		Requests:             counts.Requests,
+		TotalSuccesses:       counts.TotalSuccesses,
+		TotalFailures:        counts.TotalFailures,
+		ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+		ConsecutiveFailures:  counts.ConsecutiveFailures,
+	}
+}
+
+type CircuitBreakerMetricsParams struct {
+	fx.In
+
+	Lifecycle        fx.Lifecycle
+	Registry         *CircuitBreakerRegistry
+	MetricsCollector *metrics.HTTPClientCollector
+}
+
+type CircuitBreakerRestoreParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Registry  *CircuitBreakerRegistry
+	Logger    *zap.Logger
+}
+
+// RestoreCircuitBreakers reopens recent trips on start, then persists the
+// state changes until the application stops. Without the trips breakers
+// start closed as before, so a failed load does not stop the application
+func RestoreCircuitBreakers(params CircuitBreakerRestoreParams) {
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := params.Registry.Restore(ctx); err != nil {
+				params.Logger.Warn("failed to restore circuit breaker trips",
+					zap.Error(err),
+				)
+			}
+			params.Registry.StartPersisting()
+			return nil
+		},
+		OnStop: params.Registry.StopPersisting,
+	})
+}
+
+// RegisterCircuitBreakerMetrics exports every breaker in the registry through
+// observable gauges until the application stops
+func RegisterCircuitBreakerMetrics(params CircuitBreakerMetricsParams) error {
+	registration, err := params.MetricsCollector.ObserveCircuitBreakers(params.Registry.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return registration.Unregister()
+		},
+	})
+
+	return nil
+}
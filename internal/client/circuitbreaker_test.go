@@ -1,17 +1,36 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"sync"
 	"testing"
 	"time"
 
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	mockerrortracking "github.com/koungkub/fw-challenge-notification-service/internal/errortracking/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	mockevent "github.com/koungkub/fw-challenge-notification-service/internal/event/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
 	"github.com/sony/gobreaker/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 )
 
+// testCircuitBreakerRegistryConfig matches the documented defaults
+var testCircuitBreakerRegistryConfig = CircuitBreakerRegistryConfig{
+	MaxHalfOpenRequests:     5,
+	OpenStateTimeout:        60 * time.Second,
+	MinRequestsBeforeTrip:   3,
+	FailureThresholdPercent: 60,
+}
+
 func TestNewCircuitBreakerRegistry(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -153,6 +172,317 @@ func TestCircuitBreakerRegistry_ReadyToTrip(t *testing.T) {
 	}
 }
 
+func TestIsSuccessful(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"no error", nil, true},
+		{"transport error", errors.New("connection refused"), false},
+		{"read timeout", context.DeadlineExceeded, false},
+		{"caller cancelled", fmt.Errorf("post: %w", context.Canceled), true},
+		{"server error", &ProviderError{StatusCode: http.StatusBadGateway}, false},
+		{"rejected request", &ProviderError{StatusCode: http.StatusBadRequest}, true},
+		{"rate limited", &ProviderError{StatusCode: http.StatusTooManyRequests, Throttled: true}, true},
+		{"unavailable with retry after", &ProviderError{StatusCode: http.StatusServiceUnavailable, Throttled: true}, true},
+		{"unavailable without retry after", &ProviderError{StatusCode: http.StatusServiceUnavailable}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isSuccessful(tt.err))
+		})
+	}
+}
+
+func TestCircuitBreakerRegistry_SetTripThresholds(t *testing.T) {
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: testCircuitBreakerRegistryConfig,
+		Logger: zap.NewNop(),
+	})
+	counts := gobreaker.Counts{Requests: 4, TotalFailures: 2}
+
+	require.False(t, registry.settings.ReadyToTrip(counts), "50% failure rate is below the 60% default")
+
+	registry.SetTripThresholds(TripThresholds{MinRequests: 4, FailureRatioPercent: 50})
+
+	assert.True(t, registry.settings.ReadyToTrip(counts))
+}
+
+func TestCircuitBreakerRegistry_Reset(t *testing.T) {
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: testCircuitBreakerRegistryConfig,
+		Logger: zap.NewNop(),
+	})
+	host := "api.example.com"
+
+	_, ok := registry.Reset(host)
+	require.False(t, ok, "unknown host has nothing to reset")
+
+	for range 3 {
+		registry.GetOrCreate(host).Execute(func() (CircuitBreakerResponse, error) {
+			return CircuitBreakerResponse{}, errors.New("provider down")
+		})
+	}
+	require.Equal(t, gobreaker.StateOpen, registry.GetOrCreate(host).State())
+
+	previous, ok := registry.Reset(host)
+
+	require.True(t, ok)
+	assert.Equal(t, "open", previous.State)
+	assert.Equal(t, gobreaker.StateClosed, registry.GetOrCreate(host).State())
+	assert.Equal(t, uint32(0), registry.GetOrCreate(host).Counts().Requests)
+}
+
+func TestCircuitBreakerRegistry_PersistState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(now).AnyTimes()
+
+	trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: CircuitBreakerRegistryConfig{
+			MaxHalfOpenRequests:     1,
+			OpenStateTimeout:        10 * time.Millisecond,
+			MinRequestsBeforeTrip:   1,
+			FailureThresholdPercent: 50,
+			PersistState:            true,
+		},
+		Trips:  trips,
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+
+	gomock.InOrder(
+		trips.EXPECT().RecordCircuitBreakerTrip(gomock.Any(), "api.example.com", now).Return(errors.New("database down")),
+		trips.EXPECT().ClearCircuitBreakerTrip(gomock.Any(), "api.example.com").Return(nil),
+	)
+	registry.StartPersisting()
+
+	cb := registry.GetOrCreate("api.example.com")
+	_, _ = cb.Execute(func() (CircuitBreakerResponse, error) {
+		return CircuitBreakerResponse{}, errors.New("provider down")
+	})
+	require.Equal(t, gobreaker.StateOpen, cb.State(), "a failed write does not change the breaker")
+
+	time.Sleep(20 * time.Millisecond)
+	_, err := cb.Execute(func() (CircuitBreakerResponse, error) {
+		return CircuitBreakerResponse{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, gobreaker.StateClosed, cb.State())
+	// The changes are written in the background, at the latest on stop
+	require.NoError(t, registry.StopPersisting(context.Background()))
+}
+
+func TestCircuitBreakerRegistry_persistStateChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).AnyTimes()
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: CircuitBreakerRegistryConfig{PersistState: true},
+		Trips:  mockrepository.NewMockCircuitBreakerTripProvider(ctrl),
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+
+	registry.persistStateChange("api.example.com", gobreaker.StateHalfOpen)
+	assert.Empty(t, registry.changes, "half-open probing keeps the trip")
+
+	// Nothing writes, so the queue fills up and further changes are
+	// dropped rather than blocking the breaker
+	for range stateChangeBuffer + 1 {
+		registry.persistStateChange("api.example.com", gobreaker.StateOpen)
+	}
+	assert.Len(t, registry.changes, stateChangeBuffer)
+}
+
+func TestCircuitBreakerRegistry_Reset_DropsQueuedChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(now).AnyTimes()
+
+	// Only the trip queued after the reset is recorded
+	trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+	gomock.InOrder(
+		trips.EXPECT().ClearCircuitBreakerTrip(gomock.Any(), "api.example.com").Return(nil),
+		trips.EXPECT().RecordCircuitBreakerTrip(gomock.Any(), "api.example.com", now).Return(nil),
+	)
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: CircuitBreakerRegistryConfig{PersistState: true},
+		Trips:  trips,
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+	registry.GetOrCreate("api.example.com")
+
+	registry.persistStateChange("api.example.com", gobreaker.StateOpen)
+	_, ok := registry.Reset("api.example.com")
+	require.True(t, ok)
+	registry.persistStateChange("api.example.com", gobreaker.StateOpen)
+
+	registry.StartPersisting()
+	require.NoError(t, registry.StopPersisting(context.Background()))
+}
+
+func TestCircuitBreakerRegistry_Restore(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	host := "api.example.com"
+
+	tests := []struct {
+		name          string
+		persistState  bool
+		held          bool
+		setupMocks    func(*mockrepository.MockCircuitBreakerTripProvider)
+		expectedError bool
+		expectedHeld  bool
+	}{
+		{
+			name:         "holds open a host tripped within the open state timeout",
+			persistState: true,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), now.Add(-60*time.Second)).Return([]repository.CircuitBreakerTrip{
+					{Host: host, OpenedAt: now.Add(-20 * time.Second)},
+				}, nil)
+			},
+			expectedHeld: true,
+		},
+		{
+			name:         "ignores a trip whose timeout ended",
+			persistState: true,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return([]repository.CircuitBreakerTrip{
+					{Host: host, OpenedAt: now.Add(-60 * time.Second)},
+				}, nil)
+			},
+		},
+		{
+			name:         "releases a held host whose trip was reset on another instance",
+			persistState: true,
+			held:         true,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:         "returns the error of the lookup",
+			persistState: true,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, errors.New("database down"))
+			},
+			expectedError: true,
+		},
+		{
+			name:         "keeps held hosts when the lookup fails",
+			persistState: true,
+			held:         true,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, errors.New("database down"))
+			},
+			expectedError: true,
+			expectedHeld:  true,
+		},
+		{
+			name:       "restores nothing unless persisting state",
+			setupMocks: func(*mockrepository.MockCircuitBreakerTripProvider) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clock := mockclock.NewMockClock(ctrl)
+			clock.EXPECT().Now().Return(now).AnyTimes()
+
+			trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+			tt.setupMocks(trips)
+
+			config := testCircuitBreakerRegistryConfig
+			config.PersistState = tt.persistState
+			registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+				Config: config,
+				Trips:  trips,
+				Clock:  clock,
+				Logger: zap.NewNop(),
+			})
+			if tt.held {
+				registry.GetOrCreate(host)
+				registry.held.Store(host, now.Add(30*time.Second))
+			}
+
+			err := registry.Restore(context.Background())
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedHeld, registry.heldOpen(host))
+			if tt.expectedHeld {
+				snapshots := registry.Snapshot()
+				require.Len(t, snapshots, 1)
+				assert.Equal(t, "open", snapshots[0].State)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerRegistry_heldOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := mockclock.NewMockClock(ctrl)
+	trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+
+	config := testCircuitBreakerRegistryConfig
+	config.PersistState = true
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: config,
+		Trips:  trips,
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+
+	clock.EXPECT().Now().Return(now)
+	trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return([]repository.CircuitBreakerTrip{
+		{Host: "api.example.com", OpenedAt: now.Add(-50 * time.Second)},
+		{Host: "api2.example.com", OpenedAt: now.Add(-50 * time.Second)},
+	}, nil)
+	require.NoError(t, registry.Restore(context.Background()))
+
+	t.Run("releases the host once the trip times out", func(t *testing.T) {
+		clock.EXPECT().Now().Return(now.Add(5 * time.Second))
+		assert.True(t, registry.heldOpen("api.example.com"))
+
+		clock.EXPECT().Now().Return(now.Add(10 * time.Second))
+		assert.False(t, registry.heldOpen("api.example.com"))
+		assert.False(t, registry.heldOpen("api.example.com"), "an expired hold is forgotten")
+	})
+
+	t.Run("reset releases the host and forgets its trip", func(t *testing.T) {
+		clock.EXPECT().Now().Return(now).AnyTimes()
+		trips.EXPECT().ClearCircuitBreakerTrip(gomock.Any(), "api2.example.com").Return(nil)
+
+		previous, ok := registry.Reset("api2.example.com")
+
+		require.True(t, ok)
+		assert.Equal(t, "open", previous.State)
+		assert.False(t, registry.heldOpen("api2.example.com"))
+	})
+}
+
 func TestCircuitBreakerRegistry_GetOrCreate(t *testing.T) {
 	t.Run("creates new circuit breaker for new host", func(t *testing.T) {
 		registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
@@ -214,6 +544,97 @@ func TestCircuitBreakerRegistry_GetOrCreate(t *testing.T) {
 	})
 }
 
+func TestCircuitBreakerRegistry_Snapshot(t *testing.T) {
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: CircuitBreakerRegistryConfig{
+			MaxHalfOpenRequests:     5,
+			OpenStateTimeout:        60 * time.Second,
+			MinRequestsBeforeTrip:   3,
+			FailureThresholdPercent: 60,
+		},
+		Logger: zap.NewNop(),
+	})
+
+	assert.Empty(t, registry.Snapshot())
+
+	cb := registry.GetOrCreate("api.example.com")
+	_, _ = cb.Execute(func() (CircuitBreakerResponse, error) {
+		return CircuitBreakerResponse{}, errors.New("provider down")
+	})
+	registry.GetOrCreate("api2.example.com")
+
+	snapshots := registry.Snapshot()
+	require.Len(t, snapshots, 2)
+
+	byHost := map[string]int{}
+	for i, snapshot := range snapshots {
+		byHost[snapshot.Host] = i
+	}
+
+	failing := snapshots[byHost["api.example.com"]]
+	assert.Equal(t, "closed", failing.State)
+	assert.Equal(t, uint32(1), failing.Requests)
+	assert.Equal(t, uint32(1), failing.TotalFailures)
+	assert.Equal(t, uint32(1), failing.ConsecutiveFailures)
+
+	idle := snapshots[byHost["api2.example.com"]]
+	assert.Equal(t, "closed", idle.State)
+	assert.Equal(t, uint32(0), idle.Requests)
+}
+
+func TestCircuitBreakerRegistry_StateChangeEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := mockevent.NewMockPublisher(ctrl)
+	tracker := mockerrortracking.NewMockTracker(ctrl)
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: CircuitBreakerRegistryConfig{
+			MaxHalfOpenRequests:     1,
+			OpenStateTimeout:        10 * time.Millisecond,
+			MinRequestsBeforeTrip:   1,
+			FailureThresholdPercent: 50,
+		},
+		Events:  events,
+		Tracker: tracker,
+		Logger:  zap.NewNop(),
+	})
+
+	// Only the opening is captured, as the provider failed repeatedly
+	tracker.EXPECT().Capture(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, evt errortracking.Event) {
+			assert.Equal(t, errortracking.LevelWarning, evt.Level)
+			assert.Equal(t, "api.example.com", evt.Tags["host"])
+		})
+
+	gomock.InOrder(
+		events.EXPECT().Publish(gomock.Any(), event.TypeCircuitBreakerOpened, map[string]string{
+			"host":       "api.example.com",
+			"from_state": "closed",
+			"to_state":   "open",
+		}),
+		events.EXPECT().Publish(gomock.Any(), event.TypeCircuitBreakerClosed, map[string]string{
+			"host":       "api.example.com",
+			"from_state": "half-open",
+			"to_state":   "closed",
+		}),
+	)
+
+	cb := registry.GetOrCreate("api.example.com")
+	_, _ = cb.Execute(func() (CircuitBreakerResponse, error) {
+		return CircuitBreakerResponse{}, errors.New("provider down")
+	})
+	require.Equal(t, gobreaker.StateOpen, cb.State())
+
+	// Half-open is reached after the open timeout and is not published
+	time.Sleep(20 * time.Millisecond)
+	_, err := cb.Execute(func() (CircuitBreakerResponse, error) {
+		return CircuitBreakerResponse{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, gobreaker.StateClosed, cb.State())
+}
+
 func TestCircuitBreakerRegistry_Concurrency(t *testing.T) {
 	t.Run("concurrent access to GetOrCreate is safe", func(t *testing.T) {
 		registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
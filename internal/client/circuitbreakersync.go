@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/sony/gobreaker/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// circuitBreakerTally counts the outcomes of one host not yet shared
+type circuitBreakerTally struct {
+	requests atomic.Int64
+	failures atomic.Int64
+}
+
+// settingsFor returns the breaker settings of host, which tally every
+// outcome for the other instances with SharedState
+func (r *CircuitBreakerRegistry) settingsFor(host string) gobreaker.Settings {
+	settings := r.settings
+	settings.Name = host
+
+	if r.tallies != nil {
+		settings.IsSuccessful = func(err error) bool {
+			successful := isSuccessful(err)
+
+			value, _ := r.tallies.LoadOrStore(host, &circuitBreakerTally{})
+			tally := value.(*circuitBreakerTally)
+			tally.requests.Add(1)
+			if !successful {
+				tally.failures.Add(1)
+			}
+			return successful
+		}
+	}
+
+	return settings
+}
+
+// Sync shares the outcomes counted since the last sync, trips the hosts
+// whose outcomes across every instance reach the trip thresholds, and holds
+// open the hosts other instances tripped
+func (r *CircuitBreakerRegistry) Sync(ctx context.Context) error {
+	if r.tallies == nil {
+		return nil
+	}
+
+	// Trips of other instances come first, so a host they already tripped
+	// is not tripped again
+	err := r.Restore(ctx)
+
+	now := r.clock.Now()
+	windowStart := now.Truncate(r.sharedWindow)
+
+	r.tallies.Range(func(key, value any) bool {
+		host := key.(string)
+		tally := value.(*circuitBreakerTally)
+
+		requests := tally.requests.Swap(0)
+		failures := tally.failures.Swap(0)
+		if requests == 0 {
+			return true
+		}
+
+		counts, addErr := r.trips.AddCircuitBreakerCounts(ctx, host, windowStart, requests, failures)
+		if addErr != nil {
+			err = errors.Join(err, addErr)
+			return true
+		}
+
+		shared := gobreaker.Counts{
+			Requests:      uint32(counts.Requests),
+			TotalFailures: uint32(counts.Failures),
+		}
+		if counts.Requests == 0 || !r.readyToTrip(shared) || r.heldOpen(host) ||
+			r.GetOrCreate(host).State() == gobreaker.StateOpen {
+			return true
+		}
+
+		r.tripShared(ctx, host, now, counts.Requests, counts.Failures)
+		return true
+	})
+
+	return err
+}
+
+// tripShared holds host open for OpenStateTimeout and records the trip for
+// the other instances to hold it open too
+func (r *CircuitBreakerRegistry) tripShared(ctx context.Context, host string, now time.Time, requests int64, failures int64) {
+	logging.From(ctx, r.logger).Warn("circuit breaker tripped by the outcomes of every instance",
+		zap.String("host", host),
+		zap.Int64("requests", requests),
+		zap.Int64("failures", failures),
+	)
+
+	r.held.Store(host, now.Add(r.settings.Timeout))
+	publishStateChange(r.events, host, gobreaker.StateClosed, gobreaker.StateOpen)
+	trackStateChange(r.tracker, host, gobreaker.StateClosed, gobreaker.StateOpen)
+
+	if err := r.trips.RecordCircuitBreakerTrip(ctx, host, now); err != nil {
+		logging.From(ctx, r.logger).Warn("failed to persist circuit breaker state",
+			zap.String("host", host),
+			zap.String("state", gobreaker.StateOpen.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// StartSync syncs every interval until StopSync
+func (r *CircuitBreakerRegistry) StartSync(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancelSync = cancel
+
+	r.syncing.Add(1)
+	go func() {
+		defer r.syncing.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.clock.After(interval):
+			}
+
+			if err := r.Sync(ctx); err != nil && ctx.Err() == nil {
+				logging.From(ctx, r.logger).Warn("failed to sync circuit breakers", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// StopSync ends syncing and waits for the sync in progress to finish or ctx
+// to end
+func (r *CircuitBreakerRegistry) StopSync(ctx context.Context) error {
+	if r.cancelSync == nil {
+		return nil
+	}
+	r.cancelSync()
+
+	done := make(chan struct{})
+	go func() {
+		r.syncing.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type CircuitBreakerSyncParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    CircuitBreakerRegistryConfig
+	Registry  *CircuitBreakerRegistry
+}
+
+// SyncCircuitBreakers shares breaker outcomes between instances while the
+// application runs, when SharedState is set
+func SyncCircuitBreakers(params CircuitBreakerSyncParams) error {
+	if !params.Config.SharedState {
+		return nil
+	}
+	if params.Config.SyncInterval <= 0 || params.Config.SharedWindow <= 0 {
+		return errors.New("circuit breaker sync interval and shared window must be positive")
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			params.Registry.StartSync(params.Config.SyncInterval)
+			return nil
+		},
+		OnStop: params.Registry.StopSync,
+	})
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	mockevent "github.com/koungkub/fw-challenge-notification-service/internal/event/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/sony/gobreaker/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestCircuitBreakerRegistry_Sync(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 7, 0, time.UTC)
+	windowStart := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	host := "api.example.com"
+
+	tests := []struct {
+		name          string
+		failures      int
+		setupMocks    func(*mockrepository.MockCircuitBreakerTripProvider, *mockevent.MockPublisher)
+		expectedError bool
+		expectedHeld  bool
+	}{
+		{
+			name:     "trips the host once the outcomes of every instance reach the thresholds",
+			failures: 1,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, events *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil)
+				trips.EXPECT().AddCircuitBreakerCounts(gomock.Any(), host, windowStart, int64(1), int64(1)).
+					Return(repository.CircuitBreakerCounts{Host: host, WindowStart: windowStart, Requests: 4, Failures: 3}, nil)
+				events.EXPECT().Publish(gomock.Any(), event.TypeCircuitBreakerOpened, map[string]string{
+					"host":       host,
+					"from_state": "closed",
+					"to_state":   "open",
+				})
+				trips.EXPECT().RecordCircuitBreakerTrip(gomock.Any(), host, now).Return(nil)
+			},
+			expectedHeld: true,
+		},
+		{
+			name:     "keeps the host closed below the thresholds",
+			failures: 1,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, _ *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil)
+				trips.EXPECT().AddCircuitBreakerCounts(gomock.Any(), host, windowStart, int64(1), int64(1)).
+					Return(repository.CircuitBreakerCounts{Host: host, WindowStart: windowStart, Requests: 10, Failures: 2}, nil)
+			},
+		},
+		{
+			name:     "drops counts of a window already replaced",
+			failures: 1,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, _ *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil)
+				trips.EXPECT().AddCircuitBreakerCounts(gomock.Any(), host, windowStart, int64(1), int64(1)).
+					Return(repository.CircuitBreakerCounts{Host: host}, nil)
+			},
+		},
+		{
+			name: "shares nothing without new outcomes",
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, _ *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name: "holds open a host another instance tripped",
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, _ *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), now.Add(-60*time.Second)).Return([]repository.CircuitBreakerTrip{
+					{Host: host, OpenedAt: now.Add(-time.Second)},
+				}, nil)
+			},
+			expectedHeld: true,
+		},
+		{
+			name:     "returns the errors of the store",
+			failures: 1,
+			setupMocks: func(trips *mockrepository.MockCircuitBreakerTripProvider, _ *mockevent.MockPublisher) {
+				trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, errors.New("database down"))
+				trips.EXPECT().AddCircuitBreakerCounts(gomock.Any(), host, windowStart, int64(1), int64(1)).
+					Return(repository.CircuitBreakerCounts{}, errors.New("database down"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clock := mockclock.NewMockClock(ctrl)
+			clock.EXPECT().Now().Return(now).AnyTimes()
+
+			trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+			events := mockevent.NewMockPublisher(ctrl)
+			tt.setupMocks(trips, events)
+
+			config := testCircuitBreakerRegistryConfig
+			config.SharedState = true
+			config.SharedWindow = 10 * time.Second
+			registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+				Config: config,
+				Events: events,
+				Trips:  trips,
+				Clock:  clock,
+				Logger: zap.NewNop(),
+			})
+
+			// Fewer failures than a breaker of this instance alone trips on
+			for range tt.failures {
+				_, _ = registry.GetOrCreate(host).Execute(func() (CircuitBreakerResponse, error) {
+					return CircuitBreakerResponse{}, errors.New("provider down")
+				})
+			}
+			require.Equal(t, gobreaker.StateClosed, registry.GetOrCreate(host).State())
+
+			err := registry.Sync(context.Background())
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedHeld, registry.heldOpen(host))
+		})
+	}
+}
+
+func TestCircuitBreakerRegistry_Sync_SharesOutcomesOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(now).AnyTimes()
+
+	trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+	trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	trips.EXPECT().AddCircuitBreakerCounts(gomock.Any(), "api.example.com", now, int64(2), int64(0)).
+		Return(repository.CircuitBreakerCounts{Requests: 2}, nil)
+
+	config := testCircuitBreakerRegistryConfig
+	config.SharedState = true
+	config.SharedWindow = 10 * time.Second
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: config,
+		Trips:  trips,
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+
+	for range 2 {
+		_, _ = registry.GetOrCreate("api.example.com").Execute(func() (CircuitBreakerResponse, error) {
+			return CircuitBreakerResponse{}, nil
+		})
+	}
+
+	require.NoError(t, registry.Sync(context.Background()))
+	require.NoError(t, registry.Sync(context.Background()))
+}
+
+func TestSyncCircuitBreakers(t *testing.T) {
+	t.Run("does nothing without shared state", func(t *testing.T) {
+		assert.NoError(t, SyncCircuitBreakers(CircuitBreakerSyncParams{}))
+	})
+
+	t.Run("rejects a sync interval that is not positive", func(t *testing.T) {
+		err := SyncCircuitBreakers(CircuitBreakerSyncParams{
+			Config: CircuitBreakerRegistryConfig{SharedState: true, SharedWindow: 10 * time.Second},
+		})
+		assert.EqualError(t, err, "circuit breaker sync interval and shared window must be positive")
+	})
+
+	t.Run("syncs while the application runs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		synced := make(chan struct{})
+		clock := mockclock.NewMockClock(ctrl)
+		clock.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).AnyTimes()
+		clock.EXPECT().After(time.Second).DoAndReturn(func(time.Duration) <-chan time.Time {
+			ch := make(chan time.Time, 1)
+			select {
+			case <-synced:
+			default:
+				ch <- time.Time{}
+			}
+			return ch
+		}).AnyTimes()
+
+		trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+		trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, time.Time) ([]repository.CircuitBreakerTrip, error) {
+				close(synced)
+				return nil, nil
+			},
+		)
+
+		config := testCircuitBreakerRegistryConfig
+		config.SharedState = true
+		config.SyncInterval = time.Second
+		config.SharedWindow = 10 * time.Second
+
+		lc := fxtest.NewLifecycle(t)
+		require.NoError(t, SyncCircuitBreakers(CircuitBreakerSyncParams{
+			Lifecycle: lc,
+			Config:    config,
+			Registry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+				Config: config,
+				Trips:  trips,
+				Clock:  clock,
+				Logger: zap.NewNop(),
+			}),
+		}))
+
+		lc.RequireStart()
+		<-synced
+		lc.RequireStop()
+	})
+}
@@ -6,12 +6,18 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
 	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/sony/gobreaker/v2"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -27,46 +33,66 @@ type HTTPClient struct {
 	httpclient             *http.Client
 	circuitBreakerRegistry *CircuitBreakerRegistry
 	metricsCollector       *metrics.HTTPClientCollector
+	signer                 *JWTSigner
+	clock                  clock.Clock
 	logger                 *zap.Logger
+	maxRetryAfter          time.Duration
 }
 
 type HTTPClientConfig struct {
-	Timeout time.Duration `envconfig:"HTTP_CLIENT_TIMEOUT" default:"5s"`
+	// Timeout bounds the whole request, the phases below included
+	Timeout       time.Duration `envconfig:"HTTP_CLIENT_TIMEOUT" default:"5s"`
+	MaxRetryAfter time.Duration `envconfig:"HTTP_CLIENT_MAX_RETRY_AFTER" default:"0s"`
+	// DialTimeout bounds DNS resolution and the TCP connect; zero leaves
+	// only Timeout
+	DialTimeout           time.Duration `envconfig:"HTTP_CLIENT_DIAL_TIMEOUT" default:"2s"`
+	TLSHandshakeTimeout   time.Duration `envconfig:"HTTP_CLIENT_TLS_HANDSHAKE_TIMEOUT" default:"3s"`
+	ResponseHeaderTimeout time.Duration `envconfig:"HTTP_CLIENT_RESPONSE_HEADER_TIMEOUT" default:"0s"`
 }
 
 type HTTPClientParams struct {
 	fx.In
 
 	Config                 HTTPClientConfig
+	Faults                 FaultConfig
 	CircuitBreakerRegistry *CircuitBreakerRegistry
 	MetricsCollector       *metrics.HTTPClientCollector
+	Signer                 *JWTSigner
+	Clock                  clock.Clock
 	Logger                 *zap.Logger
 }
 
 func NewHTTPClient(params HTTPClientParams) *HTTPClient {
+	var transport http.RoundTripper = newTransport(params.Config)
+	if params.Faults.Enabled {
+		params.Logger.Warn("fault injection enabled, provider requests fail on purpose",
+			zap.Float64("latency_rate", params.Faults.LatencyRate),
+			zap.Duration("max_latency", params.Faults.MaxLatency),
+			zap.Float64("error_rate", params.Faults.ErrorRate),
+			zap.Int("error_status_code", params.Faults.ErrorStatusCode),
+			zap.Strings("failing_hosts", params.Faults.FailingHosts),
+		)
+		transport = newFaultTransport(transport, params.Faults, params.Clock, params.MetricsCollector)
+	}
+
 	return &HTTPClient{
 		httpclient: &http.Client{
-			Timeout: params.Config.Timeout,
+			Timeout:   params.Config.Timeout,
+			Transport: transport,
 		},
 		circuitBreakerRegistry: params.CircuitBreakerRegistry,
 		metricsCollector:       params.MetricsCollector,
+		signer:                 params.Signer,
+		clock:                  params.Clock,
 		logger:                 params.Logger,
+		maxRetryAfter:          params.Config.MaxRetryAfter,
 	}
 }
 
-func NewHTTPClientConfig() HTTPClientConfig {
-	var cfg HTTPClientConfig
-	envconfig.MustProcess("", &cfg)
-
-	return cfg
-}
-
 func (c *HTTPClient) Post(ctx context.Context, u string, reqBody NotificationRequest) error {
-	start := time.Now()
-
 	host, err := extractHost(u)
 	if err != nil {
-		c.logger.Error("failed to extract host from URL",
+		logging.From(ctx, c.logger).Error("failed to extract host from URL",
 			zap.String("url", u),
 			zap.Error(err),
 		)
@@ -75,42 +101,161 @@ func (c *HTTPClient) Post(ctx context.Context, u string, reqBody NotificationReq
 
 	circuitBreaker := c.circuitBreakerRegistry.GetOrCreate(host)
 
-	cbState := circuitBreaker.State().String()
-	c.metricsCollector.RecordCircuitBreakerState(ctx, host, cbState)
+	if c.circuitBreakerRegistry.heldOpen(host) {
+		c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, 0, 0, gobreaker.ErrOpenState)
+		logging.From(ctx, c.logger).Warn("circuit breaker held open by a recent trip",
+			zap.String("host", host),
+			metadataField(ctx),
+		)
+		return gobreaker.ErrOpenState
+	}
 
-	c.logger.Debug("circuit breaker state checked",
+	logging.From(ctx, c.logger).Debug("circuit breaker state checked",
 		zap.String("host", host),
-		zap.String("state", cbState),
+		zap.String("state", circuitBreaker.State().String()),
 	)
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		c.logger.Error("failed to marshal request body",
+		logging.From(ctx, c.logger).Error("failed to marshal request body",
 			zap.String("host", host),
 			zap.Error(err),
 		)
 		return err
 	}
 
+	var authorization string
+	if reqBody.AuthMode == repository.AuthModeJWT {
+		token, err := c.signer.Sign(host, quota.SubjectFrom(ctx), reqBody.ID, c.clock.Now())
+		if err != nil {
+			logging.From(ctx, c.logger).Error("failed to sign provider jwt",
+				zap.String("host", host),
+				zap.Error(err),
+			)
+			return err
+		}
+		authorization = "Bearer " + token
+	}
+
+	err = c.sendWithRetry(ctx, circuitBreaker, host, u, jsonBody, authorization)
+
+	// A rotated credential may already be the only one the provider accepts
+	var providerErr *ProviderError
+	if reqBody.NextSecretKey == "" || !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+
+	logging.From(ctx, c.logger).Debug("provider rejected secret key, trying the next one",
+		zap.String("host", host),
+		metadataField(ctx),
+	)
+
+	reqBody.SecretKey = reqBody.NextSecretKey
+	jsonBody, err = json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+	if err := c.sendWithRetry(ctx, circuitBreaker, host, u, jsonBody, authorization); err != nil {
+		return err
+	}
+
+	logging.From(ctx, c.logger).Warn("provider only accepts the next secret key, promote it",
+		zap.String("host", host),
+	)
+	return nil
+}
+
+// sendWithRetry sends the body, retrying once a throttled provider that asks
+// for a delay we are willing to wait, instead of falling through to the next
+// preference. A throttling 503 is ambiguous like any 5xx, so only a 429 is
+// sent again
+func (c *HTTPClient) sendWithRetry(
+	ctx context.Context,
+	circuitBreaker *gobreaker.CircuitBreaker[CircuitBreakerResponse],
+	host string,
+	u string,
+	jsonBody []byte,
+	authorization string,
+) error {
+	for retried := false; ; retried = true {
+		err := c.send(ctx, circuitBreaker, host, u, jsonBody, authorization)
+
+		var providerErr *ProviderError
+		if retried || !errors.As(err, &providerErr) || !c.shouldRetry(providerErr) {
+			return err
+		}
+
+		logging.From(ctx, c.logger).Info("provider throttled request, retrying",
+			zap.String("host", host),
+			metadataField(ctx),
+			zap.Int("status_code", providerErr.StatusCode),
+			zap.Duration("retry_after", providerErr.RetryAfter),
+		)
+
+		select {
+		case <-c.clock.After(providerErr.RetryAfter):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (c *HTTPClient) shouldRetry(providerErr *ProviderError) bool {
+	return providerErr.Throttled && Undelivered(providerErr) &&
+		providerErr.RetryAfter > 0 &&
+		providerErr.RetryAfter <= c.maxRetryAfter
+}
+
+// newTransport applies the per-phase timeouts, so a slow DNS lookup or
+// handshake fails fast instead of spending the whole request budget
+func newTransport(config HTTPClientConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+
+	return transport
+}
+
+// send performs a single POST through the circuit breaker, which judges the
+// outcome with isSuccessful; non-200 responses are returned as ProviderError.
+// authorization is sent as the Authorization header when set
+func (c *HTTPClient) send(
+	ctx context.Context,
+	circuitBreaker *gobreaker.CircuitBreaker[CircuitBreakerResponse],
+	host string,
+	u string,
+	jsonBody []byte,
+	authorization string,
+) error {
+	start := c.clock.Now()
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		u,
-		bytes.NewBuffer(jsonBody),
+		bytes.NewReader(jsonBody),
 	)
 	if err != nil {
-		c.logger.Error("failed to create HTTP request",
+		logging.From(ctx, c.logger).Error("failed to create HTTP request",
 			zap.String("host", host),
 			zap.Error(err),
 		)
 		return err
 	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
 
-	resp, err := circuitBreaker.Execute(func() (CircuitBreakerResponse, error) {
+	_, err = circuitBreaker.Execute(func() (CircuitBreakerResponse, error) {
 		resp, err := c.httpclient.Do(req)
 		if err != nil {
-			c.logger.Warn("HTTP request failed",
+			logging.From(ctx, c.logger).Warn("HTTP request failed",
 				zap.String("host", host),
+				metadataField(ctx),
 				zap.Error(err),
 			)
 			return CircuitBreakerResponse{}, err
@@ -119,7 +264,7 @@ func (c *HTTPClient) Post(ctx context.Context, u string, reqBody NotificationReq
 
 		rawBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			c.logger.Error("failed to read response body",
+			logging.From(ctx, c.logger).Error("failed to read response body",
 				zap.String("host", host),
 				zap.Int("status_code", resp.StatusCode),
 				zap.Error(err),
@@ -127,44 +272,81 @@ func (c *HTTPClient) Post(ctx context.Context, u string, reqBody NotificationReq
 			return CircuitBreakerResponse{}, err
 		}
 
-		return CircuitBreakerResponse{
+		response := CircuitBreakerResponse{
 			Body:       rawBody,
 			StatusCode: resp.StatusCode,
-		}, nil
+			Header:     resp.Header,
+		}
+		if resp.StatusCode != http.StatusOK {
+			providerErr := newProviderError(host, resp.StatusCode, rawBody)
+			if isThrottled(resp.StatusCode, resp.Header) {
+				providerErr.Throttled = true
+				providerErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.clock.Now())
+			}
+			return response, providerErr
+		}
+		return response, nil
 	})
 
-	duration := time.Since(start)
-	statusCode := 0
-	var finalErr error
+	duration := c.clock.Since(start)
 
-	if err != nil {
-		finalErr = err
-		c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, statusCode, duration, finalErr)
-		c.logger.Error("circuit breaker execution failed",
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, providerErr.StatusCode, duration, err)
+		logging.From(ctx, c.logger).Warn("received non-200 status code",
 			zap.String("host", host),
+			metadataField(ctx),
+			zap.Int("status_code", providerErr.StatusCode),
+			zap.Bool("throttled", providerErr.Throttled),
+			zap.String("response_body", providerErr.Body),
 			zap.Duration("duration", duration),
-			zap.Error(err),
 		)
 		return err
 	}
-
-	statusCode = resp.StatusCode
-	if resp.StatusCode != http.StatusOK {
-		finalErr = errors.New("response status code not equal 200")
-		c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, statusCode, duration, finalErr)
-		c.logger.Warn("received non-200 status code",
+	if err != nil {
+		c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, 0, duration, err)
+		logging.From(ctx, c.logger).Error("circuit breaker execution failed",
 			zap.String("host", host),
-			zap.Int("status_code", statusCode),
+			metadataField(ctx),
 			zap.Duration("duration", duration),
+			zap.Error(err),
 		)
-		return finalErr
+		return err
 	}
 
-	c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, statusCode, duration, nil)
+	c.metricsCollector.RecordRequest(ctx, http.MethodPost, host, http.StatusOK, duration, nil)
 
 	return nil
 }
 
+// isThrottled reports whether the provider is rate limiting rather than
+// failing: any 429, or a 503 that tells us when to come back
+func isThrottled(statusCode int, header http.Header) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		(statusCode == http.StatusServiceUnavailable && header.Get("Retry-After") != "")
+}
+
+// parseRetryAfter reads a Retry-After value given either as delay-seconds
+// or as an HTTP date, returning zero when it is absent or malformed
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
 func extractHost(u string) (string, error) {
 	parsed, err := url.Parse(u)
 	if err != nil {
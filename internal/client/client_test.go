@@ -3,20 +3,31 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
 	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/sony/gobreaker/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
 )
 
+// testHTTPClientConfig matches the documented defaults
+var testHTTPClientConfig = HTTPClientConfig{Timeout: 5 * time.Second}
+
 func TestNewHTTPClient(t *testing.T) {
 	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
 	cbRegistry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
@@ -31,10 +42,14 @@ func TestNewHTTPClient(t *testing.T) {
 
 	params := HTTPClientParams{
 		Config: HTTPClientConfig{
-			Timeout: 10 * time.Second,
+			Timeout:               10 * time.Second,
+			DialTimeout:           time.Second,
+			TLSHandshakeTimeout:   2 * time.Second,
+			ResponseHeaderTimeout: 3 * time.Second,
 		},
 		CircuitBreakerRegistry: cbRegistry,
 		MetricsCollector:       metricsCollector,
+		Clock:                  clock.NewRealClock(),
 		Logger:                 zap.NewNop(),
 	}
 
@@ -44,15 +59,46 @@ func TestNewHTTPClient(t *testing.T) {
 	assert.NotNil(t, client.httpclient)
 	assert.NotNil(t, client.circuitBreakerRegistry)
 	assert.NotNil(t, client.metricsCollector)
+	assert.NotNil(t, client.clock)
 	assert.Equal(t, 10*time.Second, client.httpclient.Timeout)
+
+	transport, ok := client.httpclient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 3*time.Second, transport.ResponseHeaderTimeout)
+	assert.NotNil(t, transport.DialContext)
 }
 
-func TestNewHTTPClientConfig(t *testing.T) {
-	config := NewHTTPClientConfig()
+func TestHTTPClient_Post_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+	client := NewHTTPClient(HTTPClientParams{
+		Config: HTTPClientConfig{
+			Timeout:               5 * time.Second,
+			ResponseHeaderTimeout: 50 * time.Millisecond,
+		},
+		CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+			Config: testCircuitBreakerRegistryConfig,
+			Logger: zap.NewNop(),
+		}),
+		MetricsCollector: metricsCollector,
+		Clock:            clock.NewRealClock(),
+		Logger:           zap.NewNop(),
+	})
+
+	start := time.Now()
+	err := client.Post(context.Background(), server.URL, NotificationRequest{To: "test@example.com"})
 
-	assert.NotZero(t, config.Timeout)
-	// Verify timeout is set (actual default value may vary based on env)
-	assert.Greater(t, config.Timeout, time.Duration(0))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout awaiting response headers")
+	assert.Less(t, time.Since(start), time.Second)
 }
 
 func TestHTTPClient_Post_Success(t *testing.T) {
@@ -75,12 +121,13 @@ func TestHTTPClient_Post_Success(t *testing.T) {
 
 	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
 	client := NewHTTPClient(HTTPClientParams{
-		Config: NewHTTPClientConfig(),
+		Config: testHTTPClientConfig,
 		CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
-			Config: NewCircuitBreakerRegistryConfig(),
+			Config: testCircuitBreakerRegistryConfig,
 			Logger: zap.NewNop(),
 		}),
 		MetricsCollector: metricsCollector,
+		Clock:            clock.NewRealClock(),
 		Logger:           zap.NewNop(),
 	})
 
@@ -114,12 +161,13 @@ func TestHTTPClient_Post_NonOKStatus(t *testing.T) {
 
 			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
 			client := NewHTTPClient(HTTPClientParams{
-				Config: NewHTTPClientConfig(),
+				Config: testHTTPClientConfig,
 				CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
-					Config: NewCircuitBreakerRegistryConfig(),
+					Config: testCircuitBreakerRegistryConfig,
 					Logger: zap.NewNop(),
 				}),
 				MetricsCollector: metricsCollector,
+				Clock:            clock.NewRealClock(),
 				Logger:           zap.NewNop(),
 			})
 
@@ -130,21 +178,186 @@ func TestHTTPClient_Post_NonOKStatus(t *testing.T) {
 				Message: "Test",
 			})
 
-			assert.Error(t, err)
-			assert.Equal(t, "response status code not equal 200", err.Error())
+			var providerErr *ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.statusCode, providerErr.StatusCode)
+			assert.Equal(t, fmt.Sprintf("provider responded with status code %d", tt.statusCode), err.Error())
+		})
+	}
+}
+
+func TestHTTPClient_Post_NextSecretKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		nextSecretKey  string
+		accepted       []string
+		expectedKeys   []string
+		expectedStatus int
+	}{
+		{
+			name:          "current key accepted",
+			nextSecretKey: "new-key",
+			accepted:      []string{"old-key", "new-key"},
+			expectedKeys:  []string{"old-key"},
+		},
+		{
+			name:          "falls back to the next key on 401",
+			nextSecretKey: "new-key",
+			accepted:      []string{"new-key"},
+			expectedKeys:  []string{"old-key", "new-key"},
+		},
+		{
+			name:           "no next key to fall back to",
+			accepted:       []string{"new-key"},
+			expectedKeys:   []string{"old-key"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "next key rejected too",
+			nextSecretKey:  "other-key",
+			accepted:       []string{"new-key"},
+			expectedKeys:   []string{"old-key", "other-key"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.NotContains(t, body, "next_secret_key")
+
+				key, _ := body["secret_key"].(string)
+				keys = append(keys, key)
+				for _, accepted := range tt.accepted {
+					if key == accepted {
+						return
+					}
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+			client := NewHTTPClient(HTTPClientParams{
+				Config: testHTTPClientConfig,
+				CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+					Config: testCircuitBreakerRegistryConfig,
+					Logger: zap.NewNop(),
+				}),
+				MetricsCollector: metricsCollector,
+				Clock:            clock.NewRealClock(),
+				Logger:           zap.NewNop(),
+			})
+
+			err := client.Post(context.Background(), server.URL, NotificationRequest{
+				To:            "test@example.com",
+				Title:         "Test",
+				Message:       "Test",
+				SecretKey:     "old-key",
+				NextSecretKey: tt.nextSecretKey,
+			})
+
+			assert.Equal(t, tt.expectedKeys, keys)
+			if tt.expectedStatus == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var providerErr *ProviderError
+			require.ErrorAs(t, err, &providerErr)
+			assert.Equal(t, tt.expectedStatus, providerErr.StatusCode)
 		})
 	}
 }
 
+func TestHTTPClient_Post_JWT(t *testing.T) {
+	tests := []struct {
+		name          string
+		signer        *JWTSigner
+		authMode      string
+		expectedAuth  bool
+		expectedError error
+	}{
+		{
+			name:         "sends a minted token for jwt preferences",
+			signer:       mustJWTSigner(t, JWTConfig{SigningKey: "s3cret", Algorithm: JWTAlgorithmHS256, TTL: time.Minute}),
+			authMode:     repository.AuthModeJWT,
+			expectedAuth: true,
+		},
+		{
+			name:     "sends no token for secret key preferences",
+			signer:   mustJWTSigner(t, JWTConfig{SigningKey: "s3cret", Algorithm: JWTAlgorithmHS256, TTL: time.Minute}),
+			authMode: repository.AuthModeSecretKey,
+		},
+		{
+			name:          "fails without a signing key",
+			authMode:      repository.AuthModeJWT,
+			expectedError: errJWTNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+			}))
+			defer server.Close()
+
+			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+			client := NewHTTPClient(HTTPClientParams{
+				Config: testHTTPClientConfig,
+				CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+					Config: testCircuitBreakerRegistryConfig,
+					Logger: zap.NewNop(),
+				}),
+				MetricsCollector: metricsCollector,
+				Signer:           tt.signer,
+				Clock:            clock.NewRealClock(),
+				Logger:           zap.NewNop(),
+			})
+
+			err := client.Post(context.Background(), server.URL, NotificationRequest{
+				ID:       "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				To:       "test@example.com",
+				AuthMode: tt.authMode,
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.expectedAuth {
+				assert.Empty(t, authorization)
+				return
+			}
+			token, ok := strings.CutPrefix(authorization, "Bearer ")
+			require.True(t, ok)
+			assert.Len(t, strings.Split(token, "."), 3)
+		})
+	}
+}
+
+func mustJWTSigner(t *testing.T, config JWTConfig) *JWTSigner {
+	t.Helper()
+	signer, err := NewJWTSigner(config)
+	require.NoError(t, err)
+	return signer
+}
+
 func TestHTTPClient_Post_InvalidURL(t *testing.T) {
 	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
 	client := NewHTTPClient(HTTPClientParams{
-		Config: NewHTTPClientConfig(),
+		Config: testHTTPClientConfig,
 		CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
-			Config: NewCircuitBreakerRegistryConfig(),
+			Config: testCircuitBreakerRegistryConfig,
 			Logger: zap.NewNop(),
 		}),
 		MetricsCollector: metricsCollector,
+		Clock:            clock.NewRealClock(),
 		Logger:           zap.NewNop(),
 	})
 
@@ -167,12 +380,13 @@ func TestHTTPClient_Post_ContextCancellation(t *testing.T) {
 
 	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
 	client := NewHTTPClient(HTTPClientParams{
-		Config: NewHTTPClientConfig(),
+		Config: testHTTPClientConfig,
 		CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
-			Config: NewCircuitBreakerRegistryConfig(),
+			Config: testCircuitBreakerRegistryConfig,
 			Logger: zap.NewNop(),
 		}),
 		MetricsCollector: metricsCollector,
+		Clock:            clock.NewRealClock(),
 		Logger:           zap.NewNop(),
 	})
 
@@ -276,6 +490,7 @@ func TestHTTPClient_WithMetrics_SuccessfulRequest(t *testing.T) {
 		},
 		circuitBreakerRegistry: cbRegistry,
 		metricsCollector:       collector,
+		clock:                  clock.NewRealClock(),
 		logger:                 zap.NewNop(),
 	}
 
@@ -357,6 +572,7 @@ func TestHTTPClient_WithMetrics_FailedRequest(t *testing.T) {
 		},
 		circuitBreakerRegistry: cbRegistry,
 		metricsCollector:       collector,
+		clock:                  clock.NewRealClock(),
 		logger:                 zap.NewNop(),
 	}
 
@@ -370,8 +586,9 @@ func TestHTTPClient_WithMetrics_FailedRequest(t *testing.T) {
 	}
 
 	err = client.Post(ctx, server.URL, req)
-	assert.Error(t, err)
-	assert.Equal(t, "response status code not equal 200", err.Error())
+	var providerErr *ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusInternalServerError, providerErr.StatusCode)
 
 	// Verify metrics
 	var rm metricdata.ResourceMetrics
@@ -439,9 +656,14 @@ func TestHTTPClient_WithMetrics_CircuitBreakerState(t *testing.T) {
 		},
 		circuitBreakerRegistry: cbRegistry,
 		metricsCollector:       collector,
+		clock:                  clock.NewRealClock(),
 		logger:                 zap.NewNop(),
 	}
 
+	registration, err := collector.ObserveCircuitBreakers(cbRegistry.Snapshot)
+	require.NoError(t, err)
+	defer registration.Unregister()
+
 	ctx := context.Background()
 	req := NotificationRequest{
 		To:        "user@example.com",
@@ -468,10 +690,105 @@ func TestHTTPClient_WithMetrics_CircuitBreakerState(t *testing.T) {
 		if m.Name == "http.client.circuit_breaker.state" {
 			foundCBState = true
 			gauge := m.Data.(metricdata.Gauge[int64])
-			assert.NotEmpty(t, gauge.DataPoints)
+			require.Len(t, gauge.DataPoints, 1)
+			// 5xx responses count as breaker failures, so the breaker is open (1)
+			assert.Equal(t, int64(1), gauge.DataPoints[0].Value)
 		}
 	}
-	assert.True(t, foundCBState, "circuit breaker state metric should be recorded")
+	assert.True(t, foundCBState, "circuit breaker state metric should be observed")
+}
+
+func TestHTTPClient_Post_CircuitBreakerTripping(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		header        http.Header
+		expectedState gobreaker.State
+	}{
+		{"server errors trip", http.StatusInternalServerError, nil, gobreaker.StateOpen},
+		{"rejected requests do not trip", http.StatusBadRequest, nil, gobreaker.StateClosed},
+		{"throttling does not trip", http.StatusServiceUnavailable, http.Header{"Retry-After": {"120"}}, gobreaker.StateClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+				Config: testCircuitBreakerRegistryConfig,
+				Logger: zap.NewNop(),
+			})
+			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+			client := NewHTTPClient(HTTPClientParams{
+				Config:                 testHTTPClientConfig,
+				CircuitBreakerRegistry: registry,
+				MetricsCollector:       metricsCollector,
+				Clock:                  clock.NewRealClock(),
+				Logger:                 zap.NewNop(),
+			})
+
+			for range 3 {
+				var providerErr *ProviderError
+				require.ErrorAs(t, client.Post(context.Background(), server.URL, NotificationRequest{To: "test@example.com"}), &providerErr)
+				assert.Equal(t, tt.statusCode, providerErr.StatusCode)
+			}
+
+			host, err := extractHost(server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedState, registry.GetOrCreate(host).State())
+		})
+	}
+}
+
+func TestHTTPClient_Post_HeldOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clk := mockclock.NewMockClock(ctrl)
+	clk.EXPECT().Now().Return(now).AnyTimes()
+
+	trips := mockrepository.NewMockCircuitBreakerTripProvider(ctrl)
+	trips.EXPECT().FindCircuitBreakerTrips(gomock.Any(), gomock.Any()).Return([]repository.CircuitBreakerTrip{
+		{Host: server.Listener.Addr().String(), OpenedAt: now.Add(-time.Second)},
+	}, nil)
+
+	config := testCircuitBreakerRegistryConfig
+	config.PersistState = true
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: config,
+		Trips:  trips,
+		Clock:  clk,
+		Logger: zap.NewNop(),
+	})
+	require.NoError(t, registry.Restore(context.Background()))
+
+	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+	client := NewHTTPClient(HTTPClientParams{
+		Config:                 testHTTPClientConfig,
+		CircuitBreakerRegistry: registry,
+		MetricsCollector:       metricsCollector,
+		Clock:                  clk,
+		Logger:                 zap.NewNop(),
+	})
+
+	err := client.Post(context.Background(), server.URL, NotificationRequest{})
+
+	assert.ErrorIs(t, err, gobreaker.ErrOpenState)
+	assert.Zero(t, requests, "a host held open is not called")
 }
 
 func TestHTTPClient_WithNoopMetrics(t *testing.T) {
@@ -502,6 +819,7 @@ func TestHTTPClient_WithNoopMetrics(t *testing.T) {
 		},
 		circuitBreakerRegistry: cbRegistry,
 		metricsCollector:       metricsCollector,
+		clock:                  clock.NewRealClock(),
 		logger:                 zap.NewNop(),
 	}
 
@@ -556,6 +874,7 @@ func TestHTTPClient_MultipleRequests_Metrics(t *testing.T) {
 		},
 		circuitBreakerRegistry: cbRegistry,
 		metricsCollector:       collector,
+		clock:                  clock.NewRealClock(),
 		logger:                 zap.NewNop(),
 	}
 
@@ -593,3 +912,264 @@ func TestHTTPClient_MultipleRequests_Metrics(t *testing.T) {
 	}
 	assert.Equal(t, int64(numRequests), totalRequests, "all requests should be counted")
 }
+
+func TestHTTPClient_WithMetrics_DurationFromClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	collector, err := metrics.NewHTTPClientCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	start := time.Date(2025, time.October, 10, 10, 30, 0, 0, time.UTC)
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(start)
+	mockClock.EXPECT().Since(start).Return(250 * time.Millisecond)
+
+	client := &HTTPClient{
+		httpclient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+		circuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+			Config: CircuitBreakerRegistryConfig{
+				MaxHalfOpenRequests:     5,
+				OpenStateTimeout:        60 * time.Second,
+				MinRequestsBeforeTrip:   3,
+				FailureThresholdPercent: 60,
+			},
+			Logger: zap.NewNop(),
+		}),
+		metricsCollector: collector,
+		clock:            mockClock,
+		logger:           zap.NewNop(),
+	}
+
+	err = client.Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var foundDuration bool
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name == "http.client.duration" {
+			foundDuration = true
+			hist := m.Data.(metricdata.Histogram[float64])
+			require.Len(t, hist.DataPoints, 1)
+			assert.Equal(t, 0.25, hist.DataPoints[0].Sum)
+		}
+	}
+	assert.True(t, foundDuration, "request duration metric should be recorded")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "absent", value: "", expected: 0},
+		{name: "delay seconds", value: "3", expected: 3 * time.Second},
+		{name: "negative seconds", value: "-3", expected: 0},
+		{name: "http date", value: "Sun, 01 Jun 2025 12:00:10 GMT", expected: 10 * time.Second},
+		{name: "http date in the past", value: "Sun, 01 Jun 2025 11:59:00 GMT", expected: 0},
+		{name: "malformed", value: "soon", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseRetryAfter(tt.value, now))
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		expected   bool
+	}{
+		{name: "429 without retry-after", statusCode: http.StatusTooManyRequests, expected: true},
+		{name: "429 with retry-after", statusCode: http.StatusTooManyRequests, retryAfter: "1", expected: true},
+		{name: "503 with retry-after", statusCode: http.StatusServiceUnavailable, retryAfter: "1", expected: true},
+		{name: "503 without retry-after", statusCode: http.StatusServiceUnavailable, expected: false},
+		{name: "500", statusCode: http.StatusInternalServerError, retryAfter: "1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			assert.Equal(t, tt.expected, isThrottled(tt.statusCode, header))
+		})
+	}
+}
+
+func TestHTTPClient_Post_Throttled(t *testing.T) {
+	newClient := func(clk clock.Clock, maxRetryAfter time.Duration) *HTTPClient {
+		metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+		return &HTTPClient{
+			httpclient: &http.Client{Timeout: 5 * time.Second},
+			circuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+				Config: CircuitBreakerRegistryConfig{
+					MaxHalfOpenRequests:     1,
+					OpenStateTimeout:        time.Minute,
+					MinRequestsBeforeTrip:   2,
+					FailureThresholdPercent: 50,
+				},
+				Logger: zap.NewNop(),
+			}),
+			metricsCollector: metricsCollector,
+			clock:            clk,
+			logger:           zap.NewNop(),
+			maxRetryAfter:    maxRetryAfter,
+		}
+	}
+
+	t.Run("retries once after the requested delay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		mockClock := mockclock.NewMockClock(ctrl)
+		mockClock.EXPECT().Now().Return(time.Now()).AnyTimes()
+		mockClock.EXPECT().Since(gomock.Any()).Return(time.Millisecond).AnyTimes()
+		mockClock.EXPECT().After(2 * time.Second).Return(fired)
+
+		err := newClient(mockClock, 5*time.Second).Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("does not retry when the delay exceeds the limit", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := newClient(clock.NewRealClock(), 5*time.Second).Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.True(t, providerErr.Throttled)
+		assert.Equal(t, 30*time.Second, providerErr.RetryAfter)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("does not retry a throttling 503, which may have been accepted", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := newClient(clock.NewRealClock(), 5*time.Second).Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.True(t, providerErr.Throttled)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("does not retry when retries are disabled", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		err := newClient(clock.NewRealClock(), 0).Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockClock := mockclock.NewMockClock(ctrl)
+		mockClock.EXPECT().Now().Return(time.Now()).AnyTimes()
+		mockClock.EXPECT().Since(gomock.Any()).Return(time.Millisecond).AnyTimes()
+		mockClock.EXPECT().After(2 * time.Second).DoAndReturn(func(time.Duration) <-chan time.Time {
+			cancel()
+			return make(chan time.Time)
+		})
+
+		err := newClient(mockClock, 5*time.Second).Post(ctx, server.URL, NotificationRequest{To: "user@example.com"})
+
+		var providerErr *ProviderError
+		require.ErrorAs(t, err, &providerErr)
+		assert.True(t, providerErr.Throttled)
+	})
+
+	t.Run("throttling does not trip the circuit breaker", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := newClient(clock.NewRealClock(), 0)
+		for i := 0; i < 5; i++ {
+			_ = client.Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+		}
+
+		host, _ := extractHost(server.URL)
+		assert.Equal(t, gobreaker.StateClosed, client.circuitBreakerRegistry.GetOrCreate(host).State())
+	})
+
+	t.Run("server errors trip the circuit breaker", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := newClient(clock.NewRealClock(), 0)
+		for i := 0; i < 5; i++ {
+			_ = client.Post(context.Background(), server.URL, NotificationRequest{To: "user@example.com"})
+		}
+
+		host, _ := extractHost(server.URL)
+		assert.Equal(t, gobreaker.StateOpen, client.circuitBreakerRegistry.GetOrCreate(host).State())
+	})
+}
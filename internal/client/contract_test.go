@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/mockprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestHTTPClient_Post_ProviderContract posts through the real client to the
+// mock provider, which rejects payloads breaking the provider contract
+func TestHTTPClient_Post_ProviderContract(t *testing.T) {
+	server := httptest.NewServer(mockprovider.NewServer(mockprovider.MockProviderConfig{}, clock.NewRealClock(), nil, zap.NewNop()))
+	defer server.Close()
+
+	tests := []struct {
+		name               string
+		path               string
+		req                NotificationRequest
+		expectedStatusCode int
+	}{
+		{
+			name: "email accepted",
+			path: "/email/success",
+			req: NotificationRequest{
+				ID:        "01J",
+				To:        "buyer@example.com",
+				Title:     "Order shipped",
+				Message:   "On its way",
+				SecretKey: "secret",
+				ThreadKey: "order-42",
+				Headers:   map[string]string{"References": "<a@notification-service>"},
+				HTML:      "<p>On its way</p>",
+			},
+		},
+		{
+			name: "push accepted",
+			path: "/push/success",
+			req: NotificationRequest{
+				ID:          "01J",
+				To:          "device-token",
+				Title:       "Order shipped",
+				Message:     "On its way",
+				ThreadKey:   "order-42",
+				CollapseKey: "order-42",
+				DeepLink:    "app://orders/42",
+			},
+		},
+		{
+			name: "push rejects html",
+			path: "/push/success",
+			req: NotificationRequest{
+				ID:      "01J",
+				To:      "device-token",
+				Title:   "Order shipped",
+				Message: "On its way",
+				HTML:    "<p>On its way</p>",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "failing provider",
+			path:               "/email/fail",
+			req:                NotificationRequest{ID: "01J", To: "buyer@example.com", Title: "t", Message: "m"},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+			client := NewHTTPClient(HTTPClientParams{
+				Config: testHTTPClientConfig,
+				CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+					Config: testCircuitBreakerRegistryConfig,
+					Logger: zap.NewNop(),
+				}),
+				MetricsCollector: metricsCollector,
+				Clock:            clock.NewRealClock(),
+				Logger:           zap.NewNop(),
+			})
+
+			err := client.Post(context.Background(), server.URL+tt.path, tt.req)
+
+			if tt.expectedStatusCode != 0 {
+				var providerErr *ProviderError
+				require.ErrorAs(t, err, &providerErr)
+				assert.Equal(t, tt.expectedStatusCode, providerErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sony/gobreaker/v2"
+)
+
+// maxProviderErrorBodySize caps how much of a provider's response body is
+// kept on a ProviderError
+const maxProviderErrorBodySize = 1024
+
+// ProviderError is returned when a provider answers with a non-200 status
+type ProviderError struct {
+	Host       string
+	StatusCode int
+	// Body is the provider's response body, truncated to maxProviderErrorBodySize
+	Body string
+	// Throttled is set when the provider is rate limiting rather than failing
+	Throttled bool
+	// RetryAfter is the delay requested by a throttling provider, zero if none
+	RetryAfter time.Duration
+}
+
+func newProviderError(host string, statusCode int, body []byte) *ProviderError {
+	return &ProviderError{
+		Host:       host,
+		StatusCode: statusCode,
+		Body:       truncateBody(body, maxProviderErrorBodySize),
+	}
+}
+
+// Error leaves out the host and body so the message is safe to return to
+// API callers; both are available on the struct for logs
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider responded with status code %d", e.StatusCode)
+}
+
+func (e *ProviderError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+func truncateBody(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+	// Cutting may split a multi-byte rune, so drop any invalid tail
+	return strings.ToValidUTF8(string(body[:limit]), "") + "...(truncated)"
+}
+
+// Undelivered reports whether err proves the provider never accepted the
+// notification, so sending it again cannot produce a duplicate: a 4xx
+// rejection, including 429, an open breaker or a refused dial. A 5xx or an
+// error raised after the request may have reached the provider, such as a
+// read timeout, is ambiguous, the provider possibly having accepted it
+func Undelivered(err error) bool {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode >= http.StatusBadRequest && providerErr.StatusCode < http.StatusInternalServerError
+	}
+
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Rejected reports whether the provider refused the notification for good,
+// e.g. for an invalid recipient: a 4xx other than 408 or 429, which sending
+// it again only repeats
+func Rejected(err error) bool {
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || !Undelivered(providerErr) {
+		return false
+	}
+	return providerErr.StatusCode != http.StatusRequestTimeout && providerErr.StatusCode != http.StatusTooManyRequests
+}
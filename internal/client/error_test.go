@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/sony/gobreaker/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProviderError(t *testing.T) {
+	err := newProviderError("email.example.com", http.StatusBadRequest, []byte(`{"error":"invalid recipient"}`))
+
+	assert.Equal(t, "provider responded with status code 400", err.Error())
+	assert.NotContains(t, err.Error(), "email.example.com")
+	assert.NotContains(t, err.Error(), "invalid recipient")
+	assert.Equal(t, http.StatusBadRequest, err.HTTPStatusCode())
+	assert.Equal(t, `{"error":"invalid recipient"}`, err.Body)
+}
+
+func TestTruncateBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     []byte
+		limit    int
+		expected string
+	}{
+		{
+			name:     "body within limit is kept",
+			body:     []byte("short"),
+			limit:    10,
+			expected: "short",
+		},
+		{
+			name:     "body over limit is cut",
+			body:     []byte("0123456789abcdef"),
+			limit:    10,
+			expected: "0123456789...(truncated)",
+		},
+		{
+			name:     "split multi-byte rune is dropped",
+			body:     []byte("abcสวัสดี"),
+			limit:    5,
+			expected: "abc...(truncated)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := truncateBody(tt.body, tt.limit)
+
+			assert.Equal(t, tt.expected, result)
+			assert.True(t, utf8.ValidString(result))
+		})
+	}
+}
+
+func TestHTTPClient_Post_ProviderErrorBody(t *testing.T) {
+	body := strings.Repeat("x", maxProviderErrorBodySize*2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+	client := NewHTTPClient(HTTPClientParams{
+		Config: testHTTPClientConfig,
+		CircuitBreakerRegistry: NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+			Config: testCircuitBreakerRegistryConfig,
+			Logger: zap.NewNop(),
+		}),
+		MetricsCollector: metricsCollector,
+		Clock:            clock.NewRealClock(),
+		Logger:           zap.NewNop(),
+	})
+
+	err := client.Post(t.Context(), server.URL, NotificationRequest{To: "test@example.com"})
+
+	var providerErr *ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, providerErr.StatusCode)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), providerErr.Host)
+	assert.Equal(t, body[:maxProviderErrorBodySize]+"...(truncated)", providerErr.Body)
+}
+
+func TestUndelivered(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "provider rejected", err: &ProviderError{StatusCode: http.StatusBadRequest}, expected: true},
+		{name: "provider throttled", err: &ProviderError{StatusCode: http.StatusTooManyRequests, Throttled: true}, expected: true},
+		{name: "provider failed", err: &ProviderError{StatusCode: http.StatusInternalServerError}, expected: false},
+		{name: "provider timed out at the gateway", err: &ProviderError{StatusCode: http.StatusGatewayTimeout}, expected: false},
+		{name: "breaker open", err: gobreaker.ErrOpenState, expected: true},
+		{name: "breaker half-open limit", err: gobreaker.ErrTooManyRequests, expected: true},
+		{name: "dial failure", err: &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, expected: true},
+		{name: "read failure", err: &net.OpError{Op: "read", Err: errors.New("connection reset")}, expected: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Undelivered(tt.err))
+		})
+	}
+}
+
+func TestRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "provider rejected", err: &ProviderError{StatusCode: http.StatusBadRequest}, expected: true},
+		{name: "recipient unknown", err: fmt.Errorf("send: %w", &ProviderError{StatusCode: http.StatusNotFound}), expected: true},
+		{name: "provider timed out reading", err: &ProviderError{StatusCode: http.StatusRequestTimeout}, expected: false},
+		{name: "provider throttled", err: &ProviderError{StatusCode: http.StatusTooManyRequests, Throttled: true}, expected: false},
+		{name: "provider failed", err: &ProviderError{StatusCode: http.StatusInternalServerError}, expected: false},
+		{name: "breaker open", err: gobreaker.ErrOpenState, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Rejected(tt.err))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+)
+
+// Kinds of injected faults, reported as the fault.type metric label
+const (
+	FaultLatency  = "latency"
+	FaultError    = "error"
+	FaultHostDown = "host_down"
+)
+
+// FaultInjectedHeader marks responses made up by fault injection
+const FaultInjectedHeader = "X-Fault-Injected"
+
+var errInjectedFault = errors.New("connection refused (injected fault)")
+
+// FaultConfig injects provider failures into every request, to rehearse
+// circuit breaker and fallback behavior in staging. Nothing is injected
+// unless Enabled is set
+type FaultConfig struct {
+	Enabled bool `envconfig:"FAULT_INJECTION_ENABLED" default:"false"`
+	// LatencyRate is the share of requests delayed by up to MaxLatency
+	LatencyRate float64       `envconfig:"FAULT_INJECTION_LATENCY_RATE" default:"0"`
+	MaxLatency  time.Duration `envconfig:"FAULT_INJECTION_MAX_LATENCY" default:"0s"`
+	// ErrorRate is the share of requests answered with ErrorStatusCode
+	// without reaching the provider
+	ErrorRate       float64 `envconfig:"FAULT_INJECTION_ERROR_RATE" default:"0"`
+	ErrorStatusCode int     `envconfig:"FAULT_INJECTION_ERROR_STATUS_CODE" default:"503"`
+	// FailingHosts refuse every connection, as if the provider were down
+	FailingHosts []string `envconfig:"FAULT_INJECTION_FAILING_HOSTS"`
+}
+
+// Validate rejects rates outside [0, 1] and negative delays, which would
+// inject faults into every request or none without saying so
+func (c FaultConfig) Validate() error {
+	var errs []error
+	for _, rate := range []struct {
+		name  string
+		value float64
+	}{
+		{"latency", c.LatencyRate},
+		{"error", c.ErrorRate},
+	} {
+		if rate.value < 0 || rate.value > 1 {
+			errs = append(errs, fmt.Errorf("fault injection: %s rate is not between 0 and 1 (%g)", rate.name, rate.value))
+		}
+	}
+	if c.MaxLatency < 0 {
+		errs = append(errs, fmt.Errorf("fault injection: max latency is negative (%s)", c.MaxLatency))
+	}
+	return errors.Join(errs...)
+}
+
+// faultTransport sits below the circuit breaker, so injected faults count
+// against breakers, metrics and fallbacks like real ones
+type faultTransport struct {
+	next             http.RoundTripper
+	config           FaultConfig
+	clock            clock.Clock
+	metricsCollector *metrics.HTTPClientCollector
+	// random returns a number in [0.0, 1.0) deciding each fault
+	random func() float64
+}
+
+func newFaultTransport(
+	next http.RoundTripper,
+	config FaultConfig,
+	clock clock.Clock,
+	metricsCollector *metrics.HTTPClientCollector,
+) *faultTransport {
+	return &faultTransport{
+		next:             next,
+		config:           config,
+		clock:            clock,
+		metricsCollector: metricsCollector,
+		random:           rand.Float64,
+	}
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+
+	if slices.Contains(t.config.FailingHosts, host) {
+		closeBody(req)
+		t.metricsCollector.RecordInjectedFault(ctx, host, FaultHostDown)
+		// A refused dial never reached the provider, as client.Undelivered
+		// expects of a host that is down
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errInjectedFault}
+	}
+
+	if t.config.MaxLatency > 0 && t.random() < t.config.LatencyRate {
+		t.metricsCollector.RecordInjectedFault(ctx, host, FaultLatency)
+		select {
+		case <-t.clock.After(time.Duration(t.random() * float64(t.config.MaxLatency))):
+		case <-ctx.Done():
+			closeBody(req)
+			return nil, ctx.Err()
+		}
+	}
+
+	if t.random() < t.config.ErrorRate {
+		closeBody(req)
+		t.metricsCollector.RecordInjectedFault(ctx, host, FaultError)
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", t.config.ErrorStatusCode, http.StatusText(t.config.ErrorStatusCode)),
+			StatusCode: t.config.ErrorStatusCode,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header: http.Header{
+				"Content-Type":      {"application/json"},
+				FaultInjectedHeader: {"true"},
+			},
+			Body:    io.NopCloser(strings.NewReader(`{"error":"injected fault"}`)),
+			Request: req,
+		}, nil
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// closeBody releases the request body of a request never forwarded, as
+// http.RoundTripper requires
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
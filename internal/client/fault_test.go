@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/sony/gobreaker/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// sequence returns the numbers in turn, so each fault decision is fixed
+func sequence(numbers ...float64) func() float64 {
+	return func() float64 {
+		number := numbers[0]
+		numbers = numbers[1:]
+		return number
+	}
+}
+
+func TestFaultConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        FaultConfig
+		expectedError string
+	}{
+		{
+			name:   "accepts rates between 0 and 1",
+			config: FaultConfig{LatencyRate: 0, ErrorRate: 1, MaxLatency: time.Second},
+		},
+		{
+			name:          "rejects a rate above 1",
+			config:        FaultConfig{LatencyRate: 1.5},
+			expectedError: "fault injection: latency rate is not between 0 and 1 (1.5)",
+		},
+		{
+			name:          "rejects a negative rate",
+			config:        FaultConfig{ErrorRate: -0.1},
+			expectedError: "fault injection: error rate is not between 0 and 1 (-0.1)",
+		},
+		{
+			name:          "rejects a negative max latency",
+			config:        FaultConfig{MaxLatency: -time.Second},
+			expectedError: "fault injection: max latency is negative (-1s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFaultTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		config             FaultConfig
+		random             func() float64
+		expectedDelay      time.Duration
+		expectedForwarded  bool
+		expectedStatusCode int
+		expectedDialError  bool
+	}{
+		{
+			name:               "forwards requests drawing no fault",
+			url:                "https://email.example.com/send",
+			config:             FaultConfig{LatencyRate: 0.2, MaxLatency: time.Second, ErrorRate: 0.1},
+			random:             sequence(0.5, 0.5),
+			expectedForwarded:  true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "answers with the error status code",
+			url:                "https://email.example.com/send",
+			config:             FaultConfig{ErrorRate: 0.1, ErrorStatusCode: http.StatusBadGateway},
+			random:             sequence(0.05),
+			expectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			name:               "delays by a share of the max latency",
+			url:                "https://email.example.com/send",
+			config:             FaultConfig{LatencyRate: 0.2, MaxLatency: time.Second},
+			random:             sequence(0.1, 0.25, 0.9),
+			expectedDelay:      250 * time.Millisecond,
+			expectedForwarded:  true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:              "refuses connections to failing hosts",
+			url:               "https://push.example.com/send",
+			config:            FaultConfig{FailingHosts: []string{"push.example.com"}},
+			random:            sequence(),
+			expectedDialError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClock := mockclock.NewMockClock(ctrl)
+			if tt.expectedDelay > 0 {
+				elapsed := make(chan time.Time, 1)
+				elapsed <- time.Time{}
+				mockClock.EXPECT().After(tt.expectedDelay).Return(elapsed)
+			}
+
+			forwarded := false
+			next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				forwarded = true
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})
+
+			metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+			transport := newFaultTransport(next, tt.config, mockClock, metricsCollector)
+			transport.random = tt.random
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(`{}`))
+			resp, err := transport.RoundTrip(req)
+
+			assert.Equal(t, tt.expectedForwarded, forwarded)
+			if tt.expectedDialError {
+				require.Error(t, err)
+				assert.True(t, Undelivered(err), "a refused connection never reached the provider")
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			if !tt.expectedForwarded {
+				assert.Equal(t, "true", resp.Header.Get(FaultInjectedHeader))
+			}
+		})
+	}
+}
+
+func TestHTTPClient_Post_FaultInjection(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewCircuitBreakerRegistry(CircuitBreakerRegistryParams{
+		Config: testCircuitBreakerRegistryConfig,
+		Logger: zap.NewNop(),
+	})
+	metricsCollector, _ := metrics.NewHTTPClientCollector(nil)
+	client := NewHTTPClient(HTTPClientParams{
+		Config:                 testHTTPClientConfig,
+		Faults:                 FaultConfig{Enabled: true, ErrorRate: 1, ErrorStatusCode: http.StatusServiceUnavailable},
+		CircuitBreakerRegistry: registry,
+		MetricsCollector:       metricsCollector,
+		Clock:                  clock.NewRealClock(),
+		Logger:                 zap.NewNop(),
+	})
+
+	for range 3 {
+		var providerErr *ProviderError
+		require.ErrorAs(t, client.Post(context.Background(), server.URL, NotificationRequest{To: "test@example.com"}), &providerErr)
+		assert.Equal(t, http.StatusServiceUnavailable, providerErr.StatusCode)
+	}
+
+	host, err := extractHost(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, gobreaker.StateOpen, registry.GetOrCreate(host).State(), "injected faults trip the breaker")
+	assert.Zero(t, requests.Load(), "the provider is never called")
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Signing algorithms of provider JWTs
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+)
+
+var errJWTNotConfigured = errors.New("provider jwt: PROVIDER_JWT_SIGNING_KEY is not set")
+
+type JWTConfig struct {
+	// SigningKey is the HMAC secret for HS256, or a PEM private key for
+	// RS256 and ES256
+	SigningKey string        `envconfig:"PROVIDER_JWT_SIGNING_KEY" secret:"true"`
+	Algorithm  string        `envconfig:"PROVIDER_JWT_ALGORITHM" default:"HS256"`
+	KeyID      string        `envconfig:"PROVIDER_JWT_KEY_ID"`
+	Issuer     string        `envconfig:"PROVIDER_JWT_ISSUER" default:"notification-service"`
+	TTL        time.Duration `envconfig:"PROVIDER_JWT_TTL" default:"1m"`
+}
+
+// JWTSigner mints the short-lived tokens sent to providers whose preference
+// uses the jwt auth mode, in place of their static secret key
+type JWTSigner struct {
+	config JWTConfig
+	sign   func(signingInput []byte) ([]byte, error)
+}
+
+// jwtClaims identify the notification a token was minted for, so a provider
+// can tie the request to the tenant and request that caused it
+type jwtClaims struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+	Tenant    string `json:"tenant,omitempty"`
+	RequestID string `json:"request_id"`
+}
+
+// NewJWTSigner parses the signing key; without one it returns a signer
+// failing every Sign, so only preferences using the jwt auth mode fail
+func NewJWTSigner(config JWTConfig) (*JWTSigner, error) {
+	signer := &JWTSigner{config: config}
+	if config.SigningKey == "" {
+		return signer, nil
+	}
+
+	var err error
+	switch config.Algorithm {
+	case JWTAlgorithmHS256:
+		key := []byte(config.SigningKey)
+		signer.sign = func(signingInput []byte) ([]byte, error) {
+			mac := hmac.New(sha256.New, key)
+			mac.Write(signingInput)
+			return mac.Sum(nil), nil
+		}
+	case JWTAlgorithmRS256:
+		signer.sign, err = newRSASign(config.SigningKey)
+	case JWTAlgorithmES256:
+		signer.sign, err = newECDSASign(config.SigningKey)
+	default:
+		return nil, fmt.Errorf("provider jwt: algorithm '%s' not supported, use HS256, RS256 or ES256", config.Algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("provider jwt: %w", err)
+	}
+
+	if config.TTL <= 0 {
+		return nil, errors.New("provider jwt: PROVIDER_JWT_TTL must be positive")
+	}
+	return signer, nil
+}
+
+// Sign mints a token for the provider at audience, valid from now for the
+// configured TTL
+func (s *JWTSigner) Sign(audience string, tenant string, requestID string, now time.Time) (string, error) {
+	if s == nil || s.sign == nil {
+		return "", errJWTNotConfigured
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	header := map[string]string{"alg": s.config.Algorithm, "typ": "JWT"}
+	if s.config.KeyID != "" {
+		header["kid"] = s.config.KeyID
+	}
+	claims := jwtClaims{
+		Issuer:    s.config.Issuer,
+		Audience:  audience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.config.TTL).Unix(),
+		ID:        hex.EncodeToString(id),
+		Tenant:    tenant,
+		RequestID: requestID,
+	}
+
+	encodedHeader, err := encodeJWTSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeJWTSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	signature, err := s.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeJWTSegment(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func newRSASign(pemKey string) (func([]byte) ([]byte, error), error) {
+	key, err := parsePrivateKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("RS256 needs an RSA private key")
+	}
+
+	return func(signingInput []byte) ([]byte, error) {
+		digest := sha256.Sum256(signingInput)
+		return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	}, nil
+}
+
+func newECDSASign(pemKey string) (func([]byte) ([]byte, error), error) {
+	key, err := parsePrivateKey(pemKey)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("ES256 needs a P-256 ECDSA private key")
+	}
+
+	return func(signingInput []byte) ([]byte, error) {
+		digest := sha256.Sum256(signingInput)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS carries r and s as fixed-size big-endian integers, not ASN.1
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	}, nil
+}
+
+// parsePrivateKey accepts PKCS #8, PKCS #1 RSA and SEC 1 EC keys
+func parsePrivateKey(pemKey string) (any, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("signing key is not a PKCS #8, PKCS #1 or SEC 1 private key")
+}
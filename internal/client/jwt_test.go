@@ -0,0 +1,171 @@
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTSigner_Sign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config JWTConfig
+		verify func(t *testing.T, signingInput []byte, signature []byte)
+	}{
+		{
+			name:   "HS256",
+			config: JWTConfig{SigningKey: "s3cret", Algorithm: JWTAlgorithmHS256},
+			verify: func(t *testing.T, signingInput []byte, signature []byte) {
+				mac := hmac.New(sha256.New, []byte("s3cret"))
+				mac.Write(signingInput)
+				assert.True(t, hmac.Equal(mac.Sum(nil), signature))
+			},
+		},
+		{
+			name:   "RS256 with a PKCS #1 key",
+			config: JWTConfig{SigningKey: encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), Algorithm: JWTAlgorithmRS256},
+			verify: func(t *testing.T, signingInput []byte, signature []byte) {
+				digest := sha256.Sum256(signingInput)
+				assert.NoError(t, rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest[:], signature))
+			},
+		},
+		{
+			name:   "ES256 with a PKCS #8 key",
+			config: JWTConfig{SigningKey: encodePEM(t, "PRIVATE KEY", marshalPKCS8(t, ecKey)), Algorithm: JWTAlgorithmES256},
+			verify: func(t *testing.T, signingInput []byte, signature []byte) {
+				require.Len(t, signature, 64)
+				digest := sha256.Sum256(signingInput)
+				r := new(big.Int).SetBytes(signature[:32])
+				s := new(big.Int).SetBytes(signature[32:])
+				assert.True(t, ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Issuer = "notification-service"
+			tt.config.KeyID = "key-1"
+			tt.config.TTL = time.Minute
+
+			signer, err := NewJWTSigner(tt.config)
+			require.NoError(t, err)
+
+			token, err := signer.Sign("email.example.com", "tenant-a", "01JB8Z5XK3M4N5P6Q7R8S9T0VW", now)
+			require.NoError(t, err)
+
+			parts := strings.Split(token, ".")
+			require.Len(t, parts, 3)
+
+			var header map[string]string
+			decodeSegment(t, parts[0], &header)
+			assert.Equal(t, map[string]string{"alg": tt.config.Algorithm, "typ": "JWT", "kid": "key-1"}, header)
+
+			var claims jwtClaims
+			decodeSegment(t, parts[1], &claims)
+			assert.Len(t, claims.ID, 32)
+			claims.ID = ""
+			assert.Equal(t, jwtClaims{
+				Issuer:    "notification-service",
+				Audience:  "email.example.com",
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(time.Minute).Unix(),
+				Tenant:    "tenant-a",
+				RequestID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			}, claims)
+
+			signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+			require.NoError(t, err)
+			tt.verify(t, []byte(parts[0]+"."+parts[1]), signature)
+		})
+	}
+}
+
+func TestNewJWTSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPEM := encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	tests := []struct {
+		name          string
+		config        JWTConfig
+		expectedError string
+	}{
+		{
+			name:          "unknown algorithm",
+			config:        JWTConfig{SigningKey: "s3cret", Algorithm: "none", TTL: time.Minute},
+			expectedError: "provider jwt: algorithm 'none' not supported, use HS256, RS256 or ES256",
+		},
+		{
+			name:          "key that is not PEM",
+			config:        JWTConfig{SigningKey: "s3cret", Algorithm: JWTAlgorithmRS256, TTL: time.Minute},
+			expectedError: "provider jwt: signing key is not PEM encoded",
+		},
+		{
+			name:          "RSA key for ES256",
+			config:        JWTConfig{SigningKey: rsaPEM, Algorithm: JWTAlgorithmES256, TTL: time.Minute},
+			expectedError: "provider jwt: ES256 needs a P-256 ECDSA private key",
+		},
+		{
+			name:          "non positive ttl",
+			config:        JWTConfig{SigningKey: "s3cret", Algorithm: JWTAlgorithmHS256},
+			expectedError: "provider jwt: PROVIDER_JWT_TTL must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTSigner(tt.config)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+
+	t.Run("signs nothing without a key", func(t *testing.T) {
+		signer, err := NewJWTSigner(JWTConfig{Algorithm: JWTAlgorithmHS256})
+		require.NoError(t, err)
+
+		_, err = signer.Sign("email.example.com", "", "01JB8Z5XK3M4N5P6Q7R8S9T0VW", time.Now())
+		assert.ErrorIs(t, err, errJWTNotConfigured)
+	})
+}
+
+func encodePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+func marshalPKCS8(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return der
+}
+
+func decodeSegment(t *testing.T, segment string, out any) {
+	t.Helper()
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(decoded, out))
+}
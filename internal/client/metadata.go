@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type metadataKey struct{}
+
+// WithMetadata returns ctx carrying the caller metadata of the notification
+// being sent, e.g. its order_id, logged with every provider request
+func WithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// metadataField logs the metadata carried by ctx, or nothing without any
+func metadataField(ctx context.Context) zap.Field {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	if len(metadata) == 0 {
+		return zap.Skip()
+	}
+	return zap.Any("metadata", metadata)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMetadataField(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		expected map[string]any
+	}{
+		{name: "logs the metadata of the notification", metadata: map[string]string{"order_id": "42"}, expected: map[string]any{"metadata": map[string]string{"order_id": "42"}}},
+		{name: "logs nothing without metadata", expected: map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			ctx := WithMetadata(context.Background(), tt.metadata)
+
+			zap.New(core).Info("provider request", metadataField(ctx))
+
+			assert.Equal(t, tt.expected, logs.All()[0].ContextMap())
+		})
+	}
+}
@@ -1,8 +1,38 @@
 package client
 
 type NotificationRequest struct {
+	ID        string `json:"id"`
 	To        string `json:"to"`
 	Title     string `json:"title"`
 	Message   string `json:"message"`
 	SecretKey string `json:"secret_key"`
+	// NextSecretKey is sent in place of SecretKey when the provider rejects
+	// it with 401, while the credential is being rotated; never serialized
+	NextSecretKey string `json:"-"`
+	// AuthMode is the auth mode of the preference; with jwt a minted token
+	// is sent in the Authorization header and SecretKey is left empty
+	AuthMode  string `json:"-"`
+	ThreadKey string `json:"thread_key,omitempty"`
+	// Headers carries email headers, such as References for threading
+	Headers map[string]string `json:"headers,omitempty"`
+	// CollapseKey lets push providers replace earlier notifications of a thread
+	CollapseKey string `json:"collapse_key,omitempty"`
+	// HTML is a rich alternative to Message for providers that render it
+	HTML        string       `json:"html,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// DeepLink is the app location a push notification opens when tapped
+	DeepLink string `json:"deep_link,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	// Metadata is the caller metadata, only forwarded when METADATA_FORWARD
+	// is enabled
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Attachment is either fetched by the provider from URL or sent inline as
+// base64 encoded Content
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Content     string `json:"content,omitempty"`
 }
@@ -8,8 +8,8 @@ var Module = fx.Module("http_client",
 			NewHTTPClient,
 			fx.As(new(HTTPClientProvider)),
 		),
-		NewHTTPClientConfig,
 		NewCircuitBreakerRegistry,
-		NewCircuitBreakerRegistryConfig,
+		NewJWTSigner,
 	),
+	fx.Invoke(RegisterCircuitBreakerMetrics, RestoreCircuitBreakers, SyncCircuitBreakers),
 )
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"time"
+
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("clock",
+	fx.Provide(
+		fx.Annotate(
+			NewRealClock,
+			fx.As(new(Clock)),
+		),
+	),
+)
+
+// Clock abstracts the current time so time-dependent behavior can be driven
+// deterministically in tests
+//
+//go:generate mockgen -package mockclock -destination ./mock/mockclock.go . Clock
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+}
+
+var _ Clock = (*RealClock)(nil)
+
+// RealClock reads the system wall clock
+type RealClock struct{}
+
+func NewRealClock() *RealClock {
+	return &RealClock{}
+}
+
+func (c *RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *RealClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (c *RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,436 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/alert"
+	"github.com/koungkub/fw-challenge-notification-service/internal/analytics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/health"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ingest"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+	"github.com/koungkub/fw-challenge-notification-service/internal/realtime"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"github.com/koungkub/fw-challenge-notification-service/internal/server"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+var Module = fx.Module("config",
+	fx.Provide(
+		NewLoader,
+		(*Loader).Load,
+		Config.Components,
+		NewReloader,
+	),
+	fx.Invoke(func(*Reloader) {}),
+)
+
+// FileEnv names the variable pointing at an optional YAML or JSON file of
+// settings
+const FileEnv = "CONFIG_FILE"
+
+// redacted replaces the value of fields tagged secret:"true"
+const redacted = "[REDACTED]"
+
+// Config holds every setting of the API server, grouped by the component
+// consuming it. Variable names and defaults are the envconfig and default
+// tags of each component type
+type Config struct {
+	Log            LogConfig
+	Logging        logging.Config
+	HTTP           server.HTTPConfig
+	LoadShedding   server.LoadSheddingConfig
+	RateLimit      ratelimit.RateLimitConfig
+	Handler        handler.HandlerConfig
+	HTTPClient     client.HTTPClientConfig
+	Faults         client.FaultConfig
+	ProviderJWT    client.JWTConfig
+	CircuitBreaker client.CircuitBreakerRegistryConfig
+	Persistent     repository.PersistentConfig
+	Cache          repository.CacheConfig
+	Metric         metrics.MetricConfig
+	Generator      idgen.GeneratorConfig
+	Event          event.EventConfig
+	Analytics      analytics.AnalyticsConfig
+	Alert          alert.AlertConfig
+	ErrorTracking  errortracking.ErrorTrackingConfig
+	Dispatch       dispatch.DispatchConfig
+	SQS            ingest.SQSConfig
+	Secret         secret.SecretConfig
+	Cipher         secret.CipherConfig
+	Health         health.HealthConfig
+	Batch          batch.BatchConfig
+	Receipt        handler.ReceiptConfig
+	Message        service.MessageConfig
+	Realtime       realtime.RealtimeConfig
+	Digest         service.DigestConfig
+	Quota          quota.QuotaConfig
+	Cost           service.CostConfig
+	Metadata       service.MetadataConfig
+	PartialSuccess service.PartialSuccessConfig
+	RetryQueue     service.RetryQueueConfig
+	Channel        service.ChannelConfig
+	Budget         service.BudgetConfig
+	Sandbox        service.SandboxConfig
+	Replay         service.ReplayConfig
+	Region         service.RegionConfig
+	Sticky         service.StickyConfig
+	Warmup         service.WarmupConfig
+	Hook           service.HookConfig
+	Scrubber       service.ScrubberConfig
+	Callout        service.CalloutConfig
+	Emoji          service.EmojiConfig
+}
+
+// LogConfig lives here rather than with its consumer since the logger is
+// built before the application starts
+type LogConfig struct {
+	Level zapcore.Level `envconfig:"LOG_LEVEL" default:"info"`
+}
+
+// ConfigResult provides each component its own part of Config
+type ConfigResult struct {
+	fx.Out
+
+	HTTP           server.HTTPConfig
+	LoadShedding   server.LoadSheddingConfig
+	RateLimit      ratelimit.RateLimitConfig
+	Handler        handler.HandlerConfig
+	HTTPClient     client.HTTPClientConfig
+	Faults         client.FaultConfig
+	ProviderJWT    client.JWTConfig
+	CircuitBreaker client.CircuitBreakerRegistryConfig
+	Persistent     repository.PersistentConfig
+	Cache          repository.CacheConfig
+	Metric         metrics.MetricConfig
+	Generator      idgen.GeneratorConfig
+	Event          event.EventConfig
+	Analytics      analytics.AnalyticsConfig
+	Alert          alert.AlertConfig
+	ErrorTracking  errortracking.ErrorTrackingConfig
+	Dispatch       dispatch.DispatchConfig
+	SQS            ingest.SQSConfig
+	Secret         secret.SecretConfig
+	Cipher         secret.CipherConfig
+	Health         health.HealthConfig
+	Batch          batch.BatchConfig
+	Receipt        handler.ReceiptConfig
+	Message        service.MessageConfig
+	Realtime       realtime.RealtimeConfig
+	Digest         service.DigestConfig
+	Quota          quota.QuotaConfig
+	Cost           service.CostConfig
+	Metadata       service.MetadataConfig
+	PartialSuccess service.PartialSuccessConfig
+	RetryQueue     service.RetryQueueConfig
+	Channel        service.ChannelConfig
+	Budget         service.BudgetConfig
+	Sandbox        service.SandboxConfig
+	Replay         service.ReplayConfig
+	Region         service.RegionConfig
+	Sticky         service.StickyConfig
+	Warmup         service.WarmupConfig
+	Hook           service.HookConfig
+	Scrubber       service.ScrubberConfig
+	Callout        service.CalloutConfig
+	Emoji          service.EmojiConfig
+}
+
+func (c Config) Components() ConfigResult {
+	return ConfigResult{
+		HTTP:           c.HTTP,
+		LoadShedding:   c.LoadShedding,
+		RateLimit:      c.RateLimit,
+		Handler:        c.Handler,
+		HTTPClient:     c.HTTPClient,
+		Faults:         c.Faults,
+		ProviderJWT:    c.ProviderJWT,
+		CircuitBreaker: c.CircuitBreaker,
+		Persistent:     c.Persistent,
+		Cache:          c.Cache,
+		Metric:         c.Metric,
+		Generator:      c.Generator,
+		Event:          c.Event,
+		Analytics:      c.Analytics,
+		Alert:          c.Alert,
+		ErrorTracking:  c.ErrorTracking,
+		Dispatch:       c.Dispatch,
+		SQS:            c.SQS,
+		Secret:         c.Secret,
+		Cipher:         c.Cipher,
+		Health:         c.Health,
+		Batch:          c.Batch,
+		Receipt:        c.Receipt,
+		Message:        c.Message,
+		Realtime:       c.Realtime,
+		Digest:         c.Digest,
+		Quota:          c.Quota,
+		Cost:           c.Cost,
+		Metadata:       c.Metadata,
+		PartialSuccess: c.PartialSuccess,
+		RetryQueue:     c.RetryQueue,
+		Channel:        c.Channel,
+		Budget:         c.Budget,
+		Sandbox:        c.Sandbox,
+		Replay:         c.Replay,
+		Region:         c.Region,
+		Sticky:         c.Sticky,
+		Warmup:         c.Warmup,
+		Hook:           c.Hook,
+		Scrubber:       c.Scrubber,
+		Callout:        c.Callout,
+		Emoji:          c.Emoji,
+	}
+}
+
+func (c *Config) sections() []any {
+	return []any{
+		&c.Log,
+		&c.Logging,
+		&c.HTTP,
+		&c.LoadShedding,
+		&c.RateLimit,
+		&c.Handler,
+		&c.HTTPClient,
+		&c.Faults,
+		&c.ProviderJWT,
+		&c.CircuitBreaker,
+		&c.Persistent,
+		&c.Cache,
+		&c.Metric,
+		&c.Generator,
+		&c.Event,
+		&c.Analytics,
+		&c.Alert,
+		&c.ErrorTracking,
+		&c.Dispatch,
+		&c.SQS,
+		&c.Secret,
+		&c.Cipher,
+		&c.Health,
+		&c.Batch,
+		&c.Receipt,
+		&c.Message,
+		&c.Realtime,
+		&c.Digest,
+		&c.Quota,
+		&c.Cost,
+		&c.Metadata,
+		&c.PartialSuccess,
+		&c.RetryQueue,
+		&c.Channel,
+		&c.Budget,
+		&c.Sandbox,
+		&c.Replay,
+		&c.Region,
+		&c.Sticky,
+		&c.Warmup,
+		&c.Hook,
+		&c.Scrubber,
+		&c.Callout,
+		&c.Emoji,
+	}
+}
+
+// Loader reads the settings and remembers which variables it exported from
+// CONFIG_FILE, so a reload can replace them while variables set in the real
+// environment keep winning
+type Loader struct {
+	mu       sync.Mutex
+	fromFile map[string]bool
+}
+
+func NewLoader() *Loader {
+	return &Loader{fromFile: map[string]bool{}}
+}
+
+// Load reads every setting using a fresh Loader
+func Load() (Config, error) {
+	return NewLoader().Load()
+}
+
+// validator is a section that checks its settings once they are read
+type validator interface {
+	Validate() error
+}
+
+// Load reads every setting at once. Environment variables win over the
+// CONFIG_FILE file, which wins over defaults. Sections read are validated
+// when they can be. On error the settings that could be read are still
+// returned
+func (l *Loader) Load() (Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var cfg Config
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := l.applyFile(path, cfg.Effective()); err != nil {
+			return cfg, err
+		}
+	}
+
+	var errs []error
+	for _, section := range cfg.sections() {
+		if err := envconfig.Process("", section); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if section, ok := section.(validator); ok {
+			if err := section.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// applyFile exports the settings of the file that are not set in the real
+// environment, the way .env files are loaded; known holds the accepted
+// variable names so typos are rejected instead of silently ignored
+func (l *Loader) applyFile(path string, known map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	settings := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		// Keeps large integers such as CACHE_MAX_COST out of float notation
+		decoder.UseNumber()
+		err = decoder.Decode(&settings)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &settings)
+	default:
+		return fmt.Errorf("config file: unsupported extension '%s', use .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("config file: unknown setting '%s'", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := formatSetting(settings[name])
+		if err != nil {
+			return fmt.Errorf("config file: setting '%s': %w", name, err)
+		}
+		values[name] = value
+	}
+
+	// Settings removed from the file fall back to their defaults
+	for name := range l.fromFile {
+		if _, ok := values[name]; !ok {
+			os.Unsetenv(name)
+			delete(l.fromFile, name)
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := os.LookupEnv(name); ok && !l.fromFile[name] {
+			continue
+		}
+		if err := os.Setenv(name, values[name]); err != nil {
+			return err
+		}
+		l.fromFile[name] = true
+	}
+
+	return nil
+}
+
+// formatSetting renders a file value the way envconfig reads it; lists
+// become comma separated
+func formatSetting(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			formatted, err := formatSetting(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, formatted)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("nested objects are not supported")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Effective flattens the settings into their variable names and values, with
+// secrets redacted when set, so they can be printed safely
+func (c Config) Effective() map[string]string {
+	result := map[string]string{}
+
+	for _, section := range c.sections() {
+		value := reflect.ValueOf(section).Elem()
+
+		for i := range value.NumField() {
+			field := value.Type().Field(i)
+			name, ok := field.Tag.Lookup("envconfig")
+			if !ok || !field.IsExported() {
+				continue
+			}
+
+			if field.Tag.Get("secret") == "true" && !value.Field(i).IsZero() {
+				result[name] = redacted
+				continue
+			}
+			result[name] = formatValue(value.Field(i))
+		}
+	}
+
+	return result
+}
+
+// formatValue renders slices the way envconfig reads them, comma separated
+func formatValue(value reflect.Value) string {
+	if value.Kind() != reflect.Slice {
+		return fmt.Sprint(value.Interface())
+	}
+
+	items := make([]string, value.Len())
+	for i := range value.Len() {
+		items[i] = fmt.Sprint(value.Index(i).Interface())
+	}
+	return strings.Join(items, ",")
+}
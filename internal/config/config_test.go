@@ -0,0 +1,189 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setRequired sets the variables without defaults; t.Setenv restores them
+func setRequired(t *testing.T) {
+	t.Setenv("DB_HOST", "postgres")
+	t.Setenv("DB_PORT", "5432")
+	t.Setenv("DB_NAME", "postgres")
+	t.Setenv("DB_USERNAME", "myuser")
+	t.Setenv("DB_PASSWORD", "mypassword")
+}
+
+// unsetEnv removes a variable for the test and restores it afterwards
+func unsetEnv(t *testing.T, name string) {
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setRequired(t)
+	unsetEnv(t, FileEnv)
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.HTTP.Port)
+	assert.Equal(t, 5*time.Second, cfg.HTTPClient.Timeout)
+	assert.Equal(t, uint32(5), cfg.CircuitBreaker.MaxHalfOpenRequests)
+	assert.Equal(t, 10*time.Minute, cfg.Cache.ExpiredTime)
+	assert.Equal(t, "disable", cfg.Persistent.SSLMode)
+	assert.Equal(t, "ulid", cfg.Generator.Strategy)
+	assert.Equal(t, 5*time.Second, cfg.Event.WebhookTimeout)
+	assert.Equal(t, 100, cfg.Dispatch.MaxConcurrent)
+	assert.Equal(t, int32(10), cfg.SQS.MaxMessages)
+}
+
+func TestLoad_MissingRequired(t *testing.T) {
+	setRequired(t)
+	unsetEnv(t, FileEnv)
+	unsetEnv(t, "DB_HOST")
+
+	cfg, err := Load()
+
+	require.ErrorContains(t, err, "DB_HOST")
+	assert.Equal(t, ":8080", cfg.HTTP.Port)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	setRequired(t)
+	unsetEnv(t, FileEnv)
+	t.Setenv("QUOTA_DAILY_LIMITS", "acme:-1")
+
+	_, err := Load()
+
+	require.EqualError(t, err, "quota: daily limit of 'acme' is negative (-1)")
+}
+
+func TestLoad_File(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+HTTP_SERVER_PORT: ":9000"
+CACHE_MAX_COST: 1073741824
+PLATFORM_WEBHOOK_URLS:
+  - http://ops.internal/hooks
+  - http://automation.internal/events
+DB_HOST: file-host
+`,
+		},
+		{
+			name: "json",
+			file: "config.json",
+			content: `{
+	"HTTP_SERVER_PORT": ":9000",
+	"CACHE_MAX_COST": 1073741824,
+	"PLATFORM_WEBHOOK_URLS": ["http://ops.internal/hooks", "http://automation.internal/events"],
+	"DB_HOST": "file-host"
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			unsetEnv(t, "HTTP_SERVER_PORT")
+			unsetEnv(t, "CACHE_MAX_COST")
+			unsetEnv(t, "PLATFORM_WEBHOOK_URLS")
+			t.Setenv(FileEnv, writeFile(t, tt.file, tt.content))
+
+			cfg, err := Load()
+
+			require.NoError(t, err)
+			assert.Equal(t, ":9000", cfg.HTTP.Port)
+			assert.Equal(t, int64(1073741824), cfg.Cache.MaxCost)
+			assert.Equal(t, []string{"http://ops.internal/hooks", "http://automation.internal/events"}, cfg.Event.WebhookURLs)
+			// The environment wins over the file
+			assert.Equal(t, "postgres", cfg.Persistent.Host)
+		})
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "unknown setting",
+			file:          "config.yaml",
+			content:       "HTTP_SERVER_PROT: \":9000\"\n",
+			expectedError: "config file: unknown setting 'HTTP_SERVER_PROT'",
+		},
+		{
+			name:          "nested object",
+			file:          "config.yaml",
+			content:       "HTTP_SERVER_PORT:\n  value: \":9000\"\n",
+			expectedError: "config file: setting 'HTTP_SERVER_PORT': nested objects are not supported",
+		},
+		{
+			name:          "unsupported extension",
+			file:          "config.toml",
+			content:       "",
+			expectedError: "config file: unsupported extension '.toml', use .yaml, .yml or .json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			unsetEnv(t, "HTTP_SERVER_PORT")
+			t.Setenv(FileEnv, writeFile(t, tt.file, tt.content))
+
+			_, err := Load()
+
+			require.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestConfig_Effective(t *testing.T) {
+	var cfg Config
+	cfg.Persistent.Host = "postgres"
+	cfg.Persistent.Password = "mypassword"
+	cfg.Event.WebhookURLs = []string{"http://a", "http://b"}
+	cfg.Event.WebhookTimeout = 5 * time.Second
+
+	result := cfg.Effective()
+
+	assert.Equal(t, "postgres", result["DB_HOST"])
+	assert.Equal(t, redacted, result["DB_PASSWORD"])
+	assert.Equal(t, "http://a,http://b", result["PLATFORM_WEBHOOK_URLS"])
+	assert.Equal(t, "5s", result["PLATFORM_WEBHOOK_TIMEOUT"])
+	// An unset secret is shown empty so a missing value stays visible
+	assert.Equal(t, "", result["HTTP_ADMIN_TOKEN"])
+}
+
+func TestConfig_Components(t *testing.T) {
+	var cfg Config
+	cfg.HTTP.Port = ":9000"
+	cfg.Dispatch.MaxConcurrent = 7
+
+	result := cfg.Components()
+
+	assert.Equal(t, ":9000", result.HTTP.Port)
+	assert.Equal(t, 7, result.Dispatch.MaxConcurrent)
+}
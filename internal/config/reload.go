@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// reloadable lists the settings applied at runtime; any other change waits
+// for a restart
+var reloadable = map[string]bool{
+	"LOG_LEVEL":          true,
+	"CACHE_EXPIRED_TIME": true,
+	"CIRCUIT_BREAKER_MIN_REQUESTS_BEFORE_TRIP":  true,
+	"CIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT": true,
+	"RATE_LIMIT_IP_RPS":                         true,
+	"RATE_LIMIT_IP_BURST":                       true,
+	"RATE_LIMIT_KEY_RPS":                        true,
+	"RATE_LIMIT_KEY_BURST":                      true,
+}
+
+// ReloadResult lists the variable names whose value changed
+type ReloadResult struct {
+	Applied         []string
+	RestartRequired []string
+}
+
+type ReloaderParams struct {
+	fx.In
+
+	Lifecycle       fx.Lifecycle
+	Loader          *Loader
+	Config          Config
+	CacheTTL        *repository.CacheTTL
+	CircuitBreakers *client.CircuitBreakerRegistry
+	RateLimits      *ratelimit.Limits
+	LogLevel        zap.AtomicLevel
+	Events          event.Publisher
+	Logger          *zap.Logger
+}
+
+// Reloader rereads the settings on SIGHUP and applies the safe ones to the
+// running components
+type Reloader struct {
+	mu              sync.Mutex
+	loader          *Loader
+	current         Config
+	cacheTTL        *repository.CacheTTL
+	circuitBreakers *client.CircuitBreakerRegistry
+	rateLimits      *ratelimit.Limits
+	logLevel        zap.AtomicLevel
+	events          event.Publisher
+	logger          *zap.Logger
+}
+
+func NewReloader(params ReloaderParams) *Reloader {
+	reloader := &Reloader{
+		loader:          params.Loader,
+		current:         params.Config,
+		cacheTTL:        params.CacheTTL,
+		circuitBreakers: params.CircuitBreakers,
+		rateLimits:      params.RateLimits,
+		logLevel:        params.LogLevel,
+		events:          params.Events,
+		logger:          params.Logger,
+	}
+	// The logger is built before the settings are read
+	reloader.logLevel.SetLevel(params.Config.Log.Level)
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			signal.Notify(signals, syscall.SIGHUP)
+			go reloader.watch(signals, done)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			signal.Stop(signals)
+			close(done)
+			return nil
+		},
+	})
+
+	return reloader
+}
+
+func (r *Reloader) watch(signals <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-signals:
+			if _, err := r.Reload(context.Background()); err != nil {
+				r.logger.Error("failed to reload config, keeping current settings", zap.Error(err))
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Reload rereads the settings and applies the reloadable changes. An invalid
+// configuration changes nothing
+func (r *Reloader) Reload(ctx context.Context) (ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.loader.Load()
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	// The limits are only validated with rate limiting enabled, so they are
+	// checked here before anything changes
+	if err := r.rateLimits.Set(next.RateLimit); err != nil {
+		return ReloadResult{}, err
+	}
+
+	var result ReloadResult
+	previous := r.current.Effective()
+	for name, value := range next.Effective() {
+		if previous[name] == value {
+			continue
+		}
+		if reloadable[name] {
+			result.Applied = append(result.Applied, name)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, name)
+		}
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.RestartRequired)
+
+	r.logLevel.SetLevel(next.Log.Level)
+	r.cacheTTL.Set(next.Cache.ExpiredTime)
+	r.circuitBreakers.SetTripThresholds(next.CircuitBreaker.TripThresholds())
+
+	// Only the applied settings become current, so a setting waiting for a
+	// restart keeps being reported
+	r.current.Log = next.Log
+	r.current.Cache.ExpiredTime = next.Cache.ExpiredTime
+	r.current.CircuitBreaker.MinRequestsBeforeTrip = next.CircuitBreaker.MinRequestsBeforeTrip
+	r.current.CircuitBreaker.FailureThresholdPercent = next.CircuitBreaker.FailureThresholdPercent
+	r.current.RateLimit.IPRPS, r.current.RateLimit.IPBurst = next.RateLimit.IPRPS, next.RateLimit.IPBurst
+	r.current.RateLimit.KeyRPS, r.current.RateLimit.KeyBurst = next.RateLimit.KeyRPS, next.RateLimit.KeyBurst
+
+	r.logger.Info("config reloaded",
+		zap.Strings("applied", result.Applied),
+		zap.Strings("restart_required", result.RestartRequired),
+	)
+	if len(result.Applied) > 0 || len(result.RestartRequired) > 0 {
+		r.events.Publish(ctx, event.TypeConfigReloaded, map[string]string{
+			"applied":          strings.Join(result.Applied, ","),
+			"restart_required": strings.Join(result.RestartRequired, ","),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	mockevent "github.com/koungkub/fw-challenge-notification-service/internal/event/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedResult ReloadResult
+		expectedEvent  map[string]string
+		expectedLevel  zapcore.Level
+		expectedTTL    time.Duration
+		expectedKey    ratelimit.Limit
+	}{
+		{
+			name:    "applies reloadable settings",
+			content: "LOG_LEVEL: debug\nCACHE_EXPIRED_TIME: 1m\nCIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT: 80\n",
+			expectedResult: ReloadResult{
+				Applied: []string{"CACHE_EXPIRED_TIME", "CIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT", "LOG_LEVEL"},
+			},
+			expectedEvent: map[string]string{
+				"applied":          "CACHE_EXPIRED_TIME,CIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT,LOG_LEVEL",
+				"restart_required": "",
+			},
+			expectedLevel: zapcore.DebugLevel,
+			expectedTTL:   time.Minute,
+			expectedKey:   ratelimit.Limit{RPS: 50, Burst: 100},
+		},
+		{
+			name:    "applies rate limits",
+			content: "RATE_LIMIT_KEY_RPS: 5\nRATE_LIMIT_KEY_BURST: 10\nRATE_LIMIT_BACKEND: redis\n",
+			expectedResult: ReloadResult{
+				Applied:         []string{"RATE_LIMIT_KEY_BURST", "RATE_LIMIT_KEY_RPS"},
+				RestartRequired: []string{"RATE_LIMIT_BACKEND"},
+			},
+			expectedEvent: map[string]string{
+				"applied":          "RATE_LIMIT_KEY_BURST,RATE_LIMIT_KEY_RPS",
+				"restart_required": "RATE_LIMIT_BACKEND",
+			},
+			expectedLevel: zapcore.InfoLevel,
+			expectedTTL:   10 * time.Minute,
+			expectedKey:   ratelimit.Limit{RPS: 5, Burst: 10},
+		},
+		{
+			name:    "reports settings requiring a restart",
+			content: "LOG_LEVEL: warn\nHTTP_SERVER_PORT: \":9000\"\n",
+			expectedResult: ReloadResult{
+				Applied:         []string{"LOG_LEVEL"},
+				RestartRequired: []string{"HTTP_SERVER_PORT"},
+			},
+			expectedEvent: map[string]string{
+				"applied":          "LOG_LEVEL",
+				"restart_required": "HTTP_SERVER_PORT",
+			},
+			expectedLevel: zapcore.WarnLevel,
+			expectedTTL:   10 * time.Minute,
+			expectedKey:   ratelimit.Limit{RPS: 50, Burst: 100},
+		},
+		{
+			name:          "publishes nothing when unchanged",
+			content:       "CACHE_EXPIRED_TIME: 10m\n",
+			expectedLevel: zapcore.InfoLevel,
+			expectedTTL:   10 * time.Minute,
+			expectedKey:   ratelimit.Limit{RPS: 50, Burst: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			setRequired(t)
+			unsetEnv(t, "LOG_LEVEL")
+			unsetEnv(t, "CACHE_EXPIRED_TIME")
+			unsetEnv(t, "CIRCUIT_BREAKER_FAILURE_THRESHOLD_PERCENT")
+			unsetEnv(t, "HTTP_SERVER_PORT")
+			unsetEnv(t, "RATE_LIMIT_KEY_RPS")
+			unsetEnv(t, "RATE_LIMIT_KEY_BURST")
+			unsetEnv(t, "RATE_LIMIT_BACKEND")
+			path := writeFile(t, "config.yaml", "LOG_LEVEL: info\n")
+			t.Setenv(FileEnv, path)
+
+			loader := NewLoader()
+			cfg, err := loader.Load()
+			require.NoError(t, err)
+
+			mockEvents := mockevent.NewMockPublisher(ctrl)
+			if tt.expectedEvent != nil {
+				mockEvents.EXPECT().Publish(gomock.Any(), event.TypeConfigReloaded, tt.expectedEvent)
+			}
+
+			level := zap.NewAtomicLevel()
+			cacheTTL := repository.NewCacheTTL(cfg.Cache)
+			rateLimits := ratelimit.NewLimits(cfg.RateLimit)
+			reloader := NewReloader(ReloaderParams{
+				Lifecycle: fxtest.NewLifecycle(t),
+				Loader:    loader,
+				Config:    cfg,
+				CacheTTL:  cacheTTL,
+				CircuitBreakers: client.NewCircuitBreakerRegistry(client.CircuitBreakerRegistryParams{
+					Config: cfg.CircuitBreaker,
+					Logger: zap.NewNop(),
+				}),
+				RateLimits: rateLimits,
+				LogLevel:   level,
+				Events:     mockEvents,
+				Logger:     zap.NewNop(),
+			})
+
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			result, err := reloader.Reload(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedLevel, level.Level())
+			assert.Equal(t, tt.expectedTTL, cacheTTL.Get())
+			assert.Equal(t, tt.expectedKey, rateLimits.Key())
+		})
+	}
+}
+
+func TestReloader_Reload_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{name: "rejects an unknown log level", content: "LOG_LEVEL: loud\n", expectedError: "LOG_LEVEL"},
+		{
+			name:          "rejects an enabled rate limit of zero",
+			content:       "LOG_LEVEL: warn\nRATE_LIMIT_ENABLED: true\nRATE_LIMIT_KEY_RPS: 0\n",
+			expectedError: "rate limit key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			unsetEnv(t, "LOG_LEVEL")
+			unsetEnv(t, "RATE_LIMIT_ENABLED")
+			unsetEnv(t, "RATE_LIMIT_KEY_RPS")
+			path := writeFile(t, "config.yaml", "LOG_LEVEL: debug\n")
+			t.Setenv(FileEnv, path)
+
+			loader := NewLoader()
+			cfg, err := loader.Load()
+			require.NoError(t, err)
+
+			level := zap.NewAtomicLevel()
+			rateLimits := ratelimit.NewLimits(cfg.RateLimit)
+			reloader := NewReloader(ReloaderParams{
+				Lifecycle: fxtest.NewLifecycle(t),
+				Loader:    loader,
+				Config:    cfg,
+				CacheTTL:  repository.NewCacheTTL(cfg.Cache),
+				CircuitBreakers: client.NewCircuitBreakerRegistry(client.CircuitBreakerRegistryParams{
+					Config: cfg.CircuitBreaker,
+					Logger: zap.NewNop(),
+				}),
+				RateLimits: rateLimits,
+				LogLevel:   level,
+				Logger:     zap.NewNop(),
+			})
+
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			_, err = reloader.Reload(context.Background())
+
+			require.ErrorContains(t, err, tt.expectedError)
+			assert.Equal(t, zapcore.DebugLevel, level.Level())
+			assert.Equal(t, ratelimit.Limit{RPS: 50, Burst: 100}, rateLimits.Key())
+		})
+	}
+}
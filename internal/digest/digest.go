@@ -0,0 +1,211 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Module("digest",
+	fx.Invoke(RegisterFlusher),
+)
+
+type FlusherParams struct {
+	fx.In
+
+	Lifecycle        fx.Lifecycle
+	Config           service.DigestConfig
+	Digests          repository.DigestProvider
+	Service          service.NotificationProvider
+	MetricsCollector *metrics.NotificationCollector
+	Clock            clock.Clock
+	Logger           *zap.Logger
+}
+
+// RegisterFlusher starts sending digests with the application when digests
+// are enabled
+func RegisterFlusher(params FlusherParams) error {
+	if params.Config.Interval <= 0 {
+		return nil
+	}
+	if params.Config.MaxItems < 1 {
+		return errors.New("digest max items must be at least 1")
+	}
+
+	flusher := NewFlusher(params)
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			flusher.Start()
+			return nil
+		},
+		OnStop: flusher.Stop,
+	})
+
+	return nil
+}
+
+// Flusher sends the buffered notifications of every recipient as one
+// notification each interval. Instances share the buffer, each digest being
+// claimed by one of them
+type Flusher struct {
+	config           service.DigestConfig
+	digests          repository.DigestProvider
+	service          service.NotificationProvider
+	metricsCollector *metrics.NotificationCollector
+	clock            clock.Clock
+	logger           *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewFlusher(params FlusherParams) *Flusher {
+	return &Flusher{
+		config:           params.Config,
+		digests:          params.Digests,
+		service:          params.Service,
+		metricsCollector: params.MetricsCollector,
+		clock:            params.Clock,
+		logger:           params.Logger,
+	}
+}
+
+// Start flushes every interval until Stop
+func (f *Flusher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.clock.After(f.config.Interval):
+			}
+
+			f.Flush(ctx)
+		}
+	}()
+}
+
+// Stop ends flushing and waits for the digest being sent to finish or ctx
+// to end
+func (f *Flusher) Stop(ctx context.Context) error {
+	if f.cancel == nil {
+		return nil
+	}
+	f.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush sends a digest to every recipient with buffered notifications
+func (f *Flusher) Flush(ctx context.Context) {
+	for ctx.Err() == nil {
+		// A claim outliving an interval belongs to a flush that died
+		items, err := f.digests.ClaimDigest(ctx, f.config.Interval)
+		if err != nil {
+			if ctx.Err() == nil {
+				f.logger.Error("failed to claim digest", zap.Error(err))
+			}
+			return
+		}
+		if len(items) == 0 {
+			return
+		}
+
+		f.send(context.WithoutCancel(ctx), items)
+	}
+}
+
+// send delivers one digest. A digest no provider accepted keeps its claim,
+// so a later flush takes it over; any other outcome forgets the items, as
+// sending them again would repeat or fail again
+func (f *Flusher) send(ctx context.Context, items []repository.DigestItem) {
+	recipientType := items[0].RecipientType
+
+	report, err := f.service.Send(ctx, recipientType, Combine(items, f.config.MaxItems))
+	if err != nil && report.RetryDisposition == service.RetrySafe {
+		f.logger.Warn("failed to send digest, retrying at a later flush",
+			zap.String("recipient_type", recipientType),
+			zap.String("notification_id", report.ID),
+			zap.Int("items", len(items)),
+			zap.Error(err),
+		)
+		return
+	}
+	if err != nil {
+		f.logger.Error("failed to send digest, dropping it",
+			zap.String("recipient_type", recipientType),
+			zap.String("notification_id", report.ID),
+			zap.String("retry_disposition", report.RetryDisposition),
+			zap.Int("items", len(items)),
+			zap.Error(err),
+		)
+	} else {
+		f.metricsCollector.RecordDigestSent(ctx, recipientType, len(items))
+	}
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	if err := f.digests.DeleteDigestItems(ctx, ids); err != nil {
+		f.logger.Error("failed to delete sent digest items",
+			zap.String("recipient_type", recipientType),
+			zap.Int("items", len(items)),
+			zap.Error(err),
+		)
+	}
+}
+
+// Combine builds the digest of the items of one recipient: a single item is
+// sent as it was, more are listed by title, at most maxItems of them
+func Combine(items []repository.DigestItem, maxItems int) service.Notification {
+	notification := service.Notification{
+		To:       items[0].Recipient,
+		Category: items[0].Category,
+		Priority: dispatch.PriorityNormal,
+	}
+	if len(items) == 1 {
+		notification.Title = items[0].Title
+		notification.Message = items[0].Message
+		notification.DeepLink = items[0].DeepLink
+		return notification
+	}
+
+	lines := make([]string, 0, min(len(items), maxItems)+1)
+	for _, item := range items[:min(len(items), maxItems)] {
+		lines = append(lines, "- "+item.Title)
+	}
+	if len(items) > maxItems {
+		lines = append(lines, fmt.Sprintf("and %d more", len(items)-maxItems))
+	}
+
+	notification.Title = fmt.Sprintf("%d new notifications", len(items))
+	notification.Message = strings.Join(lines, "\n")
+	return notification
+}
@@ -0,0 +1,180 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestCombine(t *testing.T) {
+	item := func(id uint, title string) repository.DigestItem {
+		return repository.DigestItem{
+			ID:            id,
+			RecipientType: "seller",
+			Recipient:     "seller@example.com",
+			Category:      "reminder",
+			Title:         title,
+			Message:       title + " is waiting",
+			DeepLink:      "app://orders",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		items    []repository.DigestItem
+		maxItems int
+		expected service.Notification
+	}{
+		{
+			name:     "sends a single item as it was",
+			items:    []repository.DigestItem{item(1, "Order 1")},
+			maxItems: 10,
+			expected: service.Notification{
+				To:       "seller@example.com",
+				Category: "reminder",
+				Priority: dispatch.PriorityNormal,
+				Title:    "Order 1",
+				Message:  "Order 1 is waiting",
+				DeepLink: "app://orders",
+			},
+		},
+		{
+			name:     "lists several items by title",
+			items:    []repository.DigestItem{item(1, "Order 1"), item(2, "Order 2")},
+			maxItems: 10,
+			expected: service.Notification{
+				To:       "seller@example.com",
+				Category: "reminder",
+				Priority: dispatch.PriorityNormal,
+				Title:    "2 new notifications",
+				Message:  "- Order 1\n- Order 2",
+			},
+		},
+		{
+			name:     "counts the items beyond the maximum",
+			items:    []repository.DigestItem{item(1, "Order 1"), item(2, "Order 2"), item(3, "Order 3")},
+			maxItems: 1,
+			expected: service.Notification{
+				To:       "seller@example.com",
+				Category: "reminder",
+				Priority: dispatch.PriorityNormal,
+				Title:    "3 new notifications",
+				Message:  "- Order 1\nand 2 more",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Combine(tt.items, tt.maxItems))
+		})
+	}
+}
+
+func TestFlusher_Flush(t *testing.T) {
+	seller := []repository.DigestItem{
+		{ID: 1, RecipientType: "seller", Recipient: "seller@example.com", Title: "Order 1"},
+		{ID: 3, RecipientType: "seller", Recipient: "seller@example.com", Title: "Order 3"},
+	}
+	buyer := []repository.DigestItem{
+		{ID: 2, RecipientType: "buyer", Recipient: "buyer@example.com", Title: "Price drop"},
+	}
+
+	tests := []struct {
+		name       string
+		setupMocks func(*mockrepository.MockDigestProvider, *mockservice.MockNotificationProvider)
+	}{
+		{
+			name: "sends every claimed digest and forgets its items",
+			setupMocks: func(digests *mockrepository.MockDigestProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(seller, nil),
+					notifications.EXPECT().Send(gomock.Any(), "seller", Combine(seller, 10)).
+						Return(service.DeliveryReport{RetryDisposition: service.RetryNotNeeded}, nil),
+					digests.EXPECT().DeleteDigestItems(gomock.Any(), []uint{1, 3}).Return(nil),
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(buyer, nil),
+					notifications.EXPECT().Send(gomock.Any(), "buyer", Combine(buyer, 10)).
+						Return(service.DeliveryReport{RetryDisposition: service.RetryNotNeeded}, nil),
+					digests.EXPECT().DeleteDigestItems(gomock.Any(), []uint{2}).Return(nil),
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(nil, nil),
+				)
+			},
+		},
+		{
+			name: "keeps the claim of a digest safe to retry",
+			setupMocks: func(digests *mockrepository.MockDigestProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(seller, nil),
+					notifications.EXPECT().Send(gomock.Any(), "seller", gomock.Any()).
+						Return(service.DeliveryReport{RetryDisposition: service.RetrySafe}, errors.New("provider down")),
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(nil, nil),
+				)
+			},
+		},
+		{
+			name: "forgets a digest that may have been delivered",
+			setupMocks: func(digests *mockrepository.MockDigestProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(seller, nil),
+					notifications.EXPECT().Send(gomock.Any(), "seller", gomock.Any()).
+						Return(service.DeliveryReport{RetryDisposition: service.RetryUnsafe}, errors.New("timeout")),
+					digests.EXPECT().DeleteDigestItems(gomock.Any(), []uint{1, 3}).Return(nil),
+					digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(nil, nil),
+				)
+			},
+		},
+		{
+			name: "stops when claiming fails",
+			setupMocks: func(digests *mockrepository.MockDigestProvider, _ *mockservice.MockNotificationProvider) {
+				digests.EXPECT().ClaimDigest(gomock.Any(), time.Hour).Return(nil, errors.New("database down"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			digests := mockrepository.NewMockDigestProvider(ctrl)
+			notifications := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(digests, notifications)
+
+			metricsCollector, err := metrics.NewNotificationCollector(nil)
+			require.NoError(t, err)
+
+			flusher := NewFlusher(FlusherParams{
+				Config:           service.DigestConfig{Interval: time.Hour, MaxItems: 10},
+				Digests:          digests,
+				Service:          notifications,
+				MetricsCollector: metricsCollector,
+				Logger:           zap.NewNop(),
+			})
+
+			flusher.Flush(context.Background())
+		})
+	}
+}
+
+func TestRegisterFlusher(t *testing.T) {
+	t.Run("does nothing while disabled", func(t *testing.T) {
+		assert.NoError(t, RegisterFlusher(FlusherParams{}))
+	})
+
+	t.Run("rejects digests listing no item", func(t *testing.T) {
+		err := RegisterFlusher(FlusherParams{Config: service.DigestConfig{Interval: time.Hour}})
+		assert.EqualError(t, err, "digest max items must be at least 1")
+	})
+}
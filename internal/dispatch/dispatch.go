@@ -0,0 +1,183 @@
+package dispatch
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("dispatch",
+	fx.Provide(
+		fx.Annotate(
+			NewPriorityDispatcher,
+			fx.As(new(Dispatcher)),
+		),
+	),
+)
+
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	PriorityLow    Priority = "low"
+)
+
+// priorities lists the queues in the order they are served
+var priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// Stats is a point-in-time view of the dispatcher
+type Stats struct {
+	MaxConcurrent int
+	InFlight      int
+	Queued        map[Priority]int
+}
+
+//go:generate mockgen -package mockdispatch -destination ./mock/mockdispatch.go . Dispatcher
+type Dispatcher interface {
+	// Acquire blocks until a delivery slot is free for the priority or ctx
+	// ends; release must be called exactly once when the delivery finished
+	Acquire(ctx context.Context, priority Priority) (release func(), err error)
+	Stats() Stats
+}
+
+var _ Dispatcher = (*PriorityDispatcher)(nil)
+
+// PriorityDispatcher bounds concurrent deliveries and keeps one FIFO queue
+// per priority; a freed slot always goes to the highest non-empty queue, so
+// high priority notifications overtake queued normal and low ones
+type PriorityDispatcher struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	inFlight      int
+	queues        map[Priority]*list.List
+
+	clock   clock.Clock
+	metrics *metrics.DispatchCollector
+}
+
+type PriorityDispatcherParams struct {
+	fx.In
+
+	Config           DispatchConfig
+	Clock            clock.Clock
+	MetricsCollector *metrics.DispatchCollector
+}
+
+func NewPriorityDispatcher(params PriorityDispatcherParams) (*PriorityDispatcher, error) {
+	if params.Config.MaxConcurrent < 1 {
+		return nil, fmt.Errorf("dispatch max concurrent: %d must be at least 1", params.Config.MaxConcurrent)
+	}
+
+	queues := make(map[Priority]*list.List, len(priorities))
+	for _, priority := range priorities {
+		queues[priority] = list.New()
+	}
+
+	return &PriorityDispatcher{
+		maxConcurrent: params.Config.MaxConcurrent,
+		queues:        queues,
+		clock:         params.Clock,
+		metrics:       params.MetricsCollector,
+	}, nil
+}
+
+type DispatchConfig struct {
+	MaxConcurrent int `envconfig:"DISPATCH_MAX_CONCURRENT" default:"100"`
+}
+
+// Acquire treats an empty or unknown priority as normal
+func (d *PriorityDispatcher) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	queue, ok := d.queues[priority]
+	if !ok {
+		priority = PriorityNormal
+		queue = d.queues[priority]
+	}
+	start := d.clock.Now()
+
+	d.mu.Lock()
+	if d.inFlight < d.maxConcurrent && d.queued() == 0 {
+		d.inFlight++
+		d.mu.Unlock()
+
+		d.metrics.RecordDispatched(ctx, string(priority), 0)
+		return d.release, nil
+	}
+
+	ready := make(chan struct{})
+	element := queue.PushBack(ready)
+	d.mu.Unlock()
+	d.metrics.RecordQueued(ctx, string(priority))
+
+	select {
+	case <-ready:
+		d.metrics.RecordDequeued(ctx, string(priority))
+		d.metrics.RecordDispatched(ctx, string(priority), d.clock.Since(start))
+		return d.release, nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	select {
+	case <-ready:
+		// The slot was handed over while ctx ended; pass it on
+		d.mu.Unlock()
+		d.release()
+	default:
+		queue.Remove(element)
+		d.mu.Unlock()
+	}
+
+	d.metrics.RecordDequeued(ctx, string(priority))
+	d.metrics.RecordAbandoned(ctx, string(priority))
+	return nil, ctx.Err()
+}
+
+// release hands the slot to the oldest waiter of the highest priority, or
+// frees it when nobody waits
+func (d *PriorityDispatcher) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, priority := range priorities {
+		queue := d.queues[priority]
+		if queue.Len() == 0 {
+			continue
+		}
+
+		ready := queue.Remove(queue.Front()).(chan struct{})
+		close(ready)
+		return
+	}
+
+	d.inFlight--
+}
+
+func (d *PriorityDispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := Stats{
+		MaxConcurrent: d.maxConcurrent,
+		InFlight:      d.inFlight,
+		Queued:        make(map[Priority]int, len(priorities)),
+	}
+	for _, priority := range priorities {
+		stats.Queued[priority] = d.queues[priority].Len()
+	}
+	return stats
+}
+
+// queued must be called with mu held
+func (d *PriorityDispatcher) queued() int {
+	var total int
+	for _, queue := range d.queues {
+		total += queue.Len()
+	}
+	return total
+}
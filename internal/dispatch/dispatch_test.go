@@ -0,0 +1,169 @@
+package dispatch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDispatcher(t *testing.T, maxConcurrent int) *PriorityDispatcher {
+	metricsCollector, _ := metrics.NewDispatchCollector(nil)
+	dispatcher, err := NewPriorityDispatcher(PriorityDispatcherParams{
+		Config:           DispatchConfig{MaxConcurrent: maxConcurrent},
+		Clock:            clock.NewRealClock(),
+		MetricsCollector: metricsCollector,
+	})
+	require.NoError(t, err)
+	return dispatcher
+}
+
+// waitQueued blocks until the dispatcher has the expected number of waiters
+func waitQueued(t *testing.T, dispatcher *PriorityDispatcher, priority Priority, expected int) {
+	require.Eventually(t, func() bool {
+		return dispatcher.Stats().Queued[priority] == expected
+	}, time.Second, time.Millisecond)
+}
+
+func TestNewPriorityDispatcher(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		expectError   bool
+	}{
+		{name: "accepts a positive limit", maxConcurrent: 1},
+		{name: "rejects zero", maxConcurrent: 0, expectError: true},
+		{name: "rejects negative", maxConcurrent: -1, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, err := NewPriorityDispatcher(PriorityDispatcherParams{
+				Config: DispatchConfig{MaxConcurrent: tt.maxConcurrent},
+			})
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.maxConcurrent, dispatcher.Stats().MaxConcurrent)
+		})
+	}
+}
+
+func TestPriorityDispatcher_Acquire(t *testing.T) {
+	t.Run("dispatches immediately while slots are free", func(t *testing.T) {
+		dispatcher := newTestDispatcher(t, 2)
+
+		releaseFirst, err := dispatcher.Acquire(context.Background(), PriorityLow)
+		require.NoError(t, err)
+		releaseSecond, err := dispatcher.Acquire(context.Background(), PriorityNormal)
+		require.NoError(t, err)
+		assert.Equal(t, 2, dispatcher.Stats().InFlight)
+
+		releaseFirst()
+		releaseSecond()
+		assert.Equal(t, 0, dispatcher.Stats().InFlight)
+	})
+
+	t.Run("serves high before normal before low", func(t *testing.T) {
+		dispatcher := newTestDispatcher(t, 1)
+
+		release, err := dispatcher.Acquire(context.Background(), PriorityNormal)
+		require.NoError(t, err)
+
+		var (
+			mu    sync.Mutex
+			order []Priority
+			wg    sync.WaitGroup
+		)
+		enqueue := func(priority Priority) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, err := dispatcher.Acquire(context.Background(), priority)
+				if !assert.NoError(t, err) {
+					return
+				}
+				mu.Lock()
+				order = append(order, priority)
+				mu.Unlock()
+				release()
+			}()
+			waitQueued(t, dispatcher, priority, 1)
+		}
+
+		enqueue(PriorityLow)
+		enqueue(PriorityNormal)
+		enqueue(PriorityHigh)
+
+		release()
+		wg.Wait()
+
+		assert.Equal(t, []Priority{PriorityHigh, PriorityNormal, PriorityLow}, order)
+		assert.Equal(t, 0, dispatcher.Stats().InFlight)
+	})
+
+	t.Run("new arrivals queue behind waiters", func(t *testing.T) {
+		dispatcher := newTestDispatcher(t, 1)
+
+		release, err := dispatcher.Acquire(context.Background(), PriorityLow)
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			release, err := dispatcher.Acquire(context.Background(), PriorityHigh)
+			if assert.NoError(t, err) {
+				release()
+			}
+		}()
+		waitQueued(t, dispatcher, PriorityHigh, 1)
+
+		release()
+		<-done
+	})
+
+	t.Run("abandons the queue when ctx ends", func(t *testing.T) {
+		dispatcher := newTestDispatcher(t, 1)
+
+		release, err := dispatcher.Acquire(context.Background(), PriorityNormal)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = dispatcher.Acquire(ctx, PriorityHigh)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, dispatcher.Stats().Queued[PriorityHigh])
+
+		release()
+		assert.Equal(t, 0, dispatcher.Stats().InFlight)
+	})
+
+	t.Run("unknown priority is treated as normal", func(t *testing.T) {
+		dispatcher := newTestDispatcher(t, 1)
+
+		release, err := dispatcher.Acquire(context.Background(), PriorityNormal)
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			release, err := dispatcher.Acquire(context.Background(), "")
+			if assert.NoError(t, err) {
+				release()
+			}
+		}()
+		waitQueued(t, dispatcher, PriorityNormal, 1)
+
+		release()
+		<-done
+	})
+}
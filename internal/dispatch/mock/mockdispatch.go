@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/dispatch (interfaces: Dispatcher)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdispatch -destination ./mock/mockdispatch.go . Dispatcher
+//
+
+// Package mockdispatch is a generated GoMock package.
+package mockdispatch
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dispatch "github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDispatcher is a mock of Dispatcher interface.
+type MockDispatcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockDispatcherMockRecorder
+	isgomock struct{}
+}
+
+// MockDispatcherMockRecorder is the mock recorder for MockDispatcher.
+type MockDispatcherMockRecorder struct {
+	mock *MockDispatcher
+}
+
+// NewMockDispatcher creates a new mock instance.
+func NewMockDispatcher(ctrl *gomock.Controller) *MockDispatcher {
+	mock := &MockDispatcher{ctrl: ctrl}
+	mock.recorder = &MockDispatcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDispatcher) EXPECT() *MockDispatcherMockRecorder {
+	return m.recorder
+}
+
+// Acquire mocks base method.
+func (m *MockDispatcher) Acquire(ctx context.Context, priority dispatch.Priority) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acquire", ctx, priority)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acquire indicates an expected call of Acquire.
+func (mr *MockDispatcherMockRecorder) Acquire(ctx, priority any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acquire", reflect.TypeOf((*MockDispatcher)(nil).Acquire), ctx, priority)
+}
+
+// Stats mocks base method.
+func (m *MockDispatcher) Stats() dispatch.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(dispatch.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockDispatcherMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockDispatcher)(nil).Stats))
+}
@@ -0,0 +1,202 @@
+package errortracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/koungkub/fw-challenge-notification-service/internal/buildinfo"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Event levels, as Sentry names them
+const (
+	LevelFatal   = "fatal"
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// maxBuffered bounds the events waiting to be sent, so an error storm drops
+// events rather than piling them up
+const maxBuffered = 100
+
+var errInvalidDSN = errors.New("sentry dsn: use https://<key>@<host>/<project>")
+
+var Module = fx.Module("errortracking",
+	fx.Provide(
+		fx.Annotate(
+			NewSentryTracker,
+			fx.As(new(Tracker)),
+		),
+	),
+)
+
+type ErrorTrackingConfig struct {
+	// DSN is the Sentry project events are sent to; they are only logged
+	// when empty
+	DSN         string        `envconfig:"SENTRY_DSN" secret:"true"`
+	Timeout     time.Duration `envconfig:"SENTRY_TIMEOUT" default:"5s"`
+	Environment string        `envconfig:"APP_ENV" default:"development"`
+	// Release falls back to the version of the build
+	Release string `envconfig:"APP_VERSION"`
+}
+
+// Event is an error, panic or failure worth a look from the team. The
+// request context fields of the context it is captured with are added to
+// its tags
+type Event struct {
+	Level   string
+	Message string
+	Err     error
+	Tags    map[string]string
+	// Request is the request being served, if any
+	Request *Request
+	// Stack is the goroutine stack of a panic
+	Stack []byte
+}
+
+// Request is the HTTP request an event happened while serving
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+//go:generate mockgen -package mockerrortracking -destination ./mock/mockerrortracking.go . Tracker
+type Tracker interface {
+	// Capture reports event; it never blocks on the error tracker
+	Capture(ctx context.Context, event Event)
+}
+
+var _ Tracker = (*SentryTracker)(nil)
+
+// SentryTracker logs every event and sends it to Sentry in the background
+type SentryTracker struct {
+	// client is nil without a DSN
+	client *sentry.Client
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+type SentryTrackerParams struct {
+	fx.In
+
+	Config ErrorTrackingConfig
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+func NewSentryTracker(lc fx.Lifecycle, params SentryTrackerParams) (*SentryTracker, error) {
+	tracker := &SentryTracker{
+		clock:  params.Clock,
+		logger: params.Logger,
+	}
+	if params.Config.DSN == "" {
+		return tracker, nil
+	}
+
+	transport := sentry.NewHTTPTransport()
+	transport.BufferSize = maxBuffered
+	transport.Timeout = params.Config.Timeout
+
+	serverName, _ := os.Hostname()
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         params.Config.DSN,
+		Environment: params.Config.Environment,
+		Release:     release(params.Config),
+		ServerName:  serverName,
+		Transport:   transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidDSN, err)
+	}
+	tracker.client = client
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			defer client.Close()
+			if !client.FlushWithContext(ctx) {
+				return ctx.Err()
+			}
+			return nil
+		},
+	})
+
+	return tracker, nil
+}
+
+// release prefers the configured version and falls back to the version of
+// the build, as the service version of the metrics does
+func release(config ErrorTrackingConfig) string {
+	if config.Release != "" {
+		return config.Release
+	}
+
+	if version := buildinfo.Get().Version; version != buildinfo.Unknown {
+		return version
+	}
+	return ""
+}
+
+func (t *SentryTracker) Capture(ctx context.Context, event Event) {
+	if event.Level == "" {
+		event.Level = LevelError
+	}
+	if event.Message == "" && event.Err != nil {
+		event.Message = event.Err.Error()
+	}
+
+	logging.From(ctx, t.logger).Error("captured error",
+		zap.String("level", event.Level),
+		zap.String("event", event.Message),
+		zap.Error(event.Err),
+	)
+
+	if t.client == nil {
+		return
+	}
+	// The transport queues the event, dropping it once maxBuffered are
+	// waiting
+	t.client.CaptureEvent(t.sentryEvent(ctx, event), nil, nil)
+}
+
+// sentryEvent renders event for Sentry. Recipient addresses are masked as
+// they are in logs
+func (t *SentryTracker) sentryEvent(ctx context.Context, event Event) *sentry.Event {
+	payload := sentry.NewEvent()
+	payload.Level = sentry.Level(event.Level)
+	payload.Timestamp = t.clock.Now().UTC()
+	payload.Message = logging.MaskEmails(event.Message)
+	payload.Tags = tags(ctx, event.Tags)
+	if event.Err != nil {
+		payload.Exception = []sentry.Exception{{
+			Type:  fmt.Sprintf("%T", event.Err),
+			Value: logging.MaskEmails(event.Err.Error()),
+		}}
+	}
+	if event.Request != nil {
+		payload.Request = &sentry.Request{Method: event.Request.Method, URL: logging.MaskEmails(event.Request.URL)}
+	}
+	if len(event.Stack) > 0 {
+		payload.Contexts["panic"] = sentry.Context{"stack": string(event.Stack)}
+	}
+	return payload
+}
+
+// tags adds the request context fields of ctx, such as the request id and
+// tenant, to the tags of the event
+func tags(ctx context.Context, eventTags map[string]string) map[string]string {
+	result := make(map[string]string, len(eventTags))
+	for _, field := range logging.Fields(ctx) {
+		result[field.Key] = field.String
+	}
+	for key, value := range eventTags {
+		result[key] = value
+	}
+	return result
+}
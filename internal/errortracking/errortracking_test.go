@@ -0,0 +1,125 @@
+package errortracking
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// newTestSentry records the envelopes posted to it
+func newTestSentry(t *testing.T) (*httptest.Server, func() [][]map[string]any) {
+	var (
+		mu        sync.Mutex
+		envelopes [][]map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/envelope/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+		var envelope []map[string]any
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var line map[string]any
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			envelope = append(envelope, line)
+		}
+
+		mu.Lock()
+		envelopes = append(envelopes, envelope)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() [][]map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return envelopes
+	}
+}
+
+func TestSentryTracker_Capture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, envelopes := newTestSentry(t)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).AnyTimes()
+
+	lc := fxtest.NewLifecycle(t)
+	tracker, err := NewSentryTracker(lc, SentryTrackerParams{
+		Config: ErrorTrackingConfig{
+			DSN:         strings.Replace(server.URL, "://", "://public@", 1) + "/42",
+			Timeout:     time.Second,
+			Environment: "production",
+			Release:     "v1.4.0",
+		},
+		Clock:  clock,
+		Logger: zap.NewNop(),
+	})
+	require.NoError(t, err)
+	lc.RequireStart()
+
+	ctx := logging.With(context.Background(),
+		zap.String(logging.FieldRequestID, "req-1"),
+		zap.String(logging.FieldTenant, "acme"),
+	)
+	tracker.Capture(ctx, Event{
+		Message: "POST /api/v1.0/recipient/:recipient/notify answered 500",
+		Err:     errors.New("unknown recipient buyer@example.com"),
+		Tags:    map[string]string{"http.status_code": "500"},
+		Request: &Request{Method: http.MethodPost, URL: "/api/v1.0/recipient/buyer/notify"},
+	})
+	lc.RequireStop()
+
+	require.Len(t, envelopes(), 1)
+	envelope := envelopes()[0]
+	require.Len(t, envelope, 3)
+	assert.Equal(t, "event", envelope[1]["type"])
+
+	event := envelope[2]
+	assert.Equal(t, envelope[0]["event_id"], event["event_id"])
+	assert.Equal(t, LevelError, event["level"])
+	assert.Equal(t, "production", event["environment"])
+	assert.Equal(t, "v1.4.0", event["release"])
+	assert.Equal(t, "2025-06-01T12:00:00Z", event["timestamp"])
+	assert.Equal(t, map[string]any{"request_id": "req-1", "tenant": "acme", "http.status_code": "500"}, event["tags"])
+	assert.Equal(t, map[string]any{"method": "POST", "url": "/api/v1.0/recipient/buyer/notify"}, event["request"])
+	assert.Equal(t, []any{map[string]any{"type": "*errors.errorString", "value": "unknown recipient b***@example.com"}}, event["exception"])
+}
+
+func TestSentryTracker_Capture_WithoutDSN(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	tracker, err := NewSentryTracker(lc, SentryTrackerParams{
+		Logger: zap.NewNop(),
+	})
+	require.NoError(t, err)
+
+	// Nothing is sent, so the clock is never read
+	tracker.Capture(context.Background(), Event{Err: errors.New("database unreachable")})
+	assert.Nil(t, tracker.client)
+}
+
+func TestNewSentryTracker_InvalidDSN(t *testing.T) {
+	_, err := NewSentryTracker(fxtest.NewLifecycle(t), SentryTrackerParams{
+		Config: ErrorTrackingConfig{DSN: "not a dsn"},
+		Logger: zap.NewNop(),
+	})
+
+	assert.ErrorIs(t, err, errInvalidDSN)
+}
@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/errortracking (interfaces: Tracker)
+//
+// Generated by this command:
+//
+//	mockgen -package mockerrortracking -destination ./mock/mockerrortracking.go . Tracker
+//
+
+// Package mockerrortracking is a generated GoMock package.
+package mockerrortracking
+
+import (
+	context "context"
+	reflect "reflect"
+
+	errortracking "github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTracker is a mock of Tracker interface.
+type MockTracker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTrackerMockRecorder
+	isgomock struct{}
+}
+
+// MockTrackerMockRecorder is the mock recorder for MockTracker.
+type MockTrackerMockRecorder struct {
+	mock *MockTracker
+}
+
+// NewMockTracker creates a new mock instance.
+func NewMockTracker(ctrl *gomock.Controller) *MockTracker {
+	mock := &MockTracker{ctrl: ctrl}
+	mock.recorder = &MockTrackerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTracker) EXPECT() *MockTrackerMockRecorder {
+	return m.recorder
+}
+
+// Capture mocks base method.
+func (m *MockTracker) Capture(ctx context.Context, event errortracking.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Capture", ctx, event)
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockTrackerMockRecorder) Capture(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockTracker)(nil).Capture), ctx, event)
+}
@@ -0,0 +1,155 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Lifecycle events of a notification
+const (
+	// TypeNotificationAccepted is a notification that passed its checks and
+	// is about to be delivered or digested
+	TypeNotificationAccepted = "notification.accepted"
+	// TypeNotificationSent is a notification delivered on some channel
+	TypeNotificationSent = "notification.sent"
+	// TypeNotificationFailed is an accepted notification that was not
+	// delivered, or a queued channel given up
+	TypeNotificationFailed = "notification.failed"
+	// TypeNotificationSuppressed is an email channel dropped because its
+	// address is suppressed
+	TypeNotificationSuppressed = "notification.suppressed"
+	// TypeNotificationRetried is one retry of a queued channel
+	TypeNotificationRetried = "notification.retried"
+)
+
+// NotificationEvent is a step in the life of one notification. Channels
+// are those the step concerns, e.g. the delivered channels of a sent
+// notification; Err is set for failures
+type NotificationEvent struct {
+	Type           string
+	NotificationID string
+	RecipientType  string
+	Channels       []string
+	Attempts       int
+	Err            error
+	OccurredAt     time.Time
+}
+
+//go:generate mockgen -package mockevent -destination ./mock/mockbus.go . Bus
+type Bus interface {
+	// Emit hands the event to every subscriber before returning
+	Emit(ctx context.Context, evt NotificationEvent)
+}
+
+// Subscriber observes notification lifecycle events. Subscribers run in
+// the goroutine emitting the event, so slow work such as exporting events
+// should be handed off rather than delay delivery
+type Subscriber interface {
+	HandleEvent(ctx context.Context, evt NotificationEvent)
+}
+
+// AsSubscriber annotates a subscriber constructor so fx adds the subscriber
+// to the "event_subscribers" group, e.g.
+// fx.Provide(event.AsSubscriber(NewAnalyticsExporter))
+func AsSubscriber(constructor any) any {
+	return fx.Annotate(
+		constructor,
+		fx.As(new(Subscriber)),
+		fx.ResultTags(`group:"event_subscribers"`),
+	)
+}
+
+var _ Bus = (*NotificationBus)(nil)
+
+// NotificationBus dispatches lifecycle events to the registered subscribers
+// in registration order
+type NotificationBus struct {
+	subscribers []Subscriber
+	clock       clock.Clock
+	logger      *zap.Logger
+}
+
+type NotificationBusParams struct {
+	fx.In
+
+	Clock       clock.Clock
+	Logger      *zap.Logger
+	Subscribers []Subscriber `group:"event_subscribers"`
+}
+
+func NewNotificationBus(params NotificationBusParams) *NotificationBus {
+	return &NotificationBus{
+		subscribers: params.Subscribers,
+		clock:       params.Clock,
+		logger:      params.Logger,
+	}
+}
+
+func (b *NotificationBus) Emit(ctx context.Context, evt NotificationEvent) {
+	if evt.OccurredAt.IsZero() {
+		evt.OccurredAt = b.clock.Now().UTC()
+	}
+	for _, subscriber := range b.subscribers {
+		b.handle(ctx, subscriber, evt)
+	}
+}
+
+// handle runs one subscriber; a panicking subscriber is logged so it can
+// neither fail the notification nor starve the others
+func (b *NotificationBus) handle(ctx context.Context, subscriber Subscriber, evt NotificationEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("event subscriber panicked",
+				zap.String("type", evt.Type),
+				zap.String("subscriber", fmt.Sprintf("%T", subscriber)),
+				zap.Any("panic", r),
+			)
+		}
+	}()
+
+	subscriber.HandleEvent(ctx, evt)
+}
+
+// LogSubscriber logs every event at debug level, tracing notifications
+// with LOG_LEVEL=debug
+type LogSubscriber struct {
+	logger *zap.Logger
+}
+
+func NewLogSubscriber(logger *zap.Logger) *LogSubscriber {
+	return &LogSubscriber{logger: logger}
+}
+
+func (s *LogSubscriber) HandleEvent(_ context.Context, evt NotificationEvent) {
+	fields := []zap.Field{
+		zap.String("type", evt.Type),
+		zap.String("notification_id", evt.NotificationID),
+		zap.String("recipient_type", evt.RecipientType),
+		zap.Strings("channels", evt.Channels),
+		zap.Int("attempts", evt.Attempts),
+		zap.Time("occurred_at", evt.OccurredAt),
+	}
+	if evt.Err != nil {
+		fields = append(fields, zap.Error(evt.Err))
+	}
+	s.logger.Debug("notification event", fields...)
+}
+
+// MetricsSubscriber counts events by type and recipient type
+type MetricsSubscriber struct {
+	metricsCollector *metrics.NotificationCollector
+}
+
+func NewMetricsSubscriber(metricsCollector *metrics.NotificationCollector) *MetricsSubscriber {
+	return &MetricsSubscriber{metricsCollector: metricsCollector}
+}
+
+func (s *MetricsSubscriber) HandleEvent(ctx context.Context, evt NotificationEvent) {
+	s.metricsCollector.RecordEvent(ctx, evt.Type, evt.RecipientType)
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingSubscriber keeps the events it receives, optionally panicking
+type recordingSubscriber struct {
+	events []NotificationEvent
+	panics bool
+}
+
+func (s *recordingSubscriber) HandleEvent(_ context.Context, evt NotificationEvent) {
+	s.events = append(s.events, evt)
+	if s.panics {
+		panic("subscriber broken")
+	}
+}
+
+func TestNotificationBus_Emit(t *testing.T) {
+	occurredAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("hands the event to every subscriber, stamping its time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clk := mockclock.NewMockClock(ctrl)
+		clk.EXPECT().Now().Return(occurredAt)
+		first, second := &recordingSubscriber{}, &recordingSubscriber{}
+
+		bus := NewNotificationBus(NotificationBusParams{
+			Clock:       clk,
+			Logger:      zap.NewNop(),
+			Subscribers: []Subscriber{first, second},
+		})
+		bus.Emit(context.Background(), NotificationEvent{Type: TypeNotificationSent, NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW"})
+
+		expected := []NotificationEvent{{Type: TypeNotificationSent, NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", OccurredAt: occurredAt}}
+		assert.Equal(t, expected, first.events)
+		assert.Equal(t, expected, second.events)
+	})
+
+	t.Run("keeps the time of an event that has one", func(t *testing.T) {
+		subscriber := &recordingSubscriber{}
+
+		bus := NewNotificationBus(NotificationBusParams{Logger: zap.NewNop(), Subscribers: []Subscriber{subscriber}})
+		bus.Emit(context.Background(), NotificationEvent{Type: TypeNotificationAccepted, OccurredAt: occurredAt})
+
+		require.Len(t, subscriber.events, 1)
+		assert.Equal(t, occurredAt, subscriber.events[0].OccurredAt)
+	})
+
+	t.Run("logs a panicking subscriber and carries on", func(t *testing.T) {
+		core, logs := observer.New(zap.ErrorLevel)
+		broken, healthy := &recordingSubscriber{panics: true}, &recordingSubscriber{}
+
+		bus := NewNotificationBus(NotificationBusParams{Logger: zap.New(core), Subscribers: []Subscriber{broken, healthy}})
+
+		assert.NotPanics(t, func() {
+			bus.Emit(context.Background(), NotificationEvent{Type: TypeNotificationFailed, OccurredAt: occurredAt})
+		})
+		assert.Len(t, healthy.events, 1)
+		require.Equal(t, 1, logs.FilterMessage("event subscriber panicked").Len())
+		assert.Equal(t, "*event.recordingSubscriber", logs.All()[0].ContextMap()["subscriber"])
+	})
+}
+
+func TestLogSubscriber_HandleEvent(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	subscriber := NewLogSubscriber(zap.New(core))
+
+	subscriber.HandleEvent(context.Background(), NotificationEvent{
+		Type:           TypeNotificationFailed,
+		NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		RecipientType:  "seller",
+		Channels:       []string{"Email"},
+		Attempts:       2,
+		Err:            errors.New("provider down"),
+	})
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zap.DebugLevel, entry.Level)
+	fields := entry.ContextMap()
+	assert.Equal(t, TypeNotificationFailed, fields["type"])
+	assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW", fields["notification_id"])
+	assert.Equal(t, "provider down", fields["error"])
+}
@@ -0,0 +1,163 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	TypeCircuitBreakerOpened = "circuit_breaker.opened"
+	TypeCircuitBreakerClosed = "circuit_breaker.closed"
+	TypeConfigReloaded       = "config.reloaded"
+)
+
+var Module = fx.Module("event",
+	fx.Provide(
+		fx.Annotate(
+			NewWebhookPublisher,
+			fx.As(new(Publisher)),
+		),
+		fx.Annotate(
+			NewNotificationBus,
+			fx.As(new(Bus)),
+		),
+		AsSubscriber(NewLogSubscriber),
+		AsSubscriber(NewMetricsSubscriber),
+	),
+)
+
+// Event is a platform-level occurrence delivered to internal endpoints
+type Event struct {
+	Type       string            `json:"type"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+//go:generate mockgen -package mockevent -destination ./mock/mockevent.go . Publisher
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, attributes map[string]string)
+}
+
+var _ Publisher = (*WebhookPublisher)(nil)
+
+// WebhookPublisher posts events to every configured endpoint in the
+// background, so publishing never blocks the caller
+type WebhookPublisher struct {
+	urls       []string
+	httpclient *http.Client
+	clock      clock.Clock
+	logger     *zap.Logger
+	inflight   sync.WaitGroup
+}
+
+type EventConfig struct {
+	WebhookURLs    []string      `envconfig:"PLATFORM_WEBHOOK_URLS"`
+	WebhookTimeout time.Duration `envconfig:"PLATFORM_WEBHOOK_TIMEOUT" default:"5s"`
+}
+
+type WebhookPublisherParams struct {
+	fx.In
+
+	Config EventConfig
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+func NewWebhookPublisher(lc fx.Lifecycle, params WebhookPublisherParams) *WebhookPublisher {
+	publisher := &WebhookPublisher{
+		urls: params.Config.WebhookURLs,
+		httpclient: &http.Client{
+			Timeout: params.Config.WebhookTimeout,
+		},
+		clock:  params.Clock,
+		logger: params.Logger,
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return publisher.wait(ctx)
+		},
+	})
+
+	return publisher
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, eventType string, attributes map[string]string) {
+	if len(p.urls) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(Event{
+		Type:       eventType,
+		OccurredAt: p.clock.Now().UTC(),
+		Attributes: attributes,
+	})
+	if err != nil {
+		p.logger.Error("failed to marshal platform event",
+			zap.String("type", eventType),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Deliveries outlive the publishing call, so only the values of ctx are kept
+	ctx = context.WithoutCancel(ctx)
+	for _, u := range p.urls {
+		p.inflight.Add(1)
+		go func() {
+			defer p.inflight.Done()
+
+			if err := p.deliver(ctx, u, body); err != nil {
+				p.logger.Warn("failed to deliver platform event",
+					zap.String("type", eventType),
+					zap.String("url", u),
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+}
+
+func (p *WebhookPublisher) deliver(ctx context.Context, u string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// wait blocks until in-flight deliveries finish or ctx is done
+func (p *WebhookPublisher) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
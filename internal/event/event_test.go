@@ -0,0 +1,129 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	occurredAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(occurredAt)
+
+	var (
+		mu       sync.Mutex
+		received []Event
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var evt Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&evt))
+
+		mu.Lock()
+		received = append(received, evt)
+		mu.Unlock()
+	})
+	first := httptest.NewServer(handler)
+	defer first.Close()
+	second := httptest.NewServer(handler)
+	defer second.Close()
+
+	lc := fxtest.NewLifecycle(t)
+	publisher := NewWebhookPublisher(lc, WebhookPublisherParams{
+		Config: EventConfig{
+			WebhookURLs:    []string{first.URL, second.URL},
+			WebhookTimeout: time.Second,
+		},
+		Clock:  mockClock,
+		Logger: zap.NewNop(),
+	})
+	lc.RequireStart()
+
+	publisher.Publish(context.Background(), TypeCircuitBreakerOpened, map[string]string{"host": "email.example.com"})
+	lc.RequireStop()
+
+	require.Len(t, received, 2)
+	for _, evt := range received {
+		assert.Equal(t, TypeCircuitBreakerOpened, evt.Type)
+		assert.True(t, occurredAt.Equal(evt.OccurredAt))
+		assert.Equal(t, "email.example.com", evt.Attributes["host"])
+	}
+}
+
+func TestWebhookPublisher_Publish_NoEndpoints(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	publisher := NewWebhookPublisher(lc, WebhookPublisherParams{
+		Config: EventConfig{WebhookTimeout: time.Second},
+		Clock:  clock.NewRealClock(),
+		Logger: zap.NewNop(),
+	})
+
+	assert.NotPanics(t, func() {
+		publisher.Publish(context.Background(), TypeCircuitBreakerClosed, nil)
+	})
+	require.NoError(t, publisher.wait(context.Background()))
+}
+
+func TestWebhookPublisher_deliver(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectError bool
+	}{
+		{name: "accepted", statusCode: http.StatusAccepted},
+		{name: "server error", statusCode: http.StatusInternalServerError, expectError: true},
+		{name: "non-2xx status is a failure", statusCode: http.StatusNotModified, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			publisher := &WebhookPublisher{
+				httpclient: &http.Client{Timeout: time.Second},
+				logger:     zap.NewNop(),
+			}
+
+			err := publisher.deliver(context.Background(), server.URL, []byte(`{}`))
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookPublisher_wait(t *testing.T) {
+	t.Run("returns context error when deliveries outlast shutdown", func(t *testing.T) {
+		publisher := &WebhookPublisher{}
+		publisher.inflight.Add(1)
+		defer publisher.inflight.Done()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, publisher.wait(ctx), context.Canceled)
+	})
+}
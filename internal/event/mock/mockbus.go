@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/event (interfaces: Bus)
+//
+// Generated by this command:
+//
+//	mockgen -package mockevent -destination ./mock/mockbus.go . Bus
+//
+
+// Package mockevent is a generated GoMock package.
+package mockevent
+
+import (
+	context "context"
+	reflect "reflect"
+
+	event "github.com/koungkub/fw-challenge-notification-service/internal/event"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBus is a mock of Bus interface.
+type MockBus struct {
+	ctrl     *gomock.Controller
+	recorder *MockBusMockRecorder
+	isgomock struct{}
+}
+
+// MockBusMockRecorder is the mock recorder for MockBus.
+type MockBusMockRecorder struct {
+	mock *MockBus
+}
+
+// NewMockBus creates a new mock instance.
+func NewMockBus(ctrl *gomock.Controller) *MockBus {
+	mock := &MockBus{ctrl: ctrl}
+	mock.recorder = &MockBusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBus) EXPECT() *MockBusMockRecorder {
+	return m.recorder
+}
+
+// Emit mocks base method.
+func (m *MockBus) Emit(ctx context.Context, evt event.NotificationEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Emit", ctx, evt)
+}
+
+// Emit indicates an expected call of Emit.
+func (mr *MockBusMockRecorder) Emit(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockBus)(nil).Emit), ctx, evt)
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	errUnknownCache               = errors.New("unknown cache, use preferences, routes, translations, suppressions or consents")
+	errUnknownCircuitBreaker      = errors.New("no circuit breaker for host")
+	errCircuitBreakerHostRequired = errors.New("host is required")
+)
+
+// AdminStatus is the live state of the service reported by the admin API
+type AdminStatus struct {
+	Dispatch        DispatchStatus         `json:"dispatch"`
+	CircuitBreakers []CircuitBreakerStatus `json:"circuit_breakers"`
+	Caches          []CacheStatus          `json:"caches"`
+}
+
+type DispatchStatus struct {
+	MaxConcurrent int            `json:"max_concurrent"`
+	InFlight      int            `json:"in_flight"`
+	Queued        map[string]int `json:"queued"`
+}
+
+type CircuitBreakerStatus struct {
+	Host                string `json:"host"`
+	State               string `json:"state"`
+	Requests            uint32 `json:"requests"`
+	TotalSuccesses      uint32 `json:"total_successes"`
+	TotalFailures       uint32 `json:"total_failures"`
+	ConsecutiveFailures uint32 `json:"consecutive_failures"`
+}
+
+type CacheStatus struct {
+	Name        string  `json:"name"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	KeysAdded   uint64  `json:"keys_added"`
+	KeysEvicted uint64  `json:"keys_evicted"`
+	HitRatio    float64 `json:"hit_ratio"`
+}
+
+// ResetCircuitBreakerRequest names the provider host whose breaker is reset
+type ResetCircuitBreakerRequest struct {
+	Host string `json:"host"`
+}
+
+type Admin struct {
+	dispatcher       dispatch.Dispatcher
+	breakers         *client.CircuitBreakerRegistry
+	preferenceCache  repository.CacheProvider
+	routeCache       repository.RouteCacheProvider
+	translationCache repository.TranslationCacheProvider
+	suppressionCache repository.SuppressionCacheProvider
+	consentCache     repository.ConsentCacheProvider
+	audit            repository.AuditProvider
+	preferences      repository.PreferenceAdminProvider
+	suppressions     repository.SuppressionProvider
+	deadLetters      repository.DeadLetterProvider
+	templates        repository.TemplateProvider
+	secrets          secret.Provider
+	notificationLog  repository.NotificationLogProvider
+	costs            service.CostConfig
+	logger           *zap.Logger
+}
+
+type AdminParams struct {
+	fx.In
+
+	Dispatcher       dispatch.Dispatcher
+	Breakers         *client.CircuitBreakerRegistry
+	PreferenceCache  repository.CacheProvider
+	RouteCache       repository.RouteCacheProvider
+	TranslationCache repository.TranslationCacheProvider
+	SuppressionCache repository.SuppressionCacheProvider
+	ConsentCache     repository.ConsentCacheProvider
+	Audit            repository.AuditProvider
+	Preferences      repository.PreferenceAdminProvider
+	Suppressions     repository.SuppressionProvider
+	DeadLetters      repository.DeadLetterProvider
+	Templates        repository.TemplateProvider
+	Secrets          secret.Provider
+	NotificationLog  repository.NotificationLogProvider
+	Costs            service.CostConfig
+	Logger           *zap.Logger
+}
+
+func NewAdminHandler(params AdminParams) *Admin {
+	return &Admin{
+		dispatcher:       params.Dispatcher,
+		breakers:         params.Breakers,
+		preferenceCache:  params.PreferenceCache,
+		routeCache:       params.RouteCache,
+		translationCache: params.TranslationCache,
+		suppressionCache: params.SuppressionCache,
+		consentCache:     params.ConsentCache,
+		audit:            params.Audit,
+		preferences:      params.Preferences,
+		suppressions:     params.Suppressions,
+		deadLetters:      params.DeadLetters,
+		templates:        params.Templates,
+		secrets:          params.Secrets,
+		notificationLog:  params.NotificationLog,
+		costs:            params.Costs,
+		logger:           params.Logger,
+	}
+}
+
+func (a *Admin) StatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, a.status())
+}
+
+func (a *Admin) status() AdminStatus {
+	dispatchStats := a.dispatcher.Stats()
+	status := AdminStatus{
+		Dispatch: DispatchStatus{
+			MaxConcurrent: dispatchStats.MaxConcurrent,
+			InFlight:      dispatchStats.InFlight,
+			Queued:        make(map[string]int, len(dispatchStats.Queued)),
+		},
+		CircuitBreakers: []CircuitBreakerStatus{},
+		Caches: []CacheStatus{
+			newCacheStatus("preferences", a.preferenceCache.Stats()),
+			newCacheStatus("routes", a.routeCache.Stats()),
+			newCacheStatus("translations", a.translationCache.Stats()),
+			newCacheStatus("suppressions", a.suppressionCache.Stats()),
+			newCacheStatus("consents", a.consentCache.Stats()),
+		},
+	}
+
+	for priority, queued := range dispatchStats.Queued {
+		status.Dispatch.Queued[string(priority)] = queued
+	}
+
+	for _, snapshot := range a.breakers.Snapshot() {
+		status.CircuitBreakers = append(status.CircuitBreakers, newCircuitBreakerStatus(snapshot))
+	}
+	sort.Slice(status.CircuitBreakers, func(i, j int) bool {
+		return status.CircuitBreakers[i].Host < status.CircuitBreakers[j].Host
+	})
+
+	return status
+}
+
+// InvalidateCacheHandler clears one cache, so changed preferences, routes,
+// translations, suppressions or consents apply without waiting for
+// CACHE_EXPIRED_TIME
+func (a *Admin) InvalidateCacheHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	caches := map[string]interface {
+		Stats() repository.CacheStats
+		Clear()
+	}{
+		"preferences":  a.preferenceCache,
+		"routes":       a.routeCache,
+		"translations": a.translationCache,
+		"suppressions": a.suppressionCache,
+		"consents":     a.consentCache,
+	}
+	cache, ok := caches[name]
+	if !ok {
+		c.JSON(http.StatusNotFound, GetRequestError(errUnknownCache))
+		return
+	}
+
+	before := newCacheStatus(name, cache.Stats())
+	cache.Clear()
+	after := newCacheStatus(name, cache.Stats())
+
+	a.recordAudit(c, AuditActionCacheInvalidate, "cache:"+name, before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+// ResetCircuitBreakerHandler closes the breaker of a provider host and
+// discards its counts, e.g. once the provider is known to have recovered
+func (a *Admin) ResetCircuitBreakerHandler(c *gin.Context) {
+	var req ResetCircuitBreakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+	if req.Host == "" {
+		c.JSON(http.StatusBadRequest, GetRequestError(errCircuitBreakerHostRequired))
+		return
+	}
+
+	previous, ok := a.breakers.Reset(req.Host)
+	if !ok {
+		c.JSON(http.StatusNotFound, GetRequestError(errUnknownCircuitBreaker))
+		return
+	}
+
+	before := newCircuitBreakerStatus(previous)
+	after := CircuitBreakerStatus{Host: req.Host, State: "closed"}
+
+	a.recordAudit(c, AuditActionCircuitBreakerReset, "circuit_breaker:"+req.Host, before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+func newCacheStatus(name string, stats repository.CacheStats) CacheStatus {
+	return CacheStatus{
+		Name:        name,
+		Hits:        stats.Hits,
+		Misses:      stats.Misses,
+		KeysAdded:   stats.KeysAdded,
+		KeysEvicted: stats.KeysEvicted,
+		HitRatio:    stats.HitRatio,
+	}
+}
+
+func newCircuitBreakerStatus(snapshot metrics.CircuitBreakerSnapshot) CircuitBreakerStatus {
+	return CircuitBreakerStatus{
+		Host:                snapshot.Host,
+		State:               snapshot.State,
+		Requests:            snapshot.Requests,
+		TotalSuccesses:      snapshot.TotalSuccesses,
+		TotalFailures:       snapshot.TotalFailures,
+		ConsecutiveFailures: snapshot.ConsecutiveFailures,
+	}
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	mockdispatch "github.com/koungkub/fw-challenge-notification-service/internal/dispatch/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func newTestBreakerRegistry() *client.CircuitBreakerRegistry {
+	return client.NewCircuitBreakerRegistry(client.CircuitBreakerRegistryParams{
+		Config: client.CircuitBreakerRegistryConfig{
+			MaxHalfOpenRequests:     5,
+			OpenStateTimeout:        60 * time.Second,
+			MinRequestsBeforeTrip:   3,
+			FailureThresholdPercent: 60,
+		},
+		Logger: zap.NewNop(),
+	})
+}
+
+func TestAdmin_StatusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	preferenceCache := mockrepository.NewMockCacheProvider(ctrl)
+	preferenceCache.EXPECT().Stats().Return(repository.CacheStats{Hits: 9, Misses: 1, KeysAdded: 2, HitRatio: 0.9})
+	routeCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+	routeCache.EXPECT().Stats().Return(repository.CacheStats{Misses: 3, KeysAdded: 3})
+	translationCache := mockrepository.NewMockTranslationCacheProvider(ctrl)
+	translationCache.EXPECT().Stats().Return(repository.CacheStats{})
+	suppressionCache := mockrepository.NewMockSuppressionCacheProvider(ctrl)
+	suppressionCache.EXPECT().Stats().Return(repository.CacheStats{Hits: 5, KeysAdded: 1})
+	consentCache := mockrepository.NewMockConsentCacheProvider(ctrl)
+	consentCache.EXPECT().Stats().Return(repository.CacheStats{Misses: 2})
+
+	breakers := newTestBreakerRegistry()
+	breakers.GetOrCreate("https://push.example.com")
+	breakers.GetOrCreate("https://email.example.com")
+
+	dispatcher := mockdispatch.NewMockDispatcher(ctrl)
+	dispatcher.EXPECT().Stats().Return(dispatch.Stats{
+		MaxConcurrent: 100,
+		InFlight:      100,
+		Queued:        map[dispatch.Priority]int{dispatch.PriorityHigh: 1, dispatch.PriorityNormal: 7, dispatch.PriorityLow: 0},
+	})
+
+	admin := NewAdminHandler(AdminParams{
+		Dispatcher:       dispatcher,
+		Breakers:         breakers,
+		PreferenceCache:  preferenceCache,
+		RouteCache:       routeCache,
+		TranslationCache: translationCache,
+		SuppressionCache: suppressionCache,
+		ConsentCache:     consentCache,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/status", admin.StatusHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/status", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status AdminStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+
+	assert.Equal(t, DispatchStatus{
+		MaxConcurrent: 100,
+		InFlight:      100,
+		Queued:        map[string]int{"high": 1, "normal": 7, "low": 0},
+	}, status.Dispatch)
+
+	require.Len(t, status.CircuitBreakers, 2)
+	assert.Equal(t, "https://email.example.com", status.CircuitBreakers[0].Host)
+	assert.Equal(t, "closed", status.CircuitBreakers[0].State)
+	assert.Equal(t, "https://push.example.com", status.CircuitBreakers[1].Host)
+
+	assert.Equal(t, []CacheStatus{
+		{Name: "preferences", Hits: 9, Misses: 1, KeysAdded: 2, HitRatio: 0.9},
+		{Name: "routes", Misses: 3, KeysAdded: 3},
+		{Name: "translations"},
+		{Name: "suppressions", Hits: 5, KeysAdded: 1},
+		{Name: "consents", Misses: 2},
+	}, status.Caches)
+}
+
+func TestAdmin_InvalidateCacheHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		cache              string
+		setupMocks         func(*mockrepository.MockRouteCacheProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+	}{
+		{
+			name:  "clears cache and records audit",
+			cache: "routes",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, audit *mockrepository.MockAuditProvider) {
+				gomock.InOrder(
+					routeCache.EXPECT().Stats().Return(repository.CacheStats{Hits: 4, KeysAdded: 1}),
+					routeCache.EXPECT().Clear(),
+					routeCache.EXPECT().Stats().Return(repository.CacheStats{}),
+				)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, "key-10a4c7c9", entry.Actor)
+						assert.Equal(t, "alice", entry.OnBehalfOf)
+						assert.Equal(t, AuditActionCacheInvalidate, entry.Action)
+						assert.Equal(t, "cache:routes", entry.Target)
+						assert.JSONEq(t, `{"name":"routes","hits":4,"misses":0,"keys_added":1,"keys_evicted":0,"hit_ratio":0}`, string(entry.Before))
+						assert.JSONEq(t, `{"name":"routes","hits":0,"misses":0,"keys_added":0,"keys_evicted":0,"hit_ratio":0}`, string(entry.After))
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "still succeeds when audit write fails",
+			cache: "routes",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, audit *mockrepository.MockAuditProvider) {
+				routeCache.EXPECT().Stats().Return(repository.CacheStats{}).Times(2)
+				routeCache.EXPECT().Clear()
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects unknown cache",
+			cache:              "sessions",
+			setupMocks:         func(*mockrepository.MockRouteCacheProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			routeCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(routeCache, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				PreferenceCache:  mockrepository.NewMockCacheProvider(ctrl),
+				RouteCache:       routeCache,
+				TranslationCache: mockrepository.NewMockTranslationCacheProvider(ctrl),
+				Audit:            audit,
+				Logger:           zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(contextKeyKeyID, "key-10a4c7c9") })
+			router.POST("/admin/caches/:name/invalidate", admin.InvalidateCacheHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/caches/"+tt.cache+"/invalidate", nil)
+			req.Header.Set(AuditActorHeader, "alice")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestAdmin_ResetCircuitBreakerHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		expectedAudit      bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "resets breaker and records audit",
+			body:               `{"host":"https://email.example.com"}`,
+			expectedAudit:      true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects unknown host",
+			body:               `{"host":"https://sms.example.com"}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "rejects missing host",
+			body:               `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			breakers := newTestBreakerRegistry()
+			breakers.GetOrCreate("https://email.example.com")
+
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			if tt.expectedAudit {
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, "admin", entry.Actor, "actor defaults without a key id")
+						assert.Empty(t, entry.OnBehalfOf)
+						assert.Equal(t, AuditActionCircuitBreakerReset, entry.Action)
+						assert.Equal(t, "circuit_breaker:https://email.example.com", entry.Target)
+						assert.Contains(t, string(entry.Before), `"state":"closed"`)
+						return nil
+					})
+			}
+
+			admin := NewAdminHandler(AdminParams{
+				Breakers: breakers,
+				Audit:    audit,
+				Logger:   zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/circuit-breakers/reset", admin.ResetCircuitBreakerHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/circuit-breakers/reset", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	AuditActionCacheInvalidate     = "cache.invalidate"
+	AuditActionCircuitBreakerReset = "circuit_breaker.reset"
+	AuditActionDeadLetterRedrive   = "dead_letter.redrive"
+	AuditActionPreferenceDisable   = "preference.disable"
+	AuditActionPreferenceEnable    = "preference.enable"
+	AuditActionPreferencePromote   = "preference.promote"
+	AuditActionPreferenceRotate    = "preference.rotate"
+	AuditActionSuppressionRemove   = "suppression.remove"
+	AuditActionTemplateActivate    = "template.activate"
+	AuditActionTemplateCreate      = "template.create"
+	AuditActionTemplateUpdate      = "template.update"
+)
+
+// AuditActorHeader names the operator an admin action is performed on behalf
+// of. It is not authenticated, so the audit log keeps it beside the admin key
+// id rather than as the actor
+const AuditActorHeader = "X-Admin-Actor"
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+var errInvalidAuditLimit = errors.New("limit must be between 1 and 1000")
+
+type AuditResponse struct {
+	Entries []repository.AuditEntry `json:"entries"`
+}
+
+// AuditHandler lists audit entries newest first, filtered by the actor,
+// action, target, since and until (RFC 3339) query parameters
+func (a *Admin) AuditHandler(c *gin.Context) {
+	filter := repository.AuditFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Target: c.Query("target"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if since := c.Query("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+	if until := c.Query("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidAuditLimit))
+			return
+		}
+	}
+
+	entries, err := a.audit.FindAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditResponse{Entries: entries})
+}
+
+// recordAudit writes who changed what from before to after. The action has
+// already been applied, so a failed write is logged rather than reported as
+// a failed action
+func (a *Admin) recordAudit(c *gin.Context, action string, target string, before any, after any) {
+	entry := repository.AuditEntry{
+		Actor:      auditActor(c),
+		OnBehalfOf: c.GetHeader(AuditActorHeader),
+		Action:     action,
+		Target:     target,
+		Before:     marshalAuditState(before),
+		After:      marshalAuditState(after),
+		RemoteAddr: c.ClientIP(),
+	}
+
+	if err := a.audit.RecordAudit(c.Request.Context(), entry); err != nil {
+		logging.From(c.Request.Context(), a.logger).Error("failed to record admin action",
+			zap.String("actor", entry.Actor),
+			zap.String("on_behalf_of", entry.OnBehalfOf),
+			zap.String("action", entry.Action),
+			zap.String("target", entry.Target),
+			zap.Error(err),
+		)
+	}
+}
+
+// auditActor is the id of the admin key the request was authorized with,
+// admin when the route was reached without one
+func auditActor(c *gin.Context) string {
+	if actor := KeyID(c); actor != "" {
+		return actor
+	}
+	return "admin"
+}
+
+func marshalAuditState(state any) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return encoded
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestAdmin_AuditHandler(t *testing.T) {
+	entry := repository.AuditEntry{
+		ID:        1,
+		Actor:     "alice",
+		Action:    AuditActionCacheInvalidate,
+		Target:    "cache:routes",
+		Before:    json.RawMessage(`{"name":"routes","hits":4}`),
+		After:     json.RawMessage(`{"name":"routes","hits":0}`),
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockAuditProvider)
+		expectedStatusCode int
+	}{
+		{
+			name:  "lists with default limit",
+			query: "",
+			setupMocks: func(audit *mockrepository.MockAuditProvider) {
+				audit.EXPECT().FindAuditEntries(gomock.Any(), repository.AuditFilter{Limit: 100}).
+					Return([]repository.AuditEntry{entry}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "passes filters",
+			query: "?actor=alice&action=cache.invalidate&target=cache:routes&since=2025-06-01T00:00:00Z&until=2025-06-02T00:00:00Z&limit=10",
+			setupMocks: func(audit *mockrepository.MockAuditProvider) {
+				audit.EXPECT().FindAuditEntries(gomock.Any(), repository.AuditFilter{
+					Actor:  "alice",
+					Action: AuditActionCacheInvalidate,
+					Target: "cache:routes",
+					Since:  time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+					Until:  time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
+					Limit:  10,
+				}).Return([]repository.AuditEntry{entry}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects invalid since",
+			query:              "?since=yesterday",
+			setupMocks:         func(*mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=5000",
+			setupMocks:         func(*mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(audit *mockrepository.MockAuditProvider) {
+				audit.EXPECT().FindAuditEntries(gomock.Any(), gomock.Any()).Return([]repository.AuditEntry{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Audit:  audit,
+				Logger: zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/audit", admin.AuditHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response AuditResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Entries, 1)
+			assert.Equal(t, "alice", response.Entries[0].Actor)
+			assert.JSONEq(t, `{"name":"routes","hits":4}`, string(response.Entries[0].Before))
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	RoleNotify = "notify"
+	RoleAdmin  = "admin"
+)
+
+// roleGrants lists the roles each role holds; admin keys may also notify
+var roleGrants = map[string][]string{
+	RoleNotify: {RoleNotify},
+	RoleAdmin:  {RoleAdmin, RoleNotify},
+}
+
+// contextKeyRole is the gin context key holding the role of the caller
+const contextKeyRole = "auth.role"
+
+// contextKeySandbox is the gin context key set for callers with a sandbox
+// API key
+const contextKeySandbox = "auth.sandbox"
+
+// contextKeyKeyID is the gin context key holding the id of the API key of
+// the caller
+const contextKeyKeyID = "auth.key_id"
+
+var (
+	errAdminDisabled = errors.New("admin api is disabled")
+	errUnauthorized  = errors.New("invalid api key")
+)
+
+type apiKey struct {
+	key  []byte
+	role string
+	// id names the key without revealing it, one per key even when keys
+	// share a tenant
+	id string
+	// subject is the tenant or key id the quotas of the key are counted
+	// against
+	subject string
+	// sandbox keys never reach real providers
+	sandbox bool
+}
+
+// Authorizer checks the bearer API key of a request against the role its
+// route requires
+type Authorizer struct {
+	keys []apiKey
+	// enforceNotify is set once HTTP_API_KEYS is configured; until then the
+	// notify route stays open as it was before keys existed
+	enforceNotify    bool
+	metricsCollector *metrics.AuthorizationCollector
+}
+
+type AuthorizerParams struct {
+	fx.In
+
+	Config           HandlerConfig
+	MetricsCollector *metrics.AuthorizationCollector
+}
+
+func NewAuthorizer(params AuthorizerParams) (*Authorizer, error) {
+	authorizer := &Authorizer{
+		enforceNotify:    len(params.Config.APIKeys) > 0,
+		metricsCollector: params.MetricsCollector,
+	}
+
+	for key := range params.Config.APIKeyTenants {
+		if _, ok := params.Config.APIKeys[key]; !ok {
+			return nil, errors.New("api key tenant configured for a key missing from the api keys")
+		}
+	}
+
+	sandbox := make(map[string]bool, len(params.Config.SandboxAPIKeys))
+	for _, key := range params.Config.SandboxAPIKeys {
+		if _, ok := params.Config.APIKeys[key]; !ok {
+			return nil, errors.New("sandbox api key configured for a key missing from the api keys")
+		}
+		sandbox[key] = true
+	}
+
+	for key, role := range params.Config.APIKeys {
+		if _, ok := roleGrants[role]; !ok {
+			return nil, fmt.Errorf("api key role '%s' not supported, use %s or %s", role, RoleNotify, RoleAdmin)
+		}
+		if key == "" {
+			return nil, errors.New("api key must not be empty")
+		}
+		authorizer.keys = append(authorizer.keys, apiKey{
+			key:     []byte(key),
+			role:    role,
+			id:      keyID(key),
+			subject: subject(key, params.Config.APIKeyTenants),
+			sandbox: sandbox[key],
+		})
+	}
+
+	// The admin token predates API keys and keeps working as an admin key
+	if params.Config.AdminToken != "" {
+		authorizer.keys = append(authorizer.keys, apiKey{
+			key:     []byte(params.Config.AdminToken),
+			role:    RoleAdmin,
+			id:      keyID(params.Config.AdminToken),
+			subject: subject(params.Config.AdminToken, nil),
+		})
+	}
+
+	return authorizer, nil
+}
+
+// Require lets a request through only when its key holds role. Without any
+// admin key the admin API is disabled, as it exposes provider hosts
+func (a *Authorizer) Require(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		if role == RoleNotify && !a.enforceNotify {
+			a.metricsCollector.RecordDecision(ctx, role, "", metrics.AuthorizationAllowed)
+			c.Next()
+			return
+		}
+
+		if role == RoleAdmin && !a.hasRole(RoleAdmin) {
+			c.AbortWithStatusJSON(http.StatusNotFound, GetRequestError(errAdminDisabled))
+			return
+		}
+
+		token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		caller, ok := a.lookup(token)
+		if !ok {
+			a.metricsCollector.RecordDecision(ctx, role, "", metrics.AuthorizationUnauthenticated)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, GetRequestError(errUnauthorized))
+			return
+		}
+
+		if !grants(caller.role, role) {
+			a.metricsCollector.RecordDecision(ctx, role, caller.role, metrics.AuthorizationForbidden)
+			c.AbortWithStatusJSON(http.StatusForbidden, GetRequestError(fmt.Errorf("api key lacks the '%s' role", role)))
+			return
+		}
+
+		a.metricsCollector.RecordDecision(ctx, role, caller.role, metrics.AuthorizationAllowed)
+		c.Set(contextKeyRole, caller.role)
+		c.Set(contextKeyKeyID, caller.id)
+		c.Set(contextKeySandbox, caller.sandbox)
+		ctx = logging.With(ctx, zap.String(logging.FieldTenant, caller.subject))
+		c.Request = c.Request.WithContext(quota.WithSubject(ctx, caller.subject))
+		c.Next()
+	}
+}
+
+// lookup compares the token against every key in constant time so the
+// response time does not reveal which key matched
+func (a *Authorizer) lookup(token string) (apiKey, bool) {
+	var caller apiKey
+	found := false
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(token), k.key) == 1 && !found {
+			caller, found = k, true
+		}
+	}
+	return caller, found
+}
+
+// withSandbox marks the notification of a sandbox API key, keeping it from
+// real providers
+func withSandbox(c *gin.Context, notification service.Notification) service.Notification {
+	notification.Sandbox = c.GetBool(contextKeySandbox)
+	return notification
+}
+
+// KeyID returns the id of the API key the request was authorized with,
+// empty while notify keys are not enforced. Unlike the quota subject it
+// tells apart keys sharing a tenant
+func KeyID(c *gin.Context) string {
+	return c.GetString(contextKeyKeyID)
+}
+
+// subject is the tenant of the key, or the id of the key, which names it in
+// quota settings
+func subject(key string, tenants map[string]string) string {
+	if tenant, ok := tenants[key]; ok {
+		return tenant
+	}
+	return keyID(key)
+}
+
+// keyID derives an id from the key that names it without revealing it
+func keyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(sum[:4])
+}
+
+func (a *Authorizer) hasRole(role string) bool {
+	for _, k := range a.keys {
+		if k.role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func grants(holder string, required string) bool {
+	for _, role := range roleGrants[holder] {
+		if role == required {
+			return true
+		}
+	}
+	return false
+}
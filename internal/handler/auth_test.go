@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthorizer(t *testing.T, config HandlerConfig) (*Authorizer, error) {
+	collector, err := metrics.NewAuthorizationCollector(nil)
+	require.NoError(t, err)
+
+	return NewAuthorizer(AuthorizerParams{Config: config, MetricsCollector: collector})
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        HandlerConfig
+		expectedError string
+	}{
+		{
+			name:   "accepts known roles",
+			config: HandlerConfig{APIKeys: map[string]string{"n-key": RoleNotify, "a-key": RoleAdmin}},
+		},
+		{
+			name:          "rejects an unknown role",
+			config:        HandlerConfig{APIKeys: map[string]string{"key": "root"}},
+			expectedError: "api key role 'root' not supported, use notify or admin",
+		},
+		{
+			name:          "rejects an empty key",
+			config:        HandlerConfig{APIKeys: map[string]string{"": RoleNotify}},
+			expectedError: "api key must not be empty",
+		},
+		{
+			name: "rejects a tenant of an unknown key",
+			config: HandlerConfig{
+				APIKeys:       map[string]string{"key": RoleNotify},
+				APIKeyTenants: map[string]string{"other-key": "acme"},
+			},
+			expectedError: "api key tenant configured for a key missing from the api keys",
+		},
+		{
+			name: "rejects a sandbox key missing from the api keys",
+			config: HandlerConfig{
+				APIKeys:        map[string]string{"key": RoleNotify},
+				SandboxAPIKeys: []string{"other-key"},
+			},
+			expectedError: "sandbox api key configured for a key missing from the api keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestAuthorizer(t, tt.config)
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthorizer_Require(t *testing.T) {
+	keys := map[string]string{"notify-key": RoleNotify, "admin-key": RoleAdmin}
+
+	tests := []struct {
+		name               string
+		config             HandlerConfig
+		role               string
+		authorization      string
+		expectedStatusCode int
+	}{
+		{
+			name:               "notify is open without api keys",
+			config:             HandlerConfig{AdminToken: "s3cret"},
+			role:               RoleNotify,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "admin is disabled without an admin key",
+			config:             HandlerConfig{APIKeys: map[string]string{"notify-key": RoleNotify}},
+			role:               RoleAdmin,
+			authorization:      "Bearer notify-key",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "rejects a missing key",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleNotify,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "rejects an unknown key",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleAdmin,
+			authorization:      "Bearer wrong",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "notify key may notify",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleNotify,
+			authorization:      "Bearer notify-key",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "notify key is forbidden on admin routes",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleAdmin,
+			authorization:      "Bearer notify-key",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "admin key may notify",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleNotify,
+			authorization:      "Bearer admin-key",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "admin key may administer",
+			config:             HandlerConfig{APIKeys: keys},
+			role:               RoleAdmin,
+			authorization:      "Bearer admin-key",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "admin token acts as an admin key",
+			config:             HandlerConfig{AdminToken: "s3cret"},
+			role:               RoleAdmin,
+			authorization:      "Bearer s3cret",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer, err := newTestAuthorizer(t, tt.config)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/", authorizer.Require(tt.role), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestAuthorizer_Subject(t *testing.T) {
+	authorizer, err := newTestAuthorizer(t, HandlerConfig{
+		APIKeys:       map[string]string{"acme-key": RoleNotify, "solo-key": RoleNotify},
+		APIKeyTenants: map[string]string{"acme-key": "acme"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		token           string
+		expectedSubject string
+	}{
+		{
+			name:            "counts a key with a tenant against the tenant",
+			token:           "acme-key",
+			expectedSubject: "acme",
+		},
+		{
+			name:            "counts another key against its id",
+			token:           "solo-key",
+			expectedSubject: "key-5b94cefc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/notify", authorizer.Require(RoleNotify), func(c *gin.Context) {
+				subject = quota.SubjectFrom(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notify", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
+
+func TestAuthorizer_KeyID(t *testing.T) {
+	authorizer, err := newTestAuthorizer(t, HandlerConfig{
+		APIKeys:       map[string]string{"acme-key": RoleNotify, "acme-ops-key": RoleAdmin},
+		APIKeyTenants: map[string]string{"acme-key": "acme", "acme-ops-key": "acme"},
+		AdminToken:    "admin-token",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		token           string
+		expectedKeyID   string
+		expectedSubject string
+	}{
+		{
+			name:            "names a key of a tenant",
+			token:           "acme-key",
+			expectedKeyID:   "key-afacab35",
+			expectedSubject: "acme",
+		},
+		{
+			name:            "tells apart another key of the same tenant",
+			token:           "acme-ops-key",
+			expectedKeyID:   "key-e2b3e1e2",
+			expectedSubject: "acme",
+		},
+		{
+			name:            "names the admin token",
+			token:           "admin-token",
+			expectedKeyID:   "key-10a4c7c9",
+			expectedSubject: "key-10a4c7c9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keyID, subject string
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/notify", authorizer.Require(RoleNotify), func(c *gin.Context) {
+				keyID = KeyID(c)
+				subject = quota.SubjectFrom(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notify", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedKeyID, keyID)
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
+
+func TestAuthorizer_Sandbox(t *testing.T) {
+	authorizer, err := newTestAuthorizer(t, HandlerConfig{
+		APIKeys:        map[string]string{"live-key": RoleNotify, "sandbox-key": RoleNotify},
+		SandboxAPIKeys: []string{"sandbox-key"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		token           string
+		expectedSandbox bool
+	}{
+		{
+			name:            "marks the notifications of a sandbox key",
+			token:           "sandbox-key",
+			expectedSandbox: true,
+		},
+		{
+			name:  "leaves the notifications of another key live",
+			token: "live-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notification service.Notification
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/notify", authorizer.Require(RoleNotify), func(c *gin.Context) {
+				notification = withSandbox(c, service.Notification{To: "buyer@example.com"})
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/notify", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedSandbox, notification.Sandbox)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+)
+
+// BatchHandler delivers a stream of NDJSON notify requests, one per line, to
+// the recipient type. Records are handed to the job workers as they are
+// read, so the upload slows down to the delivery rate rather than being
+// buffered. Invalid records are counted and reported on the job instead of
+// failing the upload. The job keeps delivering after the response, which
+// links to the job in the Location header
+func (n *Notification) BatchHandler(c *gin.Context) {
+	job, err := n.batches.Start(c.Request.Context(), c.Param("recipient"))
+	if errors.Is(err, batch.ErrTooManyJobs) {
+		c.JSON(http.StatusTooManyRequests, GetRequestError(err))
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	defer job.Close()
+
+	c.Header("Location", "/api/v1.0/jobs/"+job.ID())
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, n.batchConfig.MaxRecordSize)), n.batchConfig.MaxRecordSize)
+
+	line, records := 0, 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		records++
+		if records > n.batchConfig.MaxRecords {
+			err := fmt.Errorf("batch exceeds %d records", n.batchConfig.MaxRecords)
+			c.JSON(http.StatusRequestEntityTooLarge, GetRequestError(err))
+			return
+		}
+
+		var req NotifyRequest
+		if err := n.decodeRecord(raw, &req); err != nil {
+			job.Reject(line, err)
+			continue
+		}
+		if err := job.Submit(c.Request.Context(), line, withSandbox(c, req.Notification())); err != nil {
+			c.JSON(http.StatusRequestTimeout, GetRequestError(err))
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = fmt.Errorf("line %d exceeds %d bytes", line+1, n.batchConfig.MaxRecordSize)
+			c.JSON(http.StatusRequestEntityTooLarge, GetRequestError(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	job.Close()
+	c.JSON(http.StatusAccepted, job.Status())
+}
+
+// decodeRecord reads one NDJSON line the way bindRequest reads a body
+func (n *Notification) decodeRecord(raw []byte, req any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if n.strictRequestField {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decodeSingle(decoder, req); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(req)
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	mockbatch "github.com/koungkub/fw-challenge-notification-service/internal/batch/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+var testBatchConfig = batch.BatchConfig{
+	Workers:       2,
+	MaxJobs:       1,
+	MaxRecords:    3,
+	MaxRecordSize: 256,
+	MaxNotifyJobs: 1,
+	JobLease:      time.Minute,
+}
+
+// testJobItems collects the job items a batch persists
+type testJobItems struct {
+	mu       sync.Mutex
+	items    []repository.JobItem
+	finished bool
+}
+
+func (s *testJobItems) counts() (counts repository.JobItemCounts, finished bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.items {
+		switch item.Status {
+		case repository.JobItemSent:
+			counts.Sent++
+		case repository.JobItemFailed:
+			counts.Failed++
+		case repository.JobItemRejected:
+			counts.Rejected++
+		}
+	}
+	return counts, s.finished
+}
+
+func newTestJobProvider(ctrl *gomock.Controller, store *testJobItems) *mockrepository.MockJobProvider {
+	jobs := mockrepository.NewMockJobProvider(ctrl)
+	jobs.EXPECT().CreateJob(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	jobs.EXPECT().UpdateJob(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, update repository.JobUpdate) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.finished = store.finished || update.FinishedAt != nil
+		return nil
+	}).AnyTimes()
+	jobs.EXPECT().RecordJobItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, item repository.JobItem) error {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		store.items = append(store.items, item)
+		return nil
+	}).AnyTimes()
+	return jobs
+}
+
+func TestNotification_BatchHandler(t *testing.T) {
+	validRecord := `{"to":"buyer@example.com","title":"Order shipped","message":"On its way"}`
+
+	tests := []struct {
+		name               string
+		body               string
+		strict             bool
+		expectedSends      int
+		expectedStatusCode int
+		expectedCounts     repository.JobItemCounts
+	}{
+		{
+			name:               "delivers every record",
+			body:               validRecord + "\n\n" + validRecord + "\n",
+			expectedSends:      2,
+			expectedStatusCode: http.StatusAccepted,
+			expectedCounts:     repository.JobItemCounts{Sent: 2},
+		},
+		{
+			name:               "reports invalid records by line",
+			body:               validRecord + "\n" + `{"to":"buyer@example.com"}` + "\n" + "not json",
+			expectedSends:      1,
+			expectedStatusCode: http.StatusAccepted,
+			expectedCounts:     repository.JobItemCounts{Sent: 1, Rejected: 2},
+		},
+		{
+			name:               "rejects unknown fields in strict mode",
+			body:               `{"to":"buyer@example.com","title":"Order shipped","message":"On its way","titel":"typo"}`,
+			strict:             true,
+			expectedStatusCode: http.StatusAccepted,
+			expectedCounts:     repository.JobItemCounts{Rejected: 1},
+		},
+		{
+			name:               "rejects a line holding two records",
+			body:               validRecord + " " + validRecord + "\n" + validRecord,
+			expectedSends:      1,
+			expectedStatusCode: http.StatusAccepted,
+			expectedCounts:     repository.JobItemCounts{Sent: 1, Rejected: 1},
+		},
+		{
+			name:               "stops above the record limit",
+			body:               strings.Repeat(validRecord+"\n", 4),
+			expectedSends:      3,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedCounts:     repository.JobItemCounts{Sent: 3},
+		},
+		{
+			name:               "stops at a line above the size limit",
+			body:               validRecord + "\n" + `{"to":"` + strings.Repeat("a", 300) + `"}`,
+			expectedSends:      1,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedCounts:     repository.JobItemCounts{Sent: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+				To:      "buyer@example.com",
+				Title:   "Order shipped",
+				Message: "On its way",
+			}).Return(service.DeliveryReport{}, nil).Times(tt.expectedSends)
+
+			idGenerator := mockidgen.NewMockGenerator(ctrl)
+			idGenerator.EXPECT().NewID().Return("01JB8Z5XK3M4N5P6Q7R8S9T0VW", nil)
+
+			store := &testJobItems{}
+			processor, err := batch.NewBatchProcessor(batch.BatchProcessorParams{
+				Lifecycle:   fxtest.NewLifecycle(t),
+				Config:      testBatchConfig,
+				Service:     mockService,
+				Jobs:        newTestJobProvider(ctrl, store),
+				IDGenerator: idGenerator,
+				Clock:       clock.NewRealClock(),
+				Logger:      zap.NewNop(),
+			})
+			require.NoError(t, err)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:      HandlerConfig{StrictRequestField: tt.strict},
+				Services:    mockService,
+				Batches:     processor,
+				BatchConfig: testBatchConfig,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/recipient/:recipient/batch", handler.BatchHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/recipient/buyer/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, "/api/v1.0/jobs/01JB8Z5XK3M4N5P6Q7R8S9T0VW", w.Header().Get("Location"))
+
+			var counts repository.JobItemCounts
+			require.Eventually(t, func() bool {
+				var finished bool
+				counts, finished = store.counts()
+				return finished
+			}, time.Second, 5*time.Millisecond)
+			assert.Equal(t, tt.expectedCounts, counts)
+		})
+	}
+}
+
+func TestNotification_BatchHandler_StartError(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{"too many jobs", batch.ErrTooManyJobs, http.StatusTooManyRequests},
+		{"id generation fails", errors.New("entropy exhausted"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			batches := mockbatch.NewMockProcessor(ctrl)
+			batches.EXPECT().Start(gomock.Any(), "buyer").Return(nil, tt.err)
+
+			handler := NewNotificationHandler(NotificationParams{Batches: batches, BatchConfig: testBatchConfig})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/recipient/:recipient/batch", handler.BatchHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/recipient/buyer/batch", strings.NewReader("{}")))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestNotification_BatchHandler_UploaderGone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	mockService := mockservice.NewMockNotificationProvider(ctrl)
+	mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+		DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+			<-release
+			return service.DeliveryReport{}, nil
+		})
+
+	idGenerator := mockidgen.NewMockGenerator(ctrl)
+	idGenerator.EXPECT().NewID().Return("01JB8Z5XK3M4N5P6Q7R8S9T0VW", nil)
+
+	config := testBatchConfig
+	config.Workers = 1
+	processor, err := batch.NewBatchProcessor(batch.BatchProcessorParams{
+		Lifecycle:   fxtest.NewLifecycle(t),
+		Config:      config,
+		Service:     mockService,
+		Jobs:        newTestJobProvider(ctrl, &testJobItems{}),
+		IDGenerator: idGenerator,
+		Clock:       clock.NewRealClock(),
+		Logger:      zap.NewNop(),
+	})
+	require.NoError(t, err)
+
+	handler := NewNotificationHandler(NotificationParams{Batches: processor, BatchConfig: config})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/recipient/:recipient/batch", handler.BatchHandler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	record := `{"to":"buyer@example.com","title":"Order shipped","message":"On its way"}` + "\n"
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/recipient/buyer/batch", strings.NewReader(record+record))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	close(release)
+
+	assert.Equal(t, http.StatusRequestTimeout, w.Code)
+}
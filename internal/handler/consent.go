@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+)
+
+// ConsentRequest opts a user in to or out of a category, on one channel or
+// on every channel when Channel is empty
+type ConsentRequest struct {
+	Category string `json:"category" binding:"required,oneof=transactional marketing reminder"`
+	Channel  string `json:"channel"`
+	OptedIn  *bool  `json:"opted_in" binding:"required"`
+}
+
+// ConsentsResponse lists the consents of a user and, per category, whether
+// the user receives it without a consent
+type ConsentsResponse struct {
+	Consents []repository.Consent `json:"consents"`
+	Defaults map[string]bool      `json:"defaults"`
+}
+
+// Consents serves the categories of notifications users opted in to or out
+// of; users are identified by the address notifications are sent to
+type Consents struct {
+	consents     repository.ConsentProvider
+	consentCache repository.ConsentCacheProvider
+}
+
+type ConsentsParams struct {
+	fx.In
+
+	Consents     repository.ConsentProvider
+	ConsentCache repository.ConsentCacheProvider
+}
+
+func NewConsentsHandler(params ConsentsParams) *Consents {
+	return &Consents{
+		consents:     params.Consents,
+		consentCache: params.ConsentCache,
+	}
+}
+
+// ConsentsHandler lists the consents of a user
+func (h *Consents) ConsentsHandler(c *gin.Context) {
+	consents, err := h.consents.ListConsents(c.Request.Context(), c.Param("user"))
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := ConsentsResponse{
+		Consents: consents,
+		Defaults: make(map[string]bool, len(service.Categories)),
+	}
+	if response.Consents == nil {
+		response.Consents = []repository.Consent{}
+	}
+	for _, category := range service.Categories {
+		response.Defaults[category] = service.DefaultOptedIn(category)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetConsentHandler stores the consent of a user, replacing an earlier one
+// for the same category and channel. The consents this instance cached for
+// the user are dropped; other instances apply it once their entry expires
+func (h *Consents) SetConsentHandler(c *gin.Context) {
+	var req ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+	if req.Channel != "" {
+		if _, err := repository.ParseNotificationProvider(req.Channel); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+
+	consent, err := h.consents.SetConsent(c.Request.Context(), repository.Consent{
+		Address:  c.Param("user"),
+		Category: req.Category,
+		Channel:  req.Channel,
+		OptedIn:  *req.OptedIn,
+	})
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	h.consentCache.Delete(consent.Address)
+
+	c.JSON(http.StatusOK, consent)
+}
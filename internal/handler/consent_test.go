@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConsents_ConsentsHandler(t *testing.T) {
+	consent := repository.Consent{Address: "buyer@example.com", Category: "marketing", Channel: "Email", OptedIn: true}
+	defaults := map[string]bool{"transactional": true, "marketing": false, "reminder": true}
+
+	tests := []struct {
+		name               string
+		setupMocks         func(*mockrepository.MockConsentProvider)
+		expectedStatusCode int
+		expectedResponse   ConsentsResponse
+	}{
+		{
+			name: "lists the consents with the defaults",
+			setupMocks: func(consents *mockrepository.MockConsentProvider) {
+				consents.EXPECT().ListConsents(gomock.Any(), "buyer@example.com").Return([]repository.Consent{consent}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   ConsentsResponse{Consents: []repository.Consent{consent}, Defaults: defaults},
+		},
+		{
+			name: "lists no consents as an empty list",
+			setupMocks: func(consents *mockrepository.MockConsentProvider) {
+				consents.EXPECT().ListConsents(gomock.Any(), "buyer@example.com").Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   ConsentsResponse{Consents: []repository.Consent{}, Defaults: defaults},
+		},
+		{
+			name: "fails on database error",
+			setupMocks: func(consents *mockrepository.MockConsentProvider) {
+				consents.EXPECT().ListConsents(gomock.Any(), gomock.Any()).Return(nil, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockrepository.NewMockConsentProvider(ctrl)
+			tt.setupMocks(consents)
+
+			handler := NewConsentsHandler(ConsentsParams{Consents: consents})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/users/:user/consents", handler.ConsentsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/users/buyer@example.com/consents", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response ConsentsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestConsents_SetConsentHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMocks         func(*mockrepository.MockConsentProvider, *mockrepository.MockConsentCacheProvider)
+		expectedStatusCode int
+	}{
+		{
+			name: "opts in to a category on every channel",
+			body: `{"category":"marketing","opted_in":true}`,
+			setupMocks: func(consents *mockrepository.MockConsentProvider, cache *mockrepository.MockConsentCacheProvider) {
+				consent := repository.Consent{Address: "buyer@example.com", Category: "marketing", OptedIn: true}
+				consents.EXPECT().SetConsent(gomock.Any(), consent).Return(consent, nil)
+				cache.EXPECT().Delete("buyer@example.com")
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "opts out of a category on one channel",
+			body: `{"category":"reminder","channel":"PushNotification","opted_in":false}`,
+			setupMocks: func(consents *mockrepository.MockConsentProvider, cache *mockrepository.MockConsentCacheProvider) {
+				consent := repository.Consent{Address: "buyer@example.com", Category: "reminder", Channel: "PushNotification"}
+				consents.EXPECT().SetConsent(gomock.Any(), consent).Return(consent, nil)
+				cache.EXPECT().Delete("buyer@example.com")
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects an unknown category",
+			body:               `{"category":"newsletter","opted_in":true}`,
+			setupMocks:         func(*mockrepository.MockConsentProvider, *mockrepository.MockConsentCacheProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects an unknown channel",
+			body:               `{"category":"marketing","channel":"Carrier Pigeon","opted_in":true}`,
+			setupMocks:         func(*mockrepository.MockConsentProvider, *mockrepository.MockConsentCacheProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "requires opted_in",
+			body:               `{"category":"marketing"}`,
+			setupMocks:         func(*mockrepository.MockConsentProvider, *mockrepository.MockConsentCacheProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "fails on database error",
+			body: `{"category":"marketing","opted_in":true}`,
+			setupMocks: func(consents *mockrepository.MockConsentProvider, _ *mockrepository.MockConsentCacheProvider) {
+				consents.EXPECT().SetConsent(gomock.Any(), gomock.Any()).Return(repository.Consent{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			consents := mockrepository.NewMockConsentProvider(ctrl)
+			consentCache := mockrepository.NewMockConsentCacheProvider(ctrl)
+			tt.setupMocks(consents, consentCache)
+
+			handler := NewConsentsHandler(ConsentsParams{Consents: consents, ConsentCache: consentCache})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PUT("/users/:user/consents", handler.SetConsentHandler)
+
+			req := httptest.NewRequest(http.MethodPut, "/users/buyer@example.com/consents", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+type CostsResponse struct {
+	Currency string                   `json:"currency"`
+	Total    float64                  `json:"total"`
+	Costs    []repository.CostSummary `json:"costs"`
+}
+
+// CostsHandler totals the estimated spend on notifications accepted by
+// providers per tenant, channel and provider, filtered by the tenant,
+// channel, since and until (RFC 3339) query parameters
+func (a *Admin) CostsHandler(c *gin.Context) {
+	filter := repository.CostFilter{
+		Tenant:  c.Query("tenant"),
+		Channel: c.Query("channel"),
+	}
+
+	var err error
+	if since := c.Query("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+	if until := c.Query("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+
+	costs, err := a.notificationLog.SummarizeCosts(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := CostsResponse{
+		Currency: a.costs.Currency,
+		Costs:    costs,
+	}
+	for _, cost := range costs {
+		response.Total += cost.Cost
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestAdmin_CostsHandler(t *testing.T) {
+	costs := []repository.CostSummary{
+		{Tenant: "acme", Channel: "Email", ProviderName: "Provider1", Notifications: 1200, Cost: 0.96},
+		{Tenant: "acme", Channel: "PushNotification", ProviderName: "Provider3", Notifications: 300, Cost: 0.03},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockNotificationLogProvider)
+		expectedStatusCode int
+	}{
+		{
+			name:  "totals every notification",
+			query: "",
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider) {
+				notificationLog.EXPECT().SummarizeCosts(gomock.Any(), repository.CostFilter{}).Return(costs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "passes filters",
+			query: "?tenant=acme&channel=Email&since=2025-06-01T00:00:00Z&until=2025-07-01T00:00:00Z",
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider) {
+				notificationLog.EXPECT().SummarizeCosts(gomock.Any(), repository.CostFilter{
+					Tenant:  "acme",
+					Channel: "Email",
+					Since:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+					Until:   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+				}).Return(costs, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects invalid until",
+			query:              "?until=tomorrow",
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider) {
+				notificationLog.EXPECT().SummarizeCosts(gomock.Any(), gomock.Any()).Return([]repository.CostSummary{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+			tt.setupMocks(notificationLog)
+
+			admin := NewAdminHandler(AdminParams{
+				NotificationLog: notificationLog,
+				Costs:           service.CostConfig{Currency: "USD"},
+				Logger:          zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/costs", admin.CostsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/costs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response CostsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "USD", response.Currency)
+			assert.InDelta(t, 0.99, response.Total, 1e-9)
+			assert.Equal(t, costs, response.Costs)
+		})
+	}
+}
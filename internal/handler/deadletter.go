@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+var (
+	errInvalidDeadLetterLimit  = errors.New("limit must be between 1 and 500")
+	errInvalidDeadLetterOffset = errors.New("offset must be zero or more")
+	errInvalidIncludeUnsafe    = errors.New("include_unsafe must be true or false")
+)
+
+type DeadLettersResponse struct {
+	DeadLetters []repository.DeadLetter `json:"dead_letters"`
+	Total       int64                   `json:"total"`
+	Limit       int                     `json:"limit"`
+	Offset      int                     `json:"offset"`
+}
+
+// RedriveDeadLettersResponse counts the dead letters moved back to the
+// channel retries
+type RedriveDeadLettersResponse struct {
+	Redriven int64 `json:"redriven"`
+}
+
+// DeadLettersHandler lists the given up channel retries newest first,
+// filtered by the notification_id and channel query parameters and paged
+// by limit and offset
+func (a *Admin) DeadLettersHandler(c *gin.Context) {
+	filter, ok := bindDeadLetterFilter(c)
+	if !ok {
+		return
+	}
+	filter.Limit = defaultDeadLetterLimit
+
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxDeadLetterLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidDeadLetterLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidDeadLetterOffset))
+			return
+		}
+	}
+
+	page, err := a.deadLetters.ListDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := DeadLettersResponse{
+		DeadLetters: page.DeadLetters,
+		Total:       page.Total,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+	}
+	if response.DeadLetters == nil {
+		response.DeadLetters = []repository.DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RedriveDeadLettersHandler queues the dead letters matching the
+// notification_id and channel query parameters for retry again, all of
+// them without either, e.g. once the provider outage that exhausted their
+// attempts is over. Dead letters a provider may have delivered are only
+// redriven by notification_id or with include_unsafe=true
+func (a *Admin) RedriveDeadLettersHandler(c *gin.Context) {
+	filter, ok := bindDeadLetterFilter(c)
+	if !ok {
+		return
+	}
+	if includeUnsafe := c.Query("include_unsafe"); includeUnsafe != "" {
+		var err error
+		filter.IncludeUnsafe, err = strconv.ParseBool(includeUnsafe)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidIncludeUnsafe))
+			return
+		}
+	}
+
+	redriven, err := a.deadLetters.RedriveDeadLetters(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := RedriveDeadLettersResponse{Redriven: redriven}
+	a.recordAudit(c, AuditActionDeadLetterRedrive, deadLetterTarget(filter), nil, response)
+	c.JSON(http.StatusOK, response)
+}
+
+func bindDeadLetterFilter(c *gin.Context) (repository.DeadLetterFilter, bool) {
+	filter := repository.DeadLetterFilter{
+		NotificationID: c.Query("notification_id"),
+		Channel:        c.Query("channel"),
+	}
+	if filter.Channel != "" {
+		if _, err := repository.ParseNotificationProvider(filter.Channel); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return repository.DeadLetterFilter{}, false
+		}
+	}
+	return filter, true
+}
+
+func deadLetterTarget(filter repository.DeadLetterFilter) string {
+	target := "dead_letters"
+	if filter.NotificationID != "" {
+		target += ":" + filter.NotificationID
+	}
+	if filter.Channel != "" {
+		target += ":" + filter.Channel
+	}
+	return target
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestAdmin_DeadLettersHandler(t *testing.T) {
+	deadLetter := repository.DeadLetter{
+		ID:             7,
+		NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		RecipientType:  "buyer",
+		Channel:        "Email",
+		Notification:   json.RawMessage(`{"subject":"Welcome"}`),
+		Attempts:       5,
+		Reason:         "out of attempts",
+		CreatedAt:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockDeadLetterProvider)
+		expectedStatusCode int
+		expectedResponse   DeadLettersResponse
+	}{
+		{
+			name:  "lists with default paging",
+			query: "",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider) {
+				deadLetters.EXPECT().ListDeadLetters(gomock.Any(), repository.DeadLetterFilter{Limit: 50}).
+					Return(repository.DeadLetterPage{DeadLetters: []repository.DeadLetter{deadLetter}, Total: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: DeadLettersResponse{
+				DeadLetters: []repository.DeadLetter{deadLetter},
+				Total:       1,
+				Limit:       50,
+			},
+		},
+		{
+			name:  "passes filters and paging",
+			query: "?notification_id=01JB8Z5XK3M4N5P6Q7R8S9T0VW&channel=PushNotification&limit=10&offset=20",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider) {
+				deadLetters.EXPECT().ListDeadLetters(gomock.Any(), repository.DeadLetterFilter{
+					NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					Channel:        "PushNotification",
+					Limit:          10,
+					Offset:         20,
+				}).Return(repository.DeadLetterPage{Total: 21}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: DeadLettersResponse{
+				DeadLetters: []repository.DeadLetter{},
+				Total:       21,
+				Limit:       10,
+				Offset:      20,
+			},
+		},
+		{
+			name:               "rejects unknown channel",
+			query:              "?channel=Fax",
+			setupMocks:         func(*mockrepository.MockDeadLetterProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=501",
+			setupMocks:         func(*mockrepository.MockDeadLetterProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects negative offset",
+			query:              "?offset=-1",
+			setupMocks:         func(*mockrepository.MockDeadLetterProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider) {
+				deadLetters.EXPECT().ListDeadLetters(gomock.Any(), gomock.Any()).Return(repository.DeadLetterPage{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			deadLetters := mockrepository.NewMockDeadLetterProvider(ctrl)
+			tt.setupMocks(deadLetters)
+
+			admin := NewAdminHandler(AdminParams{
+				DeadLetters: deadLetters,
+				Logger:      zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/dead-letters", admin.DeadLettersHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response DeadLettersResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestAdmin_RedriveDeadLettersHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockDeadLetterProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+		expectedResponse   RedriveDeadLettersResponse
+	}{
+		{
+			name:  "redrives every dead letter",
+			query: "",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider, audit *mockrepository.MockAuditProvider) {
+				deadLetters.EXPECT().RedriveDeadLetters(gomock.Any(), repository.DeadLetterFilter{}).Return(int64(3), nil)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionDeadLetterRedrive, entry.Action)
+						assert.Equal(t, "dead_letters", entry.Target)
+						assert.Nil(t, entry.Before)
+						assert.JSONEq(t, `{"redriven":3}`, string(entry.After))
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   RedriveDeadLettersResponse{Redriven: 3},
+		},
+		{
+			name:  "redrives the dead letters of a notification channel",
+			query: "?notification_id=01JB8Z5XK3M4N5P6Q7R8S9T0VW&channel=Email",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider, audit *mockrepository.MockAuditProvider) {
+				deadLetters.EXPECT().RedriveDeadLetters(gomock.Any(), repository.DeadLetterFilter{
+					NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					Channel:        "Email",
+				}).Return(int64(1), nil)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, "dead_letters:01JB8Z5XK3M4N5P6Q7R8S9T0VW:Email", entry.Target)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   RedriveDeadLettersResponse{Redriven: 1},
+		},
+		{
+			name:  "redrives dead letters that may have been delivered on request",
+			query: "?include_unsafe=true",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider, audit *mockrepository.MockAuditProvider) {
+				deadLetters.EXPECT().RedriveDeadLetters(gomock.Any(), repository.DeadLetterFilter{IncludeUnsafe: true}).Return(int64(4), nil)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   RedriveDeadLettersResponse{Redriven: 4},
+		},
+		{
+			name:               "rejects invalid include_unsafe",
+			query:              "?include_unsafe=maybe",
+			setupMocks:         func(*mockrepository.MockDeadLetterProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects unknown channel",
+			query:              "?channel=Fax",
+			setupMocks:         func(*mockrepository.MockDeadLetterProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(deadLetters *mockrepository.MockDeadLetterProvider, _ *mockrepository.MockAuditProvider) {
+				deadLetters.EXPECT().RedriveDeadLetters(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			deadLetters := mockrepository.NewMockDeadLetterProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(deadLetters, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				DeadLetters: deadLetters,
+				Audit:       audit,
+				Logger:      zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/dead-letters/redrive", admin.RedriveDeadLettersHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/dead-letters/redrive"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response RedriveDeadLettersResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+)
+
+// DryRunResponse lists the payloads a notify request would send on each
+// channel; nothing was sent
+type DryRunResponse struct {
+	Message       string                   `json:"message"`
+	RecipientType string                   `json:"recipient_type"`
+	Digested      bool                     `json:"digested"`
+	Channels      []ChannelPreviewResponse `json:"channels"`
+}
+
+// ChannelPreviewResponse lists the payloads of one channel; skipped marks an
+// optional channel without providers
+type ChannelPreviewResponse struct {
+	Channel  string            `json:"channel"`
+	Skipped  bool              `json:"skipped"`
+	Payloads []PayloadResponse `json:"payloads"`
+}
+
+// PayloadResponse is the body posted to one provider, in fallback order;
+// in-app payloads have no provider
+type PayloadResponse struct {
+	Provider     string                     `json:"provider,omitempty"`
+	ProviderHost string                     `json:"provider_host,omitempty"`
+	AuthMode     string                     `json:"auth_mode,omitempty"`
+	Request      client.NotificationRequest `json:"request"`
+}
+
+// DryRunHandler takes a v1.0 notify request through validation, routing,
+// consents, suppressions and rendering, and answers the payloads it would
+// send instead of sending them. Refusals are answered as by NotifyHandler
+func (n *Notification) DryRunHandler(c *gin.Context) {
+	var req NotifyRequest
+	if err := n.bindRequest(c, &req); err != nil {
+		c.JSON(bindErrorStatus(err), GetRequestError(err))
+		return
+	}
+
+	recipientType := c.Param("recipient")
+
+	report, err := n.services.DryRun(c.Request.Context(), recipientType, withSandbox(c, req.Notification()))
+	if err != nil {
+		respondSendError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDryRunResponse(recipientType, report))
+}
+
+func newDryRunResponse(recipientType string, report service.DryRunReport) DryRunResponse {
+	response := DryRunResponse{
+		Message:       "dry run, notification not sent",
+		RecipientType: recipientType,
+		Digested:      report.Digested,
+		Channels:      make([]ChannelPreviewResponse, 0, len(report.Channels)),
+	}
+
+	for _, channel := range report.Channels {
+		preview := ChannelPreviewResponse{
+			Channel:  channel.Channel,
+			Skipped:  channel.Skipped,
+			Payloads: make([]PayloadResponse, 0, len(channel.Payloads)),
+		}
+		for _, payload := range channel.Payloads {
+			preview.Payloads = append(preview.Payloads, PayloadResponse{
+				Provider:     payload.ProviderName,
+				ProviderHost: payload.Host,
+				AuthMode:     payload.AuthMode,
+				Request:      payload.Request,
+			})
+		}
+		response.Channels = append(response.Channels, preview)
+	}
+
+	return response
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotification_DryRunHandler(t *testing.T) {
+	request := client.NotificationRequest{
+		To:        "buyer@example.com",
+		Title:     "Order shipped",
+		Message:   "Your order is on its way",
+		SecretKey: service.RedactedSecretKey,
+	}
+
+	tests := []struct {
+		name               string
+		body               string
+		setupMocks         func(*mockservice.MockNotificationProvider)
+		expectedStatusCode int
+		expectedResponse   DryRunResponse
+	}{
+		{
+			name: "answers the payloads of every channel",
+			body: `{"to":"buyer@example.com","title":"Order shipped","message":"Your order is on its way"}`,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().DryRun(gomock.Any(), "buyer", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, notification service.Notification) (service.DryRunReport, error) {
+						assert.Equal(t, "buyer@example.com", notification.To)
+						return service.DryRunReport{
+							Channels: []service.ChannelPreview{
+								{
+									Channel: "Email",
+									Payloads: []service.Payload{
+										{ProviderName: "sendgrid", Host: "https://email.example.com", AuthMode: "secret_key", Request: request},
+									},
+								},
+								{Channel: "PushNotification", Skipped: true},
+							},
+						}, nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: DryRunResponse{
+				Message:       "dry run, notification not sent",
+				RecipientType: "buyer",
+				Channels: []ChannelPreviewResponse{
+					{
+						Channel: "Email",
+						Payloads: []PayloadResponse{
+							{Provider: "sendgrid", ProviderHost: "https://email.example.com", AuthMode: "secret_key", Request: request},
+						},
+					},
+					{Channel: "PushNotification", Skipped: true, Payloads: []PayloadResponse{}},
+				},
+			},
+		},
+		{
+			name:               "rejects an invalid request",
+			body:               `{"title":"Order shipped"}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "answers refusals as notify does",
+			body: `{"to":"buyer@example.com","title":"Order shipped","message":"Your order is on its way"}`,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().DryRun(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DryRunReport{}, &service.SuppressionError{Address: "buyer@example.com"})
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name: "fails on lookup error",
+			body: `{"to":"buyer@example.com","title":"Order shipped","message":"Your order is on its way"}`,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().DryRun(gomock.Any(), "buyer", gomock.Any()).Return(service.DryRunReport{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(mockService)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient/dry-run", handler.DryRunHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/buyer/dry-run", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response DryRunResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
@@ -1,10 +1,23 @@
 package handler
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
 
 type ErrorHandler struct {
-	ErrorCode string `json:"error_code"`
-	Message   string `json:"message"`
+	ErrorCode string        `json:"error_code"`
+	Message   string        `json:"message"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail names one invalid value of the request body by its JSON
+// pointer, e.g. /attachments/0/filename
+type ErrorDetail struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
 }
 
 func (e *ErrorHandler) Error() string {
@@ -18,9 +31,25 @@ func GetRequestError(err error) error {
 	}
 }
 
+// GetValidationError is a request error listing every invalid value
+func GetValidationError(err error, details []ErrorDetail) error {
+	return &ErrorHandler{
+		ErrorCode: "E101",
+		Message:   err.Error(),
+		Details:   details,
+	}
+}
+
 func GetInternalError(err error) error {
 	return &ErrorHandler{
 		ErrorCode: "E102",
 		Message:   err.Error(),
 	}
 }
+
+// respondInternalError answers err with 500 and records it on c, so the
+// server error tracking reports its cause
+func respondInternalError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.JSON(http.StatusInternalServerError, GetInternalError(err))
+}
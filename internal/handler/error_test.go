@@ -185,3 +185,18 @@ func TestGetInternalError_PreservesOriginalError(t *testing.T) {
 		assert.Equal(t, "internal error with special characters: !@#$%^&*()", errorHandler.Message)
 	})
 }
+
+func TestGetValidationError(t *testing.T) {
+	details := []ErrorDetail{
+		{Location: "/to", Message: "minLength: got 0, want 1"},
+		{Location: "/priority", Message: "value must be one of 'high', 'normal', 'low'"},
+	}
+
+	result := GetValidationError(errors.New("request does not match the api schema"), details)
+	errorHandler, ok := result.(*ErrorHandler)
+
+	assert.True(t, ok, "Expected result to be *ErrorHandler")
+	assert.Equal(t, "E101", errorHandler.ErrorCode)
+	assert.Equal(t, "request does not match the api schema", errorHandler.Message)
+	assert.Equal(t, details, errorHandler.Details)
+}
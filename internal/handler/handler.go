@@ -1,10 +1,19 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
 	"github.com/koungkub/fw-challenge-notification-service/internal/service"
 	"go.uber.org/fx"
 )
@@ -12,54 +21,366 @@ import (
 var Module = fx.Module("handler",
 	fx.Provide(
 		NewNotificationHandler,
+		NewAdminHandler,
+		NewAuthorizer,
+		NewReceiptsHandler,
+		NewInAppHandler,
+		NewConsentsHandler,
+		NewQuotaHandler,
+		NewRealtimeHandler,
 	),
 )
 
 const (
-	RecipientTypeBuyer  = "buyer"
-	RecipientTypeSeller = "seller"
+	HeaderIdempotencyKey   = "Idempotency-Key"
+	HeaderNotificationID   = "X-Notification-ID"
+	HeaderAttempts         = "X-Notification-Attempts"
+	HeaderRetryDisposition = "X-Retry-Disposition"
+	HeaderDuplicate        = "X-Notification-Duplicate"
 )
 
 type Notification struct {
-	services service.NotificationProvider
+	services            service.NotificationProvider
+	batches             batch.Processor
+	batchConfig         batch.BatchConfig
+	jobs                repository.JobProvider
+	strictRequestField  bool
+	protobufMaxBodySize int64
+	jsonMaxBodySize     int64
+	waitTimeout         time.Duration
+	maxWaitTimeout      time.Duration
 }
 
 type NotificationParams struct {
 	fx.In
 
-	Services service.NotificationProvider
+	Config      HandlerConfig
+	Services    service.NotificationProvider
+	Batches     batch.Processor
+	BatchConfig batch.BatchConfig
+	Jobs        repository.JobProvider
 }
 
 func NewNotificationHandler(params NotificationParams) *Notification {
 	return &Notification{
-		services: params.Services,
+		services:            params.Services,
+		batches:             params.Batches,
+		batchConfig:         params.BatchConfig,
+		jobs:                params.Jobs,
+		strictRequestField:  params.Config.StrictRequestField,
+		protobufMaxBodySize: params.Config.ProtobufMaxBodySize,
+		jsonMaxBodySize:     params.Config.JSONMaxBodySize,
+		waitTimeout:         params.Config.NotifyWaitTimeout,
+		maxWaitTimeout:      params.Config.NotifyMaxWaitTimeout,
 	}
 }
 
+type HandlerConfig struct {
+	StrictRequestField bool              `envconfig:"HTTP_STRICT_REQUEST_FIELD" default:"false"`
+	AdminToken         string            `envconfig:"HTTP_ADMIN_TOKEN" secret:"true"`
+	APIKeys            map[string]string `envconfig:"HTTP_API_KEYS" secret:"true"`
+	// APIKeyTenants maps API keys to the tenant their quotas are shared by;
+	// other keys have quotas of their own
+	APIKeyTenants map[string]string `envconfig:"HTTP_API_KEY_TENANTS" secret:"true"`
+	// SandboxAPIKeys lists API keys whose notifications are processed and
+	// logged but never sent to a real provider
+	SandboxAPIKeys []string `envconfig:"HTTP_SANDBOX_API_KEYS" secret:"true"`
+	// NotifyWaitTimeout is how long a notify request with wait=true and no
+	// timeout waits for its delivery; no request waits longer than
+	// NotifyMaxWaitTimeout
+	NotifyWaitTimeout    time.Duration `envconfig:"HTTP_NOTIFY_WAIT_TIMEOUT" default:"10s"`
+	NotifyMaxWaitTimeout time.Duration `envconfig:"HTTP_NOTIFY_MAX_WAIT_TIMEOUT" default:"30s"`
+	// ProtobufMaxBodySize caps protobuf bodies, which are read whole before
+	// decoding
+	ProtobufMaxBodySize int64 `envconfig:"HTTP_PROTOBUF_MAX_BODY_SIZE" default:"4194304"`
+	// JSONMaxBodySize caps JSON bodies, leaving room for the base64 of
+	// attachments as large as a protobuf body allows
+	JSONMaxBodySize int64 `envconfig:"HTTP_JSON_MAX_BODY_SIZE" default:"6291456"`
+}
+
+// NotifyHandler serves the v1.0 notify contract
 func (n *Notification) NotifyHandler(c *gin.Context) {
+	n.notify(c, &NotifyRequest{})
+}
+
+// NotifyV2Handler serves the v2.0 notify contract, answered like v1.0
+func (n *Notification) NotifyV2Handler(c *gin.Context) {
+	n.notify(c, &NotifyRequestV2{})
+}
+
+// notify binds req, a pointer to a version of the notify request, and
+// delivers it, or hands it to a job when the wait query parameter is set
+func (n *Notification) notify(c *gin.Context, req notifyContract) {
 	ctx := c.Request.Context()
 
-	var req NotifyRequest
-	if err := c.ShouldBindBodyWithJSON(&req); err != nil {
+	wait, err := n.parseWait(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	// The key is echoed back so SDKs can correlate retries; requests are not
+	// deduplicated, which is why the retry disposition is reported as well
+	if key := c.GetHeader(HeaderIdempotencyKey); key != "" {
+		c.Header(HeaderIdempotencyKey, key)
+	}
+
+	if err := n.bindRequest(c, req); err != nil {
+		writeDeliveryHeaders(c, service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry})
+		c.JSON(bindErrorStatus(err), GetRequestError(err))
+		return
+	}
+
+	if wait.async {
+		n.enqueue(c, c.Param("recipient"), withSandbox(c, req.Notification()), wait.timeout)
+		return
+	}
+
+	report, err := n.services.Send(ctx, c.Param("recipient"), withSandbox(c, req.Notification()))
+	respondDelivery(c, report, err)
+}
+
+// respondDelivery answers the outcome of a notification the service was
+// asked to send
+func respondDelivery(c *gin.Context, report service.DeliveryReport, err error) {
+	writeDeliveryHeaders(c, report)
+	if err != nil {
+		respondSendError(c, err)
+		return
+	}
+
+	if report.Digested {
+		c.JSON(http.StatusAccepted, newNotifyResponse("notification queued for digest", report))
+		return
+	}
+
+	// Some channels failed while others delivered: the failed channels are
+	// listed, queued for retry when possible
+	if report.Partial {
+		c.JSON(http.StatusMultiStatus, newNotifyResponse("notification partially sent", report))
+		return
+	}
+
+	// No channel delivered, the failed channels are retried in the
+	// background
+	if report.Retrying {
+		c.JSON(http.StatusAccepted, newNotifyResponse("notification accepted, will retry", report))
+		return
+	}
+
+	c.JSON(http.StatusOK, newNotifyResponse("notification sent", report))
+}
+
+// respondSendError answers a notification the service refused or failed to
+// deliver with the status of the cause
+func respondSendError(c *gin.Context, err error) {
+	var recipientErr *service.RecipientTypeError
+	if errors.As(err, &recipientErr) {
+		c.JSON(http.StatusNotFound, GetRequestError(err))
+		return
+	}
+
+	var unknownErr *service.UnknownNotificationError
+	if errors.As(err, &unknownErr) {
+		c.JSON(http.StatusNotFound, GetRequestError(err))
+		return
+	}
+
+	var unreplayableErr *service.UnreplayableError
+	if errors.As(err, &unreplayableErr) {
 		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
 		return
 	}
 
-	if err := func() error {
-		switch c.Param("recipient") {
-		case RecipientTypeBuyer:
-			return n.services.SendToBuyer(ctx, req.To, req.Title, req.Message)
-		case RecipientTypeSeller:
-			return n.services.SendToSeller(ctx, req.To, req.Title, req.Message)
-		default:
-			return errors.New("not supported recipient type")
-		}
-	}(); err != nil {
-		c.JSON(http.StatusInternalServerError, GetInternalError(err))
+	var selectionErr *service.ChannelSelectionError
+	if errors.As(err, &selectionErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var capabilityErr *service.CapabilityError
+	if errors.As(err, &capabilityErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var recipientAddressErr *service.RecipientError
+	if errors.As(err, &recipientAddressErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var translationErr *service.TranslationError
+	if errors.As(err, &translationErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var templateErr *service.TemplateError
+	if errors.As(err, &templateErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var rejectedErr *service.ContentRejectedError
+	if errors.As(err, &rejectedErr) {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	var suppressionErr *service.SuppressionError
+	if errors.As(err, &suppressionErr) {
+		c.JSON(http.StatusConflict, GetRequestError(err))
+		return
+	}
+
+	var optOutErr *service.OptOutError
+	if errors.As(err, &optOutErr) {
+		c.JSON(http.StatusConflict, GetRequestError(err))
+		return
+	}
+
+	var exceededErr *quota.ExceededError
+	if errors.As(err, &exceededErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(exceededErr.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, GetRequestError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "nofitication sent",
-	})
+	var duplicateErr *service.DuplicateMessageError
+	if errors.As(err, &duplicateErr) {
+		c.JSON(http.StatusConflict, GetRequestError(err))
+		return
+	}
+
+	var inProgressErr *service.MessageInProgressError
+	if errors.As(err, &inProgressErr) {
+		c.JSON(http.StatusConflict, GetRequestError(err))
+		return
+	}
+
+	// The providers failed rather than the service, so neither is recorded
+	// as a server error
+	var notificationErr *service.NotificationError
+	if errors.As(err, &notificationErr) {
+		c.JSON(http.StatusBadGateway, GetInternalError(err))
+		return
+	}
+
+	var noProviderErr *service.NoProviderError
+	if errors.As(err, &noProviderErr) {
+		c.JSON(http.StatusFailedDependency, GetInternalError(err))
+		return
+	}
+	respondInternalError(c, err)
+}
+
+// NotifyResponse tells which channels delivered the notification and the
+// provider each one used, or whether a failed channel was queued for retry.
+// Channels is omitted for digested notifications, and a duplicate only lists
+// the channels of the earlier delivery, without providers
+type NotifyResponse struct {
+	Message        string                    `json:"message"`
+	NotificationID string                    `json:"notification_id"`
+	Attempts       int                       `json:"attempts"`
+	Duplicate      bool                      `json:"duplicate,omitempty"`
+	Channels       []ChannelDeliveryResponse `json:"channels,omitempty"`
+}
+
+type ChannelDeliveryResponse struct {
+	Channel      string `json:"channel"`
+	Status       string `json:"status"`
+	Provider     string `json:"provider,omitempty"`
+	ProviderHost string `json:"provider_host,omitempty"`
+	Attempts     int    `json:"attempts"`
+}
+
+func newNotifyResponse(message string, report service.DeliveryReport) NotifyResponse {
+	response := NotifyResponse{
+		Message:        message,
+		NotificationID: report.ID,
+		Attempts:       report.Attempts,
+		Duplicate:      report.Duplicate,
+	}
+	for _, delivery := range report.Deliveries {
+		response.Channels = append(response.Channels, ChannelDeliveryResponse{
+			Channel:      delivery.Channel,
+			Status:       delivery.Status,
+			Provider:     delivery.ProviderName,
+			ProviderHost: delivery.ProviderHost,
+			Attempts:     delivery.Attempts,
+		})
+	}
+	return response
+}
+
+// writeDeliveryHeaders exposes the delivery report so clients can decide
+// whether an automatic retry is safe
+func writeDeliveryHeaders(c *gin.Context, report service.DeliveryReport) {
+	if report.ID != "" {
+		c.Header(HeaderNotificationID, report.ID)
+	}
+	c.Header(HeaderAttempts, strconv.Itoa(report.Attempts))
+	if report.RetryDisposition != "" {
+		c.Header(HeaderRetryDisposition, report.RetryDisposition)
+	}
+	if report.Duplicate {
+		c.Header(HeaderDuplicate, "true")
+	}
+}
+
+var errTrailingData = errors.New("unexpected data after the JSON value")
+
+// bindRequest decodes a JSON body of at most JSONMaxBodySize bytes into req,
+// rejecting unknown fields when strict mode is enabled so typos like "titel"
+// surface as validation errors, and data after the JSON value. A protobuf
+// body of at most ProtobufMaxBodySize bytes is decoded into req instead, and
+// validated by the same rules
+func (n *Notification) bindRequest(c *gin.Context, req any) error {
+	if c.ContentType() == binding.MIMEPROTOBUF {
+		contract, ok := req.(protoContract)
+		if !ok {
+			return errProtobufUnsupported
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, n.protobufMaxBodySize))
+		if err != nil {
+			return err
+		}
+		if err := contract.bindProto(data); err != nil {
+			return err
+		}
+		return binding.Validator.ValidateStruct(req)
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, n.jsonMaxBodySize))
+	if n.strictRequestField {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decodeSingle(decoder, req); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(req)
+}
+
+// bindErrorStatus answers a body over its size limit with 413 and any other
+// binding error with 422
+func bindErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// decodeSingle decodes the JSON value of decoder into req and rejects any
+// data after it, as json.Unmarshal does
+func decodeSingle(decoder *json.Decoder, req any) error {
+	if err := decoder.Decode(req); err != nil {
+		return err
+	}
+	if err := decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+	return nil
 }
@@ -7,15 +7,24 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
 	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+// testJSONMaxBodySize caps the JSON bodies of the handlers under test
+const testJSONMaxBodySize = 1 << 20
+
 func TestNewNotificationHandler(t *testing.T) {
 	t.Run("creates handler with service dependency", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -39,53 +48,395 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		requestBody        any
 		setupMocks         func(*mockservice.MockNotificationProvider)
 		expectedStatusCode int
+		expectedHeaders    map[string]string
 		expectedResponse   map[string]any
 	}{
 		{
 			name:      "successful notification to buyer",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: NotifyRequest{
 				To:      "buyer@example.com",
 				Title:   "Order Confirmation",
 				Message: "Your order has been confirmed",
 			},
 			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
-				mockService.EXPECT().SendToBuyer(
-					gomock.Any(),
-					"buyer@example.com",
-					"Order Confirmation",
-					"Your order has been confirmed",
-				).Return(nil)
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:      "buyer@example.com",
+					Title:   "Order Confirmation",
+					Message: "Your order has been confirmed",
+				}).Return(service.DeliveryReport{}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: map[string]any{
-				"message": "nofitication sent",
+				"message": "notification sent",
 			},
 		},
 		{
 			name:      "successful notification to seller",
-			recipient: RecipientTypeSeller,
+			recipient: "seller",
 			requestBody: NotifyRequest{
 				To:      "seller@example.com",
 				Title:   "New Order",
 				Message: "You have a new order",
 			},
 			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
-				mockService.EXPECT().SendToSeller(
-					gomock.Any(),
-					"seller@example.com",
-					"New Order",
-					"You have a new order",
-				).Return(nil)
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:      "seller@example.com",
+					Title:   "New Order",
+					Message: "You have a new order",
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: map[string]any{
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "localized notification with template keys",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":          "buyer@example.com",
+				"locale":      "th-TH",
+				"title_key":   "order_shipped.title",
+				"message_key": "order_shipped.message",
+				"params":      map[string]string{"order_id": "42"},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:         "buyer@example.com",
+					Locale:     "th-TH",
+					TitleKey:   "order_shipped.title",
+					MessageKey: "order_shipped.message",
+					Params:     map[string]string{"order_id": "42"},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: map[string]any{
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "notification with an admin template",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"template": "order_shipped",
+				"params":   map[string]string{"order_id": "42"},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "buyer@example.com",
+					Template: "order_shipped",
+					Params:   map[string]string{"order_id": "42"},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: map[string]any{
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "rich content is passed to the service",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":        "buyer@example.com",
+				"title":     "Invoice",
+				"message":   "Your invoice is attached",
+				"html":      "<p>Your invoice is attached</p>",
+				"deep_link": "app://orders/42",
+				"image_url": "https://cdn.example.com/parcel.png",
+				"attachments": []map[string]string{
+					{"filename": "invoice.pdf", "content_type": "application/pdf", "content": "JVBERi0="},
+					{"filename": "terms.pdf", "url": "https://cdn.example.com/terms.pdf"},
+				},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "buyer@example.com",
+					Title:    "Invoice",
+					Message:  "Your invoice is attached",
+					HTML:     "<p>Your invoice is attached</p>",
+					DeepLink: "app://orders/42",
+					ImageURL: "https://cdn.example.com/parcel.png",
+					Attachments: []service.Attachment{
+						{Filename: "invoice.pdf", ContentType: "application/pdf", Content: "JVBERi0="},
+						{Filename: "terms.pdf", URL: "https://cdn.example.com/terms.pdf"},
+					},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: map[string]any{
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "metadata is passed to the service",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"title":    "Order shipped",
+				"message":  "On its way",
+				"metadata": map[string]string{"order_id": "42", "shop_id": "7"},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "buyer@example.com",
+					Title:    "Order shipped",
+					Message:  "On its way",
+					Metadata: map[string]string{"order_id": "42", "shop_id": "7"},
+				}).Return(service.DeliveryReport{}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: map[string]any{
-				"message": "nofitication sent",
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "metadata value too long",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"title":    "Order shipped",
+				"message":  "On its way",
+				"metadata": map[string]string{"order_id": strings.Repeat("4", 257)},
+			},
+			setupMocks:         func(mockService *mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "metadata key too long",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"title":    "Order shipped",
+				"message":  "On its way",
+				"metadata": map[string]string{strings.Repeat("k", 65): "42"},
+			},
+			setupMocks:         func(mockService *mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "attachment with both url and content",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "buyer@example.com",
+				"title":   "Invoice",
+				"message": "Attached",
+				"attachments": []map[string]string{
+					{"filename": "invoice.pdf", "url": "https://cdn.example.com/invoice.pdf", "content": "JVBERi0="},
+				},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				// No service calls expected
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "attachment without url or content",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":          "buyer@example.com",
+				"title":       "Invoice",
+				"message":     "Attached",
+				"attachments": []map[string]string{{"filename": "invoice.pdf"}},
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				// No service calls expected
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "invalid locale",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "buyer@example.com",
+				"title":   "Test",
+				"message": "Test message",
+				"locale":  "not a locale",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				// No service calls expected
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "recipient not valid for a routed channel",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "not-an-address",
+				"title":   "Test",
+				"message": "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.RecipientError{Channel: "Email", Recipient: "not-an-address", Reason: "mail: missing '@' or angle-addr"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "unknown translation key",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":        "buyer@example.com",
+				"title_key": "missing.title",
+				"message":   "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.TranslationError{Key: "missing.title", Locale: "", Reason: "no translation found"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "unknown admin template",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"template": "missing",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.TemplateError{Name: "missing", Reason: "no such template"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "content rejected by a pre-send hook",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "buyer@example.com",
+				"title":   "Test",
+				"message": "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.ContentRejectedError{Hook: "scrubber", Reason: "content contains a denied term"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "suppressed recipient",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "bounced@example.com",
+				"title":   "Test",
+				"message": "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.SuppressionError{Address: "bounced@example.com"})
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "low priority notification queued for digest",
+			recipient: "seller",
+			requestBody: map[string]any{
+				"to":       "seller@example.com",
+				"title":    "New order",
+				"message":  "Order 42 is waiting",
+				"priority": "low",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryNotNeeded, Digested: true}, nil)
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedResponse: map[string]any{
+				"message": "notification queued for digest",
+			},
+		},
+		{
+			name:      "quota used up",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":      "buyer@example.com",
+				"title":   "Test",
+				"message": "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetrySafe}, &quota.ExceededError{Subject: "acme", Period: "day", Limit: 100, RetryAfter: 90*time.Minute + 500*time.Millisecond})
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{
+				"Retry-After": "5401",
+			},
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "recipient opted out of the category",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"title":    "Summer sale",
+				"message":  "Everything is 20% off",
+				"category": "marketing",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, notification service.Notification) (service.DeliveryReport, error) {
+						assert.Equal(t, "marketing", notification.Category)
+						return service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.OptOutError{Address: "buyer@example.com", Category: "marketing"}
+					})
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "rejects an unknown category",
+			recipient: "buyer",
+			requestBody: map[string]any{
+				"to":       "buyer@example.com",
+				"title":    "Test",
+				"message":  "Test message",
+				"category": "newsletter",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				// No service calls expected
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
 			},
 		},
 		{
 			name:      "invalid JSON body",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: map[string]any{
 				"invalid": "data",
 			},
@@ -100,7 +451,7 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		},
 		{
 			name:      "missing required field - to",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: map[string]any{
 				"title":   "Test Title",
 				"message": "Test Message",
@@ -115,7 +466,7 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		},
 		{
 			name:      "missing required field - title",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: map[string]any{
 				"to":      "test@example.com",
 				"message": "Test Message",
@@ -130,7 +481,7 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		},
 		{
 			name:      "missing required field - message",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: map[string]any{
 				"to":    "test@example.com",
 				"title": "Test Title",
@@ -145,19 +496,18 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		},
 		{
 			name:      "service error for buyer",
-			recipient: RecipientTypeBuyer,
+			recipient: "buyer",
 			requestBody: NotifyRequest{
 				To:      "buyer@example.com",
 				Title:   "Test",
 				Message: "Test message",
 			},
 			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
-				mockService.EXPECT().SendToBuyer(
-					gomock.Any(),
-					"buyer@example.com",
-					"Test",
-					"Test message",
-				).Return(errors.New("service unavailable"))
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:      "buyer@example.com",
+					Title:   "Test",
+					Message: "Test message",
+				}).Return(service.DeliveryReport{}, errors.New("service unavailable"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: map[string]any{
@@ -167,19 +517,18 @@ func TestNotification_NotifyHandler(t *testing.T) {
 		},
 		{
 			name:      "service error for seller",
-			recipient: RecipientTypeSeller,
+			recipient: "seller",
 			requestBody: NotifyRequest{
 				To:      "seller@example.com",
 				Title:   "Test",
 				Message: "Test message",
 			},
 			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
-				mockService.EXPECT().SendToSeller(
-					gomock.Any(),
-					"seller@example.com",
-					"Test",
-					"Test message",
-				).Return(errors.New("database connection error"))
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:      "seller@example.com",
+					Title:   "Test",
+					Message: "Test message",
+				}).Return(service.DeliveryReport{}, errors.New("database connection error"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse: map[string]any{
@@ -188,20 +537,103 @@ func TestNotification_NotifyHandler(t *testing.T) {
 			},
 		},
 		{
-			name:      "unsupported recipient type",
-			recipient: "admin",
+			name:      "every provider failed",
+			recipient: "buyer",
 			requestBody: NotifyRequest{
-				To:      "admin@example.com",
+				To:      "buyer@example.com",
 				Title:   "Test",
 				Message: "Test message",
 			},
 			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
-				// No service calls expected
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{}, errors.Join(&service.NotificationError{
+						Channel: "Email",
+						Causes:  []error{&client.ProviderError{StatusCode: http.StatusServiceUnavailable}},
+					}))
 			},
-			expectedStatusCode: http.StatusInternalServerError,
+			expectedStatusCode: http.StatusBadGateway,
+			expectedResponse: map[string]any{
+				"error_code": "E102",
+				"message":    "failure to sent the notifications (provider status codes: 503)",
+			},
+		},
+		{
+			name:      "channel without provider",
+			recipient: "buyer",
+			requestBody: NotifyRequest{
+				To:      "buyer@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{}, &service.NoProviderError{Channel: "Email"})
+			},
+			expectedStatusCode: http.StatusFailedDependency,
 			expectedResponse: map[string]any{
 				"error_code": "E102",
-				"message":    "not supported recipient type",
+				"message":    "no provider configured for channel 'Email'",
+			},
+		},
+		{
+			name:      "request exceeds provider capabilities",
+			recipient: "seller",
+			requestBody: NotifyRequest{
+				To:      "seller@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:      "seller@example.com",
+					Title:   "Test",
+					Message: "Test message",
+				}).Return(service.DeliveryReport{}, &service.CapabilityError{
+					Channel: "PushNotification",
+					Reason:  "message is 3000 characters, maximum is 2048",
+				})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+			},
+		},
+		{
+			name:      "successful notification to registered recipient type",
+			recipient: "courier",
+			requestBody: NotifyRequest{
+				To:      "courier-42",
+				Title:   "Pickup ready",
+				Message: "Parcel is ready for pickup",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "courier", service.Notification{
+					To:      "courier-42",
+					Title:   "Pickup ready",
+					Message: "Parcel is ready for pickup",
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: map[string]any{
+				"message": "notification sent",
+			},
+		},
+		{
+			name:      "unsupported recipient type",
+			recipient: "visitor",
+			requestBody: NotifyRequest{
+				To:      "visitor@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "visitor", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.RecipientTypeError{RecipientType: "visitor"})
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse: map[string]any{
+				"error_code": "E101",
+				"message":    "not supported recipient type 'visitor'",
 			},
 		},
 		{
@@ -229,6 +661,7 @@ func TestNotification_NotifyHandler(t *testing.T) {
 			tt.setupMocks(mockService)
 
 			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
 				Services: mockService,
 			})
 
@@ -247,6 +680,10 @@ func TestNotification_NotifyHandler(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
 
+			for key, value := range tt.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(key), "Mismatch for header %s", key)
+			}
+
 			// Verify response if expected response is not empty
 			if len(tt.expectedResponse) > 0 {
 				var response map[string]any
@@ -278,6 +715,7 @@ func TestNotification_NotifyHandler_InvalidJSON(t *testing.T) {
 		mockService := mockservice.NewMockNotificationProvider(ctrl)
 
 		handler := NewNotificationHandler(NotificationParams{
+			Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
 			Services: mockService,
 		})
 
@@ -303,6 +741,30 @@ func TestNotification_NotifyHandler_InvalidJSON(t *testing.T) {
 		assert.Equal(t, "E101", response["error_code"])
 		assert.NotEmpty(t, response["message"])
 	})
+
+	t.Run("body over the size limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := NewNotificationHandler(NotificationParams{
+			Config:   HandlerConfig{JSONMaxBodySize: 64},
+			Services: mockservice.NewMockNotificationProvider(ctrl),
+		})
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/notify/:recipient", handler.NotifyHandler)
+
+		body := []byte(`{"to": "test@example.com", "title": "Order shipped", "message": "` + strings.Repeat("a", 64) + `"}`)
+
+		req := httptest.NewRequest(http.MethodPost, "/notify/buyer", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
 }
 
 func TestNotification_NotifyHandler_ContextPropagation(t *testing.T) {
@@ -312,18 +774,18 @@ func TestNotification_NotifyHandler_ContextPropagation(t *testing.T) {
 
 		mockService := mockservice.NewMockNotificationProvider(ctrl)
 
-		mockService.EXPECT().SendToBuyer(
-			gomock.Any(),
-			"buyer@example.com",
-			"Test",
-			"Test message",
-		).DoAndReturn(func(ctx context.Context, to, title, message string) error {
+		mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+			To:      "buyer@example.com",
+			Title:   "Test",
+			Message: "Test message",
+		}).DoAndReturn(func(ctx context.Context, recipientType string, notification service.Notification) (service.DeliveryReport, error) {
 			// Verify context is not nil
 			assert.NotNil(t, ctx)
-			return nil
+			return service.DeliveryReport{}, nil
 		})
 
 		handler := NewNotificationHandler(NotificationParams{
+			Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
 			Services: mockService,
 		})
 
@@ -353,37 +815,37 @@ func TestNotification_NotifyHandler_RecipientTypeCaseSensitive(t *testing.T) {
 		name               string
 		recipient          string
 		expectedStatusCode int
-		expectServiceCall  bool
+		expectDelivered    bool
 	}{
 		{
 			name:               "lowercase buyer",
 			recipient:          "buyer",
 			expectedStatusCode: http.StatusOK,
-			expectServiceCall:  true,
+			expectDelivered:    true,
 		},
 		{
 			name:               "lowercase seller",
 			recipient:          "seller",
 			expectedStatusCode: http.StatusOK,
-			expectServiceCall:  true,
+			expectDelivered:    true,
 		},
 		{
 			name:               "uppercase BUYER",
 			recipient:          "BUYER",
-			expectedStatusCode: http.StatusInternalServerError,
-			expectServiceCall:  false,
+			expectedStatusCode: http.StatusNotFound,
+			expectDelivered:    false,
 		},
 		{
 			name:               "uppercase SELLER",
 			recipient:          "SELLER",
-			expectedStatusCode: http.StatusInternalServerError,
-			expectServiceCall:  false,
+			expectedStatusCode: http.StatusNotFound,
+			expectDelivered:    false,
 		},
 		{
 			name:               "mixed case Buyer",
 			recipient:          "Buyer",
-			expectedStatusCode: http.StatusInternalServerError,
-			expectServiceCall:  false,
+			expectedStatusCode: http.StatusNotFound,
+			expectDelivered:    false,
 		},
 	}
 
@@ -394,16 +856,16 @@ func TestNotification_NotifyHandler_RecipientTypeCaseSensitive(t *testing.T) {
 
 			mockService := mockservice.NewMockNotificationProvider(ctrl)
 
-			if tt.expectServiceCall {
-				switch tt.recipient {
-				case "buyer":
-					mockService.EXPECT().SendToBuyer(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				case "seller":
-					mockService.EXPECT().SendToSeller(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				}
+			// Recipient types are matched exactly against the routes table
+			if tt.expectDelivered {
+				mockService.EXPECT().Send(gomock.Any(), tt.recipient, gomock.Any()).Return(service.DeliveryReport{}, nil)
+			} else {
+				mockService.EXPECT().Send(gomock.Any(), tt.recipient, gomock.Any()).
+					Return(service.DeliveryReport{}, &service.RecipientTypeError{RecipientType: tt.recipient})
 			}
 
 			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
 				Services: mockService,
 			})
 
@@ -428,3 +890,581 @@ func TestNotification_NotifyHandler_RecipientTypeCaseSensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestNotification_NotifyHandler_StrictRequestField(t *testing.T) {
+	tests := []struct {
+		name               string
+		strict             bool
+		body               string
+		expectServiceCall  bool
+		expectedStatusCode int
+	}{
+		{
+			name:               "unknown field ignored when strict mode disabled",
+			strict:             false,
+			body:               `{"to": "buyer@example.com", "title": "Test", "message": "Test message", "titel": "typo"}`,
+			expectServiceCall:  true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "unknown field rejected when strict mode enabled",
+			strict:             true,
+			body:               `{"to": "buyer@example.com", "title": "Test", "message": "Test message", "titel": "typo"}`,
+			expectServiceCall:  false,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "known fields accepted when strict mode enabled",
+			strict:             true,
+			body:               `{"to": "buyer@example.com", "title": "Test", "message": "Test message"}`,
+			expectServiceCall:  true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "trailing data rejected when strict mode enabled",
+			strict:             true,
+			body:               `{"to": "buyer@example.com", "title": "Test", "message": "Test message"} {"to": "seller@example.com"}`,
+			expectServiceCall:  false,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "trailing data rejected when strict mode disabled",
+			strict:             false,
+			body:               `{"to": "buyer@example.com", "title": "Test", "message": "Test message"} garbage`,
+			expectServiceCall:  false,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "required fields still validated when strict mode enabled",
+			strict:             true,
+			body:               `{"to": "buyer@example.com", "message": "Test message"}`,
+			expectServiceCall:  false,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			if tt.expectServiceCall {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:      "buyer@example.com",
+					Title:   "Test",
+					Message: "Test message",
+				}).Return(service.DeliveryReport{}, nil)
+			}
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{StrictRequestField: tt.strict, JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient", handler.NotifyHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/buyer", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode == http.StatusUnprocessableEntity {
+				var response map[string]any
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.Equal(t, "E101", response["error_code"])
+			}
+		})
+	}
+}
+
+func TestNotification_NotifyHandler_DeliveryHeaders(t *testing.T) {
+	tests := []struct {
+		name                string
+		idempotencyKey      string
+		body                any
+		setupMocks          func(*mockservice.MockNotificationProvider)
+		expectedStatusCode  int
+		expectedID          string
+		expectedAttempts    string
+		expectedDisposition string
+		expectedDuplicate   string
+	}{
+		{
+			name:           "delivered notification",
+			idempotencyKey: "order-42-shipped",
+			body:           NotifyRequest{To: "buyer@example.com", Title: "Test", Message: "Test message"},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).Return(service.DeliveryReport{
+					ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					Attempts:         2,
+					Channels:         []string{"Email"},
+					RetryDisposition: service.RetryNotNeeded,
+				}, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedID:          "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			expectedAttempts:    "2",
+			expectedDisposition: service.RetryNotNeeded,
+		},
+		{
+			name: "failed delivery reports disposition",
+			body: NotifyRequest{To: "buyer@example.com", Title: "Test", Message: "Test message"},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).Return(service.DeliveryReport{
+					ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					Attempts:         1,
+					RetryDisposition: service.RetryUnsafe,
+				}, &service.NotificationError{Channel: "Email"})
+			},
+			expectedStatusCode:  http.StatusBadGateway,
+			expectedID:          "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			expectedAttempts:    "1",
+			expectedDisposition: service.RetryUnsafe,
+		},
+		{
+			name: "repeated message id returns the earlier delivery",
+			body: NotifyRequest{MessageID: "order-42-shipped", To: "buyer@example.com", Title: "Test", Message: "Test message"},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, notification service.Notification) (service.DeliveryReport, error) {
+						assert.Equal(t, "order-42-shipped", notification.MessageID)
+						return service.DeliveryReport{
+							ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+							Attempts:         1,
+							Channels:         []string{"Email"},
+							RetryDisposition: service.RetryNotNeeded,
+							Duplicate:        true,
+						}, nil
+					})
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedID:          "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			expectedAttempts:    "1",
+			expectedDisposition: service.RetryNotNeeded,
+			expectedDuplicate:   "true",
+		},
+		{
+			name: "repeated message id that may have been delivered conflicts",
+			body: NotifyRequest{MessageID: "order-42-shipped", To: "buyer@example.com", Title: "Test", Message: "Test message"},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).Return(service.DeliveryReport{
+					ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					Attempts:         1,
+					RetryDisposition: service.RetryDoNotRetry,
+					Duplicate:        true,
+				}, &service.DuplicateMessageError{MessageID: "order-42-shipped", NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW"})
+			},
+			expectedStatusCode:  http.StatusConflict,
+			expectedID:          "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			expectedAttempts:    "1",
+			expectedDisposition: service.RetryDoNotRetry,
+			expectedDuplicate:   "true",
+		},
+		{
+			name: "message id still being delivered conflicts",
+			body: NotifyRequest{MessageID: "order-42-shipped", To: "buyer@example.com", Title: "Test", Message: "Test message"},
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).Return(service.DeliveryReport{
+					ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+					RetryDisposition: service.RetrySafe,
+					Duplicate:        true,
+				}, &service.MessageInProgressError{MessageID: "order-42-shipped"})
+			},
+			expectedStatusCode:  http.StatusConflict,
+			expectedID:          "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			expectedAttempts:    "0",
+			expectedDisposition: service.RetrySafe,
+			expectedDuplicate:   "true",
+		},
+		{
+			name:                "invalid body must not be retried",
+			idempotencyKey:      "order-42-shipped",
+			body:                map[string]any{"to": "buyer@example.com"},
+			setupMocks:          func(mockService *mockservice.MockNotificationProvider) {},
+			expectedStatusCode:  http.StatusUnprocessableEntity,
+			expectedAttempts:    "0",
+			expectedDisposition: service.RetryDoNotRetry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(mockService)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient", handler.NotifyHandler)
+
+			bodyBytes, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/buyer", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.idempotencyKey != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.idempotencyKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.idempotencyKey, w.Header().Get(HeaderIdempotencyKey))
+			assert.Equal(t, tt.expectedID, w.Header().Get(HeaderNotificationID))
+			assert.Equal(t, tt.expectedAttempts, w.Header().Get(HeaderAttempts))
+			assert.Equal(t, tt.expectedDisposition, w.Header().Get(HeaderRetryDisposition))
+			assert.Equal(t, tt.expectedDuplicate, w.Header().Get(HeaderDuplicate))
+		})
+	}
+}
+
+func TestNotification_NotifyHandler_DeliveryDetails(t *testing.T) {
+	tests := []struct {
+		name               string
+		report             service.DeliveryReport
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "lists the channels and providers that delivered",
+			report: service.DeliveryReport{
+				ID:       "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				Attempts: 3,
+				Channels: []string{"Email", "PushNotification"},
+				Deliveries: []service.ChannelDelivery{
+					{Channel: "Email", Status: service.DeliveryStatusDelivered, ProviderName: "sendgrid", ProviderHost: "https://email2.example.com", Attempts: 2},
+					{Channel: "PushNotification", Status: service.DeliveryStatusDelivered, ProviderName: "fcm", ProviderHost: "https://push.example.com", Attempts: 1},
+				},
+				RetryDisposition: service.RetryNotNeeded,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{
+				"message": "notification sent",
+				"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				"attempts": 3,
+				"channels": [
+					{"channel": "Email", "status": "delivered", "provider": "sendgrid", "provider_host": "https://email2.example.com", "attempts": 2},
+					{"channel": "PushNotification", "status": "delivered", "provider": "fcm", "provider_host": "https://push.example.com", "attempts": 1}
+				]
+			}`,
+		},
+		{
+			name: "marks a duplicate, whose channels carry no provider",
+			report: service.DeliveryReport{
+				ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				Attempts:         1,
+				Channels:         []string{"Email"},
+				Deliveries:       []service.ChannelDelivery{{Channel: "Email", Status: service.DeliveryStatusDelivered}},
+				RetryDisposition: service.RetryNotNeeded,
+				Duplicate:        true,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{
+				"message": "notification sent",
+				"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				"attempts": 1,
+				"duplicate": true,
+				"channels": [{"channel": "Email", "status": "delivered", "attempts": 0}]
+			}`,
+		},
+		{
+			name: "reports a partially sent notification with its queued channel",
+			report: service.DeliveryReport{
+				ID:       "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				Attempts: 3,
+				Channels: []string{"Email"},
+				Deliveries: []service.ChannelDelivery{
+					{Channel: "Email", Status: service.DeliveryStatusDelivered, ProviderName: "sendgrid", ProviderHost: "https://email.example.com", Attempts: 1},
+					{Channel: "PushNotification", Status: service.DeliveryStatusQueued, Attempts: 2},
+				},
+				RetryDisposition: service.RetryNotNeeded,
+				Partial:          true,
+			},
+			expectedStatusCode: http.StatusMultiStatus,
+			expectedBody: `{
+				"message": "notification partially sent",
+				"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				"attempts": 3,
+				"channels": [
+					{"channel": "Email", "status": "delivered", "provider": "sendgrid", "provider_host": "https://email.example.com", "attempts": 1},
+					{"channel": "PushNotification", "status": "queued", "attempts": 2}
+				]
+			}`,
+		},
+		{
+			name: "accepts a notification queued for retry",
+			report: service.DeliveryReport{
+				ID:       "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				Attempts: 2,
+				Deliveries: []service.ChannelDelivery{
+					{Channel: "Email", Status: service.DeliveryStatusQueued, Attempts: 2},
+				},
+				RetryDisposition: service.RetryNotNeeded,
+				Retrying:         true,
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedBody: `{
+				"message": "notification accepted, will retry",
+				"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				"attempts": 2,
+				"channels": [{"channel": "Email", "status": "queued", "attempts": 2}]
+			}`,
+		},
+		{
+			name: "omits channels of a digested notification",
+			report: service.DeliveryReport{
+				ID:               "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				RetryDisposition: service.RetryNotNeeded,
+				Digested:         true,
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedBody: `{
+				"message": "notification queued for digest",
+				"notification_id": "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				"attempts": 0
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			mockService.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).Return(tt.report, nil)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient", handler.NotifyHandler)
+
+			bodyBytes, err := json.Marshal(NotifyRequest{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/buyer", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestNotification_NotifyHandler_ThreadKey(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               map[string]any
+		expectedThreadKey  string
+		expectedStatusCode int
+	}{
+		{
+			name: "thread key is passed to the service",
+			body: map[string]any{
+				"to":         "buyer@example.com",
+				"title":      "Order update",
+				"message":    "Shipped",
+				"thread_key": "order-42",
+			},
+			expectedThreadKey:  "order-42",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "thread key is optional",
+			body: map[string]any{
+				"to":      "buyer@example.com",
+				"title":   "Order update",
+				"message": "Shipped",
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "overlong thread key is rejected",
+			body: map[string]any{
+				"to":         "buyer@example.com",
+				"title":      "Order update",
+				"message":    "Shipped",
+				"thread_key": strings.Repeat("k", 256),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			if tt.expectedStatusCode == http.StatusOK {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:        "buyer@example.com",
+					Title:     "Order update",
+					Message:   "Shipped",
+					ThreadKey: tt.expectedThreadKey,
+				}).Return(service.DeliveryReport{}, nil)
+			}
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient", handler.NotifyHandler)
+
+			bodyBytes, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/buyer", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestNotification_NotifyV2Handler(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        string
+		setupMocks         func(*mockservice.MockNotificationProvider)
+		expectedStatusCode int
+	}{
+		{
+			name:        "translates literal content and selected channels",
+			requestBody: `{"to":"seller@example.com","channels":["PushNotification"],"priority":"high","content":{"title":"New Order","message":"You have a new order"}}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:       "seller@example.com",
+					Channels: []string{"PushNotification"},
+					Priority: dispatch.PriorityHigh,
+					Title:    "New Order",
+					Message:  "You have a new order",
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "translates a template with rich content and metadata",
+			requestBody: `{"to":"seller@example.com","template":{"title_key":"order.title","message_key":"order.message","locale":"th-TH","params":{"order_id":"42"}},"rich":{"html":"<p>42</p>","deep_link":"app://orders/42"},"metadata":{"order_id":"42"}}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:         "seller@example.com",
+					TitleKey:   "order.title",
+					MessageKey: "order.message",
+					Locale:     "th-TH",
+					Params:     map[string]string{"order_id": "42"},
+					HTML:       "<p>42</p>",
+					DeepLink:   "app://orders/42",
+					Metadata:   map[string]string{"order_id": "42"},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "renders an admin template",
+			requestBody: `{"to":"seller@example.com","template":{"name":"order_shipped","params":{"order_id":"42"}}}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", service.Notification{
+					To:       "seller@example.com",
+					Template: "order_shipped",
+					Params:   map[string]string{"order_id": "42"},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects an admin template with translation keys",
+			requestBody:        `{"to":"seller@example.com","template":{"name":"order_shipped","title_key":"t","message_key":"m"}}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "rejects a request without content or template",
+			requestBody:        `{"to":"seller@example.com"}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "rejects both content and template",
+			requestBody:        `{"to":"seller@example.com","content":{"title":"t","message":"m"},"template":{"title_key":"t","message_key":"m"}}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "rejects incomplete content",
+			requestBody:        `{"to":"seller@example.com","content":{"title":"t"}}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "rejects an unknown channel",
+			requestBody:        `{"to":"seller@example.com","channels":["Fax"],"content":{"title":"t","message":"m"}}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "rejects a channel not routed to the recipient type",
+			requestBody: `{"to":"seller@example.com","channels":["InApp"],"content":{"title":"t","message":"m"}}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "seller", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry},
+						&service.ChannelSelectionError{RecipientType: "seller", Channel: "InApp"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(mockService)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notify/:recipient", handler.NotifyV2Handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/notify/seller", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
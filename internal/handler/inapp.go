@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInAppLimit = 50
+	maxInAppLimit     = 500
+)
+
+var (
+	errInvalidInAppLimit  = errors.New("limit must be between 1 and 500")
+	errInvalidInAppOffset = errors.New("offset must be zero or more")
+	errInvalidInAppUnread = errors.New("unread must be true or false")
+	errInvalidInAppID     = errors.New("notification id must be a positive integer")
+	errUnknownInApp       = errors.New("no in-app notification with this id")
+)
+
+type InAppNotificationsResponse struct {
+	Notifications []repository.InAppNotification `json:"notifications"`
+	Total         int64                          `json:"total"`
+	Unread        int64                          `json:"unread"`
+	Limit         int                            `json:"limit"`
+	Offset        int                            `json:"offset"`
+}
+
+// InApp serves the notifications the InApp channel stored to the app
+// showing them; users are identified by the address notifications were
+// sent to, and only see the notifications of the tenant of the API key
+type InApp struct {
+	notifications repository.InAppNotificationProvider
+}
+
+type InAppParams struct {
+	fx.In
+
+	Notifications repository.InAppNotificationProvider
+}
+
+func NewInAppHandler(params InAppParams) *InApp {
+	return &InApp{
+		notifications: params.Notifications,
+	}
+}
+
+// NotificationsHandler lists the in-app notifications of a user newest
+// first, only the unread ones with unread=true, paged by limit and offset
+func (h *InApp) NotificationsHandler(c *gin.Context) {
+	filter := repository.InAppFilter{
+		Tenant:    quota.SubjectFrom(c.Request.Context()),
+		Recipient: c.Param("user"),
+		Limit:     defaultInAppLimit,
+	}
+
+	var err error
+	if unread := c.Query("unread"); unread != "" {
+		filter.UnreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidInAppUnread))
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxInAppLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidInAppLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidInAppOffset))
+			return
+		}
+	}
+
+	page, err := h.notifications.ListInAppNotifications(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := InAppNotificationsResponse{
+		Notifications: page.Notifications,
+		Total:         page.Total,
+		Unread:        page.Unread,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}
+	if response.Notifications == nil {
+		response.Notifications = []repository.InAppNotification{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkReadHandler marks an in-app notification of the user read
+func (h *InApp) MarkReadHandler(c *gin.Context) {
+	h.setRead(c, true)
+}
+
+// MarkUnreadHandler marks an in-app notification of the user unread again
+func (h *InApp) MarkUnreadHandler(c *gin.Context) {
+	h.setRead(c, false)
+}
+
+func (h *InApp) setRead(c *gin.Context, read bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, GetRequestError(errInvalidInAppID))
+		return
+	}
+
+	notification, err := h.notifications.SetInAppNotificationRead(c.Request.Context(), quota.SubjectFrom(c.Request.Context()), c.Param("user"), uint(id), read)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, GetRequestError(errUnknownInApp))
+			return
+		}
+		respondInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
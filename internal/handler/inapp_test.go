@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestInApp_NotificationsHandler(t *testing.T) {
+	notification := repository.InAppNotification{
+		ID:             7,
+		NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		RecipientType:  "buyer",
+		Recipient:      "user-42",
+		Title:          "Order shipped",
+		Message:        "Your order is on its way",
+		CreatedAt:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockInAppNotificationProvider)
+		expectedStatusCode int
+		expectedResponse   InAppNotificationsResponse
+	}{
+		{
+			name:  "lists with default paging",
+			query: "",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().ListInAppNotifications(gomock.Any(), repository.InAppFilter{Tenant: "acme", Recipient: "user-42", Limit: 50}).
+					Return(repository.InAppPage{Notifications: []repository.InAppNotification{notification}, Total: 1, Unread: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: InAppNotificationsResponse{
+				Notifications: []repository.InAppNotification{notification},
+				Total:         1,
+				Unread:        1,
+				Limit:         50,
+			},
+		},
+		{
+			name:  "lists unread only with paging",
+			query: "?unread=true&limit=10&offset=20",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().ListInAppNotifications(gomock.Any(), repository.InAppFilter{
+					Tenant:     "acme",
+					Recipient:  "user-42",
+					UnreadOnly: true,
+					Limit:      10,
+					Offset:     20,
+				}).Return(repository.InAppPage{Total: 3, Unread: 3}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: InAppNotificationsResponse{
+				Notifications: []repository.InAppNotification{},
+				Total:         3,
+				Unread:        3,
+				Limit:         10,
+				Offset:        20,
+			},
+		},
+		{
+			name:               "rejects invalid unread",
+			query:              "?unread=maybe",
+			setupMocks:         func(*mockrepository.MockInAppNotificationProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=501",
+			setupMocks:         func(*mockrepository.MockInAppNotificationProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects negative offset",
+			query:              "?offset=-1",
+			setupMocks:         func(*mockrepository.MockInAppNotificationProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().ListInAppNotifications(gomock.Any(), gomock.Any()).Return(repository.InAppPage{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notifications := mockrepository.NewMockInAppNotificationProvider(ctrl)
+			tt.setupMocks(notifications)
+
+			inApp := NewInAppHandler(InAppParams{Notifications: notifications})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(quota.WithSubject(c.Request.Context(), "acme"))
+			})
+			router.GET("/inapp/:user/notifications", inApp.NotificationsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/inapp/user-42/notifications"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response InAppNotificationsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestInApp_MarkReadHandlers(t *testing.T) {
+	readAt := time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		path               string
+		setupMocks         func(*mockrepository.MockInAppNotificationProvider)
+		expectedStatusCode int
+		expectedRead       bool
+	}{
+		{
+			name: "marks read",
+			path: "/inapp/user-42/notifications/7/read",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().SetInAppNotificationRead(gomock.Any(), "acme", "user-42", uint(7), true).
+					Return(repository.InAppNotification{ID: 7, Recipient: "user-42", ReadAt: &readAt}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedRead:       true,
+		},
+		{
+			name: "marks unread",
+			path: "/inapp/user-42/notifications/7/unread",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().SetInAppNotificationRead(gomock.Any(), "acme", "user-42", uint(7), false).
+					Return(repository.InAppNotification{ID: 7, Recipient: "user-42"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects invalid id",
+			path:               "/inapp/user-42/notifications/abc/read",
+			setupMocks:         func(*mockrepository.MockInAppNotificationProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "unknown notification of the user",
+			path: "/inapp/user-42/notifications/8/read",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().SetInAppNotificationRead(gomock.Any(), "acme", "user-42", uint(8), true).
+					Return(repository.InAppNotification{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "notification sent by another tenant",
+			path: "/inapp/user-42/notifications/9/read",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				// The repository only matches notifications of the tenant
+				notifications.EXPECT().SetInAppNotificationRead(gomock.Any(), "acme", "user-42", uint(9), true).
+					Return(repository.InAppNotification{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "fails on database error",
+			path: "/inapp/user-42/notifications/7/read",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider) {
+				notifications.EXPECT().SetInAppNotificationRead(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(repository.InAppNotification{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notifications := mockrepository.NewMockInAppNotificationProvider(ctrl)
+			tt.setupMocks(notifications)
+
+			inApp := NewInAppHandler(InAppParams{Notifications: notifications})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(quota.WithSubject(c.Request.Context(), "acme"))
+			})
+			router.POST("/inapp/:user/notifications/:id/read", inApp.MarkReadHandler)
+			router.POST("/inapp/:user/notifications/:id/unread", inApp.MarkUnreadHandler)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response repository.InAppNotification
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, uint(7), response.ID)
+			assert.Equal(t, tt.expectedRead, response.ReadAt != nil)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultJobItemLimit = 100
+	maxJobItemLimit     = 1000
+)
+
+// mimeCSV is the media type of job item results as CSV
+const mimeCSV = "text/csv"
+
+var (
+	errJobNotFound          = errors.New("job not found")
+	errInvalidJobItemLimit  = errors.New("limit must be between 1 and 1000")
+	errInvalidJobItemOffset = errors.New("offset must not be negative")
+	errNotAcceptable        = errors.New("accept application/json or text/csv")
+)
+
+// JobResponse is the state of a job and one page of its item results.
+// Total and Pending are omitted until the input of the job has ended
+type JobResponse struct {
+	ID            string            `json:"id"`
+	Kind          string            `json:"kind"`
+	RecipientType string            `json:"recipient_type"`
+	State         string            `json:"state"`
+	Total         *int              `json:"total,omitempty"`
+	Sent          int               `json:"sent"`
+	Failed        int               `json:"failed"`
+	Rejected      int               `json:"rejected"`
+	Pending       *int              `json:"pending,omitempty"`
+	Items         []JobItemResponse `json:"items"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	FinishedAt    *time.Time        `json:"finished_at,omitempty"`
+}
+
+type JobItemResponse struct {
+	Item           int    `json:"item"`
+	Status         string `json:"status"`
+	NotificationID string `json:"notification_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// JobHandler reports a job with its item results in item order, filtered
+// by the status query parameter and paged with limit and offset. Callers
+// accepting text/csv get the page of item results as CSV instead
+func (n *Notification) JobHandler(c *gin.Context) {
+	format := c.NegotiateFormat(binding.MIMEJSON, mimeCSV)
+	if format == "" {
+		c.JSON(http.StatusNotAcceptable, GetRequestError(errNotAcceptable))
+		return
+	}
+
+	filter := repository.JobItemFilter{Limit: defaultJobItemLimit}
+
+	var err error
+	if filter.Status, err = repository.ParseJobItemStatus(c.Query("status")); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxJobItemLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidJobItemLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidJobItemOffset))
+			return
+		}
+	}
+
+	// A job of another quota subject is reported as unknown, so its id does
+	// not tell it exists
+	detail, err := n.jobs.FindJob(c.Request.Context(), c.Param("id"), quota.SubjectFrom(c.Request.Context()), filter)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, GetRequestError(errJobNotFound))
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	if format == mimeCSV {
+		writeJobItemsCSV(c, detail)
+		return
+	}
+	c.JSON(http.StatusOK, newJobResponse(detail))
+}
+
+// writeJobItemsCSV writes one row per item result under a header row. The
+// job state, which CSV has no room for, goes in the X-Job-State header
+func writeJobItemsCSV(c *gin.Context, detail repository.JobDetail) {
+	c.Header("X-Job-State", detail.Job.State)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="job-%s.csv"`, detail.Job.ID))
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"item", "status", "notification_id", "error"})
+	for _, item := range detail.Items {
+		_ = w.Write([]string{strconv.Itoa(item.Item), item.Status, item.NotificationID, escapeFormula(item.Error)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// escapeFormula keeps spreadsheets from evaluating a cell as a formula, as
+// an error message may quote caller input
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func newJobResponse(detail repository.JobDetail) JobResponse {
+	response := JobResponse{
+		ID:            detail.Job.ID,
+		Kind:          detail.Job.Kind,
+		RecipientType: detail.Job.RecipientType,
+		State:         detail.Job.State,
+		Total:         detail.Job.Total,
+		Sent:          detail.Counts.Sent,
+		Failed:        detail.Counts.Failed,
+		Rejected:      detail.Counts.Rejected,
+		Items:         make([]JobItemResponse, 0, len(detail.Items)),
+		CreatedAt:     detail.Job.CreatedAt,
+		UpdatedAt:     detail.Job.UpdatedAt,
+		FinishedAt:    detail.Job.FinishedAt,
+	}
+
+	if detail.Job.Total != nil {
+		pending := *detail.Job.Total - detail.Counts.Sent - detail.Counts.Failed - detail.Counts.Rejected
+		response.Pending = &pending
+	}
+
+	for _, item := range detail.Items {
+		response.Items = append(response.Items, JobItemResponse{
+			Item:           item.Item,
+			Status:         item.Status,
+			NotificationID: item.NotificationID,
+			Error:          item.Error,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestNotification_JobHandler(t *testing.T) {
+	total := 10
+	detail := repository.JobDetail{
+		Job: repository.Job{
+			ID:            "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			Kind:          repository.JobKindBatch,
+			RecipientType: "buyer",
+			State:         repository.JobStateInProgress,
+			Total:         &total,
+			Subject:       "acme",
+			CreatedAt:     time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			UpdatedAt:     time.Date(2025, 6, 1, 12, 0, 5, 0, time.UTC),
+		},
+		Counts: repository.JobItemCounts{Sent: 4, Failed: 1, Rejected: 1},
+		Items: []repository.JobItem{
+			{JobID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Item: 3, Status: repository.JobItemFailed, NotificationID: "01JB8Z6", Error: "failure to sent the notifications"},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		accept             string
+		subject            string
+		setupMocks         func(*mockrepository.MockJobProvider)
+		expectedStatusCode int
+		expectedCSV        string
+	}{
+		{
+			name:  "reports with default page",
+			query: "",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), "01JB8Z5XK3M4N5P6Q7R8S9T0VW", "acme", repository.JobItemFilter{Limit: 100}).
+					Return(detail, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "passes filters",
+			query: "?status=failed&limit=10&offset=20",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), "01JB8Z5XK3M4N5P6Q7R8S9T0VW", "acme", repository.JobItemFilter{
+					Status: repository.JobItemFailed,
+					Limit:  10,
+					Offset: 20,
+				}).Return(detail, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:   "reports item results as csv",
+			query:  "",
+			accept: "text/csv",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), "01JB8Z5XK3M4N5P6Q7R8S9T0VW", "acme", repository.JobItemFilter{Limit: 100}).
+					Return(detail, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedCSV:        "item,status,notification_id,error\n3,failed,01JB8Z6,failure to sent the notifications\n",
+		},
+		{
+			name:   "prefers json when both are accepted",
+			query:  "",
+			accept: "application/json, text/csv",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(detail, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects an unacceptable format",
+			query:              "",
+			accept:             "application/xml",
+			setupMocks:         func(*mockrepository.MockJobProvider) {},
+			expectedStatusCode: http.StatusNotAcceptable,
+		},
+		{
+			name:               "rejects unknown status",
+			query:              "?status=bounced",
+			setupMocks:         func(*mockrepository.MockJobProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=5000",
+			setupMocks:         func(*mockrepository.MockJobProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects negative offset",
+			query:              "?offset=-1",
+			setupMocks:         func(*mockrepository.MockJobProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "unknown job",
+			query: "",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.JobDetail{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:    "hides the job of another subject",
+			query:   "",
+			subject: "globex",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), "01JB8Z5XK3M4N5P6Q7R8S9T0VW", "globex", gomock.Any()).
+					Return(repository.JobDetail{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(jobs *mockrepository.MockJobProvider) {
+				jobs.EXPECT().FindJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(repository.JobDetail{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			jobs := mockrepository.NewMockJobProvider(ctrl)
+			tt.setupMocks(jobs)
+
+			handler := NewNotificationHandler(NotificationParams{Jobs: jobs})
+
+			gin.SetMode(gin.TestMode)
+			subject := tt.subject
+			if subject == "" {
+				subject = "acme"
+			}
+			router := gin.New()
+			router.GET("/jobs/:id", func(c *gin.Context) {
+				c.Request = c.Request.WithContext(quota.WithSubject(c.Request.Context(), subject))
+			}, handler.JobHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/jobs/01JB8Z5XK3M4N5P6Q7R8S9T0VW"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			if tt.expectedCSV != "" {
+				assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, repository.JobStateInProgress, w.Header().Get("X-Job-State"))
+				assert.Equal(t, tt.expectedCSV, w.Body.String())
+				return
+			}
+
+			var response JobResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, repository.JobStateInProgress, response.State)
+			require.NotNil(t, response.Pending)
+			assert.Equal(t, 4, *response.Pending)
+			require.Len(t, response.Items, 1)
+			assert.Equal(t, JobItemResponse{
+				Item:           3,
+				Status:         repository.JobItemFailed,
+				NotificationID: "01JB8Z6",
+				Error:          "failure to sent the notifications",
+			}, response.Items[0])
+		})
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "", expected: ""},
+		{value: "provider rejected the address", expected: "provider rejected the address"},
+		{value: "=HYPERLINK(\"http://example.com\")", expected: "'=HYPERLINK(\"http://example.com\")"},
+		{value: "+1 555 0100 is not a mobile number", expected: "'+1 555 0100 is not a mobile number"},
+		{value: "@admin is unknown", expected: "'@admin is unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, escapeFormula(tt.value))
+		})
+	}
+}
+
+func TestNewJobResponse_TotalUnknown(t *testing.T) {
+	response := newJobResponse(repository.JobDetail{
+		Job:    repository.Job{ID: "01J", State: repository.JobStateQueued},
+		Counts: repository.JobItemCounts{Sent: 2},
+	})
+
+	assert.Nil(t, response.Total)
+	assert.Nil(t, response.Pending)
+	assert.Equal(t, []JobItemResponse{}, response.Items)
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPreferenceLimit = 50
+	maxPreferenceLimit     = 500
+)
+
+var (
+	errInvalidPreferenceLimit  = errors.New("limit must be between 1 and 500")
+	errInvalidPreferenceOffset = errors.New("offset must be zero or more")
+	errInvalidPreferenceID     = errors.New("preference id must be a positive integer")
+	errUnknownPreference       = errors.New("no preference with this id")
+	errUnresolvableSecretKey   = errors.New("next_secret_key cannot be resolved")
+)
+
+// PreferenceStatus describes a provider preference; the secret keys are
+// never returned, only whether they are set
+type PreferenceStatus struct {
+	ID             uint   `json:"id"`
+	ProviderType   string `json:"provider_type"`
+	ProviderName   string `json:"provider_name"`
+	Host           string `json:"host"`
+	Priority       int    `json:"priority"`
+	Enabled        bool   `json:"enabled"`
+	TrafficPercent int    `json:"traffic_percent"`
+	AuthMode       string `json:"auth_mode"`
+	Environment    string `json:"environment"`
+	Region         string `json:"region"`
+	HasSecretKey   bool   `json:"has_secret_key"`
+	// HasNextSecretKey is set while the credential is being rotated
+	HasNextSecretKey bool      `json:"has_next_secret_key"`
+	CreatedAt        time.Time `json:"created_at"`
+	// EnabledAt is when the provider was added or last enabled again, from
+	// which it warms up
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+}
+
+// RotateSecretKeyRequest stages the credential a preference rotates to; an
+// empty next_secret_key cancels the rotation
+type RotateSecretKeyRequest struct {
+	NextSecretKey string `json:"next_secret_key"`
+}
+
+type PreferencesResponse struct {
+	Preferences []PreferenceStatus `json:"preferences"`
+	Total       int64              `json:"total"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+}
+
+// PreferencesHandler lists the active preferences filtered by the
+// provider_type and host query parameters, ordered by sort (priority, id or
+// created_at, '-' prefixed for descending) and paged by limit and offset
+func (a *Admin) PreferencesHandler(c *gin.Context) {
+	filter := repository.PreferenceFilter{
+		ProviderType: c.Query("provider_type"),
+		Host:         c.Query("host"),
+		Sort:         c.Query("sort"),
+		Limit:        defaultPreferenceLimit,
+	}
+
+	if filter.ProviderType != "" {
+		if _, err := repository.ParseNotificationProvider(filter.ProviderType); err != nil {
+			c.JSON(http.StatusBadRequest, GetRequestError(err))
+			return
+		}
+	}
+	if _, err := repository.ParsePreferenceSort(filter.Sort); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxPreferenceLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidPreferenceLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidPreferenceOffset))
+			return
+		}
+	}
+
+	page, err := a.preferences.ListPreferences(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := PreferencesResponse{
+		Preferences: make([]PreferenceStatus, 0, len(page.Preferences)),
+		Total:       page.Total,
+		Limit:       filter.Limit,
+		Offset:      filter.Offset,
+	}
+	for _, preference := range page.Preferences {
+		response.Preferences = append(response.Preferences, newPreferenceStatus(preference))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisablePreferenceHandler pulls a provider out of rotation without losing
+// its config, e.g. while the vendor has an incident
+func (a *Admin) DisablePreferenceHandler(c *gin.Context) {
+	a.setPreferenceEnabled(c, false, AuditActionPreferenceDisable)
+}
+
+// EnablePreferenceHandler puts a disabled provider back into rotation
+func (a *Admin) EnablePreferenceHandler(c *gin.Context) {
+	a.setPreferenceEnabled(c, true, AuditActionPreferenceEnable)
+}
+
+func (a *Admin) setPreferenceEnabled(c *gin.Context, enabled bool, action string) {
+	id, ok := bindPreferenceID(c)
+	if !ok {
+		return
+	}
+
+	previous, err := a.preferences.SetPreferenceEnabled(c.Request.Context(), uint(id), enabled)
+	if err != nil {
+		respondPreferenceError(c, err)
+		return
+	}
+
+	// Cached preferences would keep a disabled provider in rotation until
+	// CACHE_EXPIRED_TIME; other instances still wait for it
+	a.preferenceCache.Clear()
+
+	before := newPreferenceStatus(previous)
+	after := before
+	after.Enabled = enabled
+
+	a.recordAudit(c, action, "preference:"+strconv.FormatUint(id, 10), before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+// RotateSecretKeyHandler stages the credential a provider rotates to. Until
+// it is promoted, a provider rejecting the secret key with 401 is sent the
+// next one, so the vendor can switch credentials at any time in between
+func (a *Admin) RotateSecretKeyHandler(c *gin.Context) {
+	id, ok := bindPreferenceID(c)
+	if !ok {
+		return
+	}
+
+	var req RotateSecretKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	// A reference that cannot be resolved would only fail once the vendor
+	// switched, so it is refused while the current key still works
+	if req.NextSecretKey != "" {
+		if _, err := a.secrets.Resolve(c.Request.Context(), req.NextSecretKey); err != nil {
+			logging.From(c.Request.Context(), a.logger).Warn("failed to resolve next secret key",
+				zap.Uint64("preference_id", id),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusBadRequest, GetRequestError(errUnresolvableSecretKey))
+			return
+		}
+	}
+
+	previous, err := a.preferences.SetNextSecretKey(c.Request.Context(), uint(id), req.NextSecretKey)
+	if err != nil {
+		respondPreferenceError(c, err)
+		return
+	}
+
+	a.preferenceCache.Clear()
+
+	before := newPreferenceStatus(previous)
+	after := before
+	after.HasNextSecretKey = req.NextSecretKey != ""
+
+	a.recordAudit(c, AuditActionPreferenceRotate, "preference:"+strconv.FormatUint(id, 10), before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+// PromoteSecretKeyHandler makes the staged credential the secret key once
+// the vendor accepts it, ending the rotation; the previous key is dropped
+func (a *Admin) PromoteSecretKeyHandler(c *gin.Context) {
+	id, ok := bindPreferenceID(c)
+	if !ok {
+		return
+	}
+
+	previous, err := a.preferences.PromoteNextSecretKey(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNoNextSecretKey) {
+			c.JSON(http.StatusConflict, GetRequestError(err))
+			return
+		}
+		respondPreferenceError(c, err)
+		return
+	}
+
+	a.preferenceCache.Clear()
+
+	before := newPreferenceStatus(previous)
+	after := before
+	after.HasSecretKey = true
+	after.HasNextSecretKey = false
+
+	a.recordAudit(c, AuditActionPreferencePromote, "preference:"+strconv.FormatUint(id, 10), before, after)
+	c.JSON(http.StatusOK, after)
+}
+
+func bindPreferenceID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, GetRequestError(errInvalidPreferenceID))
+		return 0, false
+	}
+	return id, true
+}
+
+func respondPreferenceError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, GetRequestError(errUnknownPreference))
+		return
+	}
+	respondInternalError(c, err)
+}
+
+func newPreferenceStatus(preference repository.NotificationPreference) PreferenceStatus {
+	return PreferenceStatus{
+		ID:               preference.ID,
+		ProviderType:     preference.ProviderType,
+		ProviderName:     preference.ProviderName,
+		Host:             preference.Host,
+		Priority:         preference.Priority,
+		Enabled:          preference.Enabled,
+		TrafficPercent:   preference.TrafficPercent,
+		AuthMode:         preference.AuthMode,
+		Environment:      preference.Environment,
+		Region:           preference.Region,
+		HasSecretKey:     preference.SecretKey != "",
+		HasNextSecretKey: preference.NextSecretKey != "",
+		CreatedAt:        preference.CreatedAt,
+		EnabledAt:        preference.EnabledAt,
+	}
+}
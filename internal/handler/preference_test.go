@@ -0,0 +1,435 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestAdmin_PreferencesHandler(t *testing.T) {
+	preference := repository.NotificationPreference{
+		Model:        gorm.Model{ID: 7, CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)},
+		ProviderType: "Email",
+		ProviderName: "MyProvider1",
+		Host:         "https://email.example.com",
+		SecretKey:    "SG.secret",
+		Priority:     1,
+		Environment:  "prod",
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockPreferenceAdminProvider)
+		expectedStatusCode int
+		expectedResponse   PreferencesResponse
+	}{
+		{
+			name:  "lists with default paging",
+			query: "",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider) {
+				preferences.EXPECT().ListPreferences(gomock.Any(), repository.PreferenceFilter{Limit: 50}).
+					Return(repository.PreferencePage{Preferences: []repository.NotificationPreference{preference}, Total: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: PreferencesResponse{
+				Preferences: []PreferenceStatus{{
+					ID:           7,
+					ProviderType: "Email",
+					ProviderName: "MyProvider1",
+					Host:         "https://email.example.com",
+					Priority:     1,
+					Environment:  "prod",
+					HasSecretKey: true,
+					CreatedAt:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+				}},
+				Total: 1,
+				Limit: 50,
+			},
+		},
+		{
+			name:  "passes filters, sort and paging",
+			query: "?provider_type=Email&host=https://email.example.com&sort=-priority&limit=10&offset=20",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider) {
+				preferences.EXPECT().ListPreferences(gomock.Any(), repository.PreferenceFilter{
+					ProviderType: "Email",
+					Host:         "https://email.example.com",
+					Sort:         "-priority",
+					Limit:        10,
+					Offset:       20,
+				}).Return(repository.PreferencePage{Total: 21}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: PreferencesResponse{
+				Preferences: []PreferenceStatus{},
+				Total:       21,
+				Limit:       10,
+				Offset:      20,
+			},
+		},
+		{
+			name:               "rejects unknown provider type",
+			query:              "?provider_type=SMS",
+			setupMocks:         func(*mockrepository.MockPreferenceAdminProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects unknown sort field",
+			query:              "?sort=host",
+			setupMocks:         func(*mockrepository.MockPreferenceAdminProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=501",
+			setupMocks:         func(*mockrepository.MockPreferenceAdminProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects negative offset",
+			query:              "?offset=-1",
+			setupMocks:         func(*mockrepository.MockPreferenceAdminProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider) {
+				preferences.EXPECT().ListPreferences(gomock.Any(), gomock.Any()).Return(repository.PreferencePage{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			preferences := mockrepository.NewMockPreferenceAdminProvider(ctrl)
+			tt.setupMocks(preferences)
+
+			admin := NewAdminHandler(AdminParams{
+				Preferences: preferences,
+				Logger:      zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/preferences", admin.PreferencesHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/preferences"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response PreferencesResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+			assert.NotContains(t, w.Body.String(), "SG.secret")
+		})
+	}
+}
+
+func TestAdmin_SetPreferenceEnabledHandler(t *testing.T) {
+	preference := repository.NotificationPreference{
+		Model:        gorm.Model{ID: 7, CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)},
+		ProviderType: "Email",
+		ProviderName: "MyProvider1",
+		Host:         "https://email.example.com",
+		Priority:     1,
+		Enabled:      true,
+	}
+
+	tests := []struct {
+		name               string
+		path               string
+		setupMocks         func(*mockrepository.MockPreferenceAdminProvider, *mockrepository.MockCacheProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+		expectedEnabled    bool
+	}{
+		{
+			name: "disables a preference",
+			path: "/admin/preferences/7/disable",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider, cache *mockrepository.MockCacheProvider, audit *mockrepository.MockAuditProvider) {
+				preferences.EXPECT().SetPreferenceEnabled(gomock.Any(), uint(7), false).Return(preference, nil)
+				cache.EXPECT().Clear()
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionPreferenceDisable, entry.Action)
+						assert.Equal(t, "preference:7", entry.Target)
+						assert.Contains(t, string(entry.Before), `"enabled":true`)
+						assert.Contains(t, string(entry.After), `"enabled":false`)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEnabled:    false,
+		},
+		{
+			name: "enables a preference",
+			path: "/admin/preferences/7/enable",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider, cache *mockrepository.MockCacheProvider, audit *mockrepository.MockAuditProvider) {
+				disabled := preference
+				disabled.Enabled = false
+				preferences.EXPECT().SetPreferenceEnabled(gomock.Any(), uint(7), true).Return(disabled, nil)
+				cache.EXPECT().Clear()
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEnabled:    true,
+		},
+		{
+			name: "rejects an invalid id",
+			path: "/admin/preferences/abc/disable",
+			setupMocks: func(*mockrepository.MockPreferenceAdminProvider, *mockrepository.MockCacheProvider, *mockrepository.MockAuditProvider) {
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "reports an unknown preference",
+			path: "/admin/preferences/99/disable",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider, _ *mockrepository.MockCacheProvider, _ *mockrepository.MockAuditProvider) {
+				preferences.EXPECT().SetPreferenceEnabled(gomock.Any(), uint(99), false).Return(repository.NotificationPreference{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "fails on database error",
+			path: "/admin/preferences/7/disable",
+			setupMocks: func(preferences *mockrepository.MockPreferenceAdminProvider, _ *mockrepository.MockCacheProvider, _ *mockrepository.MockAuditProvider) {
+				preferences.EXPECT().SetPreferenceEnabled(gomock.Any(), uint(7), false).Return(repository.NotificationPreference{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			preferences := mockrepository.NewMockPreferenceAdminProvider(ctrl)
+			cache := mockrepository.NewMockCacheProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(preferences, cache, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Preferences:     preferences,
+				PreferenceCache: cache,
+				Audit:           audit,
+				Logger:          zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/preferences/:id/disable", admin.DisablePreferenceHandler)
+			router.POST("/admin/preferences/:id/enable", admin.EnablePreferenceHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response PreferenceStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, uint(7), response.ID)
+			assert.Equal(t, tt.expectedEnabled, response.Enabled)
+		})
+	}
+}
+
+func TestAdmin_RotateSecretKeyHandler(t *testing.T) {
+	preference := repository.NotificationPreference{
+		Model:        gorm.Model{ID: 7, CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)},
+		ProviderType: "Email",
+		ProviderName: "MyProvider1",
+		Host:         "https://email.example.com",
+		SecretKey:    "old-key",
+		Enabled:      true,
+	}
+
+	type mocks struct {
+		preferences *mockrepository.MockPreferenceAdminProvider
+		cache       *mockrepository.MockCacheProvider
+		audit       *mockrepository.MockAuditProvider
+		secrets     *mocksecret.MockProvider
+	}
+
+	tests := []struct {
+		name               string
+		path               string
+		body               string
+		setupMocks         func(mocks)
+		expectedStatusCode int
+		expectedNext       bool
+	}{
+		{
+			name: "stages the next secret key",
+			path: "/admin/preferences/7/rotate",
+			body: `{"next_secret_key": "vault://secret/data/email#next"}`,
+			setupMocks: func(m mocks) {
+				m.secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email#next").Return("new-key", nil)
+				m.preferences.EXPECT().SetNextSecretKey(gomock.Any(), uint(7), "vault://secret/data/email#next").Return(preference, nil)
+				m.cache.EXPECT().Clear()
+				m.audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionPreferenceRotate, entry.Action)
+						assert.Equal(t, "preference:7", entry.Target)
+						assert.Contains(t, string(entry.Before), `"has_next_secret_key":false`)
+						assert.Contains(t, string(entry.After), `"has_next_secret_key":true`)
+						assert.NotContains(t, string(entry.After), "new-key")
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNext:       true,
+		},
+		{
+			name: "cancels the rotation",
+			path: "/admin/preferences/7/rotate",
+			body: `{"next_secret_key": ""}`,
+			setupMocks: func(m mocks) {
+				rotating := preference
+				rotating.NextSecretKey = "new-key"
+				m.preferences.EXPECT().SetNextSecretKey(gomock.Any(), uint(7), "").Return(rotating, nil)
+				m.cache.EXPECT().Clear()
+				m.audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNext:       false,
+		},
+		{
+			name: "rejects a key that cannot be resolved",
+			path: "/admin/preferences/7/rotate",
+			body: `{"next_secret_key": "vault://secret/data/email#missing"}`,
+			setupMocks: func(m mocks) {
+				m.secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email#missing").Return("", errors.New("vault returned status 404"))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects an invalid body",
+			path:               "/admin/preferences/7/rotate",
+			body:               `{"next_secret_key": 1}`,
+			setupMocks:         func(mocks) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects an invalid id",
+			path:               "/admin/preferences/abc/rotate",
+			body:               `{"next_secret_key": "new-key"}`,
+			setupMocks:         func(mocks) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "reports an unknown preference",
+			path: "/admin/preferences/99/rotate",
+			body: `{"next_secret_key": "new-key"}`,
+			setupMocks: func(m mocks) {
+				m.secrets.EXPECT().Resolve(gomock.Any(), "new-key").Return("new-key", nil)
+				m.preferences.EXPECT().SetNextSecretKey(gomock.Any(), uint(99), "new-key").Return(repository.NotificationPreference{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "promotes the next secret key",
+			path: "/admin/preferences/7/promote",
+			setupMocks: func(m mocks) {
+				rotating := preference
+				rotating.NextSecretKey = "new-key"
+				m.preferences.EXPECT().PromoteNextSecretKey(gomock.Any(), uint(7)).Return(rotating, nil)
+				m.cache.EXPECT().Clear()
+				m.audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionPreferencePromote, entry.Action)
+						assert.Contains(t, string(entry.Before), `"has_next_secret_key":true`)
+						assert.Contains(t, string(entry.After), `"has_next_secret_key":false`)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedNext:       false,
+		},
+		{
+			name: "refuses to promote without a next secret key",
+			path: "/admin/preferences/7/promote",
+			setupMocks: func(m mocks) {
+				m.preferences.EXPECT().PromoteNextSecretKey(gomock.Any(), uint(7)).Return(repository.NotificationPreference{}, repository.ErrNoNextSecretKey)
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name: "fails on database error",
+			path: "/admin/preferences/7/promote",
+			setupMocks: func(m mocks) {
+				m.preferences.EXPECT().PromoteNextSecretKey(gomock.Any(), uint(7)).Return(repository.NotificationPreference{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			m := mocks{
+				preferences: mockrepository.NewMockPreferenceAdminProvider(ctrl),
+				cache:       mockrepository.NewMockCacheProvider(ctrl),
+				audit:       mockrepository.NewMockAuditProvider(ctrl),
+				secrets:     mocksecret.NewMockProvider(ctrl),
+			}
+			tt.setupMocks(m)
+
+			admin := NewAdminHandler(AdminParams{
+				Preferences:     m.preferences,
+				PreferenceCache: m.cache,
+				Audit:           m.audit,
+				Secrets:         m.secrets,
+				Logger:          zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/preferences/:id/rotate", admin.RotateSecretKeyHandler)
+			router.POST("/admin/preferences/:id/promote", admin.PromoteSecretKeyHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response PreferenceStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, uint(7), response.ID)
+			assert.True(t, response.HasSecretKey)
+			assert.Equal(t, tt.expectedNext, response.HasNextSecretKey)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/koungkub/fw-challenge-notification-service/api/notifypb"
+	"google.golang.org/protobuf/proto"
+)
+
+var errProtobufUnsupported = errors.New("protobuf bodies are only accepted by the notify endpoints")
+
+// protoContract is a request body also accepted as a message of
+// api/notify.proto
+type protoContract interface {
+	// bindProto fills the request from its protobuf message. Unknown fields
+	// are dropped, as protobuf readers do, so older instances accept bodies
+	// of newer callers
+	bindProto(data []byte) error
+}
+
+func (r *NotifyRequest) bindProto(data []byte) error {
+	var message notifypb.NotifyRequest
+	if err := proto.Unmarshal(data, &message); err != nil {
+		return fmt.Errorf("protobuf body: %w", err)
+	}
+
+	*r = NotifyRequest{
+		MessageID:   message.GetMessageId(),
+		To:          message.GetTo(),
+		Title:       message.GetTitle(),
+		Message:     message.GetMessage(),
+		ThreadKey:   message.GetThreadKey(),
+		Priority:    message.GetPriority(),
+		Category:    message.GetCategory(),
+		Locale:      message.GetLocale(),
+		TitleKey:    message.GetTitleKey(),
+		MessageKey:  message.GetMessageKey(),
+		Params:      message.GetParams(),
+		Template:    message.GetTemplate(),
+		HTML:        message.GetHtml(),
+		Attachments: attachmentRequests(message.GetAttachments()),
+		DeepLink:    message.GetDeepLink(),
+		ImageURL:    message.GetImageUrl(),
+		Metadata:    message.GetMetadata(),
+	}
+	return nil
+}
+
+func (r *NotifyRequestV2) bindProto(data []byte) error {
+	var message notifypb.NotifyRequestV2
+	if err := proto.Unmarshal(data, &message); err != nil {
+		return fmt.Errorf("protobuf body: %w", err)
+	}
+
+	*r = NotifyRequestV2{
+		MessageID: message.GetMessageId(),
+		To:        message.GetTo(),
+		Channels:  message.GetChannels(),
+		ThreadKey: message.GetThreadKey(),
+		Priority:  message.GetPriority(),
+		Category:  message.GetCategory(),
+		Metadata:  message.GetMetadata(),
+	}
+	if content := message.GetContent(); content != nil {
+		r.Content = &ContentRequest{
+			Title:   content.GetTitle(),
+			Message: content.GetMessage(),
+		}
+	}
+	if template := message.GetTemplate(); template != nil {
+		r.Template = &TemplateRequest{
+			Name:       template.GetName(),
+			TitleKey:   template.GetTitleKey(),
+			MessageKey: template.GetMessageKey(),
+			Locale:     template.GetLocale(),
+			Params:     template.GetParams(),
+		}
+	}
+	if rich := message.GetRich(); rich != nil {
+		r.Rich = &RichRequest{
+			HTML:        rich.GetHtml(),
+			Attachments: attachmentRequests(rich.GetAttachments()),
+			DeepLink:    rich.GetDeepLink(),
+			ImageURL:    rich.GetImageUrl(),
+		}
+	}
+	return nil
+}
+
+// attachmentRequests encodes the raw content of protobuf attachments as the
+// base64 of JSON bodies
+func attachmentRequests(attachments []*notifypb.Attachment) []AttachmentRequest {
+	var requests []AttachmentRequest
+	for _, attachment := range attachments {
+		request := AttachmentRequest{
+			Filename:    attachment.GetFilename(),
+			ContentType: attachment.GetContentType(),
+			URL:         attachment.GetUrl(),
+		}
+		if content := attachment.GetContent(); len(content) > 0 {
+			request.Content = base64.StdEncoding.EncodeToString(content)
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/api/notifypb"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// encodeProto encodes the JSON form of message, a message of
+// api/notify.proto
+func encodeProto(t *testing.T, message proto.Message, body string) []byte {
+	t.Helper()
+
+	require.NoError(t, protojson.Unmarshal([]byte(body), message))
+
+	data, err := proto.Marshal(message)
+	require.NoError(t, err)
+	return data
+}
+
+func TestNotifyRequest_bindProto(t *testing.T) {
+	t.Run("binds every field", func(t *testing.T) {
+		data := encodeProto(t, &notifypb.NotifyRequest{}, `{"to":"buyer@example.com","title":"Order shipped","params":{"order_id":"42"},"attachments":[{"filename":"invoice.pdf","content":"JVBERi0="}],"metadata":{"order_id":"42"}}`)
+
+		var req NotifyRequest
+		require.NoError(t, req.bindProto(data))
+
+		assert.Equal(t, NotifyRequest{
+			To:          "buyer@example.com",
+			Title:       "Order shipped",
+			Params:      map[string]string{"order_id": "42"},
+			Attachments: []AttachmentRequest{{Filename: "invoice.pdf", Content: "JVBERi0="}},
+			Metadata:    map[string]string{"order_id": "42"},
+		}, req)
+	})
+
+	t.Run("drops unknown fields", func(t *testing.T) {
+		data := encodeProto(t, &notifypb.NotifyRequest{}, `{"to":"buyer@example.com"}`)
+		data = protowire.AppendTag(data, 99, protowire.BytesType)
+		data = protowire.AppendString(data, "from a newer caller")
+
+		var req NotifyRequest
+		require.NoError(t, req.bindProto(data))
+
+		assert.Equal(t, NotifyRequest{To: "buyer@example.com"}, req)
+	})
+
+	t.Run("rejects a malformed body", func(t *testing.T) {
+		var req NotifyRequest
+		err := req.bindProto([]byte("not protobuf"))
+
+		assert.ErrorContains(t, err, "protobuf body")
+	})
+}
+
+func TestNotifyRequestV2_bindProto(t *testing.T) {
+	data := encodeProto(t, &notifypb.NotifyRequestV2{}, `{"to":"seller@example.com","channels":["Email"],"template":{"title_key":"order.title","message_key":"order.message","params":{"order_id":"42"}},"rich":{"html":"<p>Hi</p>","attachments":[{"filename":"invoice.pdf","url":"https://example.com/invoice.pdf"}]}}`)
+
+	var req NotifyRequestV2
+	require.NoError(t, req.bindProto(data))
+
+	assert.Equal(t, NotifyRequestV2{
+		To:       "seller@example.com",
+		Channels: []string{"Email"},
+		Template: &TemplateRequest{
+			TitleKey:   "order.title",
+			MessageKey: "order.message",
+			Params:     map[string]string{"order_id": "42"},
+		},
+		Rich: &RichRequest{
+			HTML:        "<p>Hi</p>",
+			Attachments: []AttachmentRequest{{Filename: "invoice.pdf", URL: "https://example.com/invoice.pdf"}},
+		},
+	}, req)
+}
+
+func TestNotification_NotifyHandler_Protobuf(t *testing.T) {
+	tests := []struct {
+		name               string
+		version            string
+		message            proto.Message
+		requestBody        string
+		maxBodySize        int64
+		setupMocks         func(*mockservice.MockNotificationProvider)
+		expectedStatusCode int
+	}{
+		{
+			name:        "delivers a v1.0 request",
+			version:     "v1",
+			message:     &notifypb.NotifyRequest{},
+			requestBody: `{"to":"buyer@example.com","title":"Order shipped","message":"Your order is on its way","priority":"high","attachments":[{"filename":"invoice.pdf","content":"JVBERi0="}]}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "buyer@example.com",
+					Title:    "Order shipped",
+					Message:  "Your order is on its way",
+					Priority: dispatch.PriorityHigh,
+					Attachments: []service.Attachment{
+						{Filename: "invoice.pdf", Content: "JVBERi0="},
+					},
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "validates a v1.0 request like json",
+			version:            "v1",
+			message:            &notifypb.NotifyRequest{},
+			requestBody:        `{"title":"Order shipped","message":"Your order is on its way","priority":"urgent"}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "delivers a v2.0 request",
+			version:     "v2",
+			message:     &notifypb.NotifyRequestV2{},
+			requestBody: `{"to":"seller@example.com","channels":["PushNotification"],"content":{"title":"New Order","message":"You have a new order"}}`,
+			setupMocks: func(mockService *mockservice.MockNotificationProvider) {
+				mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "seller@example.com",
+					Channels: []string{"PushNotification"},
+					Title:    "New Order",
+					Message:  "You have a new order",
+				}).Return(service.DeliveryReport{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "validates a v2.0 request like json",
+			version:            "v2",
+			message:            &notifypb.NotifyRequestV2{},
+			requestBody:        `{"to":"seller@example.com","content":{"title":"t","message":"m"},"template":{"title_key":"t","message_key":"m"}}`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "refuses a body over the size limit",
+			version:            "v1",
+			message:            &notifypb.NotifyRequest{},
+			requestBody:        `{"to":"buyer@example.com","title":"Order shipped","message":"Your order is on its way"}`,
+			maxBodySize:        16,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(mockService)
+
+			maxBodySize := tt.maxBodySize
+			if maxBodySize == 0 {
+				maxBodySize = 1 << 20
+			}
+
+			handler := NewNotificationHandler(NotificationParams{
+				Services: mockService,
+				Config:   HandlerConfig{StrictRequestField: true, ProtobufMaxBodySize: maxBodySize},
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/v1/notify/:recipient", handler.NotifyHandler)
+			router.POST("/v2/notify/:recipient", handler.NotifyV2Handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tt.version+"/notify/buyer",
+				bytes.NewReader(encodeProto(t, tt.message, tt.requestBody)))
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestNotification_bindRequest_ProtobufUnsupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/replay", strings.NewReader(""))
+	c.Request.Header.Set("Content-Type", "application/x-protobuf")
+
+	var req ReplayRequest
+	err := NewNotificationHandler(NotificationParams{}).bindRequest(c, &req)
+
+	assert.ErrorIs(t, err, errProtobufUnsupported)
+}
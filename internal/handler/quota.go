@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"go.uber.org/fx"
+)
+
+var errNoQuotaSubject = errors.New("requests without an api key have no quota")
+
+type QuotaResponse struct {
+	// Subject is the tenant of the API key, or the id of the key
+	Subject string        `json:"subject"`
+	Quotas  []quota.Usage `json:"quotas"`
+}
+
+// Quota serves the send quotas of the API key calling
+type Quota struct {
+	quotas quota.Limiter
+}
+
+type QuotaParams struct {
+	fx.In
+
+	Quotas quota.Limiter
+}
+
+func NewQuotaHandler(params QuotaParams) *Quota {
+	return &Quota{
+		quotas: params.Quotas,
+	}
+}
+
+// UsageHandler reports the daily and monthly quota of the caller
+func (h *Quota) UsageHandler(c *gin.Context) {
+	subject := quota.SubjectFrom(c.Request.Context())
+	if subject == "" {
+		c.JSON(http.StatusNotFound, GetRequestError(errNoQuotaSubject))
+		return
+	}
+
+	usages, err := h.quotas.Usage(c.Request.Context(), subject)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, QuotaResponse{
+		Subject: subject,
+		Quotas:  usages,
+	})
+}
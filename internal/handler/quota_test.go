@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	mockquota "github.com/koungkub/fw-challenge-notification-service/internal/quota/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQuota_UsageHandler(t *testing.T) {
+	usages := []quota.Usage{
+		{Period: "day", Limit: 1000, Used: 12, ResetsAt: time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{Period: "month", Used: 340, ResetsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name               string
+		subject            string
+		setupMocks         func(*mockquota.MockLimiter)
+		expectedStatusCode int
+		expectedResponse   QuotaResponse
+	}{
+		{
+			name:    "reports the quotas of the caller",
+			subject: "acme",
+			setupMocks: func(quotas *mockquota.MockLimiter) {
+				quotas.EXPECT().Usage(gomock.Any(), "acme").Return(usages, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   QuotaResponse{Subject: "acme", Quotas: usages},
+		},
+		{
+			name:               "has no quota without an api key",
+			setupMocks:         func(*mockquota.MockLimiter) {},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:    "fails on database error",
+			subject: "acme",
+			setupMocks: func(quotas *mockquota.MockLimiter) {
+				quotas.EXPECT().Usage(gomock.Any(), "acme").Return(nil, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			quotas := mockquota.NewMockLimiter(ctrl)
+			tt.setupMocks(quotas)
+
+			handler := NewQuotaHandler(QuotaParams{Quotas: quotas})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/quota", handler.UsageHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/quota", nil)
+			if tt.subject != "" {
+				req = req.WithContext(quota.WithSubject(context.Background(), tt.subject))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response QuotaResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
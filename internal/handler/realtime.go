@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/realtime"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+	"golang.org/x/net/websocket"
+)
+
+// Realtime message types sent over WebSocket connections
+const (
+	RealtimeMessageNotification = "notification"
+	RealtimeMessageHeartbeat    = "heartbeat"
+)
+
+var (
+	errTicketRequired  = errors.New("ticket query parameter is required")
+	errInvalidTicket   = errors.New("ticket is invalid or expired")
+	errTicketOtherUser = errors.New("ticket was issued for another user")
+)
+
+// RealtimeMessage is one WebSocket message; Notification is set on
+// notification messages
+type RealtimeMessage struct {
+	Type         string                        `json:"type"`
+	Notification *repository.InAppNotification `json:"notification,omitempty"`
+}
+
+// TicketResponse is a subscription ticket of one user, passed as the ticket
+// query parameter of the stream and WebSocket routes
+type TicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Realtime pushes in-app notifications to users as they are stored, over
+// Server-Sent Events or WebSocket
+type Realtime struct {
+	subscriber   realtime.Subscriber
+	config       realtime.RealtimeConfig
+	clock        clock.Clock
+	ticketSecret []byte
+}
+
+type RealtimeParams struct {
+	fx.In
+
+	Subscriber realtime.Subscriber
+	Config     realtime.RealtimeConfig
+	Clock      clock.Clock
+}
+
+func NewRealtimeHandler(params RealtimeParams) *Realtime {
+	ticketSecret := []byte(params.Config.TicketSecret)
+	if len(ticketSecret) == 0 {
+		ticketSecret = make([]byte, 32)
+		rand.Read(ticketSecret)
+	}
+
+	return &Realtime{
+		subscriber:   params.Subscriber,
+		config:       params.Config,
+		clock:        params.Clock,
+		ticketSecret: ticketSecret,
+	}
+}
+
+// Close ends every realtime connection, so stopping the server does not
+// wait for clients to hang up
+func (h *Realtime) Close() {
+	h.subscriber.Close()
+}
+
+// TicketHandler issues a ticket letting the user open a realtime
+// connection for REALTIME_TICKET_TTL. Browsers cannot send an API key on an
+// EventSource or WebSocket, so the app backend fetches the ticket and hands
+// it to the user instead
+func (h *Realtime) TicketHandler(c *gin.Context) {
+	expiresAt := time.Unix(h.clock.Now().Add(h.config.TicketTTL).Unix(), 0).UTC()
+	ticket, err := h.issueTicket(c.Param("user"), quota.SubjectFrom(c.Request.Context()), expiresAt)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, TicketResponse{Ticket: ticket, ExpiresAt: expiresAt})
+}
+
+// StreamHandler streams the in-app notifications of the user as
+// Server-Sent Events named notification, with a comment line as heartbeat
+// while idle
+func (h *Realtime) StreamHandler(c *gin.Context) {
+	subscription, ok := h.subscribe(c, realtime.TransportSSE)
+	if !ok {
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-subscription.Notifications():
+			if !ok {
+				return
+			}
+			err = writeEvent(c.Writer, notification)
+		case <-h.clock.After(h.config.HeartbeatInterval):
+			_, err = fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// WebSocketHandler sends the in-app notifications of the user as JSON
+// RealtimeMessage frames, with a heartbeat message while idle. Messages
+// from the client are ignored
+func (h *Realtime) WebSocketHandler(c *gin.Context) {
+	subscription, ok := h.subscribe(c, realtime.TransportWebSocket)
+	if !ok {
+		return
+	}
+	defer subscription.Close()
+
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			ctx, cancel := context.WithCancel(c.Request.Context())
+			defer cancel()
+
+			// Reading is the only way to notice the client going away
+			go func() {
+				defer cancel()
+				var message string
+				for websocket.Message.Receive(conn, &message) == nil {
+				}
+			}()
+
+			for {
+				var err error
+				select {
+				case <-ctx.Done():
+					return
+				case notification, ok := <-subscription.Notifications():
+					if !ok {
+						return
+					}
+					err = websocket.JSON.Send(conn, RealtimeMessage{Type: RealtimeMessageNotification, Notification: &notification})
+				case <-h.clock.After(h.config.HeartbeatInterval):
+					err = websocket.JSON.Send(conn, RealtimeMessage{Type: RealtimeMessageHeartbeat})
+				}
+				if err != nil {
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// subscribe checks the ticket and opens the connection before the response
+// starts, so a refused connection still gets a JSON error
+func (h *Realtime) subscribe(c *gin.Context, transport string) (*realtime.Subscription, bool) {
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		c.JSON(http.StatusUnauthorized, GetRequestError(errTicketRequired))
+		return nil, false
+	}
+	subject, err := h.verifyTicket(ticket, c.Param("user"))
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, errTicketOtherUser) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, GetRequestError(err))
+		return nil, false
+	}
+	c.Request = c.Request.WithContext(quota.WithSubject(c.Request.Context(), subject))
+
+	subscription, err := h.subscriber.Subscribe(c.Request.Context(), c.Param("user"), transport)
+	if err != nil {
+		switch {
+		case errors.Is(err, realtime.ErrTooManyConnections):
+			c.JSON(http.StatusTooManyRequests, GetRequestError(err))
+		case errors.Is(err, realtime.ErrClosed):
+			c.JSON(http.StatusServiceUnavailable, GetInternalError(err))
+		default:
+			respondInternalError(c, err)
+		}
+		return nil, false
+	}
+	return subscription, true
+}
+
+// realtimeTicket is the signed content of a ticket: the user it lets
+// connect, the quota subject of the key it was issued to and its expiry
+type realtimeTicket struct {
+	User      string `json:"user"`
+	Subject   string `json:"subject,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// issueTicket encodes the ticket as its base64url JSON and HMAC-SHA256
+// joined by a dot
+func (h *Realtime) issueTicket(user string, subject string, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(realtimeTicket{User: user, Subject: subject, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(h.signTicket(encoded)), nil
+}
+
+// verifyTicket returns the quota subject of a ticket issued for the user
+// and not yet expired
+func (h *Realtime) verifyTicket(ticket string, user string) (string, error) {
+	encoded, signature, ok := strings.Cut(ticket, ".")
+	if !ok {
+		return "", errInvalidTicket
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, h.signTicket(encoded)) {
+		return "", errInvalidTicket
+	}
+
+	var claims realtimeTicket
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return "", errInvalidTicket
+	}
+	if !h.clock.Now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return "", errInvalidTicket
+	}
+	if claims.User != user {
+		return "", errTicketOtherUser
+	}
+	return claims.Subject, nil
+}
+
+func (h *Realtime) signTicket(encoded string) []byte {
+	mac := hmac.New(sha256.New, h.ticketSecret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func writeEvent(w gin.ResponseWriter, notification repository.InAppNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", notification.ID, data)
+	return err
+}
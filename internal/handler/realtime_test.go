@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/realtime"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/net/websocket"
+)
+
+func newTestRealtime(t *testing.T, config realtime.RealtimeConfig) (*realtime.Broker, *httptest.Server) {
+	config.TicketSecret = testTicketSecret
+
+	metricsCollector, err := metrics.NewRealtimeCollector(nil)
+	require.NoError(t, err)
+
+	broker := realtime.NewBroker(realtime.BrokerParams{
+		Config:           config,
+		Clock:            clock.NewRealClock(),
+		MetricsCollector: metricsCollector,
+	})
+	handler := NewRealtimeHandler(RealtimeParams{
+		Subscriber: broker,
+		Config:     config,
+		Clock:      clock.NewRealClock(),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/inapp/:user/tickets", handler.TicketHandler)
+	router.GET("/inapp/:user/stream", handler.StreamHandler)
+	router.GET("/inapp/:user/ws", handler.WebSocketHandler)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		handler.Close()
+		server.Close()
+	})
+	return broker, server
+}
+
+const testTicketSecret = "s3cret"
+
+// testTicket issues a ticket of the user to the acme tenant, valid for a
+// minute
+func testTicket(t *testing.T, user string) string {
+	handler := NewRealtimeHandler(RealtimeParams{
+		Config: realtime.RealtimeConfig{TicketSecret: testTicketSecret},
+		Clock:  clock.NewRealClock(),
+	})
+	ticket, err := handler.issueTicket(user, "acme", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	return ticket
+}
+
+// readEvent returns the next event or comment of an SSE stream
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestRealtime_StreamHandler(t *testing.T) {
+	notification := repository.InAppNotification{
+		ID:        7,
+		Recipient: "user-42",
+		Tenant:    "acme",
+		Title:     "Order shipped",
+		Message:   "Your order is on its way",
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("streams notifications of the user", func(t *testing.T) {
+		broker, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+
+		resp, err := http.Get(server.URL + "/inapp/user-42/stream?ticket=" + testTicket(t, "user-42"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		broker.Publish(context.Background(), repository.InAppNotification{ID: 5, Recipient: "user-42", Tenant: "globex"})
+		broker.Publish(context.Background(), repository.InAppNotification{ID: 6, Recipient: "user-7", Tenant: "acme"})
+		broker.Publish(context.Background(), notification)
+
+		data, err := json.Marshal(notification)
+		require.NoError(t, err)
+		assert.Equal(t, "id: 7\nevent: notification\ndata: "+string(data)+"\n", readEvent(t, bufio.NewReader(resp.Body)))
+	})
+
+	t.Run("sends heartbeats while idle", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: 10 * time.Millisecond})
+
+		resp, err := http.Get(server.URL + "/inapp/user-42/stream?ticket=" + testTicket(t, "user-42"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, ": heartbeat\n", readEvent(t, bufio.NewReader(resp.Body)))
+	})
+
+	t.Run("ends the stream when the broker closes", func(t *testing.T) {
+		broker, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+
+		resp, err := http.Get(server.URL + "/inapp/user-42/stream?ticket=" + testTicket(t, "user-42"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		broker.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, body)
+	})
+
+	t.Run("refuses connections above the limit", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 0, HeartbeatInterval: time.Hour})
+
+		resp, err := http.Get(server.URL + "/inapp/user-42/stream?ticket=" + testTicket(t, "user-42"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("refuses connections without a valid ticket", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+
+		expired := NewRealtimeHandler(RealtimeParams{
+			Config: realtime.RealtimeConfig{TicketSecret: testTicketSecret},
+			Clock:  clock.NewRealClock(),
+		})
+		expiredTicket, err := expired.issueTicket("user-42", "acme", time.Now().Add(-time.Second))
+		require.NoError(t, err)
+
+		tests := []struct {
+			name               string
+			query              string
+			expectedStatusCode int
+		}{
+			{
+				name:               "missing",
+				query:              "",
+				expectedStatusCode: http.StatusUnauthorized,
+			},
+			{
+				name:               "malformed",
+				query:              "?ticket=user-42",
+				expectedStatusCode: http.StatusUnauthorized,
+			},
+			{
+				name:               "signed with another secret",
+				query:              "?ticket=" + strings.Split(testTicket(t, "user-42"), ".")[0] + ".c2lnbmF0dXJl",
+				expectedStatusCode: http.StatusUnauthorized,
+			},
+			{
+				name:               "expired",
+				query:              "?ticket=" + expiredTicket,
+				expectedStatusCode: http.StatusUnauthorized,
+			},
+			{
+				name:               "issued for another user",
+				query:              "?ticket=" + testTicket(t, "user-7"),
+				expectedStatusCode: http.StatusForbidden,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				resp, err := http.Get(server.URL + "/inapp/user-42/stream" + tt.query)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+
+				assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("refuses connections while stopping", func(t *testing.T) {
+		broker, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+		broker.Close()
+
+		resp, err := http.Get(server.URL + "/inapp/user-42/stream?ticket=" + testTicket(t, "user-42"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+}
+
+func TestRealtime_WebSocketHandler(t *testing.T) {
+	t.Run("sends notifications of the user", func(t *testing.T) {
+		broker, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+
+		conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/inapp/user-42/ws?ticket="+testTicket(t, "user-42"), "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		notification := repository.InAppNotification{ID: 7, Recipient: "user-42", Title: "Order shipped", Tenant: "acme"}
+		broker.Publish(context.Background(), notification)
+
+		var message RealtimeMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		assert.Equal(t, RealtimeMessageNotification, message.Type)
+		require.NotNil(t, message.Notification)
+		assert.Equal(t, uint(7), message.Notification.ID)
+	})
+
+	t.Run("sends heartbeats while idle", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: 10 * time.Millisecond})
+
+		conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/inapp/user-42/ws?ticket="+testTicket(t, "user-42"), "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var message RealtimeMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		assert.Equal(t, RealtimeMessage{Type: RealtimeMessageHeartbeat}, message)
+	})
+
+	t.Run("refuses a ticket of another user", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5, HeartbeatInterval: time.Hour})
+
+		_, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/inapp/user-42/ws?ticket="+testTicket(t, "user-7"), "", server.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("refuses connections above the limit", func(t *testing.T) {
+		_, server := newTestRealtime(t, realtime.RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 0, HeartbeatInterval: time.Hour})
+
+		_, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/inapp/user-42/ws?ticket="+testTicket(t, "user-42"), "", server.URL)
+		assert.Error(t, err)
+	})
+}
+
+func TestRealtime_TicketHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(now).AnyTimes()
+
+	handler := NewRealtimeHandler(RealtimeParams{
+		Config: realtime.RealtimeConfig{TicketSecret: testTicketSecret, TicketTTL: time.Minute},
+		Clock:  mockClock,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(quota.WithSubject(c.Request.Context(), "acme"))
+	})
+	router.POST("/inapp/:user/tickets", handler.TicketHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/inapp/user-42/tickets", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var response TicketResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, now.Add(time.Minute).Unix(), response.ExpiresAt.Unix())
+
+	subject, err := handler.verifyTicket(response.Ticket, "user-42")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", subject)
+
+	_, err = handler.verifyTicket(response.Ticket, "user-7")
+	assert.ErrorIs(t, err, errTicketOtherUser)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"go.uber.org/fx"
+)
+
+// Receipt webhooks are signed with HMAC-SHA256 over the timestamp, a dot
+// and the raw body, hex encoded and optionally prefixed with sha256=
+const (
+	HeaderReceiptTimestamp = "X-Receipt-Timestamp"
+	HeaderReceiptSignature = "X-Receipt-Signature"
+)
+
+var (
+	errUnknownReceiptProvider  = errors.New("unknown provider")
+	errInvalidReceiptSignature = errors.New("invalid receipt signature")
+	errExpiredReceiptSignature = errors.New("receipt timestamp outside the signature tolerance")
+)
+
+type ReceiptConfig struct {
+	// SigningKeys maps the provider_name of a preference to the key signing
+	// its receipts; a key may be a secret reference
+	SigningKeys map[string]string `envconfig:"RECEIPT_SIGNING_KEYS" secret:"true"`
+	// SignatureTolerance bounds the age of a signed timestamp, so captured
+	// requests cannot be replayed later; zero disables the check
+	SignatureTolerance time.Duration `envconfig:"RECEIPT_SIGNATURE_TOLERANCE" default:"5m"`
+	MaxBodySize        int64         `envconfig:"RECEIPT_MAX_BODY_SIZE" default:"1048576"`
+}
+
+// ReceiptRequest carries delivery receipts of notifications a provider
+// accepted; NotificationID is the id the notification was sent with
+type ReceiptRequest struct {
+	Receipts []ReceiptEvent `json:"receipts" binding:"required,min=1,dive"`
+}
+
+// ReceiptEvent reports one notification; BounceType tells a permanent hard
+// bounce from a transient soft one and defaults to hard
+type ReceiptEvent struct {
+	NotificationID string `json:"notification_id" binding:"required"`
+	Event          string `json:"event" binding:"required"`
+	BounceType     string `json:"bounce_type" binding:"omitempty,oneof=hard soft"`
+	Reason         string `json:"reason"`
+}
+
+// suppressionReason is the reason the receipt suppresses the recipient
+// for, empty when it does not
+func (e ReceiptEvent) suppressionReason() string {
+	switch {
+	case e.Event == repository.LogStatusBounced && e.BounceType != "soft":
+		return repository.SuppressionHardBounce
+	case e.Event == repository.LogStatusComplained:
+		return repository.SuppressionComplaint
+	default:
+		return ""
+	}
+}
+
+// ReceiptResponse counts the receipts applied to the notification log and
+// those ignored, because they matched no notification of the provider or
+// arrived after a later status. Suppressed counts the addresses newly
+// added to the suppression list
+type ReceiptResponse struct {
+	Applied    int `json:"applied"`
+	Ignored    int `json:"ignored"`
+	Suppressed int `json:"suppressed"`
+}
+
+// Receipts takes delivery receipts posted by providers
+type Receipts struct {
+	config           ReceiptConfig
+	secrets          secret.Provider
+	notificationLog  repository.NotificationLogProvider
+	suppressions     repository.SuppressionProvider
+	metricsCollector *metrics.ReceiptCollector
+	clock            clock.Clock
+}
+
+type ReceiptsParams struct {
+	fx.In
+
+	Config           ReceiptConfig
+	Secrets          secret.Provider
+	NotificationLog  repository.NotificationLogProvider
+	Suppressions     repository.SuppressionProvider
+	MetricsCollector *metrics.ReceiptCollector
+	Clock            clock.Clock
+}
+
+func NewReceiptsHandler(params ReceiptsParams) *Receipts {
+	return &Receipts{
+		config:           params.Config,
+		secrets:          params.Secrets,
+		notificationLog:  params.NotificationLog,
+		suppressions:     params.Suppressions,
+		metricsCollector: params.MetricsCollector,
+		clock:            params.Clock,
+	}
+}
+
+// ReceiptHandler applies the delivered, bounced, opened and complained
+// receipts of a provider to the notification log, suppressing the email
+// address of hard bounces and complaints. The provider authenticates by
+// signing the body, so only providers with a RECEIPT_SIGNING_KEYS entry are
+// served. Receipts are idempotent, so a provider retrying after an error is
+// safe
+func (r *Receipts) ReceiptHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	provider := c.Param("provider")
+
+	key, ok := r.config.SigningKeys[provider]
+	if !ok {
+		c.JSON(http.StatusNotFound, GetRequestError(errUnknownReceiptProvider))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, r.config.MaxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, GetRequestError(err))
+			return
+		}
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	key, err = r.secrets.Resolve(ctx, key)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	err = r.verifySignature(key, c.GetHeader(HeaderReceiptTimestamp), c.GetHeader(HeaderReceiptSignature), body)
+	if err != nil {
+		r.metricsCollector.RecordRejection(ctx, provider, metrics.ReceiptRejectedSignature)
+		c.JSON(http.StatusUnauthorized, GetRequestError(err))
+		return
+	}
+
+	var req ReceiptRequest
+	if err := decodeReceipts(body, &req); err != nil {
+		r.metricsCollector.RecordRejection(ctx, provider, metrics.ReceiptRejectedPayload)
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	var response ReceiptResponse
+	for _, receipt := range req.Receipts {
+		applied, err := r.notificationLog.UpdateNotificationStatus(ctx, provider, receipt.NotificationID, receipt.Event, receipt.Reason)
+		if err != nil {
+			respondInternalError(c, err)
+			return
+		}
+
+		outcome := metrics.ReceiptIgnored
+		if applied {
+			outcome = metrics.ReceiptApplied
+			response.Applied++
+		} else {
+			response.Ignored++
+		}
+		r.metricsCollector.RecordReceipt(ctx, provider, receipt.Event, outcome)
+
+		// Suppressed even when the status was already applied, so a retry
+		// after a failed suppression still adds the address
+		if reason := receipt.suppressionReason(); reason != "" {
+			suppressed, err := r.suppressions.SuppressNotificationRecipient(ctx, provider, receipt.NotificationID, reason, receipt.Reason)
+			if err != nil {
+				respondInternalError(c, err)
+				return
+			}
+			if suppressed {
+				response.Suppressed++
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (r *Receipts) verifySignature(key string, timestamp string, signature string, body []byte) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errInvalidReceiptSignature
+	}
+
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !hmac.Equal(given, signReceipt(key, timestamp, body)) {
+		return errInvalidReceiptSignature
+	}
+
+	// Checked once the timestamp is known to be authentic
+	if r.config.SignatureTolerance > 0 {
+		age := r.clock.Since(time.Unix(seconds, 0))
+		if age > r.config.SignatureTolerance || age < -r.config.SignatureTolerance {
+			return errExpiredReceiptSignature
+		}
+	}
+	return nil
+}
+
+func signReceipt(key string, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// decodeReceipts validates the whole request before any receipt is applied
+func decodeReceipts(body []byte, req *ReceiptRequest) error {
+	if err := json.Unmarshal(body, req); err != nil {
+		return err
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	for _, receipt := range req.Receipts {
+		if _, err := repository.ParseReceiptStatus(receipt.Event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReceipts_ReceiptHandler(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := `{"receipts":[{"notification_id":"01JB8Z5XK3M4N5P6Q7R8S9T0VW","event":"delivered"},{"notification_id":"01JB8Z6","event":"bounced","reason":"mailbox full"}]}`
+	suppressionBody := `{"receipts":[{"notification_id":"01J","event":"bounced","bounce_type":"soft"},{"notification_id":"01K","event":"complained"}]}`
+	sign := func(key string, timestamp string, body string) string {
+		return "sha256=" + hex.EncodeToString(signReceipt(key, timestamp, []byte(body)))
+	}
+
+	tests := []struct {
+		name               string
+		provider           string
+		body               string
+		timestamp          string
+		signature          string
+		setupMocks         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider)
+		expectedStatusCode int
+		expectedResponse   ReceiptResponse
+	}{
+		{
+			name:      "applies signed receipts",
+			provider:  "MyProvider1",
+			body:      body,
+			timestamp: timestamp,
+			signature: sign("whsec", timestamp, body),
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider, suppressions *mockrepository.MockSuppressionProvider) {
+				notificationLog.EXPECT().UpdateNotificationStatus(gomock.Any(), "MyProvider1", "01JB8Z5XK3M4N5P6Q7R8S9T0VW", repository.LogStatusDelivered, "").Return(true, nil)
+				notificationLog.EXPECT().UpdateNotificationStatus(gomock.Any(), "MyProvider1", "01JB8Z6", repository.LogStatusBounced, "mailbox full").Return(false, nil)
+				suppressions.EXPECT().SuppressNotificationRecipient(gomock.Any(), "MyProvider1", "01JB8Z6", repository.SuppressionHardBounce, "mailbox full").Return(true, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   ReceiptResponse{Applied: 1, Ignored: 1, Suppressed: 1},
+		},
+		{
+			name:      "suppresses complaints but not soft bounces",
+			provider:  "MyProvider1",
+			body:      suppressionBody,
+			timestamp: timestamp,
+			signature: sign("whsec", timestamp, suppressionBody),
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider, suppressions *mockrepository.MockSuppressionProvider) {
+				notificationLog.EXPECT().UpdateNotificationStatus(gomock.Any(), "MyProvider1", "01J", repository.LogStatusBounced, "").Return(true, nil)
+				notificationLog.EXPECT().UpdateNotificationStatus(gomock.Any(), "MyProvider1", "01K", repository.LogStatusComplained, "").Return(true, nil)
+				suppressions.EXPECT().SuppressNotificationRecipient(gomock.Any(), "MyProvider1", "01K", repository.SuppressionComplaint, "").Return(false, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   ReceiptResponse{Applied: 2},
+		},
+		{
+			name:               "rejects an unknown bounce type",
+			provider:           "MyProvider1",
+			body:               `{"receipts":[{"notification_id":"01J","event":"bounced","bounce_type":"permanent"}]}`,
+			timestamp:          timestamp,
+			signature:          sign("whsec", timestamp, `{"receipts":[{"notification_id":"01J","event":"bounced","bounce_type":"permanent"}]}`),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown provider",
+			provider:           "Unknown",
+			body:               body,
+			timestamp:          timestamp,
+			signature:          sign("whsec", timestamp, body),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "rejects a signature of another key",
+			provider:           "MyProvider1",
+			body:               body,
+			timestamp:          timestamp,
+			signature:          sign("other", timestamp, body),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "rejects a tampered body",
+			provider:           "MyProvider1",
+			body:               strings.Replace(body, "bounced", "opened", 1),
+			timestamp:          timestamp,
+			signature:          sign("whsec", timestamp, body),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "rejects a replayed request",
+			provider:           "MyProvider1",
+			body:               body,
+			timestamp:          strconv.FormatInt(now.Add(-time.Hour).Unix(), 10),
+			signature:          sign("whsec", strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), body),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "rejects a missing timestamp",
+			provider:           "MyProvider1",
+			body:               body,
+			signature:          sign("whsec", "", body),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "rejects an unknown event before applying any",
+			provider:           "MyProvider1",
+			body:               `{"receipts":[{"notification_id":"01J","event":"delivered"},{"notification_id":"01K","event":"clicked"}]}`,
+			timestamp:          timestamp,
+			signature:          sign("whsec", timestamp, `{"receipts":[{"notification_id":"01J","event":"delivered"},{"notification_id":"01K","event":"clicked"}]}`),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects an empty request",
+			provider:           "MyProvider1",
+			body:               `{"receipts":[]}`,
+			timestamp:          timestamp,
+			signature:          sign("whsec", timestamp, `{"receipts":[]}`),
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects a body above the size limit",
+			provider:           "MyProvider1",
+			body:               `{"receipts":[` + strings.Repeat(" ", 2048) + `]}`,
+			timestamp:          timestamp,
+			setupMocks:         func(*mockrepository.MockNotificationLogProvider, *mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:      "fails on database error",
+			provider:  "MyProvider1",
+			body:      body,
+			timestamp: timestamp,
+			signature: sign("whsec", timestamp, body),
+			setupMocks: func(notificationLog *mockrepository.MockNotificationLogProvider, _ *mockrepository.MockSuppressionProvider) {
+				notificationLog.EXPECT().UpdateNotificationStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+			suppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			tt.setupMocks(notificationLog, suppressions)
+
+			secrets := mocksecret.NewMockProvider(ctrl)
+			secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/receipts#myprovider1").Return("whsec", nil).AnyTimes()
+
+			mockClock := mockclock.NewMockClock(ctrl)
+			mockClock.EXPECT().Since(gomock.Any()).DoAndReturn(func(t time.Time) time.Duration {
+				return now.Sub(t)
+			}).AnyTimes()
+
+			metricsCollector, err := metrics.NewReceiptCollector(nil)
+			require.NoError(t, err)
+
+			receipts := NewReceiptsHandler(ReceiptsParams{
+				Config: ReceiptConfig{
+					SigningKeys:        map[string]string{"MyProvider1": "vault://secret/data/receipts#myprovider1"},
+					SignatureTolerance: 5 * time.Minute,
+					MaxBodySize:        1024,
+				},
+				Secrets:          secrets,
+				NotificationLog:  notificationLog,
+				Suppressions:     suppressions,
+				MetricsCollector: metricsCollector,
+				Clock:            mockClock,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/providers/:provider/receipts", receipts.ReceiptHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/providers/"+tt.provider+"/receipts", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(HeaderReceiptTimestamp, tt.timestamp)
+			req.Header.Set(HeaderReceiptSignature, tt.signature)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response ReceiptResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestReceipts_VerifySignature_ToleranceDisabled(t *testing.T) {
+	receipts := NewReceiptsHandler(ReceiptsParams{})
+	body := []byte(`{"receipts":[]}`)
+
+	err := receipts.verifySignature("whsec", "0", hex.EncodeToString(signReceipt("whsec", "0", body)), body)
+
+	assert.NoError(t, err)
+}
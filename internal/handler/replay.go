@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+)
+
+// ReplayRequest optionally overrides the recipient of a replayed
+// notification
+type ReplayRequest struct {
+	To string `json:"to"`
+}
+
+// ReplayHandler sends a notification kept by an earlier notify request again
+// as a new notification, to its recipient or the one in the body, and
+// answers it as NotifyHandler does. The body is optional
+func (n *Notification) ReplayHandler(c *gin.Context) {
+	// A chunked or decompressed body has no length, so the body is read to
+	// tell whether it is empty
+	var req ReplayRequest
+	if err := n.bindRequest(c, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeDeliveryHeaders(c, service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry})
+		c.JSON(bindErrorStatus(err), GetRequestError(err))
+		return
+	}
+
+	report, err := n.services.Replay(c.Request.Context(), c.Param("id"), service.ReplayOptions{
+		To:      req.To,
+		Sandbox: c.GetBool(contextKeySandbox),
+	})
+	respondDelivery(c, report, err)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotification_ReplayHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// chunked sends the body without a length
+		chunked            bool
+		setupMocks         func(*mockservice.MockNotificationProvider)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "replays to the original recipient without a body",
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{}).
+					Return(service.DeliveryReport{ID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Attempts: 1, RetryDisposition: service.RetryNotNeeded}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"notification sent","notification_id":"01JB8Z5XK3M4N5P6Q7R8S9T0VW","attempts":1}`,
+		},
+		{
+			name:    "replays to the original recipient with an empty chunked body",
+			chunked: true,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{}).
+					Return(service.DeliveryReport{ID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Attempts: 1, RetryDisposition: service.RetryNotNeeded}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"notification sent","notification_id":"01JB8Z5XK3M4N5P6Q7R8S9T0VW","attempts":1}`,
+		},
+		{
+			name:    "replays to an overridden recipient from a chunked body",
+			body:    `{"to":"other@example.com"}`,
+			chunked: true,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{To: "other@example.com"}).
+					Return(service.DeliveryReport{ID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Attempts: 1, RetryDisposition: service.RetryNotNeeded}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"notification sent","notification_id":"01JB8Z5XK3M4N5P6Q7R8S9T0VW","attempts":1}`,
+		},
+		{
+			name: "replays to an overridden recipient",
+			body: `{"to":"other@example.com"}`,
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{To: "other@example.com"}).
+					Return(service.DeliveryReport{ID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Attempts: 1, RetryDisposition: service.RetryNotNeeded}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"message":"notification sent","notification_id":"01JB8Z5XK3M4N5P6Q7R8S9T0VW","attempts":1}`,
+		},
+		{
+			name:               "rejects an invalid body",
+			body:               `{"to":`,
+			setupMocks:         func(*mockservice.MockNotificationProvider) {},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "answers an unknown notification with not found",
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{}).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.UnknownNotificationError{NotificationID: "01JB8Z4AAAAAAAAAAAAAAAAAAA"})
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"error_code":"E101","message":"notification '01JB8Z4AAAAAAAAAAAAAAAAAAA' not found"}`,
+		},
+		{
+			name: "refuses a notification that cannot be replayed",
+			setupMocks: func(services *mockservice.MockNotificationProvider) {
+				services.EXPECT().Replay(gomock.Any(), "01JB8Z4AAAAAAAAAAAAAAAAAAA", service.ReplayOptions{}).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.UnreplayableError{NotificationID: "01JB8Z4AAAAAAAAAAAAAAAAAAA", Reason: "the content of its inline attachments was not kept"})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       `{"error_code":"E101","message":"notification '01JB8Z4AAAAAAAAAAAAAAAAAAA' cannot be replayed: the content of its inline attachments was not kept"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(mockService)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/notifications/:id/replay", handler.ReplayHandler)
+
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.chunked {
+				// Readers of unknown length are sent chunked
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/notifications/01JB8Z4AAAAAAAAAAAAAAAAAAA/replay", body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
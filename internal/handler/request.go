@@ -1,7 +1,161 @@
 package handler
 
+import (
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+)
+
+// notifyContract is a version of the notify request body; each version
+// translates itself onto the service model, so all share the delivery code
+type notifyContract interface {
+	Notification() service.Notification
+}
+
 type NotifyRequest struct {
-	To      string `json:"to" binding:"required"`
+	// MessageID deduplicates the request: a repeated id is not delivered
+	// again but answered with the result of the first request
+	MessageID string `json:"message_id" binding:"omitempty,max=255"`
+	To        string `json:"to" binding:"required"`
+	Title     string `json:"title" binding:"required_without_all=TitleKey Template"`
+	Message   string `json:"message" binding:"required_without_all=MessageKey Template"`
+	// ThreadKey groups related notifications, e.g. "order-42"
+	ThreadKey string `json:"thread_key" binding:"omitempty,max=255"`
+	// Priority orders the notification while waiting for a delivery slot
+	Priority string `json:"priority" binding:"omitempty,oneof=high normal low"`
+	// Category selects the consents that apply; marketing needs an opt-in
+	Category string `json:"category" binding:"omitempty,oneof=transactional marketing reminder"`
+	// Locale selects the translation of TitleKey and MessageKey, e.g. "th-TH"
+	Locale     string            `json:"locale" binding:"omitempty,bcp47_language_tag"`
+	TitleKey   string            `json:"title_key" binding:"omitempty,max=255"`
+	MessageKey string            `json:"message_key" binding:"omitempty,max=255"`
+	Params     map[string]string `json:"params"`
+	// Template names an admin template whose active version gives the
+	// title and message, rendered with Params
+	Template string `json:"template" binding:"omitempty,max=128"`
+	// Rich content is delivered only to providers able to render it
+	HTML        string              `json:"html"`
+	Attachments []AttachmentRequest `json:"attachments" binding:"omitempty,max=10,dive"`
+	DeepLink    string              `json:"deep_link" binding:"omitempty,uri"`
+	ImageURL    string              `json:"image_url" binding:"omitempty,url"`
+	// Metadata correlates the notification with business entities, e.g.
+	// order_id or campaign_id
+	Metadata map[string]string `json:"metadata" binding:"omitempty,max=20,dive,keys,min=1,max=64,endkeys,max=256"`
+}
+
+// AttachmentRequest carries either a URL or base64 content, never both
+type AttachmentRequest struct {
+	Filename    string `json:"filename" binding:"required,max=255"`
+	ContentType string `json:"content_type" binding:"omitempty,max=255"`
+	URL         string `json:"url" binding:"required_without=Content,excluded_with=Content,omitempty,url"`
+	Content     string `json:"content" binding:"omitempty,base64"`
+}
+
+// Notification maps the validated request onto the service model
+func (r NotifyRequest) Notification() service.Notification {
+	notification := service.Notification{
+		MessageID:  r.MessageID,
+		To:         r.To,
+		Title:      r.Title,
+		Message:    r.Message,
+		ThreadKey:  r.ThreadKey,
+		Priority:   dispatch.Priority(r.Priority),
+		Category:   r.Category,
+		Locale:     r.Locale,
+		Template:   r.Template,
+		TitleKey:   r.TitleKey,
+		MessageKey: r.MessageKey,
+		Params:     r.Params,
+		HTML:       r.HTML,
+		DeepLink:   r.DeepLink,
+		ImageURL:   r.ImageURL,
+		Metadata:   r.Metadata,
+	}
+	for _, attachment := range r.Attachments {
+		notification.Attachments = append(notification.Attachments, service.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			URL:         attachment.URL,
+			Content:     attachment.Content,
+		})
+	}
+	return notification
+}
+
+// NotifyRequestV2 is the v2.0 notify contract: the content is given either
+// literally or as translation keys, and the caller may pick among the
+// routed channels
+type NotifyRequestV2 struct {
+	MessageID string `json:"message_id" binding:"omitempty,max=255"`
+	To        string `json:"to" binding:"required"`
+	// Channels picks among the channels routed to the recipient type;
+	// empty delivers on all of them
+	Channels  []string `json:"channels" binding:"omitempty,max=3,unique,dive,oneof=Email PushNotification InApp"`
+	ThreadKey string   `json:"thread_key" binding:"omitempty,max=255"`
+	Priority  string   `json:"priority" binding:"omitempty,oneof=high normal low"`
+	Category  string   `json:"category" binding:"omitempty,oneof=transactional marketing reminder"`
+	// Exactly one of Content and Template gives the title and message
+	Content  *ContentRequest   `json:"content" binding:"required_without=Template,excluded_with=Template"`
+	Template *TemplateRequest  `json:"template" binding:"required_without=Content"`
+	Rich     *RichRequest      `json:"rich"`
+	Metadata map[string]string `json:"metadata" binding:"omitempty,max=20,dive,keys,min=1,max=64,endkeys,max=256"`
+}
+
+// ContentRequest is the literal title and message of a notification
+type ContentRequest struct {
 	Title   string `json:"title" binding:"required"`
 	Message string `json:"message" binding:"required"`
 }
+
+// TemplateRequest names either an admin template or the translations
+// rendering the title and message
+type TemplateRequest struct {
+	// Name is an admin template whose active version gives the title and
+	// message instead of TitleKey and MessageKey
+	Name       string            `json:"name" binding:"omitempty,max=128"`
+	TitleKey   string            `json:"title_key" binding:"required_without=Name,excluded_with=Name,max=255"`
+	MessageKey string            `json:"message_key" binding:"required_without=Name,excluded_with=Name,max=255"`
+	Locale     string            `json:"locale" binding:"omitempty,bcp47_language_tag"`
+	Params     map[string]string `json:"params"`
+}
+
+// RichRequest is content delivered only to providers able to render it
+type RichRequest struct {
+	HTML        string              `json:"html"`
+	Attachments []AttachmentRequest `json:"attachments" binding:"omitempty,max=10,dive"`
+	DeepLink    string              `json:"deep_link" binding:"omitempty,uri"`
+	ImageURL    string              `json:"image_url" binding:"omitempty,url"`
+}
+
+// Notification translates the v2.0 request into the v1.0 one, so both
+// versions share its mapping onto the service model
+func (r NotifyRequestV2) Notification() service.Notification {
+	v1 := NotifyRequest{
+		MessageID: r.MessageID,
+		To:        r.To,
+		ThreadKey: r.ThreadKey,
+		Priority:  r.Priority,
+		Category:  r.Category,
+		Metadata:  r.Metadata,
+	}
+	if r.Content != nil {
+		v1.Title = r.Content.Title
+		v1.Message = r.Content.Message
+	}
+	if r.Template != nil {
+		v1.Template = r.Template.Name
+		v1.TitleKey = r.Template.TitleKey
+		v1.MessageKey = r.Template.MessageKey
+		v1.Locale = r.Template.Locale
+		v1.Params = r.Template.Params
+	}
+	if r.Rich != nil {
+		v1.HTML = r.Rich.HTML
+		v1.Attachments = r.Rich.Attachments
+		v1.DeepLink = r.Rich.DeepLink
+		v1.ImageURL = r.Rich.ImageURL
+	}
+
+	notification := v1.Notification()
+	notification.Channels = r.Channels
+	return notification
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSuppressionLimit = 50
+	maxSuppressionLimit     = 500
+)
+
+var (
+	errInvalidSuppressionLimit  = errors.New("limit must be between 1 and 500")
+	errInvalidSuppressionOffset = errors.New("offset must be zero or more")
+	errUnknownSuppression       = errors.New("address is not suppressed")
+)
+
+type SuppressionsResponse struct {
+	Suppressions []repository.Suppression `json:"suppressions"`
+	Total        int64                    `json:"total"`
+	Limit        int                      `json:"limit"`
+	Offset       int                      `json:"offset"`
+}
+
+// SuppressionsHandler lists the suppressed email addresses newest first,
+// filtered by the address and reason query parameters and paged by limit
+// and offset
+func (a *Admin) SuppressionsHandler(c *gin.Context) {
+	filter := repository.SuppressionFilter{
+		Address: c.Query("address"),
+		Reason:  c.Query("reason"),
+		Limit:   defaultSuppressionLimit,
+	}
+
+	if _, err := repository.ParseSuppressionReason(filter.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxSuppressionLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidSuppressionLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidSuppressionOffset))
+			return
+		}
+	}
+
+	page, err := a.suppressions.ListSuppressions(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := SuppressionsResponse{
+		Suppressions: page.Suppressions,
+		Total:        page.Total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}
+	if response.Suppressions == nil {
+		response.Suppressions = []repository.Suppression{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveSuppressionHandler lets email reach an address again, e.g. once the
+// recipient fixed their mailbox or the complaint was a mistake. Other
+// instances send to it once their cached entry expires
+func (a *Admin) RemoveSuppressionHandler(c *gin.Context) {
+	address := repository.NormalizeAddress(c.Param("address"))
+
+	removed, err := a.suppressions.DeleteSuppression(c.Request.Context(), address)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, GetRequestError(errUnknownSuppression))
+			return
+		}
+		respondInternalError(c, err)
+		return
+	}
+
+	a.suppressionCache.Delete(address)
+
+	a.recordAudit(c, AuditActionSuppressionRemove, "suppression:"+address, removed, nil)
+	c.JSON(http.StatusOK, removed)
+}
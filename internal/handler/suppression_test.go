@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestAdmin_SuppressionsHandler(t *testing.T) {
+	suppression := repository.Suppression{
+		Address:        "user@example.com",
+		Reason:         repository.SuppressionHardBounce,
+		Detail:         "mailbox does not exist",
+		ProviderName:   "MyProvider1",
+		NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		CreatedAt:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockSuppressionProvider)
+		expectedStatusCode int
+		expectedResponse   SuppressionsResponse
+	}{
+		{
+			name:  "lists with default paging",
+			query: "",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider) {
+				suppressions.EXPECT().ListSuppressions(gomock.Any(), repository.SuppressionFilter{Limit: 50}).
+					Return(repository.SuppressionPage{Suppressions: []repository.Suppression{suppression}, Total: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: SuppressionsResponse{
+				Suppressions: []repository.Suppression{suppression},
+				Total:        1,
+				Limit:        50,
+			},
+		},
+		{
+			name:  "passes filters and paging",
+			query: "?address=user@example.com&reason=complaint&limit=10&offset=20",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider) {
+				suppressions.EXPECT().ListSuppressions(gomock.Any(), repository.SuppressionFilter{
+					Address: "user@example.com",
+					Reason:  repository.SuppressionComplaint,
+					Limit:   10,
+					Offset:  20,
+				}).Return(repository.SuppressionPage{Total: 21}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: SuppressionsResponse{
+				Suppressions: []repository.Suppression{},
+				Total:        21,
+				Limit:        10,
+				Offset:       20,
+			},
+		},
+		{
+			name:               "rejects unknown reason",
+			query:              "?reason=soft_bounce",
+			setupMocks:         func(*mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=501",
+			setupMocks:         func(*mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects negative offset",
+			query:              "?offset=-1",
+			setupMocks:         func(*mockrepository.MockSuppressionProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider) {
+				suppressions.EXPECT().ListSuppressions(gomock.Any(), gomock.Any()).Return(repository.SuppressionPage{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			suppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			tt.setupMocks(suppressions)
+
+			admin := NewAdminHandler(AdminParams{
+				Suppressions: suppressions,
+				Logger:       zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/suppressions", admin.SuppressionsHandler)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/suppressions"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response SuppressionsResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestAdmin_RemoveSuppressionHandler(t *testing.T) {
+	suppression := repository.Suppression{
+		Address:        "user@example.com",
+		Reason:         repository.SuppressionComplaint,
+		ProviderName:   "MyProvider1",
+		NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		CreatedAt:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name               string
+		path               string
+		setupMocks         func(*mockrepository.MockSuppressionProvider, *mockrepository.MockSuppressionCacheProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+	}{
+		{
+			name: "removes a suppression",
+			path: "/admin/suppressions/User@Example.com",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider, suppressionCache *mockrepository.MockSuppressionCacheProvider, audit *mockrepository.MockAuditProvider) {
+				suppressions.EXPECT().DeleteSuppression(gomock.Any(), "user@example.com").Return(suppression, nil)
+				suppressionCache.EXPECT().Delete("user@example.com")
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionSuppressionRemove, entry.Action)
+						assert.Equal(t, "suppression:user@example.com", entry.Target)
+						assert.Contains(t, string(entry.Before), `"reason":"complaint"`)
+						assert.Nil(t, entry.After)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "reports an address that is not suppressed",
+			path: "/admin/suppressions/other@example.com",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider, _ *mockrepository.MockSuppressionCacheProvider, _ *mockrepository.MockAuditProvider) {
+				suppressions.EXPECT().DeleteSuppression(gomock.Any(), "other@example.com").Return(repository.Suppression{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "fails on database error",
+			path: "/admin/suppressions/user@example.com",
+			setupMocks: func(suppressions *mockrepository.MockSuppressionProvider, _ *mockrepository.MockSuppressionCacheProvider, _ *mockrepository.MockAuditProvider) {
+				suppressions.EXPECT().DeleteSuppression(gomock.Any(), "user@example.com").Return(repository.Suppression{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			suppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			suppressionCache := mockrepository.NewMockSuppressionCacheProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(suppressions, suppressionCache, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Suppressions:     suppressions,
+				SuppressionCache: suppressionCache,
+				Audit:            audit,
+				Logger:           zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.DELETE("/admin/suppressions/:address", admin.RemoveSuppressionHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response repository.Suppression
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, suppression, response)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTemplateLimit = 50
+	maxTemplateLimit     = 500
+)
+
+// templateNamePattern keeps names usable as a path segment, e.g.
+// order_shipped or billing.invoice-due
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,127}$`)
+
+var (
+	errInvalidTemplateLimit   = errors.New("limit must be between 1 and 500")
+	errInvalidTemplateOffset  = errors.New("offset must be zero or more")
+	errInvalidTemplateName    = errors.New("name must be 1 to 128 lowercase letters, digits, '_', '.' or '-'")
+	errInvalidTemplateVersion = errors.New("version must be a positive number")
+	errUnknownTemplate        = errors.New("template not found")
+)
+
+type TemplatesResponse struct {
+	Templates []repository.Template `json:"templates"`
+	Total     int64                 `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
+}
+
+// TemplateResponse is a template with its history, newest version first
+type TemplateResponse struct {
+	repository.Template
+	Versions []repository.TemplateVersion `json:"versions"`
+}
+
+// CreateTemplateRequest is a new template and the content of its version 1
+type CreateTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description" binding:"max=1024"`
+	Title       string `json:"title" binding:"required"`
+	Message     string `json:"message" binding:"required"`
+}
+
+// UpdateTemplateRequest is the content of the next version of a template
+type UpdateTemplateRequest struct {
+	Title   string `json:"title" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+// PreviewTemplateRequest renders a version, the active one when zero, with
+// sample params
+type PreviewTemplateRequest struct {
+	Version int               `json:"version" binding:"min=0"`
+	Params  map[string]string `json:"params"`
+}
+
+type TemplatePreview struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// TemplatesHandler lists the templates by name, paged by limit and offset
+func (a *Admin) TemplatesHandler(c *gin.Context) {
+	filter := repository.TemplateFilter{Limit: defaultTemplateLimit}
+
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxTemplateLimit {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidTemplateLimit))
+			return
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			c.JSON(http.StatusBadRequest, GetRequestError(errInvalidTemplateOffset))
+			return
+		}
+	}
+
+	page, err := a.templates.ListTemplates(c.Request.Context(), filter)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	response := TemplatesResponse{
+		Templates: page.Templates,
+		Total:     page.Total,
+		Limit:     filter.Limit,
+		Offset:    filter.Offset,
+	}
+	if response.Templates == nil {
+		response.Templates = []repository.Template{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// TemplateHandler returns a template with every version it has had
+func (a *Admin) TemplateHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	template, err := a.templates.FindTemplate(c.Request.Context(), name)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	versions, err := a.templates.ListTemplateVersions(c.Request.Context(), name)
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TemplateResponse{Template: template, Versions: versions})
+}
+
+// CreateTemplateHandler adds a template whose content is its active
+// version 1
+func (a *Admin) CreateTemplateHandler(c *gin.Context) {
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+	if !templateNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, GetRequestError(errInvalidTemplateName))
+		return
+	}
+	if err := parseTemplateContent(req.Name, req.Title, req.Message); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	template, content, err := a.templates.CreateTemplate(c.Request.Context(),
+		repository.Template{Name: req.Name, Description: req.Description},
+		repository.TemplateVersion{Title: req.Title, Message: req.Message, CreatedBy: auditActor(c)},
+	)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	response := TemplateResponse{Template: template, Versions: []repository.TemplateVersion{content}}
+
+	a.recordAudit(c, AuditActionTemplateCreate, "template:"+template.Name, nil, response)
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateTemplateHandler stores the content as a new version; the active
+// version is unchanged until the new one is activated
+func (a *Admin) UpdateTemplateHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var req UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+	if err := parseTemplateContent(name, req.Title, req.Message); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	previous, content, err := a.templates.AddTemplateVersion(c.Request.Context(), repository.TemplateVersion{
+		TemplateName: name,
+		Title:        req.Title,
+		Message:      req.Message,
+		CreatedBy:    auditActor(c),
+	})
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	a.recordAudit(c, AuditActionTemplateUpdate, "template:"+name, previous, content)
+	c.JSON(http.StatusCreated, content)
+}
+
+// ActivateTemplateHandler puts a version of the template in use, either a
+// new one or an earlier one to roll back
+func (a *Admin) ActivateTemplateHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, GetRequestError(errInvalidTemplateVersion))
+		return
+	}
+
+	previous, err := a.templates.ActivateTemplateVersion(c.Request.Context(), name, version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	activated := previous
+	activated.ActiveVersion = version
+
+	a.recordAudit(c, AuditActionTemplateActivate, "template:"+name, previous, activated)
+	c.JSON(http.StatusOK, activated)
+}
+
+// PreviewTemplateHandler renders a version with sample params, so content
+// can be checked before it is activated
+func (a *Admin) PreviewTemplateHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var req PreviewTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GetRequestError(err))
+		return
+	}
+
+	if req.Version == 0 {
+		template, err := a.templates.FindTemplate(c.Request.Context(), name)
+		if err != nil {
+			respondTemplateError(c, err)
+			return
+		}
+		req.Version = template.ActiveVersion
+	}
+
+	content, err := a.templates.FindTemplateVersion(c.Request.Context(), name, req.Version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	preview := TemplatePreview{Name: name, Version: content.Version}
+	if preview.Title, err = service.RenderTemplate(name+".title", content.Title, req.Params); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+	if preview.Message, err = service.RenderTemplate(name+".message", content.Message, req.Params); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, GetRequestError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// parseTemplateContent refuses content that would only fail once rendered
+func parseTemplateContent(name string, title string, message string) error {
+	if _, err := service.ParseTemplate(name+".title", title); err != nil {
+		return err
+	}
+	if _, err := service.ParseTemplate(name+".message", message); err != nil {
+		return err
+	}
+	return nil
+}
+
+func respondTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, GetRequestError(errUnknownTemplate))
+	case errors.Is(err, repository.ErrUnknownTemplateVersion):
+		c.JSON(http.StatusNotFound, GetRequestError(err))
+	case errors.Is(err, repository.ErrTemplateExists):
+		c.JSON(http.StatusConflict, GetRequestError(err))
+	default:
+		respondInternalError(c, err)
+	}
+}
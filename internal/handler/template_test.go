@@ -0,0 +1,454 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	testTemplate = repository.Template{
+		Name:          "order_shipped",
+		Description:   "Sent when an order leaves the warehouse",
+		ActiveVersion: 1,
+		LatestVersion: 2,
+		CreatedAt:     time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:     time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC),
+	}
+	testTemplateVersion = repository.TemplateVersion{
+		TemplateName: "order_shipped",
+		Version:      2,
+		Title:        "Order {{.order_id}} has shipped",
+		Message:      "Your order {{.order_id}} is on its way.",
+		CreatedBy:    "alice",
+		CreatedAt:    time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC),
+	}
+)
+
+func TestAdmin_TemplatesHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		setupMocks         func(*mockrepository.MockTemplateProvider)
+		expectedStatusCode int
+		expectedResponse   TemplatesResponse
+	}{
+		{
+			name:  "lists with default paging",
+			query: "",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().ListTemplates(gomock.Any(), repository.TemplateFilter{Limit: 50}).
+					Return(repository.TemplatePage{Templates: []repository.Template{testTemplate}, Total: 1}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TemplatesResponse{
+				Templates: []repository.Template{testTemplate},
+				Total:     1,
+				Limit:     50,
+			},
+		},
+		{
+			name:  "passes paging",
+			query: "?limit=10&offset=20",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().ListTemplates(gomock.Any(), repository.TemplateFilter{Limit: 10, Offset: 20}).
+					Return(repository.TemplatePage{Total: 21}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TemplatesResponse{
+				Templates: []repository.Template{},
+				Total:     21,
+				Limit:     10,
+				Offset:    20,
+			},
+		},
+		{
+			name:               "rejects limit above maximum",
+			query:              "?limit=501",
+			setupMocks:         func(*mockrepository.MockTemplateProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "fails on database error",
+			query: "",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().ListTemplates(gomock.Any(), gomock.Any()).Return(repository.TemplatePage{}, errors.New("database down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			tt.setupMocks(templates)
+
+			admin := NewAdminHandler(AdminParams{
+				Templates: templates,
+				Logger:    zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/templates", admin.TemplatesHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/templates"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response TemplatesResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestAdmin_CreateTemplateHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMocks         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+	}{
+		{
+			name: "creates version 1",
+			body: `{"name":"order_shipped","title":"Order {{.order_id}} has shipped","message":"On its way."}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, audit *mockrepository.MockAuditProvider) {
+				templates.EXPECT().CreateTemplate(gomock.Any(),
+					repository.Template{Name: "order_shipped"},
+					repository.TemplateVersion{Title: "Order {{.order_id}} has shipped", Message: "On its way.", CreatedBy: "key-10a4c7c9"},
+				).Return(
+					repository.Template{Name: "order_shipped", ActiveVersion: 1, LatestVersion: 1},
+					repository.TemplateVersion{TemplateName: "order_shipped", Version: 1, Title: "Order {{.order_id}} has shipped", Message: "On its way.", CreatedBy: "alice"},
+					nil,
+				)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionTemplateCreate, entry.Action)
+						assert.Equal(t, "template:order_shipped", entry.Target)
+						assert.Equal(t, "key-10a4c7c9", entry.Actor)
+						assert.Nil(t, entry.Before)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "rejects invalid name",
+			body:               `{"name":"Order Shipped","title":"Shipped","message":"On its way."}`,
+			setupMocks:         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects invalid template syntax",
+			body:               `{"name":"order_shipped","title":"Order {{.order_id","message":"On its way."}`,
+			setupMocks:         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "rejects missing message",
+			body:               `{"name":"order_shipped","title":"Shipped"}`,
+			setupMocks:         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "conflicts on existing name",
+			body: `{"name":"order_shipped","title":"Shipped","message":"On its way."}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, _ *mockrepository.MockAuditProvider) {
+				templates.EXPECT().CreateTemplate(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(repository.Template{}, repository.TemplateVersion{}, repository.ErrTemplateExists)
+			},
+			expectedStatusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(templates, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Templates: templates,
+				Audit:     audit,
+				Logger:    zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(contextKeyKeyID, "key-10a4c7c9") })
+			router.POST("/admin/templates", admin.CreateTemplateHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/templates", strings.NewReader(tt.body))
+			req.Header.Set(AuditActorHeader, "alice")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestAdmin_UpdateTemplateHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMocks         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+		expectedResponse   repository.TemplateVersion
+	}{
+		{
+			name: "adds the next version",
+			body: `{"title":"Order {{.order_id}} has shipped","message":"Your order {{.order_id}} is on its way."}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, audit *mockrepository.MockAuditProvider) {
+				templates.EXPECT().AddTemplateVersion(gomock.Any(), repository.TemplateVersion{
+					TemplateName: "order_shipped",
+					Title:        "Order {{.order_id}} has shipped",
+					Message:      "Your order {{.order_id}} is on its way.",
+					CreatedBy:    "key-10a4c7c9",
+				}).Return(repository.TemplateVersion{TemplateName: "order_shipped", Version: 1, Title: "Shipped"}, testTemplateVersion, nil)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionTemplateUpdate, entry.Action)
+						assert.Equal(t, "template:order_shipped", entry.Target)
+						assert.Contains(t, string(entry.Before), `"version":1,"title":"Shipped"`)
+						assert.Contains(t, string(entry.After), `"version":2`)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   testTemplateVersion,
+		},
+		{
+			name:               "rejects invalid template syntax",
+			body:               `{"title":"Shipped","message":"{{if}}"}`,
+			setupMocks:         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "fails on unknown template",
+			body: `{"title":"Shipped","message":"On its way."}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, _ *mockrepository.MockAuditProvider) {
+				templates.EXPECT().AddTemplateVersion(gomock.Any(), gomock.Any()).Return(repository.TemplateVersion{}, repository.TemplateVersion{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(templates, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Templates: templates,
+				Audit:     audit,
+				Logger:    zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(contextKeyKeyID, "key-10a4c7c9") })
+			router.PUT("/admin/templates/:name", admin.UpdateTemplateHandler)
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/templates/order_shipped", strings.NewReader(tt.body))
+			req.Header.Set(AuditActorHeader, "alice")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusCreated {
+				return
+			}
+
+			var response repository.TemplateVersion
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
+
+func TestAdmin_ActivateTemplateHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		version            string
+		setupMocks         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider)
+		expectedStatusCode int
+		expectedActive     int
+	}{
+		{
+			name:    "activates the version",
+			version: "2",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, audit *mockrepository.MockAuditProvider) {
+				templates.EXPECT().ActivateTemplateVersion(gomock.Any(), "order_shipped", 2).Return(testTemplate, nil)
+				audit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entry repository.AuditEntry) error {
+						assert.Equal(t, AuditActionTemplateActivate, entry.Action)
+						assert.Contains(t, string(entry.Before), `"active_version":1`)
+						assert.Contains(t, string(entry.After), `"active_version":2`)
+						return nil
+					})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedActive:     2,
+		},
+		{
+			name:               "rejects invalid version",
+			version:            "0",
+			setupMocks:         func(*mockrepository.MockTemplateProvider, *mockrepository.MockAuditProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "fails on unknown version",
+			version: "3",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider, _ *mockrepository.MockAuditProvider) {
+				templates.EXPECT().ActivateTemplateVersion(gomock.Any(), "order_shipped", 3).Return(repository.Template{}, repository.ErrUnknownTemplateVersion)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			audit := mockrepository.NewMockAuditProvider(ctrl)
+			tt.setupMocks(templates, audit)
+
+			admin := NewAdminHandler(AdminParams{
+				Templates: templates,
+				Audit:     audit,
+				Logger:    zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/templates/:name/versions/:version/activate", admin.ActivateTemplateHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/templates/order_shipped/versions/"+tt.version+"/activate", nil))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response repository.Template
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedActive, response.ActiveVersion)
+		})
+	}
+}
+
+func TestAdmin_PreviewTemplateHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMocks         func(*mockrepository.MockTemplateProvider)
+		expectedStatusCode int
+		expectedResponse   TemplatePreview
+	}{
+		{
+			name: "renders the active version",
+			body: `{"params":{"order_id":"A1"}}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindTemplate(gomock.Any(), "order_shipped").Return(testTemplate, nil)
+				templates.EXPECT().FindTemplateVersion(gomock.Any(), "order_shipped", 1).
+					Return(repository.TemplateVersion{TemplateName: "order_shipped", Version: 1, Title: "Shipped {{.order_id}}", Message: "On its way."}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   TemplatePreview{Name: "order_shipped", Version: 1, Title: "Shipped A1", Message: "On its way."},
+		},
+		{
+			name: "renders the given version",
+			body: `{"version":2,"params":{"order_id":"A1"}}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindTemplateVersion(gomock.Any(), "order_shipped", 2).Return(testTemplateVersion, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TemplatePreview{
+				Name:    "order_shipped",
+				Version: 2,
+				Title:   "Order A1 has shipped",
+				Message: "Your order A1 is on its way.",
+			},
+		},
+		{
+			name: "fails on missing param",
+			body: `{"version":2,"params":{}}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindTemplateVersion(gomock.Any(), "order_shipped", 2).Return(testTemplateVersion, nil)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "fails on unknown template",
+			body: `{}`,
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindTemplate(gomock.Any(), "order_shipped").Return(repository.Template{}, gorm.ErrRecordNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			tt.setupMocks(templates)
+
+			admin := NewAdminHandler(AdminParams{
+				Templates: templates,
+				Logger:    zap.NewNop(),
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/admin/templates/:name/preview", admin.PreviewTemplateHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/templates/order_shipped/preview", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var response TemplatePreview
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedResponse, response)
+		})
+	}
+}
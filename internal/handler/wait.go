@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+)
+
+var (
+	errInvalidWait        = errors.New("wait must be true or false")
+	errInvalidWaitTimeout = errors.New("timeout must be a positive duration, e.g. 5s")
+	errTimeoutWithoutWait = errors.New("timeout requires wait=true")
+)
+
+// waitOption is how a notify request asked to be answered. Without the wait
+// query parameter the notification is sent while the caller waits, as it
+// always was; with it the notification is handed to a job, and the caller
+// waits up to timeout for the job to finish, or not at all when it is zero
+type waitOption struct {
+	async   bool
+	timeout time.Duration
+}
+
+// parseWait reads the wait and timeout query parameters. A timeout above
+// HTTP_NOTIFY_MAX_WAIT_TIMEOUT is shortened to it
+func (n *Notification) parseWait(c *gin.Context) (waitOption, error) {
+	value, ok := c.GetQuery("wait")
+	if !ok {
+		if _, ok := c.GetQuery("timeout"); ok {
+			return waitOption{}, errTimeoutWithoutWait
+		}
+		return waitOption{}, nil
+	}
+
+	wait, err := strconv.ParseBool(value)
+	if err != nil {
+		return waitOption{}, errInvalidWait
+	}
+
+	timeout, ok := c.GetQuery("timeout")
+	if !wait {
+		if ok {
+			return waitOption{}, errTimeoutWithoutWait
+		}
+		return waitOption{async: true}, nil
+	}
+	if !ok {
+		return waitOption{async: true, timeout: min(n.waitTimeout, n.maxWaitTimeout)}, nil
+	}
+
+	duration, err := time.ParseDuration(timeout)
+	if err != nil || duration <= 0 {
+		return waitOption{}, errInvalidWaitTimeout
+	}
+	return waitOption{async: true, timeout: min(duration, n.maxWaitTimeout)}, nil
+}
+
+// enqueue hands the notification to a job of its own, linked in the
+// Location header. A job finishing within the timeout is answered with 200
+// and its item result, as GET /api/v1.0/jobs/:id reports it; otherwise the
+// caller gets 202 and the progress of the job, which keeps delivering
+func (n *Notification) enqueue(c *gin.Context, recipientType string, notification service.Notification, timeout time.Duration) {
+	ctx := c.Request.Context()
+
+	job, err := n.batches.Enqueue(ctx, recipientType, notification)
+	if errors.Is(err, batch.ErrTooManyNotifyJobs) {
+		c.JSON(http.StatusServiceUnavailable, GetInternalError(err))
+		return
+	}
+	if err != nil {
+		respondInternalError(c, err)
+		return
+	}
+	c.Header("Location", "/api/v1.0/jobs/"+job.ID())
+
+	if timeout > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		select {
+		case <-job.Done():
+			detail, err := n.jobs.FindJob(ctx, job.ID(), quota.SubjectFrom(ctx), repository.JobItemFilter{Limit: 1})
+			if err != nil {
+				respondInternalError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, newJobResponse(detail))
+			return
+		case <-waitCtx.Done():
+		}
+	}
+
+	c.JSON(http.StatusAccepted, job.Status())
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/batch"
+	mockbatch "github.com/koungkub/fw-challenge-notification-service/internal/batch/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestNotification_parseWait(t *testing.T) {
+	tests := []struct {
+		query       string
+		expected    waitOption
+		expectedErr error
+	}{
+		{query: "", expected: waitOption{}},
+		{query: "wait=false", expected: waitOption{async: true}},
+		{query: "wait=true", expected: waitOption{async: true, timeout: 10 * time.Second}},
+		{query: "wait=true&timeout=2500ms", expected: waitOption{async: true, timeout: 2500 * time.Millisecond}},
+		{query: "wait=true&timeout=5m", expected: waitOption{async: true, timeout: 30 * time.Second}},
+		{query: "wait=maybe", expectedErr: errInvalidWait},
+		{query: "wait=true&timeout=5", expectedErr: errInvalidWaitTimeout},
+		{query: "wait=true&timeout=-1s", expectedErr: errInvalidWaitTimeout},
+		{query: "wait=false&timeout=5s", expectedErr: errTimeoutWithoutWait},
+		{query: "timeout=5s", expectedErr: errTimeoutWithoutWait},
+	}
+
+	handler := NewNotificationHandler(NotificationParams{
+		Config: HandlerConfig{NotifyWaitTimeout: 10 * time.Second, NotifyMaxWaitTimeout: 30 * time.Second, JSONMaxBodySize: testJSONMaxBodySize},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/notify?"+tt.query, nil)
+
+			option, err := handler.parseWait(c)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, option)
+		})
+	}
+}
+
+func TestNotification_NotifyHandler_Wait(t *testing.T) {
+	const jobID = "01JB8Z5XK3M4N5P6Q7R8S9T0VW"
+	body := `{"to":"buyer@example.com","title":"Order shipped","message":"On its way"}`
+
+	tests := []struct {
+		name  string
+		query string
+		// delay holds the delivery back
+		delay              time.Duration
+		expectedStatusCode int
+		expectedState      string
+	}{
+		{
+			name:               "answers at once without waiting",
+			query:              "wait=false",
+			delay:              50 * time.Millisecond,
+			expectedStatusCode: http.StatusAccepted,
+			expectedState:      repository.JobStateInProgress,
+		},
+		{
+			name:               "answers the delivery when waiting",
+			query:              "wait=true&timeout=1s",
+			expectedStatusCode: http.StatusOK,
+			expectedState:      repository.JobStateDone,
+		},
+		{
+			name:               "answers the progress once the timeout expires",
+			query:              "wait=true&timeout=10ms",
+			delay:              50 * time.Millisecond,
+			expectedStatusCode: http.StatusAccepted,
+			expectedState:      repository.JobStateInProgress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+			mockService.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+				MessageID: "job:" + jobID,
+				To:        "buyer@example.com",
+				Title:     "Order shipped",
+				Message:   "On its way",
+			}).DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+				time.Sleep(tt.delay)
+				return service.DeliveryReport{ID: "notification-1"}, nil
+			})
+
+			idGenerator := mockidgen.NewMockGenerator(ctrl)
+			idGenerator.EXPECT().NewID().Return(jobID, nil)
+
+			store := &testJobItems{}
+			jobs := newTestJobProvider(ctrl, store)
+			jobs.EXPECT().FindJob(gomock.Any(), jobID, "", repository.JobItemFilter{Limit: 1}).
+				DoAndReturn(func(context.Context, string, string, repository.JobItemFilter) (repository.JobDetail, error) {
+					counts, _ := store.counts()
+					store.mu.Lock()
+					defer store.mu.Unlock()
+					return repository.JobDetail{
+						Job:    repository.Job{ID: jobID, Kind: repository.JobKindNotify, State: repository.CompletedJobState(counts)},
+						Counts: counts,
+						Items:  append([]repository.JobItem{}, store.items...),
+					}, nil
+				}).MaxTimes(1)
+
+			processor, err := batch.NewBatchProcessor(batch.BatchProcessorParams{
+				Lifecycle:   fxtest.NewLifecycle(t),
+				Config:      testBatchConfig,
+				Service:     mockService,
+				Jobs:        jobs,
+				IDGenerator: idGenerator,
+				Clock:       clock.NewRealClock(),
+				Logger:      zap.NewNop(),
+			})
+			require.NoError(t, err)
+
+			handler := NewNotificationHandler(NotificationParams{
+				Config:   HandlerConfig{NotifyWaitTimeout: time.Second, NotifyMaxWaitTimeout: time.Second, JSONMaxBodySize: testJSONMaxBodySize},
+				Services: mockService,
+				Batches:  processor,
+				Jobs:     jobs,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/recipient/:recipient/notify", handler.NotifyHandler)
+
+			req := httptest.NewRequest(http.MethodPost, "/recipient/buyer/notify?"+tt.query, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code, w.Body.String())
+			assert.Equal(t, "/api/v1.0/jobs/"+jobID, w.Header().Get("Location"))
+
+			var response struct {
+				ID    string `json:"id"`
+				State string `json:"state"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, jobID, response.ID)
+			assert.Equal(t, tt.expectedState, response.State)
+
+			// The job keeps delivering after the response
+			require.Eventually(t, func() bool {
+				_, finished := store.counts()
+				return finished
+			}, time.Second, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestNotification_NotifyHandler_InvalidWait(t *testing.T) {
+	handler := NewNotificationHandler(NotificationParams{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/recipient/:recipient/notify", handler.NotifyHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/recipient/buyer/notify?wait=soon", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), errInvalidWait.Error())
+}
+
+func TestNotification_NotifyHandler_TooManyNotifyJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batches := mockbatch.NewMockProcessor(ctrl)
+	batches.EXPECT().Enqueue(gomock.Any(), "buyer", gomock.Any()).Return(nil, batch.ErrTooManyNotifyJobs)
+
+	handler := NewNotificationHandler(NotificationParams{
+		Config:  HandlerConfig{NotifyWaitTimeout: time.Second, NotifyMaxWaitTimeout: time.Second, JSONMaxBodySize: testJSONMaxBodySize},
+		Batches: batches,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/recipient/:recipient/notify", handler.NotifyHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/recipient/buyer/notify?wait=false", strings.NewReader(`{"to":"buyer@example.com","title":"Order shipped","message":"On its way"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), batch.ErrTooManyNotifyJobs.Error())
+}
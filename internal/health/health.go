@@ -0,0 +1,327 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var Module = fx.Module("health",
+	fx.Provide(
+		fx.Annotate(
+			NewProber,
+			fx.As(new(Checker)),
+		),
+	),
+)
+
+// probedProviders are the provider types whose preferences are probed
+var probedProviders = []repository.NotificationProvider{
+	repository.EmailProvider,
+	repository.PushNotificationProvider,
+}
+
+// Checker reports whether a provider is currently marked unhealthy, so the
+// send can skip it without waiting for a timeout
+//
+//go:generate mockgen -package mockhealth -destination ./mock/mockhealth.go . Checker
+type Checker interface {
+	// Healthy reports the last probed availability of the host of the
+	// preference URL u; hosts not probed yet are healthy
+	Healthy(u string) bool
+}
+
+var _ Checker = (*Prober)(nil)
+
+type HealthConfig struct {
+	// Interval enables the prober; providers are never marked unhealthy when
+	// it is zero
+	Interval time.Duration `envconfig:"PROVIDER_HEALTH_CHECK_INTERVAL" default:"0s"`
+	// Path is requested with GET on the scheme and host of every preference
+	Path    string        `envconfig:"PROVIDER_HEALTH_CHECK_PATH" default:"/health"`
+	Timeout time.Duration `envconfig:"PROVIDER_HEALTH_CHECK_TIMEOUT" default:"2s"`
+	// UnhealthyThreshold is the number of consecutive failed probes marking a
+	// host unhealthy; one successful probe marks it healthy again
+	UnhealthyThreshold int `envconfig:"PROVIDER_HEALTH_UNHEALTHY_THRESHOLD" default:"3"`
+}
+
+type ProberParams struct {
+	fx.In
+
+	Lifecycle          fx.Lifecycle
+	Config             HealthConfig
+	PersistentProvider repository.PersistentProvider
+	MetricsCollector   *metrics.ProviderHealthCollector
+	Clock              clock.Clock
+	Logger             *zap.Logger
+}
+
+// hostState is the probe history of one provider host
+type hostState struct {
+	healthy             bool
+	consecutiveFailures int
+}
+
+// Prober periodically requests the health endpoint of every enabled
+// preference and remembers which hosts are unhealthy. Hosts are keyed like
+// the circuit breakers, so preferences sharing a host share its health
+type Prober struct {
+	httpclient         *http.Client
+	persistentProvider repository.PersistentProvider
+	metricsCollector   *metrics.ProviderHealthCollector
+	clock              clock.Clock
+	config             HealthConfig
+	logger             *zap.Logger
+
+	mu    sync.RWMutex
+	hosts map[string]*hostState
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewProber(params ProberParams) (*Prober, error) {
+	if params.Config.Interval > 0 && params.Config.UnhealthyThreshold < 1 {
+		return nil, errors.New("provider health unhealthy threshold must be at least 1")
+	}
+
+	prober := &Prober{
+		httpclient: &http.Client{
+			Timeout: params.Config.Timeout,
+		},
+		persistentProvider: params.PersistentProvider,
+		metricsCollector:   params.MetricsCollector,
+		clock:              params.Clock,
+		config:             params.Config,
+		logger:             params.Logger,
+		hosts:              map[string]*hostState{},
+	}
+	if params.Config.Interval <= 0 {
+		return prober, nil
+	}
+
+	registration, err := params.MetricsCollector.ObserveProviderHealth(prober.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			prober.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			defer registration.Unregister()
+			return prober.Stop(ctx)
+		},
+	})
+
+	return prober, nil
+}
+
+func (p *Prober) Healthy(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return true
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	state, ok := p.hosts[parsed.Host]
+	return !ok || state.healthy
+}
+
+// Snapshot returns the availability of every probed host, sorted by host
+func (p *Prober) Snapshot() []metrics.ProviderHealthSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshots := make([]metrics.ProviderHealthSnapshot, 0, len(p.hosts))
+	for host, state := range p.hosts {
+		snapshots = append(snapshots, metrics.ProviderHealthSnapshot{Host: host, Healthy: state.healthy})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Host < snapshots[j].Host
+	})
+	return snapshots
+}
+
+// Start probes right away and then every interval until Stop
+func (p *Prober) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			p.Probe(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.clock.After(p.config.Interval):
+			}
+		}
+	}()
+}
+
+// Stop cancels probing and waits for the probes in flight to end
+func (p *Prober) Stop(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Probe checks every host of the enabled preferences once, concurrently.
+// Hosts no longer configured are forgotten
+func (p *Prober) Probe(ctx context.Context) {
+	targets, err := p.targets(ctx)
+	if err != nil {
+		p.logger.Error("failed to load preferences for health checks", zap.Error(err))
+		return
+	}
+
+	var (
+		mu      sync.Mutex
+		results = make(map[string]bool, len(targets))
+		wg      sync.WaitGroup
+	)
+	for host, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			healthy := p.check(ctx, host, target)
+			p.metricsCollector.RecordCheck(ctx, host, healthy)
+
+			mu.Lock()
+			results[host] = healthy
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for host := range p.hosts {
+		if _, ok := results[host]; !ok {
+			delete(p.hosts, host)
+		}
+	}
+	for host, healthy := range results {
+		p.record(host, healthy)
+	}
+}
+
+// targets maps the host of every enabled preference to its health URL
+func (p *Prober) targets(ctx context.Context) (map[string]string, error) {
+	targets := map[string]string{}
+	for _, provider := range probedProviders {
+		preferences, err := p.persistentProvider.FindByProviderType(ctx, provider)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, preference := range preferences {
+			parsed, err := url.Parse(preference.Host)
+			if err != nil || parsed.Host == "" {
+				p.logger.Warn("skipping health check of invalid preference host",
+					zap.String("url", preference.Host),
+				)
+				continue
+			}
+			target := url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: p.config.Path}
+			targets[parsed.Host] = target.String()
+		}
+	}
+	return targets, nil
+}
+
+// check requests target and treats any 2xx answer as healthy
+func (p *Prober) check(ctx context.Context, host, target string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := p.httpclient.Do(req)
+	if err != nil {
+		p.logger.Debug("provider health check failed",
+			zap.String("host", host),
+			zap.Error(err),
+		)
+		return false
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		p.logger.Debug("provider health check failed",
+			zap.String("host", host),
+			zap.Int("status_code", resp.StatusCode),
+		)
+		return false
+	}
+	return true
+}
+
+// record applies one probe result; the caller holds mu
+func (p *Prober) record(host string, healthy bool) {
+	state, ok := p.hosts[host]
+	if !ok {
+		state = &hostState{healthy: true}
+		p.hosts[host] = state
+	}
+
+	if healthy {
+		if !state.healthy {
+			p.logger.Info("provider marked healthy", zap.String("host", host))
+		}
+		state.healthy = true
+		state.consecutiveFailures = 0
+		return
+	}
+
+	state.consecutiveFailures++
+	if state.healthy && state.consecutiveFailures >= p.config.UnhealthyThreshold {
+		p.logger.Warn("provider marked unhealthy",
+			zap.String("host", host),
+			zap.Int("consecutive_failures", state.consecutiveFailures),
+		)
+		state.healthy = false
+	}
+}
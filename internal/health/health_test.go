@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newTestProber(t *testing.T, persistent repository.PersistentProvider, config HealthConfig) *Prober {
+	collector, err := metrics.NewProviderHealthCollector(nil)
+	require.NoError(t, err)
+
+	prober, err := NewProber(ProberParams{
+		Lifecycle:          fxtest.NewLifecycle(t),
+		Config:             config,
+		PersistentProvider: persistent,
+		MetricsCollector:   collector,
+		Clock:              clock.NewRealClock(),
+		Logger:             zap.NewNop(),
+	})
+	require.NoError(t, err)
+	return prober
+}
+
+func TestNewProber(t *testing.T) {
+	collector, err := metrics.NewProviderHealthCollector(nil)
+	require.NoError(t, err)
+
+	_, err = NewProber(ProberParams{
+		Lifecycle:        fxtest.NewLifecycle(t),
+		Config:           HealthConfig{Interval: time.Second, UnhealthyThreshold: 0},
+		MetricsCollector: collector,
+		Logger:           zap.NewNop(),
+	})
+
+	assert.Error(t, err)
+}
+
+func TestProber_Probe(t *testing.T) {
+	var failing atomic.Bool
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status", r.URL.Path)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer provider.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	persistent := mockrepository.NewMockPersistentProvider(ctrl)
+	persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).
+		Return([]repository.NotificationPreference{{Host: provider.URL + "/email/success"}}, nil).AnyTimes()
+	persistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).
+		Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound).AnyTimes()
+
+	prober := newTestProber(t, persistent, HealthConfig{
+		Interval:           time.Minute,
+		Path:               "/status",
+		Timeout:            time.Second,
+		UnhealthyThreshold: 2,
+	})
+	preferenceURL := provider.URL + "/email/success"
+	ctx := context.Background()
+
+	assert.True(t, prober.Healthy(preferenceURL), "not probed yet")
+
+	prober.Probe(ctx)
+	assert.True(t, prober.Healthy(preferenceURL))
+
+	failing.Store(true)
+	prober.Probe(ctx)
+	assert.True(t, prober.Healthy(preferenceURL), "below threshold")
+
+	prober.Probe(ctx)
+	assert.False(t, prober.Healthy(preferenceURL))
+	assert.False(t, prober.Healthy(provider.URL+"/email/fail"), "health is shared by host")
+	assert.Equal(t, []metrics.ProviderHealthSnapshot{
+		{Host: provider.Listener.Addr().String(), Healthy: false},
+	}, prober.Snapshot())
+
+	failing.Store(false)
+	prober.Probe(ctx)
+	assert.True(t, prober.Healthy(preferenceURL))
+}
+
+func TestProber_ProbeForgetsRemovedHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	persistent := mockrepository.NewMockPersistentProvider(ctrl)
+	gomock.InOrder(
+		persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).
+			Return([]repository.NotificationPreference{{Host: "http://127.0.0.1:1/email"}}, nil),
+		persistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).
+			Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound),
+		persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).
+			Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound),
+		persistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).
+			Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound),
+	)
+
+	prober := newTestProber(t, persistent, HealthConfig{
+		Interval:           time.Minute,
+		Path:               "/health",
+		Timeout:            time.Second,
+		UnhealthyThreshold: 1,
+	})
+
+	prober.Probe(context.Background())
+	assert.False(t, prober.Healthy("http://127.0.0.1:1/email"))
+
+	prober.Probe(context.Background())
+	assert.True(t, prober.Healthy("http://127.0.0.1:1/email"))
+	assert.Empty(t, prober.Snapshot())
+}
+
+func TestProber_ProbeKeepsStateOnDatabaseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	persistent := mockrepository.NewMockPersistentProvider(ctrl)
+	persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).
+		Return([]repository.NotificationPreference{}, errors.New("database down"))
+
+	prober := newTestProber(t, persistent, HealthConfig{Interval: time.Minute, UnhealthyThreshold: 1})
+	prober.hosts["127.0.0.1:1"] = &hostState{healthy: false, consecutiveFailures: 1}
+
+	prober.Probe(context.Background())
+
+	assert.False(t, prober.Healthy("http://127.0.0.1:1/email"))
+}
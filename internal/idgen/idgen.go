@@ -0,0 +1,44 @@
+package idgen
+
+import (
+	"fmt"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/fx"
+)
+
+const (
+	StrategyULID      = "ulid"
+	StrategyUUIDv7    = "uuidv7"
+	StrategySnowflake = "snowflake"
+)
+
+var Module = fx.Module("idgen",
+	fx.Provide(
+		NewGenerator,
+	),
+)
+
+//go:generate mockgen -package mockidgen -destination ./mock/mockidgen.go . Generator
+type Generator interface {
+	NewID() (string, error)
+}
+
+type GeneratorConfig struct {
+	Strategy string `envconfig:"ID_GENERATOR_STRATEGY" default:"ulid"`
+	NodeID   int64  `envconfig:"ID_GENERATOR_NODE_ID" default:"0"`
+}
+
+// NewGenerator returns the generator selected by the configured strategy
+func NewGenerator(config GeneratorConfig, clock clock.Clock) (Generator, error) {
+	switch config.Strategy {
+	case StrategyULID:
+		return NewULIDGenerator(clock), nil
+	case StrategyUUIDv7:
+		return NewUUIDv7Generator(), nil
+	case StrategySnowflake:
+		return NewSnowflakeGenerator(config.NodeID, clock)
+	default:
+		return nil, fmt.Errorf("id generator strategy: '%s' not supported", config.Strategy)
+	}
+}
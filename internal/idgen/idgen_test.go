@@ -0,0 +1,177 @@
+package idgen
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewGenerator(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      GeneratorConfig
+		expected    Generator
+		expectError bool
+	}{
+		{
+			name:     "ulid strategy",
+			config:   GeneratorConfig{Strategy: StrategyULID},
+			expected: &ULIDGenerator{},
+		},
+		{
+			name:     "uuidv7 strategy",
+			config:   GeneratorConfig{Strategy: StrategyUUIDv7},
+			expected: &UUIDv7Generator{},
+		},
+		{
+			name:     "snowflake strategy",
+			config:   GeneratorConfig{Strategy: StrategySnowflake, NodeID: 7},
+			expected: &SnowflakeGenerator{},
+		},
+		{
+			name:        "snowflake strategy with invalid node id",
+			config:      GeneratorConfig{Strategy: StrategySnowflake, NodeID: 1024},
+			expectError: true,
+		},
+		{
+			name:        "unsupported strategy",
+			config:      GeneratorConfig{Strategy: "autoincrement"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := NewGenerator(tt.config, clock.NewRealClock())
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, generator)
+		})
+	}
+}
+
+func TestULIDGenerator_NewID(t *testing.T) {
+	generator := NewULIDGenerator(clock.NewRealClock())
+
+	first, err := generator.NewID()
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), first)
+
+	second, err := generator.NewID()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	// Timestamp prefix keeps IDs sortable across milliseconds
+	assert.LessOrEqual(t, first[:10], second[:10])
+}
+
+func TestULIDGenerator_NewID_UsesClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(time.UnixMilli(1469918176385)).Times(2)
+
+	generator := NewULIDGenerator(mockClock)
+
+	first, err := generator.NewID()
+	require.NoError(t, err)
+	second, err := generator.NewID()
+	require.NoError(t, err)
+
+	// Timestamp component from the ULID spec example
+	assert.Equal(t, "01ARYZ6S41", first[:10])
+	assert.Equal(t, first[:10], second[:10])
+	assert.NotEqual(t, first, second)
+}
+
+func TestEncodeULID(t *testing.T) {
+	var zero [16]byte
+	assert.Equal(t, "00000000000000000000000000", encodeULID(zero))
+
+	var max [16]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID(max))
+}
+
+func TestUUIDv7Generator_NewID(t *testing.T) {
+	generator := NewUUIDv7Generator()
+
+	id, err := generator.NewID()
+	require.NoError(t, err)
+
+	parsed, err := uuid.Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), parsed.Version())
+}
+
+func TestSnowflakeGenerator_NewID(t *testing.T) {
+	generator, err := NewSnowflakeGenerator(42, clock.NewRealClock())
+	require.NoError(t, err)
+
+	const workers, perWorker = 8, 500
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = make(map[string]bool, workers*perWorker)
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				id, err := generator.NewID()
+				require.NoError(t, err)
+
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, workers*perWorker, "ids must be unique")
+
+	id, err := generator.NewID()
+	require.NoError(t, err)
+	value, err := strconv.ParseInt(id, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), value>>snowflakeSequenceBits&snowflakeMaxNodeID)
+}
+
+func TestSnowflakeGenerator_NewID_UsesClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.UnixMilli(snowflakeEpoch + 1000)
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Now().Return(now).Times(2)
+
+	generator, err := NewSnowflakeGenerator(1, mockClock)
+	require.NoError(t, err)
+
+	first, err := generator.NewID()
+	require.NoError(t, err)
+	second, err := generator.NewID()
+	require.NoError(t, err)
+
+	// Same millisecond: only the sequence advances
+	assert.Equal(t, strconv.FormatInt(1000<<22|1<<12, 10), first)
+	assert.Equal(t, strconv.FormatInt(1000<<22|1<<12|1, 10), second)
+}
@@ -0,0 +1,74 @@
+package idgen
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+
+	snowflakeMaxNodeID   = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch is the custom epoch IDs are measured from (2025-01-01 UTC)
+var snowflakeEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+var _ Generator = (*SnowflakeGenerator)(nil)
+
+// SnowflakeGenerator produces 64-bit IDs composed of a millisecond timestamp,
+// the node ID and a per-millisecond sequence
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	nodeID   int64
+	lastMS   int64
+	sequence int64
+}
+
+func NewSnowflakeGenerator(nodeID int64, clock clock.Clock) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID > snowflakeMaxNodeID {
+		return nil, fmt.Errorf("snowflake node id: %d out of range [0, %d]", nodeID, snowflakeMaxNodeID)
+	}
+
+	return &SnowflakeGenerator{
+		clock:  clock,
+		nodeID: nodeID,
+	}, nil
+}
+
+func (g *SnowflakeGenerator) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.clock.Now().UnixMilli() - snowflakeEpoch
+	if ms < g.lastMS {
+		// Clock moved backwards; keep IDs monotonic by reusing the last timestamp
+		ms = g.lastMS
+	}
+
+	if ms == g.lastMS {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted for this millisecond, wait for the next one
+			for ms <= g.lastMS {
+				ms = g.clock.Now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMS = ms
+
+	id := ms<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		g.nodeID<<snowflakeSequenceBits |
+		g.sequence
+
+	return strconv.FormatInt(id, 10), nil
+}
@@ -0,0 +1,59 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+)
+
+// crockfordAlphabet is the Crockford base32 alphabet used by the ULID spec
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var _ Generator = (*ULIDGenerator)(nil)
+
+// ULIDGenerator produces 26-character, lexicographically time-sortable IDs
+type ULIDGenerator struct {
+	clock clock.Clock
+}
+
+func NewULIDGenerator(clock clock.Clock) *ULIDGenerator {
+	return &ULIDGenerator{
+		clock: clock,
+	}
+}
+
+func (g *ULIDGenerator) NewID() (string, error) {
+	var raw [16]byte
+
+	// 48-bit millisecond timestamp followed by 80 bits of randomness
+	ms := uint64(g.clock.Now().UnixMilli())
+	raw[0] = byte(ms >> 40)
+	raw[1] = byte(ms >> 32)
+	raw[2] = byte(ms >> 24)
+	raw[3] = byte(ms >> 16)
+	raw[4] = byte(ms >> 8)
+	raw[5] = byte(ms)
+
+	if _, err := rand.Read(raw[6:]); err != nil {
+		return "", err
+	}
+
+	return encodeULID(raw), nil
+}
+
+// encodeULID encodes 128 bits into 26 base32 characters, 5 bits at a time
+// starting from the least significant end
+func encodeULID(raw [16]byte) string {
+	hi := binary.BigEndian.Uint64(raw[:8])
+	lo := binary.BigEndian.Uint64(raw[8:])
+
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out[:])
+}
@@ -0,0 +1,21 @@
+package idgen
+
+import "github.com/google/uuid"
+
+var _ Generator = (*UUIDv7Generator)(nil)
+
+// UUIDv7Generator produces RFC 9562 version 7 UUIDs for partners that
+// require the UUID format while keeping time ordering
+type UUIDv7Generator struct{}
+
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{}
+}
+
+func (g *UUIDv7Generator) NewID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
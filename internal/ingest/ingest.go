@@ -0,0 +1,249 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Module("ingest",
+	fx.Invoke(RegisterSQSConsumer),
+)
+
+// Message is the body of a queued notification: the HTTP notify request plus
+// the recipient type that is otherwise taken from the URL
+type Message struct {
+	RecipientType string `json:"recipient_type" binding:"required"`
+	handler.NotifyRequest
+}
+
+type SQSConfig struct {
+	// QueueURL enables the consumer; ingestion is disabled when it is empty
+	QueueURL string `envconfig:"SQS_QUEUE_URL"`
+	Region   string `envconfig:"SQS_REGION"`
+	// Endpoint overrides the AWS endpoint, e.g. for LocalStack
+	Endpoint          string        `envconfig:"SQS_ENDPOINT"`
+	Workers           int           `envconfig:"SQS_WORKERS" default:"1"`
+	MaxMessages       int32         `envconfig:"SQS_MAX_MESSAGES" default:"10"`
+	WaitTime          time.Duration `envconfig:"SQS_WAIT_TIME" default:"20s"`
+	VisibilityTimeout time.Duration `envconfig:"SQS_VISIBILITY_TIMEOUT" default:"30s"`
+	ErrorBackoff      time.Duration `envconfig:"SQS_ERROR_BACKOFF" default:"5s"`
+}
+
+//go:generate mockgen -package mockingest -destination ./mock/mockingest.go . SQSAPI
+type SQSAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// NewSQSClient resolves credentials through the default AWS chain
+func NewSQSClient(ctx context.Context, config SQSConfig) (*sqs.Client, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(config.Region))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+
+	return sqs.NewFromConfig(cfg, func(o *sqs.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+	}), nil
+}
+
+type SQSConsumerParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    SQSConfig
+	Service   service.NotificationProvider
+	Clock     clock.Clock
+	Logger    *zap.Logger
+}
+
+// RegisterSQSConsumer starts polling with the application when a queue is
+// configured
+func RegisterSQSConsumer(params SQSConsumerParams) error {
+	if params.Config.QueueURL == "" {
+		return nil
+	}
+	if params.Config.Workers < 1 {
+		return fmt.Errorf("sqs workers: %d must be at least 1", params.Config.Workers)
+	}
+
+	client, err := NewSQSClient(context.Background(), params.Config)
+	if err != nil {
+		return err
+	}
+
+	consumer := NewSQSConsumer(client, params.Service, params.Clock, params.Config, params.Logger)
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			consumer.Start()
+			return nil
+		},
+		OnStop: consumer.Stop,
+	})
+
+	return nil
+}
+
+// SQSConsumer long-polls a queue and sends every message through the
+// notification service. A message is deleted once retrying it cannot help:
+// it was delivered, it was rejected, or a retry risks a duplicate. Messages
+// that are safe to retry are left on the queue to reappear after the
+// visibility timeout, so the queue's redrive policy bounds the attempts
+type SQSConsumer struct {
+	client  SQSAPI
+	service service.NotificationProvider
+	clock   clock.Clock
+	config  SQSConfig
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSQSConsumer(client SQSAPI, service service.NotificationProvider, clock clock.Clock, config SQSConfig, logger *zap.Logger) *SQSConsumer {
+	return &SQSConsumer{
+		client:  client,
+		service: service,
+		clock:   clock,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+func (c *SQSConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	for range c.config.Workers {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.poll(ctx)
+		}()
+	}
+}
+
+// Stop ends polling and waits for in-flight messages to finish or ctx to end
+func (c *SQSConsumer) Stop(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *SQSConsumer) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := c.receive(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("failed to receive sqs messages",
+				zap.String("queue_url", c.config.QueueURL),
+				zap.Error(err),
+			)
+
+			select {
+			case <-ctx.Done():
+			case <-c.clock.After(c.config.ErrorBackoff):
+			}
+		}
+	}
+}
+
+// receive fetches one batch and handles its messages in order
+func (c *SQSConsumer) receive(ctx context.Context) error {
+	output, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.config.QueueURL),
+		MaxNumberOfMessages: c.config.MaxMessages,
+		WaitTimeSeconds:     int32(c.config.WaitTime / time.Second),
+		VisibilityTimeout:   int32(c.config.VisibilityTimeout / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, message := range output.Messages {
+		c.handle(ctx, message)
+	}
+	return nil
+}
+
+// handle finishes a received message even when polling is stopping, bounded
+// by the visibility timeout after which SQS hands it out again
+func (c *SQSConsumer) handle(ctx context.Context, message types.Message) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.config.VisibilityTimeout)
+	defer cancel()
+
+	messageID := aws.ToString(message.MessageId)
+
+	report, err := c.process(ctx, aws.ToString(message.Body))
+	if err != nil && report.RetryDisposition == service.RetrySafe {
+		c.logger.Warn("failed to process sqs message, leaving it for redelivery",
+			zap.String("message_id", messageID),
+			zap.String("notification_id", report.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	if err != nil {
+		c.logger.Error("failed to process sqs message, dropping it",
+			zap.String("message_id", messageID),
+			zap.String("notification_id", report.ID),
+			zap.String("retry_disposition", report.RetryDisposition),
+			zap.Error(err),
+		)
+	}
+
+	if _, err := c.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.config.QueueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	}); err != nil {
+		c.logger.Error("failed to delete sqs message",
+			zap.String("message_id", messageID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (c *SQSConsumer) process(ctx context.Context, body string) (service.DeliveryReport, error) {
+	var message Message
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, fmt.Errorf("decode message: %w", err)
+	}
+	if err := binding.Validator.ValidateStruct(&message); err != nil {
+		return service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, fmt.Errorf("validate message: %w", err)
+	}
+
+	return c.service.Send(ctx, message.RecipientType, message.Notification())
+}
@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	mockingest "github.com/koungkub/fw-challenge-notification-service/internal/ingest/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+const testQueueURL = "https://sqs.ap-southeast-1.amazonaws.com/123456789012/notifications"
+
+func TestRegisterSQSConsumer_Disabled(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+
+	err := RegisterSQSConsumer(SQSConsumerParams{
+		Lifecycle: lc,
+		Config:    SQSConfig{},
+		Logger:    zap.NewNop(),
+	})
+
+	require.NoError(t, err)
+	lc.RequireStart().RequireStop()
+}
+
+func TestSQSConsumer_handle(t *testing.T) {
+	validBody := `{"recipient_type":"buyer","to":"buyer@example.com","title":"Order shipped","message":"On its way","priority":"high"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		setupMocks     func(*mockservice.MockNotificationProvider)
+		expectedDelete bool
+	}{
+		{
+			name: "deletes delivered message",
+			body: validBody,
+			setupMocks: func(svc *mockservice.MockNotificationProvider) {
+				svc.EXPECT().Send(gomock.Any(), "buyer", service.Notification{
+					To:       "buyer@example.com",
+					Title:    "Order shipped",
+					Message:  "On its way",
+					Priority: dispatch.PriorityHigh,
+				}).Return(service.DeliveryReport{RetryDisposition: service.RetryNotNeeded}, nil)
+			},
+			expectedDelete: true,
+		},
+		{
+			name: "keeps message that is safe to retry",
+			body: validBody,
+			setupMocks: func(svc *mockservice.MockNotificationProvider) {
+				svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetrySafe}, errors.New("provider unavailable"))
+			},
+		},
+		{
+			name: "deletes message whose retry risks a duplicate",
+			body: validBody,
+			setupMocks: func(svc *mockservice.MockNotificationProvider) {
+				svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryUnsafe}, errors.New("sms provider timed out"))
+			},
+			expectedDelete: true,
+		},
+		{
+			name: "deletes rejected message",
+			body: validBody,
+			setupMocks: func(svc *mockservice.MockNotificationProvider) {
+				svc.EXPECT().Send(gomock.Any(), "buyer", gomock.Any()).
+					Return(service.DeliveryReport{RetryDisposition: service.RetryDoNotRetry}, &service.RecipientTypeError{RecipientType: "buyer"})
+			},
+			expectedDelete: true,
+		},
+		{
+			name:           "deletes malformed message without sending",
+			body:           `{"recipient_type":`,
+			setupMocks:     func(svc *mockservice.MockNotificationProvider) {},
+			expectedDelete: true,
+		},
+		{
+			name:           "deletes invalid message without sending",
+			body:           `{"to":"buyer@example.com","title":"Order shipped","message":"On its way"}`,
+			setupMocks:     func(svc *mockservice.MockNotificationProvider) {},
+			expectedDelete: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockSQS := mockingest.NewMockSQSAPI(ctrl)
+			mockService := mockservice.NewMockNotificationProvider(ctrl)
+
+			tt.setupMocks(mockService)
+			if tt.expectedDelete {
+				mockSQS.EXPECT().DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+					QueueUrl:      aws.String(testQueueURL),
+					ReceiptHandle: aws.String("receipt-1"),
+				}).Return(&sqs.DeleteMessageOutput{}, nil)
+			}
+
+			consumer := NewSQSConsumer(mockSQS, mockService, clock.NewRealClock(), SQSConfig{
+				QueueURL:          testQueueURL,
+				VisibilityTimeout: time.Second,
+			}, zap.NewNop())
+
+			consumer.handle(context.Background(), types.Message{
+				MessageId:     aws.String("message-1"),
+				ReceiptHandle: aws.String("receipt-1"),
+				Body:          aws.String(tt.body),
+			})
+		})
+	}
+}
+
+func TestSQSConsumer_StartStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSQS := mockingest.NewMockSQSAPI(ctrl)
+	mockService := mockservice.NewMockNotificationProvider(ctrl)
+
+	sent := make(chan struct{})
+	gomock.InOrder(
+		mockSQS.EXPECT().ReceiveMessage(gomock.Any(), &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(testQueueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+			VisibilityTimeout:   30,
+		}).Return(&sqs.ReceiveMessageOutput{
+			Messages: []types.Message{{
+				ReceiptHandle: aws.String("receipt-1"),
+				Body:          aws.String(`{"recipient_type":"seller","to":"seller@example.com","title":"New order","message":"Order 42"}`),
+			}},
+		}, nil),
+		// Long polling blocks until the consumer stops
+		mockSQS.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}).AnyTimes(),
+	)
+	mockService.EXPECT().Send(gomock.Any(), "seller", gomock.Any()).
+		DoAndReturn(func(context.Context, string, service.Notification) (service.DeliveryReport, error) {
+			close(sent)
+			return service.DeliveryReport{RetryDisposition: service.RetryNotNeeded}, nil
+		})
+	mockSQS.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+
+	consumer := NewSQSConsumer(mockSQS, mockService, clock.NewRealClock(), SQSConfig{
+		QueueURL:          testQueueURL,
+		Workers:           1,
+		MaxMessages:       10,
+		WaitTime:          20 * time.Second,
+		VisibilityTimeout: 30 * time.Second,
+	}, zap.NewNop())
+
+	consumer.Start()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("message was not sent")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, consumer.Stop(ctx))
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/ingest (interfaces: SQSAPI)
+//
+// Generated by this command:
+//
+//	mockgen -package mockingest -destination ./mock/mockingest.go . SQSAPI
+//
+
+// Package mockingest is a generated GoMock package.
+package mockingest
+
+import (
+	context "context"
+	reflect "reflect"
+
+	sqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSQSAPI is a mock of SQSAPI interface.
+type MockSQSAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSQSAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockSQSAPIMockRecorder is the mock recorder for MockSQSAPI.
+type MockSQSAPIMockRecorder struct {
+	mock *MockSQSAPI
+}
+
+// NewMockSQSAPI creates a new mock instance.
+func NewMockSQSAPI(ctrl *gomock.Controller) *MockSQSAPI {
+	mock := &MockSQSAPI{ctrl: ctrl}
+	mock.recorder = &MockSQSAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSQSAPI) EXPECT() *MockSQSAPIMockRecorder {
+	return m.recorder
+}
+
+// DeleteMessage mocks base method.
+func (m *MockSQSAPI) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteMessage", varargs...)
+	ret0, _ := ret[0].(*sqs.DeleteMessageOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockSQSAPIMockRecorder) DeleteMessage(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockSQSAPI)(nil).DeleteMessage), varargs...)
+}
+
+// ReceiveMessage mocks base method.
+func (m *MockSQSAPI) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, params}
+	for _, a := range optFns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReceiveMessage", varargs...)
+	ret0, _ := ret[0].(*sqs.ReceiveMessageOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveMessage indicates an expected call of ReceiveMessage.
+func (mr *MockSQSAPIMockRecorder) ReceiveMessage(ctx, params any, optFns ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, params}, optFns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessage", reflect.TypeOf((*MockSQSAPI)(nil).ReceiveMessage), varargs...)
+}
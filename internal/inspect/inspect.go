@@ -0,0 +1,163 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+const statusPath = "/admin/v1.0/status"
+
+// clearScreen moves the cursor home and clears the terminal between refreshes
+const clearScreen = "\033[H\033[2J"
+
+type InspectConfig struct {
+	Addr     string        `envconfig:"INSPECT_ADDR" default:"http://localhost:8080"`
+	Token    string        `envconfig:"HTTP_ADMIN_TOKEN" secret:"true"`
+	Interval time.Duration `envconfig:"INSPECT_INTERVAL" default:"0s"`
+	Timeout  time.Duration `envconfig:"INSPECT_TIMEOUT" default:"5s"`
+}
+
+func NewInspectConfig() InspectConfig {
+	var cfg InspectConfig
+	envconfig.MustProcess("", &cfg)
+
+	return cfg
+}
+
+// Inspector renders the admin API status of a running instance
+type Inspector struct {
+	addr     string
+	token    string
+	interval time.Duration
+	client   *http.Client
+}
+
+func New(config InspectConfig) *Inspector {
+	return &Inspector{
+		addr:     strings.TrimSuffix(config.Addr, "/"),
+		token:    config.Token,
+		interval: config.Interval,
+		client:   &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Run renders the status once, or keeps refreshing it every interval until
+// ctx is cancelled when an interval is configured
+func (i *Inspector) Run(ctx context.Context, w io.Writer) error {
+	if i.interval <= 0 {
+		status, err := i.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		return Render(w, i.addr, status, time.Now())
+	}
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		fmt.Fprint(w, clearScreen)
+		if status, err := i.Fetch(ctx); err != nil {
+			fmt.Fprintf(w, "%s  %s\n\nerror: %s\n", i.addr, time.Now().Format(time.RFC3339), err)
+		} else if err := Render(w, i.addr, status, time.Now()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Inspector) Fetch(ctx context.Context) (handler.AdminStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.addr+statusPath, nil)
+	if err != nil {
+		return handler.AdminStatus{}, err
+	}
+	if i.token != "" {
+		req.Header.Set("Authorization", "Bearer "+i.token)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return handler.AdminStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr handler.ErrorHandler
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+			return handler.AdminStatus{}, fmt.Errorf("admin api responded with status code %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return handler.AdminStatus{}, fmt.Errorf("admin api responded with status code %d", resp.StatusCode)
+	}
+
+	var status handler.AdminStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return handler.AdminStatus{}, err
+	}
+
+	return status, nil
+}
+
+// Render writes status as aligned tables, one section per component
+func Render(w io.Writer, addr string, status handler.AdminStatus, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%s  %s\n\n", addr, now.Format(time.RFC3339))
+
+	fmt.Fprintln(tw, "DISPATCH")
+	fmt.Fprintln(tw, "IN FLIGHT\tMAX\tQUEUED HIGH\tQUEUED NORMAL\tQUEUED LOW")
+	fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\n",
+		status.Dispatch.InFlight,
+		status.Dispatch.MaxConcurrent,
+		status.Dispatch.Queued["high"],
+		status.Dispatch.Queued["normal"],
+		status.Dispatch.Queued["low"],
+	)
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "CIRCUIT BREAKERS")
+	if len(status.CircuitBreakers) == 0 {
+		fmt.Fprintln(tw, "no provider called yet")
+	} else {
+		fmt.Fprintln(tw, "HOST\tSTATE\tREQUESTS\tSUCCESSES\tFAILURES\tCONSECUTIVE FAILURES")
+		for _, breaker := range status.CircuitBreakers {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\n",
+				breaker.Host,
+				breaker.State,
+				breaker.Requests,
+				breaker.TotalSuccesses,
+				breaker.TotalFailures,
+				breaker.ConsecutiveFailures,
+			)
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "CACHES")
+	fmt.Fprintln(tw, "NAME\tHITS\tMISSES\tHIT RATIO\tKEYS ADDED\tKEYS EVICTED")
+	for _, cache := range status.Caches {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%d\t%d\n",
+			cache.Name,
+			cache.Hits,
+			cache.Misses,
+			cache.HitRatio*100,
+			cache.KeysAdded,
+			cache.KeysEvicted,
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,124 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspector_Fetch(t *testing.T) {
+	expected := handler.AdminStatus{
+		CircuitBreakers: []handler.CircuitBreakerStatus{
+			{Host: "https://email.example.com", State: "open", Requests: 5, TotalFailures: 5, ConsecutiveFailures: 5},
+		},
+		Caches: []handler.CacheStatus{
+			{Name: "preferences", Hits: 10, Misses: 2, HitRatio: 0.8333},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		handler       http.HandlerFunc
+		expectedError string
+	}{
+		{
+			name:  "decodes status and sends the token",
+			token: "s3cret",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, statusPath, r.URL.Path)
+				assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+				json.NewEncoder(w).Encode(expected)
+			},
+		},
+		{
+			name:  "surfaces the admin api error message",
+			token: "wrong",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(handler.ErrorHandler{ErrorCode: "E101", Message: "invalid admin token"})
+			},
+			expectedError: "admin api responded with status code 401: invalid admin token",
+		},
+		{
+			name: "reports status code without an error body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			expectedError: "admin api responded with status code 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			inspector := New(InspectConfig{Addr: server.URL + "/", Token: tt.token, Timeout: time.Second})
+
+			status, err := inspector.Fetch(context.Background())
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, status)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("renders breakers and caches", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := Render(&buf, "http://localhost:8080", handler.AdminStatus{
+			Dispatch: handler.DispatchStatus{
+				MaxConcurrent: 100,
+				InFlight:      100,
+				Queued:        map[string]int{"high": 1, "normal": 7},
+			},
+			CircuitBreakers: []handler.CircuitBreakerStatus{
+				{Host: "https://email.example.com", State: "open", Requests: 5, TotalFailures: 5, ConsecutiveFailures: 5},
+			},
+			Caches: []handler.CacheStatus{
+				{Name: "preferences", Hits: 3, Misses: 1, HitRatio: 0.75, KeysAdded: 1},
+			},
+		}, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, `http://localhost:8080  2025-06-01T12:00:00Z
+
+DISPATCH
+IN FLIGHT  MAX  QUEUED HIGH  QUEUED NORMAL  QUEUED LOW
+100        100  1            7              0
+
+CIRCUIT BREAKERS
+HOST                       STATE  REQUESTS  SUCCESSES  FAILURES  CONSECUTIVE FAILURES
+https://email.example.com  open   5         0          5         5
+
+CACHES
+NAME         HITS  MISSES  HIT RATIO  KEYS ADDED  KEYS EVICTED
+preferences  3     1       75.0%      1           0
+`, buf.String())
+	})
+
+	t.Run("notes when no breaker exists yet", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := Render(&buf, "http://localhost:8080", handler.AdminStatus{}, now)
+
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "no provider called yet")
+	})
+}
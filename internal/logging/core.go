@@ -0,0 +1,216 @@
+package logging
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted replaces the value of secret fields
+const Redacted = "[REDACTED]"
+
+// secretFields and recipientFields are the field keys always redacted, on
+// top of Config.RedactedFields
+var (
+	secretFields    = []string{"secret", "secret_key", "password", "token", "api_key", "authorization"}
+	recipientFields = []string{"to", "recipient", "email", "address", "device_token"}
+)
+
+// emailPattern finds the recipient addresses in free text such as error
+// messages and provider responses
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Config holds the sampling and redaction settings of the logger, read by
+// NewConfig before the other settings since the logger is built first
+type Config struct {
+	// SamplingLevel is the most severe level sampled; warnings and errors
+	// are always written unless it is raised
+	SamplingLevel zapcore.Level `envconfig:"LOG_SAMPLING_LEVEL" default:"info"`
+	// SamplingInitial lines with the same level and message are written each
+	// SamplingTick, then every SamplingThereafter-th one; 0 disables sampling
+	SamplingInitial    int           `envconfig:"LOG_SAMPLING_INITIAL" default:"100"`
+	SamplingThereafter int           `envconfig:"LOG_SAMPLING_THEREAFTER" default:"100"`
+	SamplingTick       time.Duration `envconfig:"LOG_SAMPLING_TICK" default:"1s"`
+	// Redaction masks recipient addresses and hides secrets in every line
+	Redaction bool `envconfig:"LOG_REDACTION_ENABLED" default:"true"`
+	// RedactedFields are further field keys whose values are hidden
+	RedactedFields []string `envconfig:"LOG_REDACTED_FIELDS"`
+}
+
+// NewConfig reads Config from the environment. An invalid setting keeps
+// redaction on and sampling off; it is reported once the other settings are
+// read, since they include Config
+func NewConfig() (Config, error) {
+	var cfg Config
+	if err := envconfig.Process("", &cfg); err != nil {
+		return Config{Redaction: true}, err
+	}
+
+	return cfg, nil
+}
+
+// NewCore wraps core to sample the lines of the sampled levels and redact
+// every line written
+func NewCore(core zapcore.Core, config Config) zapcore.Core {
+	if config.Redaction {
+		core = newRedactingCore(core, config.RedactedFields)
+	}
+
+	if config.SamplingInitial > 0 && config.SamplingTick > 0 {
+		core = &samplingCore{
+			Core:    core,
+			sampler: zapcore.NewSamplerWithOptions(core, config.SamplingTick, config.SamplingInitial, config.SamplingThereafter),
+			level:   config.SamplingLevel,
+		}
+	}
+	return core
+}
+
+// samplingCore samples the lines up to level, so a flood of debug lines is
+// thinned out while every warning still gets through
+type samplingCore struct {
+	zapcore.Core
+	sampler zapcore.Core
+	level   zapcore.Level
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{
+		Core:    c.Core.With(fields),
+		sampler: c.sampler.With(fields),
+		level:   c.level,
+	}
+}
+
+func (c *samplingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level <= c.level {
+		return c.sampler.Check(entry, checked)
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// redactingCore hides the values of secret fields, and masks recipient
+// fields and the email addresses found in messages, strings and errors.
+// Other field types, such as objects, are written as they are
+type redactingCore struct {
+	zapcore.Core
+	secrets    map[string]bool
+	recipients map[string]bool
+}
+
+func newRedactingCore(core zapcore.Core, redactedFields []string) *redactingCore {
+	secrets := map[string]bool{}
+	for _, key := range slices.Concat(secretFields, redactedFields) {
+		secrets[strings.ToLower(key)] = true
+	}
+
+	recipients := map[string]bool{}
+	for _, key := range recipientFields {
+		recipients[key] = true
+	}
+
+	return &redactingCore{Core: core, secrets: secrets, recipients: recipients}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{
+		Core:       c.Core.With(c.redact(fields)),
+		secrets:    c.secrets,
+		recipients: c.recipients,
+	}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = MaskEmails(entry.Message)
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+// redact returns fields with their sensitive values replaced, copying them
+// only when one is
+func (c *redactingCore) redact(fields []zapcore.Field) []zapcore.Field {
+	redacted := fields
+	copied := false
+	for i, field := range fields {
+		replaced, ok := c.redactField(field)
+		if !ok {
+			continue
+		}
+
+		if !copied {
+			redacted = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		redacted[i] = replaced
+	}
+	return redacted
+}
+
+func (c *redactingCore) redactField(field zapcore.Field) (zapcore.Field, bool) {
+	key := strings.ToLower(field.Key)
+	if c.secrets[key] {
+		return zap.String(field.Key, Redacted), true
+	}
+
+	var value string
+	switch field.Type {
+	case zapcore.StringType:
+		value = field.String
+	case zapcore.ErrorType:
+		err, ok := field.Interface.(error)
+		if !ok || err == nil {
+			return field, false
+		}
+		value = err.Error()
+	default:
+		return field, false
+	}
+	if value == "" {
+		return field, false
+	}
+
+	masked := MaskEmails(value)
+	if c.recipients[key] {
+		masked = MaskRecipient(value)
+	}
+	if masked == value {
+		return field, false
+	}
+	return zap.String(field.Key, masked), true
+}
+
+// MaskEmails masks every email address of text, e.g. b***@example.com
+func MaskEmails(text string) string {
+	if !strings.Contains(text, "@") {
+		return text
+	}
+	return emailPattern.ReplaceAllStringFunc(text, MaskRecipient)
+}
+
+// MaskRecipient keeps the first character of a recipient address and the
+// domain of an email, e.g. b***@example.com for buyer@example.com and +***
+// for a phone number
+func MaskRecipient(recipient string) string {
+	local, domain, isEmail := strings.Cut(recipient, "@")
+	if isEmail {
+		domain = "@" + domain
+	}
+
+	first, _ := utf8.DecodeRuneInString(local)
+	if first == utf8.RuneError {
+		return "***" + domain
+	}
+	return string(first) + "***" + domain
+}
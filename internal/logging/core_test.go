@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewCore_Redaction(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          Config
+		message         string
+		fields          []zap.Field
+		expectedMessage string
+		expectedFields  map[string]any
+	}{
+		{
+			name:            "masks email addresses in the message and string fields",
+			config:          Config{Redaction: true},
+			message:         "bounced for buyer@example.com",
+			fields:          []zap.Field{zap.String("response_body", `{"to":"buyer@example.com"}`)},
+			expectedMessage: "bounced for b***@example.com",
+			expectedFields:  map[string]any{"response_body": `{"to":"b***@example.com"}`},
+		},
+		{
+			name:            "masks recipient fields that are not emails",
+			config:          Config{Redaction: true},
+			message:         "sent",
+			fields:          []zap.Field{zap.String("to", "+66812345678"), zap.String("channel", "Push")},
+			expectedMessage: "sent",
+			expectedFields:  map[string]any{"to": "+***", "channel": "Push"},
+		},
+		{
+			name:            "hides secret fields and configured fields",
+			config:          Config{Redaction: true, RedactedFields: []string{"Signature"}},
+			message:         "posted",
+			fields:          []zap.Field{zap.String("secret_key", "s3cr3t"), zap.String("signature", "abc"), zap.Int("token", 42)},
+			expectedMessage: "posted",
+			expectedFields:  map[string]any{"secret_key": Redacted, "signature": Redacted, "token": Redacted},
+		},
+		{
+			name:            "masks email addresses in errors",
+			config:          Config{Redaction: true},
+			message:         "failed",
+			fields:          []zap.Field{zap.Error(errors.New("unknown recipient buyer@example.com"))},
+			expectedMessage: "failed",
+			expectedFields:  map[string]any{"error": "unknown recipient b***@example.com"},
+		},
+		{
+			name:            "writes lines as they are without redaction",
+			message:         "bounced for buyer@example.com",
+			fields:          []zap.Field{zap.String("secret_key", "s3cr3t")},
+			expectedMessage: "bounced for buyer@example.com",
+			expectedFields:  map[string]any{"secret_key": "s3cr3t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			logger := zap.New(NewCore(core, tt.config))
+
+			logger.Info(tt.message, tt.fields...)
+
+			entries := logs.All()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tt.expectedMessage, entries[0].Message)
+			assert.Equal(t, tt.expectedFields, entries[0].ContextMap())
+		})
+	}
+}
+
+func TestNewCore_RedactsLoggerFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewCore(core, Config{Redaction: true, SamplingInitial: 1, SamplingTick: time.Minute}))
+
+	logger.With(zap.String("recipient", "buyer@example.com")).Warn("suppressed")
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]any{"recipient": "b***@example.com"}, entries[0].ContextMap())
+}
+
+func TestNewCore_Sampling(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        Config
+		level         zapcore.Level
+		expectedLines int
+	}{
+		{
+			name:          "samples repeated lines of a sampled level",
+			config:        Config{SamplingLevel: zap.DebugLevel, SamplingInitial: 2, SamplingThereafter: 5, SamplingTick: time.Minute},
+			level:         zap.DebugLevel,
+			expectedLines: 4,
+		},
+		{
+			name:          "writes every line above the sampled level",
+			config:        Config{SamplingLevel: zap.DebugLevel, SamplingInitial: 2, SamplingThereafter: 5, SamplingTick: time.Minute},
+			level:         zap.WarnLevel,
+			expectedLines: 12,
+		},
+		{
+			name:          "writes every line without sampling",
+			config:        Config{SamplingLevel: zap.DebugLevel},
+			level:         zap.DebugLevel,
+			expectedLines: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			logger := zap.New(NewCore(core, tt.config))
+
+			for range 12 {
+				logger.Log(tt.level, "cache hit")
+			}
+
+			assert.Equal(t, tt.expectedLines, logs.Len())
+		})
+	}
+}
+
+func TestMaskRecipient(t *testing.T) {
+	tests := []struct {
+		recipient string
+		expected  string
+	}{
+		{recipient: "buyer@example.com", expected: "b***@example.com"},
+		{recipient: "@example.com", expected: "***@example.com"},
+		{recipient: "+66812345678", expected: "+***"},
+		{recipient: "ดวงใจ", expected: "ด***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recipient, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MaskRecipient(tt.recipient))
+		})
+	}
+}
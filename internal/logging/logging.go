@@ -0,0 +1,57 @@
+// Package logging carries request-scoped log fields in a context, so every
+// line logged while serving a request is correlated without passing the
+// fields through each call
+package logging
+
+import (
+	"context"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// Field keys carried by request contexts, shared by every layer
+const (
+	FieldRequestID     = "request_id"
+	FieldRoute         = "route"
+	FieldClientIP      = "client_ip"
+	FieldTenant        = "tenant"
+	FieldRecipientType = "recipient_type"
+)
+
+type fieldsKey struct{}
+
+// With returns ctx carrying fields on top of those ctx already carries; a
+// field replaces a carried one with the same key
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	carried := slices.Clone(Fields(ctx))
+	for _, field := range fields {
+		i := slices.IndexFunc(carried, func(c zap.Field) bool { return c.Key == field.Key })
+		if i < 0 {
+			carried = append(carried, field)
+			continue
+		}
+		carried[i] = field
+	}
+	return context.WithValue(ctx, fieldsKey{}, carried)
+}
+
+// Fields returns the fields carried by ctx, none when there are none
+func Fields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// From returns logger with the fields carried by ctx, or logger itself when
+// ctx carries none
+func From(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
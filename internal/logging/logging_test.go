@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWith(t *testing.T) {
+	ctx := With(context.Background(), zap.String(FieldRequestID, "req-1"), zap.String(FieldRoute, "/notify"))
+	child := With(ctx, zap.String(FieldTenant, "acme"), zap.String(FieldRoute, "/batch"))
+
+	assert.Equal(t, []zap.Field{
+		zap.String(FieldRequestID, "req-1"),
+		zap.String(FieldRoute, "/notify"),
+	}, Fields(ctx), "the parent context is left untouched")
+	assert.Equal(t, []zap.Field{
+		zap.String(FieldRequestID, "req-1"),
+		zap.String(FieldRoute, "/batch"),
+		zap.String(FieldTenant, "acme"),
+	}, Fields(child))
+	assert.Equal(t, ctx, With(ctx), "no field keeps the context")
+}
+
+func TestFrom(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	From(context.Background(), logger).Info("without fields")
+	From(With(context.Background(), zap.String(FieldRequestID, "req-1")), logger).Info("with fields", zap.String("channel", "Email"))
+
+	entries := logs.All()
+	assert.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ContextMap())
+	assert.Equal(t, map[string]any{FieldRequestID: "req-1", "channel": "Email"}, entries[1].ContextMap())
+}
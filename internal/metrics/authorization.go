@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	AuthorizationAllowed         = "allowed"
+	AuthorizationUnauthenticated = "unauthenticated"
+	AuthorizationForbidden       = "forbidden"
+)
+
+type AuthorizationCollector struct {
+	decisionCount metric.Int64Counter
+}
+
+func NewAuthorizationCollector(meter metric.Meter) (*AuthorizationCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	decisionCount, err := meter.Int64Counter(
+		"http.server.authorizations",
+		metric.WithDescription("Total authorization decisions by caller role"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizationCollector{
+		decisionCount: decisionCount,
+	}, nil
+}
+
+// RecordDecision records whether a caller holding role was let through a
+// route requiring required; role is empty for callers without a known key
+func (c *AuthorizationCollector) RecordDecision(ctx context.Context, required string, role string, outcome string) {
+	if role == "" {
+		role = "none"
+	}
+
+	c.decisionCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("auth.required_role", required),
+		attribute.String("auth.role", role),
+		attribute.String("auth.outcome", outcome),
+	))
+}
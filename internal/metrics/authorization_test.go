@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewAuthorizationCollector(t *testing.T) {
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewAuthorizationCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			collector.RecordDecision(context.Background(), "admin", "notify", AuthorizationForbidden)
+		})
+	})
+}
+
+func TestAuthorizationCollector_RecordDecision(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewAuthorizationCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordDecision(ctx, "notify", "notify", AuthorizationAllowed)
+	collector.RecordDecision(ctx, "notify", "notify", AuthorizationAllowed)
+	collector.RecordDecision(ctx, "admin", "notify", AuthorizationForbidden)
+	collector.RecordDecision(ctx, "admin", "", AuthorizationUnauthenticated)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	decisions, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	counts := map[string]int64{}
+	for _, dp := range decisions.DataPoints {
+		required, _ := dp.Attributes.Value(attribute.Key("auth.required_role"))
+		role, _ := dp.Attributes.Value(attribute.Key("auth.role"))
+		outcome, _ := dp.Attributes.Value(attribute.Key("auth.outcome"))
+		counts[required.AsString()+"/"+role.AsString()+"/"+outcome.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{
+		"notify/notify/allowed":      2,
+		"admin/notify/forbidden":     1,
+		"admin/none/unauthenticated": 1,
+	}, counts)
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type DispatchCollector struct {
+	queueDepth      metric.Int64UpDownCounter
+	waitDuration    metric.Float64Histogram
+	dispatchedCount metric.Int64Counter
+	abandonedCount  metric.Int64Counter
+}
+
+func NewDispatchCollector(meter metric.Meter) (*DispatchCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	queueDepth, err := meter.Int64UpDownCounter(
+		"notification.dispatch.queue_depth",
+		metric.WithDescription("Notifications waiting for a delivery slot"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	waitDuration, err := meter.Float64Histogram(
+		"notification.dispatch.wait_duration",
+		metric.WithDescription("Time notifications waited for a delivery slot"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dispatchedCount, err := meter.Int64Counter(
+		"notification.dispatch.dispatched",
+		metric.WithDescription("Total notifications given a delivery slot"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	abandonedCount, err := meter.Int64Counter(
+		"notification.dispatch.abandoned",
+		metric.WithDescription("Total notifications whose context ended while waiting for a delivery slot"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DispatchCollector{
+		queueDepth:      queueDepth,
+		waitDuration:    waitDuration,
+		dispatchedCount: dispatchedCount,
+		abandonedCount:  abandonedCount,
+	}, nil
+}
+
+// RecordQueued records a notification starting to wait for a slot
+func (c *DispatchCollector) RecordQueued(ctx context.Context, priority string) {
+	c.queueDepth.Add(ctx, 1, metric.WithAttributes(dispatchAttributes(priority)...))
+}
+
+// RecordDequeued records a notification leaving the queue, dispatched or not
+func (c *DispatchCollector) RecordDequeued(ctx context.Context, priority string) {
+	c.queueDepth.Add(ctx, -1, metric.WithAttributes(dispatchAttributes(priority)...))
+}
+
+// RecordDispatched records a notification given a slot after waiting for it
+func (c *DispatchCollector) RecordDispatched(ctx context.Context, priority string, wait time.Duration) {
+	attrs := dispatchAttributes(priority)
+
+	c.dispatchedCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+	c.waitDuration.Record(ctx, wait.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// RecordAbandoned records a notification that gave up waiting for a slot
+func (c *DispatchCollector) RecordAbandoned(ctx context.Context, priority string) {
+	c.abandonedCount.Add(ctx, 1, metric.WithAttributes(dispatchAttributes(priority)...))
+}
+
+func dispatchAttributes(priority string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("notification.priority", priority),
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewDispatchCollector(t *testing.T) {
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewDispatchCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			collector.RecordQueued(context.Background(), "high")
+		})
+	})
+}
+
+func TestDispatchCollector_Record(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewDispatchCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordQueued(ctx, "high")
+	collector.RecordQueued(ctx, "low")
+	collector.RecordDequeued(ctx, "high")
+	collector.RecordDispatched(ctx, "high", 20*time.Millisecond)
+	collector.RecordAbandoned(ctx, "low")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	found := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		found[m.Name] = m
+	}
+
+	depth, ok := found["notification.dispatch.queue_depth"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	depthByPriority := map[string]int64{}
+	for _, dp := range depth.DataPoints {
+		priority, _ := dp.Attributes.Value(attribute.Key("notification.priority"))
+		depthByPriority[priority.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"high": 0, "low": 1}, depthByPriority)
+
+	dispatched, ok := found["notification.dispatch.dispatched"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, dispatched.DataPoints, 1)
+	assert.Equal(t, int64(1), dispatched.DataPoints[0].Value)
+
+	wait, ok := found["notification.dispatch.wait_duration"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, wait.DataPoints, 1)
+	assert.InDelta(t, 0.02, wait.DataPoints[0].Sum, 1e-9)
+
+	abandoned, ok := found["notification.dispatch.abandoned"].Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, abandoned.DataPoints, 1)
+	assert.Equal(t, int64(1), abandoned.DataPoints[0].Value)
+}
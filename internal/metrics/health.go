@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// ProviderHealthSnapshot is the availability of one provider host as last
+// probed
+type ProviderHealthSnapshot struct {
+	Host    string
+	Healthy bool
+}
+
+type ProviderHealthCollector struct {
+	meter       metric.Meter
+	checkCount  metric.Int64Counter
+	healthGauge metric.Int64ObservableGauge
+}
+
+func NewProviderHealthCollector(meter metric.Meter) (*ProviderHealthCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	checkCount, err := meter.Int64Counter(
+		"provider.health_checks",
+		metric.WithDescription("Total provider health checks"),
+		metric.WithUnit("{check}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	healthGauge, err := meter.Int64ObservableGauge(
+		"provider.healthy",
+		metric.WithDescription("Provider availability as last probed (1=Healthy, 0=Unhealthy)"),
+		metric.WithUnit("{state}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProviderHealthCollector{
+		meter:       meter,
+		checkCount:  checkCount,
+		healthGauge: healthGauge,
+	}, nil
+}
+
+// RecordCheck records the outcome of one probe of host
+func (c *ProviderHealthCollector) RecordCheck(ctx context.Context, host string, healthy bool) {
+	outcome := "healthy"
+	if !healthy {
+		outcome = "unhealthy"
+	}
+
+	c.checkCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("http.host", host),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// ObserveProviderHealth registers a callback reporting the availability of
+// every host returned by snapshot on each collection
+func (c *ProviderHealthCollector) ObserveProviderHealth(
+	snapshot func() []ProviderHealthSnapshot,
+) (metric.Registration, error) {
+	return c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, provider := range snapshot() {
+			var healthy int64
+			if provider.Healthy {
+				healthy = 1
+			}
+			o.ObserveInt64(c.healthGauge, healthy, metric.WithAttributes(
+				attribute.String("http.host", provider.Host),
+			))
+		}
+		return nil
+	}, c.healthGauge)
+}
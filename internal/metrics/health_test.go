@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestProviderHealthCollector(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewProviderHealthCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordCheck(ctx, "https://email.example.com", true)
+	collector.RecordCheck(ctx, "https://push.example.com", false)
+	collector.RecordCheck(ctx, "https://push.example.com", false)
+
+	registration, err := collector.ObserveProviderHealth(func() []ProviderHealthSnapshot {
+		return []ProviderHealthSnapshot{
+			{Host: "https://email.example.com", Healthy: true},
+			{Host: "https://push.example.com", Healthy: false},
+		}
+	})
+	require.NoError(t, err)
+	defer registration.Unregister()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	checks := map[string]int64{}
+	healthy := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, dp := range data.DataPoints {
+				host, _ := dp.Attributes.Value(attribute.Key("http.host"))
+				outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+				checks[host.AsString()+"/"+outcome.AsString()] = dp.Value
+			}
+		case metricdata.Gauge[int64]:
+			for _, dp := range data.DataPoints {
+				host, _ := dp.Attributes.Value(attribute.Key("http.host"))
+				healthy[host.AsString()] = dp.Value
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]int64{
+		"https://email.example.com/healthy":  1,
+		"https://push.example.com/unhealthy": 2,
+	}, checks)
+	assert.Equal(t, map[string]int64{
+		"https://email.example.com": 1,
+		"https://push.example.com":  0,
+	}, healthy)
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -10,11 +11,26 @@ import (
 )
 
 type HTTPClientCollector struct {
+	meter                 metric.Meter
 	requestCount          metric.Int64Counter
 	requestDuration       metric.Float64Histogram
 	errorCount            metric.Int64Counter
-	circuitBreakerState   metric.Int64Gauge
+	circuitBreakerState   metric.Int64ObservableGauge
+	circuitBreakerCounts  metric.Int64ObservableGauge
 	circuitBreakerChanges metric.Int64Counter
+	injectedFaults        metric.Int64Counter
+}
+
+// CircuitBreakerSnapshot is the point-in-time view of a single breaker
+// reported on every collection
+type CircuitBreakerSnapshot struct {
+	Host                 string
+	State                string
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
 }
 
 func NewHTTPClientCollector(meter metric.Meter) (*HTTPClientCollector, error) {
@@ -50,7 +66,7 @@ func NewHTTPClientCollector(meter metric.Meter) (*HTTPClientCollector, error) {
 		return nil, err
 	}
 
-	circuitBreakerState, err := meter.Int64Gauge(
+	circuitBreakerState, err := meter.Int64ObservableGauge(
 		"http.client.circuit_breaker.state",
 		metric.WithDescription("Circuit breaker state (0=Closed, 1=Open, 2=HalfOpen)"),
 		metric.WithUnit("{state}"),
@@ -59,6 +75,15 @@ func NewHTTPClientCollector(meter metric.Meter) (*HTTPClientCollector, error) {
 		return nil, err
 	}
 
+	circuitBreakerCounts, err := meter.Int64ObservableGauge(
+		"http.client.circuit_breaker.counts",
+		metric.WithDescription("Circuit breaker request counts in the current generation"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	circuitBreakerChanges, err := meter.Int64Counter(
 		"http.client.circuit_breaker.state_changes",
 		metric.WithDescription("Circuit breaker state changes"),
@@ -68,12 +93,24 @@ func NewHTTPClientCollector(meter metric.Meter) (*HTTPClientCollector, error) {
 		return nil, err
 	}
 
+	injectedFaults, err := meter.Int64Counter(
+		"http.client.injected_faults",
+		metric.WithDescription("Faults injected into HTTP client requests"),
+		metric.WithUnit("{fault}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &HTTPClientCollector{
+		meter:                 meter,
 		requestCount:          requestCount,
 		requestDuration:       requestDuration,
 		errorCount:            errorCount,
 		circuitBreakerState:   circuitBreakerState,
+		circuitBreakerCounts:  circuitBreakerCounts,
 		circuitBreakerChanges: circuitBreakerChanges,
+		injectedFaults:        injectedFaults,
 	}, nil
 }
 
@@ -104,19 +141,34 @@ func (c *HTTPClientCollector) RecordRequest(
 	}
 }
 
-// RecordCircuitBreakerState records the current circuit breaker state
-func (c *HTTPClientCollector) RecordCircuitBreakerState(
-	ctx context.Context,
-	host string,
-	state string,
-) {
-	attrs := []attribute.KeyValue{
-		attribute.String("http.host", host),
-		attribute.String("circuit_breaker.state", state),
-	}
-
-	stateValue := circuitBreakerStateToInt(state)
-	c.circuitBreakerState.Record(ctx, stateValue, metric.WithAttributes(attrs...))
+// ObserveCircuitBreakers registers a callback reporting the state and counts
+// of every breaker returned by snapshot on each collection, so idle breakers
+// are still exported
+func (c *HTTPClientCollector) ObserveCircuitBreakers(
+	snapshot func() []CircuitBreakerSnapshot,
+) (metric.Registration, error) {
+	return c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, breaker := range snapshot() {
+			o.ObserveInt64(c.circuitBreakerState, circuitBreakerStateToInt(breaker.State), metric.WithAttributes(
+				attribute.String("http.host", breaker.Host),
+				attribute.String("circuit_breaker.state", breaker.State),
+			))
+
+			for name, value := range map[string]uint32{
+				"requests":              breaker.Requests,
+				"total_successes":       breaker.TotalSuccesses,
+				"total_failures":        breaker.TotalFailures,
+				"consecutive_successes": breaker.ConsecutiveSuccesses,
+				"consecutive_failures":  breaker.ConsecutiveFailures,
+			} {
+				o.ObserveInt64(c.circuitBreakerCounts, int64(value), metric.WithAttributes(
+					attribute.String("http.host", breaker.Host),
+					attribute.String("circuit_breaker.count", name),
+				))
+			}
+		}
+		return nil
+	}, c.circuitBreakerState, c.circuitBreakerCounts)
 }
 
 // RecordCircuitBreakerStateChange records circuit breaker state transitions
@@ -135,6 +187,14 @@ func (c *HTTPClientCollector) RecordCircuitBreakerStateChange(
 	c.circuitBreakerChanges.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
+// RecordInjectedFault records a fault injected into a request to the host
+func (c *HTTPClientCollector) RecordInjectedFault(ctx context.Context, host string, fault string) {
+	c.injectedFaults.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("http.host", host),
+		attribute.String("fault.type", fault),
+	))
+}
+
 // circuitBreakerStateToInt converts circuit breaker state to numeric value
 func circuitBreakerStateToInt(state string) int64 {
 	switch state {
@@ -149,12 +209,23 @@ func circuitBreakerStateToInt(state string) int64 {
 	}
 }
 
+// statusCodeError is implemented by errors describing a non-success response
+type statusCodeError interface {
+	error
+	HTTPStatusCode() int
+}
+
 // getErrorType extracts error type from error
 func getErrorType(err error) string {
 	if err == nil {
 		return "none"
 	}
 
+	var statusErr statusCodeError
+	if errors.As(err, &statusErr) {
+		return "invalid_status"
+	}
+
 	// Check for common error patterns
 	errMsg := err.Error()
 	switch {
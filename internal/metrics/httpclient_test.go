@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
@@ -26,6 +27,7 @@ func TestNewHTTPClientCollector(t *testing.T) {
 		assert.NotNil(t, collector.requestDuration)
 		assert.NotNil(t, collector.errorCount)
 		assert.NotNil(t, collector.circuitBreakerState)
+		assert.NotNil(t, collector.circuitBreakerCounts)
 		assert.NotNil(t, collector.circuitBreakerChanges)
 	})
 }
@@ -143,7 +145,7 @@ func TestHTTPClientCollector_RecordRequest(t *testing.T) {
 	}
 }
 
-func TestHTTPClientCollector_RecordCircuitBreakerState(t *testing.T) {
+func TestHTTPClientCollector_ObserveCircuitBreakers(t *testing.T) {
 	tests := []struct {
 		name  string
 		host  string
@@ -175,29 +177,56 @@ func TestHTTPClientCollector_RecordCircuitBreakerState(t *testing.T) {
 			collector, err := NewHTTPClientCollector(meter)
 			require.NoError(t, err)
 
-			ctx := context.Background()
-			collector.RecordCircuitBreakerState(ctx, tt.host, tt.state)
+			registration, err := collector.ObserveCircuitBreakers(func() []CircuitBreakerSnapshot {
+				return []CircuitBreakerSnapshot{
+					{
+						Host:                tt.host,
+						State:               tt.state,
+						Requests:            4,
+						TotalFailures:       3,
+						ConsecutiveFailures: 2,
+					},
+				}
+			})
+			require.NoError(t, err)
+			defer registration.Unregister()
 
 			// Collect metrics
+			ctx := context.Background()
 			var rm metricdata.ResourceMetrics
 			err = reader.Collect(ctx, &rm)
 			require.NoError(t, err)
 
-			// Verify circuit breaker state metric
+			// Verify circuit breaker metrics
 			require.NotEmpty(t, rm.ScopeMetrics)
 			metrics := rm.ScopeMetrics[0].Metrics
 
-			var found bool
+			found := map[string]bool{}
 			for _, m := range metrics {
-				if m.Name == "http.client.circuit_breaker.state" {
-					found = true
+				found[m.Name] = true
+
+				switch m.Name {
+				case "http.client.circuit_breaker.state":
+					gauge := m.Data.(metricdata.Gauge[int64])
+					require.Len(t, gauge.DataPoints, 1)
+					assert.Equal(t, circuitBreakerStateToInt(tt.state), gauge.DataPoints[0].Value)
+				case "http.client.circuit_breaker.counts":
 					gauge := m.Data.(metricdata.Gauge[int64])
-					assert.Len(t, gauge.DataPoints, 1)
-					expectedValue := circuitBreakerStateToInt(tt.state)
-					assert.Equal(t, expectedValue, gauge.DataPoints[0].Value)
+					require.Len(t, gauge.DataPoints, 5)
+
+					counts := map[string]int64{}
+					for _, dp := range gauge.DataPoints {
+						name, _ := dp.Attributes.Value(attribute.Key("circuit_breaker.count"))
+						counts[name.AsString()] = dp.Value
+					}
+					assert.Equal(t, int64(4), counts["requests"])
+					assert.Equal(t, int64(3), counts["total_failures"])
+					assert.Equal(t, int64(2), counts["consecutive_failures"])
+					assert.Equal(t, int64(0), counts["total_successes"])
 				}
 			}
-			assert.True(t, found, "circuit breaker state metric should be recorded")
+			assert.True(t, found["http.client.circuit_breaker.state"], "circuit breaker state metric should be observed")
+			assert.True(t, found["http.client.circuit_breaker.counts"], "circuit breaker counts metric should be observed")
 		})
 	}
 }
@@ -264,6 +293,34 @@ func TestHTTPClientCollector_RecordCircuitBreakerStateChange(t *testing.T) {
 	}
 }
 
+func TestHTTPClientCollector_RecordInjectedFault(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	meter := provider.Meter("test")
+
+	collector, err := NewHTTPClientCollector(meter)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordInjectedFault(ctx, "api.example.com", "error")
+	collector.RecordInjectedFault(ctx, "api.example.com", "error")
+	collector.RecordInjectedFault(ctx, "api.example.com", "latency")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	var found bool
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name == "http.client.injected_faults" {
+			found = true
+			sum := m.Data.(metricdata.Sum[int64])
+			assert.Len(t, sum.DataPoints, 2, "one data point per fault type")
+		}
+	}
+	assert.True(t, found, "injected fault metric should be recorded")
+}
+
 func TestCircuitBreakerStateToInt(t *testing.T) {
 	tests := []struct {
 		state    string
@@ -300,6 +357,11 @@ func TestGetErrorType(t *testing.T) {
 			err:      errors.New("response status code not equal 200"),
 			expected: "invalid_status",
 		},
+		{
+			name:     "status code error",
+			err:      testStatusCodeError{statusCode: 503},
+			expected: "invalid_status",
+		},
 		{
 			name:     "circuit breaker open error",
 			err:      errors.New("circuit breaker is open"),
@@ -334,7 +396,8 @@ func TestNoopHTTPClientCollector(t *testing.T) {
 		})
 
 		assert.NotPanics(t, func() {
-			collector.RecordCircuitBreakerState(ctx, "api.example.com", "closed")
+			_, err := collector.ObserveCircuitBreakers(func() []CircuitBreakerSnapshot { return nil })
+			assert.NoError(t, err)
 		})
 
 		assert.NotPanics(t, func() {
@@ -356,3 +419,11 @@ func TestNewHTTPClientCollectorWithNilMeter(t *testing.T) {
 		})
 	})
 }
+
+type testStatusCodeError struct {
+	statusCode int
+}
+
+func (e testStatusCodeError) Error() string { return "provider responded with an error" }
+
+func (e testStatusCodeError) HTTPStatusCode() int { return e.statusCode }
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,8 +10,14 @@ import (
 )
 
 type HTTPServerCollector struct {
-	requestCount    metric.Int64Counter
-	requestDuration metric.Float64Histogram
+	requestCount     metric.Int64Counter
+	requestDuration  metric.Float64Histogram
+	inFlightRequests metric.Int64UpDownCounter
+	requestSize      metric.Int64Histogram
+	responseSize     metric.Int64Histogram
+	shedRequests     metric.Int64Counter
+	concurrencyLimit metric.Int64Gauge
+	rateLimited      metric.Int64Counter
 }
 
 func NewHTTPServerCollector(meter metric.Meter) (*HTTPServerCollector, error) {
@@ -32,12 +39,95 @@ func NewHTTPServerCollector(meter metric.Meter) (*HTTPServerCollector, error) {
 		return nil, err
 	}
 
+	inFlightRequests, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Number of in-flight HTTP requests"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	requestSize, err := meter.Int64Histogram(
+		"http.server.request.size",
+		metric.WithDescription("HTTP request body size"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	responseSize, err := meter.Int64Histogram(
+		"http.server.response.size",
+		metric.WithDescription("HTTP response body size"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	shedRequests, err := meter.Int64Counter(
+		"http.server.shed_requests",
+		metric.WithDescription("Requests rejected by load shedding"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrencyLimit, err := meter.Int64Gauge(
+		"http.server.concurrency_limit",
+		metric.WithDescription("Adaptive limit of concurrent requests before load shedding"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimited, err := meter.Int64Counter(
+		"http.server.rate_limited_requests",
+		metric.WithDescription("Requests rejected by the client IP or API key rate limit"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &HTTPServerCollector{
-		requestCount:    requestCount,
-		requestDuration: requestDuration,
+		requestCount:     requestCount,
+		requestDuration:  requestDuration,
+		inFlightRequests: inFlightRequests,
+		requestSize:      requestSize,
+		responseSize:     responseSize,
+		shedRequests:     shedRequests,
+		concurrencyLimit: concurrencyLimit,
+		rateLimited:      rateLimited,
 	}, nil
 }
 
+// RecordShed counts a request of the route rejected as overload
+func (m *HTTPServerCollector) RecordShed(ctx context.Context, route string) {
+	m.shedRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("http.route", route),
+	))
+}
+
+// RecordConcurrencyLimit reports the current concurrency limit of the route
+func (m *HTTPServerCollector) RecordConcurrencyLimit(ctx context.Context, route string, limit int) {
+	m.concurrencyLimit.Record(ctx, int64(limit), metric.WithAttributes(
+		attribute.String("http.route", route),
+	))
+}
+
+// RecordRateLimited counts a request of the route rejected by the rate
+// limit of scope, ip or key
+func (m *HTTPServerCollector) RecordRateLimited(ctx context.Context, route string, scope string) {
+	m.rateLimited.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("http.route", route),
+		attribute.String("scope", scope),
+	))
+}
+
 func (m *HTTPServerCollector) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -46,6 +136,15 @@ func (m *HTTPServerCollector) Middleware() gin.HandlerFunc {
 			path = c.Request.URL.Path
 		}
 
+		// In-flight requests only carry low-cardinality attributes since the
+		// status code is not known until the handler returns
+		inFlightAttrs := metric.WithAttributes(
+			attribute.String("http.method", c.Request.Method),
+			attribute.String("http.route", path),
+		)
+		m.inFlightRequests.Add(c.Request.Context(), 1, inFlightAttrs)
+		defer m.inFlightRequests.Add(c.Request.Context(), -1, inFlightAttrs)
+
 		c.Next()
 
 		duration := time.Since(start)
@@ -61,5 +160,7 @@ func (m *HTTPServerCollector) Middleware() gin.HandlerFunc {
 
 		m.requestCount.Add(ctx, 1, metric.WithAttributes(attrs...))
 		m.requestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+		m.requestSize.Record(ctx, max(c.Request.ContentLength, 0), metric.WithAttributes(attrs...))
+		m.responseSize.Record(ctx, int64(max(c.Writer.Size(), 0)), metric.WithAttributes(attrs...))
 	}
 }
@@ -1,8 +1,10 @@
 package metrics
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,11 @@ func TestNewHTTPServerCollector(t *testing.T) {
 		assert.NotNil(t, collector)
 		assert.NotNil(t, collector.requestCount)
 		assert.NotNil(t, collector.requestDuration)
+		assert.NotNil(t, collector.inFlightRequests)
+		assert.NotNil(t, collector.requestSize)
+		assert.NotNil(t, collector.responseSize)
+		assert.NotNil(t, collector.shedRequests)
+		assert.NotNil(t, collector.concurrencyLimit)
 	})
 }
 
@@ -579,3 +586,134 @@ func TestHTTPServerCollector_Middleware_MultipleStatusCodes(t *testing.T) {
 		assert.Equal(t, int64(3), totalRequests, "should track all 3 requests")
 	})
 }
+
+func TestHTTPServerCollector_Middleware_InFlightAndSizes(t *testing.T) {
+	// Setup metrics
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	meter := provider.Meter("test")
+
+	collector, err := NewHTTPServerCollector(meter)
+	require.NoError(t, err)
+
+	// Setup Gin
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(collector.Middleware())
+
+	var inFlightDuringRequest int64
+	router.POST("/api/echo", func(c *gin.Context) {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(c.Request.Context(), &rm))
+		for _, m := range rm.ScopeMetrics[0].Metrics {
+			if m.Name == "http.server.active_requests" {
+				sum := m.Data.(metricdata.Sum[int64])
+				inFlightDuringRequest = sum.DataPoints[0].Value
+			}
+		}
+		c.String(http.StatusOK, "pong")
+	})
+
+	// Make request
+	req := httptest.NewRequest("POST", "/api/echo", strings.NewReader("ping-ping"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), inFlightDuringRequest, "request should be counted while in flight")
+
+	// Collect metrics
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(req.Context(), &rm)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, rm.ScopeMetrics)
+	metricsData := rm.ScopeMetrics[0].Metrics
+
+	found := map[string]bool{}
+	for _, m := range metricsData {
+		switch m.Name {
+		case "http.server.active_requests":
+			found[m.Name] = true
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			assert.Equal(t, int64(0), sum.DataPoints[0].Value, "in-flight gauge should return to zero")
+		case "http.server.request.size":
+			found[m.Name] = true
+			hist := m.Data.(metricdata.Histogram[int64])
+			require.Len(t, hist.DataPoints, 1)
+			assert.Equal(t, int64(len("ping-ping")), hist.DataPoints[0].Sum)
+		case "http.server.response.size":
+			found[m.Name] = true
+			hist := m.Data.(metricdata.Histogram[int64])
+			require.Len(t, hist.DataPoints, 1)
+			assert.Equal(t, int64(len("pong")), hist.DataPoints[0].Sum)
+		}
+	}
+	assert.True(t, found["http.server.active_requests"], "in-flight metric should be recorded")
+	assert.True(t, found["http.server.request.size"], "request size metric should be recorded")
+	assert.True(t, found["http.server.response.size"], "response size metric should be recorded")
+}
+
+func TestHTTPServerCollector_LoadShedding(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewHTTPServerCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordShed(ctx, "/notify")
+	collector.RecordShed(ctx, "/notify")
+	collector.RecordConcurrencyLimit(ctx, "/notify", 120)
+	collector.RecordConcurrencyLimit(ctx, "/notify", 108)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	found := map[string]bool{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch m.Name {
+		case "http.server.shed_requests":
+			found[m.Name] = true
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			assert.Equal(t, int64(2), sum.DataPoints[0].Value)
+		case "http.server.concurrency_limit":
+			found[m.Name] = true
+			gauge := m.Data.(metricdata.Gauge[int64])
+			require.Len(t, gauge.DataPoints, 1)
+			assert.Equal(t, int64(108), gauge.DataPoints[0].Value)
+		}
+	}
+	assert.True(t, found["http.server.shed_requests"])
+	assert.True(t, found["http.server.concurrency_limit"])
+}
+
+func TestHTTPServerCollector_RecordRateLimited(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewHTTPServerCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordRateLimited(ctx, "/notify", "ip")
+	collector.RecordRateLimited(ctx, "/notify", "ip")
+	collector.RecordRateLimited(ctx, "/notify", "key")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+
+	values := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "http.server.rate_limited_requests" {
+			continue
+		}
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			scope, _ := dp.Attributes.Value("scope")
+			values[scope.AsString()] = dp.Value
+		}
+	}
+	assert.Equal(t, map[string]int64{"ip": 2, "key": 1}, values)
+}
@@ -3,28 +3,76 @@ package metrics
 import (
 	"context"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/buildinfo"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/fx"
 )
 
-func NewMeterProvider() (*sdkmetric.MeterProvider, error) {
+func NewMeterProvider(config MetricConfig) (*sdkmetric.MeterProvider, error) {
 	exporter, err := prometheus.New()
 	if err != nil {
 		return nil, err
 	}
 
+	res, err := newResource(config)
+	if err != nil {
+		return nil, err
+	}
+
 	provider := sdkmetric.NewMeterProvider(
 		sdkmetric.WithReader(exporter),
+		sdkmetric.WithResource(res),
 	)
 
+	// Go runtime metrics (goroutines, GC, memory) share the same exporter,
+	// so they are surfaced on the existing /metrics endpoint
+	if err := runtime.Start(runtime.WithMeterProvider(provider)); err != nil {
+		return nil, err
+	}
+
 	otel.SetMeterProvider(provider)
 	return provider, nil
 }
 
+func newResource(config MetricConfig) (*resource.Resource, error) {
+	build := buildinfo.Get()
+
+	return resource.New(
+		context.Background(),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(
+			semconv.ServiceName(config.AppName),
+			semconv.ServiceVersion(serviceVersion(config)),
+			semconv.DeploymentEnvironmentName(config.Environment),
+			semconv.VCSRefHeadRevision(build.Commit),
+			attribute.String(attributeBuildTime, build.BuildTime),
+		),
+	)
+}
+
+// attributeBuildTime is the resource attribute holding when the service was
+// built, which has no semantic convention
+const attributeBuildTime = "service.build.time"
+
+// serviceVersion prefers the configured version and falls back to the
+// version of the build
+func serviceVersion(config MetricConfig) string {
+	if config.Version != "" {
+		return config.Version
+	}
+	return buildinfo.Get().Version
+}
+
 type MetricParams struct {
 	fx.In
 
@@ -43,12 +91,7 @@ func NewMetric(lc fx.Lifecycle, params MetricParams) (metric.Meter, error) {
 }
 
 type MetricConfig struct {
-	AppName string `envconfig:"APP_NAME" default:"myapp"`
-}
-
-func NewMetricConfig() MetricConfig {
-	var cfg MetricConfig
-	envconfig.MustProcess("", &cfg)
-
-	return cfg
+	AppName     string `envconfig:"APP_NAME" default:"myapp"`
+	Environment string `envconfig:"APP_ENV" default:"development"`
+	Version     string `envconfig:"APP_VERSION"`
 }
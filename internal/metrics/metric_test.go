@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestNewResource(t *testing.T) {
+	t.Run("sets service metadata attributes", func(t *testing.T) {
+		res, err := newResource(MetricConfig{
+			AppName:     "notification-service",
+			Environment: "staging",
+			Version:     "v1.2.3",
+		})
+		require.NoError(t, err)
+
+		attrs := res.Set()
+
+		name, ok := attrs.Value(attribute.Key("service.name"))
+		assert.True(t, ok)
+		assert.Equal(t, "notification-service", name.AsString())
+
+		version, ok := attrs.Value(attribute.Key("service.version"))
+		assert.True(t, ok)
+		assert.Equal(t, "v1.2.3", version.AsString())
+
+		env, ok := attrs.Value(attribute.Key("deployment.environment.name"))
+		assert.True(t, ok)
+		assert.Equal(t, "staging", env.AsString())
+
+		_, ok = attrs.Value(attribute.Key("host.name"))
+		assert.True(t, ok)
+
+		for _, key := range []string{"vcs.ref.head.revision", "service.build.time"} {
+			_, ok = attrs.Value(attribute.Key(key))
+			assert.True(t, ok, key)
+		}
+	})
+
+	t.Run("falls back to build info version", func(t *testing.T) {
+		version := serviceVersion(MetricConfig{})
+
+		assert.NotEmpty(t, version)
+	})
+}
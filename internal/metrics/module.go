@@ -6,10 +6,16 @@ var Module = fx.Module("metric",
 	fx.Provide(
 		NewMeterProvider,
 		NewMetric,
-		NewMetricConfig,
 	),
 	httpCollectorModule,
 	httpclientCollectorModule,
+	notificationCollectorModule,
+	dispatchCollectorModule,
+	authorizationCollectorModule,
+	providerHealthCollectorModule,
+	receiptCollectorModule,
+	realtimeCollectorModule,
+	quotaCollectorModule,
 )
 
 var httpCollectorModule = fx.Provide(
@@ -19,3 +25,31 @@ var httpCollectorModule = fx.Provide(
 var httpclientCollectorModule = fx.Provide(
 	NewHTTPClientCollector,
 )
+
+var notificationCollectorModule = fx.Provide(
+	NewNotificationCollector,
+)
+
+var dispatchCollectorModule = fx.Provide(
+	NewDispatchCollector,
+)
+
+var authorizationCollectorModule = fx.Provide(
+	NewAuthorizationCollector,
+)
+
+var providerHealthCollectorModule = fx.Provide(
+	NewProviderHealthCollector,
+)
+
+var receiptCollectorModule = fx.Provide(
+	NewReceiptCollector,
+)
+
+var realtimeCollectorModule = fx.Provide(
+	NewRealtimeCollector,
+)
+
+var quotaCollectorModule = fx.Provide(
+	NewQuotaCollector,
+)
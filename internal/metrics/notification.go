@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type NotificationCollector struct {
+	attemptCount  metric.Int64Counter
+	successCount  metric.Int64Counter
+	failureCount  metric.Int64Counter
+	fallbackDepth metric.Int64Histogram
+	suppressed    metric.Int64Counter
+	optedOut      metric.Int64Counter
+	unconfigured  metric.Int64Counter
+	digested      metric.Int64Counter
+	digestItems   metric.Int64Histogram
+	cost          metric.Float64Counter
+	channelDown   metric.Int64Counter
+	crossRegion   metric.Int64Counter
+	moderated     metric.Int64Counter
+	events        metric.Int64Counter
+}
+
+func NewNotificationCollector(meter metric.Meter) (*NotificationCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+	attemptCount, err := meter.Int64Counter(
+		"notification.attempts",
+		metric.WithDescription("Total notification attempts per provider"),
+		metric.WithUnit("{attempt}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	successCount, err := meter.Int64Counter(
+		"notification.successes",
+		metric.WithDescription("Total notifications sent successfully"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	failureCount, err := meter.Int64Counter(
+		"notification.failures",
+		metric.WithDescription("Total failed notification attempts per provider"),
+		metric.WithUnit("{attempt}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackDepth, err := meter.Int64Histogram(
+		"notification.fallback_depth",
+		metric.WithDescription("Index of the preference that delivered the notification (0=primary)"),
+		metric.WithUnit("{preference}"),
+		metric.WithExplicitBucketBoundaries(0, 1, 2, 3, 5, 10),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed, err := meter.Int64Counter(
+		"notification.suppressed",
+		metric.WithDescription("Total channels skipped because the recipient address is suppressed"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	optedOut, err := meter.Int64Counter(
+		"notification.opted_out",
+		metric.WithDescription("Total channels skipped because the recipient opted out of the category"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	unconfigured, err := meter.Int64Counter(
+		"notification.unconfigured",
+		metric.WithDescription("Total optional channels skipped because none of their providers is configured"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	digested, err := meter.Int64Counter(
+		"notification.digested",
+		metric.WithDescription("Total notifications buffered for the digest of their recipient"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	digestItems, err := meter.Int64Histogram(
+		"notification.digest.items",
+		metric.WithDescription("Notifications combined into one sent digest"),
+		metric.WithUnit("{notification}"),
+		metric.WithExplicitBucketBoundaries(1, 2, 5, 10, 25, 50, 100),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := meter.Float64Counter(
+		"notification.cost",
+		metric.WithDescription("Estimated price of the notifications accepted by providers, in COST_CURRENCY"),
+		metric.WithUnit("{currency}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	channelDown, err := meter.Int64Counter(
+		"notification.channel.down",
+		metric.WithDescription("Total notifications no provider of their channel accepted"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	crossRegion, err := meter.Int64Counter(
+		"notification.cross_region",
+		metric.WithDescription("Total notifications delivered by a provider outside the region of the instance"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	moderated, err := meter.Int64Counter(
+		"notification.moderated",
+		metric.WithDescription("Total notifications redacted or rejected by a pre-send hook"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := meter.Int64Counter(
+		"notification.events",
+		metric.WithDescription("Total notification lifecycle events by type"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationCollector{
+		attemptCount:  attemptCount,
+		successCount:  successCount,
+		failureCount:  failureCount,
+		fallbackDepth: fallbackDepth,
+		suppressed:    suppressed,
+		optedOut:      optedOut,
+		unconfigured:  unconfigured,
+		digested:      digested,
+		digestItems:   digestItems,
+		cost:          cost,
+		channelDown:   channelDown,
+		crossRegion:   crossRegion,
+		moderated:     moderated,
+		events:        events,
+	}, nil
+}
+
+// RecordAttempt records a notification attempt against a provider host
+func (c *NotificationCollector) RecordAttempt(
+	ctx context.Context,
+	recipientType string,
+	channel string,
+	host string,
+	region string,
+) {
+	attrs := notificationAttributes(recipientType, channel, host, region)
+
+	c.attemptCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// RecordSuccess records a delivered notification and the fallback depth it needed
+func (c *NotificationCollector) RecordSuccess(
+	ctx context.Context,
+	recipientType string,
+	channel string,
+	host string,
+	region string,
+	depth int,
+) {
+	attrs := notificationAttributes(recipientType, channel, host, region)
+
+	c.successCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+	c.fallbackDepth.Record(ctx, int64(depth), metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+	))
+}
+
+// RecordFailure records a failed notification attempt against a provider host
+func (c *NotificationCollector) RecordFailure(
+	ctx context.Context,
+	recipientType string,
+	channel string,
+	host string,
+	region string,
+) {
+	attrs := notificationAttributes(recipientType, channel, host, region)
+
+	c.failureCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// RecordSuppressed records a channel skipped because the recipient address
+// is suppressed
+func (c *NotificationCollector) RecordSuppressed(ctx context.Context, recipientType string, channel string) {
+	c.suppressed.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+	))
+}
+
+// RecordOptedOut records a channel skipped because the recipient opted out
+// of the category
+func (c *NotificationCollector) RecordOptedOut(ctx context.Context, recipientType string, channel string, category string) {
+	c.optedOut.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+		attribute.String("notification.category", category),
+	))
+}
+
+// RecordUnconfigured records an optional channel skipped because none of
+// its providers is configured
+func (c *NotificationCollector) RecordUnconfigured(ctx context.Context, recipientType string, channel string) {
+	c.unconfigured.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+	))
+}
+
+// RecordDigested records a notification buffered for the digest of its
+// recipient
+func (c *NotificationCollector) RecordDigested(ctx context.Context, recipientType string) {
+	c.digested.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+	))
+}
+
+// RecordDigestSent records a digest sent and the notifications it combined
+func (c *NotificationCollector) RecordDigestSent(ctx context.Context, recipientType string, items int) {
+	c.digestItems.Record(ctx, int64(items), metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+	))
+}
+
+// RecordCost records the estimated price of a notification a provider
+// accepted, attributed to the tenant that sent it
+func (c *NotificationCollector) RecordCost(ctx context.Context, tenant string, channel string, providerName string, cost float64) {
+	c.cost.Add(ctx, cost, metric.WithAttributes(
+		attribute.String("notification.tenant", tenant),
+		attribute.String("notification.channel", channel),
+		attribute.String("provider.name", providerName),
+	))
+}
+
+// RecordChannelDown records a notification every provider of its channel
+// failed
+func (c *NotificationCollector) RecordChannelDown(ctx context.Context, recipientType string, channel string) {
+	c.channelDown.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+	))
+}
+
+// RecordCrossRegion records a notification the providers of the region of
+// the instance failed, delivered by a provider of another region
+func (c *NotificationCollector) RecordCrossRegion(ctx context.Context, recipientType string, channel string, region string) {
+	c.crossRegion.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+		attribute.String("notification.region", region),
+	))
+}
+
+// RecordModerated records a notification a pre-send hook redacted or
+// rejected
+func (c *NotificationCollector) RecordModerated(ctx context.Context, recipientType string, hook string, action string) {
+	c.moderated.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.hook", hook),
+		attribute.String("notification.action", action),
+	))
+}
+
+// notificationAttributes builds the common attribute set for notification
+// metrics; region is empty for hosts without one
+func notificationAttributes(recipientType string, channel string, host string, region string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("notification.recipient_type", recipientType),
+		attribute.String("notification.channel", channel),
+		attribute.String("notification.host", host),
+		attribute.String("notification.region", region),
+	}
+}
+
+// RecordEvent records a lifecycle event of a notification, e.g. accepted,
+// sent or failed
+func (c *NotificationCollector) RecordEvent(ctx context.Context, eventType string, recipientType string) {
+	c.events.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("notification.event", eventType),
+		attribute.String("notification.recipient_type", recipientType),
+	))
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewNotificationCollector(t *testing.T) {
+	t.Run("creates collector with all metrics", func(t *testing.T) {
+		reader := metric.NewManualReader()
+		provider := metric.NewMeterProvider(metric.WithReader(reader))
+		meter := provider.Meter("test")
+
+		collector, err := NewNotificationCollector(meter)
+
+		require.NoError(t, err)
+		assert.NotNil(t, collector)
+		assert.NotNil(t, collector.attemptCount)
+		assert.NotNil(t, collector.successCount)
+		assert.NotNil(t, collector.failureCount)
+		assert.NotNil(t, collector.fallbackDepth)
+		assert.NotNil(t, collector.suppressed)
+		assert.NotNil(t, collector.optedOut)
+		assert.NotNil(t, collector.digested)
+		assert.NotNil(t, collector.digestItems)
+		assert.NotNil(t, collector.cost)
+		assert.NotNil(t, collector.channelDown)
+		assert.NotNil(t, collector.crossRegion)
+	})
+
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewNotificationCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotNil(t, collector)
+		assert.NotPanics(t, func() {
+			collector.RecordAttempt(context.Background(), "buyer", "Email", "email.example.com", "")
+		})
+	})
+}
+
+func TestNotificationCollector_Record(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	meter := provider.Meter("test")
+
+	collector, err := NewNotificationCollector(meter)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordAttempt(ctx, "seller", "Email", "primary.example.com", "ap-southeast-1")
+	collector.RecordFailure(ctx, "seller", "Email", "primary.example.com", "ap-southeast-1")
+	collector.RecordAttempt(ctx, "seller", "Email", "secondary.example.com", "us-east-1")
+	collector.RecordSuccess(ctx, "seller", "Email", "secondary.example.com", "us-east-1", 1)
+	collector.RecordCrossRegion(ctx, "seller", "Email", "us-east-1")
+	collector.RecordModerated(ctx, "buyer", "scrubber", "redacted")
+	collector.RecordSuppressed(ctx, "buyer", "Email")
+	collector.RecordOptedOut(ctx, "buyer", "PushNotification", "marketing")
+	collector.RecordUnconfigured(ctx, "seller", "PushNotification")
+	collector.RecordDigested(ctx, "seller")
+	collector.RecordDigestSent(ctx, "seller", 3)
+	collector.RecordCost(ctx, "acme", "Email", "Provider1", 0.0008)
+	collector.RecordCost(ctx, "acme", "Email", "Provider1", 0.0008)
+	collector.RecordChannelDown(ctx, "seller", "PushNotification")
+	collector.RecordEvent(ctx, "notification.sent", "seller")
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(ctx, &rm)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, rm.ScopeMetrics)
+	metrics := rm.ScopeMetrics[0].Metrics
+
+	found := map[string]bool{}
+	for _, m := range metrics {
+		found[m.Name] = true
+
+		switch m.Name {
+		case "notification.attempts":
+			sum := m.Data.(metricdata.Sum[int64])
+			assert.Len(t, sum.DataPoints, 2)
+		case "notification.failures":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			host, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.host"))
+			assert.True(t, ok)
+			assert.Equal(t, "primary.example.com", host.AsString())
+			region, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.region"))
+			assert.True(t, ok)
+			assert.Equal(t, "ap-southeast-1", region.AsString())
+		case "notification.cross_region":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			region, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.region"))
+			assert.True(t, ok)
+			assert.Equal(t, "us-east-1", region.AsString())
+		case "notification.moderated":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			action, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.action"))
+			assert.True(t, ok)
+			assert.Equal(t, "redacted", action.AsString())
+		case "notification.successes":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+			recipient, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.recipient_type"))
+			assert.True(t, ok)
+			assert.Equal(t, "seller", recipient.AsString())
+		case "notification.fallback_depth":
+			hist := m.Data.(metricdata.Histogram[int64])
+			require.Len(t, hist.DataPoints, 1)
+			assert.Equal(t, int64(1), hist.DataPoints[0].Sum)
+		case "notification.suppressed":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			channel, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.channel"))
+			assert.True(t, ok)
+			assert.Equal(t, "Email", channel.AsString())
+		case "notification.opted_out":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			category, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.category"))
+			assert.True(t, ok)
+			assert.Equal(t, "marketing", category.AsString())
+		case "notification.unconfigured":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			channel, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.channel"))
+			assert.True(t, ok)
+			assert.Equal(t, "PushNotification", channel.AsString())
+		case "notification.digest.items":
+			hist := m.Data.(metricdata.Histogram[int64])
+			require.Len(t, hist.DataPoints, 1)
+			assert.Equal(t, int64(3), hist.DataPoints[0].Sum)
+		case "notification.cost":
+			sum := m.Data.(metricdata.Sum[float64])
+			require.Len(t, sum.DataPoints, 1)
+			assert.InDelta(t, 0.0016, sum.DataPoints[0].Value, 1e-9)
+			tenant, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.tenant"))
+			assert.True(t, ok)
+			assert.Equal(t, "acme", tenant.AsString())
+		case "notification.events":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			eventType, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.event"))
+			assert.True(t, ok)
+			assert.Equal(t, "notification.sent", eventType.AsString())
+		case "notification.channel.down":
+			sum := m.Data.(metricdata.Sum[int64])
+			require.Len(t, sum.DataPoints, 1)
+			channel, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("notification.channel"))
+			assert.True(t, ok)
+			assert.Equal(t, "PushNotification", channel.AsString())
+		}
+	}
+
+	assert.True(t, found["notification.attempts"], "attempt metric should be recorded")
+	assert.True(t, found["notification.successes"], "success metric should be recorded")
+	assert.True(t, found["notification.failures"], "failure metric should be recorded")
+	assert.True(t, found["notification.fallback_depth"], "fallback depth metric should be recorded")
+	assert.True(t, found["notification.suppressed"], "suppressed metric should be recorded")
+	assert.True(t, found["notification.opted_out"], "opted out metric should be recorded")
+	assert.True(t, found["notification.unconfigured"], "unconfigured metric should be recorded")
+	assert.True(t, found["notification.digested"], "digested metric should be recorded")
+	assert.True(t, found["notification.digest.items"], "digest items metric should be recorded")
+	assert.True(t, found["notification.cost"], "cost metric should be recorded")
+	assert.True(t, found["notification.channel.down"], "channel down metric should be recorded")
+	assert.True(t, found["notification.cross_region"], "cross region metric should be recorded")
+	assert.True(t, found["notification.moderated"], "moderated metric should be recorded")
+	assert.True(t, found["notification.events"], "event metric should be recorded")
+}
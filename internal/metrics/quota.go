@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type QuotaCollector struct {
+	consumedCount metric.Int64Counter
+	exceededCount metric.Int64Counter
+}
+
+func NewQuotaCollector(meter metric.Meter) (*QuotaCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	consumedCount, err := meter.Int64Counter(
+		"notification.quota.consumed",
+		metric.WithDescription("Total notifications counted against the quotas of a tenant or API key"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exceededCount, err := meter.Int64Counter(
+		"notification.quota.exceeded",
+		metric.WithDescription("Total notifications refused because a quota was used up"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuotaCollector{
+		consumedCount: consumedCount,
+		exceededCount: exceededCount,
+	}, nil
+}
+
+// RecordConsumed records a notification counted against the quotas of the
+// subject, a tenant or an API key id
+func (c *QuotaCollector) RecordConsumed(ctx context.Context, subject string) {
+	c.consumedCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("quota.subject", subject),
+	))
+}
+
+// RecordExceeded records a notification refused because the quota of the
+// period, day or month, was used up
+func (c *QuotaCollector) RecordExceeded(ctx context.Context, subject string, period string) {
+	c.exceededCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("quota.subject", subject),
+		attribute.String("quota.period", period),
+	))
+}
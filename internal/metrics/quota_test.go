@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewQuotaCollector(t *testing.T) {
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewQuotaCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			collector.RecordConsumed(context.Background(), "acme")
+			collector.RecordExceeded(context.Background(), "acme", "day")
+		})
+	})
+}
+
+func TestQuotaCollector_Record(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewQuotaCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordConsumed(ctx, "acme")
+	collector.RecordConsumed(ctx, "acme")
+	collector.RecordExceeded(ctx, "acme", "month")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	found := map[string]bool{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		found[m.Name] = true
+		sum := m.Data.(metricdata.Sum[int64])
+		require.Len(t, sum.DataPoints, 1)
+
+		switch m.Name {
+		case "notification.quota.consumed":
+			assert.Equal(t, int64(2), sum.DataPoints[0].Value)
+		case "notification.quota.exceeded":
+			period, ok := sum.DataPoints[0].Attributes.Value(attribute.Key("quota.period"))
+			assert.True(t, ok)
+			assert.Equal(t, "month", period.AsString())
+		}
+	}
+
+	assert.True(t, found["notification.quota.consumed"], "consumed metric should be recorded")
+	assert.True(t, found["notification.quota.exceeded"], "exceeded metric should be recorded")
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// Reasons a realtime connection ends
+const (
+	// RealtimeClosedByClient connections were closed by the client or its
+	// request context ended
+	RealtimeClosedByClient = "client"
+	// RealtimeClosedSlowConsumer connections fell a full buffer behind and
+	// were dropped so publishing never blocks
+	RealtimeClosedSlowConsumer = "slow_consumer"
+	// RealtimeClosedShutdown connections were closed by the server stopping
+	RealtimeClosedShutdown = "shutdown"
+)
+
+type RealtimeCollector struct {
+	connections        metric.Int64UpDownCounter
+	rejectionCount     metric.Int64Counter
+	messageCount       metric.Int64Counter
+	connectionDuration metric.Float64Histogram
+	connectionMessages metric.Int64Histogram
+}
+
+func NewRealtimeCollector(meter metric.Meter) (*RealtimeCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	connections, err := meter.Int64UpDownCounter(
+		"notification.realtime.connections",
+		metric.WithDescription("Open realtime connections receiving in-app notifications"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rejectionCount, err := meter.Int64Counter(
+		"notification.realtime.rejections",
+		metric.WithDescription("Total realtime connections refused because the recipient had too many open"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	messageCount, err := meter.Int64Counter(
+		"notification.realtime.messages",
+		metric.WithDescription("Total in-app notifications pushed to realtime connections"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionDuration, err := meter.Float64Histogram(
+		"notification.realtime.connection.duration",
+		metric.WithDescription("Time realtime connections stayed open"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1, 10, 60, 300, 900, 1800, 3600, 14400),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionMessages, err := meter.Int64Histogram(
+		"notification.realtime.connection.messages",
+		metric.WithDescription("In-app notifications pushed over one realtime connection"),
+		metric.WithUnit("{notification}"),
+		metric.WithExplicitBucketBoundaries(0, 1, 5, 10, 50, 100, 500),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RealtimeCollector{
+		connections:        connections,
+		rejectionCount:     rejectionCount,
+		messageCount:       messageCount,
+		connectionDuration: connectionDuration,
+		connectionMessages: connectionMessages,
+	}, nil
+}
+
+// RecordOpened records a connection of the transport, sse or websocket,
+// starting to receive notifications
+func (c *RealtimeCollector) RecordOpened(ctx context.Context, transport string) {
+	c.connections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("realtime.transport", transport),
+	))
+}
+
+// RecordClosed records a connection ending, how long it was open and how
+// many notifications it was pushed
+func (c *RealtimeCollector) RecordClosed(ctx context.Context, transport string, reason string, duration time.Duration, messages int64) {
+	c.connections.Add(ctx, -1, metric.WithAttributes(
+		attribute.String("realtime.transport", transport),
+	))
+
+	attributes := metric.WithAttributes(
+		attribute.String("realtime.transport", transport),
+		attribute.String("realtime.close_reason", reason),
+	)
+	c.connectionDuration.Record(ctx, duration.Seconds(), attributes)
+	c.connectionMessages.Record(ctx, messages, attributes)
+}
+
+func (c *RealtimeCollector) RecordRejected(ctx context.Context, transport string) {
+	c.rejectionCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("realtime.transport", transport),
+	))
+}
+
+func (c *RealtimeCollector) RecordMessage(ctx context.Context, transport string) {
+	c.messageCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("realtime.transport", transport),
+	))
+}
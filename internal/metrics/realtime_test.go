@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewRealtimeCollector(t *testing.T) {
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewRealtimeCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			collector.RecordOpened(context.Background(), "sse")
+			collector.RecordMessage(context.Background(), "sse")
+			collector.RecordRejected(context.Background(), "sse")
+			collector.RecordClosed(context.Background(), "sse", RealtimeClosedByClient, time.Minute, 1)
+		})
+	})
+}
+
+func TestRealtimeCollector_Record(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewRealtimeCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordOpened(ctx, "sse")
+	collector.RecordOpened(ctx, "websocket")
+	collector.RecordMessage(ctx, "sse")
+	collector.RecordMessage(ctx, "sse")
+	collector.RecordRejected(ctx, "websocket")
+	collector.RecordClosed(ctx, "sse", RealtimeClosedSlowConsumer, 90*time.Second, 2)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	counts := map[string]int64{}
+	histograms := map[string]metricdata.HistogramDataPoint[float64]{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, dp := range data.DataPoints {
+				transport, _ := dp.Attributes.Value(attribute.Key("realtime.transport"))
+				counts[m.Name+"/"+transport.AsString()] = dp.Value
+			}
+		case metricdata.Histogram[float64]:
+			for _, dp := range data.DataPoints {
+				reason, _ := dp.Attributes.Value(attribute.Key("realtime.close_reason"))
+				histograms[m.Name+"/"+reason.AsString()] = dp
+			}
+		case metricdata.Histogram[int64]:
+			for _, dp := range data.DataPoints {
+				reason, _ := dp.Attributes.Value(attribute.Key("realtime.close_reason"))
+				counts[m.Name+"/"+reason.AsString()] = dp.Sum
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]int64{
+		"notification.realtime.connections/sse":                   0,
+		"notification.realtime.connections/websocket":             1,
+		"notification.realtime.messages/sse":                      2,
+		"notification.realtime.rejections/websocket":              1,
+		"notification.realtime.connection.messages/slow_consumer": 2,
+	}, counts)
+
+	duration := histograms["notification.realtime.connection.duration/slow_consumer"]
+	assert.Equal(t, uint64(1), duration.Count)
+	assert.InDelta(t, 90, duration.Sum, 0.001)
+}
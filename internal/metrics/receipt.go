@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	// ReceiptApplied receipts updated the status of a logged notification
+	ReceiptApplied = "applied"
+	// ReceiptIgnored receipts matched no notification of the provider or
+	// arrived after a later status
+	ReceiptIgnored = "ignored"
+)
+
+const (
+	ReceiptRejectedSignature = "invalid_signature"
+	ReceiptRejectedPayload   = "invalid_payload"
+)
+
+type ReceiptCollector struct {
+	receiptCount   metric.Int64Counter
+	rejectionCount metric.Int64Counter
+}
+
+func NewReceiptCollector(meter metric.Meter) (*ReceiptCollector, error) {
+	// If meter is nil, use noop meter from OpenTelemetry
+	// The noop meter never returns errors, so this is safe
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("noop")
+	}
+
+	receiptCount, err := meter.Int64Counter(
+		"notification.receipts",
+		metric.WithDescription("Total delivery receipts reported by providers, by event"),
+		metric.WithUnit("{receipt}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rejectionCount, err := meter.Int64Counter(
+		"notification.receipt_rejections",
+		metric.WithDescription("Total receipt webhook requests rejected before any receipt was applied"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptCollector{
+		receiptCount:   receiptCount,
+		rejectionCount: rejectionCount,
+	}, nil
+}
+
+// RecordReceipt records a delivery, bounce or open reported by a provider;
+// the bounce rate of a provider is its bounced over delivered and bounced
+// receipts
+func (c *ReceiptCollector) RecordReceipt(ctx context.Context, provider string, event string, outcome string) {
+	c.receiptCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("provider.name", provider),
+		attribute.String("receipt.event", event),
+		attribute.String("receipt.outcome", outcome),
+	))
+}
+
+// RecordRejection records a webhook request refused as a whole
+func (c *ReceiptCollector) RecordRejection(ctx context.Context, provider string, reason string) {
+	c.rejectionCount.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("provider.name", provider),
+		attribute.String("receipt.rejection_reason", reason),
+	))
+}
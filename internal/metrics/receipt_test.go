@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewReceiptCollector(t *testing.T) {
+	t.Run("falls back to noop meter when meter is nil", func(t *testing.T) {
+		collector, err := NewReceiptCollector(nil)
+
+		require.NoError(t, err)
+		assert.NotPanics(t, func() {
+			collector.RecordReceipt(context.Background(), "MyProvider1", "bounced", ReceiptApplied)
+			collector.RecordRejection(context.Background(), "MyProvider1", ReceiptRejectedSignature)
+		})
+	})
+}
+
+func TestReceiptCollector_Record(t *testing.T) {
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+
+	collector, err := NewReceiptCollector(provider.Meter("test"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	collector.RecordReceipt(ctx, "MyProvider1", "delivered", ReceiptApplied)
+	collector.RecordReceipt(ctx, "MyProvider1", "delivered", ReceiptApplied)
+	collector.RecordReceipt(ctx, "MyProvider1", "bounced", ReceiptApplied)
+	collector.RecordReceipt(ctx, "MyProvider2", "opened", ReceiptIgnored)
+	collector.RecordRejection(ctx, "MyProvider1", ReceiptRejectedSignature)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.NotEmpty(t, rm.ScopeMetrics)
+
+	counts := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		for _, dp := range sum.DataPoints {
+			name, _ := dp.Attributes.Value(attribute.Key("provider.name"))
+			key := m.Name + "/" + name.AsString()
+			if event, ok := dp.Attributes.Value(attribute.Key("receipt.event")); ok {
+				outcome, _ := dp.Attributes.Value(attribute.Key("receipt.outcome"))
+				key += "/" + event.AsString() + "/" + outcome.AsString()
+			}
+			if reason, ok := dp.Attributes.Value(attribute.Key("receipt.rejection_reason")); ok {
+				key += "/" + reason.AsString()
+			}
+			counts[key] = dp.Value
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"notification.receipts/MyProvider1/delivered/applied":           2,
+		"notification.receipts/MyProvider1/bounced/applied":             1,
+		"notification.receipts/MyProvider2/opened/ignored":              1,
+		"notification.receipt_rejections/MyProvider1/invalid_signature": 1,
+	}, counts)
+}
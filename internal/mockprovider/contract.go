@@ -0,0 +1,90 @@
+package mockprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// The payloads below are the provider contract, written down independently
+// of client.NotificationRequest so a field added to the outbound request
+// breaks the contract tests until the contract is updated on purpose
+
+// EmailPayload is what email providers accept
+type EmailPayload struct {
+	ID          string              `json:"id" binding:"required"`
+	To          string              `json:"to" binding:"required"`
+	Title       string              `json:"title" binding:"required"`
+	Message     string              `json:"message" binding:"required"`
+	SecretKey   string              `json:"secret_key"`
+	ThreadKey   string              `json:"thread_key"`
+	Headers     map[string]string   `json:"headers" binding:"omitempty,dive,keys,oneof=References In-Reply-To,endkeys,required"`
+	HTML        string              `json:"html"`
+	Attachments []AttachmentPayload `json:"attachments" binding:"omitempty,dive"`
+	// Metadata is only sent with METADATA_FORWARD
+	Metadata map[string]string `json:"metadata"`
+}
+
+// AttachmentPayload carries either a URL or base64 content, never both
+type AttachmentPayload struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url" binding:"required_without=Content,excluded_with=Content,omitempty,url"`
+	Content     string `json:"content" binding:"omitempty,base64"`
+}
+
+// PushPayload is what push providers accept; rich email content is rejected
+type PushPayload struct {
+	ID          string `json:"id" binding:"required"`
+	To          string `json:"to" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Message     string `json:"message" binding:"required"`
+	SecretKey   string `json:"secret_key"`
+	ThreadKey   string `json:"thread_key"`
+	CollapseKey string `json:"collapse_key" binding:"required_with=ThreadKey"`
+	DeepLink    string `json:"deep_link" binding:"omitempty,uri"`
+	ImageURL    string `json:"image_url" binding:"omitempty,url"`
+	// Metadata is only sent with METADATA_FORWARD
+	Metadata map[string]string `json:"metadata"`
+}
+
+// ContractError reports a payload that breaks the provider contract
+type ContractError struct {
+	Channel string
+	Err     error
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("%s payload breaks the provider contract: %s", e.Channel, e.Err)
+}
+
+func (e *ContractError) Unwrap() error {
+	return e.Err
+}
+
+func DecodeEmail(r io.Reader) (EmailPayload, error) {
+	var payload EmailPayload
+	return payload, decode(ChannelEmail, r, &payload)
+}
+
+func DecodePush(r io.Reader) (PushPayload, error) {
+	var payload PushPayload
+	return payload, decode(ChannelPush, r, &payload)
+}
+
+// decode rejects unknown fields, so a payload carrying content the channel
+// cannot render fails just like a missing field does
+func decode(channel string, r io.Reader, payload any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return &ContractError{Channel: channel, Err: err}
+	}
+
+	if err := binding.Validator.ValidateStruct(payload); err != nil {
+		return &ContractError{Channel: channel, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package mockprovider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEmail(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name: "threaded email with attachments",
+			body: `{"id":"01J","to":"buyer@example.com","title":"t","message":"m","thread_key":"order-42",
+				"headers":{"References":"<a@b>","In-Reply-To":"<a@b>"},
+				"attachments":[{"filename":"a.pdf","url":"https://cdn.example.com/a.pdf"},{"filename":"b.txt","content":"aGk="}]}`,
+		},
+		{
+			name: "forwarded metadata",
+			body: `{"id":"01J","to":"buyer@example.com","title":"t","message":"m","metadata":{"order_id":"42"}}`,
+		},
+		{
+			name:          "unknown header",
+			body:          `{"id":"01J","to":"buyer@example.com","title":"t","message":"m","headers":{"X-Priority":"1"}}`,
+			expectedError: "oneof",
+		},
+		{
+			name:          "attachment with url and content",
+			body:          `{"id":"01J","to":"buyer@example.com","title":"t","message":"m","attachments":[{"filename":"a","url":"https://cdn.example.com/a","content":"aGk="}]}`,
+			expectedError: "excluded_with",
+		},
+		{
+			name:          "push only field",
+			body:          `{"id":"01J","to":"buyer@example.com","title":"t","message":"m","deep_link":"app://orders/42"}`,
+			expectedError: `unknown field "deep_link"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeEmail(strings.NewReader(tt.body))
+
+			if tt.expectedError != "" {
+				var contractErr *ContractError
+				require.ErrorAs(t, err, &contractErr)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDecodePush(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name: "threaded push with image",
+			body: `{"id":"01J","to":"device","title":"t","message":"m","thread_key":"order-42","collapse_key":"order-42","image_url":"https://cdn.example.com/a.png"}`,
+		},
+		{
+			name:          "thread without collapse key",
+			body:          `{"id":"01J","to":"device","title":"t","message":"m","thread_key":"order-42"}`,
+			expectedError: "required_with",
+		},
+		{
+			name:          "email only field",
+			body:          `{"id":"01J","to":"device","title":"t","message":"m","attachments":[]}`,
+			expectedError: `unknown field "attachments"`,
+		},
+		{
+			name:          "missing id",
+			body:          `{"to":"device","title":"t","message":"m"}`,
+			expectedError: "'ID' failed on the 'required' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePush(strings.NewReader(tt.body))
+
+			if tt.expectedError != "" {
+				var contractErr *ContractError
+				require.ErrorAs(t, err, &contractErr)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
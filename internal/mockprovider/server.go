@@ -0,0 +1,170 @@
+package mockprovider
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/zap"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Modes select how an endpoint answers a valid payload
+const (
+	ModeSuccess = "success"
+	// ModeFail answers 500, which trips the circuit breaker
+	ModeFail = "fail"
+	// ModeSlow answers after MOCKPROVIDER_SLOW_DELAY, past the client timeout
+	// by default
+	ModeSlow = "slow"
+	// ModeFlaky fails a MOCKPROVIDER_FLAKY_FAILURE_RATE share of the requests
+	ModeFlaky = "flaky"
+)
+
+var modes = map[string]bool{ModeSuccess: true, ModeFail: true, ModeSlow: true, ModeFlaky: true}
+
+type MockProviderConfig struct {
+	Addr             string        `envconfig:"MOCKPROVIDER_ADDR" default:":9090"`
+	SlowDelay        time.Duration `envconfig:"MOCKPROVIDER_SLOW_DELAY" default:"10s"`
+	FlakyFailureRate float64       `envconfig:"MOCKPROVIDER_FLAKY_FAILURE_RATE" default:"0.5"`
+}
+
+func NewMockProviderConfig() MockProviderConfig {
+	var cfg MockProviderConfig
+	envconfig.MustProcess("", &cfg)
+
+	return cfg
+}
+
+// Response is the body of every mock provider answer
+type Response struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Server emulates email and push providers at POST /{channel}/{mode}, so a
+// notification preference host such as http://mockprovider:9090/push/flaky
+// picks the behavior. Payloads are checked against the provider contract
+// before the mode applies
+type Server struct {
+	mux              *http.ServeMux
+	slowDelay        time.Duration
+	flakyFailureRate float64
+	// random returns a number in [0.0, 1.0) deciding flaky failures
+	random func() float64
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+func NewServer(config MockProviderConfig, clock clock.Clock, random func() float64, logger *zap.Logger) *Server {
+	if random == nil {
+		random = rand.Float64
+	}
+
+	s := &Server{
+		mux:              http.NewServeMux(),
+		slowDelay:        config.SlowDelay,
+		flakyFailureRate: config.FlakyFailureRate,
+		random:           random,
+		clock:            clock,
+		logger:           logger,
+	}
+	s.mux.HandleFunc("POST /{channel}/{mode}", s.handleNotify)
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleNotify(w http.ResponseWriter, r *http.Request) {
+	channel, mode := r.PathValue("channel"), r.PathValue("mode")
+	if !modes[mode] {
+		writeResponse(w, http.StatusNotFound, Response{Error: "unknown mode '" + mode + "'"})
+		return
+	}
+
+	var (
+		id  string
+		err error
+	)
+	switch channel {
+	case ChannelEmail:
+		var payload EmailPayload
+		payload, err = DecodeEmail(r.Body)
+		id = payload.ID
+	case ChannelPush:
+		var payload PushPayload
+		payload, err = DecodePush(r.Body)
+		id = payload.ID
+	default:
+		writeResponse(w, http.StatusNotFound, Response{Error: "unknown channel '" + channel + "'"})
+		return
+	}
+	if err != nil {
+		s.logger.Warn("rejected payload", zap.String("channel", channel), zap.Error(err))
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+
+	status, err := s.apply(r, mode)
+	if err != nil {
+		return
+	}
+
+	s.logger.Info("received notification",
+		zap.String("channel", channel),
+		zap.String("mode", mode),
+		zap.String("id", id),
+		zap.Int("status_code", status),
+	)
+
+	if status != http.StatusOK {
+		writeResponse(w, status, Response{ID: id, Error: "mock provider failure"})
+		return
+	}
+	writeResponse(w, status, Response{ID: id, Status: "accepted"})
+}
+
+// handleHealth answers the provider health checks
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, http.StatusOK, Response{Status: "ok"})
+}
+
+// apply returns the status code for mode, waiting first in slow mode; it
+// fails when the caller gave up while waiting
+func (s *Server) apply(r *http.Request, mode string) (int, error) {
+	switch mode {
+	case ModeFail:
+		return http.StatusInternalServerError, nil
+	case ModeFlaky:
+		if s.random() < s.flakyFailureRate {
+			return http.StatusInternalServerError, nil
+		}
+		return http.StatusOK, nil
+	case ModeSlow:
+		select {
+		case <-s.clock.After(s.slowDelay):
+			return http.StatusOK, nil
+		case <-r.Context().Done():
+			return 0, r.Context().Err()
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func writeResponse(w http.ResponseWriter, status int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
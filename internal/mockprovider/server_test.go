@@ -0,0 +1,164 @@
+package mockprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+const (
+	validEmail = `{"id":"01J","to":"buyer@example.com","title":"Order shipped","message":"On its way","html":"<p>On its way</p>"}`
+	validPush  = `{"id":"01J","to":"device-token","title":"Order shipped","message":"On its way","deep_link":"app://orders/42"}`
+)
+
+func TestNewMockProviderConfig(t *testing.T) {
+	cfg := NewMockProviderConfig()
+
+	assert.Equal(t, ":9090", cfg.Addr)
+	assert.Equal(t, 10*time.Second, cfg.SlowDelay)
+	assert.Equal(t, 0.5, cfg.FlakyFailureRate)
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		random         float64
+		expectedStatus int
+		expectedBody   Response
+	}{
+		{
+			name:           "email success",
+			path:           "/email/success",
+			body:           validEmail,
+			expectedStatus: http.StatusOK,
+			expectedBody:   Response{ID: "01J", Status: "accepted"},
+		},
+		{
+			name:           "push fail",
+			path:           "/push/fail",
+			body:           validPush,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   Response{ID: "01J", Error: "mock provider failure"},
+		},
+		{
+			name:           "flaky below failure rate fails",
+			path:           "/push/flaky",
+			body:           validPush,
+			random:         0.2,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   Response{ID: "01J", Error: "mock provider failure"},
+		},
+		{
+			name:           "flaky above failure rate succeeds",
+			path:           "/email/flaky",
+			body:           validEmail,
+			random:         0.7,
+			expectedStatus: http.StatusOK,
+			expectedBody:   Response{ID: "01J", Status: "accepted"},
+		},
+		{
+			name:           "push rejects email content",
+			path:           "/push/success",
+			body:           validEmail,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   Response{Error: `push payload breaks the provider contract: json: unknown field "html"`},
+		},
+		{
+			name:           "email rejects missing fields",
+			path:           "/email/success",
+			body:           `{"id":"01J","to":"buyer@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown channel",
+			path:           "/sms/success",
+			body:           validPush,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   Response{Error: "unknown channel 'sms'"},
+		},
+		{
+			name:           "unknown mode",
+			path:           "/email/broken",
+			body:           validEmail,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   Response{Error: "unknown mode 'broken'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(MockProviderConfig{FlakyFailureRate: 0.5}, nil, func() float64 { return tt.random }, zap.NewNop())
+
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody == (Response{}) {
+				return
+			}
+			var body Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	server := NewServer(MockProviderConfig{}, nil, nil, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body Response
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, Response{Status: "ok"}, body)
+}
+
+func TestServer_ServeHTTP_Slow(t *testing.T) {
+	t.Run("answers after the delay", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		elapsed := make(chan time.Time, 1)
+		elapsed <- time.Time{}
+		mockClock := mockclock.NewMockClock(ctrl)
+		mockClock.EXPECT().After(3 * time.Second).Return(elapsed)
+
+		server := NewServer(MockProviderConfig{SlowDelay: 3 * time.Second}, mockClock, nil, zap.NewNop())
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/email/slow", strings.NewReader(validEmail)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("gives up when the caller does", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClock := mockclock.NewMockClock(ctrl)
+		mockClock.EXPECT().After(3 * time.Second).Return(make(chan time.Time))
+
+		server := NewServer(MockProviderConfig{SlowDelay: 3 * time.Second}, mockClock, nil, zap.NewNop())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/email/slow", strings.NewReader(validEmail)).WithContext(ctx))
+
+		assert.Empty(t, w.Body.String())
+	})
+}
@@ -0,0 +1,155 @@
+package notifyctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+type NotifyctlConfig struct {
+	Addr  string `envconfig:"NOTIFYCTL_ADDR" default:"http://localhost:8080"`
+	Token string `envconfig:"HTTP_ADMIN_TOKEN" secret:"true"`
+	// Actor names the operator the admin actions are performed on behalf of
+	Actor   string        `envconfig:"NOTIFYCTL_ACTOR"`
+	Timeout time.Duration `envconfig:"NOTIFYCTL_TIMEOUT" default:"10s"`
+}
+
+func NewNotifyctlConfig() NotifyctlConfig {
+	var cfg NotifyctlConfig
+	envconfig.MustProcess("", &cfg)
+
+	return cfg
+}
+
+// Client calls the admin API of a running instance, and its notify API to
+// send test notifications with the same admin token
+type Client struct {
+	addr   string
+	token  string
+	actor  string
+	client *http.Client
+}
+
+func NewClient(config NotifyctlConfig) *Client {
+	return &Client{
+		addr:   strings.TrimSuffix(config.Addr, "/"),
+		token:  config.Token,
+		actor:  config.Actor,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (c *Client) Send(ctx context.Context, recipient string, notification handler.NotifyRequest) (handler.NotifyResponse, error) {
+	var response handler.NotifyResponse
+	path := "/api/v1.0/recipient/" + url.PathEscape(recipient) + "/notify"
+	err := c.do(ctx, http.MethodPost, path, nil, notification, &response)
+	return response, err
+}
+
+func (c *Client) Status(ctx context.Context) (handler.AdminStatus, error) {
+	var status handler.AdminStatus
+	err := c.do(ctx, http.MethodGet, "/admin/v1.0/status", nil, nil, &status)
+	return status, err
+}
+
+func (c *Client) Preferences(ctx context.Context, query url.Values) (handler.PreferencesResponse, error) {
+	var response handler.PreferencesResponse
+	err := c.do(ctx, http.MethodGet, "/admin/v1.0/preferences", query, nil, &response)
+	return response, err
+}
+
+// SetPreferenceEnabled puts the preference back into rotation or takes it
+// out, and returns it as changed
+func (c *Client) SetPreferenceEnabled(ctx context.Context, id uint, enabled bool) (handler.PreferenceStatus, error) {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+
+	var preference handler.PreferenceStatus
+	path := "/admin/v1.0/preferences/" + strconv.FormatUint(uint64(id), 10) + "/" + action
+	err := c.do(ctx, http.MethodPost, path, nil, nil, &preference)
+	return preference, err
+}
+
+func (c *Client) ResetCircuitBreaker(ctx context.Context, host string) (handler.CircuitBreakerStatus, error) {
+	var status handler.CircuitBreakerStatus
+	err := c.do(ctx, http.MethodPost, "/admin/v1.0/circuit-breakers/reset", nil, handler.ResetCircuitBreakerRequest{Host: host}, &status)
+	return status, err
+}
+
+func (c *Client) DeadLetters(ctx context.Context, query url.Values) (handler.DeadLettersResponse, error) {
+	var response handler.DeadLettersResponse
+	err := c.do(ctx, http.MethodGet, "/admin/v1.0/dead-letters", query, nil, &response)
+	return response, err
+}
+
+func (c *Client) RedriveDeadLetters(ctx context.Context, query url.Values) (handler.RedriveDeadLettersResponse, error) {
+	var response handler.RedriveDeadLettersResponse
+	err := c.do(ctx, http.MethodPost, "/admin/v1.0/dead-letters/redrive", query, nil, &response)
+	return response, err
+}
+
+func (c *Client) InvalidateCache(ctx context.Context, name string) (handler.CacheStatus, error) {
+	var status handler.CacheStatus
+	err := c.do(ctx, http.MethodPost, "/admin/v1.0/caches/"+url.PathEscape(name)+"/invalidate", nil, nil, &status)
+	return status, err
+}
+
+// do sends body as JSON when set and decodes a 2xx response into out; any
+// other status is returned as an error with the message of the API
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	target := c.addr + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.actor != "" {
+		req.Header.Set(handler.AuditActorHeader, c.actor)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr handler.ErrorHandler
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+			return fmt.Errorf("api responded with status code %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("api responded with status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,97 @@
+package notifyctl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Do(t *testing.T) {
+	tests := []struct {
+		name          string
+		actor         string
+		handler       http.HandlerFunc
+		expectedError string
+	}{
+		{
+			name:  "sends the token, actor and query",
+			actor: "alice",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/admin/v1.0/dead-letters/redrive", r.URL.Path)
+				assert.Equal(t, "Email", r.URL.Query().Get("channel"))
+				assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+				assert.Equal(t, "alice", r.Header.Get(handler.AuditActorHeader))
+				json.NewEncoder(w).Encode(handler.RedriveDeadLettersResponse{Redriven: 2})
+			},
+		},
+		{
+			name: "leaves the actor to the api default",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Empty(t, r.Header.Values(handler.AuditActorHeader))
+				json.NewEncoder(w).Encode(handler.RedriveDeadLettersResponse{Redriven: 2})
+			},
+		},
+		{
+			name: "surfaces the api error message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(handler.ErrorHandler{ErrorCode: "E103", Message: "key lacks the admin role"})
+			},
+			expectedError: "api responded with status code 403: key lacks the admin role",
+		},
+		{
+			name: "reports status code without an error body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			expectedError: "api responded with status code 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			client := NewClient(NotifyctlConfig{Addr: server.URL + "/", Token: "s3cret", Actor: tt.actor, Timeout: time.Second})
+			response, err := client.RedriveDeadLetters(context.Background(), url.Values{"channel": {"Email"}})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), response.Redriven)
+		})
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1.0/recipient/buyer/notify", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req handler.NotifyRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, handler.NotifyRequest{To: "user@example.com", Title: "Hi", Message: "Hello"}, req)
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(handler.NotifyResponse{Message: "notification accepted, will retry", NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW"})
+	}))
+	defer server.Close()
+
+	client := NewClient(NotifyctlConfig{Addr: server.URL, Timeout: time.Second})
+	response, err := client.Send(context.Background(), "buyer", handler.NotifyRequest{To: "user@example.com", Title: "Hi", Message: "Hello"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW", response.NotificationID)
+}
@@ -0,0 +1,302 @@
+package notifyctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+// ErrUsage reports a command line that names no known command or lacks
+// its arguments; Usage describes the accepted ones
+var ErrUsage = errors.New("invalid usage")
+
+const Usage = `usage: notifyctl <command> [flags] [args]
+
+commands:
+  send [-title t] [-message m] [-priority p] <recipient type> <to>
+  preferences list [-type t] [-host h] [-sort s] [-limit n] [-offset n]
+  preferences enable <id>
+  preferences disable <id>
+  breakers list
+  breakers reset <host>
+  dlq list [-notification-id id] [-channel c] [-limit n] [-offset n]
+  dlq drain [-notification-id id] [-channel c] [-include-unsafe]
+  cache invalidate <preferences|routes|translations>
+
+environment:
+  NOTIFYCTL_ADDR     base URL of the service (default http://localhost:8080)
+  HTTP_ADMIN_TOKEN   admin API key
+  NOTIFYCTL_ACTOR    name recorded in the audit log of admin actions
+  NOTIFYCTL_TIMEOUT  timeout of each API call (default 10s)
+`
+
+// CLI runs one notifyctl command against the API and renders its answer
+type CLI struct {
+	client *Client
+}
+
+func New(config NotifyctlConfig) *CLI {
+	return &CLI{client: NewClient(config)}
+}
+
+// Run executes the command named by args, without the program name
+func (c *CLI) Run(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch command, args := args[0], args[1:]; command {
+	case "send":
+		return c.send(ctx, args, w)
+	case "preferences":
+		return c.preferences(ctx, args, w)
+	case "breakers":
+		return c.breakers(ctx, args, w)
+	case "dlq":
+		return c.deadLetters(ctx, args, w)
+	case "cache":
+		return c.cache(ctx, args, w)
+	default:
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, command)
+	}
+}
+
+func (c *CLI) send(ctx context.Context, args []string, w io.Writer) error {
+	flags := newFlagSet("send")
+	title := flags.String("title", "Test notification", "title of the notification")
+	message := flags.String("message", "Sent with notifyctl", "message of the notification")
+	priority := flags.String("priority", "", "high, normal or low")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return fmt.Errorf("%w: send takes a recipient type and a to address", ErrUsage)
+	}
+
+	response, err := c.client.Send(ctx, flags.Arg(0), handler.NotifyRequest{
+		To:       flags.Arg(1),
+		Title:    *title,
+		Message:  *message,
+		Priority: *priority,
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\nnotification %s, %d attempts\n", response.Message, response.NotificationID, response.Attempts)
+	if len(response.Channels) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "CHANNEL\tSTATUS\tPROVIDER\tATTEMPTS")
+		for _, channel := range response.Channels {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", channel.Channel, channel.Status, channel.Provider, channel.Attempts)
+		}
+	}
+	return tw.Flush()
+}
+
+func (c *CLI) preferences(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: preferences takes list, enable or disable", ErrUsage)
+	}
+
+	switch subcommand, args := args[0], args[1:]; subcommand {
+	case "list":
+		flags := newFlagSet("preferences list")
+		query := url.Values{}
+		queryFlag(flags, query, "type", "provider_type", "Email, PushNotification or InApp")
+		queryFlag(flags, query, "host", "host", "provider host")
+		queryFlag(flags, query, "sort", "sort", "priority, id or created_at, '-' prefixed for descending")
+		queryFlag(flags, query, "limit", "limit", "preferences per page")
+		queryFlag(flags, query, "offset", "offset", "preferences to skip")
+		if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+			return fmt.Errorf("%w: preferences list takes flags only", ErrUsage)
+		}
+
+		response, err := c.client.Preferences(ctx, query)
+		if err != nil {
+			return err
+		}
+		return renderPreferences(w, response.Preferences, response.Total)
+	case "enable", "disable":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: preferences %s takes a preference id", ErrUsage, subcommand)
+		}
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil || id == 0 {
+			return fmt.Errorf("%w: preference id must be a positive integer", ErrUsage)
+		}
+
+		preference, err := c.client.SetPreferenceEnabled(ctx, uint(id), subcommand == "enable")
+		if err != nil {
+			return err
+		}
+		return renderPreferences(w, []handler.PreferenceStatus{preference}, 1)
+	default:
+		return fmt.Errorf("%w: unknown preferences command %q", ErrUsage, subcommand)
+	}
+}
+
+func (c *CLI) breakers(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: breakers takes list or reset", ErrUsage)
+	}
+
+	var breakers []handler.CircuitBreakerStatus
+	switch subcommand, args := args[0], args[1:]; subcommand {
+	case "list":
+		if len(args) != 0 {
+			return fmt.Errorf("%w: breakers list takes no arguments", ErrUsage)
+		}
+		status, err := c.client.Status(ctx)
+		if err != nil {
+			return err
+		}
+		breakers = status.CircuitBreakers
+	case "reset":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: breakers reset takes a provider host", ErrUsage)
+		}
+		breaker, err := c.client.ResetCircuitBreaker(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		breakers = []handler.CircuitBreakerStatus{breaker}
+	default:
+		return fmt.Errorf("%w: unknown breakers command %q", ErrUsage, subcommand)
+	}
+
+	if len(breakers) == 0 {
+		_, err := fmt.Fprintln(w, "no provider called yet")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tSTATE\tREQUESTS\tSUCCESSES\tFAILURES\tCONSECUTIVE FAILURES")
+	for _, breaker := range breakers {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\n",
+			breaker.Host,
+			breaker.State,
+			breaker.Requests,
+			breaker.TotalSuccesses,
+			breaker.TotalFailures,
+			breaker.ConsecutiveFailures,
+		)
+	}
+	return tw.Flush()
+}
+
+func (c *CLI) deadLetters(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: dlq takes list or drain", ErrUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	flags := newFlagSet("dlq " + subcommand)
+	query := url.Values{}
+	queryFlag(flags, query, "notification-id", "notification_id", "dead letters of this notification only")
+	queryFlag(flags, query, "channel", "channel", "dead letters of this channel only")
+
+	switch subcommand {
+	case "list":
+		queryFlag(flags, query, "limit", "limit", "dead letters per page")
+		queryFlag(flags, query, "offset", "offset", "dead letters to skip")
+		if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+			return fmt.Errorf("%w: dlq list takes flags only", ErrUsage)
+		}
+
+		response, err := c.client.DeadLetters(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNOTIFICATION\tRECIPIENT TYPE\tCHANNEL\tATTEMPTS\tDISPOSITION\tREASON\tCREATED AT")
+		for _, deadLetter := range response.DeadLetters {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				deadLetter.ID,
+				deadLetter.NotificationID,
+				deadLetter.RecipientType,
+				deadLetter.Channel,
+				deadLetter.Attempts,
+				deadLetter.RetryDisposition,
+				deadLetter.Reason,
+				deadLetter.CreatedAt.Format(time.RFC3339),
+			)
+		}
+		fmt.Fprintf(tw, "\n%d of %d dead letters\n", len(response.DeadLetters), response.Total)
+		return tw.Flush()
+	case "drain":
+		includeUnsafe := flags.Bool("include-unsafe", false, "also dead letters a provider may have delivered, risking duplicates")
+		if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+			return fmt.Errorf("%w: dlq drain takes flags only", ErrUsage)
+		}
+		if *includeUnsafe {
+			query.Set("include_unsafe", "true")
+		}
+
+		response, err := c.client.RedriveDeadLetters(ctx, query)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%d dead letters queued for retry\n", response.Redriven)
+		return err
+	default:
+		return fmt.Errorf("%w: unknown dlq command %q", ErrUsage, subcommand)
+	}
+}
+
+func (c *CLI) cache(ctx context.Context, args []string, w io.Writer) error {
+	if len(args) != 2 || args[0] != "invalidate" {
+		return fmt.Errorf("%w: cache takes invalidate and a cache name", ErrUsage)
+	}
+
+	status, err := c.client.InvalidateCache(ctx, args[1])
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "cache %s invalidated, %d keys evicted in total\n", status.Name, status.KeysEvicted)
+	return err
+}
+
+func renderPreferences(w io.Writer, preferences []handler.PreferenceStatus, total int64) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tNAME\tHOST\tPRIORITY\tENABLED\tTRAFFIC")
+	for _, preference := range preferences {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%t\t%d%%\n",
+			preference.ID,
+			preference.ProviderType,
+			preference.ProviderName,
+			preference.Host,
+			preference.Priority,
+			preference.Enabled,
+			preference.TrafficPercent,
+		)
+	}
+	if total > int64(len(preferences)) {
+		fmt.Fprintf(tw, "\n%d of %d preferences\n", len(preferences), total)
+	}
+	return tw.Flush()
+}
+
+// newFlagSet returns a flag set that reports errors to the caller instead of
+// printing them, so every usage error surfaces as ErrUsage
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// queryFlag sets the query parameter from the flag only when it is given,
+// leaving the API defaults in place otherwise
+func queryFlag(flags *flag.FlagSet, query url.Values, name string, param string, usage string) {
+	flags.Func(name, usage, func(value string) error {
+		query.Set(param, value)
+		return nil
+	})
+}
@@ -0,0 +1,182 @@
+package notifyctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_Run(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		method         string
+		path           string
+		query          string
+		response       any
+		expectedOutput string
+	}{
+		{
+			name:     "sends a test notification",
+			args:     []string{"send", "-title", "Ping", "buyer", "user@example.com"},
+			method:   http.MethodPost,
+			path:     "/api/v1.0/recipient/buyer/notify",
+			response: handler.NotifyResponse{Message: "notification sent", NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW", Attempts: 1, Channels: []handler.ChannelDeliveryResponse{{Channel: "Email", Status: "sent", Provider: "MyProvider1", Attempts: 1}}},
+			expectedOutput: "notification sent\n" +
+				"notification 01JB8Z5XK3M4N5P6Q7R8S9T0VW, 1 attempts\n" +
+				"\n" +
+				"CHANNEL  STATUS  PROVIDER     ATTEMPTS\n" +
+				"Email    sent    MyProvider1  1\n",
+		},
+		{
+			name:     "lists preferences with the given filters only",
+			args:     []string{"preferences", "list", "-type", "Email", "-limit", "1"},
+			method:   http.MethodGet,
+			path:     "/admin/v1.0/preferences",
+			query:    "limit=1&provider_type=Email",
+			response: handler.PreferencesResponse{Preferences: []handler.PreferenceStatus{{ID: 1, ProviderType: "Email", ProviderName: "MyProvider1", Host: "http://mockserver/post", Priority: 1, Enabled: true}}, Total: 2, Limit: 1},
+			expectedOutput: "ID  TYPE   NAME         HOST                    PRIORITY  ENABLED  TRAFFIC\n" +
+				"1   Email  MyProvider1  http://mockserver/post  1         true     0%\n" +
+				"\n" +
+				"1 of 2 preferences\n",
+		},
+		{
+			name:     "disables a preference",
+			args:     []string{"preferences", "disable", "1"},
+			method:   http.MethodPost,
+			path:     "/admin/v1.0/preferences/1/disable",
+			response: handler.PreferenceStatus{ID: 1, ProviderType: "Email", ProviderName: "MyProvider1", Host: "http://mockserver/post", Priority: 1},
+			expectedOutput: "ID  TYPE   NAME         HOST                    PRIORITY  ENABLED  TRAFFIC\n" +
+				"1   Email  MyProvider1  http://mockserver/post  1         false    0%\n",
+		},
+		{
+			name:     "lists circuit breakers from the status",
+			args:     []string{"breakers", "list"},
+			method:   http.MethodGet,
+			path:     "/admin/v1.0/status",
+			response: handler.AdminStatus{CircuitBreakers: []handler.CircuitBreakerStatus{{Host: "https://email.example.com", State: "open", Requests: 5, TotalFailures: 5, ConsecutiveFailures: 5}}},
+			expectedOutput: "HOST                       STATE  REQUESTS  SUCCESSES  FAILURES  CONSECUTIVE FAILURES\n" +
+				"https://email.example.com  open   5         0          5         5\n",
+		},
+		{
+			name:           "reports no circuit breakers",
+			args:           []string{"breakers", "list"},
+			method:         http.MethodGet,
+			path:           "/admin/v1.0/status",
+			response:       handler.AdminStatus{},
+			expectedOutput: "no provider called yet\n",
+		},
+		{
+			name:     "resets a circuit breaker",
+			args:     []string{"breakers", "reset", "https://email.example.com"},
+			method:   http.MethodPost,
+			path:     "/admin/v1.0/circuit-breakers/reset",
+			response: handler.CircuitBreakerStatus{Host: "https://email.example.com", State: "closed"},
+			expectedOutput: "HOST                       STATE   REQUESTS  SUCCESSES  FAILURES  CONSECUTIVE FAILURES\n" +
+				"https://email.example.com  closed  0         0          0         0\n",
+		},
+		{
+			name:   "lists dead letters",
+			args:   []string{"dlq", "list", "-channel", "Email"},
+			method: http.MethodGet,
+			path:   "/admin/v1.0/dead-letters",
+			query:  "channel=Email",
+			response: handler.DeadLettersResponse{DeadLetters: []repository.DeadLetter{{
+				ID:               7,
+				NotificationID:   "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+				RecipientType:    "buyer",
+				Channel:          "Email",
+				Attempts:         5,
+				RetryDisposition: "safe",
+				Reason:           "out of attempts",
+				CreatedAt:        time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			}}, Total: 1, Limit: 50},
+			expectedOutput: "ID  NOTIFICATION                RECIPIENT TYPE  CHANNEL  ATTEMPTS  DISPOSITION  REASON           CREATED AT\n" +
+				"7   01JB8Z5XK3M4N5P6Q7R8S9T0VW  buyer           Email    5         safe         out of attempts  2025-06-01T12:00:00Z\n" +
+				"\n" +
+				"1 of 1 dead letters\n",
+		},
+		{
+			name:           "drains dead letters",
+			args:           []string{"dlq", "drain", "-notification-id", "01JB8Z5XK3M4N5P6Q7R8S9T0VW"},
+			method:         http.MethodPost,
+			path:           "/admin/v1.0/dead-letters/redrive",
+			query:          "notification_id=01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			response:       handler.RedriveDeadLettersResponse{Redriven: 2},
+			expectedOutput: "2 dead letters queued for retry\n",
+		},
+		{
+			name:           "drains dead letters that may have been delivered",
+			args:           []string{"dlq", "drain", "--include-unsafe"},
+			method:         http.MethodPost,
+			path:           "/admin/v1.0/dead-letters/redrive",
+			query:          "include_unsafe=true",
+			response:       handler.RedriveDeadLettersResponse{Redriven: 5},
+			expectedOutput: "5 dead letters queued for retry\n",
+		},
+		{
+			name:           "invalidates a cache",
+			args:           []string{"cache", "invalidate", "routes"},
+			method:         http.MethodPost,
+			path:           "/admin/v1.0/caches/routes/invalidate",
+			response:       handler.CacheStatus{Name: "routes", KeysEvicted: 4},
+			expectedOutput: "cache routes invalidated, 4 keys evicted in total\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.method, r.Method)
+				assert.Equal(t, tt.path, r.URL.Path)
+				assert.Equal(t, tt.query, r.URL.RawQuery)
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			var out bytes.Buffer
+			err := New(NotifyctlConfig{Addr: server.URL, Timeout: time.Second}).Run(context.Background(), tt.args, &out)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestCLI_RunUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"status"}},
+		{name: "send without address", args: []string{"send", "buyer"}},
+		{name: "unknown flag", args: []string{"preferences", "list", "-provider", "Email"}},
+		{name: "invalid preference id", args: []string{"preferences", "enable", "abc"}},
+		{name: "breakers without subcommand", args: []string{"breakers"}},
+		{name: "dlq drain with argument", args: []string{"dlq", "drain", "7"}},
+		{name: "cache without name", args: []string{"cache", "invalidate"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			}))
+			defer server.Close()
+
+			err := New(NotifyctlConfig{Addr: server.URL, Timeout: time.Second}).Run(context.Background(), tt.args, &bytes.Buffer{})
+
+			assert.ErrorIs(t, err, ErrUsage)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/config"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"github.com/koungkub/fw-challenge-notification-service/migrations"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+	// Config is the effective configuration by variable name, secrets redacted
+	Config map[string]string `json:"config"`
+}
+
+func (r *Report) add(result CheckResult) {
+	if result.Status == StatusFail {
+		r.Passed = false
+	}
+	r.Checks = append(r.Checks, result)
+}
+
+type Preflight struct {
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+type PreflightConfig struct {
+	Timeout time.Duration `envconfig:"PREFLIGHT_TIMEOUT" default:"5s"`
+}
+
+func New(config PreflightConfig, logger *zap.Logger) *Preflight {
+	return &Preflight{
+		timeout: config.Timeout,
+		logger:  logger,
+	}
+}
+
+func NewPreflightConfig() PreflightConfig {
+	var cfg PreflightConfig
+	envconfig.MustProcess("", &cfg)
+
+	return cfg
+}
+
+// Run executes every check in order; checks depending on a failed step are
+// reported as skipped rather than attempted
+func (p *Preflight) Run(ctx context.Context) Report {
+	report := Report{Passed: true}
+
+	cfg, result := p.checkConfig()
+	report.Config = cfg.Effective()
+	report.add(result)
+	if result.Status != StatusPass {
+		report.add(skipped("cache", "configuration is invalid"))
+		report.add(skipped("database", "configuration is invalid"))
+		report.add(skipped("schema_version", "configuration is invalid"))
+		report.add(skipped("providers", "configuration is invalid"))
+		return report
+	}
+
+	report.add(p.checkCache(cfg.Cache))
+
+	secrets := secret.NewResolver(secret.ResolverParams{
+		Config: cfg.Secret,
+		Clock:  clock.NewRealClock(),
+		Logger: p.logger,
+	})
+
+	conn, result := p.checkDatabase(ctx, cfg.Persistent, secrets)
+	report.add(result)
+	if result.Status != StatusPass {
+		report.add(skipped("schema_version", "database is unreachable"))
+		report.add(skipped("providers", "database is unreachable"))
+		return report
+	}
+	defer func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	report.add(p.checkSchemaVersion(ctx, conn))
+
+	for _, result := range p.checkProviders(ctx, conn, cfg.Persistent.Environment) {
+		report.add(result)
+	}
+
+	return report
+}
+
+func (p *Preflight) checkConfig() (config.Config, CheckResult) {
+	start := time.Now()
+
+	cfg, err := config.Load()
+	if err == nil {
+		err = errors.Join(
+			validateGenerator(cfg.Generator),
+			validateDispatch(cfg.Dispatch),
+			validateAPIKeys(cfg.Handler),
+		)
+	}
+
+	if err != nil {
+		return cfg, failed("config", start, err)
+	}
+	return cfg, passed("config", start, "")
+}
+
+func validateGenerator(cfg idgen.GeneratorConfig) error {
+	_, err := idgen.NewGenerator(cfg, clock.NewRealClock())
+	return err
+}
+
+func validateDispatch(cfg dispatch.DispatchConfig) error {
+	_, err := dispatch.NewPriorityDispatcher(dispatch.PriorityDispatcherParams{Config: cfg})
+	return err
+}
+
+func validateAPIKeys(cfg handler.HandlerConfig) error {
+	_, err := handler.NewAuthorizer(handler.AuthorizerParams{Config: cfg})
+	return err
+}
+
+// checkCache builds a cache with the configured sizing and round-trips an
+// entry; the cache is in-process, so this validates settings rather than a
+// connection
+func (p *Preflight) checkCache(config repository.CacheConfig) CheckResult {
+	start := time.Now()
+
+	cache, err := ristretto.NewCache(&ristretto.Config[string, struct{}]{
+		NumCounters: config.NumCounters,
+		MaxCost:     config.MaxCost,
+		BufferItems: config.BufferItems,
+	})
+	if err != nil {
+		return failed("cache", start, err)
+	}
+	defer cache.Close()
+
+	cache.SetWithTTL("preflight", struct{}{}, 1, config.ExpiredTime)
+	cache.Wait()
+	if _, ok := cache.Get("preflight"); !ok {
+		return failed("cache", start, errors.New("entry was not admitted, check CACHE_MAX_COST"))
+	}
+
+	return passed("cache", start, "")
+}
+
+func (p *Preflight) checkDatabase(ctx context.Context, config repository.PersistentConfig, secrets secret.Provider) (*gorm.DB, CheckResult) {
+	start := time.Now()
+
+	conn, err := repository.Open(config, secrets)
+	if err != nil {
+		return nil, failed("database", start, err)
+	}
+
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, failed("database", start, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, failed("database", start, err)
+	}
+
+	return conn, passed("database", start, "")
+}
+
+func (p *Preflight) checkSchemaVersion(ctx context.Context, conn *gorm.DB) CheckResult {
+	start := time.Now()
+
+	expected, err := migrations.LatestVersion()
+	if err != nil {
+		return failed("schema_version", start, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var row struct {
+		Version uint
+		Dirty   bool
+	}
+	if err := conn.WithContext(ctx).
+		Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&row).Error; err != nil {
+		return failed("schema_version", start, err)
+	}
+
+	switch {
+	case row.Dirty:
+		return failed("schema_version", start, fmt.Errorf("schema version %d is dirty", row.Version))
+	case row.Version != expected:
+		return failed("schema_version", start, fmt.Errorf("schema version %d, expected %d", row.Version, expected))
+	}
+
+	return passed("schema_version", start, fmt.Sprintf("version %d", row.Version))
+}
+
+func (p *Preflight) checkProviders(ctx context.Context, conn *gorm.DB, environment string) []CheckResult {
+	start := time.Now()
+
+	queryCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	preferences, err := gorm.
+		G[repository.NotificationPreference](conn).
+		Where("deleted_at IS NULL").
+		Where("environment IN ?", repository.PreferenceEnvironments(environment)).
+		Find(queryCtx)
+	if err != nil {
+		return []CheckResult{failed("providers", start, err)}
+	}
+	if len(preferences) == 0 {
+		return []CheckResult{failed("providers", start, errors.New("no notification preferences configured"))}
+	}
+
+	seen := map[string]bool{}
+	results := make([]CheckResult, 0, len(preferences))
+	for _, preference := range preferences {
+		if seen[preference.Host] {
+			continue
+		}
+		seen[preference.Host] = true
+
+		results = append(results, p.checkProvider(ctx, preference.Host))
+	}
+
+	return results
+}
+
+func (p *Preflight) checkProvider(ctx context.Context, rawURL string) CheckResult {
+	start := time.Now()
+	name := "provider:" + rawURL
+
+	address, err := dialAddress(rawURL)
+	if err != nil {
+		return failed(name, start, err)
+	}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		p.logger.Warn("provider unreachable",
+			zap.String("host", address),
+			zap.Error(err),
+		)
+		return failed(name, start, err)
+	}
+	conn.Close()
+
+	return passed(name, start, address)
+}
+
+// dialAddress converts a provider URL into a host:port pair, using the
+// scheme's default port when none is given
+func dialAddress(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Hostname() == "" {
+		return "", fmt.Errorf("url: '%s' has no host", rawURL)
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	return net.JoinHostPort(parsed.Hostname(), port), nil
+}
+
+func passed(name string, start time.Time, message string) CheckResult {
+	return CheckResult{
+		Name:       name,
+		Status:     StatusPass,
+		Message:    message,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+}
+
+func failed(name string, start time.Time, err error) CheckResult {
+	return CheckResult{
+		Name:       name,
+		Status:     StatusFail,
+		Message:    err.Error(),
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+}
+
+func skipped(name string, reason string) CheckResult {
+	return CheckResult{
+		Name:    name,
+		Status:  StatusSkip,
+		Message: reason,
+	}
+}
@@ -0,0 +1,125 @@
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestDialAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "explicit port",
+			url:      "http://mockserver:9090/post",
+			expected: "mockserver:9090",
+		},
+		{
+			name:     "http default port",
+			url:      "http://mockserver/post",
+			expected: "mockserver:80",
+		},
+		{
+			name:     "https default port",
+			url:      "https://email.example.com/send",
+			expected: "email.example.com:443",
+		},
+		{
+			name:        "missing host",
+			url:         "/post",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := dialAddress(tt.url)
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, address)
+		})
+	}
+}
+
+func TestPreflight_checkProvider(t *testing.T) {
+	p := New(PreflightConfig{Timeout: time.Second}, zap.NewNop())
+
+	t.Run("reachable provider passes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		result := p.checkProvider(context.Background(), server.URL)
+
+		assert.Equal(t, StatusPass, result.Status)
+		assert.Equal(t, "provider:"+server.URL, result.Name)
+	})
+
+	t.Run("unreachable provider fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		result := p.checkProvider(context.Background(), url)
+
+		assert.Equal(t, StatusFail, result.Status)
+		assert.NotEmpty(t, result.Message)
+	})
+}
+
+func TestPreflight_Run_InvalidConfig(t *testing.T) {
+	// t.Setenv restores the original values once the test finishes
+	t.Setenv("DB_HOST", "")
+	os.Unsetenv("DB_HOST")
+
+	p := New(PreflightConfig{Timeout: time.Second}, zap.NewNop())
+
+	report := p.Run(context.Background())
+
+	assert.False(t, report.Passed)
+	require.Len(t, report.Checks, 5)
+	assert.Equal(t, "config", report.Checks[0].Name)
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	for _, check := range report.Checks[1:] {
+		assert.Equal(t, StatusSkip, check.Status)
+	}
+	assert.Equal(t, "", report.Config["DB_HOST"])
+	assert.Equal(t, ":8080", report.Config["HTTP_SERVER_PORT"])
+}
+
+func TestPreflight_checkCache(t *testing.T) {
+	p := New(PreflightConfig{Timeout: time.Second}, zap.NewNop())
+
+	t.Run("usable sizing passes", func(t *testing.T) {
+		result := p.checkCache(repository.CacheConfig{
+			ExpiredTime: time.Minute,
+			NumCounters: 1000,
+			MaxCost:     100,
+			BufferItems: 64,
+		})
+
+		assert.Equal(t, StatusPass, result.Status)
+	})
+
+	t.Run("invalid sizing fails", func(t *testing.T) {
+		result := p.checkCache(repository.CacheConfig{ExpiredTime: time.Minute})
+
+		assert.Equal(t, StatusFail, result.Status)
+		assert.NotEmpty(t, result.Message)
+	})
+}
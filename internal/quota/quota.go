@@ -0,0 +1,218 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("quota",
+	fx.Provide(
+		fx.Annotate(
+			NewEnforcer,
+			fx.As(new(Limiter)),
+		),
+	),
+)
+
+type QuotaConfig struct {
+	// DailyLimits and MonthlyLimits map a subject, a tenant or an API key id,
+	// to the notifications it may send per UTC day or month
+	DailyLimits   map[string]int64 `envconfig:"QUOTA_DAILY_LIMITS"`
+	MonthlyLimits map[string]int64 `envconfig:"QUOTA_MONTHLY_LIMITS"`
+	// The defaults apply to subjects without a limit of their own; zero
+	// means unlimited
+	DefaultDailyLimit   int64 `envconfig:"QUOTA_DEFAULT_DAILY_LIMIT" default:"0"`
+	DefaultMonthlyLimit int64 `envconfig:"QUOTA_DEFAULT_MONTHLY_LIMIT" default:"0"`
+}
+
+// Validate rejects negative limits, which would refuse or skip every send
+// of their subjects
+func (c QuotaConfig) Validate() error {
+	var errs []error
+	for _, period := range []struct {
+		name     string
+		limits   map[string]int64
+		fallback int64
+	}{
+		{"daily", c.DailyLimits, c.DefaultDailyLimit},
+		{"monthly", c.MonthlyLimits, c.DefaultMonthlyLimit},
+	} {
+		for _, subject := range slices.Sorted(maps.Keys(period.limits)) {
+			if limit := period.limits[subject]; limit < 0 {
+				errs = append(errs, fmt.Errorf("quota: %s limit of '%s' is negative (%d)", period.name, subject, limit))
+			}
+		}
+		if period.fallback < 0 {
+			errs = append(errs, fmt.Errorf("quota: default %s limit is negative (%d)", period.name, period.fallback))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled tells whether any quota is configured; notifications are only
+// counted while one is
+func (c QuotaConfig) Enabled() bool {
+	return len(c.DailyLimits) > 0 || len(c.MonthlyLimits) > 0 || c.DefaultDailyLimit > 0 || c.DefaultMonthlyLimit > 0
+}
+
+func (c QuotaConfig) limit(limits map[string]int64, fallback int64, subject string) int64 {
+	if limit, ok := limits[subject]; ok {
+		return limit
+	}
+	return fallback
+}
+
+// ExceededError is returned when the subject used up its quota of a period;
+// RetryAfter is the time left until the period ends
+type ExceededError struct {
+	Subject    string
+	Period     string
+	Limit      int64
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d notifications used up for '%s'", periodAdjective[e.Period], e.Limit, e.Subject)
+}
+
+var periodAdjective = map[string]string{
+	repository.QuotaPeriodDay:   "daily",
+	repository.QuotaPeriodMonth: "monthly",
+}
+
+// Usage is the state of one quota of a subject; a zero Limit is unlimited
+type Usage struct {
+	Period   string    `json:"period"`
+	Limit    int64     `json:"limit"`
+	Used     int64     `json:"used"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+type contextKey struct{}
+
+// WithSubject returns ctx carrying the subject the notifications sent with
+// it are counted against
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, contextKey{}, subject)
+}
+
+// SubjectFrom returns the subject carried by ctx, empty when there is none
+func SubjectFrom(ctx context.Context) string {
+	subject, _ := ctx.Value(contextKey{}).(string)
+	return subject
+}
+
+//go:generate mockgen -package mockquota -destination ./mock/mockquota.go . Limiter
+type Limiter interface {
+	// Consume counts one notification against the quotas of the subject of
+	// ctx, or returns an ExceededError once one is used up. Notifications
+	// without a subject, e.g. from SQS, are not limited
+	Consume(ctx context.Context) error
+	// Usage reports the daily and monthly quota of the subject
+	Usage(ctx context.Context, subject string) ([]Usage, error)
+}
+
+var _ Limiter = (*Enforcer)(nil)
+
+// Enforcer keeps the quota counters in Postgres, so every instance enforces
+// the same quotas
+type Enforcer struct {
+	quotas           repository.QuotaProvider
+	config           QuotaConfig
+	clock            clock.Clock
+	metricsCollector *metrics.QuotaCollector
+}
+
+type EnforcerParams struct {
+	fx.In
+
+	Quotas           repository.QuotaProvider
+	Config           QuotaConfig
+	Clock            clock.Clock
+	MetricsCollector *metrics.QuotaCollector
+}
+
+func NewEnforcer(params EnforcerParams) *Enforcer {
+	return &Enforcer{
+		quotas:           params.Quotas,
+		config:           params.Config,
+		clock:            params.Clock,
+		metricsCollector: params.MetricsCollector,
+	}
+}
+
+func (e *Enforcer) Consume(ctx context.Context) error {
+	subject := SubjectFrom(ctx)
+	if subject == "" || !e.config.Enabled() {
+		return nil
+	}
+
+	windows := e.windows(subject)
+	exceeded, ok, err := e.quotas.ConsumeQuota(ctx, subject, windows)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		e.metricsCollector.RecordExceeded(ctx, subject, exceeded.Period)
+		return &ExceededError{
+			Subject:    subject,
+			Period:     exceeded.Period,
+			Limit:      exceeded.Limit,
+			RetryAfter: periodEnd(exceeded).Sub(e.clock.Now()),
+		}
+	}
+
+	e.metricsCollector.RecordConsumed(ctx, subject)
+	return nil
+}
+
+func (e *Enforcer) Usage(ctx context.Context, subject string) ([]Usage, error) {
+	windows := e.windows(subject)
+	usages := make([]Usage, 0, len(windows))
+	for _, window := range windows {
+		used, err := e.quotas.FindQuotaUsage(ctx, subject, window.Period, window.Start)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, Usage{
+			Period:   window.Period,
+			Limit:    window.Limit,
+			Used:     used,
+			ResetsAt: periodEnd(window),
+		})
+	}
+	return usages, nil
+}
+
+// windows returns the current day and month of the subject, in UTC
+func (e *Enforcer) windows(subject string) []repository.QuotaWindow {
+	now := e.clock.Now().UTC()
+	return []repository.QuotaWindow{
+		{
+			Period: repository.QuotaPeriodDay,
+			Start:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+			Limit:  e.config.limit(e.config.DailyLimits, e.config.DefaultDailyLimit, subject),
+		},
+		{
+			Period: repository.QuotaPeriodMonth,
+			Start:  time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+			Limit:  e.config.limit(e.config.MonthlyLimits, e.config.DefaultMonthlyLimit, subject),
+		},
+	}
+}
+
+func periodEnd(window repository.QuotaWindow) time.Time {
+	if window.Period == repository.QuotaPeriodMonth {
+		return window.Start.AddDate(0, 1, 0)
+	}
+	return window.Start.AddDate(0, 0, 1)
+}
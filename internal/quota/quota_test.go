@@ -0,0 +1,190 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testNow   = time.Date(2025, 6, 14, 22, 30, 0, 0, time.UTC)
+	testDay   = time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)
+	testMonth = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newTestEnforcer(t *testing.T, ctrl *gomock.Controller, quotas repository.QuotaProvider, config QuotaConfig) *Enforcer {
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().Return(testNow).AnyTimes()
+
+	metricsCollector, err := metrics.NewQuotaCollector(nil)
+	require.NoError(t, err)
+
+	return NewEnforcer(EnforcerParams{
+		Quotas:           quotas,
+		Config:           config,
+		Clock:            clock,
+		MetricsCollector: metricsCollector,
+	})
+}
+
+func TestQuotaConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        QuotaConfig
+		expectedError string
+	}{
+		{
+			name: "accepts limits of zero and above",
+			config: QuotaConfig{
+				DailyLimits:         map[string]int64{"acme": 0, "globex": 100},
+				DefaultMonthlyLimit: 1000,
+			},
+		},
+		{
+			name:          "rejects a negative subject limit",
+			config:        QuotaConfig{MonthlyLimits: map[string]int64{"acme": -5}},
+			expectedError: "quota: monthly limit of 'acme' is negative (-5)",
+		},
+		{
+			name:          "rejects a negative default limit",
+			config:        QuotaConfig{DefaultDailyLimit: -1},
+			expectedError: "quota: default daily limit is negative (-1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEnforcer_Consume(t *testing.T) {
+	config := QuotaConfig{
+		DailyLimits:         map[string]int64{"acme": 1000},
+		DefaultMonthlyLimit: 5000,
+	}
+	windows := []repository.QuotaWindow{
+		{Period: repository.QuotaPeriodDay, Start: testDay, Limit: 1000},
+		{Period: repository.QuotaPeriodMonth, Start: testMonth, Limit: 5000},
+	}
+
+	tests := []struct {
+		name          string
+		subject       string
+		config        QuotaConfig
+		setupMocks    func(*mockrepository.MockQuotaProvider)
+		expectedError error
+	}{
+		{
+			name:    "counts the notification in the day and month",
+			subject: "acme",
+			config:  config,
+			setupMocks: func(quotas *mockrepository.MockQuotaProvider) {
+				quotas.EXPECT().ConsumeQuota(gomock.Any(), "acme", windows).Return(repository.QuotaWindow{}, true, nil)
+			},
+		},
+		{
+			name:    "refuses once a quota is used up",
+			subject: "acme",
+			config:  config,
+			setupMocks: func(quotas *mockrepository.MockQuotaProvider) {
+				quotas.EXPECT().ConsumeQuota(gomock.Any(), "acme", windows).Return(windows[0], false, nil)
+			},
+			expectedError: &ExceededError{Subject: "acme", Period: repository.QuotaPeriodDay, Limit: 1000, RetryAfter: 90 * time.Minute},
+		},
+		{
+			name:    "applies the defaults to other subjects",
+			subject: "key-5b94cefc",
+			config:  config,
+			setupMocks: func(quotas *mockrepository.MockQuotaProvider) {
+				quotas.EXPECT().ConsumeQuota(gomock.Any(), "key-5b94cefc", []repository.QuotaWindow{
+					{Period: repository.QuotaPeriodDay, Start: testDay},
+					{Period: repository.QuotaPeriodMonth, Start: testMonth, Limit: 5000},
+				}).Return(repository.QuotaWindow{}, true, nil)
+			},
+		},
+		{
+			name:       "does not limit notifications without a subject",
+			config:     config,
+			setupMocks: func(*mockrepository.MockQuotaProvider) {},
+		},
+		{
+			name:       "does not count without quotas",
+			subject:    "acme",
+			setupMocks: func(*mockrepository.MockQuotaProvider) {},
+		},
+		{
+			name:    "fails when the counter cannot be updated",
+			subject: "acme",
+			config:  config,
+			setupMocks: func(quotas *mockrepository.MockQuotaProvider) {
+				quotas.EXPECT().ConsumeQuota(gomock.Any(), "acme", gomock.Any()).Return(repository.QuotaWindow{}, false, errors.New("database down"))
+			},
+			expectedError: errors.New("database down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			quotas := mockrepository.NewMockQuotaProvider(ctrl)
+			tt.setupMocks(quotas)
+
+			enforcer := newTestEnforcer(t, ctrl, quotas, tt.config)
+
+			ctx := context.Background()
+			if tt.subject != "" {
+				ctx = WithSubject(ctx, tt.subject)
+			}
+			err := enforcer.Consume(ctx)
+
+			if tt.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+func TestEnforcer_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quotas := mockrepository.NewMockQuotaProvider(ctrl)
+	quotas.EXPECT().FindQuotaUsage(gomock.Any(), "acme", repository.QuotaPeriodDay, testDay).Return(int64(12), nil)
+	quotas.EXPECT().FindQuotaUsage(gomock.Any(), "acme", repository.QuotaPeriodMonth, testMonth).Return(int64(340), nil)
+
+	enforcer := newTestEnforcer(t, ctrl, quotas, QuotaConfig{MonthlyLimits: map[string]int64{"acme": 5000}})
+
+	usages, err := enforcer.Usage(context.Background(), "acme")
+
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{
+		{Period: repository.QuotaPeriodDay, Used: 12, ResetsAt: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{Period: repository.QuotaPeriodMonth, Limit: 5000, Used: 340, ResetsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}, usages)
+}
+
+func TestExceededError(t *testing.T) {
+	err := &ExceededError{Subject: "acme", Period: repository.QuotaPeriodMonth, Limit: 5000}
+	assert.EqualError(t, err, "monthly quota of 5000 notifications used up for 'acme'")
+}
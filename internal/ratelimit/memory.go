@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+)
+
+// sweepInterval is how often the memory limiter drops the buckets that
+// refilled, so callers seen once do not stay in memory
+const sweepInterval = time.Minute
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// MemoryLimiter keeps the buckets in process memory; each replica limits
+// the requests it serves on its own
+type MemoryLimiter struct {
+	clock clock.Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is refilled
+	full time.Time
+}
+
+func NewMemoryLimiter(clock clock.Clock) *MemoryLimiter {
+	return &MemoryLimiter{
+		clock:   clock,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) Result {
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.RPS)
+	b.updated = now
+
+	result := take(&b.tokens, limit)
+	b.full = now.Add(result.Reset)
+	return result
+}
+
+// sweep drops the buckets refilled by now, at most once a sweepInterval
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// take takes a token from a bucket holding tokens, when there is one
+func take(tokens *float64, limit Limit) Result {
+	result := Result{Limit: limit.Burst}
+	if *tokens >= 1 {
+		*tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = limit.durationFor(1 - *tokens)
+	}
+	result.Remaining = int(math.Floor(*tokens))
+	result.Reset = limit.durationFor(float64(limit.Burst) - *tokens)
+	return result
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	ctx := context.Background()
+	limit := Limit{RPS: 2, Burst: 3}
+	limiter := NewMemoryLimiter(clock)
+
+	t.Run("allows a burst", func(t *testing.T) {
+		for remaining := 2; remaining >= 0; remaining-- {
+			result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+			require.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Limit)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+	})
+
+	t.Run("denies once the bucket is empty", func(t *testing.T) {
+		result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+
+		assert.Equal(t, Result{Limit: 3, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}, result)
+	})
+
+	t.Run("keeps the buckets of other keys apart", func(t *testing.T) {
+		assert.True(t, limiter.Allow(ctx, "ip:192.0.2.2", limit).Allowed)
+	})
+
+	t.Run("refills at the rate", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+
+		result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+
+		assert.Equal(t, Result{Allowed: true, Limit: 3, Reset: 1500 * time.Millisecond}, result)
+	})
+
+	t.Run("drops refilled buckets", func(t *testing.T) {
+		now = now.Add(sweepInterval)
+
+		limiter.Allow(ctx, "ip:192.0.2.3", limit)
+
+		assert.Len(t, limiter.buckets, 1)
+		assert.Contains(t, limiter.buckets, "ip:192.0.2.3")
+	})
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Backends of the rate limit buckets
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+var Module = fx.Module("ratelimit",
+	fx.Provide(
+		NewLimiter,
+		NewLimits,
+	),
+)
+
+// RateLimitConfig limits the notify requests of each client IP and each API
+// key with token buckets: a caller may send Burst requests at once, then
+// RPS requests a second
+type RateLimitConfig struct {
+	Enabled  bool    `envconfig:"RATE_LIMIT_ENABLED" default:"false"`
+	IPRPS    float64 `envconfig:"RATE_LIMIT_IP_RPS" default:"10"`
+	IPBurst  int     `envconfig:"RATE_LIMIT_IP_BURST" default:"20"`
+	KeyRPS   float64 `envconfig:"RATE_LIMIT_KEY_RPS" default:"50"`
+	KeyBurst int     `envconfig:"RATE_LIMIT_KEY_BURST" default:"100"`
+	// Backend keeps the buckets in process memory, so each replica limits
+	// on its own, or in Redis, shared by every replica
+	Backend string `envconfig:"RATE_LIMIT_BACKEND" default:"memory"`
+	// RedisURL is the Redis of the redis backend, e.g.
+	// redis://:password@redis:6379/0
+	RedisURL       string        `envconfig:"RATE_LIMIT_REDIS_URL" secret:"true"`
+	RedisTimeout   time.Duration `envconfig:"RATE_LIMIT_REDIS_TIMEOUT" default:"100ms"`
+	RedisKeyPrefix string        `envconfig:"RATE_LIMIT_REDIS_KEY_PREFIX" default:"notification:ratelimit:"`
+}
+
+// IPLimit is the limit of each client IP
+func (c RateLimitConfig) IPLimit() Limit {
+	return Limit{RPS: c.IPRPS, Burst: c.IPBurst}
+}
+
+// KeyLimit is the limit of each API key
+func (c RateLimitConfig) KeyLimit() Limit {
+	return Limit{RPS: c.KeyRPS, Burst: c.KeyBurst}
+}
+
+func (c RateLimitConfig) validate() error {
+	if err := c.validateLimits(); err != nil {
+		return err
+	}
+
+	switch c.Backend {
+	case BackendMemory:
+	case BackendRedis:
+		if c.RedisURL == "" {
+			return errors.New("rate limit: the redis backend needs RATE_LIMIT_REDIS_URL")
+		}
+		if c.RedisTimeout <= 0 {
+			return errors.New("rate limit redis timeout must be positive")
+		}
+	default:
+		return fmt.Errorf("rate limit backend: '%s' is not %s or %s", c.Backend, BackendMemory, BackendRedis)
+	}
+	return nil
+}
+
+func (c RateLimitConfig) validateLimits() error {
+	for name, limit := range map[string]Limit{"ip": c.IPLimit(), "key": c.KeyLimit()} {
+		if limit.RPS <= 0 || limit.Burst < 1 {
+			return fmt.Errorf("rate limit %s: need rps (%g) > 0 and burst (%d) >= 1", name, limit.RPS, limit.Burst)
+		}
+	}
+	return nil
+}
+
+// Limits are the limits of the ip and key buckets; they can be changed at
+// runtime and apply from the next request
+type Limits struct {
+	value atomic.Pointer[limits]
+}
+
+type limits struct {
+	ip  Limit
+	key Limit
+}
+
+func NewLimits(config RateLimitConfig) *Limits {
+	l := &Limits{}
+	l.value.Store(&limits{ip: config.IPLimit(), key: config.KeyLimit()})
+	return l
+}
+
+// IP is the limit of each client IP
+func (l *Limits) IP() Limit {
+	return l.value.Load().ip
+}
+
+// Key is the limit of each API key
+func (l *Limits) Key() Limit {
+	return l.value.Load().key
+}
+
+// Set replaces the limits, unless rate limiting is enabled and one of them
+// is invalid
+func (l *Limits) Set(config RateLimitConfig) error {
+	if config.Enabled {
+		if err := config.validateLimits(); err != nil {
+			return err
+		}
+	}
+	l.value.Store(&limits{ip: config.IPLimit(), key: config.KeyLimit()})
+	return nil
+}
+
+// Limit is the token bucket of a caller: it holds Burst tokens and refills
+// RPS tokens a second
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// durationFor is the time the bucket takes to refill tokens
+func (l Limit) durationFor(tokens float64) time.Duration {
+	return time.Duration(tokens / l.RPS * float64(time.Second))
+}
+
+// Result is the state of a bucket after a request took a token from it
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket
+	Limit int
+	// Remaining are the tokens left
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until a denied request would be allowed
+	RetryAfter time.Duration
+}
+
+//go:generate mockgen -package mockratelimit -destination ./mock/mockratelimit.go . Limiter
+type Limiter interface {
+	// Allow takes a token from the bucket of key. It never fails: a backend
+	// that cannot be reached allows the request, so an outage of the
+	// limiter does not take the API down with it
+	Allow(ctx context.Context, key string, limit Limit) Result
+}
+
+type LimiterParams struct {
+	fx.In
+
+	Config RateLimitConfig
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+// NewLimiter returns the limiter of the configured backend
+func NewLimiter(lc fx.Lifecycle, params LimiterParams) (Limiter, error) {
+	if !params.Config.Enabled {
+		return NewMemoryLimiter(params.Clock), nil
+	}
+	if err := params.Config.validate(); err != nil {
+		return nil, err
+	}
+
+	if params.Config.Backend == BackendMemory {
+		return NewMemoryLimiter(params.Clock), nil
+	}
+
+	limiter, err := NewRedisLimiter(params.Config, params.Clock, params.Logger)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return limiter.Close()
+		},
+	})
+	return limiter, nil
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testRateLimitConfig matches the documented defaults
+var testRateLimitConfig = RateLimitConfig{
+	Enabled:        true,
+	IPRPS:          10,
+	IPBurst:        20,
+	KeyRPS:         50,
+	KeyBurst:       100,
+	Backend:        BackendMemory,
+	RedisTimeout:   100 * time.Millisecond,
+	RedisKeyPrefix: "notification:ratelimit:",
+}
+
+func TestRateLimitConfig_validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*RateLimitConfig)
+		expectedError string
+	}{
+		{name: "accepts the defaults", modify: func(*RateLimitConfig) {}},
+		{
+			name:   "accepts the redis backend with a url",
+			modify: func(c *RateLimitConfig) { c.Backend, c.RedisURL = BackendRedis, "redis://redis:6379" },
+		},
+		{
+			name:          "rejects an ip rate of zero",
+			modify:        func(c *RateLimitConfig) { c.IPRPS = 0 },
+			expectedError: "rate limit ip",
+		},
+		{
+			name:          "rejects a key burst of zero",
+			modify:        func(c *RateLimitConfig) { c.KeyBurst = 0 },
+			expectedError: "rate limit key",
+		},
+		{
+			name:          "rejects the redis backend without url",
+			modify:        func(c *RateLimitConfig) { c.Backend = BackendRedis },
+			expectedError: "RATE_LIMIT_REDIS_URL",
+		},
+		{
+			name:          "rejects an unknown backend",
+			modify:        func(c *RateLimitConfig) { c.Backend = "memcached" },
+			expectedError: "'memcached' is not memory or redis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testRateLimitConfig
+			tt.modify(&config)
+
+			err := config.validate()
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*RateLimitConfig)
+		expected      any
+		expectedError bool
+	}{
+		{name: "keeps buckets in memory by default", modify: func(*RateLimitConfig) {}, expected: &MemoryLimiter{}},
+		{
+			name:     "keeps buckets in redis",
+			modify:   func(c *RateLimitConfig) { c.Backend, c.RedisURL = BackendRedis, "redis://redis:6379" },
+			expected: &RedisLimiter{},
+		},
+		{
+			name:     "skips validation when disabled",
+			modify:   func(c *RateLimitConfig) { c.Enabled, c.Backend = false, "memcached" },
+			expected: &MemoryLimiter{},
+		},
+		{name: "refuses an invalid config", modify: func(c *RateLimitConfig) { c.Backend = "memcached" }, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			config := testRateLimitConfig
+			tt.modify(&config)
+
+			limiter, err := NewLimiter(fxtest.NewLifecycle(t), LimiterParams{
+				Config: config,
+				Clock:  mockclock.NewMockClock(ctrl),
+				Logger: zap.NewNop(),
+			})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, limiter)
+		})
+	}
+}
+
+func TestLimits_Set(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*RateLimitConfig)
+		expectedIP    Limit
+		expectedKey   Limit
+		expectedError string
+	}{
+		{
+			name:        "replaces the limits",
+			modify:      func(c *RateLimitConfig) { c.IPRPS, c.KeyBurst = 1, 2 },
+			expectedIP:  Limit{RPS: 1, Burst: 20},
+			expectedKey: Limit{RPS: 50, Burst: 2},
+		},
+		{
+			name:          "keeps the limits when one is invalid",
+			modify:        func(c *RateLimitConfig) { c.IPRPS, c.KeyBurst = 1, 0 },
+			expectedIP:    Limit{RPS: 10, Burst: 20},
+			expectedKey:   Limit{RPS: 50, Burst: 100},
+			expectedError: "rate limit key",
+		},
+		{
+			name:        "skips validation when disabled",
+			modify:      func(c *RateLimitConfig) { c.Enabled, c.KeyBurst = false, 0 },
+			expectedIP:  Limit{RPS: 10, Burst: 20},
+			expectedKey: Limit{RPS: 50, Burst: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := NewLimits(testRateLimitConfig)
+			config := testRateLimitConfig
+			tt.modify(&config)
+
+			err := limits.Set(config)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedIP, limits.IP())
+			assert.Equal(t, tt.expectedKey, limits.Key())
+		})
+	}
+}
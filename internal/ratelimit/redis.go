@@ -0,0 +1,176 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// While Redis cannot be reached the limiter allows requests without asking
+// it, and asks again after a backoff that doubles on each failure
+const (
+	minRedisBackoff = time.Second
+	maxRedisBackoff = 30 * time.Second
+)
+
+var errInvalidRedisURL = errors.New("rate limit redis url: use redis://[:password@]host:port[/db] or rediss://")
+
+// tokenBucketScript refills and takes from the bucket atomically. It reads
+// the time of Redis rather than of the replica, so replicas with skewed
+// clocks share a bucket correctly. It returns whether the request is
+// allowed, the tokens left, and the retry and reset times in milliseconds
+var tokenBucketScript = redis.NewScript(`
+local rps = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rps / 1000)
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rps)
+end
+local reset = math.ceil((burst - tokens) * 1000 / rps)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// RedisLimiter keeps the buckets in Redis, so every replica takes from the
+// same bucket of a caller
+type RedisLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+	clock     clock.Clock
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	backoff time.Duration
+	retryAt time.Time
+	// unlimited counts the requests allowed without asking Redis since
+	// the last log
+	unlimited int
+}
+
+func NewRedisLimiter(config RateLimitConfig, clock clock.Clock, logger *zap.Logger) (*RedisLimiter, error) {
+	if u, err := url.Parse(config.RedisURL); err != nil || u.Host == "" {
+		return nil, errInvalidRedisURL
+	}
+	options, err := redis.ParseURL(config.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRedisURL, err)
+	}
+	options.DialTimeout = config.RedisTimeout
+	options.ReadTimeout = config.RedisTimeout
+	options.WriteTimeout = config.RedisTimeout
+	// A request is allowed rather than waiting on retries
+	options.MaxRetries = -1
+	options.DialerRetries = 1
+
+	return &RedisLimiter{
+		client:    redis.NewClient(options),
+		keyPrefix: config.RedisKeyPrefix,
+		clock:     clock,
+		logger:    logger,
+	}, nil
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) Result {
+	unlimited := Result{Allowed: true, Limit: limit.Burst, Remaining: limit.Burst}
+	if !l.reachable() {
+		return unlimited
+	}
+
+	result, err := l.take(ctx, key, limit)
+	if err != nil {
+		// A request that went away says nothing about Redis
+		if ctx.Err() == nil {
+			l.unreachable(err)
+		}
+		return unlimited
+	}
+	l.recovered()
+	return result
+}
+
+func (l *RedisLimiter) take(ctx context.Context, key string, limit Limit) (Result, error) {
+	numbers, err := tokenBucketScript.Run(ctx, l.client, []string{l.keyPrefix + key}, limit.RPS, limit.Burst).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(numbers) != 4 {
+		return Result{}, fmt.Errorf("unexpected redis reply %v", numbers)
+	}
+
+	return Result{
+		Allowed:    numbers[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(numbers[1]),
+		RetryAfter: time.Duration(numbers[2]) * time.Millisecond,
+		Reset:      time.Duration(numbers[3]) * time.Millisecond,
+	}, nil
+}
+
+// reachable is false during the backoff after a failure
+func (l *RedisLimiter) reachable() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.clock.Now().Before(l.retryAt) {
+		l.unlimited++
+		return false
+	}
+	return true
+}
+
+// unreachable doubles the backoff. It logs once per backoff, so an outage
+// does not log every request
+func (l *RedisLimiter) unreachable(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	// Another request already failed since the backoff ended
+	if now.Before(l.retryAt) {
+		return
+	}
+	l.backoff = min(max(2*l.backoff, minRedisBackoff), maxRedisBackoff)
+	l.retryAt = now.Add(l.backoff)
+
+	l.logger.Warn("rate limit unavailable, allowing requests",
+		zap.Duration("retry_in", l.backoff),
+		zap.Int("unlimited_requests", l.unlimited),
+		zap.Error(err))
+	l.unlimited = 0
+}
+
+func (l *RedisLimiter) recovered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.backoff == 0 {
+		return
+	}
+	l.logger.Info("rate limit available again", zap.Int("unlimited_requests", l.unlimited))
+	l.backoff, l.retryAt, l.unlimited = 0, time.Time{}, 0
+}
+
+// Close closes the connections to Redis
+func (l *RedisLimiter) Close() error {
+	return l.client.Close()
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestRedisLimiter(t *testing.T, url string, now *time.Time) (*RedisLimiter, *observer.ObservedLogs) {
+	ctrl := gomock.NewController(t)
+	clock := mockclock.NewMockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return *now }).AnyTimes()
+
+	config := testRateLimitConfig
+	config.Backend, config.RedisURL = BackendRedis, url
+	core, logs := observer.New(zap.InfoLevel)
+
+	limiter, err := NewRedisLimiter(config, clock, zap.New(core))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = limiter.Close() })
+	return limiter, logs
+}
+
+func TestNewRedisLimiter(t *testing.T) {
+	tests := []struct {
+		name             string
+		url              string
+		expectedAddress  string
+		expectedPassword string
+		expectedDB       int
+		expectedTLS      bool
+		expectedError    bool
+	}{
+		{name: "defaults the port", url: "redis://redis", expectedAddress: "redis:6379"},
+		{
+			name:             "reads the password and database",
+			url:              "redis://:s3cret@redis:6380/2",
+			expectedAddress:  "redis:6380",
+			expectedPassword: "s3cret",
+			expectedDB:       2,
+		},
+		{name: "connects with tls", url: "rediss://redis:6380", expectedAddress: "redis:6380", expectedTLS: true},
+		{name: "refuses another scheme", url: "http://redis:6379", expectedError: true},
+		{name: "refuses an invalid database", url: "redis://redis:6379/cache", expectedError: true},
+		{name: "refuses a url without host", url: "redis://", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testRateLimitConfig
+			config.RedisURL = tt.url
+
+			limiter, err := NewRedisLimiter(config, nil, zap.NewNop())
+
+			if tt.expectedError {
+				assert.ErrorIs(t, err, errInvalidRedisURL)
+				return
+			}
+			require.NoError(t, err)
+			defer limiter.Close()
+			options := limiter.client.Options()
+			assert.Equal(t, tt.expectedAddress, options.Addr)
+			assert.Equal(t, tt.expectedPassword, options.Password)
+			assert.Equal(t, tt.expectedDB, options.DB)
+			assert.Equal(t, tt.expectedTLS, options.TLSConfig != nil)
+			assert.Equal(t, testRateLimitConfig.RedisTimeout, options.ReadTimeout)
+		})
+	}
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{RPS: 2, Burst: 3}
+
+	t.Run("takes from the bucket in redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		server.RequireAuth("s3cret")
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		server.SetTime(now)
+		limiter, _ := newTestRedisLimiter(t, "redis://:s3cret@"+server.Addr()+"/2", &now)
+
+		for remaining := 2; remaining >= 0; remaining-- {
+			result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+			require.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+		result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+
+		assert.Equal(t, Result{Limit: 3, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}, result)
+		assert.True(t, server.DB(2).Exists("notification:ratelimit:ip:192.0.2.1"))
+	})
+
+	t.Run("allows requests without asking redis while it is down", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		limiter, logs := newTestRedisLimiter(t, "redis://"+server.Addr(), &now)
+		server.Close()
+
+		result := limiter.Allow(ctx, "ip:192.0.2.1", limit)
+
+		assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 3}, result)
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, minRedisBackoff, logs.All()[0].ContextMap()["retry_in"])
+
+		// Redis is back, but the limiter waits out the backoff
+		require.NoError(t, server.Restart())
+		for range 2 {
+			result = limiter.Allow(ctx, "ip:192.0.2.1", limit)
+			assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 3}, result)
+		}
+		assert.Equal(t, 1, logs.Len())
+
+		now = now.Add(minRedisBackoff)
+		result = limiter.Allow(ctx, "ip:192.0.2.1", limit)
+
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+		require.Equal(t, 2, logs.Len())
+		recovered := logs.All()[1]
+		assert.Equal(t, "rate limit available again", recovered.Message)
+		assert.Equal(t, int64(2), recovered.ContextMap()["unlimited_requests"])
+	})
+
+	t.Run("doubles the backoff while redis stays down", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		limiter, logs := newTestRedisLimiter(t, "redis://"+server.Addr(), &now)
+		server.Close()
+
+		var backoffs []any
+		for range 7 {
+			limiter.Allow(ctx, "ip:192.0.2.1", limit)
+			backoffs = append(backoffs, logs.All()[logs.Len()-1].ContextMap()["retry_in"])
+			now = now.Add(limiter.backoff)
+		}
+
+		assert.Equal(t, []any{
+			time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+			16 * time.Second, maxRedisBackoff, maxRedisBackoff,
+		}, backoffs)
+	})
+}
@@ -0,0 +1,213 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+)
+
+// Transports a recipient may receive in-app notifications over
+const (
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+)
+
+var (
+	ErrTooManyConnections = errors.New("recipient has too many realtime connections open")
+	ErrClosed             = errors.New("realtime broker is closed")
+)
+
+var Module = fx.Module("realtime",
+	fx.Provide(
+		fx.Annotate(
+			NewBroker,
+			fx.As(new(Publisher)),
+			fx.As(new(Subscriber)),
+		),
+	),
+)
+
+// Publisher pushes stored in-app notifications to the connections of their
+// recipient
+//
+//go:generate mockgen -package mockrealtime -destination ./mock/mockrealtime.go . Publisher
+type Publisher interface {
+	// Publish never blocks; a connection too far behind to take the
+	// notification is closed instead, and its client lists what it missed
+	Publish(ctx context.Context, notification repository.InAppNotification)
+}
+
+type Subscriber interface {
+	// Subscribe opens a connection of the recipient, receiving the
+	// notifications of the quota subject of ctx only; ErrTooManyConnections
+	// when the recipient already has MaxConnectionsPerRecipient open and
+	// ErrClosed once the server is stopping
+	Subscribe(ctx context.Context, recipient string, transport string) (*Subscription, error)
+	// Close ends every connection and refuses new ones
+	Close()
+}
+
+var (
+	_ Publisher  = (*Broker)(nil)
+	_ Subscriber = (*Broker)(nil)
+)
+
+type RealtimeConfig struct {
+	// BufferSize is the number of notifications a connection may fall behind
+	// by before it is closed as a slow consumer
+	BufferSize                 int           `envconfig:"REALTIME_BUFFER_SIZE" default:"16"`
+	MaxConnectionsPerRecipient int           `envconfig:"REALTIME_MAX_CONNECTIONS_PER_RECIPIENT" default:"5"`
+	HeartbeatInterval          time.Duration `envconfig:"REALTIME_HEARTBEAT_INTERVAL" default:"30s"`
+	// TicketSecret signs the tickets a connection is opened with; unset, every
+	// instance signs with a random secret of its own
+	TicketSecret string        `envconfig:"REALTIME_TICKET_SECRET" secret:"true"`
+	TicketTTL    time.Duration `envconfig:"REALTIME_TICKET_TTL" default:"1m"`
+}
+
+// Subscription is one open connection of a recipient. Notifications is
+// closed when the broker ends the connection
+type Subscription struct {
+	broker        *Broker
+	tenant        string
+	recipient     string
+	transport     string
+	notifications chan repository.InAppNotification
+	openedAt      time.Time
+	// messages is the number of notifications pushed, guarded by the broker
+	messages int64
+}
+
+func (s *Subscription) Notifications() <-chan repository.InAppNotification {
+	return s.notifications
+}
+
+// Close ends the connection from the client side
+func (s *Subscription) Close() {
+	s.broker.remove(context.Background(), s, metrics.RealtimeClosedByClient)
+}
+
+// Broker fans in-app notifications out to the open connections of their
+// recipient on this instance
+type Broker struct {
+	config           RealtimeConfig
+	clock            clock.Clock
+	metricsCollector *metrics.RealtimeCollector
+
+	mu sync.Mutex
+	// subscriptions are keyed by subscriptionKey
+	subscriptions map[string]map[*Subscription]struct{}
+	closed        bool
+}
+
+type BrokerParams struct {
+	fx.In
+
+	Config           RealtimeConfig
+	Clock            clock.Clock
+	MetricsCollector *metrics.RealtimeCollector
+}
+
+func NewBroker(params BrokerParams) *Broker {
+	return &Broker{
+		config:           params.Config,
+		clock:            params.Clock,
+		metricsCollector: params.MetricsCollector,
+		subscriptions:    make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broker) Subscribe(ctx context.Context, recipient string, transport string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	key := subscriptionKey(quota.SubjectFrom(ctx), recipient)
+	if len(b.subscriptions[key]) >= b.config.MaxConnectionsPerRecipient {
+		b.metricsCollector.RecordRejected(ctx, transport)
+		return nil, ErrTooManyConnections
+	}
+
+	subscription := &Subscription{
+		broker:        b,
+		tenant:        quota.SubjectFrom(ctx),
+		recipient:     recipient,
+		transport:     transport,
+		notifications: make(chan repository.InAppNotification, b.config.BufferSize),
+		openedAt:      b.clock.Now(),
+	}
+	if b.subscriptions[key] == nil {
+		b.subscriptions[key] = make(map[*Subscription]struct{})
+	}
+	b.subscriptions[key][subscription] = struct{}{}
+
+	b.metricsCollector.RecordOpened(ctx, transport)
+	return subscription, nil
+}
+
+func (b *Broker) Publish(ctx context.Context, notification repository.InAppNotification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscriptions[subscriptionKey(notification.Tenant, notification.Recipient)] {
+		select {
+		case subscription.notifications <- notification:
+			subscription.messages++
+			b.metricsCollector.RecordMessage(ctx, subscription.transport)
+		default:
+			b.removeLocked(ctx, subscription, metrics.RealtimeClosedSlowConsumer)
+		}
+	}
+}
+
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subscriptions := range b.subscriptions {
+		for subscription := range subscriptions {
+			b.removeLocked(context.Background(), subscription, metrics.RealtimeClosedShutdown)
+		}
+	}
+}
+
+func (b *Broker) remove(ctx context.Context, subscription *Subscription, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.removeLocked(ctx, subscription, reason)
+}
+
+// removeLocked ends a connection once; later calls for the same connection
+// do nothing
+func (b *Broker) removeLocked(ctx context.Context, subscription *Subscription, reason string) {
+	key := subscriptionKey(subscription.tenant, subscription.recipient)
+	subscriptions := b.subscriptions[key]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(b.subscriptions, key)
+	}
+	close(subscription.notifications)
+
+	b.metricsCollector.RecordClosed(ctx, subscription.transport, reason, b.clock.Since(subscription.openedAt), subscription.messages)
+}
+
+// subscriptionKey groups the connections of a recipient by tenant, so a
+// notification only reaches the connections opened with a ticket of the
+// tenant that sent it
+func subscriptionKey(tenant string, recipient string) string {
+	return tenant + "\x00" + recipient
+}
@@ -0,0 +1,109 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBroker(t *testing.T, config RealtimeConfig) *Broker {
+	metricsCollector, err := metrics.NewRealtimeCollector(nil)
+	require.NoError(t, err)
+
+	return NewBroker(BrokerParams{
+		Config:           config,
+		Clock:            clock.NewRealClock(),
+		MetricsCollector: metricsCollector,
+	})
+}
+
+func TestBroker_Publish(t *testing.T) {
+	broker := newTestBroker(t, RealtimeConfig{BufferSize: 4, MaxConnectionsPerRecipient: 5})
+	ctx := quota.WithSubject(context.Background(), "acme")
+
+	first, err := broker.Subscribe(ctx, "user-42", TransportSSE)
+	require.NoError(t, err)
+	second, err := broker.Subscribe(ctx, "user-42", TransportWebSocket)
+	require.NoError(t, err)
+	other, err := broker.Subscribe(ctx, "user-7", TransportSSE)
+	require.NoError(t, err)
+	otherTenant, err := broker.Subscribe(quota.WithSubject(ctx, "globex"), "user-42", TransportSSE)
+	require.NoError(t, err)
+
+	notification := repository.InAppNotification{ID: 1, Recipient: "user-42", Title: "Order shipped", Tenant: "acme"}
+	broker.Publish(ctx, notification)
+
+	assert.Equal(t, notification, <-first.Notifications())
+	assert.Equal(t, notification, <-second.Notifications())
+	assert.Empty(t, other.Notifications())
+	assert.Empty(t, otherTenant.Notifications(), "another tenant's connection of the same user")
+}
+
+func TestBroker_Subscribe(t *testing.T) {
+	t.Run("refuses connections above the limit of the recipient", func(t *testing.T) {
+		broker := newTestBroker(t, RealtimeConfig{BufferSize: 1, MaxConnectionsPerRecipient: 1})
+		ctx := context.Background()
+
+		subscription, err := broker.Subscribe(ctx, "user-42", TransportSSE)
+		require.NoError(t, err)
+
+		_, err = broker.Subscribe(ctx, "user-42", TransportSSE)
+		assert.ErrorIs(t, err, ErrTooManyConnections)
+
+		_, err = broker.Subscribe(ctx, "user-7", TransportSSE)
+		assert.NoError(t, err)
+
+		subscription.Close()
+		_, err = broker.Subscribe(ctx, "user-42", TransportSSE)
+		assert.NoError(t, err)
+	})
+
+	t.Run("refuses connections once closed", func(t *testing.T) {
+		broker := newTestBroker(t, RealtimeConfig{BufferSize: 1, MaxConnectionsPerRecipient: 1})
+
+		broker.Close()
+
+		_, err := broker.Subscribe(context.Background(), "user-42", TransportSSE)
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestBroker_SlowConsumer(t *testing.T) {
+	broker := newTestBroker(t, RealtimeConfig{BufferSize: 1, MaxConnectionsPerRecipient: 5})
+	ctx := context.Background()
+
+	subscription, err := broker.Subscribe(ctx, "user-42", TransportSSE)
+	require.NoError(t, err)
+
+	broker.Publish(ctx, repository.InAppNotification{ID: 1, Recipient: "user-42"})
+	broker.Publish(ctx, repository.InAppNotification{ID: 2, Recipient: "user-42"})
+
+	notification, ok := <-subscription.Notifications()
+	require.True(t, ok)
+	assert.Equal(t, uint(1), notification.ID)
+
+	_, ok = <-subscription.Notifications()
+	assert.False(t, ok, "a connection a full buffer behind is closed")
+
+	assert.NotPanics(t, subscription.Close)
+}
+
+func TestBroker_Close(t *testing.T) {
+	broker := newTestBroker(t, RealtimeConfig{BufferSize: 1, MaxConnectionsPerRecipient: 5})
+
+	subscription, err := broker.Subscribe(context.Background(), "user-42", TransportWebSocket)
+	require.NoError(t, err)
+
+	broker.Close()
+
+	_, ok := <-subscription.Notifications()
+	assert.False(t, ok)
+	assert.NotPanics(t, subscription.Close)
+	assert.NotPanics(t, broker.Close)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockaudit.go . AuditProvider
+type AuditProvider interface {
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+	FindAuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+var _ AuditProvider = (*Persistent)(nil)
+
+// AuditFilter narrows the audit log; zero fields match everything. Entries
+// are returned newest first
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+func (p *Persistent) RecordAudit(ctx context.Context, entry AuditEntry) error {
+	if err := gorm.G[AuditEntry](p.conn).Create(ctx, &entry); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("audit_action", entry.Action),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) FindAuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	query := gorm.G[AuditEntry](p.conn).Limit(filter.Limit)
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	entries, err := query.Order("created_at DESC, id DESC").Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("audit_action", filter.Action),
+			zap.Error(err),
+		)
+		return []AuditEntry{}, err
+	}
+
+	return entries, nil
+}
@@ -3,10 +3,10 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
-	"github.com/kelseyhightower/envconfig"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -19,20 +19,45 @@ const (
 type CacheProvider interface {
 	Get(key NotificationProvider) ([]NotificationPreference, error)
 	Set(key NotificationProvider, values []NotificationPreference) error
+	Stats() CacheStats
+	// Clear drops every entry, and the statistics with them, so the next
+	// reads go to the database
+	Clear()
+}
+
+// CacheStats is a point-in-time view of a cache's effectiveness since start
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	KeysAdded   uint64
+	KeysEvicted uint64
+	HitRatio    float64
+}
+
+func newCacheStats(metrics *ristretto.Metrics) CacheStats {
+	return CacheStats{
+		Hits:        metrics.Hits(),
+		Misses:      metrics.Misses(),
+		KeysAdded:   metrics.KeysAdded(),
+		KeysEvicted: metrics.KeysEvicted(),
+		HitRatio:    metrics.Ratio(),
+	}
 }
 
 var _ CacheProvider = (*Cache)(nil)
 
 type Cache struct {
-	engine      *ristretto.Cache[string, []NotificationPreference]
-	expiredTime time.Duration
-	logger      *zap.Logger
+	engine  *ristretto.Cache[string, []NotificationPreference]
+	ttl     *CacheTTL
+	missTTL time.Duration
+	logger  *zap.Logger
 }
 
 type CacheParams struct {
 	fx.In
 
 	Config CacheConfig
+	TTL    *CacheTTL
 	Logger *zap.Logger
 }
 
@@ -41,6 +66,7 @@ func NewCache(lc fx.Lifecycle, params CacheParams) (*Cache, error) {
 		NumCounters: params.Config.NumCounters,
 		MaxCost:     params.Config.MaxCost,
 		BufferItems: params.Config.BufferItems,
+		Metrics:     true,
 	})
 	if err != nil {
 		return nil, err
@@ -54,24 +80,42 @@ func NewCache(lc fx.Lifecycle, params CacheParams) (*Cache, error) {
 	})
 
 	return &Cache{
-		engine:      engine,
-		expiredTime: params.Config.ExpiredTime,
-		logger:      params.Logger,
+		engine:  engine,
+		ttl:     params.TTL,
+		missTTL: params.Config.MissExpiredTime,
+		logger:  params.Logger,
 	}, nil
 }
 
-type CacheConfig struct {
-	ExpiredTime time.Duration `envconfig:"CACHE_EXPIRED_TIME" default:"10m"`
-	NumCounters int64         `envconfig:"CACHE_NUM_COUNTERS" default:"10000000"`
-	MaxCost     int64         `envconfig:"CACHE_MAX_COST" default:"1073741824"` // 1GB
-	BufferItems int64         `envconfig:"CACHE_BUFFER_ITEMS" default:"64"`
+// CacheTTL is the expiry shared by every cache; it can be changed at runtime
+// and applies to entries stored afterwards
+type CacheTTL struct {
+	value atomic.Int64
+}
+
+func NewCacheTTL(config CacheConfig) *CacheTTL {
+	ttl := &CacheTTL{}
+	ttl.Set(config.ExpiredTime)
+	return ttl
+}
+
+func (t *CacheTTL) Get() time.Duration {
+	return time.Duration(t.value.Load())
 }
 
-func NewCacheConfig() CacheConfig {
-	var cfg CacheConfig
-	envconfig.MustProcess("", &cfg)
+func (t *CacheTTL) Set(ttl time.Duration) {
+	t.value.Store(int64(ttl))
+}
 
-	return cfg
+type CacheConfig struct {
+	ExpiredTime time.Duration `envconfig:"CACHE_EXPIRED_TIME" default:"10m"`
+	// MissExpiredTime is how long an empty result is kept, so a lookup that
+	// finds nothing is not repeated on every request while the change that
+	// adds it shows up soon; zero keeps nothing
+	MissExpiredTime time.Duration `envconfig:"CACHE_MISS_EXPIRED_TIME" default:"30s"`
+	NumCounters     int64         `envconfig:"CACHE_NUM_COUNTERS" default:"10000000"`
+	MaxCost         int64         `envconfig:"CACHE_MAX_COST" default:"1073741824"` // 1GB
+	BufferItems     int64         `envconfig:"CACHE_BUFFER_ITEMS" default:"64"`
 }
 
 func (c *Cache) Get(key NotificationProvider) ([]NotificationPreference, error) {
@@ -93,15 +137,37 @@ func (c *Cache) Get(key NotificationProvider) ([]NotificationPreference, error)
 	return value, nil
 }
 
+// Set keeps values for the cache TTL, or for the miss TTL when there are none
 func (c *Cache) Set(key NotificationProvider, values []NotificationPreference) error {
 	cacheKey := fmt.Sprintf(cacheKeyPattern, key.String())
 
-	c.engine.SetWithTTL(cacheKey, values, 1, c.expiredTime)
+	ttl, ok := entryTTL(len(values), c.ttl.Get(), c.missTTL)
+	if !ok {
+		return nil
+	}
+	c.engine.SetWithTTL(cacheKey, values, 1, ttl)
 
 	c.logger.Debug("cache set",
 		zap.String("provider_type", key.String()),
 		zap.Int("preferences_count", len(values)),
-		zap.Duration("ttl", c.expiredTime),
+		zap.Duration("ttl", ttl),
 	)
 	return nil
 }
+
+// entryTTL is how long an entry of count values is kept: the miss TTL when
+// it is empty, where zero keeps nothing, the cache TTL otherwise
+func entryTTL(count int, ttl, missTTL time.Duration) (time.Duration, bool) {
+	if count == 0 {
+		return missTTL, missTTL > 0
+	}
+	return ttl, true
+}
+
+func (c *Cache) Stats() CacheStats {
+	return newCacheStats(c.engine.Metrics)
+}
+
+func (c *Cache) Clear() {
+	c.engine.Clear()
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockcircuitbreaker.go . CircuitBreakerTripProvider
+type CircuitBreakerTripProvider interface {
+	// RecordCircuitBreakerTrip stores when the breaker of host opened,
+	// replacing an earlier trip
+	RecordCircuitBreakerTrip(ctx context.Context, host string, openedAt time.Time) error
+	// ClearCircuitBreakerTrip forgets the trip of host; clearing a host
+	// without a trip is not an error
+	ClearCircuitBreakerTrip(ctx context.Context, host string) error
+	// FindCircuitBreakerTrips returns the trips opened at or after since
+	FindCircuitBreakerTrips(ctx context.Context, since time.Time) ([]CircuitBreakerTrip, error)
+	// AddCircuitBreakerCounts adds the outcomes of one instance to the
+	// window of host starting at windowStart, which replaces an earlier
+	// window, and returns the counts of every instance. Counts of a window
+	// already replaced are dropped and return zero counts
+	AddCircuitBreakerCounts(ctx context.Context, host string, windowStart time.Time, requests int64, failures int64) (CircuitBreakerCounts, error)
+}
+
+var _ CircuitBreakerTripProvider = (*Persistent)(nil)
+
+func (p *Persistent) RecordCircuitBreakerTrip(ctx context.Context, host string, openedAt time.Time) error {
+	trip := CircuitBreakerTrip{Host: host, OpenedAt: openedAt}
+
+	err := p.conn.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "host"}},
+			DoUpdates: clause.AssignmentColumns([]string{"opened_at", "updated_at"}),
+		}).
+		Create(&trip).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("host", host),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) ClearCircuitBreakerTrip(ctx context.Context, host string) error {
+	err := p.conn.WithContext(ctx).
+		Where("host = ?", host).
+		Delete(&CircuitBreakerTrip{}).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.String("host", host),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) FindCircuitBreakerTrips(ctx context.Context, since time.Time) ([]CircuitBreakerTrip, error) {
+	trips, err := gorm.G[CircuitBreakerTrip](p.conn).
+		Where("opened_at >= ?", since).
+		Order("host ASC").
+		Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return trips, nil
+}
+
+func (p *Persistent) AddCircuitBreakerCounts(
+	ctx context.Context,
+	host string,
+	windowStart time.Time,
+	requests int64,
+	failures int64,
+) (CircuitBreakerCounts, error) {
+	var counts []CircuitBreakerCounts
+	err := p.conn.WithContext(ctx).Raw(`
+		INSERT INTO notification_circuit_breaker_counts (host, window_start, requests, failures)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (host) DO UPDATE
+		SET requests = CASE WHEN notification_circuit_breaker_counts.window_start = EXCLUDED.window_start
+				THEN notification_circuit_breaker_counts.requests + EXCLUDED.requests
+				ELSE EXCLUDED.requests END,
+			failures = CASE WHEN notification_circuit_breaker_counts.window_start = EXCLUDED.window_start
+				THEN notification_circuit_breaker_counts.failures + EXCLUDED.failures
+				ELSE EXCLUDED.failures END,
+			window_start = EXCLUDED.window_start,
+			updated_at = NOW()
+		WHERE notification_circuit_breaker_counts.window_start <= EXCLUDED.window_start
+		RETURNING host, window_start, requests, failures, updated_at`,
+		host, windowStart, requests, failures,
+	).Scan(&counts).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("host", host),
+			zap.Error(err),
+		)
+		return CircuitBreakerCounts{}, err
+	}
+	if len(counts) == 0 {
+		return CircuitBreakerCounts{Host: host}, nil
+	}
+	return counts[0], nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notification categories. Transactional notifications are the ones a
+// recipient expects from their own activity, e.g. an order confirmation
+const (
+	CategoryTransactional = "transactional"
+	CategoryMarketing     = "marketing"
+	CategoryReminder      = "reminder"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockconsent.go . ConsentProvider
+type ConsentProvider interface {
+	// ListConsents returns every consent the address gave or withdrew
+	ListConsents(ctx context.Context, address string) ([]Consent, error)
+	// SetConsent stores the consent, replacing an earlier one for the same
+	// address, category and channel
+	SetConsent(ctx context.Context, consent Consent) (Consent, error)
+}
+
+var _ ConsentProvider = (*Persistent)(nil)
+
+func (p *Persistent) ListConsents(ctx context.Context, address string) ([]Consent, error) {
+	consents, err := gorm.G[Consent](p.conn).
+		Where("address = ?", NormalizeAddress(address)).
+		Order("category ASC, channel ASC").
+		Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return consents, nil
+}
+
+func (p *Persistent) SetConsent(ctx context.Context, consent Consent) (Consent, error) {
+	consent.Address = NormalizeAddress(consent.Address)
+
+	err := p.conn.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "address"}, {Name: "category"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"opted_in", "updated_at"}),
+		}).
+		Create(&consent).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("category", consent.Category),
+			zap.String("channel", consent.Channel),
+			zap.Error(err),
+		)
+		return Consent{}, err
+	}
+	return consent, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	consentCacheKeyPattern = "notification:consents:%s"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockconsentcache.go . ConsentCacheProvider
+type ConsentCacheProvider interface {
+	Get(address string) ([]Consent, error)
+	Set(address string, consents []Consent) error
+	// Delete drops the entry of the address, e.g. once it changes a consent
+	Delete(address string)
+	Stats() CacheStats
+	// Clear drops every entry, and the statistics with them, so the next
+	// reads go to the database
+	Clear()
+}
+
+var _ ConsentCacheProvider = (*ConsentCache)(nil)
+
+// ConsentCache caches the consents of an address, so consents changed on
+// another instance take effect once the entry expires
+type ConsentCache struct {
+	engine  *ristretto.Cache[string, []Consent]
+	ttl     *CacheTTL
+	missTTL time.Duration
+	logger  *zap.Logger
+}
+
+func NewConsentCache(lc fx.Lifecycle, params CacheParams) (*ConsentCache, error) {
+	engine, err := ristretto.NewCache(&ristretto.Config[string, []Consent]{
+		NumCounters: params.Config.NumCounters,
+		MaxCost:     params.Config.MaxCost,
+		BufferItems: params.Config.BufferItems,
+		Metrics:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			engine.Close()
+			return nil
+		},
+	})
+
+	return &ConsentCache{
+		engine:  engine,
+		ttl:     params.TTL,
+		missTTL: params.Config.MissExpiredTime,
+		logger:  params.Logger,
+	}, nil
+}
+
+func (c *ConsentCache) Get(address string) ([]Consent, error) {
+	cacheKey := fmt.Sprintf(consentCacheKeyPattern, NormalizeAddress(address))
+
+	value, found := c.engine.Get(cacheKey)
+	if !found {
+		c.logger.Debug("cache miss",
+			zap.String("cache_key", cacheKey),
+		)
+		return nil, fmt.Errorf("cache key: '%s' not found", cacheKey)
+	}
+
+	c.logger.Debug("cache hit",
+		zap.Int("consents_count", len(value)),
+	)
+	return value, nil
+}
+
+// Set keeps consents for the cache TTL, or for the miss TTL when the address
+// has none
+func (c *ConsentCache) Set(address string, consents []Consent) error {
+	cacheKey := fmt.Sprintf(consentCacheKeyPattern, NormalizeAddress(address))
+
+	ttl, ok := entryTTL(len(consents), c.ttl.Get(), c.missTTL)
+	if !ok {
+		return nil
+	}
+	c.engine.SetWithTTL(cacheKey, consents, 1, ttl)
+
+	c.logger.Debug("cache set",
+		zap.Int("consents_count", len(consents)),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+func (c *ConsentCache) Delete(address string) {
+	c.engine.Del(fmt.Sprintf(consentCacheKeyPattern, NormalizeAddress(address)))
+}
+
+func (c *ConsentCache) Stats() CacheStats {
+	return newCacheStats(c.engine.Metrics)
+}
+
+func (c *ConsentCache) Clear() {
+	c.engine.Clear()
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockcontent.go . NotificationContentProvider
+type NotificationContentProvider interface {
+	StoreNotificationContent(ctx context.Context, content NotificationContent) error
+	// PurgeNotificationContents deletes the contents kept since before
+	// the time and returns how many it deleted
+	PurgeNotificationContents(ctx context.Context, before time.Time) (int64, error)
+	// FindNotificationContent returns gorm.ErrRecordNotFound when the
+	// content of the notification was not kept
+	FindNotificationContent(ctx context.Context, notificationID string) (NotificationContent, error)
+}
+
+var _ NotificationContentProvider = (*Persistent)(nil)
+
+func (p *Persistent) StoreNotificationContent(ctx context.Context, content NotificationContent) error {
+	if err := gorm.G[NotificationContent](p.conn).Create(ctx, &content); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", content.NotificationID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) PurgeNotificationContents(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := gorm.G[NotificationContent](p.conn).Where("created_at < ?", before).Delete(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.Time("before", before),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return int64(purged), nil
+}
+
+func (p *Persistent) FindNotificationContent(ctx context.Context, notificationID string) (NotificationContent, error) {
+	return gorm.G[NotificationContent](p.conn).Where("notification_id = ?", notificationID).First(ctx)
+}
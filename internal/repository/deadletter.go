@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockdeadletter.go . DeadLetterProvider
+type DeadLetterProvider interface {
+	ListDeadLetters(ctx context.Context, filter DeadLetterFilter) (DeadLetterPage, error)
+	// RedriveDeadLetters moves the dead letters matching the filter back to
+	// the channel retries, due at once with no attempts made, and returns
+	// how many were moved; Limit and Offset are ignored. Those a provider
+	// may have delivered are left unless the filter names their
+	// notification or includes them
+	RedriveDeadLetters(ctx context.Context, filter DeadLetterFilter) (int64, error)
+}
+
+var _ DeadLetterProvider = (*Persistent)(nil)
+
+// DeadLetterFilter narrows the dead letters, newest first; zero fields
+// match everything
+type DeadLetterFilter struct {
+	NotificationID string
+	Channel        string
+	// IncludeUnsafe redrives dead letters a provider may have delivered,
+	// which risks a duplicate
+	IncludeUnsafe bool
+	Limit         int
+	Offset        int
+}
+
+// DeadLetterPage is one page of dead letters with the number of dead
+// letters matching the filter across all pages
+type DeadLetterPage struct {
+	DeadLetters []DeadLetter
+	Total       int64
+}
+
+func (p *Persistent) ListDeadLetters(ctx context.Context, filter DeadLetterFilter) (DeadLetterPage, error) {
+	query := gorm.G[DeadLetter](p.conn).Scopes()
+	if filter.NotificationID != "" {
+		query = query.Where("notification_id = ?", filter.NotificationID)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+
+	total, err := query.Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("notification_id", filter.NotificationID),
+			zap.Error(err),
+		)
+		return DeadLetterPage{}, err
+	}
+
+	deadLetters, err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("notification_id", filter.NotificationID),
+			zap.Error(err),
+		)
+		return DeadLetterPage{}, err
+	}
+
+	return DeadLetterPage{DeadLetters: deadLetters, Total: total}, nil
+}
+
+func (p *Persistent) RedriveDeadLetters(ctx context.Context, filter DeadLetterFilter) (int64, error) {
+	result := p.conn.WithContext(ctx).Exec(`
+		WITH redriven AS (
+			DELETE FROM notification_dead_letters
+			WHERE (? = '' OR notification_id = ?) AND (? = '' OR channel = ?)
+			AND (? OR retry_disposition <> 'unsafe')
+			RETURNING notification_id, recipient_type, channel, tenant, notification
+		)
+		INSERT INTO notification_channel_retries (notification_id, recipient_type, channel, tenant, notification)
+		SELECT notification_id, recipient_type, channel, tenant, notification
+		FROM redriven`,
+		filter.NotificationID, filter.NotificationID, filter.Channel, filter.Channel,
+		filter.IncludeUnsafe || filter.NotificationID != "",
+	)
+	if result.Error != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("notification_id", filter.NotificationID),
+			zap.Error(result.Error),
+		)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
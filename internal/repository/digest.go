@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockdigest.go . DigestProvider
+type DigestProvider interface {
+	// AddDigestItem buffers a notification for the next digest of its
+	// recipient
+	AddDigestItem(ctx context.Context, item DigestItem) error
+	// ClaimDigest claims every buffered item of one recipient, recipient
+	// type and category, oldest first, and returns none once every item is
+	// claimed. A claim older than staleAfter is taken over, its digest
+	// presumed abandoned
+	ClaimDigest(ctx context.Context, staleAfter time.Duration) ([]DigestItem, error)
+	// DeleteDigestItems forgets the items of a digest that was sent
+	DeleteDigestItems(ctx context.Context, ids []uint) error
+}
+
+var _ DigestProvider = (*Persistent)(nil)
+
+func (p *Persistent) AddDigestItem(ctx context.Context, item DigestItem) error {
+	if err := gorm.G[DigestItem](p.conn).Create(ctx, &item); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", item.NotificationID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) ClaimDigest(ctx context.Context, staleAfter time.Duration) ([]DigestItem, error) {
+	var claimed []DigestItem
+	err := p.conn.WithContext(ctx).Raw(`
+		WITH head AS (
+			SELECT recipient_type, recipient, category
+			FROM notification_digest_items
+			WHERE claimed_at IS NULL OR claimed_at < NOW() - ? * INTERVAL '1 second'
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_digest_items AS item
+		SET claimed_at = NOW()
+		FROM head
+		WHERE item.recipient_type = head.recipient_type
+			AND item.recipient = head.recipient
+			AND item.category = head.category
+			AND (item.claimed_at IS NULL OR item.claimed_at < NOW() - ? * INTERVAL '1 second')
+		RETURNING item.*`,
+		staleAfter.Seconds(), staleAfter.Seconds(),
+	).Scan(&claimed).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	slices.SortFunc(claimed, func(a, b DigestItem) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return claimed, nil
+}
+
+func (p *Persistent) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := gorm.G[DigestItem](p.conn).Where("id IN ?", ids).Delete(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.Int("items", len(ids)),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"gorm.io/gorm"
+)
+
+var _ gorm.Plugin = (*SecretKeyEncryption)(nil)
+
+// SecretKeyEncryption is a gorm plugin encrypting NotificationPreference
+// SecretKey and NextSecretKey on write and decrypting them on read, so the
+// columns are never stored in plaintext while callers only see plaintext
+type SecretKeyEncryption struct {
+	cipher *secret.Cipher
+}
+
+func NewSecretKeyEncryption(cipher *secret.Cipher) *SecretKeyEncryption {
+	return &SecretKeyEncryption{cipher: cipher}
+}
+
+func (e *SecretKeyEncryption) Name() string {
+	return "secret_key_encryption"
+}
+
+func (e *SecretKeyEncryption) Initialize(db *gorm.DB) error {
+	return errors.Join(
+		db.Callback().Create().Before("gorm:create").Register("secret_key:encrypt", e.encrypt),
+		// Callers keep working with the plaintext they saved
+		db.Callback().Create().After("gorm:create").Register("secret_key:restore", e.decrypt),
+		db.Callback().Update().Before("gorm:update").Register("secret_key:encrypt", e.encrypt),
+		db.Callback().Update().After("gorm:update").Register("secret_key:restore", e.decrypt),
+		db.Callback().Query().After("gorm:query").Register("secret_key:decrypt", e.decrypt),
+	)
+}
+
+func (e *SecretKeyEncryption) encrypt(db *gorm.DB) {
+	e.apply(db, e.cipher.Encrypt)
+}
+
+func (e *SecretKeyEncryption) decrypt(db *gorm.DB) {
+	e.apply(db, e.cipher.Decrypt)
+}
+
+// apply transforms the preferences the statement reads or writes; other
+// models and column updates by name are left untouched
+func (e *SecretKeyEncryption) apply(db *gorm.DB, transform func(string) (string, error)) {
+	if db.Error != nil || !db.Statement.ReflectValue.IsValid() {
+		return
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			e.applyOne(db, reflect.Indirect(value.Index(i)), transform)
+		}
+	case reflect.Struct:
+		e.applyOne(db, value, transform)
+	}
+}
+
+func (e *SecretKeyEncryption) applyOne(db *gorm.DB, value reflect.Value, transform func(string) (string, error)) {
+	if !value.CanAddr() {
+		return
+	}
+	preference, ok := value.Addr().Interface().(*NotificationPreference)
+	if !ok {
+		return
+	}
+
+	secretKey, err := transform(preference.SecretKey)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	nextSecretKey, err := transform(preference.NextSecretKey)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	preference.SecretKey = secretKey
+	preference.NextSecretKey = nextSecretKey
+}
+
+// secretKeyColumns are the encrypted columns of notification_preferences
+var secretKeyColumns = []string{"secret_key", "next_secret_key"}
+
+// ReencryptSecretKeys rewrites every secret_key and next_secret_key not
+// written with the current key, including soft-deleted rows, and returns how
+// many rows changed. Raw column values are read so it works whether or not
+// the plugin is installed
+func ReencryptSecretKeys(ctx context.Context, conn *gorm.DB, cipher *secret.Cipher) (int, error) {
+	var rows []struct {
+		ID            uint
+		SecretKey     string
+		NextSecretKey string
+	}
+	err := conn.WithContext(ctx).
+		Table("notification_preferences").
+		Select("id", "COALESCE(secret_key, '') AS secret_key", "COALESCE(next_secret_key, '') AS next_secret_key").
+		Where("secret_key IS NOT NULL OR next_secret_key IS NOT NULL").
+		Order("id").
+		Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var updated int
+	for _, row := range rows {
+		columns := map[string]any{}
+		for i, value := range []string{row.SecretKey, row.NextSecretKey} {
+			if !cipher.Stale(value) {
+				continue
+			}
+
+			plaintext, err := cipher.Decrypt(value)
+			if err != nil {
+				return updated, err
+			}
+			encrypted, err := cipher.Encrypt(plaintext)
+			if err != nil {
+				return updated, err
+			}
+			columns[secretKeyColumns[i]] = encrypted
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		err = conn.WithContext(ctx).
+			Table("notification_preferences").
+			Where("id = ?", row.ID).
+			Updates(columns).Error
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
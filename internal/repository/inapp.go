@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockinapp.go . InAppNotificationProvider
+type InAppNotificationProvider interface {
+	// CreateInAppNotification stores the notification and returns it with
+	// its id and creation time
+	CreateInAppNotification(ctx context.Context, notification InAppNotification) (InAppNotification, error)
+	ListInAppNotifications(ctx context.Context, filter InAppFilter) (InAppPage, error)
+	// SetInAppNotificationRead marks a notification the tenant sent the
+	// recipient read or unread and returns it; a notification already read
+	// keeps its read_at. gorm.ErrRecordNotFound when the recipient has no
+	// notification with the id from the tenant
+	SetInAppNotificationRead(ctx context.Context, tenant string, recipient string, id uint, read bool) (InAppNotification, error)
+}
+
+var _ InAppNotificationProvider = (*Persistent)(nil)
+
+// InAppFilter narrows the notifications the tenant sent a recipient, newest
+// first
+type InAppFilter struct {
+	Tenant     string
+	Recipient  string
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// InAppPage is one page of in-app notifications with the number matching
+// the filter across all pages and the number the recipient has not read
+type InAppPage struct {
+	Notifications []InAppNotification
+	Total         int64
+	Unread        int64
+}
+
+func (p *Persistent) CreateInAppNotification(ctx context.Context, notification InAppNotification) (InAppNotification, error) {
+	if err := gorm.G[InAppNotification](p.conn).Create(ctx, &notification); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", notification.NotificationID),
+			zap.Error(err),
+		)
+		return InAppNotification{}, err
+	}
+	return notification, nil
+}
+
+func (p *Persistent) ListInAppNotifications(ctx context.Context, filter InAppFilter) (InAppPage, error) {
+	unreadQuery := gorm.G[InAppNotification](p.conn).Where("tenant = ? AND recipient = ? AND read_at IS NULL", filter.Tenant, filter.Recipient)
+	unread, err := unreadQuery.Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return InAppPage{}, err
+	}
+
+	query := gorm.G[InAppNotification](p.conn).Where("tenant = ? AND recipient = ?", filter.Tenant, filter.Recipient)
+	total := unread
+	if filter.UnreadOnly {
+		query = unreadQuery
+	} else {
+		total, err = query.Count(ctx, "*")
+		if err != nil {
+			logging.From(ctx, p.logger).Error("database query failed",
+				zap.Error(err),
+			)
+			return InAppPage{}, err
+		}
+	}
+
+	notifications, err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return InAppPage{}, err
+	}
+
+	return InAppPage{Notifications: notifications, Total: total, Unread: unread}, nil
+}
+
+func (p *Persistent) SetInAppNotificationRead(ctx context.Context, tenant string, recipient string, id uint, read bool) (InAppNotification, error) {
+	var updated []InAppNotification
+	err := p.conn.WithContext(ctx).Raw(`
+		UPDATE in_app_notifications
+		SET read_at = CASE WHEN ? THEN COALESCE(read_at, NOW()) END
+		WHERE id = ? AND tenant = ? AND recipient = ?
+		RETURNING *`,
+		read, id, tenant, recipient,
+	).Scan(&updated).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Uint("in_app_notification_id", id),
+			zap.Error(err),
+		)
+		return InAppNotification{}, err
+	}
+	if len(updated) == 0 {
+		return InAppNotification{}, gorm.ErrRecordNotFound
+	}
+
+	return updated[0], nil
+}
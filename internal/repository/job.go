@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Job kinds; a notify job delivers one notification of a notify request
+// that did not wait for it
+const (
+	JobKindBatch  = "batch"
+	JobKindNotify = "notify"
+)
+
+// Job states; a job ends done when every item was sent, failed when none
+// was and partial otherwise
+const (
+	JobStateQueued     = "queued"
+	JobStateInProgress = "in_progress"
+	JobStateDone       = "done"
+	JobStatePartial    = "partial"
+	JobStateFailed     = "failed"
+)
+
+// Job item statuses; rejected items were invalid and never sent
+const (
+	JobItemSent     = "sent"
+	JobItemFailed   = "failed"
+	JobItemRejected = "rejected"
+)
+
+var jobItemStatuses = map[string]bool{JobItemSent: true, JobItemFailed: true, JobItemRejected: true}
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockjob.go . JobProvider
+type JobProvider interface {
+	CreateJob(ctx context.Context, job Job) error
+	UpdateJob(ctx context.Context, id string, update JobUpdate) error
+	RecordJobItem(ctx context.Context, item JobItem) error
+	// FindJob returns the job of the quota subject, or
+	// gorm.ErrRecordNotFound for an unknown id or a job of another subject,
+	// so callers cannot tell a job of another tenant exists
+	FindJob(ctx context.Context, id string, subject string, filter JobItemFilter) (JobDetail, error)
+	// HeartbeatJobs tells the jobs are still being delivered
+	HeartbeatJobs(ctx context.Context, ids []string) error
+	// ClaimStaleJob claims an unfinished job without a heartbeat for
+	// staleAfter, left by an instance that died, reporting false when none
+	// is left
+	ClaimStaleJob(ctx context.Context, staleAfter time.Duration) (Job, bool, error)
+}
+
+var _ JobProvider = (*Persistent)(nil)
+
+// JobUpdate changes the set fields of a job
+type JobUpdate struct {
+	State      string
+	Total      *int
+	FinishedAt *time.Time
+}
+
+// JobItemFilter pages the items of a job in item order; an empty Status
+// matches every item
+type JobItemFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// JobItemCounts counts the items of a job by status
+type JobItemCounts struct {
+	Sent     int
+	Failed   int
+	Rejected int
+}
+
+// JobDetail is a job with its item counts and one page of its items
+type JobDetail struct {
+	Job    Job
+	Counts JobItemCounts
+	Items  []JobItem
+}
+
+// CompletedJobState is the state of a job whose items all have an outcome
+func CompletedJobState(counts JobItemCounts) string {
+	switch {
+	case counts.Failed+counts.Rejected == 0:
+		return JobStateDone
+	case counts.Sent == 0:
+		return JobStateFailed
+	default:
+		return JobStatePartial
+	}
+}
+
+// InterruptedJobState is the state of a job whose delivery was cut short,
+// its remaining items having no outcome
+func InterruptedJobState(counts JobItemCounts) string {
+	if counts.Sent == 0 {
+		return JobStateFailed
+	}
+	return JobStatePartial
+}
+
+// ParseJobItemStatus accepts the job item statuses and the empty string
+func ParseJobItemStatus(status string) (string, error) {
+	if status != "" && !jobItemStatuses[status] {
+		return "", fmt.Errorf("job item status: '%s' not supported, use sent, failed or rejected", status)
+	}
+	return status, nil
+}
+
+func (p *Persistent) CreateJob(ctx context.Context, job Job) error {
+	if err := gorm.G[Job](p.conn).Create(ctx, &job); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("job_id", job.ID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) UpdateJob(ctx context.Context, id string, update JobUpdate) error {
+	fields := map[string]any{}
+	if update.State != "" {
+		fields["state"] = update.State
+	}
+	if update.Total != nil {
+		fields["total"] = *update.Total
+	}
+	if update.FinishedAt != nil {
+		fields["finished_at"] = *update.FinishedAt
+	}
+
+	err := p.conn.WithContext(ctx).Model(&Job{}).Where("id = ?", id).Updates(fields).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("job_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) RecordJobItem(ctx context.Context, item JobItem) error {
+	if err := gorm.G[JobItem](p.conn).Create(ctx, &item); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("job_id", item.JobID),
+			zap.Int("job_item", item.Item),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) FindJob(ctx context.Context, id string, subject string, filter JobItemFilter) (JobDetail, error) {
+	job, err := gorm.G[Job](p.conn).Where("id = ? AND subject = ?", id, subject).First(ctx)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.From(ctx, p.logger).Error("database query failed",
+				zap.String("job_id", id),
+				zap.Error(err),
+			)
+		}
+		return JobDetail{}, err
+	}
+
+	var statusCounts []struct {
+		Status string
+		Count  int
+	}
+	err = p.conn.WithContext(ctx).Model(&JobItem{}).
+		Select("status, COUNT(*) AS count").
+		Where("job_id = ?", id).
+		Group("status").
+		Scan(&statusCounts).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("job_id", id),
+			zap.Error(err),
+		)
+		return JobDetail{}, err
+	}
+
+	detail := JobDetail{Job: job}
+	for _, statusCount := range statusCounts {
+		switch statusCount.Status {
+		case JobItemSent:
+			detail.Counts.Sent = statusCount.Count
+		case JobItemFailed:
+			detail.Counts.Failed = statusCount.Count
+		case JobItemRejected:
+			detail.Counts.Rejected = statusCount.Count
+		}
+	}
+
+	query := gorm.G[JobItem](p.conn).Where("job_id = ?", id)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	detail.Items, err = query.Order("item").Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("job_id", id),
+			zap.Error(err),
+		)
+		return JobDetail{}, err
+	}
+
+	return detail, nil
+}
+
+func (p *Persistent) HeartbeatJobs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := p.conn.WithContext(ctx).Model(&Job{}).
+		Where("id IN ? AND finished_at IS NULL", ids).
+		UpdateColumn("heartbeat_at", gorm.Expr("NOW()")).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Int("jobs", len(ids)),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) ClaimStaleJob(ctx context.Context, staleAfter time.Duration) (Job, bool, error) {
+	var claimed []Job
+	err := p.conn.WithContext(ctx).Raw(`
+		WITH stale AS (
+			SELECT id
+			FROM notification_jobs
+			WHERE finished_at IS NULL
+				AND heartbeat_at < NOW() - ? * INTERVAL '1 second'
+			ORDER BY heartbeat_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_jobs AS job
+		SET heartbeat_at = NOW()
+		FROM stale
+		WHERE job.id = stale.id
+		RETURNING job.*`,
+		staleAfter.Seconds(),
+	).Scan(&claimed).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Error(err),
+		)
+		return Job{}, false, err
+	}
+
+	if len(claimed) == 0 {
+		return Job{}, false, nil
+	}
+	return claimed[0], true, nil
+}
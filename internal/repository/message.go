@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Message states. A message is pending while its notification is being
+// delivered, sent once delivered and failed when the delivery failed after
+// a provider may have accepted it
+const (
+	MessageStatePending = "pending"
+	MessageStateSent    = "sent"
+	MessageStateFailed  = "failed"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockmessage.go . MessageProvider
+type MessageProvider interface {
+	// ClaimMessage records the message as pending delivery by its
+	// notification and reports true; when the message id is already known it
+	// returns the earlier record and false instead. A pending claim not
+	// updated for staleAfter is taken over, its delivery presumed abandoned
+	ClaimMessage(ctx context.Context, message NotificationMessage, staleAfter time.Duration) (NotificationMessage, bool, error)
+	// CompleteMessage stores the state, attempts and channels of a message
+	// still claimed by its notification
+	CompleteMessage(ctx context.Context, message NotificationMessage) error
+	// ReleaseMessage forgets a pending message, so its id may be sent again
+	ReleaseMessage(ctx context.Context, messageID string, notificationID string) error
+}
+
+var _ MessageProvider = (*Persistent)(nil)
+
+func (p *Persistent) ClaimMessage(
+	ctx context.Context,
+	message NotificationMessage,
+	staleAfter time.Duration,
+) (NotificationMessage, bool, error) {
+	// A released message may disappear between the insert and the lookup, in
+	// which case it is claimed again
+	for range 2 {
+		var claimed []NotificationMessage
+		err := p.conn.WithContext(ctx).Raw(`
+			INSERT INTO notification_messages (message_id, notification_id, recipient_type, state)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (message_id) DO UPDATE
+			SET notification_id = EXCLUDED.notification_id,
+				recipient_type = EXCLUDED.recipient_type,
+				attempts = 0,
+				channels = '',
+				updated_at = NOW()
+			WHERE notification_messages.state = ?
+				AND notification_messages.updated_at < NOW() - ? * INTERVAL '1 second'
+			RETURNING *`,
+			message.MessageID, message.NotificationID, message.RecipientType, MessageStatePending,
+			MessageStatePending, staleAfter.Seconds(),
+		).Scan(&claimed).Error
+		if err != nil {
+			logging.From(ctx, p.logger).Error("database insert failed",
+				zap.String("message_id", message.MessageID),
+				zap.Error(err),
+			)
+			return NotificationMessage{}, false, err
+		}
+		if len(claimed) > 0 {
+			return claimed[0], true, nil
+		}
+
+		existing, err := gorm.G[NotificationMessage](p.conn).Where("message_id = ?", message.MessageID).First(ctx)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			logging.From(ctx, p.logger).Error("database query failed",
+				zap.String("message_id", message.MessageID),
+				zap.Error(err),
+			)
+			return NotificationMessage{}, false, err
+		}
+		return existing, false, nil
+	}
+
+	return NotificationMessage{}, false, gorm.ErrRecordNotFound
+}
+
+func (p *Persistent) CompleteMessage(ctx context.Context, message NotificationMessage) error {
+	err := p.conn.WithContext(ctx).Model(&NotificationMessage{}).
+		Where("message_id = ? AND notification_id = ?", message.MessageID, message.NotificationID).
+		Updates(map[string]any{
+			"state":    message.State,
+			"attempts": message.Attempts,
+			"channels": message.Channels,
+		}).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("message_id", message.MessageID),
+			zap.String("notification_id", message.NotificationID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) ReleaseMessage(ctx context.Context, messageID string, notificationID string) error {
+	_, err := gorm.G[NotificationMessage](p.conn).
+		Where("message_id = ? AND notification_id = ? AND state = ?", messageID, notificationID, MessageStatePending).
+		Delete(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.String("message_id", messageID),
+			zap.String("notification_id", notificationID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
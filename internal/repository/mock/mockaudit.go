@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: AuditProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockaudit.go . AuditProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuditProvider is a mock of AuditProvider interface.
+type MockAuditProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditProviderMockRecorder is the mock recorder for MockAuditProvider.
+type MockAuditProviderMockRecorder struct {
+	mock *MockAuditProvider
+}
+
+// NewMockAuditProvider creates a new mock instance.
+func NewMockAuditProvider(ctrl *gomock.Controller) *MockAuditProvider {
+	mock := &MockAuditProvider{ctrl: ctrl}
+	mock.recorder = &MockAuditProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditProvider) EXPECT() *MockAuditProviderMockRecorder {
+	return m.recorder
+}
+
+// FindAuditEntries mocks base method.
+func (m *MockAuditProvider) FindAuditEntries(ctx context.Context, filter repository.AuditFilter) ([]repository.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuditEntries", ctx, filter)
+	ret0, _ := ret[0].([]repository.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuditEntries indicates an expected call of FindAuditEntries.
+func (mr *MockAuditProviderMockRecorder) FindAuditEntries(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuditEntries", reflect.TypeOf((*MockAuditProvider)(nil).FindAuditEntries), ctx, filter)
+}
+
+// RecordAudit mocks base method.
+func (m *MockAuditProvider) RecordAudit(ctx context.Context, entry repository.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockAuditProviderMockRecorder) RecordAudit(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockAuditProvider)(nil).RecordAudit), ctx, entry)
+}
@@ -40,6 +40,18 @@ func (m *MockCacheProvider) EXPECT() *MockCacheProviderMockRecorder {
 	return m.recorder
 }
 
+// Clear mocks base method.
+func (m *MockCacheProvider) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockCacheProviderMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCacheProvider)(nil).Clear))
+}
+
 // Get mocks base method.
 func (m *MockCacheProvider) Get(key repository.NotificationProvider) ([]repository.NotificationPreference, error) {
 	m.ctrl.T.Helper()
@@ -68,3 +80,17 @@ func (mr *MockCacheProviderMockRecorder) Set(key, values any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCacheProvider)(nil).Set), key, values)
 }
+
+// Stats mocks base method.
+func (m *MockCacheProvider) Stats() repository.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCacheProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCacheProvider)(nil).Stats))
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: CircuitBreakerTripProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockcircuitbreaker.go . CircuitBreakerTripProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCircuitBreakerTripProvider is a mock of CircuitBreakerTripProvider interface.
+type MockCircuitBreakerTripProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockCircuitBreakerTripProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockCircuitBreakerTripProviderMockRecorder is the mock recorder for MockCircuitBreakerTripProvider.
+type MockCircuitBreakerTripProviderMockRecorder struct {
+	mock *MockCircuitBreakerTripProvider
+}
+
+// NewMockCircuitBreakerTripProvider creates a new mock instance.
+func NewMockCircuitBreakerTripProvider(ctrl *gomock.Controller) *MockCircuitBreakerTripProvider {
+	mock := &MockCircuitBreakerTripProvider{ctrl: ctrl}
+	mock.recorder = &MockCircuitBreakerTripProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCircuitBreakerTripProvider) EXPECT() *MockCircuitBreakerTripProviderMockRecorder {
+	return m.recorder
+}
+
+// AddCircuitBreakerCounts mocks base method.
+func (m *MockCircuitBreakerTripProvider) AddCircuitBreakerCounts(ctx context.Context, host string, windowStart time.Time, requests, failures int64) (repository.CircuitBreakerCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCircuitBreakerCounts", ctx, host, windowStart, requests, failures)
+	ret0, _ := ret[0].(repository.CircuitBreakerCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCircuitBreakerCounts indicates an expected call of AddCircuitBreakerCounts.
+func (mr *MockCircuitBreakerTripProviderMockRecorder) AddCircuitBreakerCounts(ctx, host, windowStart, requests, failures any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCircuitBreakerCounts", reflect.TypeOf((*MockCircuitBreakerTripProvider)(nil).AddCircuitBreakerCounts), ctx, host, windowStart, requests, failures)
+}
+
+// ClearCircuitBreakerTrip mocks base method.
+func (m *MockCircuitBreakerTripProvider) ClearCircuitBreakerTrip(ctx context.Context, host string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCircuitBreakerTrip", ctx, host)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCircuitBreakerTrip indicates an expected call of ClearCircuitBreakerTrip.
+func (mr *MockCircuitBreakerTripProviderMockRecorder) ClearCircuitBreakerTrip(ctx, host any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCircuitBreakerTrip", reflect.TypeOf((*MockCircuitBreakerTripProvider)(nil).ClearCircuitBreakerTrip), ctx, host)
+}
+
+// FindCircuitBreakerTrips mocks base method.
+func (m *MockCircuitBreakerTripProvider) FindCircuitBreakerTrips(ctx context.Context, since time.Time) ([]repository.CircuitBreakerTrip, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCircuitBreakerTrips", ctx, since)
+	ret0, _ := ret[0].([]repository.CircuitBreakerTrip)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCircuitBreakerTrips indicates an expected call of FindCircuitBreakerTrips.
+func (mr *MockCircuitBreakerTripProviderMockRecorder) FindCircuitBreakerTrips(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCircuitBreakerTrips", reflect.TypeOf((*MockCircuitBreakerTripProvider)(nil).FindCircuitBreakerTrips), ctx, since)
+}
+
+// RecordCircuitBreakerTrip mocks base method.
+func (m *MockCircuitBreakerTripProvider) RecordCircuitBreakerTrip(ctx context.Context, host string, openedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCircuitBreakerTrip", ctx, host, openedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCircuitBreakerTrip indicates an expected call of RecordCircuitBreakerTrip.
+func (mr *MockCircuitBreakerTripProviderMockRecorder) RecordCircuitBreakerTrip(ctx, host, openedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCircuitBreakerTrip", reflect.TypeOf((*MockCircuitBreakerTripProvider)(nil).RecordCircuitBreakerTrip), ctx, host, openedAt)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: ConsentProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockconsent.go . ConsentProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConsentProvider is a mock of ConsentProvider interface.
+type MockConsentProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsentProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockConsentProviderMockRecorder is the mock recorder for MockConsentProvider.
+type MockConsentProviderMockRecorder struct {
+	mock *MockConsentProvider
+}
+
+// NewMockConsentProvider creates a new mock instance.
+func NewMockConsentProvider(ctrl *gomock.Controller) *MockConsentProvider {
+	mock := &MockConsentProvider{ctrl: ctrl}
+	mock.recorder = &MockConsentProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsentProvider) EXPECT() *MockConsentProviderMockRecorder {
+	return m.recorder
+}
+
+// ListConsents mocks base method.
+func (m *MockConsentProvider) ListConsents(ctx context.Context, address string) ([]repository.Consent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConsents", ctx, address)
+	ret0, _ := ret[0].([]repository.Consent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConsents indicates an expected call of ListConsents.
+func (mr *MockConsentProviderMockRecorder) ListConsents(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsents", reflect.TypeOf((*MockConsentProvider)(nil).ListConsents), ctx, address)
+}
+
+// SetConsent mocks base method.
+func (m *MockConsentProvider) SetConsent(ctx context.Context, consent repository.Consent) (repository.Consent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetConsent", ctx, consent)
+	ret0, _ := ret[0].(repository.Consent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetConsent indicates an expected call of SetConsent.
+func (mr *MockConsentProviderMockRecorder) SetConsent(ctx, consent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConsent", reflect.TypeOf((*MockConsentProvider)(nil).SetConsent), ctx, consent)
+}
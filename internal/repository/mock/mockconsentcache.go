@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: ConsentCacheProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockconsentcache.go . ConsentCacheProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConsentCacheProvider is a mock of ConsentCacheProvider interface.
+type MockConsentCacheProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsentCacheProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockConsentCacheProviderMockRecorder is the mock recorder for MockConsentCacheProvider.
+type MockConsentCacheProviderMockRecorder struct {
+	mock *MockConsentCacheProvider
+}
+
+// NewMockConsentCacheProvider creates a new mock instance.
+func NewMockConsentCacheProvider(ctrl *gomock.Controller) *MockConsentCacheProvider {
+	mock := &MockConsentCacheProvider{ctrl: ctrl}
+	mock.recorder = &MockConsentCacheProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsentCacheProvider) EXPECT() *MockConsentCacheProviderMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockConsentCacheProvider) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockConsentCacheProviderMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockConsentCacheProvider)(nil).Clear))
+}
+
+// Delete mocks base method.
+func (m *MockConsentCacheProvider) Delete(address string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Delete", address)
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockConsentCacheProviderMockRecorder) Delete(address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockConsentCacheProvider)(nil).Delete), address)
+}
+
+// Get mocks base method.
+func (m *MockConsentCacheProvider) Get(address string) ([]repository.Consent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", address)
+	ret0, _ := ret[0].([]repository.Consent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockConsentCacheProviderMockRecorder) Get(address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockConsentCacheProvider)(nil).Get), address)
+}
+
+// Set mocks base method.
+func (m *MockConsentCacheProvider) Set(address string, consents []repository.Consent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", address, consents)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockConsentCacheProviderMockRecorder) Set(address, consents any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockConsentCacheProvider)(nil).Set), address, consents)
+}
+
+// Stats mocks base method.
+func (m *MockConsentCacheProvider) Stats() repository.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockConsentCacheProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockConsentCacheProvider)(nil).Stats))
+}
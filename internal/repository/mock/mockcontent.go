@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: NotificationContentProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockcontent.go . NotificationContentProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationContentProvider is a mock of NotificationContentProvider interface.
+type MockNotificationContentProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationContentProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationContentProviderMockRecorder is the mock recorder for MockNotificationContentProvider.
+type MockNotificationContentProviderMockRecorder struct {
+	mock *MockNotificationContentProvider
+}
+
+// NewMockNotificationContentProvider creates a new mock instance.
+func NewMockNotificationContentProvider(ctrl *gomock.Controller) *MockNotificationContentProvider {
+	mock := &MockNotificationContentProvider{ctrl: ctrl}
+	mock.recorder = &MockNotificationContentProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationContentProvider) EXPECT() *MockNotificationContentProviderMockRecorder {
+	return m.recorder
+}
+
+// FindNotificationContent mocks base method.
+func (m *MockNotificationContentProvider) FindNotificationContent(ctx context.Context, notificationID string) (repository.NotificationContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNotificationContent", ctx, notificationID)
+	ret0, _ := ret[0].(repository.NotificationContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNotificationContent indicates an expected call of FindNotificationContent.
+func (mr *MockNotificationContentProviderMockRecorder) FindNotificationContent(ctx, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNotificationContent", reflect.TypeOf((*MockNotificationContentProvider)(nil).FindNotificationContent), ctx, notificationID)
+}
+
+// PurgeNotificationContents mocks base method.
+func (m *MockNotificationContentProvider) PurgeNotificationContents(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeNotificationContents", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeNotificationContents indicates an expected call of PurgeNotificationContents.
+func (mr *MockNotificationContentProviderMockRecorder) PurgeNotificationContents(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeNotificationContents", reflect.TypeOf((*MockNotificationContentProvider)(nil).PurgeNotificationContents), ctx, before)
+}
+
+// StoreNotificationContent mocks base method.
+func (m *MockNotificationContentProvider) StoreNotificationContent(ctx context.Context, content repository.NotificationContent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreNotificationContent", ctx, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreNotificationContent indicates an expected call of StoreNotificationContent.
+func (mr *MockNotificationContentProviderMockRecorder) StoreNotificationContent(ctx, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreNotificationContent", reflect.TypeOf((*MockNotificationContentProvider)(nil).StoreNotificationContent), ctx, content)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: DeadLetterProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockdeadletter.go . DeadLetterProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDeadLetterProvider is a mock of DeadLetterProvider interface.
+type MockDeadLetterProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLetterProviderMockRecorder is the mock recorder for MockDeadLetterProvider.
+type MockDeadLetterProviderMockRecorder struct {
+	mock *MockDeadLetterProvider
+}
+
+// NewMockDeadLetterProvider creates a new mock instance.
+func NewMockDeadLetterProvider(ctrl *gomock.Controller) *MockDeadLetterProvider {
+	mock := &MockDeadLetterProvider{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterProvider) EXPECT() *MockDeadLetterProviderMockRecorder {
+	return m.recorder
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockDeadLetterProvider) ListDeadLetters(ctx context.Context, filter repository.DeadLetterFilter) (repository.DeadLetterPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, filter)
+	ret0, _ := ret[0].(repository.DeadLetterPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockDeadLetterProviderMockRecorder) ListDeadLetters(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockDeadLetterProvider)(nil).ListDeadLetters), ctx, filter)
+}
+
+// RedriveDeadLetters mocks base method.
+func (m *MockDeadLetterProvider) RedriveDeadLetters(ctx context.Context, filter repository.DeadLetterFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedriveDeadLetters", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedriveDeadLetters indicates an expected call of RedriveDeadLetters.
+func (mr *MockDeadLetterProviderMockRecorder) RedriveDeadLetters(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedriveDeadLetters", reflect.TypeOf((*MockDeadLetterProvider)(nil).RedriveDeadLetters), ctx, filter)
+}
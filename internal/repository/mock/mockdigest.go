@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: DigestProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockdigest.go . DigestProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDigestProvider is a mock of DigestProvider interface.
+type MockDigestProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockDigestProviderMockRecorder is the mock recorder for MockDigestProvider.
+type MockDigestProviderMockRecorder struct {
+	mock *MockDigestProvider
+}
+
+// NewMockDigestProvider creates a new mock instance.
+func NewMockDigestProvider(ctrl *gomock.Controller) *MockDigestProvider {
+	mock := &MockDigestProvider{ctrl: ctrl}
+	mock.recorder = &MockDigestProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestProvider) EXPECT() *MockDigestProviderMockRecorder {
+	return m.recorder
+}
+
+// AddDigestItem mocks base method.
+func (m *MockDigestProvider) AddDigestItem(ctx context.Context, item repository.DigestItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDigestItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDigestItem indicates an expected call of AddDigestItem.
+func (mr *MockDigestProviderMockRecorder) AddDigestItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDigestItem", reflect.TypeOf((*MockDigestProvider)(nil).AddDigestItem), ctx, item)
+}
+
+// ClaimDigest mocks base method.
+func (m *MockDigestProvider) ClaimDigest(ctx context.Context, staleAfter time.Duration) ([]repository.DigestItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDigest", ctx, staleAfter)
+	ret0, _ := ret[0].([]repository.DigestItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDigest indicates an expected call of ClaimDigest.
+func (mr *MockDigestProviderMockRecorder) ClaimDigest(ctx, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDigest", reflect.TypeOf((*MockDigestProvider)(nil).ClaimDigest), ctx, staleAfter)
+}
+
+// DeleteDigestItems mocks base method.
+func (m *MockDigestProvider) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDigestItems", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDigestItems indicates an expected call of DeleteDigestItems.
+func (mr *MockDigestProviderMockRecorder) DeleteDigestItems(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDigestItems", reflect.TypeOf((*MockDigestProvider)(nil).DeleteDigestItems), ctx, ids)
+}
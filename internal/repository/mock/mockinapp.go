@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: InAppNotificationProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockinapp.go . InAppNotificationProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInAppNotificationProvider is a mock of InAppNotificationProvider interface.
+type MockInAppNotificationProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockInAppNotificationProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockInAppNotificationProviderMockRecorder is the mock recorder for MockInAppNotificationProvider.
+type MockInAppNotificationProviderMockRecorder struct {
+	mock *MockInAppNotificationProvider
+}
+
+// NewMockInAppNotificationProvider creates a new mock instance.
+func NewMockInAppNotificationProvider(ctrl *gomock.Controller) *MockInAppNotificationProvider {
+	mock := &MockInAppNotificationProvider{ctrl: ctrl}
+	mock.recorder = &MockInAppNotificationProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInAppNotificationProvider) EXPECT() *MockInAppNotificationProviderMockRecorder {
+	return m.recorder
+}
+
+// CreateInAppNotification mocks base method.
+func (m *MockInAppNotificationProvider) CreateInAppNotification(ctx context.Context, notification repository.InAppNotification) (repository.InAppNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInAppNotification", ctx, notification)
+	ret0, _ := ret[0].(repository.InAppNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInAppNotification indicates an expected call of CreateInAppNotification.
+func (mr *MockInAppNotificationProviderMockRecorder) CreateInAppNotification(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInAppNotification", reflect.TypeOf((*MockInAppNotificationProvider)(nil).CreateInAppNotification), ctx, notification)
+}
+
+// ListInAppNotifications mocks base method.
+func (m *MockInAppNotificationProvider) ListInAppNotifications(ctx context.Context, filter repository.InAppFilter) (repository.InAppPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInAppNotifications", ctx, filter)
+	ret0, _ := ret[0].(repository.InAppPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInAppNotifications indicates an expected call of ListInAppNotifications.
+func (mr *MockInAppNotificationProviderMockRecorder) ListInAppNotifications(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInAppNotifications", reflect.TypeOf((*MockInAppNotificationProvider)(nil).ListInAppNotifications), ctx, filter)
+}
+
+// SetInAppNotificationRead mocks base method.
+func (m *MockInAppNotificationProvider) SetInAppNotificationRead(ctx context.Context, tenant, recipient string, id uint, read bool) (repository.InAppNotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInAppNotificationRead", ctx, tenant, recipient, id, read)
+	ret0, _ := ret[0].(repository.InAppNotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetInAppNotificationRead indicates an expected call of SetInAppNotificationRead.
+func (mr *MockInAppNotificationProviderMockRecorder) SetInAppNotificationRead(ctx, tenant, recipient, id, read any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInAppNotificationRead", reflect.TypeOf((*MockInAppNotificationProvider)(nil).SetInAppNotificationRead), ctx, tenant, recipient, id, read)
+}
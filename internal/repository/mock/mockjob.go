@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: JobProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockjob.go . JobProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockJobProvider is a mock of JobProvider interface.
+type MockJobProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockJobProviderMockRecorder is the mock recorder for MockJobProvider.
+type MockJobProviderMockRecorder struct {
+	mock *MockJobProvider
+}
+
+// NewMockJobProvider creates a new mock instance.
+func NewMockJobProvider(ctrl *gomock.Controller) *MockJobProvider {
+	mock := &MockJobProvider{ctrl: ctrl}
+	mock.recorder = &MockJobProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobProvider) EXPECT() *MockJobProviderMockRecorder {
+	return m.recorder
+}
+
+// ClaimStaleJob mocks base method.
+func (m *MockJobProvider) ClaimStaleJob(ctx context.Context, staleAfter time.Duration) (repository.Job, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimStaleJob", ctx, staleAfter)
+	ret0, _ := ret[0].(repository.Job)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimStaleJob indicates an expected call of ClaimStaleJob.
+func (mr *MockJobProviderMockRecorder) ClaimStaleJob(ctx, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimStaleJob", reflect.TypeOf((*MockJobProvider)(nil).ClaimStaleJob), ctx, staleAfter)
+}
+
+// CreateJob mocks base method.
+func (m *MockJobProvider) CreateJob(ctx context.Context, job repository.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockJobProviderMockRecorder) CreateJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockJobProvider)(nil).CreateJob), ctx, job)
+}
+
+// FindJob mocks base method.
+func (m *MockJobProvider) FindJob(ctx context.Context, id, subject string, filter repository.JobItemFilter) (repository.JobDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJob", ctx, id, subject, filter)
+	ret0, _ := ret[0].(repository.JobDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJob indicates an expected call of FindJob.
+func (mr *MockJobProviderMockRecorder) FindJob(ctx, id, subject, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockJobProvider)(nil).FindJob), ctx, id, subject, filter)
+}
+
+// HeartbeatJobs mocks base method.
+func (m *MockJobProvider) HeartbeatJobs(ctx context.Context, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeartbeatJobs", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HeartbeatJobs indicates an expected call of HeartbeatJobs.
+func (mr *MockJobProviderMockRecorder) HeartbeatJobs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeartbeatJobs", reflect.TypeOf((*MockJobProvider)(nil).HeartbeatJobs), ctx, ids)
+}
+
+// RecordJobItem mocks base method.
+func (m *MockJobProvider) RecordJobItem(ctx context.Context, item repository.JobItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordJobItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordJobItem indicates an expected call of RecordJobItem.
+func (mr *MockJobProviderMockRecorder) RecordJobItem(ctx, item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordJobItem", reflect.TypeOf((*MockJobProvider)(nil).RecordJobItem), ctx, item)
+}
+
+// UpdateJob mocks base method.
+func (m *MockJobProvider) UpdateJob(ctx context.Context, id string, update repository.JobUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", ctx, id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockJobProviderMockRecorder) UpdateJob(ctx, id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockJobProvider)(nil).UpdateJob), ctx, id, update)
+}
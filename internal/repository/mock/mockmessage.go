@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: MessageProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockmessage.go . MessageProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMessageProvider is a mock of MessageProvider interface.
+type MockMessageProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockMessageProviderMockRecorder is the mock recorder for MockMessageProvider.
+type MockMessageProviderMockRecorder struct {
+	mock *MockMessageProvider
+}
+
+// NewMockMessageProvider creates a new mock instance.
+func NewMockMessageProvider(ctrl *gomock.Controller) *MockMessageProvider {
+	mock := &MockMessageProvider{ctrl: ctrl}
+	mock.recorder = &MockMessageProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageProvider) EXPECT() *MockMessageProviderMockRecorder {
+	return m.recorder
+}
+
+// ClaimMessage mocks base method.
+func (m *MockMessageProvider) ClaimMessage(ctx context.Context, message repository.NotificationMessage, staleAfter time.Duration) (repository.NotificationMessage, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMessage", ctx, message, staleAfter)
+	ret0, _ := ret[0].(repository.NotificationMessage)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimMessage indicates an expected call of ClaimMessage.
+func (mr *MockMessageProviderMockRecorder) ClaimMessage(ctx, message, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMessage", reflect.TypeOf((*MockMessageProvider)(nil).ClaimMessage), ctx, message, staleAfter)
+}
+
+// CompleteMessage mocks base method.
+func (m *MockMessageProvider) CompleteMessage(ctx context.Context, message repository.NotificationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteMessage indicates an expected call of CompleteMessage.
+func (mr *MockMessageProviderMockRecorder) CompleteMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteMessage", reflect.TypeOf((*MockMessageProvider)(nil).CompleteMessage), ctx, message)
+}
+
+// ReleaseMessage mocks base method.
+func (m *MockMessageProvider) ReleaseMessage(ctx context.Context, messageID, notificationID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseMessage", ctx, messageID, notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseMessage indicates an expected call of ReleaseMessage.
+func (mr *MockMessageProviderMockRecorder) ReleaseMessage(ctx, messageID, notificationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseMessage", reflect.TypeOf((*MockMessageProvider)(nil).ReleaseMessage), ctx, messageID, notificationID)
+}
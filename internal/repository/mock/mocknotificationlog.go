@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: NotificationLogProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mocknotificationlog.go . NotificationLogProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationLogProvider is a mock of NotificationLogProvider interface.
+type MockNotificationLogProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationLogProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockNotificationLogProviderMockRecorder is the mock recorder for MockNotificationLogProvider.
+type MockNotificationLogProviderMockRecorder struct {
+	mock *MockNotificationLogProvider
+}
+
+// NewMockNotificationLogProvider creates a new mock instance.
+func NewMockNotificationLogProvider(ctrl *gomock.Controller) *MockNotificationLogProvider {
+	mock := &MockNotificationLogProvider{ctrl: ctrl}
+	mock.recorder = &MockNotificationLogProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationLogProvider) EXPECT() *MockNotificationLogProviderMockRecorder {
+	return m.recorder
+}
+
+// RecordNotification mocks base method.
+func (m *MockNotificationLogProvider) RecordNotification(ctx context.Context, entry repository.NotificationLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordNotification", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordNotification indicates an expected call of RecordNotification.
+func (mr *MockNotificationLogProviderMockRecorder) RecordNotification(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordNotification", reflect.TypeOf((*MockNotificationLogProvider)(nil).RecordNotification), ctx, entry)
+}
+
+// SummarizeCosts mocks base method.
+func (m *MockNotificationLogProvider) SummarizeCosts(ctx context.Context, filter repository.CostFilter) ([]repository.CostSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SummarizeCosts", ctx, filter)
+	ret0, _ := ret[0].([]repository.CostSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SummarizeCosts indicates an expected call of SummarizeCosts.
+func (mr *MockNotificationLogProviderMockRecorder) SummarizeCosts(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeCosts", reflect.TypeOf((*MockNotificationLogProvider)(nil).SummarizeCosts), ctx, filter)
+}
+
+// UpdateNotificationStatus mocks base method.
+func (m *MockNotificationLogProvider) UpdateNotificationStatus(ctx context.Context, providerName, notificationID, status, reason string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationStatus", ctx, providerName, notificationID, status, reason)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationStatus indicates an expected call of UpdateNotificationStatus.
+func (mr *MockNotificationLogProviderMockRecorder) UpdateNotificationStatus(ctx, providerName, notificationID, status, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationStatus", reflect.TypeOf((*MockNotificationLogProvider)(nil).UpdateNotificationStatus), ctx, providerName, notificationID, status, reason)
+}
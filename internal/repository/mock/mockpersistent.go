@@ -55,3 +55,33 @@ func (mr *MockPersistentProviderMockRecorder) FindByProviderType(ctx, provider a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByProviderType", reflect.TypeOf((*MockPersistentProvider)(nil).FindByProviderType), ctx, provider)
 }
+
+// FindRoutesByRecipientType mocks base method.
+func (m *MockPersistentProvider) FindRoutesByRecipientType(ctx context.Context, recipientType string) ([]repository.NotificationRoute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoutesByRecipientType", ctx, recipientType)
+	ret0, _ := ret[0].([]repository.NotificationRoute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoutesByRecipientType indicates an expected call of FindRoutesByRecipientType.
+func (mr *MockPersistentProviderMockRecorder) FindRoutesByRecipientType(ctx, recipientType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoutesByRecipientType", reflect.TypeOf((*MockPersistentProvider)(nil).FindRoutesByRecipientType), ctx, recipientType)
+}
+
+// FindTranslationsByKey mocks base method.
+func (m *MockPersistentProvider) FindTranslationsByKey(ctx context.Context, key string) ([]repository.NotificationTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTranslationsByKey", ctx, key)
+	ret0, _ := ret[0].([]repository.NotificationTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTranslationsByKey indicates an expected call of FindTranslationsByKey.
+func (mr *MockPersistentProviderMockRecorder) FindTranslationsByKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTranslationsByKey", reflect.TypeOf((*MockPersistentProvider)(nil).FindTranslationsByKey), ctx, key)
+}
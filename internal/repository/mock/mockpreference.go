@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: PreferenceAdminProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockpreference.go . PreferenceAdminProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPreferenceAdminProvider is a mock of PreferenceAdminProvider interface.
+type MockPreferenceAdminProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferenceAdminProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockPreferenceAdminProviderMockRecorder is the mock recorder for MockPreferenceAdminProvider.
+type MockPreferenceAdminProviderMockRecorder struct {
+	mock *MockPreferenceAdminProvider
+}
+
+// NewMockPreferenceAdminProvider creates a new mock instance.
+func NewMockPreferenceAdminProvider(ctrl *gomock.Controller) *MockPreferenceAdminProvider {
+	mock := &MockPreferenceAdminProvider{ctrl: ctrl}
+	mock.recorder = &MockPreferenceAdminProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreferenceAdminProvider) EXPECT() *MockPreferenceAdminProviderMockRecorder {
+	return m.recorder
+}
+
+// ListPreferences mocks base method.
+func (m *MockPreferenceAdminProvider) ListPreferences(ctx context.Context, filter repository.PreferenceFilter) (repository.PreferencePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPreferences", ctx, filter)
+	ret0, _ := ret[0].(repository.PreferencePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPreferences indicates an expected call of ListPreferences.
+func (mr *MockPreferenceAdminProviderMockRecorder) ListPreferences(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPreferences", reflect.TypeOf((*MockPreferenceAdminProvider)(nil).ListPreferences), ctx, filter)
+}
+
+// PromoteNextSecretKey mocks base method.
+func (m *MockPreferenceAdminProvider) PromoteNextSecretKey(ctx context.Context, id uint) (repository.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteNextSecretKey", ctx, id)
+	ret0, _ := ret[0].(repository.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteNextSecretKey indicates an expected call of PromoteNextSecretKey.
+func (mr *MockPreferenceAdminProviderMockRecorder) PromoteNextSecretKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteNextSecretKey", reflect.TypeOf((*MockPreferenceAdminProvider)(nil).PromoteNextSecretKey), ctx, id)
+}
+
+// SetNextSecretKey mocks base method.
+func (m *MockPreferenceAdminProvider) SetNextSecretKey(ctx context.Context, id uint, secretKey string) (repository.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNextSecretKey", ctx, id, secretKey)
+	ret0, _ := ret[0].(repository.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNextSecretKey indicates an expected call of SetNextSecretKey.
+func (mr *MockPreferenceAdminProviderMockRecorder) SetNextSecretKey(ctx, id, secretKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNextSecretKey", reflect.TypeOf((*MockPreferenceAdminProvider)(nil).SetNextSecretKey), ctx, id, secretKey)
+}
+
+// SetPreferenceEnabled mocks base method.
+func (m *MockPreferenceAdminProvider) SetPreferenceEnabled(ctx context.Context, id uint, enabled bool) (repository.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferenceEnabled", ctx, id, enabled)
+	ret0, _ := ret[0].(repository.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPreferenceEnabled indicates an expected call of SetPreferenceEnabled.
+func (mr *MockPreferenceAdminProviderMockRecorder) SetPreferenceEnabled(ctx, id, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferenceEnabled", reflect.TypeOf((*MockPreferenceAdminProvider)(nil).SetPreferenceEnabled), ctx, id, enabled)
+}
@@ -0,0 +1,74 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: QuotaProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockquota.go . QuotaProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockQuotaProvider is a mock of QuotaProvider interface.
+type MockQuotaProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockQuotaProviderMockRecorder is the mock recorder for MockQuotaProvider.
+type MockQuotaProviderMockRecorder struct {
+	mock *MockQuotaProvider
+}
+
+// NewMockQuotaProvider creates a new mock instance.
+func NewMockQuotaProvider(ctrl *gomock.Controller) *MockQuotaProvider {
+	mock := &MockQuotaProvider{ctrl: ctrl}
+	mock.recorder = &MockQuotaProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaProvider) EXPECT() *MockQuotaProviderMockRecorder {
+	return m.recorder
+}
+
+// ConsumeQuota mocks base method.
+func (m *MockQuotaProvider) ConsumeQuota(ctx context.Context, subject string, windows []repository.QuotaWindow) (repository.QuotaWindow, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeQuota", ctx, subject, windows)
+	ret0, _ := ret[0].(repository.QuotaWindow)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ConsumeQuota indicates an expected call of ConsumeQuota.
+func (mr *MockQuotaProviderMockRecorder) ConsumeQuota(ctx, subject, windows any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeQuota", reflect.TypeOf((*MockQuotaProvider)(nil).ConsumeQuota), ctx, subject, windows)
+}
+
+// FindQuotaUsage mocks base method.
+func (m *MockQuotaProvider) FindQuotaUsage(ctx context.Context, subject, period string, start time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuotaUsage", ctx, subject, period, start)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindQuotaUsage indicates an expected call of FindQuotaUsage.
+func (mr *MockQuotaProviderMockRecorder) FindQuotaUsage(ctx, subject, period, start any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuotaUsage", reflect.TypeOf((*MockQuotaProvider)(nil).FindQuotaUsage), ctx, subject, period, start)
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: ChannelRetryProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockretry.go . ChannelRetryProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockChannelRetryProvider is a mock of ChannelRetryProvider interface.
+type MockChannelRetryProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelRetryProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockChannelRetryProviderMockRecorder is the mock recorder for MockChannelRetryProvider.
+type MockChannelRetryProviderMockRecorder struct {
+	mock *MockChannelRetryProvider
+}
+
+// NewMockChannelRetryProvider creates a new mock instance.
+func NewMockChannelRetryProvider(ctrl *gomock.Controller) *MockChannelRetryProvider {
+	mock := &MockChannelRetryProvider{ctrl: ctrl}
+	mock.recorder = &MockChannelRetryProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChannelRetryProvider) EXPECT() *MockChannelRetryProviderMockRecorder {
+	return m.recorder
+}
+
+// AddChannelRetry mocks base method.
+func (m *MockChannelRetryProvider) AddChannelRetry(ctx context.Context, retry repository.ChannelRetry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChannelRetry", ctx, retry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddChannelRetry indicates an expected call of AddChannelRetry.
+func (mr *MockChannelRetryProviderMockRecorder) AddChannelRetry(ctx, retry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChannelRetry", reflect.TypeOf((*MockChannelRetryProvider)(nil).AddChannelRetry), ctx, retry)
+}
+
+// BuryChannelRetry mocks base method.
+func (m *MockChannelRetryProvider) BuryChannelRetry(ctx context.Context, id uint, attempts int, disposition, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuryChannelRetry", ctx, id, attempts, disposition, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BuryChannelRetry indicates an expected call of BuryChannelRetry.
+func (mr *MockChannelRetryProviderMockRecorder) BuryChannelRetry(ctx, id, attempts, disposition, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuryChannelRetry", reflect.TypeOf((*MockChannelRetryProvider)(nil).BuryChannelRetry), ctx, id, attempts, disposition, reason)
+}
+
+// ClaimChannelRetry mocks base method.
+func (m *MockChannelRetryProvider) ClaimChannelRetry(ctx context.Context, staleAfter time.Duration) (repository.ChannelRetry, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimChannelRetry", ctx, staleAfter)
+	ret0, _ := ret[0].(repository.ChannelRetry)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimChannelRetry indicates an expected call of ClaimChannelRetry.
+func (mr *MockChannelRetryProviderMockRecorder) ClaimChannelRetry(ctx, staleAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimChannelRetry", reflect.TypeOf((*MockChannelRetryProvider)(nil).ClaimChannelRetry), ctx, staleAfter)
+}
+
+// DeleteChannelRetry mocks base method.
+func (m *MockChannelRetryProvider) DeleteChannelRetry(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChannelRetry", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChannelRetry indicates an expected call of DeleteChannelRetry.
+func (mr *MockChannelRetryProviderMockRecorder) DeleteChannelRetry(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChannelRetry", reflect.TypeOf((*MockChannelRetryProvider)(nil).DeleteChannelRetry), ctx, id)
+}
+
+// RescheduleChannelRetry mocks base method.
+func (m *MockChannelRetryProvider) RescheduleChannelRetry(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleChannelRetry", ctx, id, attempts, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleChannelRetry indicates an expected call of RescheduleChannelRetry.
+func (mr *MockChannelRetryProviderMockRecorder) RescheduleChannelRetry(ctx, id, attempts, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleChannelRetry", reflect.TypeOf((*MockChannelRetryProvider)(nil).RescheduleChannelRetry), ctx, id, attempts, nextAttemptAt)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: RouteCacheProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mockroutecache.go . RouteCacheProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRouteCacheProvider is a mock of RouteCacheProvider interface.
+type MockRouteCacheProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockRouteCacheProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockRouteCacheProviderMockRecorder is the mock recorder for MockRouteCacheProvider.
+type MockRouteCacheProviderMockRecorder struct {
+	mock *MockRouteCacheProvider
+}
+
+// NewMockRouteCacheProvider creates a new mock instance.
+func NewMockRouteCacheProvider(ctrl *gomock.Controller) *MockRouteCacheProvider {
+	mock := &MockRouteCacheProvider{ctrl: ctrl}
+	mock.recorder = &MockRouteCacheProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRouteCacheProvider) EXPECT() *MockRouteCacheProviderMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockRouteCacheProvider) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockRouteCacheProviderMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockRouteCacheProvider)(nil).Clear))
+}
+
+// Get mocks base method.
+func (m *MockRouteCacheProvider) Get(recipientType string) ([]repository.NotificationRoute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", recipientType)
+	ret0, _ := ret[0].([]repository.NotificationRoute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRouteCacheProviderMockRecorder) Get(recipientType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRouteCacheProvider)(nil).Get), recipientType)
+}
+
+// Set mocks base method.
+func (m *MockRouteCacheProvider) Set(recipientType string, routes []repository.NotificationRoute) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", recipientType, routes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockRouteCacheProviderMockRecorder) Set(recipientType, routes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRouteCacheProvider)(nil).Set), recipientType, routes)
+}
+
+// Stats mocks base method.
+func (m *MockRouteCacheProvider) Stats() repository.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockRouteCacheProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockRouteCacheProvider)(nil).Stats))
+}
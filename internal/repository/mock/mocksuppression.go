@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: SuppressionProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mocksuppression.go . SuppressionProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSuppressionProvider is a mock of SuppressionProvider interface.
+type MockSuppressionProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockSuppressionProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockSuppressionProviderMockRecorder is the mock recorder for MockSuppressionProvider.
+type MockSuppressionProviderMockRecorder struct {
+	mock *MockSuppressionProvider
+}
+
+// NewMockSuppressionProvider creates a new mock instance.
+func NewMockSuppressionProvider(ctrl *gomock.Controller) *MockSuppressionProvider {
+	mock := &MockSuppressionProvider{ctrl: ctrl}
+	mock.recorder = &MockSuppressionProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSuppressionProvider) EXPECT() *MockSuppressionProviderMockRecorder {
+	return m.recorder
+}
+
+// DeleteSuppression mocks base method.
+func (m *MockSuppressionProvider) DeleteSuppression(ctx context.Context, address string) (repository.Suppression, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSuppression", ctx, address)
+	ret0, _ := ret[0].(repository.Suppression)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSuppression indicates an expected call of DeleteSuppression.
+func (mr *MockSuppressionProviderMockRecorder) DeleteSuppression(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSuppression", reflect.TypeOf((*MockSuppressionProvider)(nil).DeleteSuppression), ctx, address)
+}
+
+// IsSuppressed mocks base method.
+func (m *MockSuppressionProvider) IsSuppressed(ctx context.Context, address string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSuppressed", ctx, address)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSuppressed indicates an expected call of IsSuppressed.
+func (mr *MockSuppressionProviderMockRecorder) IsSuppressed(ctx, address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSuppressed", reflect.TypeOf((*MockSuppressionProvider)(nil).IsSuppressed), ctx, address)
+}
+
+// ListSuppressions mocks base method.
+func (m *MockSuppressionProvider) ListSuppressions(ctx context.Context, filter repository.SuppressionFilter) (repository.SuppressionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSuppressions", ctx, filter)
+	ret0, _ := ret[0].(repository.SuppressionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSuppressions indicates an expected call of ListSuppressions.
+func (mr *MockSuppressionProviderMockRecorder) ListSuppressions(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSuppressions", reflect.TypeOf((*MockSuppressionProvider)(nil).ListSuppressions), ctx, filter)
+}
+
+// SuppressNotificationRecipient mocks base method.
+func (m *MockSuppressionProvider) SuppressNotificationRecipient(ctx context.Context, providerName, notificationID, reason, detail string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuppressNotificationRecipient", ctx, providerName, notificationID, reason, detail)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuppressNotificationRecipient indicates an expected call of SuppressNotificationRecipient.
+func (mr *MockSuppressionProviderMockRecorder) SuppressNotificationRecipient(ctx, providerName, notificationID, reason, detail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuppressNotificationRecipient", reflect.TypeOf((*MockSuppressionProvider)(nil).SuppressNotificationRecipient), ctx, providerName, notificationID, reason, detail)
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: SuppressionCacheProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mocksuppressioncache.go . SuppressionCacheProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSuppressionCacheProvider is a mock of SuppressionCacheProvider interface.
+type MockSuppressionCacheProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockSuppressionCacheProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockSuppressionCacheProviderMockRecorder is the mock recorder for MockSuppressionCacheProvider.
+type MockSuppressionCacheProviderMockRecorder struct {
+	mock *MockSuppressionCacheProvider
+}
+
+// NewMockSuppressionCacheProvider creates a new mock instance.
+func NewMockSuppressionCacheProvider(ctrl *gomock.Controller) *MockSuppressionCacheProvider {
+	mock := &MockSuppressionCacheProvider{ctrl: ctrl}
+	mock.recorder = &MockSuppressionCacheProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSuppressionCacheProvider) EXPECT() *MockSuppressionCacheProviderMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockSuppressionCacheProvider) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockSuppressionCacheProviderMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockSuppressionCacheProvider)(nil).Clear))
+}
+
+// Delete mocks base method.
+func (m *MockSuppressionCacheProvider) Delete(address string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Delete", address)
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSuppressionCacheProviderMockRecorder) Delete(address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSuppressionCacheProvider)(nil).Delete), address)
+}
+
+// Get mocks base method.
+func (m *MockSuppressionCacheProvider) Get(address string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", address)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSuppressionCacheProviderMockRecorder) Get(address any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSuppressionCacheProvider)(nil).Get), address)
+}
+
+// Set mocks base method.
+func (m *MockSuppressionCacheProvider) Set(address string, suppressed bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", address, suppressed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockSuppressionCacheProviderMockRecorder) Set(address, suppressed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSuppressionCacheProvider)(nil).Set), address, suppressed)
+}
+
+// Stats mocks base method.
+func (m *MockSuppressionCacheProvider) Stats() repository.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSuppressionCacheProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSuppressionCacheProvider)(nil).Stats))
+}
@@ -0,0 +1,164 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: TemplateProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mocktemplate.go . TemplateProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTemplateProvider is a mock of TemplateProvider interface.
+type MockTemplateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockTemplateProviderMockRecorder is the mock recorder for MockTemplateProvider.
+type MockTemplateProviderMockRecorder struct {
+	mock *MockTemplateProvider
+}
+
+// NewMockTemplateProvider creates a new mock instance.
+func NewMockTemplateProvider(ctrl *gomock.Controller) *MockTemplateProvider {
+	mock := &MockTemplateProvider{ctrl: ctrl}
+	mock.recorder = &MockTemplateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateProvider) EXPECT() *MockTemplateProviderMockRecorder {
+	return m.recorder
+}
+
+// ActivateTemplateVersion mocks base method.
+func (m *MockTemplateProvider) ActivateTemplateVersion(ctx context.Context, name string, version int) (repository.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateTemplateVersion", ctx, name, version)
+	ret0, _ := ret[0].(repository.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateTemplateVersion indicates an expected call of ActivateTemplateVersion.
+func (mr *MockTemplateProviderMockRecorder) ActivateTemplateVersion(ctx, name, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateTemplateVersion", reflect.TypeOf((*MockTemplateProvider)(nil).ActivateTemplateVersion), ctx, name, version)
+}
+
+// AddTemplateVersion mocks base method.
+func (m *MockTemplateProvider) AddTemplateVersion(ctx context.Context, content repository.TemplateVersion) (repository.TemplateVersion, repository.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTemplateVersion", ctx, content)
+	ret0, _ := ret[0].(repository.TemplateVersion)
+	ret1, _ := ret[1].(repository.TemplateVersion)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddTemplateVersion indicates an expected call of AddTemplateVersion.
+func (mr *MockTemplateProviderMockRecorder) AddTemplateVersion(ctx, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTemplateVersion", reflect.TypeOf((*MockTemplateProvider)(nil).AddTemplateVersion), ctx, content)
+}
+
+// CreateTemplate mocks base method.
+func (m *MockTemplateProvider) CreateTemplate(ctx context.Context, template repository.Template, content repository.TemplateVersion) (repository.Template, repository.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTemplate", ctx, template, content)
+	ret0, _ := ret[0].(repository.Template)
+	ret1, _ := ret[1].(repository.TemplateVersion)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateTemplate indicates an expected call of CreateTemplate.
+func (mr *MockTemplateProviderMockRecorder) CreateTemplate(ctx, template, content any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemplate", reflect.TypeOf((*MockTemplateProvider)(nil).CreateTemplate), ctx, template, content)
+}
+
+// FindActiveTemplateVersion mocks base method.
+func (m *MockTemplateProvider) FindActiveTemplateVersion(ctx context.Context, name string) (repository.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveTemplateVersion", ctx, name)
+	ret0, _ := ret[0].(repository.TemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveTemplateVersion indicates an expected call of FindActiveTemplateVersion.
+func (mr *MockTemplateProviderMockRecorder) FindActiveTemplateVersion(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveTemplateVersion", reflect.TypeOf((*MockTemplateProvider)(nil).FindActiveTemplateVersion), ctx, name)
+}
+
+// FindTemplate mocks base method.
+func (m *MockTemplateProvider) FindTemplate(ctx context.Context, name string) (repository.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTemplate", ctx, name)
+	ret0, _ := ret[0].(repository.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTemplate indicates an expected call of FindTemplate.
+func (mr *MockTemplateProviderMockRecorder) FindTemplate(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTemplate", reflect.TypeOf((*MockTemplateProvider)(nil).FindTemplate), ctx, name)
+}
+
+// FindTemplateVersion mocks base method.
+func (m *MockTemplateProvider) FindTemplateVersion(ctx context.Context, name string, version int) (repository.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTemplateVersion", ctx, name, version)
+	ret0, _ := ret[0].(repository.TemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTemplateVersion indicates an expected call of FindTemplateVersion.
+func (mr *MockTemplateProviderMockRecorder) FindTemplateVersion(ctx, name, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTemplateVersion", reflect.TypeOf((*MockTemplateProvider)(nil).FindTemplateVersion), ctx, name, version)
+}
+
+// ListTemplateVersions mocks base method.
+func (m *MockTemplateProvider) ListTemplateVersions(ctx context.Context, name string) ([]repository.TemplateVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplateVersions", ctx, name)
+	ret0, _ := ret[0].([]repository.TemplateVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTemplateVersions indicates an expected call of ListTemplateVersions.
+func (mr *MockTemplateProviderMockRecorder) ListTemplateVersions(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplateVersions", reflect.TypeOf((*MockTemplateProvider)(nil).ListTemplateVersions), ctx, name)
+}
+
+// ListTemplates mocks base method.
+func (m *MockTemplateProvider) ListTemplates(ctx context.Context, filter repository.TemplateFilter) (repository.TemplatePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplates", ctx, filter)
+	ret0, _ := ret[0].(repository.TemplatePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTemplates indicates an expected call of ListTemplates.
+func (mr *MockTemplateProviderMockRecorder) ListTemplates(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplates", reflect.TypeOf((*MockTemplateProvider)(nil).ListTemplates), ctx, filter)
+}
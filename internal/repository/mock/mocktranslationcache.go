@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/koungkub/fw-challenge-notification-service/internal/repository (interfaces: TranslationCacheProvider)
+//
+// Generated by this command:
+//
+//	mockgen -package mockrepository -destination ./mock/mocktranslationcache.go . TranslationCacheProvider
+//
+
+// Package mockrepository is a generated GoMock package.
+package mockrepository
+
+import (
+	reflect "reflect"
+
+	repository "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTranslationCacheProvider is a mock of TranslationCacheProvider interface.
+type MockTranslationCacheProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockTranslationCacheProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockTranslationCacheProviderMockRecorder is the mock recorder for MockTranslationCacheProvider.
+type MockTranslationCacheProviderMockRecorder struct {
+	mock *MockTranslationCacheProvider
+}
+
+// NewMockTranslationCacheProvider creates a new mock instance.
+func NewMockTranslationCacheProvider(ctrl *gomock.Controller) *MockTranslationCacheProvider {
+	mock := &MockTranslationCacheProvider{ctrl: ctrl}
+	mock.recorder = &MockTranslationCacheProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTranslationCacheProvider) EXPECT() *MockTranslationCacheProviderMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockTranslationCacheProvider) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockTranslationCacheProviderMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockTranslationCacheProvider)(nil).Clear))
+}
+
+// Get mocks base method.
+func (m *MockTranslationCacheProvider) Get(key string) ([]repository.NotificationTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].([]repository.NotificationTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTranslationCacheProviderMockRecorder) Get(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTranslationCacheProvider)(nil).Get), key)
+}
+
+// Set mocks base method.
+func (m *MockTranslationCacheProvider) Set(key string, translations []repository.NotificationTranslation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", key, translations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockTranslationCacheProviderMockRecorder) Set(key, translations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockTranslationCacheProvider)(nil).Set), key, translations)
+}
+
+// Stats mocks base method.
+func (m *MockTranslationCacheProvider) Stats() repository.CacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(repository.CacheStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockTranslationCacheProviderMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockTranslationCacheProvider)(nil).Stats))
+}
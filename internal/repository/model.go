@@ -1,27 +1,395 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type NotificationProvider int
 
 const (
 	EmailProvider NotificationProvider = iota
 	PushNotificationProvider
+	InAppProvider
 )
 
 var providerName = map[NotificationProvider]string{
 	EmailProvider:            "Email",
 	PushNotificationProvider: "PushNotification",
+	InAppProvider:            "InApp",
 }
 
 func (x NotificationProvider) String() string {
 	return providerName[x]
 }
 
+// ParseNotificationProvider converts a provider_type value into a NotificationProvider
+func ParseNotificationProvider(name string) (NotificationProvider, error) {
+	for provider, providerName := range providerName {
+		if providerName == name {
+			return provider, nil
+		}
+	}
+	return 0, fmt.Errorf("provider type: '%s' not supported", name)
+}
+
+// Auth modes of a provider preference
+const (
+	// AuthModeSecretKey sends the static SecretKey in the request body
+	AuthModeSecretKey = "secret_key"
+	// AuthModeJWT sends a short-lived JWT signed by the service in the
+	// Authorization header instead
+	AuthModeJWT = "jwt"
+)
+
 type NotificationPreference struct {
 	gorm.Model
 
+	ProviderType string
 	Host         string
 	ProviderName string
 	SecretKey    string
+	// NextSecretKey is the credential being rotated to; a provider rejecting
+	// SecretKey is sent it instead, until it is promoted to SecretKey
+	NextSecretKey string
+	// AuthMode is AuthModeSecretKey or AuthModeJWT
+	AuthMode string `gorm:"default:secret_key"`
+	Priority int
+	// Enabled keeps the provider in rotation; disabling it keeps its config
+	Enabled bool `gorm:"default:true"`
+	// TrafficPercent sends that share of the channel's notifications to this
+	// provider first, e.g. to roll out a new vendor; 0 leaves it to priority
+	TrafficPercent int
+	// Environment is the APP_ENV of the instances using the preference, e.g.
+	// staging or prod; empty shares it with every environment
+	Environment string
+	// Region is where the provider host serves from, e.g. ap-southeast-1;
+	// instances try the hosts of their own region first
+	Region string
+	// EnabledAt is when the provider was added or last enabled again, from
+	// which it warms up; nil for providers predating it
+	EnabledAt *time.Time
+}
+
+// NotificationRoute assigns a channel to a recipient type; priority orders
+// the channels of a recipient type
+type NotificationRoute struct {
+	gorm.Model
+
+	RecipientType string
+	ProviderType  string
+	Priority      int
+}
+
+// NotificationTranslation is the text of a template key in one locale
+type NotificationTranslation struct {
+	gorm.Model
+
+	Key    string
+	Locale string
+	Text   string
+}
+
+// AuditEntry records an admin operation; Before and After are the JSON state
+// of the target around the change, null when it did not exist. Actor is the
+// id of the admin key, OnBehalfOf the operator the caller named, if any
+type AuditEntry struct {
+	ID         uint            `json:"id"`
+	Actor      string          `json:"actor"`
+	OnBehalfOf string          `json:"on_behalf_of,omitempty"`
+	Action     string          `json:"action"`
+	Target     string          `json:"target"`
+	Before     json.RawMessage `json:"before" gorm:"type:jsonb"`
+	After      json.RawMessage `json:"after" gorm:"type:jsonb"`
+	RemoteAddr string          `json:"remote_addr"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func (AuditEntry) TableName() string {
+	return "admin_audit_log"
+}
+
+// Job tracks an asynchronous send, such as a batch upload, so its progress
+// can be reported after the request that started it, even across restarts
+type Job struct {
+	ID            string `gorm:"primaryKey"`
+	Kind          string
+	RecipientType string
+	State         string
+	// Total is the number of items, unknown until the input ends
+	Total      *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+	// HeartbeatAt is last set by the instance delivering the job
+	HeartbeatAt time.Time
+	// Notification is the JSON of the notification of a notify job and
+	// Subject the quota subject it is charged to, for resending it when its
+	// instance dies before delivering it
+	Notification json.RawMessage `gorm:"type:jsonb"`
+	Subject      string
+}
+
+func (Job) TableName() string {
+	return "notification_jobs"
+}
+
+// JobItem is the outcome of one item of a job; Item is the line of a batch
+type JobItem struct {
+	JobID          string `gorm:"primaryKey"`
+	Item           int    `gorm:"primaryKey;autoIncrement:false"`
+	Status         string
+	NotificationID string
+	Error          string
+	CreatedAt      time.Time
+}
+
+func (JobItem) TableName() string {
+	return "notification_job_items"
+}
+
+// NotificationLog is a notification accepted by a provider on one channel;
+// Status moves on as the provider reports delivery receipts
+type NotificationLog struct {
+	NotificationID string `gorm:"primaryKey"`
+	Channel        string `gorm:"primaryKey"`
+	RecipientType  string
+	ProviderName   string
+	// Recipient is the address the notification was sent to
+	Recipient    string
+	Status       string
+	StatusReason string
+	// Tenant is the quota subject of the API key that sent the notification,
+	// empty for notifications sent without one
+	Tenant string
+	// Cost is the estimated price the provider charges for the notification
+	Cost float64
+	// Metadata is the caller metadata of the notification, e.g. its order_id
+	Metadata  map[string]string `gorm:"serializer:json;type:jsonb"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (NotificationLog) TableName() string {
+	return "notification_log"
+}
+
+// NotificationMessage remembers a caller supplied message id and the
+// notification delivering it, so the same message is never delivered twice.
+// Channels lists the channels that delivered it, comma separated
+type NotificationMessage struct {
+	MessageID      string `gorm:"primaryKey"`
+	NotificationID string
+	RecipientType  string
+	State          string
+	Attempts       int
+	Channels       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (NotificationMessage) TableName() string {
+	return "notification_messages"
+}
+
+// Suppression is an email address no notification is sent to, after it
+// bounced permanently or its owner complained; Address is lower case
+type Suppression struct {
+	Address        string    `json:"address" gorm:"primaryKey"`
+	Reason         string    `json:"reason"`
+	Detail         string    `json:"detail"`
+	ProviderName   string    `json:"provider_name"`
+	NotificationID string    `json:"notification_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (Suppression) TableName() string {
+	return "notification_suppressions"
+}
+
+// InAppNotification is a notification kept for its recipient to read in the
+// app; ReadAt is nil while it is unread
+type InAppNotification struct {
+	ID             uint       `json:"id"`
+	NotificationID string     `json:"notification_id"`
+	RecipientType  string     `json:"recipient_type"`
+	Recipient      string     `json:"recipient"`
+	Title          string     `json:"title"`
+	Message        string     `json:"message"`
+	ThreadKey      string     `json:"thread_key,omitempty"`
+	DeepLink       string     `json:"deep_link,omitempty"`
+	ImageURL       string     `json:"image_url,omitempty"`
+	ReadAt         *time.Time `json:"read_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// Tenant is the quota subject of the API key that sent the notification;
+	// only keys of the same tenant list it or mark it read
+	Tenant string `json:"-"`
+}
+
+func (InAppNotification) TableName() string {
+	return "in_app_notifications"
+}
+
+// Consent is the choice of a recipient address to receive a category of
+// notifications or not, on one channel or, when Channel is empty, on every
+// channel; Address is lower case
+type Consent struct {
+	Address   string    `json:"address" gorm:"primaryKey"`
+	Category  string    `json:"category" gorm:"primaryKey"`
+	Channel   string    `json:"channel" gorm:"primaryKey"`
+	OptedIn   bool      `json:"opted_in"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Consent) TableName() string {
+	return "notification_consents"
+}
+
+// DigestItem is a notification buffered until the next digest of its
+// recipient; ClaimedAt is set while a digest is sending it
+type DigestItem struct {
+	ID             uint `gorm:"primaryKey"`
+	NotificationID string
+	RecipientType  string
+	Recipient      string
+	Category       string
+	Title          string
+	Message        string
+	DeepLink       string
+	ClaimedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+func (DigestItem) TableName() string {
+	return "notification_digest_items"
+}
+
+// ChannelRetry is a failed channel of a notification, queued by partial
+// success or the retry queue, waiting for NextAttemptAt to be sent again.
+// Notification is the JSON of the localized notification; ClaimedAt is set
+// while a retry is sending it
+type ChannelRetry struct {
+	ID             uint `gorm:"primaryKey"`
+	NotificationID string
+	RecipientType  string
+	Channel        string
+	Tenant         string
+	Notification   json.RawMessage `gorm:"type:jsonb"`
+	Attempts       int
+	NextAttemptAt  time.Time `gorm:"default:now()"`
+	ClaimedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+func (ChannelRetry) TableName() string {
+	return "notification_channel_retries"
+}
+
+// DeadLetter is a channel retry that was given up, kept with the attempts
+// made and the reason until an operator redrives it
+type DeadLetter struct {
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	NotificationID string          `json:"notification_id"`
+	RecipientType  string          `json:"recipient_type"`
+	Channel        string          `json:"channel"`
+	Tenant         string          `json:"tenant"`
+	Notification   json.RawMessage `json:"notification" gorm:"type:jsonb"`
+	Attempts       int             `json:"attempts"`
+	Reason         string          `json:"reason"`
+	// RetryDisposition is that of the last attempt, empty for dead letters
+	// buried before it was kept
+	RetryDisposition string    `json:"retry_disposition"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (DeadLetter) TableName() string {
+	return "notification_dead_letters"
+}
+
+// Template is a named title and message pair rendered with text/template;
+// every edit adds a version, and ActiveVersion is the one in use
+type Template struct {
+	Name          string    `json:"name" gorm:"primaryKey"`
+	Description   string    `json:"description"`
+	ActiveVersion int       `json:"active_version"`
+	LatestVersion int       `json:"latest_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (Template) TableName() string {
+	return "notification_templates"
+}
+
+// TemplateVersion is one immutable revision of a template, kept so past
+// content stays auditable after it is replaced
+type TemplateVersion struct {
+	TemplateName string    `json:"template_name" gorm:"primaryKey"`
+	Version      int       `json:"version" gorm:"primaryKey"`
+	Title        string    `json:"title"`
+	Message      string    `json:"message"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (TemplateVersion) TableName() string {
+	return "notification_template_versions"
+}
+
+// QuotaUsage counts the notifications a subject, a tenant or an API key,
+// sent in the period starting at PeriodStart
+type QuotaUsage struct {
+	Subject     string    `gorm:"primaryKey"`
+	Period      string    `gorm:"primaryKey"`
+	PeriodStart time.Time `gorm:"primaryKey;type:date"`
+	Used        int64
+	UpdatedAt   time.Time
+}
+
+func (QuotaUsage) TableName() string {
+	return "notification_quota_usage"
+}
+
+// CircuitBreakerTrip is the last time the circuit breaker of a provider host
+// opened, kept until it closes again
+type CircuitBreakerTrip struct {
+	Host      string `gorm:"primaryKey"`
+	OpenedAt  time.Time
+	UpdatedAt time.Time
+}
+
+func (CircuitBreakerTrip) TableName() string {
+	return "notification_circuit_breaker_trips"
+}
+
+// CircuitBreakerCounts are the requests every instance sent to a provider
+// host, and those that failed, in the window starting at WindowStart
+type CircuitBreakerCounts struct {
+	Host        string `gorm:"primaryKey"`
+	WindowStart time.Time
+	Requests    int64
+	Failures    int64
+	UpdatedAt   time.Time
+}
+
+func (CircuitBreakerCounts) TableName() string {
+	return "notification_circuit_breaker_counts"
+}
+
+// NotificationContent is the localized notification as accepted, kept so it
+// can be replayed later
+type NotificationContent struct {
+	NotificationID string `gorm:"primaryKey"`
+	RecipientType  string
+	// Tenant is the quota subject of the API key that sent the notification
+	Tenant       string
+	Notification json.RawMessage `gorm:"type:jsonb"`
+	CreatedAt    time.Time
+}
+
+func (NotificationContent) TableName() string {
+	return "notification_contents"
 }
@@ -5,6 +5,10 @@ import "go.uber.org/fx"
 var Module = fx.Module("repository",
 	persistentModule,
 	cacheModule,
+	routeCacheModule,
+	translationCacheModule,
+	suppressionCacheModule,
+	consentCacheModule,
 )
 
 var (
@@ -12,8 +16,22 @@ var (
 		fx.Annotate(
 			NewPersistent,
 			fx.As(new(PersistentProvider)),
+			fx.As(new(AuditProvider)),
+			fx.As(new(PreferenceAdminProvider)),
+			fx.As(new(JobProvider)),
+			fx.As(new(NotificationLogProvider)),
+			fx.As(new(SuppressionProvider)),
+			fx.As(new(MessageProvider)),
+			fx.As(new(InAppNotificationProvider)),
+			fx.As(new(ConsentProvider)),
+			fx.As(new(DigestProvider)),
+			fx.As(new(ChannelRetryProvider)),
+			fx.As(new(DeadLetterProvider)),
+			fx.As(new(TemplateProvider)),
+			fx.As(new(NotificationContentProvider)),
+			fx.As(new(QuotaProvider)),
+			fx.As(new(CircuitBreakerTripProvider)),
 		),
-		NewPersistentConfig,
 	)
 
 	cacheModule = fx.Provide(
@@ -21,6 +39,34 @@ var (
 			NewCache,
 			fx.As(new(CacheProvider)),
 		),
-		NewCacheConfig,
+		NewCacheTTL,
+	)
+
+	routeCacheModule = fx.Provide(
+		fx.Annotate(
+			NewRouteCache,
+			fx.As(new(RouteCacheProvider)),
+		),
+	)
+
+	translationCacheModule = fx.Provide(
+		fx.Annotate(
+			NewTranslationCache,
+			fx.As(new(TranslationCacheProvider)),
+		),
+	)
+
+	suppressionCacheModule = fx.Provide(
+		fx.Annotate(
+			NewSuppressionCache,
+			fx.As(new(SuppressionCacheProvider)),
+		),
+	)
+
+	consentCacheModule = fx.Provide(
+		fx.Annotate(
+			NewConsentCache,
+			fx.As(new(ConsentCacheProvider)),
+		),
 	)
 )
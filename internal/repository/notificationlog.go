@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Notification log statuses. A notification is logged as sent once the
+// provider accepts it; receipts then report it delivered, bounced, opened
+// or complained about, i.e. marked as spam by its recipient
+const (
+	LogStatusSent       = "sent"
+	LogStatusDelivered  = "delivered"
+	LogStatusBounced    = "bounced"
+	LogStatusOpened     = "opened"
+	LogStatusComplained = "complained"
+)
+
+// logStatusPredecessors lists the statuses a receipt status may replace, so
+// receipts arriving out of order never move a notification backwards
+var logStatusPredecessors = map[string][]string{
+	LogStatusDelivered:  {LogStatusSent},
+	LogStatusBounced:    {LogStatusSent, LogStatusDelivered},
+	LogStatusOpened:     {LogStatusSent, LogStatusDelivered},
+	LogStatusComplained: {LogStatusSent, LogStatusDelivered, LogStatusOpened},
+}
+
+//go:generate mockgen -package mockrepository -destination ./mock/mocknotificationlog.go . NotificationLogProvider
+type NotificationLogProvider interface {
+	RecordNotification(ctx context.Context, entry NotificationLog) error
+	// UpdateNotificationStatus applies a receipt of the provider to its
+	// notification; it reports false when no notification of the provider
+	// matches or the notification already has a later status
+	UpdateNotificationStatus(ctx context.Context, providerName string, notificationID string, status string, reason string) (bool, error)
+	// SummarizeCosts totals the logged notifications and their cost per
+	// tenant, channel and provider
+	SummarizeCosts(ctx context.Context, filter CostFilter) ([]CostSummary, error)
+}
+
+// CostFilter narrows the notifications a cost summary covers; zero fields
+// match everything
+type CostFilter struct {
+	Tenant  string
+	Channel string
+	Since   time.Time
+	Until   time.Time
+}
+
+// CostSummary is the spend of a tenant on one provider of a channel
+type CostSummary struct {
+	Tenant        string  `json:"tenant"`
+	Channel       string  `json:"channel"`
+	ProviderName  string  `json:"provider_name"`
+	Notifications int64   `json:"notifications"`
+	Cost          float64 `json:"cost"`
+}
+
+var _ NotificationLogProvider = (*Persistent)(nil)
+
+// ParseReceiptStatus accepts the statuses a delivery receipt reports
+func ParseReceiptStatus(status string) (string, error) {
+	if _, ok := logStatusPredecessors[status]; !ok {
+		return "", fmt.Errorf("receipt event: '%s' not supported, use delivered, bounced, opened or complained", status)
+	}
+	return status, nil
+}
+
+func (p *Persistent) RecordNotification(ctx context.Context, entry NotificationLog) error {
+	if err := gorm.G[NotificationLog](p.conn).Create(ctx, &entry); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", entry.NotificationID),
+			zap.String("channel", entry.Channel),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) UpdateNotificationStatus(
+	ctx context.Context,
+	providerName string,
+	notificationID string,
+	status string,
+	reason string,
+) (bool, error) {
+	result := p.conn.WithContext(ctx).Model(&NotificationLog{}).
+		Where("notification_id = ? AND provider_name = ? AND status IN ?", notificationID, providerName, logStatusPredecessors[status]).
+		Updates(map[string]any{
+			"status":        status,
+			"status_reason": reason,
+		})
+	if result.Error != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("notification_id", notificationID),
+			zap.String("provider_name", providerName),
+			zap.Error(result.Error),
+		)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (p *Persistent) SummarizeCosts(ctx context.Context, filter CostFilter) ([]CostSummary, error) {
+	query := p.conn.WithContext(ctx).Model(&NotificationLog{}).
+		Select("tenant, channel, provider_name, COUNT(*) AS notifications, COALESCE(SUM(cost), 0) AS cost")
+	if filter.Tenant != "" {
+		query = query.Where("tenant = ?", filter.Tenant)
+	}
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	summaries := []CostSummary{}
+	if err := query.Group("tenant, channel, provider_name").
+		Order("tenant, channel, provider_name").
+		Scan(&summaries).Error; err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("tenant", filter.Tenant),
+			zap.Error(err),
+		)
+		return []CostSummary{}, err
+	}
+	return summaries, nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
@@ -14,36 +17,39 @@ import (
 //go:generate mockgen -package mockrepository -destination ./mock/mockpersistent.go . PersistentProvider
 type PersistentProvider interface {
 	FindByProviderType(ctx context.Context, provider NotificationProvider) ([]NotificationPreference, error)
+	FindRoutesByRecipientType(ctx context.Context, recipientType string) ([]NotificationRoute, error)
+	FindTranslationsByKey(ctx context.Context, key string) ([]NotificationTranslation, error)
 }
 
 var _ PersistentProvider = (*Persistent)(nil)
 
 type Persistent struct {
-	conn   *gorm.DB
+	conn *gorm.DB
+	// environments are the preference environments this instance uses
+	environments []string
+	// cipher encrypts secret keys written by column, which the
+	// SecretKeyEncryption plugin leaves untouched
+	cipher *secret.Cipher
 	logger *zap.Logger
 }
 
 type PersistentParams struct {
 	fx.In
 
-	Config PersistentConfig
-	Logger *zap.Logger
+	Config  PersistentConfig
+	Secrets secret.Provider
+	Cipher  *secret.Cipher
+	Logger  *zap.Logger
 }
 
 func NewPersistent(lc fx.Lifecycle, params PersistentParams) (*Persistent, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		params.Config.Host,
-		params.Config.Username,
-		params.Config.Password,
-		params.Config.Name,
-		params.Config.Port,
-		params.Config.SSLMode,
-	)
-
-	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	conn, err := Open(params.Config, params.Secrets)
 	if err != nil {
 		return nil, err
 	}
+	if err := conn.Use(NewSecretKeyEncryption(params.Cipher)); err != nil {
+		return nil, err
+	}
 
 	lc.Append(fx.Hook{
 		OnStop: func(_ context.Context) error {
@@ -53,8 +59,10 @@ func NewPersistent(lc fx.Lifecycle, params PersistentParams) (*Persistent, error
 	})
 
 	return &Persistent{
-		conn:   conn,
-		logger: params.Logger,
+		conn:         conn,
+		environments: PreferenceEnvironments(params.Config.Environment),
+		cipher:       params.Cipher,
+		logger:       params.Logger,
 	}, nil
 }
 
@@ -63,15 +71,46 @@ type PersistentConfig struct {
 	Port     string `envconfig:"DB_PORT" required:"true"`
 	Name     string `envconfig:"DB_NAME" required:"true"`
 	Username string `envconfig:"DB_USERNAME" required:"true"`
-	Password string `envconfig:"DB_PASSWORD" required:"true"`
+	Password string `envconfig:"DB_PASSWORD" required:"true" secret:"true"`
 	SSLMode  string `envconfig:"DB_SSLMODE" default:"disable"`
+	// Environment selects the preferences of this environment, next to the
+	// shared ones, so environments can share a database
+	Environment string `envconfig:"APP_ENV" default:"development"`
 }
 
-func NewPersistentConfig() PersistentConfig {
-	var cfg PersistentConfig
-	envconfig.MustProcess("", &cfg)
+// PreferenceEnvironments are the environment values of the preferences an
+// instance of environment uses: its own and the shared empty one
+func PreferenceEnvironments(environment string) []string {
+	return []string{"", environment}
+}
+
+// Open connects to PostgreSQL using the given config. The password is
+// resolved through secrets for every new connection, so a rotated password
+// is used once the secret cache expires
+func Open(config PersistentConfig, secrets secret.Provider) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
+		config.Host,
+		config.Username,
+		config.Name,
+		config.Port,
+		config.SSLMode,
+	)
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+		password, err := secrets.Resolve(ctx, config.Password)
+		if err != nil {
+			return err
+		}
+		connConfig.Password = password
+		return nil
+	}))
 
-	return cfg
+	return gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
 }
 
 func (p *Persistent) FindByProviderType(ctx context.Context, provider NotificationProvider) ([]NotificationPreference, error) {
@@ -79,17 +118,19 @@ func (p *Persistent) FindByProviderType(ctx context.Context, provider Notificati
 		G[NotificationPreference](p.conn).
 		Where("provider_type = ?", provider.String()).
 		Where("deleted_at IS NULL").
+		Where("enabled").
+		Where("environment IN ?", p.environments).
 		Order("priority").
 		Find(ctx)
 	if err != nil {
-		p.logger.Error("database query failed",
+		logging.From(ctx, p.logger).Error("database query failed",
 			zap.String("provider_type", provider.String()),
 			zap.Error(err),
 		)
 		return []NotificationPreference{}, err
 	}
 	if len(preferences) == 0 {
-		p.logger.Warn("no preferences found for provider type",
+		logging.From(ctx, p.logger).Warn("no preferences found for provider type",
 			zap.String("provider_type", provider.String()),
 		)
 		return []NotificationPreference{}, gorm.ErrRecordNotFound
@@ -97,3 +138,50 @@ func (p *Persistent) FindByProviderType(ctx context.Context, provider Notificati
 
 	return preferences, nil
 }
+
+func (p *Persistent) FindRoutesByRecipientType(ctx context.Context, recipientType string) ([]NotificationRoute, error) {
+	routes, err := gorm.
+		G[NotificationRoute](p.conn).
+		Where("recipient_type = ?", recipientType).
+		Where("deleted_at IS NULL").
+		Order("priority").
+		Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("recipient_type", recipientType),
+			zap.Error(err),
+		)
+		return []NotificationRoute{}, err
+	}
+	if len(routes) == 0 {
+		logging.From(ctx, p.logger).Warn("no routes found for recipient type",
+			zap.String("recipient_type", recipientType),
+		)
+		return []NotificationRoute{}, gorm.ErrRecordNotFound
+	}
+
+	return routes, nil
+}
+
+func (p *Persistent) FindTranslationsByKey(ctx context.Context, key string) ([]NotificationTranslation, error) {
+	translations, err := gorm.
+		G[NotificationTranslation](p.conn).
+		Where("key = ?", key).
+		Where("deleted_at IS NULL").
+		Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("translation_key", key),
+			zap.Error(err),
+		)
+		return []NotificationTranslation{}, err
+	}
+	if len(translations) == 0 {
+		logging.From(ctx, p.logger).Warn("no translations found for key",
+			zap.String("translation_key", key),
+		)
+		return []NotificationTranslation{}, gorm.ErrRecordNotFound
+	}
+
+	return translations, nil
+}
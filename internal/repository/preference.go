@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockpreference.go . PreferenceAdminProvider
+type PreferenceAdminProvider interface {
+	ListPreferences(ctx context.Context, filter PreferenceFilter) (PreferencePage, error)
+	// SetPreferenceEnabled pulls a preference out of rotation or puts it
+	// back, returning it as it was before; gorm.ErrRecordNotFound when no
+	// active preference has the id
+	SetPreferenceEnabled(ctx context.Context, id uint, enabled bool) (NotificationPreference, error)
+	// SetNextSecretKey stages the credential a preference rotates to, or
+	// cancels the rotation when secretKey is empty, returning the preference
+	// as it was before; gorm.ErrRecordNotFound when no active preference has
+	// the id
+	SetNextSecretKey(ctx context.Context, id uint, secretKey string) (NotificationPreference, error)
+	// PromoteNextSecretKey makes the staged credential the secret key and
+	// forgets the previous one, returning the preference as it was before;
+	// ErrNoNextSecretKey when no credential is staged
+	PromoteNextSecretKey(ctx context.Context, id uint) (NotificationPreference, error)
+}
+
+// ErrNoNextSecretKey is returned when promoting a preference that is not
+// rotating its credential
+var ErrNoNextSecretKey = errors.New("no next secret key staged")
+
+var _ PreferenceAdminProvider = (*Persistent)(nil)
+
+// preferenceSortColumns maps the accepted sort fields onto their columns
+var preferenceSortColumns = map[string]string{
+	"priority":   "priority",
+	"id":         "id",
+	"created_at": "created_at",
+}
+
+// PreferenceFilter narrows and orders the preferences not deleted, enabled
+// or not, of the environment of the instance; zero fields match everything.
+// Sort is a field name, prefixed with '-' for descending order, e.g.
+// "-priority"
+type PreferenceFilter struct {
+	ProviderType string
+	Host         string
+	Sort         string
+	Limit        int
+	Offset       int
+}
+
+// PreferencePage is one page of preferences with the number of preferences
+// matching the filter across all pages
+type PreferencePage struct {
+	Preferences []NotificationPreference
+	Total       int64
+}
+
+// ParsePreferenceSort validates a sort expression and returns its ORDER BY
+// clause, with the id as tie-breaker so pages are stable
+func ParsePreferenceSort(sort string) (string, error) {
+	if sort == "" {
+		sort = "priority"
+	}
+
+	field, descending := strings.CutPrefix(sort, "-")
+	column, ok := preferenceSortColumns[field]
+	if !ok {
+		return "", fmt.Errorf("sort field: '%s' not supported, use priority, id or created_at", field)
+	}
+
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	if column == "id" {
+		return "id " + direction, nil
+	}
+	return column + " " + direction + ", id ASC", nil
+}
+
+func (p *Persistent) ListPreferences(ctx context.Context, filter PreferenceFilter) (PreferencePage, error) {
+	order, err := ParsePreferenceSort(filter.Sort)
+	if err != nil {
+		return PreferencePage{}, err
+	}
+
+	query := gorm.G[NotificationPreference](p.conn).
+		Where("deleted_at IS NULL").
+		Where("environment IN ?", p.environments)
+	if filter.ProviderType != "" {
+		query = query.Where("provider_type = ?", filter.ProviderType)
+	}
+	if filter.Host != "" {
+		query = query.Where("host = ?", filter.Host)
+	}
+
+	total, err := query.Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("provider_type", filter.ProviderType),
+			zap.Error(err),
+		)
+		return PreferencePage{}, err
+	}
+
+	preferences, err := query.Order(order).Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("provider_type", filter.ProviderType),
+			zap.Error(err),
+		)
+		return PreferencePage{}, err
+	}
+
+	return PreferencePage{Preferences: preferences, Total: total}, nil
+}
+
+func (p *Persistent) SetPreferenceEnabled(ctx context.Context, id uint, enabled bool) (NotificationPreference, error) {
+	return p.updatePreference(ctx, id, func(tx *gorm.DB, previous NotificationPreference) error {
+		columns := map[string]any{"enabled": enabled}
+		// Enabling a disabled provider warms it up again
+		if enabled && !previous.Enabled {
+			columns["enabled_at"] = gorm.Expr("NOW()")
+		}
+
+		// The table has no updated_at, so the columns are set without the
+		// timestamp gorm adds to updates
+		return tx.Model(&NotificationPreference{}).
+			Where("id = ?", id).
+			UpdateColumns(columns).Error
+	})
+}
+
+func (p *Persistent) SetNextSecretKey(ctx context.Context, id uint, secretKey string) (NotificationPreference, error) {
+	encrypted, err := p.cipher.Encrypt(secretKey)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("failed to encrypt secret key",
+			zap.Uint("preference_id", id),
+			zap.Error(err),
+		)
+		return NotificationPreference{}, err
+	}
+
+	var next any
+	if encrypted != "" {
+		next = encrypted
+	}
+
+	return p.updatePreference(ctx, id, func(tx *gorm.DB, _ NotificationPreference) error {
+		return tx.Model(&NotificationPreference{}).
+			Where("id = ?", id).
+			UpdateColumn("next_secret_key", next).Error
+	})
+}
+
+func (p *Persistent) PromoteNextSecretKey(ctx context.Context, id uint) (NotificationPreference, error) {
+	return p.updatePreference(ctx, id, func(tx *gorm.DB, previous NotificationPreference) error {
+		if previous.NextSecretKey == "" {
+			return ErrNoNextSecretKey
+		}
+		// Both columns are encrypted alike, so the value moves as stored
+		return tx.Model(&NotificationPreference{}).
+			Where("id = ?", id).
+			UpdateColumns(map[string]any{
+				"secret_key":      gorm.Expr("next_secret_key"),
+				"next_secret_key": nil,
+			}).Error
+	})
+}
+
+// updatePreference runs update on the locked active preference and returns
+// the preference as it was before
+func (p *Persistent) updatePreference(
+	ctx context.Context,
+	id uint,
+	update func(tx *gorm.DB, previous NotificationPreference) error,
+) (NotificationPreference, error) {
+	var previous NotificationPreference
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		previous, err = gorm.G[NotificationPreference](tx, clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			Where("deleted_at IS NULL").
+			Where("environment IN ?", p.environments).
+			First(ctx)
+		if err != nil {
+			return err
+		}
+
+		return update(tx, previous)
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrNoNextSecretKey) {
+			logging.From(ctx, p.logger).Error("database update failed",
+				zap.Uint("preference_id", id),
+				zap.Error(err),
+			)
+		}
+		return NotificationPreference{}, err
+	}
+
+	return previous, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Quota periods
+const (
+	QuotaPeriodDay   = "day"
+	QuotaPeriodMonth = "month"
+)
+
+// QuotaWindow is one quota of a subject: at most Limit notifications in the
+// period starting at Start; a zero Limit only counts
+type QuotaWindow struct {
+	Period string
+	Start  time.Time
+	Limit  int64
+}
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockquota.go . QuotaProvider
+type QuotaProvider interface {
+	// ConsumeQuota counts one notification in every window of the subject
+	// and reports true, or counts nothing and returns the first window
+	// already used up and false
+	ConsumeQuota(ctx context.Context, subject string, windows []QuotaWindow) (QuotaWindow, bool, error)
+	// FindQuotaUsage returns the notifications the subject sent in the
+	// period starting at start
+	FindQuotaUsage(ctx context.Context, subject string, period string, start time.Time) (int64, error)
+}
+
+var _ QuotaProvider = (*Persistent)(nil)
+
+// errQuotaExceeded rolls the transaction of a used up quota back
+var errQuotaExceeded = errors.New("quota exceeded")
+
+func (p *Persistent) ConsumeQuota(ctx context.Context, subject string, windows []QuotaWindow) (QuotaWindow, bool, error) {
+	var exceeded QuotaWindow
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, window := range windows {
+			var used []int64
+			err := tx.Raw(`
+				INSERT INTO notification_quota_usage (subject, period, period_start, used)
+				VALUES (?, ?, ?, 1)
+				ON CONFLICT (subject, period, period_start) DO UPDATE
+				SET used = notification_quota_usage.used + 1,
+					updated_at = NOW()
+				WHERE ? = 0 OR notification_quota_usage.used < ?
+				RETURNING used`,
+				subject, window.Period, window.Start, window.Limit, window.Limit,
+			).Scan(&used).Error
+			if err != nil {
+				return err
+			}
+			if len(used) == 0 {
+				exceeded = window
+				return errQuotaExceeded
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errQuotaExceeded) {
+		return exceeded, false, nil
+	}
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.String("subject", subject),
+			zap.Error(err),
+		)
+		return QuotaWindow{}, false, err
+	}
+	return QuotaWindow{}, true, nil
+}
+
+func (p *Persistent) FindQuotaUsage(ctx context.Context, subject string, period string, start time.Time) (int64, error) {
+	usage, err := gorm.G[QuotaUsage](p.conn).
+		Where("subject = ? AND period = ? AND period_start = ?", subject, period, start).
+		First(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("subject", subject),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return usage.Used, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockretry.go . ChannelRetryProvider
+type ChannelRetryProvider interface {
+	// AddChannelRetry queues a failed channel of a notification
+	AddChannelRetry(ctx context.Context, retry ChannelRetry) error
+	// ClaimChannelRetry claims the retry due the longest, and reports false
+	// when none is due. A claim older than staleAfter is taken over, its
+	// retry presumed abandoned
+	ClaimChannelRetry(ctx context.Context, staleAfter time.Duration) (ChannelRetry, bool, error)
+	// RescheduleChannelRetry releases a claimed retry until nextAttemptAt,
+	// storing the attempts made so far
+	RescheduleChannelRetry(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time) error
+	// DeleteChannelRetry forgets a retry that was delivered
+	DeleteChannelRetry(ctx context.Context, id uint) error
+	// BuryChannelRetry moves a retry that was given up to the
+	// notification_dead_letters table, with the attempts made, the retry
+	// disposition of the last one and why
+	BuryChannelRetry(ctx context.Context, id uint, attempts int, disposition string, reason string) error
+}
+
+var _ ChannelRetryProvider = (*Persistent)(nil)
+
+func (p *Persistent) AddChannelRetry(ctx context.Context, retry ChannelRetry) error {
+	if err := gorm.G[ChannelRetry](p.conn).Create(ctx, &retry); err != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", retry.NotificationID),
+			zap.String("channel", retry.Channel),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) ClaimChannelRetry(ctx context.Context, staleAfter time.Duration) (ChannelRetry, bool, error) {
+	var claimed []ChannelRetry
+	err := p.conn.WithContext(ctx).Raw(`
+		WITH head AS (
+			SELECT id
+			FROM notification_channel_retries
+			WHERE next_attempt_at <= NOW()
+				AND (claimed_at IS NULL OR claimed_at < NOW() - ? * INTERVAL '1 second')
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_channel_retries AS retry
+		SET claimed_at = NOW()
+		FROM head
+		WHERE retry.id = head.id
+		RETURNING retry.*`,
+		staleAfter.Seconds(),
+	).Scan(&claimed).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Error(err),
+		)
+		return ChannelRetry{}, false, err
+	}
+
+	if len(claimed) == 0 {
+		return ChannelRetry{}, false, nil
+	}
+	return claimed[0], true, nil
+}
+
+func (p *Persistent) RescheduleChannelRetry(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time) error {
+	err := p.conn.WithContext(ctx).Model(&ChannelRetry{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"claimed_at":      nil,
+		}).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Uint("retry_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) DeleteChannelRetry(ctx context.Context, id uint) error {
+	_, err := gorm.G[ChannelRetry](p.conn).Where("id = ?", id).Delete(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.Uint("retry_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (p *Persistent) BuryChannelRetry(ctx context.Context, id uint, attempts int, disposition string, reason string) error {
+	err := p.conn.WithContext(ctx).Exec(`
+		WITH buried AS (
+			DELETE FROM notification_channel_retries
+			WHERE id = ?
+			RETURNING notification_id, recipient_type, channel, tenant, notification
+		)
+		INSERT INTO notification_dead_letters (notification_id, recipient_type, channel, tenant, notification, attempts, retry_disposition, reason)
+		SELECT notification_id, recipient_type, channel, tenant, notification, ?, ?, ?
+		FROM buried`,
+		id, attempts, disposition, reason,
+	).Error
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database update failed",
+			zap.Uint("retry_id", id),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
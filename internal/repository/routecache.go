@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	routeCacheKeyPattern = "notification:routes:%s"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mockroutecache.go . RouteCacheProvider
+type RouteCacheProvider interface {
+	Get(recipientType string) ([]NotificationRoute, error)
+	Set(recipientType string, routes []NotificationRoute) error
+	Stats() CacheStats
+	// Clear drops every entry, and the statistics with them, so the next
+	// reads go to the database
+	Clear()
+}
+
+var _ RouteCacheProvider = (*RouteCache)(nil)
+
+// RouteCache caches routes per recipient type, so routing changes in the
+// database take effect once the entry expires
+type RouteCache struct {
+	engine  *ristretto.Cache[string, []NotificationRoute]
+	ttl     *CacheTTL
+	missTTL time.Duration
+	logger  *zap.Logger
+}
+
+func NewRouteCache(lc fx.Lifecycle, params CacheParams) (*RouteCache, error) {
+	engine, err := ristretto.NewCache(&ristretto.Config[string, []NotificationRoute]{
+		NumCounters: params.Config.NumCounters,
+		MaxCost:     params.Config.MaxCost,
+		BufferItems: params.Config.BufferItems,
+		Metrics:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			engine.Close()
+			return nil
+		},
+	})
+
+	return &RouteCache{
+		engine:  engine,
+		ttl:     params.TTL,
+		missTTL: params.Config.MissExpiredTime,
+		logger:  params.Logger,
+	}, nil
+}
+
+func (c *RouteCache) Get(recipientType string) ([]NotificationRoute, error) {
+	cacheKey := fmt.Sprintf(routeCacheKeyPattern, recipientType)
+
+	value, found := c.engine.Get(cacheKey)
+	if !found {
+		c.logger.Debug("cache miss",
+			zap.String("recipient_type", recipientType),
+			zap.String("cache_key", cacheKey),
+		)
+		return nil, fmt.Errorf("cache key: '%s' not found", cacheKey)
+	}
+
+	c.logger.Debug("cache hit",
+		zap.String("recipient_type", recipientType),
+		zap.Int("routes_count", len(value)),
+	)
+	return value, nil
+}
+
+// Set keeps routes for the cache TTL, or for the miss TTL when there are
+// none, as for an unknown recipient type
+func (c *RouteCache) Set(recipientType string, routes []NotificationRoute) error {
+	cacheKey := fmt.Sprintf(routeCacheKeyPattern, recipientType)
+
+	ttl, ok := entryTTL(len(routes), c.ttl.Get(), c.missTTL)
+	if !ok {
+		return nil
+	}
+	c.engine.SetWithTTL(cacheKey, routes, 1, ttl)
+
+	c.logger.Debug("cache set",
+		zap.String("recipient_type", recipientType),
+		zap.Int("routes_count", len(routes)),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+func (c *RouteCache) Stats() CacheStats {
+	return newCacheStats(c.engine.Metrics)
+}
+
+func (c *RouteCache) Clear() {
+	c.engine.Clear()
+}
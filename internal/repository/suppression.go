@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Suppression reasons
+const (
+	SuppressionHardBounce = "hard_bounce"
+	SuppressionComplaint  = "complaint"
+)
+
+var suppressionReasons = map[string]bool{SuppressionHardBounce: true, SuppressionComplaint: true}
+
+//go:generate mockgen -package mockrepository -destination ./mock/mocksuppression.go . SuppressionProvider
+type SuppressionProvider interface {
+	// SuppressNotificationRecipient suppresses the address an email
+	// notification of the provider was sent to; it reports false when no
+	// such notification is logged or the address is already suppressed
+	SuppressNotificationRecipient(ctx context.Context, providerName string, notificationID string, reason string, detail string) (bool, error)
+	IsSuppressed(ctx context.Context, address string) (bool, error)
+	ListSuppressions(ctx context.Context, filter SuppressionFilter) (SuppressionPage, error)
+	// DeleteSuppression returns the removed suppression;
+	// gorm.ErrRecordNotFound when the address is not suppressed
+	DeleteSuppression(ctx context.Context, address string) (Suppression, error)
+}
+
+var _ SuppressionProvider = (*Persistent)(nil)
+
+// SuppressionFilter narrows the suppressions, newest first; zero fields
+// match everything
+type SuppressionFilter struct {
+	Address string
+	Reason  string
+	Limit   int
+	Offset  int
+}
+
+// SuppressionPage is one page of suppressions with the number of
+// suppressions matching the filter across all pages
+type SuppressionPage struct {
+	Suppressions []Suppression
+	Total        int64
+}
+
+// NormalizeAddress is the form addresses are suppressed and looked up in
+func NormalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+// ParseSuppressionReason accepts the suppression reasons and the empty
+// string
+func ParseSuppressionReason(reason string) (string, error) {
+	if reason != "" && !suppressionReasons[reason] {
+		return "", fmt.Errorf("suppression reason: '%s' not supported, use hard_bounce or complaint", reason)
+	}
+	return reason, nil
+}
+
+func (p *Persistent) SuppressNotificationRecipient(
+	ctx context.Context,
+	providerName string,
+	notificationID string,
+	reason string,
+	detail string,
+) (bool, error) {
+	// The first reason an address was suppressed for is kept
+	result := p.conn.WithContext(ctx).Exec(`
+		INSERT INTO notification_suppressions (address, reason, detail, provider_name, notification_id)
+		SELECT LOWER(TRIM(recipient)), ?, ?, provider_name, notification_id
+		FROM notification_log
+		WHERE notification_id = ? AND provider_name = ? AND channel = ? AND recipient <> ''
+		ON CONFLICT (address) DO NOTHING`,
+		reason, detail, notificationID, providerName, EmailProvider.String(),
+	)
+	if result.Error != nil {
+		logging.From(ctx, p.logger).Error("database insert failed",
+			zap.String("notification_id", notificationID),
+			zap.String("provider_name", providerName),
+			zap.Error(result.Error),
+		)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (p *Persistent) IsSuppressed(ctx context.Context, address string) (bool, error) {
+	count, err := gorm.G[Suppression](p.conn).Where("address = ?", NormalizeAddress(address)).Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (p *Persistent) ListSuppressions(ctx context.Context, filter SuppressionFilter) (SuppressionPage, error) {
+	query := gorm.G[Suppression](p.conn).Scopes()
+	if filter.Address != "" {
+		query = query.Where("address = ?", NormalizeAddress(filter.Address))
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+
+	total, err := query.Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("suppression_reason", filter.Reason),
+			zap.Error(err),
+		)
+		return SuppressionPage{}, err
+	}
+
+	suppressions, err := query.Order("created_at DESC, address ASC").Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("suppression_reason", filter.Reason),
+			zap.Error(err),
+		)
+		return SuppressionPage{}, err
+	}
+
+	return SuppressionPage{Suppressions: suppressions, Total: total}, nil
+}
+
+func (p *Persistent) DeleteSuppression(ctx context.Context, address string) (Suppression, error) {
+	var removed []Suppression
+
+	result := p.conn.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("address = ?", NormalizeAddress(address)).
+		Delete(&removed)
+	if result.Error != nil {
+		logging.From(ctx, p.logger).Error("database delete failed",
+			zap.Error(result.Error),
+		)
+		return Suppression{}, result.Error
+	}
+	if len(removed) == 0 {
+		return Suppression{}, gorm.ErrRecordNotFound
+	}
+
+	return removed[0], nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	suppressionCacheKeyPattern = "notification:suppressions:%s"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mocksuppressioncache.go . SuppressionCacheProvider
+type SuppressionCacheProvider interface {
+	Get(address string) (bool, error)
+	Set(address string, suppressed bool) error
+	// Delete drops the entry of the address, e.g. once its suppression is
+	// removed
+	Delete(address string)
+	Stats() CacheStats
+	// Clear drops every entry, and the statistics with them, so the next
+	// reads go to the database
+	Clear()
+}
+
+var _ SuppressionCacheProvider = (*SuppressionCache)(nil)
+
+// SuppressionCache caches whether an address is suppressed. An address that
+// is not is only kept for the miss TTL, so a suppression added on any
+// instance applies soon
+type SuppressionCache struct {
+	engine  *ristretto.Cache[string, bool]
+	ttl     *CacheTTL
+	missTTL time.Duration
+	logger  *zap.Logger
+}
+
+func NewSuppressionCache(lc fx.Lifecycle, params CacheParams) (*SuppressionCache, error) {
+	engine, err := ristretto.NewCache(&ristretto.Config[string, bool]{
+		NumCounters: params.Config.NumCounters,
+		MaxCost:     params.Config.MaxCost,
+		BufferItems: params.Config.BufferItems,
+		Metrics:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			engine.Close()
+			return nil
+		},
+	})
+
+	return &SuppressionCache{
+		engine:  engine,
+		ttl:     params.TTL,
+		missTTL: params.Config.MissExpiredTime,
+		logger:  params.Logger,
+	}, nil
+}
+
+func (c *SuppressionCache) Get(address string) (bool, error) {
+	cacheKey := fmt.Sprintf(suppressionCacheKeyPattern, NormalizeAddress(address))
+
+	value, found := c.engine.Get(cacheKey)
+	if !found {
+		c.logger.Debug("cache miss",
+			zap.String("cache_key", cacheKey),
+		)
+		return false, fmt.Errorf("cache key: '%s' not found", cacheKey)
+	}
+
+	c.logger.Debug("cache hit",
+		zap.Bool("suppressed", value),
+	)
+	return value, nil
+}
+
+func (c *SuppressionCache) Set(address string, suppressed bool) error {
+	cacheKey := fmt.Sprintf(suppressionCacheKeyPattern, NormalizeAddress(address))
+
+	count := 0
+	if suppressed {
+		count = 1
+	}
+	ttl, ok := entryTTL(count, c.ttl.Get(), c.missTTL)
+	if !ok {
+		return nil
+	}
+	c.engine.SetWithTTL(cacheKey, suppressed, 1, ttl)
+
+	c.logger.Debug("cache set",
+		zap.Bool("suppressed", suppressed),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+func (c *SuppressionCache) Delete(address string) {
+	c.engine.Del(fmt.Sprintf(suppressionCacheKeyPattern, NormalizeAddress(address)))
+}
+
+func (c *SuppressionCache) Stats() CacheStats {
+	return newCacheStats(c.engine.Metrics)
+}
+
+func (c *SuppressionCache) Clear() {
+	c.engine.Clear()
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTemplateExists         = errors.New("template already exists")
+	ErrUnknownTemplateVersion = errors.New("template has no such version")
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mocktemplate.go . TemplateProvider
+type TemplateProvider interface {
+	ListTemplates(ctx context.Context, filter TemplateFilter) (TemplatePage, error)
+	// FindTemplate returns gorm.ErrRecordNotFound when no template has the
+	// name
+	FindTemplate(ctx context.Context, name string) (Template, error)
+	// ListTemplateVersions returns every version of the template, newest
+	// first
+	ListTemplateVersions(ctx context.Context, name string) ([]TemplateVersion, error)
+	// FindTemplateVersion returns ErrUnknownTemplateVersion when the
+	// template has no such version
+	FindTemplateVersion(ctx context.Context, name string, version int) (TemplateVersion, error)
+	// FindActiveTemplateVersion returns the content of the version in use;
+	// gorm.ErrRecordNotFound when no template has the name
+	FindActiveTemplateVersion(ctx context.Context, name string) (TemplateVersion, error)
+	// CreateTemplate stores the template with the content as its active
+	// version 1; ErrTemplateExists when the name is taken
+	CreateTemplate(ctx context.Context, template Template, content TemplateVersion) (Template, TemplateVersion, error)
+	// AddTemplateVersion stores the content as the next version of the
+	// template, leaving the active version as it is. It returns the latest
+	// version before it and the one stored
+	AddTemplateVersion(ctx context.Context, content TemplateVersion) (TemplateVersion, TemplateVersion, error)
+	// ActivateTemplateVersion makes the version the one in use and returns
+	// the template as it was before
+	ActivateTemplateVersion(ctx context.Context, name string, version int) (Template, error)
+}
+
+var _ TemplateProvider = (*Persistent)(nil)
+
+// TemplateFilter pages the templates, ordered by name
+type TemplateFilter struct {
+	Limit  int
+	Offset int
+}
+
+// TemplatePage is one page of templates with the number of templates
+// across all pages
+type TemplatePage struct {
+	Templates []Template
+	Total     int64
+}
+
+func (p *Persistent) ListTemplates(ctx context.Context, filter TemplateFilter) (TemplatePage, error) {
+	query := gorm.G[Template](p.conn).Scopes()
+
+	total, err := query.Count(ctx, "*")
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return TemplatePage{}, err
+	}
+
+	templates, err := query.Order("name ASC").Limit(filter.Limit).Offset(filter.Offset).Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.Error(err),
+		)
+		return TemplatePage{}, err
+	}
+
+	return TemplatePage{Templates: templates, Total: total}, nil
+}
+
+func (p *Persistent) FindTemplate(ctx context.Context, name string) (Template, error) {
+	template, err := gorm.G[Template](p.conn).Where("name = ?", name).First(ctx)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.From(ctx, p.logger).Error("database query failed",
+				zap.String("template_name", name),
+				zap.Error(err),
+			)
+		}
+		return Template{}, err
+	}
+	return template, nil
+}
+
+func (p *Persistent) ListTemplateVersions(ctx context.Context, name string) ([]TemplateVersion, error) {
+	versions, err := gorm.G[TemplateVersion](p.conn).
+		Where("template_name = ?", name).
+		Order("version DESC").
+		Find(ctx)
+	if err != nil {
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("template_name", name),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (p *Persistent) FindTemplateVersion(ctx context.Context, name string, version int) (TemplateVersion, error) {
+	content, err := gorm.G[TemplateVersion](p.conn).
+		Where("template_name = ? AND version = ?", name, version).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return TemplateVersion{}, ErrUnknownTemplateVersion
+		}
+		logging.From(ctx, p.logger).Error("database query failed",
+			zap.String("template_name", name),
+			zap.Int("template_version", version),
+			zap.Error(err),
+		)
+		return TemplateVersion{}, err
+	}
+	return content, nil
+}
+
+func (p *Persistent) FindActiveTemplateVersion(ctx context.Context, name string) (TemplateVersion, error) {
+	content, err := gorm.G[TemplateVersion](p.conn).
+		Where("template_name = ? AND version = (SELECT active_version FROM notification_templates WHERE name = ?)", name, name).
+		First(ctx)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.From(ctx, p.logger).Error("database query failed",
+				zap.String("template_name", name),
+				zap.Error(err),
+			)
+		}
+		return TemplateVersion{}, err
+	}
+	return content, nil
+}
+
+func (p *Persistent) CreateTemplate(ctx context.Context, template Template, content TemplateVersion) (Template, TemplateVersion, error) {
+	template.ActiveVersion = 1
+	template.LatestVersion = 1
+	content.TemplateName = template.Name
+	content.Version = 1
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&template)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTemplateExists
+		}
+
+		return tx.Create(&content).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrTemplateExists) {
+			logging.From(ctx, p.logger).Error("database insert failed",
+				zap.String("template_name", template.Name),
+				zap.Error(err),
+			)
+		}
+		return Template{}, TemplateVersion{}, err
+	}
+
+	return template, content, nil
+}
+
+func (p *Persistent) AddTemplateVersion(ctx context.Context, content TemplateVersion) (TemplateVersion, TemplateVersion, error) {
+	var latest TemplateVersion
+
+	_, err := p.updateTemplate(ctx, content.TemplateName, func(tx *gorm.DB, previous Template) error {
+		var err error
+		latest, err = gorm.G[TemplateVersion](tx).
+			Where("template_name = ? AND version = ?", previous.Name, previous.LatestVersion).
+			First(ctx)
+		if err != nil {
+			return err
+		}
+
+		content.Version = previous.LatestVersion + 1
+		if err := tx.Create(&content).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&Template{}).
+			Where("name = ?", previous.Name).
+			UpdateColumns(map[string]any{
+				"latest_version": content.Version,
+				"updated_at":     time.Now(),
+			}).Error
+	})
+	if err != nil {
+		return TemplateVersion{}, TemplateVersion{}, err
+	}
+	return latest, content, nil
+}
+
+func (p *Persistent) ActivateTemplateVersion(ctx context.Context, name string, version int) (Template, error) {
+	return p.updateTemplate(ctx, name, func(tx *gorm.DB, previous Template) error {
+		if version < 1 || version > previous.LatestVersion {
+			return ErrUnknownTemplateVersion
+		}
+
+		return tx.Model(&Template{}).
+			Where("name = ?", name).
+			UpdateColumns(map[string]any{
+				"active_version": version,
+				"updated_at":     time.Now(),
+			}).Error
+	})
+}
+
+// updateTemplate runs update on the locked template and returns the
+// template as it was before, so concurrent edits number versions in turn
+func (p *Persistent) updateTemplate(
+	ctx context.Context,
+	name string,
+	update func(tx *gorm.DB, previous Template) error,
+) (Template, error) {
+	var previous Template
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		previous, err = gorm.G[Template](tx, clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", name).
+			First(ctx)
+		if err != nil {
+			return err
+		}
+
+		return update(tx, previous)
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrUnknownTemplateVersion) {
+			logging.From(ctx, p.logger).Error("database update failed",
+				zap.String("template_name", name),
+				zap.Error(err),
+			)
+		}
+		return Template{}, err
+	}
+
+	return previous, nil
+}
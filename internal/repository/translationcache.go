@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	translationCacheKeyPattern = "notification:translations:%s"
+)
+
+//go:generate mockgen -package mockrepository -destination ./mock/mocktranslationcache.go . TranslationCacheProvider
+type TranslationCacheProvider interface {
+	Get(key string) ([]NotificationTranslation, error)
+	Set(key string, translations []NotificationTranslation) error
+	Stats() CacheStats
+	// Clear drops every entry, and the statistics with them, so the next
+	// reads go to the database
+	Clear()
+}
+
+var _ TranslationCacheProvider = (*TranslationCache)(nil)
+
+// TranslationCache caches every locale of a template key, so edited
+// translations take effect once the entry expires
+type TranslationCache struct {
+	engine  *ristretto.Cache[string, []NotificationTranslation]
+	ttl     *CacheTTL
+	missTTL time.Duration
+	logger  *zap.Logger
+}
+
+func NewTranslationCache(lc fx.Lifecycle, params CacheParams) (*TranslationCache, error) {
+	engine, err := ristretto.NewCache(&ristretto.Config[string, []NotificationTranslation]{
+		NumCounters: params.Config.NumCounters,
+		MaxCost:     params.Config.MaxCost,
+		BufferItems: params.Config.BufferItems,
+		Metrics:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			engine.Close()
+			return nil
+		},
+	})
+
+	return &TranslationCache{
+		engine:  engine,
+		ttl:     params.TTL,
+		missTTL: params.Config.MissExpiredTime,
+		logger:  params.Logger,
+	}, nil
+}
+
+func (c *TranslationCache) Get(key string) ([]NotificationTranslation, error) {
+	cacheKey := fmt.Sprintf(translationCacheKeyPattern, key)
+
+	value, found := c.engine.Get(cacheKey)
+	if !found {
+		c.logger.Debug("cache miss",
+			zap.String("translation_key", key),
+			zap.String("cache_key", cacheKey),
+		)
+		return nil, fmt.Errorf("cache key: '%s' not found", cacheKey)
+	}
+
+	c.logger.Debug("cache hit",
+		zap.String("translation_key", key),
+		zap.Int("translations_count", len(value)),
+	)
+	return value, nil
+}
+
+// Set keeps translations for the cache TTL, or for the miss TTL when the key
+// has none
+func (c *TranslationCache) Set(key string, translations []NotificationTranslation) error {
+	cacheKey := fmt.Sprintf(translationCacheKeyPattern, key)
+
+	ttl, ok := entryTTL(len(translations), c.ttl.Get(), c.missTTL)
+	if !ok {
+		return nil
+	}
+	c.engine.SetWithTTL(cacheKey, translations, 1, ttl)
+
+	c.logger.Debug("cache set",
+		zap.String("translation_key", key),
+		zap.Int("translations_count", len(translations)),
+		zap.Duration("ttl", ttl),
+	)
+	return nil
+}
+
+func (c *TranslationCache) Stats() CacheStats {
+	return newCacheStats(c.engine.Metrics)
+}
+
+func (c *TranslationCache) Clear() {
+	c.engine.Clear()
+}
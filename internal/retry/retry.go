@@ -0,0 +1,216 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Module("retry",
+	fx.Invoke(RegisterRetrier),
+)
+
+type RetrierParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    service.PartialSuccessConfig
+	Queue     service.RetryQueueConfig
+	Retries   repository.ChannelRetryProvider
+	Service   service.NotificationProvider
+	Events    event.Bus
+	Clock     clock.Clock
+	Logger    *zap.Logger
+}
+
+// RegisterRetrier starts retrying queued channels with the application when
+// partial success or the retry queue is enabled
+func RegisterRetrier(params RetrierParams) error {
+	if !params.Config.Enabled && !params.Queue.Enabled {
+		return nil
+	}
+	if params.Config.RetryInterval <= 0 {
+		return errors.New("channel retry interval must be positive")
+	}
+	if params.Config.RetryMaxAttempts < 1 {
+		return errors.New("channel retry max attempts must be at least 1")
+	}
+	if params.Config.RetryMaxInterval < params.Config.RetryInterval {
+		return errors.New("channel retry max interval must be at least the retry interval")
+	}
+
+	retrier := NewRetrier(params)
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			retrier.Start()
+			return nil
+		},
+		OnStop: retrier.Stop,
+	})
+
+	return nil
+}
+
+// maxBackoffShift is the largest doubling a duration can take
+const maxBackoffShift = 62
+
+// Retrier sends the channels partial success or the retry queue queued
+// again, backing off exponentially, and moves those it gives up to the dead
+// letters. Instances share the queue, each retry being claimed by one of
+// them
+type Retrier struct {
+	config  service.PartialSuccessConfig
+	retries repository.ChannelRetryProvider
+	service service.NotificationProvider
+	events  event.Bus
+	clock   clock.Clock
+	logger  *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRetrier(params RetrierParams) *Retrier {
+	return &Retrier{
+		config:  params.Config,
+		retries: params.Retries,
+		service: params.Service,
+		events:  params.Events,
+		clock:   params.Clock,
+		logger:  params.Logger,
+	}
+}
+
+// Start retries the due channels every interval until Stop
+func (r *Retrier) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.clock.After(r.config.RetryInterval):
+			}
+
+			r.RetryDue(ctx)
+		}
+	}()
+}
+
+// Stop ends retrying and waits for the channel being sent to finish or ctx
+// to end
+func (r *Retrier) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryDue sends every channel whose retry is due
+func (r *Retrier) RetryDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		// A claim outliving an interval belongs to a retrier that died
+		retry, claimed, err := r.retries.ClaimChannelRetry(ctx, r.config.RetryInterval)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Error("failed to claim channel retry", zap.Error(err))
+			}
+			return
+		}
+		if !claimed {
+			return
+		}
+
+		r.send(context.WithoutCancel(ctx), retry)
+	}
+}
+
+// send delivers one queued channel. A channel no provider accepted is
+// rescheduled until it runs out of attempts; a delivered channel is
+// forgotten, and any other is moved to the dead letters, as sending it
+// again would repeat or fail again. Every retry emits a retried event, and a
+// retry given up a failed one. A failed write is logged by the repository,
+// leaving the claim to expire
+func (r *Retrier) send(ctx context.Context, retry repository.ChannelRetry) {
+	fields := []zap.Field{
+		zap.String("notification_id", retry.NotificationID),
+		zap.String("recipient_type", retry.RecipientType),
+		zap.String("channel", retry.Channel),
+	}
+
+	var notification service.Notification
+	if err := json.Unmarshal(retry.Notification, &notification); err != nil {
+		r.logger.Error("failed to decode queued channel, moving it to the dead letters", append(fields, zap.Error(err))...)
+		r.emit(ctx, event.TypeNotificationFailed, retry, retry.Attempts, err)
+		_ = r.retries.BuryChannelRetry(ctx, retry.ID, retry.Attempts, service.RetryDoNotRetry, err.Error())
+		return
+	}
+
+	ctx = quota.WithSubject(ctx, retry.Tenant)
+	report, err := r.service.SendChannel(ctx, retry.NotificationID, retry.RecipientType, retry.Channel, notification)
+	attempts := retry.Attempts + 1
+	fields = append(fields, zap.Int("attempts", attempts))
+	r.emit(ctx, event.TypeNotificationRetried, retry, attempts, err)
+
+	switch {
+	case err == nil:
+		r.logger.Info("delivered queued channel", fields...)
+		_ = r.retries.DeleteChannelRetry(ctx, retry.ID)
+	case report.RetryDisposition == service.RetrySafe && attempts < r.config.RetryMaxAttempts:
+		next := r.clock.Now().Add(r.backoff(attempts))
+		r.logger.Warn("failed to deliver queued channel, retrying later", append(fields, zap.Time("next_attempt_at", next), zap.Error(err))...)
+		_ = r.retries.RescheduleChannelRetry(ctx, retry.ID, attempts, next)
+	default:
+		r.logger.Error("failed to deliver queued channel, moving it to the dead letters", append(fields, zap.String("retry_disposition", report.RetryDisposition), zap.Error(err))...)
+		r.emit(ctx, event.TypeNotificationFailed, retry, attempts, err)
+		_ = r.retries.BuryChannelRetry(ctx, retry.ID, attempts, report.RetryDisposition, err.Error())
+	}
+}
+
+// backoff is the wait after attempts failed retries: the retry interval
+// doubled per attempt, up to the max interval. The interval is compared
+// before it is shifted, so a large attempt count cannot overflow it
+func (r *Retrier) backoff(attempts int) time.Duration {
+	if attempts >= maxBackoffShift || r.config.RetryInterval > r.config.RetryMaxInterval>>attempts {
+		return r.config.RetryMaxInterval
+	}
+	return r.config.RetryInterval << attempts
+}
+
+func (r *Retrier) emit(ctx context.Context, eventType string, retry repository.ChannelRetry, attempts int, err error) {
+	r.events.Emit(ctx, event.NotificationEvent{
+		Type:           eventType,
+		NotificationID: retry.NotificationID,
+		RecipientType:  retry.RecipientType,
+		Channels:       []string{retry.Channel},
+		Attempts:       attempts,
+		Err:            err,
+	})
+}
@@ -0,0 +1,207 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	mockevent "github.com/koungkub/fw-challenge-notification-service/internal/event/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/service"
+	mockservice "github.com/koungkub/fw-challenge-notification-service/internal/service/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestRetrier_RetryDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	push := func(attempts int) repository.ChannelRetry {
+		return repository.ChannelRetry{
+			ID:             7,
+			NotificationID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+			RecipientType:  "seller",
+			Channel:        "PushNotification",
+			Tenant:         "tenant-a",
+			Notification:   []byte(`{"To":"device-token","Title":"New order","Message":"Order 42 is waiting"}`),
+			Attempts:       attempts,
+		}
+	}
+	notification := service.Notification{To: "device-token", Title: "New order", Message: "Order 42 is waiting"}
+	rejected := service.DeliveryReport{RetryDisposition: service.RetrySafe}
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*mockrepository.MockChannelRetryProvider, *mockservice.MockNotificationProvider)
+		expectedEvents []string
+	}{
+		{
+			name: "sends every due channel as its tenant and forgets it",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(push(0), true, nil),
+					notifications.EXPECT().SendChannel(gomock.Any(), "01JB8Z5XK3M4N5P6Q7R8S9T0VW", "seller", "PushNotification", notification).
+						DoAndReturn(func(ctx context.Context, _, _, _ string, _ service.Notification) (service.DeliveryReport, error) {
+							assert.Equal(t, "tenant-a", quota.SubjectFrom(ctx))
+							return service.DeliveryReport{RetryDisposition: service.RetryNotNeeded}, nil
+						}),
+					retries.EXPECT().DeleteChannelRetry(gomock.Any(), uint(7)).Return(nil),
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, nil),
+				)
+			},
+			expectedEvents: []string{event.TypeNotificationRetried},
+		},
+		{
+			name: "reschedules a channel no provider accepted, doubling the wait",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(push(1), true, nil),
+					notifications.EXPECT().SendChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(rejected, errors.New("provider down")),
+					retries.EXPECT().RescheduleChannelRetry(gomock.Any(), uint(7), 2, now.Add(4*time.Minute)).Return(nil),
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, nil),
+				)
+			},
+			expectedEvents: []string{event.TypeNotificationRetried},
+		},
+		{
+			name: "buries a channel out of attempts",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(push(2), true, nil),
+					notifications.EXPECT().SendChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(rejected, errors.New("provider down")),
+					retries.EXPECT().BuryChannelRetry(gomock.Any(), uint(7), 3, service.RetrySafe, "provider down").Return(nil),
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, nil),
+				)
+			},
+			expectedEvents: []string{event.TypeNotificationRetried, event.TypeNotificationFailed},
+		},
+		{
+			name: "buries a channel that may have been delivered",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, notifications *mockservice.MockNotificationProvider) {
+				gomock.InOrder(
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(push(0), true, nil),
+					notifications.EXPECT().SendChannel(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(service.DeliveryReport{RetryDisposition: service.RetryUnsafe}, errors.New("timeout")),
+					retries.EXPECT().BuryChannelRetry(gomock.Any(), uint(7), 1, service.RetryUnsafe, "timeout").Return(nil),
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, nil),
+				)
+			},
+			expectedEvents: []string{event.TypeNotificationRetried, event.TypeNotificationFailed},
+		},
+		{
+			name: "buries a channel that cannot be decoded",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, _ *mockservice.MockNotificationProvider) {
+				undecodable := push(1)
+				undecodable.Notification = []byte(`"not a notification"`)
+				gomock.InOrder(
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(undecodable, true, nil),
+					retries.EXPECT().BuryChannelRetry(gomock.Any(), uint(7), 1, service.RetryDoNotRetry, gomock.Any()).Return(nil),
+					retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, nil),
+				)
+			},
+			expectedEvents: []string{event.TypeNotificationFailed},
+		},
+		{
+			name: "stops when claiming fails",
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider, _ *mockservice.MockNotificationProvider) {
+				retries.EXPECT().ClaimChannelRetry(gomock.Any(), time.Minute).Return(repository.ChannelRetry{}, false, errors.New("database down"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			retries := mockrepository.NewMockChannelRetryProvider(ctrl)
+			notifications := mockservice.NewMockNotificationProvider(ctrl)
+			tt.setupMocks(retries, notifications)
+
+			clk := mockclock.NewMockClock(ctrl)
+			clk.EXPECT().Now().Return(now).AnyTimes()
+
+			var emitted []string
+			events := mockevent.NewMockBus(ctrl)
+			events.EXPECT().Emit(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, evt event.NotificationEvent) {
+					assert.Equal(t, "01JB8Z5XK3M4N5P6Q7R8S9T0VW", evt.NotificationID)
+					assert.Equal(t, []string{"PushNotification"}, evt.Channels)
+					emitted = append(emitted, evt.Type)
+				}).AnyTimes()
+
+			retrier := NewRetrier(RetrierParams{
+				Config:  service.PartialSuccessConfig{Enabled: true, RetryInterval: time.Minute, RetryMaxInterval: time.Hour, RetryMaxAttempts: 3},
+				Retries: retries,
+				Service: notifications,
+				Events:  events,
+				Clock:   clk,
+				Logger:  zap.NewNop(),
+			})
+
+			retrier.RetryDue(context.Background())
+			assert.Equal(t, tt.expectedEvents, emitted)
+		})
+	}
+}
+
+func TestRegisterRetrier(t *testing.T) {
+	t.Run("does nothing while disabled", func(t *testing.T) {
+		assert.NoError(t, RegisterRetrier(RetrierParams{}))
+	})
+
+	t.Run("validates the retries of the retry queue", func(t *testing.T) {
+		err := RegisterRetrier(RetrierParams{Queue: service.RetryQueueConfig{Enabled: true}})
+		assert.EqualError(t, err, "channel retry interval must be positive")
+	})
+
+	t.Run("rejects a retry interval of zero", func(t *testing.T) {
+		err := RegisterRetrier(RetrierParams{Config: service.PartialSuccessConfig{Enabled: true, RetryMaxAttempts: 5}})
+		assert.EqualError(t, err, "channel retry interval must be positive")
+	})
+
+	t.Run("rejects retries without attempts", func(t *testing.T) {
+		err := RegisterRetrier(RetrierParams{Config: service.PartialSuccessConfig{Enabled: true, RetryInterval: time.Minute}})
+		assert.EqualError(t, err, "channel retry max attempts must be at least 1")
+	})
+
+	t.Run("rejects a max interval below the retry interval", func(t *testing.T) {
+		err := RegisterRetrier(RetrierParams{Config: service.PartialSuccessConfig{
+			Enabled:          true,
+			RetryInterval:    time.Minute,
+			RetryMaxInterval: time.Second,
+			RetryMaxAttempts: 5,
+		}})
+		assert.EqualError(t, err, "channel retry max interval must be at least the retry interval")
+	})
+}
+
+func TestRetrier_backoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		expected time.Duration
+	}{
+		{name: "doubles the interval per attempt", attempts: 3, expected: 8 * time.Minute},
+		{name: "stops at the max interval", attempts: 6, expected: time.Hour},
+		{name: "does not overflow past the width of a duration", attempts: 64, expected: time.Hour},
+		{name: "does not overflow on many attempts", attempts: 1 << 20, expected: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrier := NewRetrier(RetrierParams{
+				Config: service.PartialSuccessConfig{RetryInterval: time.Minute, RetryMaxInterval: time.Hour},
+			})
+
+			assert.Equal(t, tt.expected, retrier.backoff(tt.attempts))
+		})
+	}
+}
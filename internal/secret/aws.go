@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// awsClient builds an AWS service client from the default credential chain
+// on first use, so deployments without AWS references never need one. The
+// SDK's own HTTP client is used, so settings such as AWS_CA_BUNDLE apply
+type awsClient[T any] struct {
+	region  string
+	timeout time.Duration
+	build   func(aws.Config) T
+
+	mu     sync.Mutex
+	client *T
+}
+
+func newAWSClient[T any](region string, timeout time.Duration, build func(aws.Config) T) *awsClient[T] {
+	return &awsClient[T]{
+		region:  region,
+		timeout: timeout,
+		build:   build,
+	}
+}
+
+func (c *awsClient[T]) get(ctx context.Context) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		return *c.client, nil
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(c.timeout)),
+	}
+	if c.region != "" {
+		opts = append(opts, awsconfig.WithRegion(c.region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("aws config: %w", err)
+	}
+	if cfg.Region == "" {
+		var zero T
+		return zero, errors.New("aws region is not configured")
+	}
+
+	client := c.build(cfg)
+	c.client = &client
+	return client, nil
+}
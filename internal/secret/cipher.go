@@ -0,0 +1,150 @@
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/fx"
+)
+
+// encryptedPrefix marks values written by Cipher; any other value is
+// plaintext written before encryption was enabled
+const encryptedPrefix = "enc:v1:"
+
+// Cipher encrypts values with AES-256-GCM. Each value records the id of its
+// key, so values written with a previous key stay readable during rotation
+type Cipher struct {
+	currentID string
+	aeads     map[string]cipher.AEAD
+}
+
+type CipherConfig struct {
+	Key          string   `envconfig:"SECRET_KEY_ENCRYPTION_KEY" secret:"true"`
+	PreviousKeys []string `envconfig:"SECRET_KEY_PREVIOUS_ENCRYPTION_KEYS" secret:"true"`
+}
+
+type CipherParams struct {
+	fx.In
+
+	Config  CipherConfig
+	Secrets Provider
+}
+
+// NewCipher resolves the base64 encoded keys through Secrets, so they can be
+// given as is or as vault://, awssm:// or kms:// references. Without a key
+// values are written in plaintext
+func NewCipher(params CipherParams) (*Cipher, error) {
+	c := &Cipher{aeads: map[string]cipher.AEAD{}}
+
+	keys := params.Config.PreviousKeys
+	if params.Config.Key != "" {
+		keys = append([]string{params.Config.Key}, keys...)
+	}
+
+	for i, key := range keys {
+		id, aead, err := newAEAD(params.Secrets, key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %d: %w", i, err)
+		}
+		c.aeads[id] = aead
+		if i == 0 && params.Config.Key != "" {
+			c.currentID = id
+		}
+	}
+
+	return c, nil
+}
+
+func newAEAD(secrets Provider, key string) (string, cipher.AEAD, error) {
+	resolved, err := secrets.Resolve(context.Background(), key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(resolved))
+	if err != nil {
+		return "", nil, fmt.Errorf("key is not base64: %w", err)
+	}
+	if len(raw) != 32 {
+		return "", nil, fmt.Errorf("key is %d bytes, AES-256 needs 32", len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:4]), aead, nil
+}
+
+// Encrypt returns value encrypted with the current key, or unchanged when
+// no key is configured
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if c.currentID == "" || value == "" {
+		return value, nil
+	}
+
+	aead := c.aeads[c.currentID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return encryptedPrefix + c.currentID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of an encrypted value; plaintext values are
+// returned unchanged
+func (c *Cipher) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("value is encrypted with unknown key '%s'", id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt value with key '%s': %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// Stale reports whether value is not written with the current key, i.e. is
+// plaintext while a key is configured or uses a previous key
+func (c *Cipher) Stale(value string) bool {
+	if value == "" {
+		return false
+	}
+	if c.currentID == "" {
+		return strings.HasPrefix(value, encryptedPrefix)
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+c.currentID+":")
+}
@@ -0,0 +1,175 @@
+package secret
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testKey         = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	testPreviousKey = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+// newTestCipher resolves every key to itself
+func newTestCipher(t *testing.T, config CipherConfig) *Cipher {
+	ctrl := gomock.NewController(t)
+	secrets := mocksecret.NewMockProvider(ctrl)
+	secrets.EXPECT().Resolve(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, value string) (string, error) {
+			return value, nil
+		}).AnyTimes()
+
+	cipher, err := NewCipher(CipherParams{Config: config, Secrets: secrets})
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestNewCipher(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        CipherConfig
+		expectedError string
+	}{
+		{
+			name:   "accepts missing key",
+			config: CipherConfig{},
+		},
+		{
+			name:   "accepts current and previous keys",
+			config: CipherConfig{Key: testKey, PreviousKeys: []string{testPreviousKey}},
+		},
+		{
+			name:          "rejects key that is not base64",
+			config:        CipherConfig{Key: "not base64!"},
+			expectedError: "encryption key 0: key is not base64",
+		},
+		{
+			name:          "rejects short key",
+			config:        CipherConfig{Key: base64.StdEncoding.EncodeToString([]byte("short"))},
+			expectedError: "encryption key 0: key is 5 bytes, AES-256 needs 32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			secrets := mocksecret.NewMockProvider(ctrl)
+			secrets.EXPECT().Resolve(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, value string) (string, error) {
+					return value, nil
+				}).AnyTimes()
+
+			_, err := NewCipher(CipherParams{Config: tt.config, Secrets: secrets})
+
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	cipher := newTestCipher(t, CipherConfig{Key: testKey})
+
+	encrypted, err := cipher.Encrypt("provider-secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, encryptedPrefix))
+	assert.NotContains(t, encrypted, "provider-secret")
+
+	again, err := cipher.Encrypt("provider-secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "nonce must be random")
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "provider-secret", decrypted)
+}
+
+func TestCipher_Decrypt(t *testing.T) {
+	previous := newTestCipher(t, CipherConfig{Key: testPreviousKey})
+	encryptedWithPrevious, err := previous.Encrypt("provider-secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		config        CipherConfig
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "returns plaintext unchanged",
+			config:   CipherConfig{Key: testKey},
+			value:    "legacy-plaintext",
+			expected: "legacy-plaintext",
+		},
+		{
+			name:     "decrypts value of previous key",
+			config:   CipherConfig{Key: testKey, PreviousKeys: []string{testPreviousKey}},
+			value:    encryptedWithPrevious,
+			expected: "provider-secret",
+		},
+		{
+			name:          "fails on unknown key",
+			config:        CipherConfig{Key: testKey},
+			value:         encryptedWithPrevious,
+			expectedError: "value is encrypted with unknown key",
+		},
+		{
+			name:          "fails on tampered value",
+			config:        CipherConfig{Key: testPreviousKey},
+			value:         encryptedWithPrevious[:len(encryptedWithPrevious)-4] + "AAAA",
+			expectedError: "decrypt value with key",
+		},
+		{
+			name:          "fails on malformed value",
+			config:        CipherConfig{Key: testKey},
+			value:         encryptedPrefix + "missing-separator",
+			expectedError: "malformed encrypted value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher := newTestCipher(t, tt.config)
+
+			result, err := cipher.Decrypt(tt.value)
+
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCipher_Stale(t *testing.T) {
+	current := newTestCipher(t, CipherConfig{Key: testKey, PreviousKeys: []string{testPreviousKey}})
+	encryptedWithCurrent, err := current.Encrypt("provider-secret")
+	require.NoError(t, err)
+	encryptedWithPrevious, err := newTestCipher(t, CipherConfig{Key: testPreviousKey}).Encrypt("provider-secret")
+	require.NoError(t, err)
+
+	disabled := newTestCipher(t, CipherConfig{PreviousKeys: []string{testKey}})
+
+	assert.False(t, current.Stale(""))
+	assert.False(t, current.Stale(encryptedWithCurrent))
+	assert.True(t, current.Stale(encryptedWithPrevious))
+	assert.True(t, current.Stale("legacy-plaintext"))
+	// Without a current key, rows are decrypted back to plaintext
+	assert.True(t, disabled.Stale(encryptedWithCurrent))
+	assert.False(t, disabled.Stale("legacy-plaintext"))
+}
@@ -0,0 +1,54 @@
+package secret
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+var _ Store = (*KMSStore)(nil)
+
+// KMSStore decrypts data keys through the AWS KMS API, so an encryption key
+// can be configured as its KMS-encrypted form
+type KMSStore struct {
+	client *awsClient[*kms.Client]
+}
+
+func NewKMSStore(config SecretConfig) *KMSStore {
+	return &KMSStore{
+		client: newAWSClient(config.KMSRegion, config.Timeout, func(cfg aws.Config) *kms.Client {
+			return kms.NewFromConfig(cfg, func(o *kms.Options) {
+				if config.KMSEndpoint != "" {
+					o.BaseEndpoint = aws.String(config.KMSEndpoint)
+				}
+			})
+		}),
+	}
+}
+
+// Fetch decrypts path, the base64 ciphertext blob returned by
+// GenerateDataKey or Encrypt, and returns the plaintext base64 encoded
+func (s *KMSStore) Fetch(ctx context.Context, path string, field string) (string, error) {
+	if field != "" {
+		return "", errors.New("kms reference takes no #field")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(path)
+	if err != nil {
+		return "", fmt.Errorf("kms reference is not base64: %w", err)
+	}
+
+	client, err := s.client.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(output.Plaintext), nil
+}
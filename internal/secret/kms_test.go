@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMSStore_Fetch(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		status        int
+		body          string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "returns decrypted data key",
+			status:   http.StatusOK,
+			body:     `{"KeyId":"arn:aws:kms:ap-southeast-1:123456789012:key/1","Plaintext":"AAECAw=="}`,
+			expected: "AAECAw==",
+		},
+		{
+			name:          "fails on api error",
+			status:        http.StatusBadRequest,
+			body:          `{"__type":"InvalidCiphertextException"}`,
+			expectedError: "InvalidCiphertextException",
+		},
+		{
+			name:          "fails with field",
+			field:         "key",
+			expectedError: "kms reference takes no #field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+			t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+				assert.Contains(t, r.Header.Get("Authorization"), "/ap-southeast-1/kms/aws4_request")
+
+				var body map[string]string
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "c2VhbGVk", body["CiphertextBlob"])
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			store := NewKMSStore(SecretConfig{
+				KMSRegion:   "ap-southeast-1",
+				KMSEndpoint: server.URL,
+			})
+
+			result, err := store.Fetch(context.Background(), "c2VhbGVk", tt.field)
+
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
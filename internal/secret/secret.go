@@ -0,0 +1,150 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	SchemeVault          = "vault"
+	SchemeSecretsManager = "awssm"
+	SchemeKMS            = "kms"
+)
+
+var Module = fx.Module("secret",
+	fx.Provide(
+		fx.Annotate(
+			NewResolver,
+			fx.As(new(Provider)),
+		),
+		NewCipher,
+	),
+)
+
+// Provider turns a setting or column value into the secret it stands for
+//
+//go:generate mockgen -package mocksecret -destination ./mock/mocksecret.go . Provider,Store
+type Provider interface {
+	// Resolve fetches the secret when value is a reference such as
+	// vault://secret/data/db#password, awssm://prod/db#password or
+	// kms://<ciphertext>, and returns any other value unchanged
+	Resolve(ctx context.Context, value string) (string, error)
+}
+
+// Store fetches secrets from one backend; field selects a key of a secret
+// holding several, and an empty field asks for the whole secret
+type Store interface {
+	Fetch(ctx context.Context, path string, field string) (string, error)
+}
+
+var _ Provider = (*Resolver)(nil)
+
+// Resolver dispatches references to the store of their scheme and caches
+// the fetched values for SECRET_CACHE_TTL, so rotated secrets are picked up
+// once the entry expires
+type Resolver struct {
+	stores map[string]Store
+	ttl    time.Duration
+	clock  clock.Clock
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	value     string
+	fetchedAt time.Time
+}
+
+type SecretConfig struct {
+	CacheTTL               time.Duration `envconfig:"SECRET_CACHE_TTL" default:"5m"`
+	Timeout                time.Duration `envconfig:"SECRET_TIMEOUT" default:"5s"`
+	VaultAddr              string        `envconfig:"VAULT_ADDR"`
+	VaultToken             string        `envconfig:"VAULT_TOKEN" secret:"true"`
+	VaultNamespace         string        `envconfig:"VAULT_NAMESPACE"`
+	SecretsManagerRegion   string        `envconfig:"SECRETS_MANAGER_REGION"`
+	SecretsManagerEndpoint string        `envconfig:"SECRETS_MANAGER_ENDPOINT"`
+	KMSRegion              string        `envconfig:"KMS_REGION"`
+	KMSEndpoint            string        `envconfig:"KMS_ENDPOINT"`
+}
+
+type ResolverParams struct {
+	fx.In
+
+	Config SecretConfig
+	Clock  clock.Clock
+	Logger *zap.Logger
+}
+
+// NewResolver enables Vault when VAULT_ADDR is set; Secrets Manager and KMS
+// are always available and resolve credentials through the default AWS chain
+func NewResolver(params ResolverParams) *Resolver {
+	stores := map[string]Store{
+		SchemeSecretsManager: NewSecretsManagerStore(params.Config),
+		SchemeKMS:            NewKMSStore(params.Config),
+	}
+	if params.Config.VaultAddr != "" {
+		stores[SchemeVault] = NewVaultStore(params.Config, &http.Client{Timeout: params.Config.Timeout})
+	}
+
+	return NewResolverWithStores(stores, params.Config.CacheTTL, params.Clock, params.Logger)
+}
+
+func NewResolverWithStores(stores map[string]Store, ttl time.Duration, clock clock.Clock, logger *zap.Logger) *Resolver {
+	return &Resolver{
+		stores:  stores,
+		ttl:     ttl,
+		clock:   clock,
+		logger:  logger,
+		entries: map[string]entry{},
+	}
+}
+
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok || (scheme != SchemeVault && scheme != SchemeSecretsManager && scheme != SchemeKMS) {
+		return value, nil
+	}
+
+	store, ok := r.stores[scheme]
+	if !ok {
+		return "", fmt.Errorf("secret %s: %s is not configured", value, scheme)
+	}
+
+	r.mu.Lock()
+	cached, found := r.entries[value]
+	r.mu.Unlock()
+	if found && r.clock.Since(cached.fetchedAt) < r.ttl {
+		return cached.value, nil
+	}
+
+	path, field, _ := strings.Cut(rest, "#")
+	secret, err := store.Fetch(ctx, path, field)
+	if err != nil {
+		// A backend outage should not take down callers that already hold a
+		// value; the stale one is kept until the backend answers again
+		if found {
+			r.logger.Warn("failed to refresh secret, using cached value",
+				zap.String("reference", value),
+				zap.Error(err),
+			)
+			return cached.value, nil
+		}
+		return "", fmt.Errorf("secret %s: %w", value, err)
+	}
+
+	r.mu.Lock()
+	r.entries[value] = entry{value: secret, fetchedAt: r.clock.Now()}
+	r.mu.Unlock()
+
+	return secret, nil
+}
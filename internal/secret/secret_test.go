@@ -0,0 +1,144 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		setupMocks    func(*mocksecret.MockStore)
+		expected      string
+		expectedError string
+	}{
+		{
+			name:       "returns plain value unchanged",
+			value:      "plain-secret",
+			setupMocks: func(store *mocksecret.MockStore) {},
+			expected:   "plain-secret",
+		},
+		{
+			name:       "returns value with unknown scheme unchanged",
+			value:      "https://email.example.com",
+			setupMocks: func(store *mocksecret.MockStore) {},
+			expected:   "https://email.example.com",
+		},
+		{
+			name:  "fetches reference from its store",
+			value: "vault://secret/data/db#password",
+			setupMocks: func(store *mocksecret.MockStore) {
+				store.EXPECT().Fetch(gomock.Any(), "secret/data/db", "password").Return("mypassword", nil)
+			},
+			expected: "mypassword",
+		},
+		{
+			name:  "fails when store fails",
+			value: "vault://secret/data/db#password",
+			setupMocks: func(store *mocksecret.MockStore) {
+				store.EXPECT().Fetch(gomock.Any(), "secret/data/db", "password").Return("", errors.New("vault returned status 403"))
+			},
+			expectedError: "secret vault://secret/data/db#password: vault returned status 403",
+		},
+		{
+			name:          "fails when scheme is not configured",
+			value:         "awssm://prod/db#password",
+			setupMocks:    func(store *mocksecret.MockStore) {},
+			expectedError: "secret awssm://prod/db#password: awssm is not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocksecret.NewMockStore(ctrl)
+			mockClock := mockclock.NewMockClock(ctrl)
+			mockClock.EXPECT().Now().Return(time.Now()).AnyTimes()
+
+			tt.setupMocks(mockStore)
+
+			resolver := NewResolverWithStores(map[string]Store{SchemeVault: mockStore}, time.Minute, mockClock, zap.NewNop())
+
+			result, err := resolver.Resolve(context.Background(), tt.value)
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestResolver_Resolve_Cache(t *testing.T) {
+	const reference = "vault://secret/data/db#password"
+
+	tests := []struct {
+		name       string
+		age        time.Duration
+		setupMocks func(*mocksecret.MockStore)
+		expected   string
+	}{
+		{
+			name:       "serves fresh entry from cache",
+			age:        30 * time.Second,
+			setupMocks: func(store *mocksecret.MockStore) {},
+			expected:   "old-password",
+		},
+		{
+			name: "refetches expired entry to pick up rotation",
+			age:  2 * time.Minute,
+			setupMocks: func(store *mocksecret.MockStore) {
+				store.EXPECT().Fetch(gomock.Any(), "secret/data/db", "password").Return("rotated-password", nil)
+			},
+			expected: "rotated-password",
+		},
+		{
+			name: "keeps expired entry when store fails",
+			age:  2 * time.Minute,
+			setupMocks: func(store *mocksecret.MockStore) {
+				store.EXPECT().Fetch(gomock.Any(), "secret/data/db", "password").Return("", errors.New("connection refused"))
+			},
+			expected: "old-password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocksecret.NewMockStore(ctrl)
+			mockClock := mockclock.NewMockClock(ctrl)
+			fetchedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+			mockStore.EXPECT().Fetch(gomock.Any(), "secret/data/db", "password").Return("old-password", nil)
+			mockClock.EXPECT().Now().Return(fetchedAt)
+			resolver := NewResolverWithStores(map[string]Store{SchemeVault: mockStore}, time.Minute, mockClock, zap.NewNop())
+			_, err := resolver.Resolve(context.Background(), reference)
+			require.NoError(t, err)
+
+			tt.setupMocks(mockStore)
+			mockClock.EXPECT().Since(fetchedAt).Return(tt.age)
+			mockClock.EXPECT().Now().Return(fetchedAt.Add(tt.age)).AnyTimes()
+
+			result, err := resolver.Resolve(context.Background(), reference)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
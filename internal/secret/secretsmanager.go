@@ -0,0 +1,60 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+var _ Store = (*SecretsManagerStore)(nil)
+
+// SecretsManagerStore reads secrets through the AWS Secrets Manager API
+type SecretsManagerStore struct {
+	client *awsClient[*secretsmanager.Client]
+}
+
+func NewSecretsManagerStore(config SecretConfig) *SecretsManagerStore {
+	return &SecretsManagerStore{
+		client: newAWSClient(config.SecretsManagerRegion, config.Timeout, func(cfg aws.Config) *secretsmanager.Client {
+			return secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+				if config.SecretsManagerEndpoint != "" {
+					o.BaseEndpoint = aws.String(config.SecretsManagerEndpoint)
+				}
+			})
+		}),
+	}
+}
+
+// Fetch reads the secret string of the secret id; with a field the secret
+// string is a JSON object, as created by the console for key/value secrets
+func (s *SecretsManagerStore) Fetch(ctx context.Context, path string, field string) (string, error) {
+	client, err := s.client.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(path),
+	})
+	if err != nil {
+		return "", err
+	}
+	secretString := aws.ToString(output.SecretString)
+
+	if field == "" {
+		return secretString, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secretString), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field '%s'", field)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,86 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManagerStore_Fetch(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		status        int
+		body          string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "returns whole secret string",
+			status:   http.StatusOK,
+			body:     `{"Name":"prod/db","SecretString":"mypassword"}`,
+			expected: "mypassword",
+		},
+		{
+			name:     "returns field of key value secret",
+			field:    "password",
+			status:   http.StatusOK,
+			body:     `{"Name":"prod/db","SecretString":"{\"username\":\"myuser\",\"password\":\"mypassword\"}"}`,
+			expected: "mypassword",
+		},
+		{
+			name:          "fails on missing field",
+			field:         "token",
+			status:        http.StatusOK,
+			body:          `{"Name":"prod/db","SecretString":"{\"password\":\"mypassword\"}"}`,
+			expectedError: "secret has no field 'token'",
+		},
+		{
+			name:          "fails on api error",
+			status:        http.StatusBadRequest,
+			body:          `{"__type":"ResourceNotFoundException"}`,
+			expectedError: "ResourceNotFoundException",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+			t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+				assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+				assert.Contains(t, r.Header.Get("Authorization"), "/ap-southeast-1/secretsmanager/aws4_request")
+
+				var body map[string]string
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "prod/db", body["SecretId"])
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			store := NewSecretsManagerStore(SecretConfig{
+				SecretsManagerRegion:   "ap-southeast-1",
+				SecretsManagerEndpoint: server.URL,
+			})
+
+			result, err := store.Fetch(context.Background(), "prod/db", tt.field)
+
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
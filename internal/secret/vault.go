@@ -0,0 +1,78 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var _ Store = (*VaultStore)(nil)
+
+// VaultStore reads secrets through the HashiCorp Vault HTTP API; both KV
+// version 1 and version 2 mounts are supported
+type VaultStore struct {
+	addr       string
+	token      string
+	namespace  string
+	httpclient *http.Client
+}
+
+func NewVaultStore(config SecretConfig, httpclient *http.Client) *VaultStore {
+	return &VaultStore{
+		addr:       strings.TrimSuffix(config.VaultAddr, "/"),
+		token:      config.VaultToken,
+		namespace:  config.VaultNamespace,
+		httpclient: httpclient,
+	}
+}
+
+// Fetch reads path, e.g. secret/data/notification for a KV version 2 mount,
+// and returns its field
+func (s *VaultStore) Fetch(ctx context.Context, path string, field string) (string, error) {
+	if field == "" {
+		return "", errors.New("vault reference needs a #field")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+
+	resp, err := s.httpclient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault response: %w", err)
+	}
+
+	data := body.Data
+	// KV version 2 nests the secret next to its metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret has no field '%s'", field)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultStore_Fetch(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		field         string
+		status        int
+		body          string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "reads kv version 2 secret",
+			path:     "secret/data/db",
+			field:    "password",
+			status:   http.StatusOK,
+			body:     `{"data":{"data":{"password":"mypassword"},"metadata":{"version":3}}}`,
+			expected: "mypassword",
+		},
+		{
+			name:     "reads kv version 1 secret",
+			path:     "kv/db",
+			field:    "password",
+			status:   http.StatusOK,
+			body:     `{"data":{"password":"mypassword"}}`,
+			expected: "mypassword",
+		},
+		{
+			name:          "fails on missing field",
+			path:          "secret/data/db",
+			field:         "username",
+			status:        http.StatusOK,
+			body:          `{"data":{"data":{"password":"mypassword"},"metadata":{}}}`,
+			expectedError: "vault secret has no field 'username'",
+		},
+		{
+			name:          "fails on denied token",
+			path:          "secret/data/db",
+			field:         "password",
+			status:        http.StatusForbidden,
+			body:          `{"errors":["permission denied"]}`,
+			expectedError: "vault returned status 403",
+		},
+		{
+			name:          "fails without field",
+			path:          "secret/data/db",
+			expectedError: "vault reference needs a #field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/"+tt.path, r.URL.Path)
+				assert.Equal(t, "my-token", r.Header.Get("X-Vault-Token"))
+				assert.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			store := NewVaultStore(SecretConfig{
+				VaultAddr:      server.URL + "/",
+				VaultToken:     "my-token",
+				VaultNamespace: "team-a",
+			}, server.Client())
+
+			result, err := store.Fetch(context.Background(), tt.path, tt.field)
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+// Content codings of compressed responses, in order of preference when
+// the caller accepts both equally
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// compressibleTypes are the media types worth compressing. Others are
+// either compressed already, like images, or streamed, like server-sent
+// events, which must reach the caller event by event
+var compressibleTypes = map[string]bool{
+	"application/json":         true,
+	"application/problem+json": true,
+	"application/javascript":   true,
+	"text/csv":                 true,
+	"text/css":                 true,
+	"text/html":                true,
+	"text/javascript":          true,
+	"text/plain":               true,
+}
+
+var errUnsupportedEncoding = errors.New("content-encoding: only gzip request bodies are supported")
+
+// compression decompresses gzip request bodies, and compresses responses
+// of at least HTTP_COMPRESSION_MIN_SIZE bytes with br or gzip, whichever
+// the Accept-Encoding of the caller prefers
+func compression(config HTTPConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !decompressRequest(c, config.MaxDecompressedBodyBytes) {
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: config.CompressionMinSize}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+			w.finish()
+		}()
+
+		c.Next()
+	}
+}
+
+// decompressRequest swaps a gzip request body for its decompressed
+// content, bounded by maxBytes, or aborts the request and reports false
+func decompressRequest(c *gin.Context, maxBytes int64) bool {
+	switch strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))) {
+	case "", "identity":
+		return true
+	case encodingGzip, "x-gzip":
+	default:
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, handler.GetRequestError(errUnsupportedEncoding))
+		return false
+	}
+
+	reader, err := gzip.NewReader(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, handler.GetRequestError(fmt.Errorf("gzip request body: %w", err)))
+		return false
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, reader, maxBytes)
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.Header.Del("Content-Length")
+	c.Request.ContentLength = -1
+	return true
+}
+
+// negotiateEncoding returns the content coding of the response the
+// Accept-Encoding header prefers, empty when it accepts neither
+func negotiateEncoding(header string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[coding] = quality
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, coding := range []string{encodingBrotli, encodingGzip} {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
+
+// encoder is a compressing writer
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter buffers the response until it holds minSize bytes, then
+// compresses it from there on. A shorter response, a response of another
+// media type or one flushed early is written as it is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if !w.compressibleType() || len(w.buf) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// routes lifting the server deadlines, such as streams and batch uploads,
+// still reach the connection
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends what was written so far; a stream flushed before it holds
+// minSize bytes is never compressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish writes a buffered response and ends the compressed stream
+func (w *compressWriter) finish() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
+
+// decide compresses the response when allowed to and worth it, and writes
+// what was buffered
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	if w.compressibleType() {
+		// Responses differ per accepted encoding, so shared caches must key
+		// on it
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if compress && len(w.buf) >= w.minSize && w.compressibleResponse() {
+			w.Header().Set("Content-Encoding", w.encoding)
+			w.Header().Del("Content-Length")
+			w.encoder = newEncoder(w.encoding, w.ResponseWriter)
+		}
+	}
+
+	data := w.buf
+	w.buf = nil
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := w.write(data)
+	return err
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) compressibleType() bool {
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	return err == nil && compressibleTypes[mediaType]
+}
+
+// compressibleResponse reports whether the response may still be encoded:
+// its headers are not sent, it has a body, and it is neither encoded nor a
+// byte range already
+func (w *compressWriter) compressibleResponse() bool {
+	status := w.Status()
+	return !w.ResponseWriter.Written() &&
+		status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		w.Header().Get("Content-Encoding") == "" && w.Header().Get("Content-Range") == ""
+}
+
+func newEncoder(encoding string, w io.Writer) encoder {
+	if encoding == encodingBrotli {
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	}
+	return gzip.NewWriter(w)
+}
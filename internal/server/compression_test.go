@@ -0,0 +1,260 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: ""},
+		{header: "identity", expected: ""},
+		{header: "gzip", expected: "gzip"},
+		{header: "gzip, deflate, br", expected: "br"},
+		{header: "br;q=0.5, gzip", expected: "gzip"},
+		{header: "br;q=0, gzip;q=0", expected: ""},
+		{header: "*", expected: "br"},
+		{header: "br;q=0, *", expected: "gzip"},
+		{header: "GZIP ; q=0.8", expected: "gzip"},
+		{header: "br;q=high, gzip;q=0.1", expected: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, negotiateEncoding(tt.header))
+		})
+	}
+}
+
+func newCompressionRouter(config HTTPConfig, handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(compression(config))
+	router.POST("/test", handlers...)
+	return router
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	var reader io.Reader
+	switch encoding {
+	case encodingGzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		reader = gzipReader
+	case encodingBrotli:
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		reader = bytes.NewReader(body)
+	}
+
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestCompression_Response(t *testing.T) {
+	config := HTTPConfig{CompressionMinSize: 64, MaxDecompressedBodyBytes: 1 << 20}
+	large := `{"message":"` + strings.Repeat("notification accepted ", 10) + `"}`
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		handler          gin.HandlerFunc
+		expectedEncoding string
+		expectedVary     bool
+		expectedBody     string
+	}{
+		{
+			name:             "compresses with br when preferred",
+			acceptEncoding:   "gzip, br",
+			handler:          func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(large)) },
+			expectedEncoding: encodingBrotli,
+			expectedVary:     true,
+			expectedBody:     large,
+		},
+		{
+			name:             "compresses with gzip",
+			acceptEncoding:   "gzip",
+			handler:          func(c *gin.Context) { c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(large)) },
+			expectedEncoding: encodingGzip,
+			expectedVary:     true,
+			expectedBody:     large,
+		},
+		{
+			name:           "leaves out a response below the minimum size",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(`{"message":"ok"}`)) },
+			expectedVary:   true,
+			expectedBody:   `{"message":"ok"}`,
+		},
+		{
+			name:           "leaves out other media types",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(large)) },
+			expectedBody:   large,
+		},
+		{
+			name:           "leaves out callers accepting neither encoding",
+			acceptEncoding: "deflate",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(large)) },
+			expectedBody:   large,
+		},
+		{
+			name:           "leaves out a stream flushed early",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/plain")
+				c.Status(http.StatusOK)
+				_, _ = c.Writer.WriteString("event 1\n")
+				c.Writer.Flush()
+				_, _ = c.Writer.WriteString(large)
+			},
+			expectedVary: true,
+			expectedBody: "event 1\n" + large,
+		},
+		{
+			name:             "compresses a response written in parts",
+			acceptEncoding:   "gzip",
+			expectedEncoding: encodingGzip,
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/csv")
+				c.Status(http.StatusOK)
+				for range 10 {
+					_, _ = c.Writer.WriteString("1,sent,01JB8Z6,\n")
+				}
+			},
+			expectedVary: true,
+			expectedBody: strings.Repeat("1,sent,01JB8Z6,\n", 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newCompressionRouter(config, tt.handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/test", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.expectedVary, w.Header().Get("Vary") == "Accept-Encoding")
+			assert.Equal(t, tt.expectedBody, decode(t, tt.expectedEncoding, w.Body.Bytes()))
+		})
+	}
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestCompression_Request(t *testing.T) {
+	config := HTTPConfig{CompressionMinSize: 64, MaxDecompressedBodyBytes: 32}
+
+	tests := []struct {
+		name            string
+		contentEncoding string
+		body            []byte
+		expectedStatus  int
+		expectedBody    string
+	}{
+		{name: "passes a plain body", body: []byte(`{"title":"hi"}`), expectedStatus: http.StatusOK, expectedBody: `{"title":"hi"}`},
+		{
+			name:            "decompresses a gzip body",
+			contentEncoding: "gzip",
+			body:            gzipped(t, `{"title":"hi"}`),
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"title":"hi"}`,
+		},
+		{
+			name:            "bounds the decompressed body",
+			contentEncoding: "gzip",
+			body:            gzipped(t, strings.Repeat("a", 64)),
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{name: "rejects an invalid gzip body", contentEncoding: "gzip", body: []byte("not gzip"), expectedStatus: http.StatusBadRequest},
+		{name: "rejects another encoding", contentEncoding: "zstd", body: []byte("data"), expectedStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newCompressionRouter(config, func(c *gin.Context) {
+				assert.Empty(t, c.GetHeader("Content-Encoding"))
+				body, err := io.ReadAll(c.Request.Body)
+				if err != nil {
+					c.Status(http.StatusRequestEntityTooLarge)
+					return
+				}
+				c.String(http.StatusOK, string(body))
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewReader(tt.body))
+			if tt.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", tt.contentEncoding)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// deadlineRecorder records the deadlines http.ResponseController sets
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	readDeadlines  int
+	writeDeadlines int
+}
+
+func (r *deadlineRecorder) SetReadDeadline(time.Time) error {
+	r.readDeadlines++
+	return nil
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(time.Time) error {
+	r.writeDeadlines++
+	return nil
+}
+
+func TestCompression_Streaming(t *testing.T) {
+	router := newCompressionRouter(HTTPConfig{CompressionMinSize: 64, MaxDecompressedBodyBytes: 1 << 20}, streaming, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: hello\n\n")
+		c.Writer.Flush()
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 1, w.readDeadlines)
+	assert.Equal(t, 1, w.writeDeadlines)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "data: hello\n\n", w.Body.String())
+}
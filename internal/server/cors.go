@@ -0,0 +1,133 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+var errOriginNotAllowed = errors.New("origin not allowed")
+
+// allowedOrigins reports whether an origin is listed in
+// HTTP_CORS_ALLOWED_ORIGINS, or any origin is with a wildcard
+func allowedOrigins(config HTTPConfig) func(origin string) bool {
+	var (
+		anyOrigin bool
+		origins   = make(map[string]bool, len(config.CORSAllowedOrigins))
+	)
+	for _, origin := range config.CORSAllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
+	return func(origin string) bool {
+		return anyOrigin || origins[strings.ToLower(origin)]
+	}
+}
+
+// cors answers preflight requests and adds the CORS headers for origins
+// listed in HTTP_CORS_ALLOWED_ORIGINS, so the admin UI served from another
+// origin can call the API; without allowed origins it does nothing
+func cors(config HTTPConfig) gin.HandlerFunc {
+	allowed := allowedOrigins(config)
+	enabled := len(config.CORSAllowedOrigins) > 0
+
+	methods := strings.Join(config.CORSAllowedMethods, ", ")
+	headers := strings.Join(config.CORSAllowedHeaders, ", ")
+	exposed := strings.Join(config.CORSExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.CORSMaxAge / time.Second))
+
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		// Responses differ per origin, so shared caches must key on it
+		c.Writer.Header().Add("Vary", "Origin")
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if !preflight {
+			if exposed != "" {
+				c.Header("Access-Control-Expose-Headers", exposed)
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", methods)
+		c.Header("Access-Control-Allow-Headers", headers)
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// websocketOrigin refuses WebSocket handshakes from pages of an origin other
+// than the API itself or those listed in HTTP_CORS_ALLOWED_ORIGINS. Browsers
+// do not apply CORS to WebSockets, so the server has to check the origin.
+// Handshakes without an Origin, from clients other than browsers, are let
+// through
+func websocketOrigin(config HTTPConfig) gin.HandlerFunc {
+	allowed := allowedOrigins(config)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || allowed(origin) {
+			c.Next()
+			return
+		}
+
+		if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, c.Request.Host) {
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, handler.GetRequestError(errOriginNotAllowed))
+	}
+}
+
+// securityHeaders sets the standard hardening headers on every response.
+// Responses are JSON only, so nothing may be framed, sniffed or loaded
+func securityHeaders(config HTTPConfig) gin.HandlerFunc {
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(config.HSTSMaxAge/time.Second)) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
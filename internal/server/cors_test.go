@@ -0,0 +1,222 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestConfig(origins ...string) HTTPConfig {
+	return HTTPConfig{
+		CORSAllowedOrigins: origins,
+		CORSAllowedMethods: []string{"GET", "POST"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type"},
+		CORSExposedHeaders: []string{"X-Notification-ID"},
+		CORSMaxAge:         10 * time.Minute,
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             HTTPConfig
+		method             string
+		headers            map[string]string
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+	}{
+		{
+			name:               "disabled without allowed origins",
+			config:             newCORSTestConfig(),
+			method:             http.MethodPost,
+			headers:            map[string]string{"Origin": "https://admin.example.com"},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		{
+			name:               "allows a listed origin",
+			config:             newCORSTestConfig("https://admin.example.com"),
+			method:             http.MethodPost,
+			headers:            map[string]string{"Origin": "https://admin.example.com"},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "https://admin.example.com",
+				"Access-Control-Expose-Headers": "X-Notification-ID",
+				"Vary":                          "Origin",
+			},
+		},
+		{
+			name:               "ignores an unlisted origin",
+			config:             newCORSTestConfig("https://admin.example.com"),
+			method:             http.MethodPost,
+			headers:            map[string]string{"Origin": "https://evil.example.com"},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "answers a preflight",
+			config: newCORSTestConfig("https://admin.example.com"),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://admin.example.com",
+				"Access-Control-Request-Method": "POST",
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://admin.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Authorization, Content-Type",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "rejects a preflight from an unlisted origin",
+			config: newCORSTestConfig("https://admin.example.com"),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.example.com",
+				"Access-Control-Request-Method": "POST",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedHeaders:    map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:               "allows any origin with a wildcard",
+			config:             newCORSTestConfig("*"),
+			method:             http.MethodPost,
+			headers:            map[string]string{"Origin": "https://ui.example.com"},
+			expectedStatusCode: http.StatusOK,
+			expectedHeaders:    map[string]string{"Access-Control-Allow-Origin": "https://ui.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(cors(tt.config))
+			router.POST("/notify", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/notify", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			for key, value := range tt.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(key), key)
+			}
+		})
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             HTTPConfig
+		origin             string
+		expectedStatusCode int
+	}{
+		{
+			name:               "allows a listed origin",
+			config:             newCORSTestConfig("https://admin.example.com"),
+			origin:             "https://Admin.example.com",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "allows the origin of the api itself",
+			config:             newCORSTestConfig(),
+			origin:             "https://api.example.com",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "allows a handshake without an origin",
+			config:             newCORSTestConfig(),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "allows any origin with a wildcard",
+			config:             newCORSTestConfig("*"),
+			origin:             "https://ui.example.com",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects an unlisted origin",
+			config:             newCORSTestConfig("https://admin.example.com"),
+			origin:             "https://evil.example.com",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "rejects other origins without allowed origins",
+			config:             newCORSTestConfig(),
+			origin:             "https://evil.example.com",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/ws", websocketOrigin(tt.config), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "https://api.example.com/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       HTTPConfig
+		expectedHSTS string
+	}{
+		{
+			name:         "omits hsts by default",
+			config:       HTTPConfig{},
+			expectedHSTS: "",
+		},
+		{
+			name:         "sets hsts when configured",
+			config:       HTTPConfig{HSTSMaxAge: 365 * 24 * time.Hour},
+			expectedHSTS: "max-age=31536000; includeSubDomains",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(securityHeaders(tt.config))
+			router.GET("/healthz", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+			assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, tt.expectedHSTS, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
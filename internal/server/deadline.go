@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+)
+
+const (
+	// HeaderRequestDeadline carries the caller's absolute deadline as an RFC 3339 timestamp
+	HeaderRequestDeadline = "X-Request-Deadline"
+	// HeaderGRPCTimeout carries the caller's remaining budget in grpc-timeout format, e.g. "250m"
+	HeaderGRPCTimeout = "Grpc-Timeout"
+)
+
+var errDeadlineExceeded = errors.New("request deadline already exceeded")
+
+// requestDeadline converts the caller's deadline headers into the request
+// context deadline; when both headers are present the earlier deadline wins.
+// The operational routes ignore the headers, so a probe or scrape is never
+// refused for them
+func requestDeadline(clock clock.Clock) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isOperational(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		deadline, ok, err := parseDeadline(c.Request.Header, clock.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, handler.GetRequestError(err))
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if !deadline.After(clock.Now()) {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, handler.GetInternalError(errDeadlineExceeded))
+			return
+		}
+
+		ctx, cancel := context.WithDeadline(c.Request.Context(), deadline)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func parseDeadline(header http.Header, now time.Time) (time.Time, bool, error) {
+	var (
+		deadline time.Time
+		found    bool
+	)
+
+	if value := header.Get(HeaderRequestDeadline); value != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s header: %w", HeaderRequestDeadline, err)
+		}
+		deadline, found = parsed, true
+	}
+
+	if value := header.Get(HeaderGRPCTimeout); value != "" {
+		timeout, err := parseGRPCTimeout(value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s header: %w", HeaderGRPCTimeout, err)
+		}
+		if candidate := now.Add(timeout); !found || candidate.Before(deadline) {
+			deadline, found = candidate, true
+		}
+	}
+
+	return deadline, found, nil
+}
+
+// parseGRPCTimeout parses the grpc-timeout wire format: at most 8 digits
+// followed by a single unit (H, M, S, m, u, n)
+func parseGRPCTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("malformed timeout '%s'", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("unknown timeout unit in '%s'", value)
+	}
+
+	amount, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed timeout '%s'", value)
+	}
+	if amount > uint64(math.MaxInt64/unit) {
+		return time.Duration(math.MaxInt64), nil
+	}
+
+	return time.Duration(amount) * unit, nil
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{name: "hours", value: "2H", expected: 2 * time.Hour},
+		{name: "minutes", value: "3M", expected: 3 * time.Minute},
+		{name: "seconds", value: "5S", expected: 5 * time.Second},
+		{name: "milliseconds", value: "250m", expected: 250 * time.Millisecond},
+		{name: "microseconds", value: "100u", expected: 100 * time.Microsecond},
+		{name: "nanoseconds", value: "10n", expected: 10 * time.Nanosecond},
+		{name: "saturates on overflow", value: "99999999H", expected: time.Duration(1<<63 - 1)},
+		{name: "missing unit", value: "100", expectError: true},
+		{name: "unknown unit", value: "100x", expectError: true},
+		{name: "missing amount", value: "m", expectError: true},
+		{name: "too many digits", value: "123456789m", expectError: true},
+		{name: "negative amount", value: "-5S", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, err := parseGRPCTimeout(tt.value)
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, timeout)
+		})
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		expected      time.Time
+		expectedFound bool
+		expectError   bool
+	}{
+		{
+			name:          "no headers",
+			headers:       map[string]string{},
+			expectedFound: false,
+		},
+		{
+			name:          "absolute deadline",
+			headers:       map[string]string{HeaderRequestDeadline: "2025-06-01T12:00:02Z"},
+			expected:      now.Add(2 * time.Second),
+			expectedFound: true,
+		},
+		{
+			name:          "grpc timeout",
+			headers:       map[string]string{HeaderGRPCTimeout: "500m"},
+			expected:      now.Add(500 * time.Millisecond),
+			expectedFound: true,
+		},
+		{
+			name: "earlier of both headers wins",
+			headers: map[string]string{
+				HeaderRequestDeadline: "2025-06-01T12:00:02Z",
+				HeaderGRPCTimeout:     "1S",
+			},
+			expected:      now.Add(time.Second),
+			expectedFound: true,
+		},
+		{
+			name:        "malformed absolute deadline",
+			headers:     map[string]string{HeaderRequestDeadline: "tomorrow"},
+			expectError: true,
+		},
+		{
+			name:        "malformed grpc timeout",
+			headers:     map[string]string{HeaderGRPCTimeout: "soon"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+
+			deadline, found, err := parseDeadline(header, now)
+
+			if tt.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFound, found)
+			if tt.expectedFound {
+				assert.True(t, tt.expected.Equal(deadline), "expected %s, got %s", tt.expected, deadline)
+			}
+		})
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+
+	tests := []struct {
+		name             string
+		path             string
+		headers          map[string]string
+		expectedStatus   int
+		expectedDeadline bool
+	}{
+		{
+			name:           "no deadline header leaves context untouched",
+			headers:        map[string]string{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "grpc timeout sets context deadline",
+			headers:          map[string]string{HeaderGRPCTimeout: "5S"},
+			expectedStatus:   http.StatusOK,
+			expectedDeadline: true,
+		},
+		{
+			name:           "malformed header is rejected",
+			headers:        map[string]string{HeaderGRPCTimeout: "5X"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "expired deadline is rejected",
+			headers:        map[string]string{HeaderRequestDeadline: now.Add(-time.Second).Format(time.RFC3339Nano)},
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "health check ignores the deadline headers",
+			path:           "/healthz",
+			headers:        map[string]string{HeaderRequestDeadline: now.Add(-time.Second).Format(time.RFC3339Nano)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "metrics ignore the deadline headers",
+			path:           "/metrics",
+			headers:        map[string]string{HeaderGRPCTimeout: "5S"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			clock := mockclock.NewMockClock(ctrl)
+			clock.EXPECT().Now().Return(now).AnyTimes()
+
+			var (
+				called      bool
+				hasDeadline bool
+				deadline    time.Time
+			)
+			router := gin.New()
+			router.Use(requestDeadline(clock))
+			path := tt.path
+			if path == "" {
+				path = "/test"
+			}
+			router.GET(path, func(c *gin.Context) {
+				called = true
+				deadline, hasDeadline = c.Request.Context().Deadline()
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, called)
+			assert.Equal(t, tt.expectedDeadline, hasDeadline)
+			if tt.expectedDeadline {
+				assert.True(t, now.Add(5*time.Second).Equal(deadline))
+			}
+		})
+	}
+}
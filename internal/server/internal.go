@@ -0,0 +1,73 @@
+package server
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/buildinfo"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Paths of the operational routes
+const (
+	healthPath  = "/healthz"
+	metricsPath = "/metrics"
+	versionPath = "/version"
+)
+
+// isOperational tells whether path is one of the operational routes, which
+// probes and scrapers call without a deadline of their own
+func isOperational(path string) bool {
+	return path == healthPath || path == metricsPath || path == versionPath
+}
+
+// operationalRoutes registers the health check, metrics and build version;
+// they move to the internal port when HTTP_INTERNAL_PORT is set
+func operationalRoutes(router gin.IRoutes) {
+	router.GET(healthPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "server is running",
+		})
+	})
+	router.GET(metricsPath, gin.WrapH(promhttp.Handler()))
+	router.GET(versionPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, buildinfo.Get())
+	})
+}
+
+// debugRoutes registers the pprof profiles and expvar variables, which
+// are only ever served on the internal port
+func debugRoutes(router gin.IRoutes) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	router.Any("/debug/pprof/*profile", gin.WrapH(mux))
+	router.GET("/debug/vars", gin.WrapH(mux))
+}
+
+// newInternalServer serves the operational and debug routes on
+// InternalPort, for scrapers and probes inside the cluster only
+func newInternalServer(config HTTPConfig) *http.Server {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	operationalRoutes(router)
+	debugRoutes(router)
+
+	return &http.Server{
+		Addr:              config.InternalPort,
+		Handler:           router,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		// No write timeout: CPU profiles and traces are written for as
+		// long as the caller asks them to run
+		IdleTimeout:    config.IdleTimeout,
+		MaxHeaderBytes: config.MaxHeaderBytes,
+	}
+}
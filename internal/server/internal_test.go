@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func TestNewInternalServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := newInternalServer(HTTPConfig{InternalPort: ":9090"})
+	assert.Equal(t, ":9090", srv.Addr)
+
+	tests := []struct {
+		name     string
+		target   string
+		expected string
+	}{
+		{name: "serves the health check", target: "/healthz", expected: "server is running"},
+		{name: "serves metrics", target: "/metrics", expected: "# HELP"},
+		{name: "serves the build version", target: "/version", expected: `"go_version":"go`},
+		{name: "serves the pprof index", target: "/debug/pprof/", expected: "goroutine"},
+		{name: "serves a named profile", target: "/debug/pprof/goroutine?debug=1", expected: "goroutine profile"},
+		{name: "serves expvar variables", target: "/debug/vars", expected: "memstats"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expected)
+		})
+	}
+}
+
+func TestHTTPServer_setupRoutes_internalPort(t *testing.T) {
+	httpMetrics, err := metrics.NewHTTPServerCollector(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		internalPort   string
+		expectedRouted bool
+	}{
+		{name: "serves health and metrics on the api without an internal port", expectedRouted: true},
+		{name: "keeps health and metrics off the api with an internal port", internalPort: ":9090"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			h := &HTTPServer{
+				router:      gin.New(),
+				httpMetrics: httpMetrics,
+				clock:       clock.NewRealClock(),
+				ids:         idgen.NewUUIDv7Generator(),
+			}
+			require.NoError(t, h.setupRoutes(HTTPConfig{InternalPort: tt.internalPort}))
+
+			for _, target := range []string{"/healthz", "/metrics", "/version", "/debug/pprof/"} {
+				w := httptest.NewRecorder()
+				h.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+				routed := w.Code == http.StatusOK
+				assert.Equal(t, tt.expectedRouted && target != "/debug/pprof/", routed, target)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixPrefix marks a listen address as the path of a Unix domain socket
+const unixPrefix = "unix:"
+
+// addresses returns every address the server listens on: Port first, then
+// Listeners
+func (c HTTPConfig) addresses() []string {
+	return append([]string{c.Port}, c.Listeners...)
+}
+
+func (c HTTPConfig) validateListeners() error {
+	seen := make(map[string]bool)
+	for _, addr := range c.addresses() {
+		if addr == "" || addr == unixPrefix {
+			return errors.New("listeners: address must not be empty")
+		}
+		if seen[addr] {
+			return fmt.Errorf("listeners: '%s' is listed twice", addr)
+		}
+		seen[addr] = true
+	}
+	if c.InternalPort != "" && seen[c.InternalPort] {
+		return fmt.Errorf("listeners: internal port '%s' is also an API address", c.InternalPort)
+	}
+	return nil
+}
+
+// listen opens a listener on every address of the server. TCP listeners
+// terminate TLS when configured; Unix sockets stay plain, as the proxy in
+// front of them terminates it
+func (h *HTTPServer) listen() ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range h.addresses {
+		ln, err := h.listenOn(addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+func (h *HTTPServer) listenOn(addr string) (net.Listener, error) {
+	path, isUnix := strings.CutPrefix(addr, unixPrefix)
+	if !isUnix {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		if h.srv.TLSConfig != nil {
+			ln = tls.NewListener(ln, h.srv.TLSConfig)
+		}
+		return ln, nil
+	}
+
+	// A socket left behind by a process that did not shut down cleanly
+	// would make the listen fail; anything else at the path is kept
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket %s: %w", path, err)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, h.unixSocketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("chmod socket %s: %w", path, err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPConfig_validateListeners(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      HTTPConfig
+		expectError bool
+	}{
+		{name: "accepts the port alone", config: HTTPConfig{Port: ":8080"}},
+		{name: "accepts extra addresses and sockets", config: HTTPConfig{Port: ":8080", Listeners: []string{"127.0.0.1:8081", "unix:/run/notify.sock"}}},
+		{name: "rejects an empty socket path", config: HTTPConfig{Port: ":8080", Listeners: []string{"unix:"}}, expectError: true},
+		{name: "rejects an address listed twice", config: HTTPConfig{Port: ":8080", Listeners: []string{":8080"}}, expectError: true},
+		{name: "accepts a separate internal port", config: HTTPConfig{Port: ":8080", InternalPort: ":9090"}},
+		{name: "rejects an internal port serving the api", config: HTTPConfig{Port: ":8080", InternalPort: ":8080"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validateListeners()
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPServer_listen(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "notify.sock")
+
+	// A socket left behind by an earlier process
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})}
+	httpServer := &HTTPServer{
+		srv:            srv,
+		addresses:      []string{"127.0.0.1:0", "unix:" + socket},
+		unixSocketMode: 0o600,
+	}
+
+	listeners, err := httpServer.listen()
+	require.NoError(t, err)
+	require.Len(t, listeners, 2)
+	for _, ln := range listeners {
+		go srv.Serve(ln)
+	}
+	defer srv.Close()
+
+	t.Run("serves TCP", func(t *testing.T) {
+		resp, err := http.Get("http://" + listeners[0].Addr().String())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+	})
+
+	t.Run("serves the Unix socket with its mode", func(t *testing.T) {
+		info, err := os.Stat(socket)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}}
+		resp, err := client.Get("http://notify/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+	})
+
+	t.Run("fails on a path that is not a socket, keeping the file", func(t *testing.T) {
+		notSocket := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(notSocket, nil, 0o600))
+
+		failing := &HTTPServer{
+			srv:       &http.Server{},
+			addresses: []string{"127.0.0.1:0", "unix:" + notSocket},
+		}
+		_, err := failing.listen()
+		assert.Error(t, err)
+
+		_, err = os.Stat(notSocket)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+)
+
+var errOverloaded = errors.New("server overloaded, retry later")
+
+// LoadSheddingConfig bounds concurrent notify requests by a limit that
+// grows while requests are fast and shrinks once they slow down, so a slow
+// downstream sheds load instead of queueing it until every request times out
+type LoadSheddingConfig struct {
+	Enabled      bool `envconfig:"LOAD_SHEDDING_ENABLED" default:"false"`
+	InitialLimit int  `envconfig:"LOAD_SHEDDING_INITIAL_LIMIT" default:"100"`
+	MinLimit     int  `envconfig:"LOAD_SHEDDING_MIN_LIMIT" default:"10"`
+	MaxLimit     int  `envconfig:"LOAD_SHEDDING_MAX_LIMIT" default:"1000"`
+	// LatencyThreshold is the latency above which a request is taken as a
+	// sign of overload and shrinks the limit by BackoffRatio
+	LatencyThreshold time.Duration `envconfig:"LOAD_SHEDDING_LATENCY_THRESHOLD" default:"2s"`
+	BackoffRatio     float64       `envconfig:"LOAD_SHEDDING_BACKOFF_RATIO" default:"0.9"`
+	// RetryAfter is sent to shed callers as the Retry-After header
+	RetryAfter time.Duration `envconfig:"LOAD_SHEDDING_RETRY_AFTER" default:"1s"`
+}
+
+func (c LoadSheddingConfig) validate() error {
+	if c.MinLimit < 1 || c.InitialLimit < c.MinLimit || c.MaxLimit < c.InitialLimit {
+		return fmt.Errorf("load shedding limits: need 1 <= min (%d) <= initial (%d) <= max (%d)",
+			c.MinLimit, c.InitialLimit, c.MaxLimit)
+	}
+	if c.BackoffRatio <= 0 || c.BackoffRatio >= 1 {
+		return fmt.Errorf("load shedding backoff ratio: %g must be between 0 and 1", c.BackoffRatio)
+	}
+	if c.LatencyThreshold <= 0 {
+		return errors.New("load shedding latency threshold must be positive")
+	}
+	return nil
+}
+
+// aimdLimiter is an additive increase, multiplicative decrease concurrency
+// limit: every fast request while at least half the limit is in use raises
+// it by one, every slow request multiplies it by the backoff ratio
+type aimdLimiter struct {
+	config LoadSheddingConfig
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+}
+
+func newAIMDLimiter(config LoadSheddingConfig) *aimdLimiter {
+	return &aimdLimiter{
+		config: config,
+		limit:  float64(config.InitialLimit),
+	}
+}
+
+// acquire takes a slot, or reports false when the limit is reached
+func (l *aimdLimiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= int(l.limit) {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// release frees the slot of a request that took latency and returns the
+// limit it leads to
+func (l *aimdLimiter) release(latency time.Duration) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Utilization is judged with the request still counted, as it was
+	// while running
+	utilized := l.inFlight*2 >= int(l.limit)
+	l.inFlight--
+
+	switch {
+	case latency > l.config.LatencyThreshold:
+		l.limit = max(float64(l.config.MinLimit), l.limit*l.config.BackoffRatio)
+	case utilized:
+		l.limit = min(float64(l.config.MaxLimit), l.limit+1)
+	}
+	return int(l.limit)
+}
+
+// loadShedding rejects requests above the adaptive limit with 503 and a
+// Retry-After header
+func loadShedding(config LoadSheddingConfig, clock clock.Clock, metricsCollector *metrics.HTTPServerCollector) gin.HandlerFunc {
+	limiter := newAIMDLimiter(config)
+	retryAfter := strconv.Itoa(int(math.Ceil(config.RetryAfter.Seconds())))
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		if !limiter.acquire() {
+			metricsCollector.RecordShed(ctx, c.FullPath())
+			c.Header("Retry-After", retryAfter)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, handler.GetInternalError(errOverloaded))
+			return
+		}
+
+		start := clock.Now()
+		defer func() {
+			limit := limiter.release(clock.Since(start))
+			metricsCollector.RecordConcurrencyLimit(ctx, c.FullPath(), limit)
+		}()
+
+		c.Next()
+	}
+}
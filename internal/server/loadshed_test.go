@@ -0,0 +1,170 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+)
+
+// testLoadSheddingConfig matches the documented defaults
+var testLoadSheddingConfig = LoadSheddingConfig{
+	Enabled:          true,
+	InitialLimit:     100,
+	MinLimit:         10,
+	MaxLimit:         1000,
+	LatencyThreshold: 2 * time.Second,
+	BackoffRatio:     0.9,
+	RetryAfter:       time.Second,
+}
+
+func TestLoadSheddingConfig_validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*LoadSheddingConfig)
+		expectError bool
+	}{
+		{name: "accepts the defaults", modify: func(*LoadSheddingConfig) {}},
+		{name: "rejects a minimum below one", modify: func(c *LoadSheddingConfig) { c.MinLimit = 0 }, expectError: true},
+		{name: "rejects an initial limit below the minimum", modify: func(c *LoadSheddingConfig) { c.InitialLimit = 5 }, expectError: true},
+		{name: "rejects a maximum below the initial limit", modify: func(c *LoadSheddingConfig) { c.MaxLimit = 50 }, expectError: true},
+		{name: "rejects a backoff ratio of one", modify: func(c *LoadSheddingConfig) { c.BackoffRatio = 1 }, expectError: true},
+		{name: "rejects a latency threshold of zero", modify: func(c *LoadSheddingConfig) { c.LatencyThreshold = 0 }, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testLoadSheddingConfig
+			tt.modify(&config)
+
+			err := config.validate()
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAIMDLimiter(t *testing.T) {
+	config := testLoadSheddingConfig
+	config.InitialLimit = 10
+	config.MinLimit = 8
+	config.MaxLimit = 11
+
+	t.Run("refuses requests above the limit", func(t *testing.T) {
+		limiter := newAIMDLimiter(config)
+		for range 10 {
+			require.True(t, limiter.acquire())
+		}
+		assert.False(t, limiter.acquire())
+
+		limiter.release(time.Millisecond)
+		assert.True(t, limiter.acquire())
+	})
+
+	t.Run("raises the limit on fast requests while utilized, up to the maximum", func(t *testing.T) {
+		limiter := newAIMDLimiter(config)
+		for range 6 {
+			require.True(t, limiter.acquire())
+		}
+
+		assert.Equal(t, 11, limiter.release(time.Millisecond))
+		assert.Equal(t, 11, limiter.release(time.Millisecond))
+	})
+
+	t.Run("keeps the limit on fast requests while underused", func(t *testing.T) {
+		limiter := newAIMDLimiter(config)
+		require.True(t, limiter.acquire())
+
+		assert.Equal(t, 10, limiter.release(time.Millisecond))
+	})
+
+	t.Run("shrinks the limit on slow requests, down to the minimum", func(t *testing.T) {
+		limiter := newAIMDLimiter(config)
+		for range 3 {
+			require.True(t, limiter.acquire())
+		}
+
+		assert.Equal(t, 9, limiter.release(3*time.Second))
+		assert.Equal(t, 8, limiter.release(3*time.Second))
+		assert.Equal(t, 8, limiter.release(3*time.Second))
+	})
+}
+
+func TestLoadShedding(t *testing.T) {
+	httpMetrics, err := metrics.NewHTTPServerCollector(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+
+	t.Run("sheds requests above the limit with Retry-After", func(t *testing.T) {
+		config := testLoadSheddingConfig
+		config.InitialLimit = 1
+		config.MinLimit = 1
+		config.RetryAfter = 1500 * time.Millisecond
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/notify", loadShedding(config, clock.NewRealClock(), httpMetrics), func(c *gin.Context) {
+			close(started)
+			<-release
+			c.Status(http.StatusOK)
+		})
+
+		done := make(chan int)
+		go func() {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notify", nil))
+			done <- w.Code
+		}()
+		<-started
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notify", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error_code":"E102","message":"server overloaded, retry later"}`, w.Body.String())
+
+		close(release)
+		assert.Equal(t, http.StatusOK, <-done)
+	})
+
+	t.Run("times requests with the clock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		clk := mockclock.NewMockClock(ctrl)
+		clk.EXPECT().Now().Return(start)
+		clk.EXPECT().Since(start).Return(3 * time.Second)
+
+		config := testLoadSheddingConfig
+		config.InitialLimit = 1
+		config.MinLimit = 1
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/notify", loadShedding(config, clk, httpMetrics), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/notify", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/koungkub/fw-challenge-notification-service/api"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	openAPIResource = "openapi.json"
+	// swaggerUIAssets hosts the Swagger UI bundle, so the binary does not
+	// have to ship it
+	swaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+)
+
+var errRequestSchema = errors.New("request does not match the api schema")
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Notification Service API</title>
+<link rel="stylesheet" href="` + swaggerUIAssets + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="` + swaggerUIAssets + `/swagger-ui-bundle.js"></script>
+<script src="/docs/init.js"></script>
+</body>
+</html>
+`
+
+const swaggerUIInit = `window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+`
+
+// swaggerUIPolicy relaxes the default policy just enough for the UI to load
+// its bundle and fetch the document
+const swaggerUIPolicy = "default-src 'none'; script-src 'self' https://unpkg.com; style-src https://unpkg.com; " +
+	"img-src 'self' data: https://unpkg.com; connect-src 'self'; frame-ancestors 'none'"
+
+func openAPIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", api.OpenAPI)
+}
+
+func swaggerUIHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", swaggerUIPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+func swaggerUIInitHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/javascript; charset=utf-8", []byte(swaggerUIInit))
+}
+
+// requestValidator checks JSON request bodies against the schemas of the
+// OpenAPI document, keyed by method and gin route
+type requestValidator struct {
+	schemas map[string]*jsonschema.Schema
+	// optional holds the routes whose body may be left empty
+	optional map[string]bool
+	// maxBodySize caps the bodies read for validation, as the handlers cap
+	// the JSON bodies they bind
+	maxBodySize int64
+}
+
+func newRequestValidator(spec []byte, maxBodySize int64) (*requestValidator, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(spec))
+	if err != nil {
+		return nil, fmt.Errorf("openapi document: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	compiler.AssertContent()
+	if err := compiler.AddResource(openAPIResource, doc); err != nil {
+		return nil, fmt.Errorf("openapi document: %w", err)
+	}
+
+	paths, _ := doc.(map[string]any)["paths"].(map[string]any)
+	validator := &requestValidator{
+		schemas:     map[string]*jsonschema.Schema{},
+		optional:    map[string]bool{},
+		maxBodySize: maxBodySize,
+	}
+	for path, item := range paths {
+		operations, _ := item.(map[string]any)
+		for method, operation := range operations {
+			body, _ := operation.(map[string]any)["requestBody"].(map[string]any)
+			content, _ := body["content"].(map[string]any)
+			if _, ok := content["application/json"]; !ok {
+				continue
+			}
+
+			location := fmt.Sprintf("%s#/paths/%s/%s/requestBody/content/application~1json/schema",
+				openAPIResource, escapePointer(path), method)
+			schema, err := compiler.Compile(location)
+			if err != nil {
+				return nil, fmt.Errorf("openapi document: %s %s: %w", method, path, err)
+			}
+			route := strings.ToUpper(method) + " " + ginRoute(path)
+			validator.schemas[route] = schema
+			if required, _ := body["required"].(bool); !required {
+				validator.optional[route] = true
+			}
+		}
+	}
+
+	return validator, nil
+}
+
+// Middleware rejects bodies violating the schema of their route with a 422
+// listing every violation, and bodies over the size limit with a 413;
+// routes without a body schema, and empty optional bodies, pass through
+func (v *requestValidator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Protobuf bodies are checked by the binding rules they share with
+		// JSON bodies once the handler translated them
+		route := c.Request.Method + " " + c.FullPath()
+		schema, ok := v.schemas[route]
+		if !ok || c.ContentType() == binding.MIMEPROTOBUF {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, v.maxBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, handler.GetRequestError(err))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, handler.GetRequestError(err))
+			return
+		}
+		// Handlers decode the body again
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if v.optional[route] && len(bytes.TrimSpace(body)) == 0 {
+			c.Next()
+			return
+		}
+
+		instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, handler.GetRequestError(err))
+			return
+		}
+
+		if err := schema.Validate(instance); err != nil {
+			var validationErr *jsonschema.ValidationError
+			if !errors.As(err, &validationErr) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, handler.GetInternalError(err))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+				handler.GetValidationError(errRequestSchema, schemaViolations(validationErr)))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// schemaViolations flattens the error tree into the failed keywords, plus
+// the anyOf and oneOf alternatives giving the context of their causes
+func schemaViolations(err *jsonschema.ValidationError) []handler.ErrorDetail {
+	var details []handler.ErrorDetail
+
+	var walk func(*jsonschema.ValidationError)
+	walk = func(err *jsonschema.ValidationError) {
+		_, alternative := err.ErrorKind.(*kind.AnyOf)
+		if _, ok := err.ErrorKind.(*kind.OneOf); ok {
+			alternative = true
+		}
+
+		if alternative || len(err.Causes) == 0 {
+			details = append(details, handler.ErrorDetail{
+				Location: instanceLocation(err.InstanceLocation),
+				Message:  err.ErrorKind.LocalizedString(violationPrinter),
+			})
+		}
+		for _, cause := range err.Causes {
+			walk(cause)
+		}
+	}
+	walk(err)
+
+	sort.SliceStable(details, func(i, j int) bool {
+		return details[i].Location < details[j].Location
+	})
+	return details
+}
+
+var violationPrinter = message.NewPrinter(language.English)
+
+// instanceLocation renders the path of the invalid value as a JSON pointer
+func instanceLocation(tokens []string) string {
+	var location strings.Builder
+	for _, token := range tokens {
+		location.WriteString("/" + escapePointer(token))
+	}
+	return location.String()
+}
+
+// ginRoute turns an OpenAPI path template into the gin route, e.g.
+// /recipient/{recipient} into /recipient/:recipient
+func ginRoute(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
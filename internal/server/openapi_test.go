@@ -0,0 +1,239 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/api"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// TestOpenAPI_DocumentsEveryRoute keeps the document in step with the
+// router; a new route fails here until it is described
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	httpMetrics, err := metrics.NewHTTPServerCollector(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	h := &HTTPServer{
+		router:      gin.New(),
+		httpMetrics: httpMetrics,
+		clock:       clock.NewRealClock(),
+		ids:         idgen.NewUUIDv7Generator(),
+	}
+	require.NoError(t, h.setupRoutes(HTTPConfig{OpenAPIValidation: true}))
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(api.OpenAPI, &spec))
+
+	var documented []string
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+ginRoute(path))
+		}
+	}
+
+	var routed []string
+	for _, route := range h.router.Routes() {
+		// The document and its viewer describe the api, not themselves
+		if route.Path == "/openapi.json" || strings.HasPrefix(route.Path, "/docs") {
+			continue
+		}
+		routed = append(routed, route.Method+" "+route.Path)
+	}
+
+	sort.Strings(documented)
+	sort.Strings(routed)
+	assert.Equal(t, routed, documented)
+}
+
+func TestRequestValidator_Middleware(t *testing.T) {
+	validator, err := newRequestValidator(api.OpenAPI, 1<<20)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		// version of the notify route, v1.0 when empty
+		version            string
+		body               string
+		expectedStatusCode int
+		expectedDetails    []handler.ErrorDetail
+	}{
+		{
+			name:               "passes a valid body on to the handler",
+			body:               `{"to":"buyer@example.com","title":"Order shipped","message":"On its way","priority":"high"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "passes a translated body on to the handler",
+			body:               `{"to":"buyer@example.com","title_key":"order.shipped","message_key":"order.shipped.body"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects malformed json",
+			body:               `{"to":`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "lists every violation",
+			body:               `{"to":"","title":"Order shipped","message":"On its way","priority":"urgent","attachments":[{"url":"not a url"}]}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedDetails: []handler.ErrorDetail{
+				{Location: "/attachments/0", Message: "missing property 'filename'"},
+				{Location: "/attachments/0/url", Message: "'not a url' is not valid uri: relative url"},
+				{Location: "/priority", Message: "value must be one of 'high', 'normal', 'low'"},
+				{Location: "/to", Message: "minLength: got 0, want 1"},
+			},
+		},
+		{
+			name:               "reports an unmet alternative",
+			body:               `{"to":"buyer@example.com","message":"On its way"}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedDetails: []handler.ErrorDetail{
+				{Location: "", Message: "'anyOf' failed"},
+				{Location: "", Message: "missing property 'title'"},
+				{Location: "", Message: "missing property 'title_key'"},
+				{Location: "", Message: "missing property 'template'"},
+			},
+		},
+		{
+			name:               "passes a valid v2.0 body on to the handler",
+			version:            "v2.0",
+			body:               `{"to":"seller@example.com","channels":["PushNotification"],"template":{"title_key":"order.shipped","message_key":"order.shipped.body"}}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rejects a v2.0 body with both content and template",
+			version:            "v2.0",
+			body:               `{"to":"seller@example.com","content":{"title":"Order shipped","message":"On its way"},"template":{"title_key":"order.shipped","message_key":"order.shipped.body"}}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedDetails: []handler.ErrorDetail{
+				{Location: "", Message: "'oneOf' failed, subschemas 0, 1 matched"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := tt.version
+			if version == "" {
+				version = "v1.0"
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/api/"+version+"/recipient/:recipient/notify", validator.Middleware(), func(c *gin.Context) {
+				// The handler reads the body again after validation
+				body, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, tt.body, string(body))
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/"+version+"/recipient/buyer/notify", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatusCode, w.Code, w.Body.String())
+			if tt.expectedDetails == nil {
+				return
+			}
+
+			var response handler.ErrorHandler
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "E101", response.ErrorCode)
+			assert.Equal(t, "request does not match the api schema", response.Message)
+			assert.Equal(t, tt.expectedDetails, response.Details)
+		})
+	}
+}
+
+func TestRequestValidator_Middleware_BodyTooLarge(t *testing.T) {
+	validator, err := newRequestValidator(api.OpenAPI, 64)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1.0/recipient/:recipient/notify", validator.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"to":"buyer@example.com","title":"Order shipped","message":"` + strings.Repeat("a", 64) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1.0/recipient/buyer/notify", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+}
+
+func TestRequestValidator_Middleware_OptionalBody(t *testing.T) {
+	validator, err := newRequestValidator(api.OpenAPI, 1<<20)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		path               string
+		body               string
+		expectedStatusCode int
+	}{
+		{name: "passes an empty optional body", path: "/api/v1.0/notifications/01JB8Z4AAAAAAAAAAAAAAAAAAA/replay", expectedStatusCode: http.StatusOK},
+		{name: "passes a blank optional body", path: "/api/v1.0/notifications/01JB8Z4AAAAAAAAAAAAAAAAAAA/replay", body: " \n", expectedStatusCode: http.StatusOK},
+		{name: "rejects an empty required body", path: "/api/v1.0/recipient/buyer/notify", expectedStatusCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.POST("/api/v1.0/notifications/:id/replay", validator.Middleware(), ok)
+			router.POST("/api/v1.0/recipient/:recipient/notify", validator.Middleware(), ok)
+
+			// Readers of unknown length are sent chunked
+			req := httptest.NewRequest(http.MethodPost, tt.path, io.MultiReader(strings.NewReader(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestRequestValidator_Middleware_Protobuf(t *testing.T) {
+	validator, err := newRequestValidator(api.OpenAPI, 1<<20)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1.0/recipient/:recipient/notify", validator.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Not a JSON document, which the schema would reject
+	req := httptest.NewRequest(http.MethodPost, "/api/v1.0/recipient/buyer/notify", strings.NewReader("\x12\x11buyer@example.com"))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestGinRoute(t *testing.T) {
+	assert.Equal(t, "/api/v1.0/recipient/:recipient/notify", ginRoute("/api/v1.0/recipient/{recipient}/notify"))
+	assert.Equal(t, "/healthz", ginRoute("/healthz"))
+}
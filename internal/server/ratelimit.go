@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+)
+
+var errRateLimited = errors.New("rate limit exceeded, retry later")
+
+// rateLimitResultKey holds the result of the ip bucket for the key bucket
+// to compare against
+const rateLimitResultKey = "ratelimit.result"
+
+// ipRateLimiting takes a token from the bucket of the client IP and rejects
+// the request with 429 when it is empty. It runs before authorization, so
+// a caller guessing API keys is limited too
+func ipRateLimiting(limits *ratelimit.Limits, limiter ratelimit.Limiter, metricsCollector *metrics.HTTPServerCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, ok := takeToken(c, limiter, metricsCollector, "ip", c.ClientIP(), limits.IP())
+		if !ok {
+			return
+		}
+		setRateLimitHeaders(c, result)
+		c.Set(rateLimitResultKey, result)
+
+		c.Next()
+	}
+}
+
+// keyRateLimiting takes a token from the bucket of the authorized API key
+// and rejects the request with 429 when it is empty. Keys sharing a tenant
+// share its quotas but have a bucket each. The RateLimit headers describe
+// the bucket with the fewest tokens left
+func keyRateLimiting(limits *ratelimit.Limits, limiter ratelimit.Limiter, metricsCollector *metrics.HTTPServerCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyID := handler.KeyID(c)
+		if keyID == "" {
+			c.Next()
+			return
+		}
+
+		result, ok := takeToken(c, limiter, metricsCollector, "key", keyID, limits.Key())
+		if !ok {
+			return
+		}
+		if ip, found := c.Get(rateLimitResultKey); !found || result.Remaining < ip.(ratelimit.Result).Remaining {
+			setRateLimitHeaders(c, result)
+		}
+
+		c.Next()
+	}
+}
+
+// takeToken takes a token from the bucket of key in scope, ip or key, and
+// aborts the request when the bucket is empty
+func takeToken(c *gin.Context, limiter ratelimit.Limiter, metricsCollector *metrics.HTTPServerCollector, scope string, key string, limit ratelimit.Limit) (ratelimit.Result, bool) {
+	ctx := c.Request.Context()
+
+	result := limiter.Allow(ctx, scope+":"+key, limit)
+	if !result.Allowed {
+		metricsCollector.RecordRateLimited(ctx, c.FullPath(), scope)
+		setRateLimitHeaders(c, result)
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, handler.GetRequestError(errRateLimited))
+		return result, false
+	}
+	return result, true
+}
+
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
+	mockratelimit "github.com/koungkub/fw-challenge-notification-service/internal/ratelimit/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestKeyRoute serves POST /notify behind the ip bucket, the authorizer
+// and the key bucket; acme-key and acme-ops-key are notify keys of the acme
+// tenant
+func newTestKeyRoute(t *testing.T, config ratelimit.RateLimitConfig, limiter ratelimit.Limiter, next gin.HandlerFunc) *gin.Engine {
+	collector, err := metrics.NewHTTPServerCollector(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+	authorizationCollector, err := metrics.NewAuthorizationCollector(nil)
+	require.NoError(t, err)
+	authorizer, err := handler.NewAuthorizer(handler.AuthorizerParams{
+		Config: handler.HandlerConfig{
+			APIKeys:       map[string]string{"acme-key": handler.RoleNotify, "acme-ops-key": handler.RoleNotify},
+			APIKeyTenants: map[string]string{"acme-key": "acme", "acme-ops-key": "acme"},
+		},
+		MetricsCollector: authorizationCollector,
+	})
+	require.NoError(t, err)
+	authorize := authorizer.Require(handler.RoleNotify)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	limits := ratelimit.NewLimits(config)
+	router.POST("/notify", ipRateLimiting(limits, limiter, collector), func(c *gin.Context) {
+		// Anonymous callers are only limited by ip
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authorize(c)
+	}, keyRateLimiting(limits, limiter, collector), next)
+	return router
+}
+
+func TestRateLimiting(t *testing.T) {
+	config := ratelimit.RateLimitConfig{Enabled: true, IPRPS: 10, IPBurst: 20, KeyRPS: 50, KeyBurst: 100}
+
+	tests := []struct {
+		name            string
+		key             string
+		setupMock       func(limiter *mockratelimit.MockLimiter)
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "limits an anonymous caller by ip",
+			setupMock: func(limiter *mockratelimit.MockLimiter) {
+				limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 20, Remaining: 19, Reset: 100 * time.Millisecond})
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"RateLimit-Limit": "20", "RateLimit-Remaining": "19", "RateLimit-Reset": "1"},
+		},
+		{
+			name: "reports the bucket with the fewest tokens left",
+			key:  "acme-key",
+			setupMock: func(limiter *mockratelimit.MockLimiter) {
+				limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 20, Remaining: 19, Reset: 100 * time.Millisecond})
+				limiter.EXPECT().Allow(gomock.Any(), "key:key-afacab35", config.KeyLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 100, Remaining: 4, Reset: 1900 * time.Millisecond})
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "4", "RateLimit-Reset": "2"},
+		},
+		{
+			name: "keeps the ip bucket when it has fewer tokens left",
+			key:  "acme-key",
+			setupMock: func(limiter *mockratelimit.MockLimiter) {
+				limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 20, Remaining: 2, Reset: 100 * time.Millisecond})
+				limiter.EXPECT().Allow(gomock.Any(), "key:key-afacab35", config.KeyLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 100, Remaining: 99, Reset: 1900 * time.Millisecond})
+			},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"RateLimit-Limit": "20", "RateLimit-Remaining": "2", "RateLimit-Reset": "1"},
+		},
+		{
+			name: "rejects a caller over the ip limit",
+			setupMock: func(limiter *mockratelimit.MockLimiter) {
+				limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+					Return(ratelimit.Result{Limit: 20, Reset: 2 * time.Second, RetryAfter: 100 * time.Millisecond})
+			},
+			expectedStatus:  http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{"RateLimit-Limit": "20", "RateLimit-Remaining": "0", "RateLimit-Reset": "2", "Retry-After": "1"},
+		},
+		{
+			name: "rejects an api key over its limit",
+			key:  "acme-key",
+			setupMock: func(limiter *mockratelimit.MockLimiter) {
+				limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+					Return(ratelimit.Result{Allowed: true, Limit: 20, Remaining: 19})
+				limiter.EXPECT().Allow(gomock.Any(), "key:key-afacab35", config.KeyLimit()).
+					Return(ratelimit.Result{Limit: 100, Reset: 2 * time.Second, RetryAfter: 20 * time.Millisecond})
+			},
+			expectedStatus:  http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{"RateLimit-Limit": "100", "RateLimit-Remaining": "0", "Retry-After": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limiter := mockratelimit.NewMockLimiter(ctrl)
+			tt.setupMock(limiter)
+			router := newTestKeyRoute(t, config, limiter, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/notify", nil)
+			req.RemoteAddr = "192.0.2.1:41000"
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for header, value := range tt.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assert.JSONEq(t, `{"error_code":"E101","message":"rate limit exceeded, retry later"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRateLimiting_KeysOfOneTenant(t *testing.T) {
+	config := ratelimit.RateLimitConfig{Enabled: true, IPRPS: 10, IPBurst: 20, KeyRPS: 50, KeyBurst: 100}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limiter := mockratelimit.NewMockLimiter(ctrl)
+	limiter.EXPECT().Allow(gomock.Any(), "ip:192.0.2.1", config.IPLimit()).
+		Return(ratelimit.Result{Allowed: true, Limit: 20, Remaining: 19}).Times(2)
+	// The first key used up its bucket; the second key of the tenant has
+	// its own
+	limiter.EXPECT().Allow(gomock.Any(), "key:key-afacab35", config.KeyLimit()).
+		Return(ratelimit.Result{Limit: 100, Reset: 2 * time.Second, RetryAfter: 20 * time.Millisecond})
+	limiter.EXPECT().Allow(gomock.Any(), "key:key-e2b3e1e2", config.KeyLimit()).
+		Return(ratelimit.Result{Allowed: true, Limit: 100, Remaining: 99})
+
+	router := newTestKeyRoute(t, config, limiter, func(c *gin.Context) {
+		// Quotas are still shared by the tenant
+		assert.Equal(t, "acme", quota.SubjectFrom(c.Request.Context()))
+		c.Status(http.StatusOK)
+	})
+
+	for key, expectedStatus := range map[string]int{"acme-key": http.StatusTooManyRequests, "acme-ops-key": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, "/notify", nil)
+		req.RemoteAddr = "192.0.2.1:41000"
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code, key)
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+)
+
+// HeaderRequestID correlates a request with the log lines it produced; the
+// caller's id is kept, otherwise one is generated
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds the caller ids logged, so a request cannot
+// bloat every line it produces
+const maxRequestIDLength = 128
+
+// requestLogging makes the request id, route and client IP fields of every
+// line logged while serving the request, and answers the id in
+// HeaderRequestID. The client IP is only read from forwarding headers set
+// by HTTP_TRUSTED_PROXIES
+func requestLogging(ids idgen.Generator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			// Without an id the request is still served, only uncorrelated
+			requestID, _ = ids.NewID()
+		}
+
+		fields := []zap.Field{
+			zap.String(logging.FieldRoute, c.FullPath()),
+			zap.String(logging.FieldClientIP, c.ClientIP()),
+		}
+		if requestID != "" {
+			fields = append(fields, zap.String(logging.FieldRequestID, requestID))
+			c.Header(HeaderRequestID, requestID)
+		}
+
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), fields...))
+		c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII ids of a bounded length
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
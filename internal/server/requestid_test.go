@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestRequestLogging(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestID         string
+		setupMocks        func(*mockidgen.MockGenerator)
+		expectedRequestID string
+	}{
+		{
+			name:              "keeps the id of the caller",
+			requestID:         "req-42",
+			setupMocks:        func(*mockidgen.MockGenerator) {},
+			expectedRequestID: "req-42",
+		},
+		{
+			name: "generates an id without one",
+			setupMocks: func(ids *mockidgen.MockGenerator) {
+				ids.EXPECT().NewID().Return("01JB8Z5XK3M4N5P6Q7R8S9T0VW", nil)
+			},
+			expectedRequestID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		},
+		{
+			name:      "replaces an id with spaces",
+			requestID: "req 42",
+			setupMocks: func(ids *mockidgen.MockGenerator) {
+				ids.EXPECT().NewID().Return("01JB8Z5XK3M4N5P6Q7R8S9T0VW", nil)
+			},
+			expectedRequestID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		},
+		{
+			name:      "replaces an overlong id",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+			setupMocks: func(ids *mockidgen.MockGenerator) {
+				ids.EXPECT().NewID().Return("01JB8Z5XK3M4N5P6Q7R8S9T0VW", nil)
+			},
+			expectedRequestID: "01JB8Z5XK3M4N5P6Q7R8S9T0VW",
+		},
+		{
+			name: "serves the request without an id when none can be generated",
+			setupMocks: func(ids *mockidgen.MockGenerator) {
+				ids.EXPECT().NewID().Return("", errors.New("entropy exhausted"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ids := mockidgen.NewMockGenerator(ctrl)
+			tt.setupMocks(ids)
+
+			var fields []zap.Field
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(requestLogging(ids))
+			router.GET("/recipient/:recipient/notify", func(c *gin.Context) {
+				fields = logging.Fields(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/recipient/buyer/notify", nil)
+			if tt.requestID != "" {
+				req.Header.Set(HeaderRequestID, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedRequestID, w.Header().Get(HeaderRequestID))
+			expected := []zap.Field{
+				zap.String(logging.FieldRoute, "/recipient/:recipient/notify"),
+				zap.String(logging.FieldClientIP, "192.0.2.1"),
+			}
+			if tt.expectedRequestID != "" {
+				expected = append(expected, zap.String(logging.FieldRequestID, tt.expectedRequestID))
+			}
+			assert.Equal(t, expected, fields)
+		})
+	}
+}
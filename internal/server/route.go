@@ -1,21 +1,109 @@
 package server
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/koungkub/fw-challenge-notification-service/api"
+	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
 )
 
-func (h *HTTPServer) setupRoutes() {
-	h.router.Use(h.httpMetrics.Middleware())
+func (h *HTTPServer) setupRoutes(config HTTPConfig) error {
+	if err := config.validateTimeouts(); err != nil {
+		return err
+	}
+
+	h.router.Use(requestLogging(h.ids), trackErrors(h.tracker), h.httpMetrics.Middleware(), securityHeaders(config), cors(config), requestDeadline(h.clock))
+	// Compression runs inside the metrics middleware, so response sizes are
+	// the bytes sent
+	if config.CompressionEnabled {
+		h.router.Use(compression(config))
+	}
+
+	// Validation runs after authorization so the schema errors are only
+	// reported to callers allowed on the route
+	validate := func(c *gin.Context) { c.Next() }
+	if config.OpenAPIValidation {
+		validator, err := newRequestValidator(api.OpenAPI, h.jsonMaxBodySize)
+		if err != nil {
+			return err
+		}
+		validate = validator.Middleware()
+	}
+
+	// Shedding runs after authorization so rejected callers cannot use up
+	// the limit
+	shed := func(c *gin.Context) { c.Next() }
+	if h.loadShedding.Enabled {
+		if err := h.loadShedding.validate(); err != nil {
+			return err
+		}
+		shed = loadShedding(h.loadShedding, h.clock, h.httpMetrics)
+	}
+
+	// The client IP is limited before authorization, so callers guessing
+	// API keys are limited too. The API key is limited after it, and both
+	// before shedding so a single caller cannot use up the limit
+	limitIP := func(c *gin.Context) { c.Next() }
+	limit := func(c *gin.Context) { c.Next() }
+	if h.rateLimit.Enabled {
+		limitIP = ipRateLimiting(h.rateLimits, h.rateLimiter, h.httpMetrics)
+		limit = keyRateLimiting(h.rateLimits, h.rateLimiter, h.httpMetrics)
+	}
+
+	if config.InternalPort == "" {
+		operationalRoutes(h.router)
+	}
+	h.router.GET("/openapi.json", openAPIHandler)
+	h.router.GET("/docs", swaggerUIHandler)
+	h.router.GET("/docs/init.js", swaggerUIInitHandler)
+
+	// API routes are grouped by version. A new version only lists the
+	// routes whose contract it changes, its handlers translate the request
+	// onto the same service calls
+	v1 := h.router.Group("/api/v1.0")
+	v1.POST("/recipient/:recipient/notify", limitIP, h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteNotify), limit, shed, validate, h.handler.NotifyHandler)
+	// A dry run calls no provider, so it is not shed
+	v1.POST("/recipient/:recipient/notify/dry-run", limitIP, h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteNotify), limit, validate, h.handler.DryRunHandler)
+	v1.POST("/recipient/:recipient/batch", limitIP, h.auth.Require(handler.RoleNotify), streaming, config.routeTimeout(RouteBatch), limit, h.handler.BatchHandler)
+	v1.GET("/jobs/:id", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteJobs), h.handler.JobHandler)
+	v1.POST("/notifications/:id/replay", limitIP, h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteNotify), limit, shed, validate, h.handler.ReplayHandler)
+	v1.GET("/quota", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteQuota), h.quota.UsageHandler)
+	v1.GET("/inapp/:user/notifications", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteInApp), h.inApp.NotificationsHandler)
+	v1.POST("/inapp/:user/notifications/:id/read", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteInApp), h.inApp.MarkReadHandler)
+	v1.POST("/inapp/:user/notifications/:id/unread", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteInApp), h.inApp.MarkUnreadHandler)
+	v1.POST("/inapp/:user/tickets", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteInApp), h.realtime.TicketHandler)
+	// Realtime connections stay open for as long as the user is online.
+	// Browsers cannot send an API key on them, so they carry a ticket
+	v1.GET("/inapp/:user/stream", streaming, h.realtime.StreamHandler)
+	v1.GET("/inapp/:user/ws", websocketOrigin(config), streaming, h.realtime.WebSocketHandler)
+	v1.GET("/users/:user/consents", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteConsents), h.consents.ConsentsHandler)
+	v1.PUT("/users/:user/consents", h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteConsents), h.consents.SetConsentHandler)
+	// Providers authenticate by signing the body instead of with an API key
+	v1.POST("/providers/:provider/receipts", config.routeTimeout(RouteReceipts), h.receipts.ReceiptHandler)
+
+	v2 := h.router.Group("/api/v2.0")
+	v2.POST("/recipient/:recipient/notify", limitIP, h.auth.Require(handler.RoleNotify), config.routeTimeout(RouteNotify), limit, shed, validate, h.handler.NotifyV2Handler)
 
-	h.router.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "server is running",
-		})
-	})
-	h.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	admin := h.router.Group("/admin/v1.0", h.auth.Require(handler.RoleAdmin), config.routeTimeout(RouteAdmin), validate)
+	admin.GET("/status", h.admin.StatusHandler)
+	admin.GET("/audit", h.admin.AuditHandler)
+	admin.GET("/preferences", h.admin.PreferencesHandler)
+	admin.POST("/preferences/:id/disable", h.admin.DisablePreferenceHandler)
+	admin.POST("/preferences/:id/enable", h.admin.EnablePreferenceHandler)
+	admin.POST("/preferences/:id/rotate", h.admin.RotateSecretKeyHandler)
+	admin.POST("/preferences/:id/promote", h.admin.PromoteSecretKeyHandler)
+	admin.GET("/suppressions", h.admin.SuppressionsHandler)
+	admin.DELETE("/suppressions/:address", h.admin.RemoveSuppressionHandler)
+	admin.GET("/dead-letters", h.admin.DeadLettersHandler)
+	admin.POST("/dead-letters/redrive", h.admin.RedriveDeadLettersHandler)
+	admin.GET("/templates", h.admin.TemplatesHandler)
+	admin.POST("/templates", h.admin.CreateTemplateHandler)
+	admin.GET("/templates/:name", h.admin.TemplateHandler)
+	admin.PUT("/templates/:name", h.admin.UpdateTemplateHandler)
+	admin.POST("/templates/:name/preview", h.admin.PreviewTemplateHandler)
+	admin.POST("/templates/:name/versions/:version/activate", h.admin.ActivateTemplateHandler)
+	admin.GET("/costs", h.admin.CostsHandler)
+	admin.POST("/caches/:name/invalidate", h.admin.InvalidateCacheHandler)
+	admin.POST("/circuit-breakers/reset", h.admin.ResetCircuitBreakerHandler)
 
-	h.router.POST("/api/v1.0/recipient/:recipient/notify", h.handler.NotifyHandler)
+	return nil
 }
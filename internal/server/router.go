@@ -0,0 +1,25 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRouter builds the API router in the configured gin mode. Client IPs
+// are only read from forwarding headers sent by TrustedProxies; gin would
+// otherwise trust them from any peer, letting a caller pick its own IP
+func newRouter(config HTTPConfig) (*gin.Engine, error) {
+	switch config.Mode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		gin.SetMode(config.Mode)
+	default:
+		return nil, fmt.Errorf("gin mode: '%s' is not debug, release or test", config.Mode)
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return router, nil
+}
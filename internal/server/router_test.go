@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRouter(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           HTTPConfig
+		remoteAddr       string
+		expectedClientIP string
+		expectedError    string
+	}{
+		{
+			name:             "ignores forwarding headers without trusted proxies",
+			config:           HTTPConfig{Mode: gin.ReleaseMode},
+			remoteAddr:       "10.0.0.5:41000",
+			expectedClientIP: "10.0.0.5",
+		},
+		{
+			name:             "reads the client ip forwarded by a trusted proxy",
+			config:           HTTPConfig{Mode: gin.ReleaseMode, TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr:       "10.0.0.5:41000",
+			expectedClientIP: "203.0.113.7",
+		},
+		{
+			name:             "ignores forwarding headers of other peers",
+			config:           HTTPConfig{Mode: gin.ReleaseMode, TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr:       "198.51.100.9:41000",
+			expectedClientIP: "198.51.100.9",
+		},
+		{
+			name:          "refuses an unknown mode",
+			config:        HTTPConfig{Mode: "production"},
+			expectedError: "gin mode: 'production' is not debug, release or test",
+		},
+		{
+			name:          "refuses an invalid proxy",
+			config:        HTTPConfig{Mode: gin.ReleaseMode, TrustedProxies: []string{"10.0.0.0/99"}},
+			expectedError: "trusted proxies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { gin.SetMode(gin.TestMode) })
+
+			router, err := newRouter(tt.config)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.config.Mode, gin.Mode())
+
+			var clientIP string
+			router.GET("/ip", func(c *gin.Context) {
+				clientIP = c.ClientIP()
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedClientIP, clientIP)
+		})
+	}
+}
@@ -2,80 +2,269 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kelseyhightower/envconfig"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
 	"github.com/koungkub/fw-challenge-notification-service/internal/handler"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
 	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/ratelimit"
 	"go.uber.org/fx"
 )
 
 var Module = fx.Module("http_server",
 	fx.Provide(
 		NewHTTP,
-		NewConfig,
 	),
 )
 
 type HTTPParams struct {
 	fx.In
 
-	Config      HTTPConfig
-	Handler     *handler.Notification
-	HTTPMetrics *metrics.HTTPServerCollector
+	Config       HTTPConfig
+	LoadShedding LoadSheddingConfig
+	RateLimit    ratelimit.RateLimitConfig
+	RateLimiter  ratelimit.Limiter
+	RateLimits   *ratelimit.Limits
+	Handler      *handler.Notification
+	Admin        *handler.Admin
+	Auth         *handler.Authorizer
+	Receipts     *handler.Receipts
+	InApp        *handler.InApp
+	Consents     *handler.Consents
+	Quota        *handler.Quota
+	Realtime     *handler.Realtime
+	HTTPMetrics  *metrics.HTTPServerCollector
+	IDGenerator  idgen.Generator
+	Tracker      errortracking.Tracker
+	Clock        clock.Clock
+
+	// HandlerConfig carries the size limit the handlers bind JSON bodies
+	// with, which schema validation reads bodies with as well
+	HandlerConfig handler.HandlerConfig
 }
 
 type HTTPServer struct {
 	router *gin.Engine
 	srv    *http.Server
+	// redirect sends plain HTTP to HTTPS, and answers ACME challenges with
+	// autocert; nil unless TLS and HTTP_TLS_REDIRECT_PORT are set
+	redirect *http.Server
+	// internal serves health, metrics and debug routes apart from the
+	// API; nil unless HTTP_INTERNAL_PORT is set
+	internal *http.Server
+	// addresses are TCP addresses or unix: socket paths served alike
+	addresses      []string
+	unixSocketMode os.FileMode
 
 	handler     *handler.Notification
+	admin       *handler.Admin
+	auth        *handler.Authorizer
+	receipts    *handler.Receipts
+	inApp       *handler.InApp
+	consents    *handler.Consents
+	quota       *handler.Quota
+	realtime    *handler.Realtime
 	httpMetrics *metrics.HTTPServerCollector
+	ids         idgen.Generator
+	tracker     errortracking.Tracker
+	clock       clock.Clock
+
+	loadShedding LoadSheddingConfig
+	rateLimit    ratelimit.RateLimitConfig
+	rateLimiter  ratelimit.Limiter
+	rateLimits   *ratelimit.Limits
+
+	jsonMaxBodySize int64
 }
 
-func NewHTTP(lc fx.Lifecycle, params HTTPParams) *HTTPServer {
-	router := gin.New()
-	router.Use(gin.Recovery())
+func NewHTTP(lc fx.Lifecycle, params HTTPParams) (*HTTPServer, error) {
+	router, err := newRouter(params.Config)
+	if err != nil {
+		return nil, err
+	}
+	router.Use(recovery(params.Tracker))
 
 	httpServer := &HTTPServer{
 		router: router,
 		srv: &http.Server{
-			Addr:    params.Config.Port,
-			Handler: router,
+			Addr:              params.Config.Port,
+			Handler:           router,
+			ReadTimeout:       params.Config.ReadTimeout,
+			ReadHeaderTimeout: params.Config.ReadHeaderTimeout,
+			WriteTimeout:      params.Config.WriteTimeout,
+			IdleTimeout:       params.Config.IdleTimeout,
+			MaxHeaderBytes:    params.Config.MaxHeaderBytes,
 		},
 		httpMetrics: params.HTTPMetrics,
 		handler:     params.Handler,
+		admin:       params.Admin,
+		auth:        params.Auth,
+		receipts:    params.Receipts,
+		inApp:       params.InApp,
+		consents:    params.Consents,
+		quota:       params.Quota,
+		realtime:    params.Realtime,
+		ids:         params.IDGenerator,
+		tracker:     params.Tracker,
+		clock:       params.Clock,
+
+		addresses:      params.Config.addresses(),
+		unixSocketMode: params.Config.UnixSocketMode,
+
+		loadShedding: params.LoadShedding,
+		rateLimit:    params.RateLimit,
+		rateLimiter:  params.RateLimiter,
+		rateLimits:   params.RateLimits,
+
+		jsonMaxBodySize: params.HandlerConfig.JSONMaxBodySize,
 	}
 
-	httpServer.setupRoutes()
+	if err := params.Config.validateListeners(); err != nil {
+		return nil, err
+	}
+	if err := httpServer.setupRoutes(params.Config); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, certManager, err := newTLSConfig(params.Config)
+	if err != nil {
+		return nil, err
+	}
+	httpServer.srv.TLSConfig = tlsConfig
+	if tlsConfig != nil && params.Config.TLSRedirectPort != "" {
+		redirect := redirectToHTTPS(params.Config.Port)
+		if certManager != nil {
+			redirect = certManager.HTTPHandler(redirect)
+		}
+		httpServer.redirect = &http.Server{
+			Addr:              params.Config.TLSRedirectPort,
+			Handler:           redirect,
+			ReadTimeout:       params.Config.ReadTimeout,
+			ReadHeaderTimeout: params.Config.ReadHeaderTimeout,
+			WriteTimeout:      params.Config.WriteTimeout,
+			IdleTimeout:       params.Config.IdleTimeout,
+			MaxHeaderBytes:    params.Config.MaxHeaderBytes,
+		}
+	}
+	if params.Config.InternalPort != "" {
+		httpServer.internal = newInternalServer(params.Config)
+	}
+	// Realtime connections never go idle, so Shutdown would wait for them
+	// until its context ends
+	httpServer.srv.RegisterOnShutdown(params.Realtime.Close)
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			ln, err := net.Listen("tcp", httpServer.srv.Addr)
+			listeners, err := httpServer.listen()
 			if err != nil {
 				return err
 			}
+			closeAll := func() {
+				for _, ln := range listeners {
+					ln.Close()
+				}
+			}
+			var redirectLn, internalLn net.Listener
+			if httpServer.redirect != nil {
+				if redirectLn, err = net.Listen("tcp", httpServer.redirect.Addr); err != nil {
+					closeAll()
+					return err
+				}
+			}
+			if httpServer.internal != nil {
+				if internalLn, err = net.Listen("tcp", httpServer.internal.Addr); err != nil {
+					closeAll()
+					if redirectLn != nil {
+						redirectLn.Close()
+					}
+					return err
+				}
+			}
+			if redirectLn != nil {
+				go httpServer.redirect.Serve(redirectLn)
+			}
+			if internalLn != nil {
+				go httpServer.internal.Serve(internalLn)
+			}
 			// log.Info("Starting HTTP server", zap.String("addr", srv.Addr))
-			go httpServer.srv.Serve(ln)
+			// Shutdown closes every listener Serve was given
+			for _, ln := range listeners {
+				go httpServer.srv.Serve(ln)
+			}
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			return httpServer.srv.Shutdown(ctx)
+			// The internal server stops last so metrics are scraped while
+			// the API drains
+			var errs []error
+			for _, srv := range []*http.Server{httpServer.redirect, httpServer.srv, httpServer.internal} {
+				if srv != nil {
+					errs = append(errs, srv.Shutdown(ctx))
+				}
+			}
+			return errors.Join(errs...)
 		},
 	})
 
-	return httpServer
+	return httpServer, nil
 }
 
 type HTTPConfig struct {
 	Port string `envconfig:"HTTP_SERVER_PORT" default:":8080"`
-}
-
-func NewConfig() HTTPConfig {
-	var cfg HTTPConfig
-	envconfig.MustProcess("", &cfg)
-
-	return cfg
+	// Mode is the gin mode: debug, release or test
+	Mode string `envconfig:"GIN_MODE" default:"debug"`
+	// TrustedProxies are the IPs or CIDRs, e.g. of the load balancer,
+	// whose X-Forwarded-For and X-Real-IP headers tell the client IP;
+	// without any the peer address is the client IP
+	TrustedProxies []string `envconfig:"HTTP_TRUSTED_PROXIES"`
+	// Listeners are served alongside Port, as TCP addresses or Unix socket
+	// paths prefixed with unix:, e.g. a socket for a sidecar proxy
+	Listeners      []string    `envconfig:"HTTP_LISTENERS"`
+	UnixSocketMode os.FileMode `envconfig:"HTTP_UNIX_SOCKET_MODE" default:"0660"`
+	// InternalPort moves /healthz and /metrics off the API listeners and
+	// adds /debug, for ports the public ingress does not route to
+	InternalPort       string        `envconfig:"HTTP_INTERNAL_PORT"`
+	CORSAllowedOrigins []string      `envconfig:"HTTP_CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods []string      `envconfig:"HTTP_CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE"`
+	CORSAllowedHeaders []string      `envconfig:"HTTP_CORS_ALLOWED_HEADERS" default:"Authorization,Content-Type,Idempotency-Key,X-Request-Deadline,Grpc-Timeout,X-Admin-Actor,X-Request-ID"`
+	CORSExposedHeaders []string      `envconfig:"HTTP_CORS_EXPOSED_HEADERS" default:"Idempotency-Key,X-Notification-ID,X-Notification-Attempts,X-Retry-Disposition,X-Notification-Duplicate,X-Request-ID"`
+	CORSMaxAge         time.Duration `envconfig:"HTTP_CORS_MAX_AGE" default:"10m"`
+	HSTSMaxAge         time.Duration `envconfig:"HTTP_HSTS_MAX_AGE" default:"0s"`
+	OpenAPIValidation  bool          `envconfig:"HTTP_OPENAPI_VALIDATION" default:"false"`
+	// Compression decompresses gzip request bodies, up to
+	// MaxDecompressedBodyBytes against decompression bombs, and compresses
+	// responses of at least CompressionMinSize bytes with br or gzip
+	CompressionEnabled       bool  `envconfig:"HTTP_COMPRESSION_ENABLED" default:"true"`
+	CompressionMinSize       int   `envconfig:"HTTP_COMPRESSION_MIN_SIZE" default:"1024"`
+	MaxDecompressedBodyBytes int64 `envconfig:"HTTP_MAX_DECOMPRESSED_BODY_BYTES" default:"104857600"`
+	// Connection timeouts keep slow clients from holding connections open,
+	// e.g. a slowloris trickling headers; streams and batch uploads lift
+	// the read and write timeouts for themselves
+	ReadTimeout       time.Duration `envconfig:"HTTP_READ_TIMEOUT" default:"30s"`
+	ReadHeaderTimeout time.Duration `envconfig:"HTTP_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `envconfig:"HTTP_WRITE_TIMEOUT" default:"75s"`
+	IdleTimeout       time.Duration `envconfig:"HTTP_IDLE_TIMEOUT" default:"120s"`
+	MaxHeaderBytes    int           `envconfig:"HTTP_MAX_HEADER_BYTES" default:"1048576"`
+	// HandlerTimeout bounds the request context of every API route;
+	// RouteTimeouts overrides it per route, e.g. notify:20s,admin:10s
+	HandlerTimeout time.Duration            `envconfig:"HTTP_HANDLER_TIMEOUT" default:"60s"`
+	RouteTimeouts  map[string]time.Duration `envconfig:"HTTP_ROUTE_TIMEOUTS"`
+	// TLS is served directly, for deployments without a load balancer in
+	// front, from either a certificate pair or certificates autocert
+	// obtains from Let's Encrypt for the listed domains
+	TLSCertFile         string   `envconfig:"HTTP_TLS_CERT_FILE"`
+	TLSKeyFile          string   `envconfig:"HTTP_TLS_KEY_FILE"`
+	TLSAutocertDomains  []string `envconfig:"HTTP_TLS_AUTOCERT_DOMAINS"`
+	TLSAutocertCacheDir string   `envconfig:"HTTP_TLS_AUTOCERT_CACHE_DIR" default:"autocert"`
+	TLSAutocertEmail    string   `envconfig:"HTTP_TLS_AUTOCERT_EMAIL"`
+	// TLSRedirectPort serves plain HTTP redirecting to HTTPS, and the ACME
+	// http-01 challenges of autocert; empty disables it
+	TLSRedirectPort string `envconfig:"HTTP_TLS_REDIRECT_PORT"`
 }
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes HTTP_ROUTE_TIMEOUTS sets the handler timeout of
+const (
+	RouteNotify   = "notify"
+	RouteBatch    = "batch"
+	RouteJobs     = "jobs"
+	RouteQuota    = "quota"
+	RouteInApp    = "inapp"
+	RouteConsents = "consents"
+	RouteReceipts = "receipts"
+	RouteAdmin    = "admin"
+)
+
+var timeoutRoutes = []string{
+	RouteNotify, RouteBatch, RouteJobs, RouteQuota, RouteInApp, RouteConsents, RouteReceipts, RouteAdmin,
+}
+
+func (c HTTPConfig) validateTimeouts() error {
+	for route, timeout := range c.RouteTimeouts {
+		if !slices.Contains(timeoutRoutes, route) {
+			return fmt.Errorf("route timeout: unknown route '%s', use one of %v", route, timeoutRoutes)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("route timeout of '%s': %s must not be negative", route, timeout)
+		}
+	}
+	if c.HandlerTimeout < 0 {
+		return fmt.Errorf("handler timeout: %s must not be negative", c.HandlerTimeout)
+	}
+	return nil
+}
+
+// routeTimeout bounds the request context of a route by its entry in
+// RouteTimeouts, or HandlerTimeout when it has none. Batch uploads last as
+// long as the caller keeps sending, so they are only bounded when listed
+func (c HTTPConfig) routeTimeout(route string) gin.HandlerFunc {
+	timeout, ok := c.RouteTimeouts[route]
+	if !ok && route != RouteBatch {
+		timeout = c.HandlerTimeout
+	}
+	return handlerTimeout(timeout)
+}
+
+// handlerTimeout cancels the request context after timeout; zero leaves it
+// unbounded. An earlier caller deadline still wins
+func handlerTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// streaming lifts the server read and write timeouts for requests meant to
+// outlast them: realtime streams and batch uploads. It runs after
+// authorization, so a caller without a key cannot hold a connection open.
+// Writers without deadlines, such as test recorders, are left as they are
+func streaming(c *gin.Context) {
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	c.Next()
+}
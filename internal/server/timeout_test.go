@@ -0,0 +1,131 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPConfig_validateTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      HTTPConfig
+		expectError bool
+	}{
+		{
+			name:   "accepts known routes",
+			config: HTTPConfig{HandlerTimeout: time.Minute, RouteTimeouts: map[string]time.Duration{RouteNotify: 20 * time.Second, RouteAdmin: 0}},
+		},
+		{
+			name:        "rejects an unknown route",
+			config:      HTTPConfig{RouteTimeouts: map[string]time.Duration{"notifications": time.Second}},
+			expectError: true,
+		},
+		{
+			name:        "rejects a negative route timeout",
+			config:      HTTPConfig{RouteTimeouts: map[string]time.Duration{RouteJobs: -time.Second}},
+			expectError: true,
+		},
+		{
+			name:        "rejects a negative handler timeout",
+			config:      HTTPConfig{HandlerTimeout: -time.Second},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validateTimeouts()
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_routeTimeout(t *testing.T) {
+	config := HTTPConfig{
+		HandlerTimeout: time.Minute,
+		RouteTimeouts:  map[string]time.Duration{RouteNotify: 20 * time.Second, RouteAdmin: 0},
+	}
+
+	tests := []struct {
+		name          string
+		route         string
+		expected      time.Duration
+		expectedBound bool
+	}{
+		{name: "uses the timeout of the route", route: RouteNotify, expected: 20 * time.Second, expectedBound: true},
+		{name: "falls back to the handler timeout", route: RouteJobs, expected: time.Minute, expectedBound: true},
+		{name: "leaves a route set to zero unbounded", route: RouteAdmin},
+		{name: "leaves batch uploads unbounded unless listed", route: RouteBatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				deadline time.Time
+				bound    bool
+			)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/", config.routeTimeout(tt.route), func(c *gin.Context) {
+				deadline, bound = c.Request.Context().Deadline()
+				c.Status(http.StatusOK)
+			})
+
+			start := time.Now()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, tt.expectedBound, bound)
+			if tt.expectedBound {
+				assert.WithinDuration(t, start.Add(tt.expected), deadline, time.Second)
+			}
+		})
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	slow := func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "still streaming")
+	}
+	router.GET("/stream", streaming, slow)
+	router.GET("/plain", slow)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 20 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	t.Run("lifts the write timeout", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "still streaming", string(body))
+	})
+
+	t.Run("keeps the write timeout of other routes", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/plain")
+		if err == nil {
+			defer resp.Body.Close()
+			_, err = io.ReadAll(resp.Body)
+		}
+		assert.Error(t, err)
+	})
+}
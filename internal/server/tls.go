@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+var (
+	errTLSBothSources = errors.New("tls: set either HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE or HTTP_TLS_AUTOCERT_DOMAINS, not both")
+	errTLSPartialPair = errors.New("tls: HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+)
+
+// TLSEnabled tells whether the server terminates TLS itself
+func (c HTTPConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != "" || len(c.TLSAutocertDomains) > 0
+}
+
+// newTLSConfig returns the TLS settings of the server, and with autocert
+// the manager answering ACME challenges; both are nil without TLS
+func newTLSConfig(config HTTPConfig) (*tls.Config, *autocert.Manager, error) {
+	if !config.TLSEnabled() {
+		return nil, nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// TLS 1.3 suites are not configurable and always preferred; these
+		// keep TLS 1.2 to forward secret AEAD ciphers
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		NextProtos:       []string{"h2", "http/1.1"},
+	}
+
+	if len(config.TLSAutocertDomains) > 0 {
+		if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+			return nil, nil, errTLSBothSources
+		}
+
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.TLSAutocertDomains...),
+			Cache:      autocert.DirCache(config.TLSAutocertCacheDir),
+			Email:      config.TLSAutocertEmail,
+		}
+		tlsConfig.GetCertificate = manager.GetCertificate
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, acme.ALPNProto)
+		return tlsConfig, manager, nil
+	}
+
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, nil, errTLSPartialPair
+	}
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tls: load certificate: %w", err)
+	}
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+
+	return tlsConfig, nil, nil
+}
+
+// redirectToHTTPS permanently redirects every request to the same URL on
+// the HTTPS port, keeping method and body
+func redirectToHTTPS(httpsPort string) http.Handler {
+	port := ""
+	if _, p, err := net.SplitHostPort(httpsPort); err == nil && p != "443" {
+		port = p
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		switch {
+		case port != "":
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
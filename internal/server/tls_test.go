@@ -0,0 +1,187 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate for localhost into
+// dir and returns its certificate and key paths
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	tests := []struct {
+		name           string
+		config         HTTPConfig
+		expectTLS      bool
+		expectAutocert bool
+		expectedError  error
+		expectAnyError bool
+	}{
+		{
+			name: "leaves TLS off without a certificate source",
+		},
+		{
+			name:      "loads a certificate pair",
+			config:    HTTPConfig{TLSCertFile: certFile, TLSKeyFile: keyFile},
+			expectTLS: true,
+		},
+		{
+			name:           "obtains certificates with autocert",
+			config:         HTTPConfig{TLSAutocertDomains: []string{"notify.example.com"}, TLSAutocertCacheDir: dir},
+			expectTLS:      true,
+			expectAutocert: true,
+		},
+		{
+			name:          "rejects both certificate sources",
+			config:        HTTPConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSAutocertDomains: []string{"notify.example.com"}},
+			expectedError: errTLSBothSources,
+		},
+		{
+			name:          "rejects half a certificate pair",
+			config:        HTTPConfig{TLSCertFile: certFile},
+			expectedError: errTLSPartialPair,
+		},
+		{
+			name:           "rejects a missing certificate file",
+			config:         HTTPConfig{TLSCertFile: filepath.Join(dir, "missing.pem"), TLSKeyFile: keyFile},
+			expectAnyError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, manager, err := newTLSConfig(tt.config)
+
+			switch {
+			case tt.expectedError != nil:
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			case tt.expectAnyError:
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectTLS, tlsConfig != nil)
+			assert.Equal(t, tt.expectAutocert, manager != nil)
+			if tt.expectTLS {
+				assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+			}
+		})
+	}
+}
+
+func TestHTTPServer_listenTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+	tlsConfig, _, err := newTLSConfig(HTTPConfig{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Proto)
+		}),
+		TLSConfig: tlsConfig,
+	}
+	listeners, err := (&HTTPServer{srv: srv, addresses: []string{"127.0.0.1:0"}}).listen()
+	require.NoError(t, err)
+	ln := listeners[0]
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool, ServerName: "localhost"},
+		ForceAttemptHTTP2: true,
+	}}
+
+	t.Run("serves HTTP/2 over TLS", func(t *testing.T) {
+		resp, err := client.Get("https://" + ln.Addr().String())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", string(body))
+		assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+	})
+
+	t.Run("refuses TLS 1.1", func(t *testing.T) {
+		old := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, ServerName: "localhost", MaxVersion: tls.VersionTLS11},
+		}}
+
+		_, err := old.Get("https://" + ln.Addr().String())
+		assert.Error(t, err)
+	})
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort string
+		target    string
+		expected  string
+	}{
+		{name: "drops the default port", httpsPort: ":443", target: "http://notify.example.com/api/v1/notify?x=1", expected: "https://notify.example.com/api/v1/notify?x=1"},
+		{name: "keeps another port", httpsPort: ":8443", target: "http://notify.example.com:8080/health", expected: "https://notify.example.com:8443/health"},
+		{name: "brackets an IPv6 host", httpsPort: ":443", target: "http://[::1]:8080/health", expected: "https://[::1]/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			redirectToHTTPS(tt.httpsPort).ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.target, nil))
+
+			assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+			assert.Equal(t, tt.expected, w.Header().Get("Location"))
+		})
+	}
+}
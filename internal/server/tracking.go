@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+)
+
+// recovery answers a panicking request with 500 as gin.Recovery does, and
+// captures the panic with its stack
+func recovery(tracker errortracking.Tracker) gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		if tracker != nil {
+			tracker.Capture(c.Request.Context(), errortracking.Event{
+				Level:   errortracking.LevelFatal,
+				Message: fmt.Sprintf("panic: %v", recovered),
+				Request: trackedRequest(c),
+				Stack:   debug.Stack(),
+			})
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// trackErrors captures the requests answered with a server error. Failing
+// providers, overload and expired deadlines push back on callers rather
+// than point at a fault of the service, so 502, 503 and 504 are left out
+func trackErrors(tracker errortracking.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if tracker == nil || status < http.StatusInternalServerError || status == http.StatusBadGateway ||
+			status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout {
+			return
+		}
+
+		event := errortracking.Event{
+			Message: fmt.Sprintf("%s %s answered %d", c.Request.Method, c.FullPath(), status),
+			Tags:    map[string]string{"http.status_code": strconv.Itoa(status)},
+			Request: trackedRequest(c),
+		}
+		if last := c.Errors.Last(); last != nil {
+			event.Err = last.Err
+		}
+		tracker.Capture(c.Request.Context(), event)
+	}
+}
+
+func trackedRequest(c *gin.Context) *errortracking.Request {
+	return &errortracking.Request{Method: c.Request.Method, URL: c.Request.URL.String()}
+}
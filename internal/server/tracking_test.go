@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/koungkub/fw-challenge-notification-service/internal/errortracking"
+	mockerrortracking "github.com/koungkub/fw-challenge-notification-service/internal/errortracking/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrackErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		err             error
+		expectedCapture bool
+	}{
+		{name: "captures an internal error with its cause", status: http.StatusInternalServerError, err: errors.New("database unreachable"), expectedCapture: true},
+		{name: "captures a server error without cause", status: http.StatusNotImplemented, expectedCapture: true},
+		{name: "leaves out failing providers", status: http.StatusBadGateway},
+		{name: "leaves out overload", status: http.StatusServiceUnavailable},
+		{name: "leaves out expired deadlines", status: http.StatusGatewayTimeout},
+		{name: "leaves out client errors", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tracker := mockerrortracking.NewMockTracker(ctrl)
+			if tt.expectedCapture {
+				tracker.EXPECT().Capture(gomock.Any(), gomock.Any()).
+					Do(func(_ context.Context, event errortracking.Event) {
+						assert.Equal(t, tt.err, event.Err)
+						assert.Equal(t, &errortracking.Request{Method: http.MethodGet, URL: "/jobs/42?limit=1"}, event.Request)
+						assert.Contains(t, event.Message, "GET /jobs/:id answered")
+					})
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(trackErrors(tracker))
+			router.GET("/jobs/:id", func(c *gin.Context) {
+				if tt.err != nil {
+					_ = c.Error(tt.err)
+				}
+				c.Status(tt.status)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/jobs/42?limit=1", nil))
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracker := mockerrortracking.NewMockTracker(ctrl)
+	tracker.EXPECT().Capture(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, event errortracking.Event) {
+			assert.Equal(t, errortracking.LevelFatal, event.Level)
+			assert.Equal(t, "panic: nil map", event.Message)
+			assert.NotEmpty(t, event.Stack)
+		})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(recovery(tracker))
+	router.GET("/panic", func(*gin.Context) { panic("nil map") })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
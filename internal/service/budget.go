@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// BudgetConfig splits the time a notification may take to deliver between
+// its stages, so a slow lookup or provider cannot use it all up
+type BudgetConfig struct {
+	// Total bounds the delivery of a notification, from its first lookup to
+	// its last provider attempt; an earlier caller deadline wins. Zero leaves
+	// delivery bounded by the caller alone
+	Total time.Duration `envconfig:"DELIVERY_BUDGET" default:"10s"`
+	// LookupShare is the share of the remaining budget, between 0 and 1, a
+	// database query or secret lookup may take
+	LookupShare float64 `envconfig:"DELIVERY_BUDGET_LOOKUP_SHARE" default:"0.2"`
+	// MinAttempt is the least a provider attempt is given when splitting the
+	// remaining budget among the providers left would give it less
+	MinAttempt time.Duration `envconfig:"DELIVERY_BUDGET_MIN_ATTEMPT" default:"1s"`
+}
+
+// start bounds the delivery of a notification by the total budget
+func (c BudgetConfig) start(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Total <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Total)
+}
+
+// lookup bounds one lookup by its share of the remaining budget. In memory
+// caches never wait, so only lookups reaching the database or a secret
+// store are bounded
+func (c BudgetConfig) lookup(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || c.LookupShare <= 0 || c.LookupShare >= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(float64(time.Until(deadline))*c.LookupShare))
+}
+
+// attempt bounds one provider attempt by an even split of the remaining
+// budget among the attempts left, so fallback providers keep their turn.
+// The last attempt gets whatever remains
+func (c BudgetConfig) attempt(ctx context.Context, attemptsLeft int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || attemptsLeft <= 1 {
+		return context.WithCancel(ctx)
+	}
+	share := time.Until(deadline) / time.Duration(attemptsLeft)
+	return context.WithTimeout(ctx, max(share, c.MinAttempt))
+}
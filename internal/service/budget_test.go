@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBudgetConfig(t *testing.T) {
+	budget := BudgetConfig{Total: 10 * time.Second, LookupShare: 0.2, MinAttempt: time.Second}
+
+	tests := []struct {
+		name            string
+		bound           func(context.Context) (context.Context, context.CancelFunc)
+		parent          time.Duration
+		expected        time.Duration
+		expectedUnbound bool
+	}{
+		{name: "bounds delivery by the total", bound: budget.start, expected: 10 * time.Second},
+		{name: "keeps an earlier caller deadline", bound: budget.start, parent: 2 * time.Second, expected: 2 * time.Second},
+		{name: "leaves delivery to the caller without a total", bound: BudgetConfig{}.start, expectedUnbound: true},
+		{name: "gives a lookup its share of the remaining budget", bound: budget.lookup, parent: 5 * time.Second, expected: time.Second},
+		{name: "leaves a lookup without deadline unbound", bound: budget.lookup, expectedUnbound: true},
+		{
+			name:     "splits the remaining budget among the attempts left",
+			bound:    func(ctx context.Context) (context.Context, context.CancelFunc) { return budget.attempt(ctx, 3) },
+			parent:   9 * time.Second,
+			expected: 3 * time.Second,
+		},
+		{
+			name:     "gives an attempt at least the minimum",
+			bound:    func(ctx context.Context) (context.Context, context.CancelFunc) { return budget.attempt(ctx, 5) },
+			parent:   2 * time.Second,
+			expected: time.Second,
+		},
+		{
+			name:     "gives the last attempt whatever remains",
+			bound:    func(ctx context.Context) (context.Context, context.CancelFunc) { return budget.attempt(ctx, 1) },
+			parent:   9 * time.Second,
+			expected: 9 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.parent > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.parent)
+				defer cancel()
+			}
+
+			start := time.Now()
+			bounded, cancel := tt.bound(ctx)
+			defer cancel()
+
+			deadline, ok := bounded.Deadline()
+			require.Equal(t, !tt.expectedUnbound, ok)
+			if ok {
+				assert.WithinDuration(t, start.Add(tt.expected), deadline, 50*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestProviderChannel_AttemptBudget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mockrepository.NewMockCacheProvider(ctrl)
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+		{Host: "https://slow.example.com"},
+		{Host: "https://fallback.example.com"},
+	}, nil)
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://slow.example.com", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ string, _ any) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://fallback.example.com", gomock.Any()).Return(nil)
+
+	channel := NewEmailChannel(ProviderChannelParams{
+		CacheProvider:    mockCache,
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          newTestSecrets(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Alerter:          newTestAlerter(ctrl),
+		Budget:           BudgetConfig{Total: 400 * time.Millisecond},
+	})
+
+	ctx, cancel := channel.budget.start(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	err := channel.Send(ctx, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 300*time.Millisecond, "the slow provider must only get its share of the budget")
+}
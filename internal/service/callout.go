@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CalloutHookName is the name of CalloutHook in PRE_SEND_HOOKS
+const CalloutHookName = "callout"
+
+// Callout actions answered by the moderation service
+const (
+	CalloutActionAllow  = "allow"
+	CalloutActionRedact = "redact"
+	CalloutActionReject = "reject"
+)
+
+type CalloutConfig struct {
+	// URL is the moderation service the callout hook posts each
+	// notification to; empty lets every notification through
+	URL     string        `envconfig:"PRE_SEND_HOOK_URL" secret:"true"`
+	Timeout time.Duration `envconfig:"PRE_SEND_HOOK_TIMEOUT" default:"2s"`
+}
+
+// CalloutRequest is the content posted to the moderation service
+type CalloutRequest struct {
+	RecipientType string            `json:"recipient_type"`
+	To            string            `json:"to"`
+	Title         string            `json:"title"`
+	Message       string            `json:"message"`
+	HTML          string            `json:"html,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+}
+
+// CalloutResponse is the decision of the moderation service; a redact
+// action sends its title, message and HTML instead
+type CalloutResponse struct {
+	Action  string `json:"action"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	HTML    string `json:"html"`
+	Reason  string `json:"reason"`
+}
+
+var _ Hook = (*CalloutHook)(nil)
+
+// CalloutHook asks an external moderation service whether to send, redact
+// or reject each notification. A failing service fails the notification as
+// safe to retry, so unmoderated content never reaches a provider
+type CalloutHook struct {
+	url        string
+	httpclient *http.Client
+}
+
+func NewCalloutHook(config CalloutConfig) *CalloutHook {
+	return &CalloutHook{
+		url: config.URL,
+		httpclient: &http.Client{
+			Timeout: config.Timeout,
+		},
+	}
+}
+
+func (h *CalloutHook) Name() string {
+	return CalloutHookName
+}
+
+func (h *CalloutHook) Check(ctx context.Context, notification Notification) (Notification, error) {
+	if h.url == "" {
+		return notification, nil
+	}
+
+	body, err := json.Marshal(CalloutRequest{
+		RecipientType: notification.RecipientType,
+		To:            notification.To,
+		Title:         notification.Title,
+		Message:       notification.Message,
+		HTML:          notification.HTML,
+		Metadata:      notification.Metadata,
+	})
+	if err != nil {
+		return notification, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return notification, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.httpclient.Do(req)
+	if err != nil {
+		return notification, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return notification, fmt.Errorf("pre-send hook callout responded with status code %d", resp.StatusCode)
+	}
+
+	var decision CalloutResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return notification, fmt.Errorf("pre-send hook callout response: %w", err)
+	}
+
+	switch decision.Action {
+	case CalloutActionAllow:
+		return notification, nil
+	case CalloutActionRedact:
+		notification.Title = decision.Title
+		notification.Message = decision.Message
+		notification.HTML = decision.HTML
+		return notification, nil
+	case CalloutActionReject:
+		return notification, &ContentRejectedError{Hook: h.Name(), Reason: decision.Reason}
+	default:
+		return notification, fmt.Errorf("pre-send hook callout answered unknown action '%s'", decision.Action)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalloutHook_Check(t *testing.T) {
+	notification := Notification{
+		RecipientType: recipientTypeBuyer,
+		To:            "buyer@example.com",
+		Title:         "Order shipped",
+		Message:       "Call 081-234-5678",
+	}
+
+	tests := []struct {
+		name              string
+		statusCode        int
+		response          string
+		expected          Notification
+		expectedRejection bool
+		expectedError     bool
+	}{
+		{
+			name:       "sends the content as is when allowed",
+			statusCode: http.StatusOK,
+			response:   `{"action":"allow"}`,
+			expected:   notification,
+		},
+		{
+			name:       "sends the redacted content",
+			statusCode: http.StatusOK,
+			response:   `{"action":"redact","title":"Order shipped","message":"Call [REDACTED]"}`,
+			expected: Notification{
+				RecipientType: recipientTypeBuyer,
+				To:            "buyer@example.com",
+				Title:         "Order shipped",
+				Message:       "Call [REDACTED]",
+			},
+		},
+		{
+			name:              "rejects the content with the reason of the service",
+			statusCode:        http.StatusOK,
+			response:          `{"action":"reject","reason":"phone number"}`,
+			expectedRejection: true,
+		},
+		{
+			name:          "fails on an unknown action",
+			statusCode:    http.StatusOK,
+			response:      `{"action":"maybe"}`,
+			expectedError: true,
+		},
+		{
+			name:          "fails when the service fails",
+			statusCode:    http.StatusServiceUnavailable,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req CalloutRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, CalloutRequest{
+					RecipientType: recipientTypeBuyer,
+					To:            "buyer@example.com",
+					Title:         "Order shipped",
+					Message:       "Call 081-234-5678",
+				}, req)
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			hook := NewCalloutHook(CalloutConfig{URL: server.URL})
+			checked, err := hook.Check(context.Background(), notification)
+
+			switch {
+			case tt.expectedRejection:
+				var rejectedErr *ContentRejectedError
+				require.True(t, errors.As(err, &rejectedErr))
+				assert.Equal(t, "phone number", rejectedErr.Reason)
+			case tt.expectedError:
+				require.Error(t, err)
+				assert.False(t, errors.As(err, new(*ContentRejectedError)))
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, checked)
+			}
+		})
+	}
+}
+
+func TestCalloutHook_Check_WithoutURL(t *testing.T) {
+	notification := Notification{Title: "Order shipped"}
+
+	checked, err := NewCalloutHook(CalloutConfig{}).Check(context.Background(), notification)
+
+	require.NoError(t, err)
+	assert.Equal(t, notification, checked)
+}
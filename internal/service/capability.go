@@ -0,0 +1,125 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+// Capabilities describes what a channel accepts, so requests can be
+// rejected up front instead of by the vendor
+type Capabilities struct {
+	SupportsHTML        bool
+	SupportsAttachments bool
+	SupportsActions     bool
+	SupportsImages      bool
+	MaxTitleLength      int
+	MaxMessageLength    int
+	// MaxAttachmentSize bounds the decoded size of all inline attachments in bytes
+	MaxAttachmentSize int
+}
+
+var providerCapabilities = map[repository.NotificationProvider]Capabilities{
+	repository.EmailProvider: {
+		SupportsHTML:        true,
+		SupportsAttachments: true,
+		SupportsActions:     false,
+		SupportsImages:      false,
+		// RFC 5322 limits a header line, including the subject, to 998 characters
+		MaxTitleLength:   998,
+		MaxMessageLength: 100000,
+		// Matches the 10MB message limit common to transactional email vendors
+		MaxAttachmentSize: 10 << 20,
+	},
+	repository.PushNotificationProvider: {
+		SupportsHTML:        false,
+		SupportsAttachments: false,
+		SupportsActions:     true,
+		SupportsImages:      true,
+		// Keeps the whole payload within the 4KB limit of APNs and FCM
+		MaxTitleLength:   256,
+		MaxMessageLength: 2048,
+	},
+	repository.InAppProvider: {
+		SupportsHTML:        false,
+		SupportsAttachments: false,
+		SupportsActions:     true,
+		SupportsImages:      true,
+		// Stored in Postgres and listed in the app, so only kept short enough
+		// for a notification feed
+		MaxTitleLength:   256,
+		MaxMessageLength: 4096,
+	},
+}
+
+// CapabilitiesFor returns the capabilities of the given provider kind
+func CapabilitiesFor(providerType repository.NotificationProvider) (Capabilities, bool) {
+	capabilities, ok := providerCapabilities[providerType]
+	return capabilities, ok
+}
+
+type CapabilityError struct {
+	Channel string
+	Reason  string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("provider '%s' does not support the request: %s", e.Channel, e.Reason)
+}
+
+// validateCapabilities checks req against every channel that will deliver it
+func validateCapabilities(req client.NotificationRequest, channels ...Channel) error {
+	for _, channel := range channels {
+		capabilities := channel.Capabilities()
+
+		if length := utf8.RuneCountInString(req.Title); length > capabilities.MaxTitleLength {
+			return &CapabilityError{
+				Channel: channel.Name(),
+				Reason:  fmt.Sprintf("title is %d characters, maximum is %d", length, capabilities.MaxTitleLength),
+			}
+		}
+
+		if length := utf8.RuneCountInString(req.Message); length > capabilities.MaxMessageLength {
+			return &CapabilityError{
+				Channel: channel.Name(),
+				Reason:  fmt.Sprintf("message is %d characters, maximum is %d", length, capabilities.MaxMessageLength),
+			}
+		}
+
+		if capabilities.SupportsAttachments {
+			size, err := inlineAttachmentSize(req.Attachments)
+			if err != nil {
+				return &CapabilityError{Channel: channel.Name(), Reason: err.Error()}
+			}
+			if size > capabilities.MaxAttachmentSize {
+				return &CapabilityError{
+					Channel: channel.Name(),
+					Reason:  fmt.Sprintf("attachments are %d bytes, maximum is %d", size, capabilities.MaxAttachmentSize),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// inlineAttachmentSize sums the decoded size of base64 attachments; URL
+// attachments are fetched by the provider and not counted
+func inlineAttachmentSize(attachments []client.Attachment) (int, error) {
+	var size int
+	for _, attachment := range attachments {
+		if attachment.Content == "" {
+			continue
+		}
+
+		content, err := base64.StdEncoding.DecodeString(attachment.Content)
+		if err != nil {
+			return 0, fmt.Errorf("attachment '%s' is not valid base64", attachment.Filename)
+		}
+		size += len(content)
+	}
+	return size, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilitiesFor(t *testing.T) {
+	email, ok := CapabilitiesFor(repository.EmailProvider)
+	require.True(t, ok)
+	assert.True(t, email.SupportsHTML)
+
+	push, ok := CapabilitiesFor(repository.PushNotificationProvider)
+	require.True(t, ok)
+	assert.False(t, push.SupportsHTML)
+	assert.Less(t, push.MaxMessageLength, email.MaxMessageLength)
+
+	_, ok = CapabilitiesFor(repository.NotificationProvider(99))
+	assert.False(t, ok)
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           client.NotificationRequest
+		providerTypes []repository.NotificationProvider
+		expectError   bool
+		expectedError string
+	}{
+		{
+			name: "fits every provider",
+			req: client.NotificationRequest{
+				Title:   "Order shipped",
+				Message: "Your order is on its way",
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider, repository.PushNotificationProvider},
+		},
+		{
+			name: "long message fits email only",
+			req: client.NotificationRequest{
+				Title:   "Weekly summary",
+				Message: strings.Repeat("a", 5000),
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider},
+		},
+		{
+			name: "long message rejected by push",
+			req: client.NotificationRequest{
+				Title:   "Weekly summary",
+				Message: strings.Repeat("a", 5000),
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider, repository.PushNotificationProvider},
+			expectError:   true,
+			expectedError: "provider 'PushNotification' does not support the request: message is 5000 characters, maximum is 2048",
+		},
+		{
+			name: "title length counts characters not bytes",
+			req: client.NotificationRequest{
+				Title:   strings.Repeat("ส", 256),
+				Message: "ok",
+			},
+			providerTypes: []repository.NotificationProvider{repository.PushNotificationProvider},
+		},
+		{
+			name: "long title rejected by push",
+			req: client.NotificationRequest{
+				Title:   strings.Repeat("a", 257),
+				Message: "ok",
+			},
+			providerTypes: []repository.NotificationProvider{repository.PushNotificationProvider},
+			expectError:   true,
+			expectedError: "provider 'PushNotification' does not support the request: title is 257 characters, maximum is 256",
+		},
+		{
+			name: "inline attachments within email limit",
+			req: client.NotificationRequest{
+				Title:   "Invoice",
+				Message: "Attached",
+				Attachments: []client.Attachment{
+					{Filename: "invoice.pdf", Content: base64.StdEncoding.EncodeToString(make([]byte, 1024))},
+					{Filename: "terms.pdf", URL: "https://cdn.example.com/terms.pdf"},
+				},
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider},
+		},
+		{
+			name: "inline attachments over email limit",
+			req: client.NotificationRequest{
+				Title:   "Invoice",
+				Message: "Attached",
+				Attachments: []client.Attachment{
+					{Filename: "a.bin", Content: base64.StdEncoding.EncodeToString(make([]byte, 6<<20))},
+					{Filename: "b.bin", Content: base64.StdEncoding.EncodeToString(make([]byte, 5<<20))},
+				},
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider},
+			expectError:   true,
+			expectedError: "provider 'Email' does not support the request: attachments are 11534336 bytes, maximum is 10485760",
+		},
+		{
+			name: "attachments are not checked for kinds that drop them",
+			req: client.NotificationRequest{
+				Title:       "Invoice",
+				Message:     "Attached",
+				Attachments: []client.Attachment{{Filename: "a.bin", Content: base64.StdEncoding.EncodeToString(make([]byte, 11<<20))}},
+			},
+			providerTypes: []repository.NotificationProvider{repository.PushNotificationProvider},
+		},
+		{
+			name: "invalid base64 attachment",
+			req: client.NotificationRequest{
+				Title:       "Invoice",
+				Message:     "Attached",
+				Attachments: []client.Attachment{{Filename: "a.bin", Content: "not base64!"}},
+			},
+			providerTypes: []repository.NotificationProvider{repository.EmailProvider},
+			expectError:   true,
+			expectedError: "provider 'Email' does not support the request: attachment 'a.bin' is not valid base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channels := make([]Channel, 0, len(tt.providerTypes))
+			for _, providerType := range tt.providerTypes {
+				channels = append(channels, newProviderChannel(providerType, ProviderChannelParams{}))
+			}
+
+			err := validateCapabilities(tt.req, channels...)
+
+			if !tt.expectError {
+				require.NoError(t, err)
+				return
+			}
+
+			var capabilityErr *CapabilityError
+			require.ErrorAs(t, err, &capabilityErr)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.uber.org/fx"
+)
+
+// Channel delivers notifications of one kind, e.g. email or push. Channels
+// are provided to the "channels" fx group and picked by the provider_type of
+// the routes of a recipient type, so a new kind such as SMS, chat or in-app
+// is added by registering it, without changing Send
+type Channel interface {
+	// Name is the provider_type routing notifications to the channel
+	Name() string
+	// Capabilities describes the content the channel delivers; requests
+	// exceeding it are rejected before any channel sends
+	Capabilities() Capabilities
+	// Validate rejects a recipient address the channel cannot deliver to
+	Validate(recipient string) error
+	// Send delivers the notification. Channels calling providers report
+	// each request with RecordAttempt and the provider accepting it with
+	// RecordProvider; a failed Send without attempts is reported as safe
+	// to retry
+	Send(ctx context.Context, notification Notification) error
+}
+
+// AsChannel annotates a channel constructor so fx adds the channel to the
+// "channels" group, e.g. fx.Provide(service.AsChannel(NewSMSChannel))
+func AsChannel(constructor any) any {
+	return fx.Annotate(
+		constructor,
+		fx.As(new(Channel)),
+		fx.ResultTags(`group:"channels"`),
+	)
+}
+
+// RecipientError is returned when a channel routed to the recipient type
+// cannot deliver to the recipient address
+type RecipientError struct {
+	Channel   string
+	Recipient string
+	Reason    string
+}
+
+func (e *RecipientError) Error() string {
+	return fmt.Sprintf("recipient '%s' is not valid for channel '%s': %s", e.Recipient, e.Channel, e.Reason)
+}
+
+type ChannelConfig struct {
+	// Optional lists the channels skipped, rather than failing the
+	// notification, while none of their providers is configured
+	Optional []string `envconfig:"OPTIONAL_CHANNELS" default:"PushNotification"`
+}
+
+func (c ChannelConfig) optional(channel string) bool {
+	return slices.Contains(c.Optional, channel)
+}
+
+type attemptLogKey struct{}
+
+// attemptLog collects the provider attempts a channel makes while sending
+// one notification
+type attemptLog struct {
+	mu     sync.Mutex
+	result channelResult
+}
+
+// RecordAttempt reports the outcome of one request a channel made to a
+// provider while sending, so the delivery report counts the attempts and
+// tells whether a failed notification may still have been delivered
+func RecordAttempt(ctx context.Context, err error) {
+	log, ok := ctx.Value(attemptLogKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.result = log.result.record(err)
+}
+
+// RecordProvider reports the provider that accepted the notification, so
+// the delivery report tells callers which one handled the channel
+func RecordProvider(ctx context.Context, name string, host string) {
+	log, ok := ctx.Value(attemptLogKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.result.providerName = name
+	log.result.providerHost = host
+}
+
+// sendToChannel sends the notification on one channel and collects the
+// attempts it made
+func sendToChannel(ctx context.Context, channel Channel, notification Notification) (channelResult, error) {
+	log := &attemptLog{}
+	err := channel.Send(context.WithValue(ctx, attemptLogKey{}, log), notification)
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	result := log.result
+	result.channel = channel.Name()
+	result.delivered = err == nil
+	return result, err
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+// fakeChannel records what it was asked to send and reports the configured
+// attempts
+type fakeChannel struct {
+	name        string
+	validateErr error
+	attempts    []error
+	sendErr     error
+	provider    string
+	sent        []Notification
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Capabilities() Capabilities {
+	return Capabilities{MaxTitleLength: 64, MaxMessageLength: 160}
+}
+
+func (c *fakeChannel) Validate(string) error { return c.validateErr }
+
+func (c *fakeChannel) Send(ctx context.Context, notification Notification) error {
+	c.sent = append(c.sent, notification)
+	for _, err := range c.attempts {
+		RecordAttempt(ctx, err)
+	}
+	if c.provider != "" {
+		RecordProvider(ctx, c.provider, "https://"+c.provider+".example.com")
+	}
+	return c.sendErr
+}
+
+func TestSendToChannel(t *testing.T) {
+	providerErr := &client.ProviderError{StatusCode: 429}
+
+	tests := []struct {
+		name     string
+		channel  *fakeChannel
+		expected channelResult
+	}{
+		{
+			name:     "delivered after a failed attempt",
+			channel:  &fakeChannel{name: "SMS", attempts: []error{providerErr, nil}},
+			expected: channelResult{channel: "SMS", attempts: 2, delivered: true},
+		},
+		{
+			name:     "delivered by a recorded provider",
+			channel:  &fakeChannel{name: "SMS", attempts: []error{nil}, provider: "twilio"},
+			expected: channelResult{channel: "SMS", attempts: 1, delivered: true, providerName: "twilio", providerHost: "https://twilio.example.com"},
+		},
+		{
+			name:     "failed attempt that may have reached the provider",
+			channel:  &fakeChannel{name: "SMS", attempts: []error{errors.New("read timeout")}, sendErr: errors.New("read timeout")},
+			expected: channelResult{channel: "SMS", attempts: 1, ambiguous: true},
+		},
+		{
+			name:     "failed without attempts",
+			channel:  &fakeChannel{name: "SMS", sendErr: errors.New("no provider")},
+			expected: channelResult{channel: "SMS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sendToChannel(context.Background(), tt.channel, Notification{To: "+66800000000"})
+
+			assert.Equal(t, tt.channel.sendErr, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRecordAttempt_WithoutAttemptLog(t *testing.T) {
+	assert.NotPanics(t, func() {
+		RecordAttempt(context.Background(), nil)
+		RecordProvider(context.Background(), "twilio", "https://twilio.example.com")
+	})
+}
+
+func TestNotificationService_Send_RegisteredChannel(t *testing.T) {
+	newService := func(ctrl *gomock.Controller, t *testing.T, channel Channel) *NotificationService {
+		routeCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+		routeCache.EXPECT().Get("courier").Return([]repository.NotificationRoute{
+			{RecipientType: "courier", ProviderType: "SMS"},
+		}, nil)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		return NewNotificationService(NotificationServiceParams{
+			MetricsCollector: metricsCollector,
+			IDGenerator:      newTestIDGenerator(ctrl),
+			Dispatcher:       newTestDispatcher(t),
+			Suppressions:     newTestSuppressions(ctrl),
+			SuppressionCache: newTestSuppressionCache(ctrl),
+			Consents:         newTestConsents(ctrl),
+			ConsentCache:     newTestConsentCache(ctrl),
+			Quotas:           newTestQuotas(ctrl),
+			RouteCache:       routeCache,
+			Channels:         []Channel{channel},
+		})
+	}
+
+	t.Run("delivers on a channel routed by name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		channel := &fakeChannel{name: "SMS", attempts: []error{nil}}
+		service := newService(ctrl, t, channel)
+
+		report, err := service.Send(context.Background(), "courier", Notification{To: "+66800000000", Title: "Test", Message: "Test message"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SMS"}, report.Channels)
+		assert.Equal(t, 1, report.Attempts)
+		require.Len(t, channel.sent, 1)
+		assert.Equal(t, testNotificationID, channel.sent[0].ID)
+		assert.Equal(t, "courier", channel.sent[0].RecipientType)
+	})
+
+	t.Run("rejects a recipient the channel cannot deliver to", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		channel := &fakeChannel{
+			name:        "SMS",
+			validateErr: &RecipientError{Channel: "SMS", Recipient: "courier@example.com", Reason: "not a phone number"},
+		}
+		service := newService(ctrl, t, channel)
+
+		report, err := service.Send(context.Background(), "courier", Notification{To: "courier@example.com", Title: "Test", Message: "Test message"})
+
+		var recipientErr *RecipientError
+		require.ErrorAs(t, err, &recipientErr)
+		assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+		assert.Empty(t, channel.sent)
+	})
+}
+
+func TestNotificationService_SkipsUnconfiguredChannels(t *testing.T) {
+	tests := []struct {
+		name               string
+		config             ChannelConfig
+		emailPreferences   []repository.NotificationPreference
+		expectError        bool
+		expectedDeliveries []ChannelDelivery
+		expectedLogs       int
+	}{
+		{
+			name:             "skips optional push without providers",
+			config:           ChannelConfig{Optional: []string{"PushNotification"}},
+			emailPreferences: []repository.NotificationPreference{{Host: "https://email.example.com", ProviderName: "email"}},
+			expectedDeliveries: []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusDelivered, ProviderName: "email", ProviderHost: "https://email.example.com", Attempts: 1},
+				{Channel: "PushNotification", Status: DeliveryStatusSkipped},
+			},
+			expectedLogs: 1,
+		},
+		{
+			name:             "fails when push is not optional",
+			emailPreferences: []repository.NotificationPreference{{Host: "https://email.example.com", ProviderName: "email"}},
+			expectError:      true,
+			expectedDeliveries: []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusDelivered, ProviderName: "email", ProviderHost: "https://email.example.com", Attempts: 1},
+				{Channel: "PushNotification", Status: DeliveryStatusFailed},
+			},
+		},
+		{
+			name:        "fails when every channel is skipped",
+			config:      ChannelConfig{Optional: []string{"Email", "PushNotification"}},
+			expectError: true,
+			expectedDeliveries: []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusSkipped},
+				{Channel: "PushNotification", Status: DeliveryStatusSkipped},
+			},
+			expectedLogs: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, errors.New("cache miss")).Times(2)
+			if tt.emailPreferences != nil {
+				mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(tt.emailPreferences, nil)
+				mockCache.EXPECT().Set(repository.EmailProvider, tt.emailPreferences).Return(nil)
+				mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.example.com", gomock.Any()).Return(nil)
+			} else {
+				mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound)
+				mockCache.EXPECT().Set(repository.EmailProvider, []repository.NotificationPreference{}).Return(nil)
+			}
+			mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound)
+			mockCache.EXPECT().Set(repository.PushNotificationProvider, []repository.NotificationPreference{}).Return(nil)
+
+			core, logs := observer.New(zap.WarnLevel)
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				ChannelConfig:    tt.config,
+				Logger:           zap.New(core),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockCache,
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockHTTPClient,
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeSeller, Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting"})
+
+			if tt.expectError {
+				var noProviderErr *NoProviderError
+				require.ErrorAs(t, err, &noProviderErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedDeliveries, report.Deliveries)
+			assert.Equal(t, tt.expectedLogs, logs.FilterMessage("skipped optional channel without providers").Len())
+		})
+	}
+}
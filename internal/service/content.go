@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ContentKeeper writes the contents kept for replays in the background, so
+// Send does not wait on the insert, and purges those older than the
+// retention. Contents arriving while the buffer is full are dropped and
+// counted, as are those still buffered when the process dies
+type ContentKeeper struct {
+	config   ReplayConfig
+	contents repository.NotificationContentProvider
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	queue   chan repository.NotificationContent
+	dropped atomic.Int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type ContentKeeperParams struct {
+	fx.In
+
+	Config   ReplayConfig
+	Contents repository.NotificationContentProvider
+	Clock    clock.Clock
+	Logger   *zap.Logger
+}
+
+// NewContentKeeper writes and purges with the application while replays
+// are enabled; otherwise it keeps nothing
+func NewContentKeeper(lc fx.Lifecycle, params ContentKeeperParams) (*ContentKeeper, error) {
+	if !params.Config.Enabled {
+		return &ContentKeeper{}, nil
+	}
+	if err := params.Config.validate(); err != nil {
+		return nil, err
+	}
+
+	keeper := NewContentKeeperWithConfig(params.Config, params.Contents, params.Clock, params.Logger)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			keeper.Start()
+			return nil
+		},
+		OnStop: keeper.Stop,
+	})
+
+	return keeper, nil
+}
+
+func NewContentKeeperWithConfig(config ReplayConfig, contents repository.NotificationContentProvider, clock clock.Clock, logger *zap.Logger) *ContentKeeper {
+	return &ContentKeeper{
+		config:   config,
+		contents: contents,
+		clock:    clock,
+		logger:   logger,
+		queue:    make(chan repository.NotificationContent, config.BufferSize),
+	}
+}
+
+func (c ReplayConfig) validate() error {
+	if c.Retention <= 0 {
+		return errors.New("replay: REPLAY_RETENTION must be positive")
+	}
+	if c.PurgeInterval <= 0 {
+		return errors.New("replay: REPLAY_PURGE_INTERVAL must be positive")
+	}
+	if c.BufferSize < 1 {
+		return errors.New("replay: REPLAY_BUFFER_SIZE must be at least 1")
+	}
+	return nil
+}
+
+// Keep queues the content to be written; it never blocks
+func (k *ContentKeeper) Keep(content repository.NotificationContent) {
+	if k.queue == nil {
+		return
+	}
+
+	select {
+	case k.queue <- content:
+	default:
+		k.dropped.Add(1)
+	}
+}
+
+// Start writes the queued contents and purges every purge interval, until
+// Stop
+func (k *ContentKeeper) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	k.cancel = cancel
+
+	k.wg.Add(2)
+	go func() {
+		defer k.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case content := <-k.queue:
+				k.write(context.WithoutCancel(ctx), content)
+			}
+		}
+	}()
+	go func() {
+		defer k.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-k.clock.After(k.config.PurgeInterval):
+			}
+
+			k.Purge(ctx)
+		}
+	}()
+}
+
+// Stop ends the writes and purges and writes what is still queued, until
+// ctx ends
+func (k *ContentKeeper) Stop(ctx context.Context) error {
+	if k.queue == nil {
+		return nil
+	}
+	if k.cancel != nil {
+		k.cancel()
+		k.wg.Wait()
+	}
+
+	for {
+		select {
+		case content := <-k.queue:
+			k.write(ctx, content)
+		default:
+			return ctx.Err()
+		}
+	}
+}
+
+// write stores the content. A failed write is logged by the repository and
+// only costs the replay of that notification
+func (k *ContentKeeper) write(ctx context.Context, content repository.NotificationContent) {
+	if dropped := k.dropped.Swap(0); dropped > 0 {
+		k.logger.Warn("dropped notification contents, the replay buffer was full",
+			zap.Int64("dropped", dropped),
+		)
+	}
+
+	_ = k.contents.StoreNotificationContent(ctx, content)
+}
+
+// Purge deletes the contents kept longer than the retention
+func (k *ContentKeeper) Purge(ctx context.Context) {
+	before := k.clock.Now().Add(-k.config.Retention)
+
+	purged, err := k.contents.PurgeNotificationContents(ctx, before)
+	if err != nil {
+		return
+	}
+	if purged > 0 {
+		k.logger.Info("purged notification contents",
+			zap.Int64("purged", purged),
+			zap.Time("before", before),
+		)
+	}
+}
+
+// keptAttachments references the attachments of a kept notification: URL
+// attachments are kept as they are, inline ones without their content, so
+// rows do not grow with the attachments
+func keptAttachments(attachments []Attachment) []Attachment {
+	if len(attachments) == 0 {
+		return attachments
+	}
+
+	kept := make([]Attachment, len(attachments))
+	for i, attachment := range attachments {
+		attachment.Content = ""
+		kept[i] = attachment
+	}
+	return kept
+}
+
+// inlineAttachmentsDropped is true when a kept notification had inline
+// attachments, whose content was not kept
+func inlineAttachmentsDropped(attachments []Attachment) bool {
+	for _, attachment := range attachments {
+		if attachment.URL == "" && attachment.Content == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var testReplayConfig = ReplayConfig{
+	Enabled:       true,
+	Retention:     720 * time.Hour,
+	PurgeInterval: time.Hour,
+	BufferSize:    2,
+}
+
+func TestReplayConfig_validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*ReplayConfig)
+		expectedError string
+	}{
+		{name: "accepts the defaults", modify: func(*ReplayConfig) {}},
+		{name: "refuses no retention", modify: func(c *ReplayConfig) { c.Retention = 0 }, expectedError: "REPLAY_RETENTION"},
+		{name: "refuses no purge interval", modify: func(c *ReplayConfig) { c.PurgeInterval = 0 }, expectedError: "REPLAY_PURGE_INTERVAL"},
+		{name: "refuses an empty buffer", modify: func(c *ReplayConfig) { c.BufferSize = 0 }, expectedError: "REPLAY_BUFFER_SIZE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testReplayConfig
+			tt.modify(&config)
+
+			err := config.validate()
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestContentKeeper_Keep(t *testing.T) {
+	t.Run("drops contents beyond the buffer and writes the rest on stop", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		contents := mockrepository.NewMockNotificationContentProvider(ctrl)
+		core, logs := observer.New(zap.WarnLevel)
+		keeper := NewContentKeeperWithConfig(testReplayConfig, contents, mockclock.NewMockClock(ctrl), zap.New(core))
+
+		var written []string
+		contents.EXPECT().StoreNotificationContent(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, content repository.NotificationContent) error {
+				written = append(written, content.NotificationID)
+				return nil
+			}).Times(2)
+
+		for _, id := range []string{"a", "b", "c"} {
+			keeper.Keep(repository.NotificationContent{NotificationID: id})
+		}
+		require.NoError(t, keeper.Stop(context.Background()))
+
+		assert.Equal(t, []string{"a", "b"}, written)
+		require.Equal(t, 1, logs.Len())
+		assert.Equal(t, int64(1), logs.All()[0].ContextMap()["dropped"])
+	})
+
+	t.Run("keeps nothing while replays are disabled", func(t *testing.T) {
+		keeper, err := NewContentKeeper(nil, ContentKeeperParams{Config: ReplayConfig{}})
+		require.NoError(t, err)
+
+		keeper.Keep(repository.NotificationContent{NotificationID: "a"})
+
+		require.NoError(t, keeper.Stop(context.Background()))
+	})
+}
+
+func TestContentKeeper_Purge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		purgeErr     error
+		expectedLogs int
+	}{
+		{name: "deletes the contents older than the retention", expectedLogs: 1},
+		{name: "leaves a failed purge to the next interval", purgeErr: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clock := mockclock.NewMockClock(ctrl)
+			clock.EXPECT().Now().Return(now)
+			contents := mockrepository.NewMockNotificationContentProvider(ctrl)
+			contents.EXPECT().PurgeNotificationContents(gomock.Any(), now.Add(-720*time.Hour)).Return(int64(3), tt.purgeErr)
+			core, logs := observer.New(zap.InfoLevel)
+			keeper := NewContentKeeperWithConfig(testReplayConfig, contents, clock, zap.New(core))
+
+			keeper.Purge(context.Background())
+
+			assert.Equal(t, tt.expectedLogs, logs.Len())
+		})
+	}
+}
+
+func TestKeptAttachments(t *testing.T) {
+	attachments := []Attachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf", Content: "JVBERi0="},
+		{Filename: "label.pdf", URL: "https://cdn.example.com/label.pdf"},
+	}
+
+	kept := keptAttachments(attachments)
+
+	assert.Equal(t, []Attachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf"},
+		{Filename: "label.pdf", URL: "https://cdn.example.com/label.pdf"},
+	}, kept)
+	assert.Equal(t, "JVBERi0=", attachments[0].Content, "the sent notification is left as it is")
+	assert.True(t, inlineAttachmentsDropped(kept))
+	assert.False(t, inlineAttachmentsDropped(attachments))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/mockprovider"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdaptPayload_ProviderContract checks that every payload shape sent to a
+// provider kind matches the contract the mock provider enforces
+func TestAdaptPayload_ProviderContract(t *testing.T) {
+	decoders := map[repository.NotificationProvider]func(*bytes.Reader) error{
+		repository.EmailProvider: func(r *bytes.Reader) error {
+			_, err := mockprovider.DecodeEmail(r)
+			return err
+		},
+		repository.PushNotificationProvider: func(r *bytes.Reader) error {
+			_, err := mockprovider.DecodePush(r)
+			return err
+		},
+	}
+
+	requests := []struct {
+		name string
+		req  client.NotificationRequest
+	}{
+		{
+			name: "plain",
+			req: client.NotificationRequest{
+				ID:        "01J",
+				To:        "buyer@example.com",
+				Title:     "Order shipped",
+				Message:   "On its way",
+				SecretKey: "secret",
+			},
+		},
+		{
+			name: "threaded rich content",
+			req: client.NotificationRequest{
+				ID:        "01J",
+				To:        "buyer@example.com",
+				Title:     "Order shipped",
+				Message:   "On its way",
+				SecretKey: "secret",
+				ThreadKey: "order-42",
+				HTML:      "<p>On its way</p>",
+				Attachments: []client.Attachment{
+					{Filename: "invoice.pdf", ContentType: "application/pdf", URL: "https://cdn.example.com/invoice.pdf"},
+					{Filename: "note.txt", Content: "aGVsbG8="},
+				},
+				DeepLink: "app://orders/42",
+				ImageURL: "https://cdn.example.com/parcel.png",
+			},
+		},
+		{
+			name: "forwarded metadata",
+			req: client.NotificationRequest{
+				ID:        "01J",
+				To:        "buyer@example.com",
+				Title:     "Order shipped",
+				Message:   "On its way",
+				SecretKey: "secret",
+				Metadata:  map[string]string{"order_id": "42"},
+			},
+		},
+	}
+
+	for providerType, decode := range decoders {
+		for _, tt := range requests {
+			t.Run(providerType.String()+"/"+tt.name, func(t *testing.T) {
+				body, err := json.Marshal(adaptPayload(tt.req, providerType))
+				require.NoError(t, err)
+
+				require.NoError(t, decode(bytes.NewReader(body)))
+			})
+		}
+	}
+}
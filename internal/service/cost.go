@@ -0,0 +1,15 @@
+package service
+
+type CostConfig struct {
+	// PerMessage maps a provider, by the provider_name of its preferences, to
+	// the estimated price of one notification it accepts
+	PerMessage map[string]float64 `envconfig:"COST_PER_MESSAGE"`
+	// Currency the costs are in, reported next to cost summaries
+	Currency string `envconfig:"COST_CURRENCY" default:"USD"`
+}
+
+// Cost returns the estimated price of a notification accepted by the
+// provider; providers without a configured cost are free
+func (c CostConfig) Cost(providerName string) float64 {
+	return c.PerMessage[providerName]
+}
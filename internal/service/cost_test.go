@@ -0,0 +1,14 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostConfig_Cost(t *testing.T) {
+	config := CostConfig{PerMessage: map[string]float64{"Provider1": 0.0008}}
+
+	assert.Equal(t, 0.0008, config.Cost("Provider1"))
+	assert.Zero(t, config.Cost("Provider2"), "providers without a cost are free")
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+type DigestConfig struct {
+	// Interval enables digests: low priority notifications are buffered and
+	// sent combined, one notification per recipient every interval
+	Interval time.Duration `envconfig:"DIGEST_INTERVAL" default:"0s"`
+	// RecipientTypes limits digests to these recipient types; every type is
+	// digested when empty
+	RecipientTypes []string `envconfig:"DIGEST_RECIPIENT_TYPES"`
+	// MaxItems is the number of notifications a digest lists by title; the
+	// others are only counted
+	MaxItems int `envconfig:"DIGEST_MAX_ITEMS" default:"10"`
+}
+
+// Digested tells whether the notification waits for the digest of its
+// recipient instead of being sent right away
+func (c DigestConfig) Digested(recipientType string, notification Notification) bool {
+	// A digest is delivered on every routed channel, so notifications
+	// restricted to some of them are sent right away; a digest is sent for
+	// the recipient, not an API key, so sandbox notifications are as well
+	if c.Interval <= 0 || notification.Priority != dispatch.PriorityLow || len(notification.Channels) > 0 || notification.Sandbox {
+		return false
+	}
+	return len(c.RecipientTypes) == 0 || slices.Contains(c.RecipientTypes, recipientType)
+}
+
+// digest buffers the notification for the next digest of its recipient.
+// Only the title, message and deep link are kept
+func (s *NotificationService) digest(
+	ctx context.Context,
+	id string,
+	recipientType string,
+	notification Notification,
+) (DeliveryReport, error) {
+	report := DeliveryReport{ID: id}
+
+	err := s.digests.AddDigestItem(ctx, repository.DigestItem{
+		NotificationID: id,
+		RecipientType:  recipientType,
+		Recipient:      notification.To,
+		Category:       notification.Category,
+		Title:          notification.Title,
+		Message:        notification.Message,
+		DeepLink:       notification.DeepLink,
+	})
+	if err != nil {
+		return report.finish(err), err
+	}
+
+	s.metricsCollector.RecordDigested(ctx, recipientType)
+
+	report.RetryDisposition = RetryNotNeeded
+	report.Digested = true
+	return report, nil
+}
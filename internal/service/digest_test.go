@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDigestConfig_Digested(t *testing.T) {
+	low := Notification{Priority: dispatch.PriorityLow}
+
+	tests := []struct {
+		name          string
+		config        DigestConfig
+		recipientType string
+		notification  Notification
+		expected      bool
+	}{
+		{
+			name:          "digests low priority notifications of listed recipient types",
+			config:        DigestConfig{Interval: time.Hour, RecipientTypes: []string{recipientTypeSeller}},
+			recipientType: recipientTypeSeller,
+			notification:  low,
+			expected:      true,
+		},
+		{
+			name:          "digests every recipient type when none is listed",
+			config:        DigestConfig{Interval: time.Hour},
+			recipientType: recipientTypeBuyer,
+			notification:  low,
+			expected:      true,
+		},
+		{
+			name:          "sends other recipient types",
+			config:        DigestConfig{Interval: time.Hour, RecipientTypes: []string{recipientTypeSeller}},
+			recipientType: recipientTypeBuyer,
+			notification:  low,
+		},
+		{
+			name:          "sends other priorities",
+			config:        DigestConfig{Interval: time.Hour},
+			recipientType: recipientTypeSeller,
+			notification:  Notification{Priority: dispatch.PriorityNormal},
+		},
+		{
+			name:          "sends notifications restricted to some channels",
+			config:        DigestConfig{Interval: time.Hour},
+			recipientType: recipientTypeSeller,
+			notification:  Notification{Priority: dispatch.PriorityLow, Channels: []string{"Email"}},
+		},
+		{
+			name:          "sends sandbox notifications",
+			config:        DigestConfig{Interval: time.Hour},
+			recipientType: recipientTypeSeller,
+			notification:  Notification{Priority: dispatch.PriorityLow, Sandbox: true},
+		},
+		{
+			name:          "sends everything while disabled",
+			recipientType: recipientTypeSeller,
+			notification:  low,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.Digested(tt.recipientType, tt.notification))
+		})
+	}
+}
+
+func TestNotificationService_Send_Digest(t *testing.T) {
+	tests := []struct {
+		name                string
+		addErr              error
+		expectedError       bool
+		expectedDisposition string
+		expectedDigested    bool
+	}{
+		{
+			name:                "buffers the notification without sending it",
+			expectedDisposition: RetryNotNeeded,
+			expectedDigested:    true,
+		},
+		{
+			name:                "fails when the notification cannot be buffered",
+			addErr:              errors.New("database down"),
+			expectedError:       true,
+			expectedDisposition: RetrySafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDigests := mockrepository.NewMockDigestProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockDigests.EXPECT().AddDigestItem(gomock.Any(), repository.DigestItem{
+				NotificationID: testNotificationID,
+				RecipientType:  recipientTypeSeller,
+				Recipient:      "seller@example.com",
+				Category:       repository.CategoryReminder,
+				Title:          "New order",
+				Message:        "Order 42 is waiting",
+				DeepLink:       "app://orders/42",
+			}).Return(tt.addErr)
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Digests:          mockDigests,
+				DigestConfig:     DigestConfig{Interval: time.Hour, RecipientTypes: []string{recipientTypeSeller}, MaxItems: 10},
+				Channels: newTestChannels(ProviderChannelParams{
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeSeller, Notification{
+				To:       "seller@example.com",
+				Title:    "New order",
+				Message:  "Order 42 is waiting",
+				DeepLink: "app://orders/42",
+				Priority: dispatch.PriorityLow,
+				Category: repository.CategoryReminder,
+			})
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, testNotificationID, report.ID)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+			assert.Equal(t, tt.expectedDigested, report.Digested)
+			assert.Zero(t, report.Attempts)
+		})
+	}
+}
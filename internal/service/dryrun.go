@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"go.uber.org/zap"
+)
+
+// RedactedSecretKey stands in for the secret key of previewed requests
+const RedactedSecretKey = "[REDACTED]"
+
+// Previewer is implemented by channels able to tell what they would send
+// for a notification without sending it; a dry run lists no payloads for
+// other channels
+type Previewer interface {
+	Preview(ctx context.Context, notification Notification) ([]Payload, error)
+}
+
+// Payload is a request a channel would make for a notification. Provider
+// channels make one per provider, in the order they fall back through
+type Payload struct {
+	ProviderName string
+	Host         string
+	AuthMode     string
+	Request      client.NotificationRequest
+}
+
+// DryRunReport is what Send would do with a notification
+type DryRunReport struct {
+	// Digested tells the notification would wait for the digest of its
+	// recipient, which sends it combined with others
+	Digested bool
+	Channels []ChannelPreview
+}
+
+// ChannelPreview lists the payloads of one channel the notification would
+// be sent on; Skipped marks an optional channel without providers
+type ChannelPreview struct {
+	Channel  string
+	Skipped  bool
+	Payloads []Payload
+}
+
+// DryRun routes, localizes and checks the notification as Send does and
+// returns the payloads of the channels it would be sent on. Nothing is
+// sent, stored, counted against quotas or emitted, and the message id is
+// not claimed, so no id is assigned
+func (s *NotificationService) DryRun(ctx context.Context, recipientType string, notification Notification) (DryRunReport, error) {
+	ctx, cancel := s.budget.start(ctx)
+	defer cancel()
+	ctx = logging.With(ctx, zap.String(logging.FieldRecipientType, recipientType))
+
+	channels, err := s.getRoutedChannels(ctx, recipientType)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+
+	channels, err = selectChannels(recipientType, channels, notification.Channels)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+
+	notification, err = s.localize(ctx, notification)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+	notification = notification.normalized()
+
+	notification, err = s.hooks.Run(ctx, recipientType, notification)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+
+	channels, _, err = s.withoutSuppressed(ctx, notification.To, channels)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+
+	channels, _, err = s.withoutOptedOut(ctx, notification.Category, notification.To, channels)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+
+	for _, channel := range channels {
+		if err := channel.Validate(notification.To); err != nil {
+			return DryRunReport{}, err
+		}
+	}
+	if err := validateCapabilities(notification.providerRequest(), channels...); err != nil {
+		return DryRunReport{}, err
+	}
+
+	notification.RecipientType = recipientType
+
+	report := DryRunReport{
+		Digested: s.digestConfig.Digested(recipientType, notification),
+		Channels: make([]ChannelPreview, 0, len(channels)),
+	}
+	for _, channel := range channels {
+		preview := ChannelPreview{Channel: channel.Name()}
+
+		if previewer, ok := channel.(Previewer); ok {
+			preview.Payloads, err = previewer.Preview(ctx, notification)
+
+			var noProviderErr *NoProviderError
+			if errors.As(err, &noProviderErr) && s.channelConfig.optional(preview.Channel) {
+				preview.Skipped = true
+				err = nil
+			}
+			if err != nil {
+				return DryRunReport{}, err
+			}
+		}
+
+		report.Channels = append(report.Channels, preview)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	mockquota "github.com/koungkub/fw-challenge-notification-service/internal/quota/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_DryRun(t *testing.T) {
+	emailRequest := client.NotificationRequest{
+		To:      "seller@example.com",
+		Title:   "New order",
+		Message: "Order 42 is waiting",
+	}
+
+	tests := []struct {
+		name            string
+		recipientType   string
+		notification    Notification
+		suppressed      bool
+		expectedReport  DryRunReport
+		expectedErrorAs any
+	}{
+		{
+			name:          "lists the payload of every provider and skips unconfigured optional channels",
+			recipientType: recipientTypeSeller,
+			notification:  Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting", Metadata: map[string]string{"order_id": "42"}},
+			expectedReport: DryRunReport{
+				Channels: []ChannelPreview{
+					{
+						Channel: "Email",
+						Payloads: []Payload{
+							{
+								ProviderName: "sendgrid",
+								Host:         "https://email.com",
+								AuthMode:     repository.AuthModeSecretKey,
+								Request:      withSecretKey(emailRequest, RedactedSecretKey),
+							},
+							{
+								ProviderName: "mailgun",
+								Host:         "https://email-backup.com",
+								AuthMode:     repository.AuthModeJWT,
+								Request:      emailRequest,
+							},
+						},
+					},
+					{Channel: "PushNotification", Skipped: true},
+				},
+			},
+		},
+		{
+			name:          "keeps only the selected channels",
+			recipientType: recipientTypeSeller,
+			notification:  Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting", Channels: []string{"PushNotification"}},
+			expectedReport: DryRunReport{
+				Channels: []ChannelPreview{{Channel: "PushNotification", Skipped: true}},
+			},
+		},
+		{
+			name:            "refuses a suppressed address as Send does",
+			recipientType:   recipientTypeBuyer,
+			notification:    Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting"},
+			suppressed:      true,
+			expectedErrorAs: new(*SuppressionError),
+		},
+		{
+			name:            "refuses a channel not routed to the recipient type",
+			recipientType:   recipientTypeBuyer,
+			notification:    Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting", Channels: []string{"PushNotification"}},
+			expectedErrorAs: new(*ChannelSelectionError),
+		},
+		{
+			name:            "refuses an invalid email address",
+			recipientType:   recipientTypeBuyer,
+			notification:    Notification{To: "not-an-address", Title: "New order", Message: "Order 42 is waiting"},
+			expectedErrorAs: new(*RecipientError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockSuppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{ProviderName: "sendgrid", Host: "https://email.com", SecretKey: "secret"},
+				{ProviderName: "mailgun", Host: "https://email-backup.com", AuthMode: repository.AuthModeJWT, Priority: 1},
+			}, nil).AnyTimes()
+			mockCache.EXPECT().Get(repository.PushNotificationProvider).Return(nil, nil).AnyTimes()
+			mockSuppressions.EXPECT().IsSuppressed(gomock.Any(), gomock.Any()).Return(tt.suppressed, nil).AnyTimes()
+
+			// Nothing is sent or counted, so the client and quotas expect
+			// no calls
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				Suppressions:     mockSuppressions,
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           mockquota.NewMockLimiter(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				ChannelConfig:    ChannelConfig{Optional: []string{"PushNotification"}},
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockclient.NewMockHTTPClientProvider(ctrl),
+					MetricsCollector: metricsCollector,
+				}),
+			})
+
+			report, err := service.DryRun(context.Background(), tt.recipientType, tt.notification)
+
+			if tt.expectedErrorAs != nil {
+				require.Error(t, err)
+				assert.True(t, errors.As(err, tt.expectedErrorAs))
+				assert.Equal(t, DryRunReport{}, report)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedReport, report)
+		})
+	}
+}
+
+func TestInAppChannel_Preview(t *testing.T) {
+	channel := NewInAppChannel(InAppChannelParams{})
+
+	payloads, err := channel.Preview(context.Background(), Notification{
+		To:       "user-1",
+		Title:    "New order",
+		Message:  "Order 42 is waiting",
+		HTML:     "<p>Order 42</p>",
+		DeepLink: "app://orders/42",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Payload{{
+		Request: client.NotificationRequest{
+			To:       "user-1",
+			Title:    "New order",
+			Message:  "Order 42 is waiting",
+			DeepLink: "app://orders/42",
+		},
+	}}, payloads)
+}
+
+func withSecretKey(req client.NotificationRequest, secretKey string) client.NotificationRequest {
+	req.SecretKey = secretKey
+	return req
+}
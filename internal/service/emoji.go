@@ -0,0 +1,103 @@
+package service
+
+import (
+	"slices"
+	"unicode"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+)
+
+type EmojiConfig struct {
+	// Strip lists the channels whose providers are sent no emoji, e.g. for
+	// providers rejecting unusual code points
+	Strip []string `envconfig:"EMOJI_STRIP_CHANNELS"`
+	// Transliterate lists the channels whose providers are sent common emoji
+	// as text, e.g. :) for 🙂, and no other emoji; it wins over Strip
+	Transliterate []string `envconfig:"EMOJI_TRANSLITERATE_CHANNELS"`
+}
+
+// emojiText is the text common emoji are transliterated to
+var emojiText = map[rune]string{
+	'🙂': ":)", '😊': ":)", '😀': ":D", '😃': ":D", '😄': ":D", '😁': ":D",
+	'😂': ":'D", '😉': ";)", '🙁': ":(", '☹': ":(", '😞': ":(", '😢': ":'(",
+	'😮': ":O", '😛': ":P", '❤': "<3", '💔': "</3", '👍': "(y)", '👎': "(n)",
+	'✅': "[v]", '✔': "[v]", '❌': "[x]", '⭐': "*", '➡': "->", '⬅': "<-",
+}
+
+// emoji covers the pictographic blocks emoji are drawn from
+var emoji = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b55, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1},
+	},
+}
+
+// emojiComponent covers the code points joining and modifying emoji:
+// zero width joiner, variation selectors, keycap and tags
+var emojiComponent = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200d, Hi: 0x200d, Stride: 1},
+		{Lo: 0x20e3, Hi: 0x20e3, Stride: 1},
+		{Lo: 0xfe0e, Hi: 0xfe0f, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+	},
+}
+
+// adapt replaces or drops the emoji of the title, message and HTML as
+// configured for the channel
+func (c EmojiConfig) adapt(req client.NotificationRequest, channel string) client.NotificationRequest {
+	transliterate := slices.Contains(c.Transliterate, channel)
+	if !transliterate && !slices.Contains(c.Strip, channel) {
+		return req
+	}
+
+	req.Title = replaceEmoji(req.Title, transliterate)
+	req.Message = replaceEmoji(req.Message, transliterate)
+	req.HTML = replaceEmoji(req.HTML, transliterate)
+	return req
+}
+
+// replaceEmoji drops every emoji sequence of text, or replaces it with the
+// text of its first emoji when transliterating and it has one. A dropped
+// sequence takes one of the spaces around it along, so words stay one
+// space apart
+func replaceEmoji(text string, transliterate bool) string {
+	runes := []rune(text)
+	out := make([]rune, 0, len(runes))
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !unicode.In(r, emoji, emojiComponent) {
+			out = append(out, r)
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && (unicode.Is(emojiComponent, runes[end]) ||
+			runes[end-1] == '\u200d' && unicode.Is(emoji, runes[end])) {
+			end++
+		}
+		i = end - 1
+
+		if replacement, ok := emojiText[r]; ok && transliterate {
+			out = append(out, []rune(replacement)...)
+			continue
+		}
+
+		spaceBefore := len(out) == 0 || out[len(out)-1] == ' '
+		switch {
+		case spaceBefore && end < len(runes) && runes[end] == ' ':
+			i++
+		case end == len(runes) && len(out) > 0 && out[len(out)-1] == ' ':
+			out = out[:len(out)-1]
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceEmoji(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		transliterate bool
+		expected      string
+	}{
+		{name: "drops an emoji between words", text: "Order 🚚 shipped", expected: "Order shipped"},
+		{name: "drops a trailing emoji", text: "Order shipped 🚚", expected: "Order shipped"},
+		{name: "drops a leading emoji", text: "🎉 Order shipped", expected: "Order shipped"},
+		{name: "drops a joined sequence", text: "Hi 👩‍👩‍👧 family", expected: "Hi family"},
+		{name: "drops skin tones and variation selectors", text: "Thanks 👍🏽 and ❤️!", expected: "Thanks and !"},
+		{name: "drops flags", text: "Ship to 🇹🇭 today", expected: "Ship to today"},
+		{name: "keeps the digit of a keycap", text: "Step 1️⃣ done", expected: "Step 1 done"},
+		{name: "transliterates common emoji", text: "Thanks 🙂 ❤️", transliterate: true, expected: "Thanks :) <3"},
+		{name: "drops emoji without text when transliterating", text: "Order 🚚 shipped 👍🏽", transliterate: true, expected: "Order shipped (y)"},
+		{name: "keeps text without emoji", text: "Café © 2026 ~ ok", expected: "Café © 2026 ~ ok"},
+		{name: "keeps Thai text", text: "ส่งของแล้ว", expected: "ส่งของแล้ว"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replaceEmoji(tt.text, tt.transliterate))
+		})
+	}
+}
+
+func TestEmojiConfig_adapt(t *testing.T) {
+	req := client.NotificationRequest{
+		To:      "user@example.com",
+		Title:   "Order shipped 🚚",
+		Message: "Thanks 🙂",
+		HTML:    "<p>Thanks 🙂</p>",
+	}
+
+	tests := []struct {
+		name     string
+		config   EmojiConfig
+		expected client.NotificationRequest
+	}{
+		{
+			name:     "keeps emoji by default",
+			expected: req,
+		},
+		{
+			name:   "strips emoji for the channel",
+			config: EmojiConfig{Strip: []string{"Email"}},
+			expected: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Order shipped",
+				Message: "Thanks",
+				HTML:    "<p>Thanks </p>",
+			},
+		},
+		{
+			name:   "transliterates emoji for the channel, over stripping",
+			config: EmojiConfig{Strip: []string{"Email"}, Transliterate: []string{"Email"}},
+			expected: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Order shipped",
+				Message: "Thanks :)",
+				HTML:    "<p>Thanks :)</p>",
+			},
+		},
+		{
+			name:     "keeps emoji for other channels",
+			config:   EmojiConfig{Strip: []string{"PushNotification"}},
+			expected: req,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.adapt(req, "Email"))
+		})
+	}
+}
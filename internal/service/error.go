@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+)
+
+// NotificationError is returned when every preference of a channel failed.
+// Its message only carries provider status codes, keeping hosts and
+// response bodies out of API responses; the full causes stay unwrappable
+type NotificationError struct {
+	Channel string
+	Causes  []error
+}
+
+func (e *NotificationError) Error() string {
+	var statusCodes []string
+	for _, cause := range e.Causes {
+		var providerErr *client.ProviderError
+		if errors.As(cause, &providerErr) {
+			statusCodes = append(statusCodes, fmt.Sprint(providerErr.StatusCode))
+		}
+	}
+
+	if len(statusCodes) == 0 {
+		return "failure to sent the notifications"
+	}
+	return fmt.Sprintf("failure to sent the notifications (provider status codes: %s)", strings.Join(statusCodes, ", "))
+}
+
+func (e *NotificationError) Unwrap() []error {
+	return e.Causes
+}
+
+// SuppressionError is returned when the address is suppressed and email is
+// the only channel routed to the recipient type
+type SuppressionError struct {
+	Address string
+}
+
+func (e *SuppressionError) Error() string {
+	return fmt.Sprintf("recipient '%s' is suppressed after a hard bounce or complaint", e.Address)
+}
+
+// OptOutError is returned when the recipient has not opted in to the
+// category on any channel routed to the recipient type
+type OptOutError struct {
+	Address  string
+	Category string
+}
+
+func (e *OptOutError) Error() string {
+	return fmt.Sprintf("recipient '%s' is opted out of %s notifications", e.Address, e.Category)
+}
+
+// DuplicateMessageError is returned for a message id whose earlier
+// notification failed after a provider may have accepted it, so delivering
+// it again risks a duplicate
+type DuplicateMessageError struct {
+	MessageID      string
+	NotificationID string
+}
+
+func (e *DuplicateMessageError) Error() string {
+	return fmt.Sprintf("message '%s' was already attempted as notification '%s' and may have been delivered", e.MessageID, e.NotificationID)
+}
+
+// MessageInProgressError is returned for a message id whose earlier
+// notification is still being delivered
+type MessageInProgressError struct {
+	MessageID string
+}
+
+func (e *MessageInProgressError) Error() string {
+	return fmt.Sprintf("message '%s' is still being delivered", e.MessageID)
+}
+
+// ChannelSelectionError is returned when a requested channel is not routed
+// to the recipient type
+type ChannelSelectionError struct {
+	RecipientType string
+	Channel       string
+}
+
+func (e *ChannelSelectionError) Error() string {
+	return fmt.Sprintf("channel '%s' is not routed to recipient type '%s'", e.Channel, e.RecipientType)
+}
+
+// ChannelError is returned when a channel a notification is resent on is
+// not registered
+type ChannelError struct {
+	Channel string
+}
+
+func (e *ChannelError) Error() string {
+	return fmt.Sprintf("channel '%s' is not registered", e.Channel)
+}
+
+// NoProviderError is returned when a channel has no enabled provider in the
+// notification_preferences table
+type NoProviderError struct {
+	Channel string
+}
+
+func (e *NoProviderError) Error() string {
+	return fmt.Sprintf("no provider configured for channel '%s'", e.Channel)
+}
+
+// RecipientTypeError is returned when no channel is routed to the recipient
+// type, i.e. the type is not registered
+type RecipientTypeError struct {
+	RecipientType string
+}
+
+func (e *RecipientTypeError) Error() string {
+	return fmt.Sprintf("not supported recipient type '%s'", e.RecipientType)
+}
+
+// UnknownNotificationError is returned when a notification to replay was
+// not kept, or was sent by another tenant
+type UnknownNotificationError struct {
+	NotificationID string
+}
+
+func (e *UnknownNotificationError) Error() string {
+	return fmt.Sprintf("notification '%s' not found", e.NotificationID)
+}
+
+// UnreplayableError is returned when a kept notification cannot be sent
+// again as it was, e.g. it had inline attachments
+type UnreplayableError struct {
+	NotificationID string
+	Reason         string
+}
+
+func (e *UnreplayableError) Error() string {
+	return fmt.Sprintf("notification '%s' cannot be replayed: %s", e.NotificationID, e.Reason)
+}
+
+// ContentRejectedError is returned when a pre-send hook refuses the
+// content of the notification, e.g. for PII or banned content
+type ContentRejectedError struct {
+	Hook   string
+	Reason string
+}
+
+func (e *ContentRejectedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("notification content rejected by the %s hook", e.Hook)
+	}
+	return fmt.Sprintf("notification content rejected by the %s hook: %s", e.Hook, e.Reason)
+}
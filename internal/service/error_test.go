@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		causes   []error
+		expected string
+	}{
+		{
+			name:     "no attempts",
+			causes:   nil,
+			expected: "failure to sent the notifications",
+		},
+		{
+			name:     "transport errors are not exposed",
+			causes:   []error{errors.New("dial tcp 10.0.0.5:443: connection refused")},
+			expected: "failure to sent the notifications",
+		},
+		{
+			name: "provider status codes are listed",
+			causes: []error{
+				&client.ProviderError{Host: "primary.example.com", StatusCode: http.StatusServiceUnavailable, Body: "maintenance"},
+				errors.New("context deadline exceeded"),
+				&client.ProviderError{Host: "secondary.example.com", StatusCode: http.StatusBadRequest, Body: "bad token"},
+			},
+			expected: "failure to sent the notifications (provider status codes: 503, 400)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &NotificationError{Channel: "Email", Causes: tt.causes}
+
+			assert.Equal(t, tt.expected, err.Error())
+			for _, cause := range tt.causes {
+				assert.ErrorIs(t, err, cause)
+			}
+		})
+	}
+}
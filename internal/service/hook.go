@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Moderation actions of a hook, as counted by notification.moderated
+const (
+	HookActionRedacted = "redacted"
+	HookActionRejected = "rejected"
+)
+
+// Hook checks the content of a notification before it reaches any channel,
+// e.g. to scrub PII or reject banned content. Hooks are provided to the
+// "hooks" fx group and run in the order of PRE_SEND_HOOKS, each on the
+// content the previous one returned
+type Hook interface {
+	// Name is how PRE_SEND_HOOKS refers to the hook
+	Name() string
+	// Check returns the notification to send, redacted as needed, or a
+	// ContentRejectedError when it must not be sent at all
+	Check(ctx context.Context, notification Notification) (Notification, error)
+}
+
+// AsHook annotates a hook constructor so fx adds the hook to the "hooks"
+// group, e.g. fx.Provide(service.AsHook(NewProfanityHook))
+func AsHook(constructor any) any {
+	return fx.Annotate(
+		constructor,
+		fx.As(new(Hook)),
+		fx.ResultTags(`group:"hooks"`),
+	)
+}
+
+type HookConfig struct {
+	// Hooks names the pre-send hooks run on every notification, in order;
+	// registered hooks left out do not run
+	Hooks []string `envconfig:"PRE_SEND_HOOKS" default:"scrubber"`
+}
+
+// HookChain runs the configured pre-send hooks on a notification. A nil
+// chain lets every notification through unchanged
+type HookChain struct {
+	hooks            []Hook
+	metricsCollector *metrics.NotificationCollector
+	logger           *zap.Logger
+}
+
+type HookChainParams struct {
+	fx.In
+
+	Config           HookConfig
+	MetricsCollector *metrics.NotificationCollector
+	Logger           *zap.Logger
+	Hooks            []Hook `group:"hooks"`
+}
+
+func NewHookChain(params HookChainParams) (*HookChain, error) {
+	registered := make([]string, 0, len(params.Hooks))
+	for _, hook := range params.Hooks {
+		registered = append(registered, hook.Name())
+	}
+	slices.Sort(registered)
+
+	hooks := make([]Hook, 0, len(params.Config.Hooks))
+	for _, name := range params.Config.Hooks {
+		i := slices.IndexFunc(params.Hooks, func(hook Hook) bool { return hook.Name() == name })
+		if i < 0 {
+			return nil, fmt.Errorf("pre-send hook: '%s' is not registered, use %s", name, strings.Join(registered, ", "))
+		}
+		hooks = append(hooks, params.Hooks[i])
+	}
+
+	return &HookChain{
+		hooks:            hooks,
+		metricsCollector: params.MetricsCollector,
+		logger:           params.Logger,
+	}, nil
+}
+
+// Run passes the notification through every hook in order and returns the
+// content to send, or the error of the first hook failing
+func (c *HookChain) Run(ctx context.Context, recipientType string, notification Notification) (Notification, error) {
+	if c == nil {
+		return notification, nil
+	}
+	notification.RecipientType = recipientType
+
+	for _, hook := range c.hooks {
+		checked, err := hook.Check(ctx, notification)
+		if err != nil {
+			var rejectedErr *ContentRejectedError
+			if errors.As(err, &rejectedErr) {
+				logging.From(ctx, c.logger).Warn("pre-send hook rejected the notification",
+					zap.String("hook", hook.Name()),
+					zap.String("reason", rejectedErr.Reason),
+				)
+				c.metricsCollector.RecordModerated(ctx, recipientType, hook.Name(), HookActionRejected)
+			}
+			return notification, err
+		}
+
+		if redacted(notification, checked) {
+			c.metricsCollector.RecordModerated(ctx, recipientType, hook.Name(), HookActionRedacted)
+		}
+		notification = checked
+	}
+	return notification, nil
+}
+
+// redacted tells whether a hook changed the content of the notification
+func redacted(before Notification, after Notification) bool {
+	return before.Title != after.Title || before.Message != after.Message || before.HTML != after.HTML
+}
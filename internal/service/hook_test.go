@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testHook appends its name to the message, or fails with err
+type testHook struct {
+	name string
+	err  error
+}
+
+func (h testHook) Name() string {
+	return h.name
+}
+
+func (h testHook) Check(_ context.Context, notification Notification) (Notification, error) {
+	if h.err != nil {
+		return notification, h.err
+	}
+	notification.Message += " " + h.name
+	return notification, nil
+}
+
+func TestNewHookChain(t *testing.T) {
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+	hooks := []Hook{testHook{name: "first"}, testHook{name: "second"}}
+
+	tests := []struct {
+		name            string
+		config          HookConfig
+		expectedMessage string
+		expectedError   string
+	}{
+		{
+			name:            "runs the hooks in the configured order",
+			config:          HookConfig{Hooks: []string{"second", "first"}},
+			expectedMessage: "hello second first",
+		},
+		{
+			name:            "skips the hooks left out",
+			config:          HookConfig{Hooks: []string{"first"}},
+			expectedMessage: "hello first",
+		},
+		{
+			name:            "runs no hook when none is configured",
+			expectedMessage: "hello",
+		},
+		{
+			name:          "refuses a hook not registered",
+			config:        HookConfig{Hooks: []string{"third"}},
+			expectedError: "pre-send hook: 'third' is not registered, use first, second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := NewHookChain(HookChainParams{
+				Config:           tt.config,
+				MetricsCollector: metricsCollector,
+				Logger:           zap.NewNop(),
+				Hooks:            hooks,
+			})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			notification, err := chain.Run(context.Background(), recipientTypeBuyer, Notification{Message: "hello"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMessage, notification.Message)
+			assert.Equal(t, recipientTypeBuyer, notification.RecipientType)
+		})
+	}
+}
+
+func TestHookChain_Run_StopsAtFailure(t *testing.T) {
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+	rejected := &ContentRejectedError{Hook: "first", Reason: "banned"}
+
+	chain, err := NewHookChain(HookChainParams{
+		Config:           HookConfig{Hooks: []string{"first", "second"}},
+		MetricsCollector: metricsCollector,
+		Logger:           zap.NewNop(),
+		Hooks:            []Hook{testHook{name: "first", err: rejected}, testHook{name: "second"}},
+	})
+	require.NoError(t, err)
+
+	notification, err := chain.Run(context.Background(), recipientTypeBuyer, Notification{Message: "hello"})
+
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, "hello", notification.Message)
+}
+
+func TestNotificationService_Send_RejectedContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+	scrubber, err := NewScrubberHook(ScrubberConfig{DenyList: []string{"casino"}})
+	require.NoError(t, err)
+	chain, err := NewHookChain(HookChainParams{
+		Config:           HookConfig{Hooks: []string{ScrubberHookName}},
+		MetricsCollector: metricsCollector,
+		Logger:           zap.NewNop(),
+		Hooks:            []Hook{scrubber},
+	})
+	require.NoError(t, err)
+
+	// Rejected content is never checked against suppressions, consents or
+	// quotas, nor sent
+	service := NewNotificationService(NotificationServiceParams{
+		MetricsCollector: metricsCollector,
+		IDGenerator:      newTestIDGenerator(ctrl),
+		RouteCache:       newTestRouteCache(ctrl),
+		Hooks:            chain,
+		Channels:         newTestChannels(ProviderChannelParams{}),
+	})
+
+	report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+		To:      "buyer@example.com",
+		Title:   "Big win",
+		Message: "Visit our casino",
+	})
+
+	var rejectedErr *ContentRejectedError
+	require.True(t, errors.As(err, &rejectedErr))
+	assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+}
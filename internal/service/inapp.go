@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/realtime"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/fx"
+)
+
+var _ Channel = (*InAppChannel)(nil)
+
+// InAppChannel keeps notifications in Postgres for the recipient to read in
+// the app instead of calling a provider, and pushes them to the realtime
+// connections of the recipient. The recipient address is the id the app
+// lists notifications by, e.g. a user id
+type InAppChannel struct {
+	notifications repository.InAppNotificationProvider
+	publisher     realtime.Publisher
+}
+
+type InAppChannelParams struct {
+	fx.In
+
+	Notifications repository.InAppNotificationProvider
+	Publisher     realtime.Publisher
+}
+
+func NewInAppChannel(params InAppChannelParams) *InAppChannel {
+	return &InAppChannel{
+		notifications: params.Notifications,
+		publisher:     params.Publisher,
+	}
+}
+
+func (c *InAppChannel) Name() string {
+	return repository.InAppProvider.String()
+}
+
+func (c *InAppChannel) Capabilities() Capabilities {
+	capabilities, _ := CapabilitiesFor(repository.InAppProvider)
+	return capabilities
+}
+
+// Validate rejects a blank recipient, which no user could ever list
+func (c *InAppChannel) Validate(recipient string) error {
+	if strings.TrimSpace(recipient) == "" {
+		return &RecipientError{Channel: c.Name(), Recipient: recipient, Reason: "recipient is blank"}
+	}
+	return nil
+}
+
+// Preview returns the content stored for the recipient, none for a sandbox
+// notification; no provider is called
+func (c *InAppChannel) Preview(_ context.Context, notification Notification) ([]Payload, error) {
+	if notification.Sandbox {
+		return nil, nil
+	}
+
+	req := adaptPayload(notification.providerRequest(), repository.InAppProvider)
+	return []Payload{{Request: req}}, nil
+}
+
+// Send stores the notification, then pushes it live. A failed insert stored
+// nothing, so only the stored notification is recorded as an attempt and a
+// failure stays safe to retry. A sandbox notification would reach a real
+// user's inbox, so it is accepted without being stored
+func (c *InAppChannel) Send(ctx context.Context, notification Notification) error {
+	if notification.Sandbox {
+		RecordProvider(ctx, SandboxProvider, "")
+		return nil
+	}
+
+	req := adaptPayload(notification.providerRequest(), repository.InAppProvider)
+
+	stored, err := c.notifications.CreateInAppNotification(ctx, repository.InAppNotification{
+		NotificationID: notification.ID,
+		RecipientType:  notification.RecipientType,
+		Recipient:      req.To,
+		Title:          req.Title,
+		Message:        req.Message,
+		ThreadKey:      req.ThreadKey,
+		DeepLink:       req.DeepLink,
+		ImageURL:       req.ImageURL,
+		Tenant:         quota.SubjectFrom(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	RecordAttempt(ctx, nil)
+	c.publisher.Publish(ctx, stored)
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	mockrealtime "github.com/koungkub/fw-challenge-notification-service/internal/realtime/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInAppChannel_Validate(t *testing.T) {
+	channel := NewInAppChannel(InAppChannelParams{})
+
+	assert.NoError(t, channel.Validate("user-42"))
+
+	var recipientErr *RecipientError
+	require.ErrorAs(t, channel.Validate("  "), &recipientErr)
+	assert.Equal(t, "InApp", recipientErr.Channel)
+}
+
+func TestInAppChannel_Send(t *testing.T) {
+	notification := Notification{
+		ID:            testNotificationID,
+		RecipientType: recipientTypeBuyer,
+		To:            "user-42",
+		Title:         "Order shipped",
+		Message:       "Your order is on its way",
+		ThreadKey:     "order-42",
+		HTML:          "<p>Your order is on its way</p>",
+		DeepLink:      "app://orders/42",
+		ImageURL:      "https://cdn.example.com/parcel.png",
+	}
+
+	tests := []struct {
+		name             string
+		sandbox          bool
+		setupMocks       func(*mockrepository.MockInAppNotificationProvider, *mockrealtime.MockPublisher)
+		expectedError    bool
+		expectedAttempts int
+	}{
+		{
+			name: "stores the notification without unsupported content and pushes it",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider, publisher *mockrealtime.MockPublisher) {
+				stored := repository.InAppNotification{ID: 7, Recipient: "user-42"}
+				notifications.EXPECT().CreateInAppNotification(gomock.Any(), repository.InAppNotification{
+					NotificationID: testNotificationID,
+					RecipientType:  recipientTypeBuyer,
+					Recipient:      "user-42",
+					Title:          "Order shipped",
+					Message:        "Your order is on its way",
+					ThreadKey:      "order-42",
+					DeepLink:       "app://orders/42",
+					ImageURL:       "https://cdn.example.com/parcel.png",
+					Tenant:         "acme",
+				}).Return(stored, nil)
+				publisher.EXPECT().Publish(gomock.Any(), stored)
+			},
+			expectedAttempts: 1,
+		},
+		{
+			name: "records no attempt when the insert fails",
+			setupMocks: func(notifications *mockrepository.MockInAppNotificationProvider, _ *mockrealtime.MockPublisher) {
+				notifications.EXPECT().CreateInAppNotification(gomock.Any(), gomock.Any()).Return(repository.InAppNotification{}, errors.New("database down"))
+			},
+			expectedError: true,
+		},
+		{
+			name:       "accepts a sandbox notification without storing it",
+			sandbox:    true,
+			setupMocks: func(*mockrepository.MockInAppNotificationProvider, *mockrealtime.MockPublisher) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			notifications := mockrepository.NewMockInAppNotificationProvider(ctrl)
+			publisher := mockrealtime.NewMockPublisher(ctrl)
+			tt.setupMocks(notifications, publisher)
+
+			channel := NewInAppChannel(InAppChannelParams{Notifications: notifications, Publisher: publisher})
+
+			sent := notification
+			sent.Sandbox = tt.sandbox
+			result, err := sendToChannel(quota.WithSubject(context.Background(), "acme"), channel, sent)
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAttempts, result.attempts)
+			assert.Equal(t, !tt.expectedError, result.delivered)
+			assert.False(t, result.ambiguous)
+		})
+	}
+}
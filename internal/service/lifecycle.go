@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+)
+
+// emit hands a lifecycle event of the notification to the event bus. Events
+// of a cancelled delivery are still observed, so only the values of ctx are
+// passed on
+func (s *NotificationService) emit(ctx context.Context, evt event.NotificationEvent) {
+	if s.events == nil {
+		return
+	}
+	s.events.Emit(context.WithoutCancel(ctx), evt)
+}
+
+// emitOutcome emits whether an accepted notification was sent or failed. A
+// notification queued by the retry queue has neither outcome yet; its
+// retries emit theirs
+func (s *NotificationService) emitOutcome(ctx context.Context, recipientType string, report DeliveryReport, err error) {
+	if report.Retrying {
+		return
+	}
+
+	evt := event.NotificationEvent{
+		Type:           event.TypeNotificationSent,
+		NotificationID: report.ID,
+		RecipientType:  recipientType,
+		Channels:       report.Channels,
+		Attempts:       report.Attempts,
+		Err:            err,
+	}
+	if err != nil {
+		evt.Type = event.TypeNotificationFailed
+	}
+	s.emit(ctx, evt)
+}
+
+// channelNames lists the names of channels, in order
+func channelNames(channels []Channel) []string {
+	names := make([]string, len(channels))
+	for i, channel := range channels {
+		names[i] = channel.Name()
+	}
+	return names
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	mockevent "github.com/koungkub/fw-challenge-notification-service/internal/event/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_EmitsLifecycleEvents(t *testing.T) {
+	rejected := &client.ProviderError{StatusCode: http.StatusServiceUnavailable}
+	notification := Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting"}
+
+	tests := []struct {
+		name           string
+		suppressed     bool
+		pushErr        error
+		expectedEvents []event.NotificationEvent
+	}{
+		{
+			name: "emits a sent notification with its channels",
+			expectedEvents: []event.NotificationEvent{
+				{Type: event.TypeNotificationAccepted, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"Email", "PushNotification"}},
+				{Type: event.TypeNotificationSent, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"Email", "PushNotification"}, Attempts: 2},
+			},
+		},
+		{
+			name:    "emits a failed notification with its error",
+			pushErr: rejected,
+			expectedEvents: []event.NotificationEvent{
+				{Type: event.TypeNotificationAccepted, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"Email", "PushNotification"}},
+				{Type: event.TypeNotificationFailed, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"Email"}, Attempts: 2, Err: rejected},
+			},
+		},
+		{
+			name:       "emits a suppressed email before the notification is accepted",
+			suppressed: true,
+			expectedEvents: []event.NotificationEvent{
+				{Type: event.TypeNotificationSuppressed, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"Email"}},
+				{Type: event.TypeNotificationAccepted, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"PushNotification"}},
+				{Type: event.TypeNotificationSent, NotificationID: testNotificationID, RecipientType: recipientTypeSeller, Channels: []string{"PushNotification"}, Attempts: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			suppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			suppressions.EXPECT().IsSuppressed(gomock.Any(), notification.To).Return(tt.suppressed, nil)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			var emitted []event.NotificationEvent
+			events := mockevent.NewMockBus(ctrl)
+			events.EXPECT().Emit(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, evt event.NotificationEvent) {
+					emitted = append(emitted, evt)
+				}).AnyTimes()
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     suppressions,
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Events:           events,
+				Channels: []Channel{
+					&fakeChannel{name: "Email", attempts: []error{nil}},
+					&fakeChannel{name: "PushNotification", attempts: []error{tt.pushErr}, sendErr: tt.pushErr},
+				},
+			})
+
+			_, _ = service.Send(context.Background(), recipientTypeSeller, notification)
+
+			assert.Equal(t, tt.expectedEvents, emitted)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+type MessageConfig struct {
+	// ClaimTimeout is how long a message may stay pending before another
+	// request with its id presumes the delivery abandoned and takes it over
+	ClaimTimeout time.Duration `envconfig:"MESSAGE_ID_CLAIM_TIMEOUT" default:"5m"`
+}
+
+// sendOnce delivers a notification carrying a caller supplied message id at
+// most once; a repeated id returns the result of the first delivery instead
+func (s *NotificationService) sendOnce(
+	ctx context.Context,
+	id string,
+	recipientType string,
+	notification Notification,
+) (DeliveryReport, error) {
+	message, claimed, err := s.messages.ClaimMessage(ctx, repository.NotificationMessage{
+		MessageID:      notification.MessageID,
+		NotificationID: id,
+		RecipientType:  recipientType,
+	}, s.messageConfig.ClaimTimeout)
+	if err != nil {
+		return DeliveryReport{ID: id}.finish(err), err
+	}
+	if !claimed {
+		return priorReport(message)
+	}
+
+	report, err := s.send(ctx, id, recipientType, notification)
+
+	// The outcome is stored even when the caller has gone; failed writes
+	// are logged by the repository, leaving the claim to expire
+	ctx = context.WithoutCancel(ctx)
+	message.Attempts = report.Attempts
+	message.Channels = strings.Join(report.Channels, ",")
+	switch report.RetryDisposition {
+	case RetryNotNeeded:
+		message.State = repository.MessageStateSent
+		_ = s.messages.CompleteMessage(ctx, message)
+	case RetryUnsafe:
+		message.State = repository.MessageStateFailed
+		_ = s.messages.CompleteMessage(ctx, message)
+	default:
+		// Nothing was delivered, so the message may be sent again
+		_ = s.messages.ReleaseMessage(ctx, message.MessageID, message.NotificationID)
+	}
+
+	return report, err
+}
+
+// priorReport is the result of the earlier delivery of a message
+func priorReport(message repository.NotificationMessage) (DeliveryReport, error) {
+	report := DeliveryReport{
+		ID:        message.NotificationID,
+		Attempts:  message.Attempts,
+		Duplicate: true,
+	}
+	// Only the delivered channels are stored, without their providers
+	if message.Channels != "" {
+		report.Channels = strings.Split(message.Channels, ",")
+		for _, channel := range report.Channels {
+			report.Deliveries = append(report.Deliveries, ChannelDelivery{Channel: channel, Status: DeliveryStatusDelivered})
+		}
+	}
+
+	switch message.State {
+	case repository.MessageStateSent:
+		report.RetryDisposition = RetryNotNeeded
+		return report, nil
+	case repository.MessageStatePending:
+		// Retrying later returns the outcome once it is known
+		report.RetryDisposition = RetrySafe
+		return report, &MessageInProgressError{MessageID: message.MessageID}
+	default:
+		report.RetryDisposition = RetryDoNotRetry
+		return report, &DuplicateMessageError{MessageID: message.MessageID, NotificationID: message.NotificationID}
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_SendOnce(t *testing.T) {
+	const messageID = "order-42-shipped"
+	claim := repository.NotificationMessage{
+		MessageID:      messageID,
+		NotificationID: testNotificationID,
+		RecipientType:  recipientTypeBuyer,
+	}
+	prior := func(state string) repository.NotificationMessage {
+		return repository.NotificationMessage{
+			MessageID:      messageID,
+			NotificationID: "01JB8Z0000000000000000PRIOR",
+			RecipientType:  recipientTypeBuyer,
+			State:          state,
+			Attempts:       2,
+			Channels:       "Email",
+		}
+	}
+
+	tests := []struct {
+		name                string
+		setupMocks          func(*mockrepository.MockMessageProvider, *mockclient.MockHTTPClientProvider)
+		expectedID          string
+		expectedDisposition string
+		expectedDuplicate   bool
+		expectedDeliveries  []ChannelDelivery
+		expectedErr         error
+	}{
+		{
+			name: "delivers a new message and stores the result",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, httpClient *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(claim, true, nil)
+				httpClient.EXPECT().Post(gomock.Any(), "https://email.com", gomock.Any()).Return(nil)
+				sent := claim
+				sent.State = repository.MessageStateSent
+				sent.Attempts = 1
+				sent.Channels = "Email"
+				messages.EXPECT().CompleteMessage(gomock.Any(), sent).Return(nil)
+			},
+			expectedID:          testNotificationID,
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name: "releases a message no provider accepted",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, httpClient *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(claim, true, nil)
+				httpClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).Return(&client.ProviderError{StatusCode: 429})
+				messages.EXPECT().ReleaseMessage(gomock.Any(), messageID, testNotificationID).Return(nil)
+			},
+			expectedID:          testNotificationID,
+			expectedDisposition: RetrySafe,
+			expectedErr:         &NotificationError{},
+		},
+		{
+			name: "stores a failure a provider may have accepted",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, httpClient *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(claim, true, nil)
+				httpClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("read timeout"))
+				failed := claim
+				failed.State = repository.MessageStateFailed
+				failed.Attempts = 1
+				messages.EXPECT().CompleteMessage(gomock.Any(), failed).Return(nil)
+			},
+			expectedID:          testNotificationID,
+			expectedDisposition: RetryUnsafe,
+			expectedErr:         &NotificationError{},
+		},
+		{
+			name: "returns the earlier delivery",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, _ *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(prior(repository.MessageStateSent), false, nil)
+			},
+			expectedID:          "01JB8Z0000000000000000PRIOR",
+			expectedDisposition: RetryNotNeeded,
+			expectedDuplicate:   true,
+			expectedDeliveries:  []ChannelDelivery{{Channel: "Email", Status: DeliveryStatusDelivered}},
+		},
+		{
+			name: "refuses a message still being delivered",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, _ *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(prior(repository.MessageStatePending), false, nil)
+			},
+			expectedID:          "01JB8Z0000000000000000PRIOR",
+			expectedDisposition: RetrySafe,
+			expectedDuplicate:   true,
+			expectedErr:         &MessageInProgressError{},
+		},
+		{
+			name: "refuses a message whose delivery may have succeeded",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, _ *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(prior(repository.MessageStateFailed), false, nil)
+			},
+			expectedID:          "01JB8Z0000000000000000PRIOR",
+			expectedDisposition: RetryDoNotRetry,
+			expectedDuplicate:   true,
+			expectedErr:         &DuplicateMessageError{},
+		},
+		{
+			name: "fails when the claim fails",
+			setupMocks: func(messages *mockrepository.MockMessageProvider, _ *mockclient.MockHTTPClientProvider) {
+				messages.EXPECT().ClaimMessage(gomock.Any(), claim, time.Minute).Return(repository.NotificationMessage{}, false, errors.New("database down"))
+			},
+			expectedID:          testNotificationID,
+			expectedDisposition: RetrySafe,
+			expectedErr:         errors.New("database down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockMessages := mockrepository.NewMockMessageProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+			tt.setupMocks(mockMessages, mockHTTPClient)
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				Messages:         mockMessages,
+				MessageConfig:    MessageConfig{ClaimTimeout: time.Minute},
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+				MessageID: messageID,
+				To:        "user@example.com",
+				Title:     "Test",
+				Message:   "Test message",
+			})
+
+			if tt.expectedErr != nil {
+				require.Error(t, err)
+				assert.IsType(t, tt.expectedErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedID, report.ID)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+			assert.Equal(t, tt.expectedDuplicate, report.Duplicate)
+			if tt.expectedDeliveries != nil {
+				assert.Equal(t, tt.expectedDeliveries, report.Deliveries)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+// MetadataConfig decides whether providers see the caller metadata of a
+// notification; it is always logged and kept in the notification log
+type MetadataConfig struct {
+	Forward bool `envconfig:"METADATA_FORWARD" default:"false"`
+}
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	service "github.com/koungkub/fw-challenge-notification-service/internal/service"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,30 +41,62 @@ func (m *MockNotificationProvider) EXPECT() *MockNotificationProviderMockRecorde
 	return m.recorder
 }
 
-// SendToBuyer mocks base method.
-func (m *MockNotificationProvider) SendToBuyer(ctx context.Context, to, title, message string) error {
+// DryRun mocks base method.
+func (m *MockNotificationProvider) DryRun(ctx context.Context, recipientType string, notification service.Notification) (service.DryRunReport, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendToBuyer", ctx, to, title, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "DryRun", ctx, recipientType, notification)
+	ret0, _ := ret[0].(service.DryRunReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SendToBuyer indicates an expected call of SendToBuyer.
-func (mr *MockNotificationProviderMockRecorder) SendToBuyer(ctx, to, title, message any) *gomock.Call {
+// DryRun indicates an expected call of DryRun.
+func (mr *MockNotificationProviderMockRecorder) DryRun(ctx, recipientType, notification any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToBuyer", reflect.TypeOf((*MockNotificationProvider)(nil).SendToBuyer), ctx, to, title, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRun", reflect.TypeOf((*MockNotificationProvider)(nil).DryRun), ctx, recipientType, notification)
 }
 
-// SendToSeller mocks base method.
-func (m *MockNotificationProvider) SendToSeller(ctx context.Context, to, title, message string) error {
+// Replay mocks base method.
+func (m *MockNotificationProvider) Replay(ctx context.Context, id string, options service.ReplayOptions) (service.DeliveryReport, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendToSeller", ctx, to, title, message)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Replay", ctx, id, options)
+	ret0, _ := ret[0].(service.DeliveryReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SendToSeller indicates an expected call of SendToSeller.
-func (mr *MockNotificationProviderMockRecorder) SendToSeller(ctx, to, title, message any) *gomock.Call {
+// Replay indicates an expected call of Replay.
+func (mr *MockNotificationProviderMockRecorder) Replay(ctx, id, options any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToSeller", reflect.TypeOf((*MockNotificationProvider)(nil).SendToSeller), ctx, to, title, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockNotificationProvider)(nil).Replay), ctx, id, options)
+}
+
+// Send mocks base method.
+func (m *MockNotificationProvider) Send(ctx context.Context, recipientType string, notification service.Notification) (service.DeliveryReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, recipientType, notification)
+	ret0, _ := ret[0].(service.DeliveryReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockNotificationProviderMockRecorder) Send(ctx, recipientType, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockNotificationProvider)(nil).Send), ctx, recipientType, notification)
+}
+
+// SendChannel mocks base method.
+func (m *MockNotificationProvider) SendChannel(ctx context.Context, id, recipientType, channel string, notification service.Notification) (service.DeliveryReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendChannel", ctx, id, recipientType, channel, notification)
+	ret0, _ := ret[0].(service.DeliveryReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendChannel indicates an expected call of SendChannel.
+func (mr *MockNotificationProviderMockRecorder) SendChannel(ctx, id, recipientType, channel, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChannel", reflect.TypeOf((*MockNotificationProvider)(nil).SendChannel), ctx, id, recipientType, channel, notification)
 }
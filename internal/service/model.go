@@ -0,0 +1,58 @@
+package service
+
+import "github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+
+// Notification is the content to deliver to a recipient
+type Notification struct {
+	// ID and RecipientType are set by Send before the notification reaches
+	// a channel
+	ID            string
+	RecipientType string
+	// MessageID is the caller's id of the message; notifications sharing it
+	// are delivered once
+	MessageID string
+	To        string
+	// Channels restricts delivery to these of the channels routed to the
+	// recipient type; empty means every routed channel
+	Channels []string
+	Title    string
+	Message  string
+	// ThreadKey groups related notifications into one conversation; each
+	// channel maps it to its native threading mechanism
+	ThreadKey string
+	// Priority orders the notification among those waiting for a delivery
+	// slot; empty means normal
+	Priority dispatch.Priority
+	// Category decides which consents of the recipient apply; empty means
+	// transactional
+	Category string
+	// Locale is the recipient's BCP 47 language tag, e.g. th-TH
+	Locale string
+	// Template names an admin template whose active version replaces
+	// Title and Message, rendered with Params
+	Template string
+	// TitleKey and MessageKey name translations that replace Title and
+	// Message, rendered with Params
+	TitleKey   string
+	MessageKey string
+	Params     map[string]string
+	// Rich content is only delivered to providers able to render it
+	HTML        string
+	Attachments []Attachment
+	DeepLink    string
+	ImageURL    string
+	// Metadata is free-form caller context, e.g. order_id or campaign_id,
+	// kept in the notification log and the logs of its delivery
+	Metadata map[string]string
+	// Sandbox is set for the notifications of sandbox API keys, which never
+	// reach a real provider or recipient
+	Sandbox bool
+}
+
+// Attachment is either a URL the provider fetches or inline base64 content
+type Attachment struct {
+	Filename    string
+	ContentType string
+	URL         string
+	Content     string
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type PartialSuccessConfig struct {
+	// Enabled reports a notification delivered on some of its channels as
+	// sent, queueing the failed channels for retry, instead of failing it
+	Enabled bool `envconfig:"PARTIAL_SUCCESS_ENABLED" default:"false"`
+	// RetryInterval is how often queued channels are retried; each failed
+	// retry doubles the wait before the next one, up to RetryMaxInterval
+	RetryInterval    time.Duration `envconfig:"CHANNEL_RETRY_INTERVAL" default:"1m"`
+	RetryMaxInterval time.Duration `envconfig:"CHANNEL_RETRY_MAX_INTERVAL" default:"1h"`
+	// RetryMaxAttempts is the number of retries before a channel is given up
+	RetryMaxAttempts int `envconfig:"CHANNEL_RETRY_MAX_ATTEMPTS" default:"5"`
+}
+
+// SendChannel delivers an accepted notification again on one channel,
+// keeping its id. The checks Send made when accepting it are not repeated
+func (s *NotificationService) SendChannel(
+	ctx context.Context,
+	id string,
+	recipientType string,
+	channelName string,
+	notification Notification,
+) (DeliveryReport, error) {
+	ctx, cancel := s.budget.start(ctx)
+	defer cancel()
+	ctx = logging.With(ctx, zap.String(logging.FieldRecipientType, recipientType))
+
+	report := DeliveryReport{ID: id}
+
+	channel, ok := s.channels[channelName]
+	if !ok {
+		report.RetryDisposition = RetryDoNotRetry
+		return report, &ChannelError{Channel: channelName}
+	}
+
+	release, err := s.dispatcher.Acquire(ctx, notification.Priority)
+	if err != nil {
+		return report.finish(err), err
+	}
+	defer release()
+
+	notification.ID = id
+	notification.RecipientType = recipientType
+
+	result, err := sendToChannel(ctx, channel, notification)
+	return report.finish(err, result), err
+}
+
+// queueFailedChannels queues every failed channel of a notification for
+// retry. Channels a provider may have accepted are left failed, as a retry
+// risks a duplicate; so are those a provider refused for good, which a
+// retry would not change, and those whose retry could not be stored, the
+// repository having logged why
+func (s *NotificationService) queueFailedChannels(
+	ctx context.Context,
+	recipientType string,
+	notification Notification,
+	results []channelResult,
+) []channelResult {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return results
+	}
+
+	// The retries are queued even when the caller has gone, as the
+	// notification is answered as sent or accepted either way
+	ctx = context.WithoutCancel(ctx)
+	for i, result := range results {
+		if result.delivered || result.ambiguous || result.rejected || result.skipped {
+			continue
+		}
+
+		err := s.retries.AddChannelRetry(ctx, repository.ChannelRetry{
+			NotificationID: notification.ID,
+			RecipientType:  recipientType,
+			Channel:        result.channel,
+			Tenant:         quota.SubjectFrom(ctx),
+			Notification:   payload,
+		})
+		results[i].queued = err == nil
+	}
+	return results
+}
+
+// partiallyDelivered tells whether some channels delivered while others
+// failed
+func partiallyDelivered(results []channelResult) bool {
+	var delivered, failed bool
+	for _, result := range results {
+		delivered = delivered || result.delivered
+		failed = failed || !result.delivered && !result.skipped
+	}
+	return delivered && failed
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_PartialSuccess(t *testing.T) {
+	throttled := &client.ProviderError{StatusCode: http.StatusTooManyRequests}
+	notification := Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting"}
+
+	tests := []struct {
+		name                string
+		config              PartialSuccessConfig
+		push                *fakeChannel
+		setupMocks          func(*mockrepository.MockChannelRetryProvider)
+		expectError         bool
+		expectedPartial     bool
+		expectedDisposition string
+		expectedPushStatus  string
+	}{
+		{
+			name:   "queues the failed channel and reports the notification sent",
+			config: PartialSuccessConfig{Enabled: true},
+			push:   &fakeChannel{name: "PushNotification", attempts: []error{throttled}, sendErr: throttled},
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider) {
+				retries.EXPECT().AddChannelRetry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, retry repository.ChannelRetry) error {
+						assert.Equal(t, testNotificationID, retry.NotificationID)
+						assert.Equal(t, recipientTypeSeller, retry.RecipientType)
+						assert.Equal(t, "PushNotification", retry.Channel)
+						assert.Equal(t, "tenant-a", retry.Tenant)
+
+						var queued Notification
+						require.NoError(t, json.Unmarshal(retry.Notification, &queued))
+						assert.Equal(t, notification.To, queued.To)
+						assert.Equal(t, notification.Message, queued.Message)
+						return nil
+					})
+			},
+			expectedPartial:     true,
+			expectedDisposition: RetryNotNeeded,
+			expectedPushStatus:  DeliveryStatusQueued,
+		},
+		{
+			name:                "leaves a channel a provider may have accepted failed",
+			config:              PartialSuccessConfig{Enabled: true},
+			push:                &fakeChannel{name: "PushNotification", attempts: []error{errors.New("read timeout")}, sendErr: errors.New("read timeout")},
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectedPartial:     true,
+			expectedDisposition: RetryNotNeeded,
+			expectedPushStatus:  DeliveryStatusFailed,
+		},
+		{
+			name:                "leaves a channel a provider refused for good failed",
+			config:              PartialSuccessConfig{Enabled: true},
+			push:                &fakeChannel{name: "PushNotification", attempts: []error{&client.ProviderError{StatusCode: http.StatusUnprocessableEntity}}, sendErr: &client.ProviderError{StatusCode: http.StatusUnprocessableEntity}},
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectedPartial:     true,
+			expectedDisposition: RetryNotNeeded,
+			expectedPushStatus:  DeliveryStatusFailed,
+		},
+		{
+			name:   "leaves a channel whose retry could not be stored failed",
+			config: PartialSuccessConfig{Enabled: true},
+			push:   &fakeChannel{name: "PushNotification", attempts: []error{throttled}, sendErr: throttled},
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider) {
+				retries.EXPECT().AddChannelRetry(gomock.Any(), gomock.Any()).Return(errors.New("database down"))
+			},
+			expectedPartial:     true,
+			expectedDisposition: RetryNotNeeded,
+			expectedPushStatus:  DeliveryStatusFailed,
+		},
+		{
+			name:                "fails the notification when disabled",
+			push:                &fakeChannel{name: "PushNotification", attempts: []error{throttled}, sendErr: throttled},
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectError:         true,
+			expectedDisposition: RetryUnsafe,
+			expectedPushStatus:  DeliveryStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRetries := mockrepository.NewMockChannelRetryProvider(ctrl)
+			tt.setupMocks(mockRetries)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Retries:          mockRetries,
+				PartialSuccess:   tt.config,
+				Channels: []Channel{
+					&fakeChannel{name: "Email", attempts: []error{nil}},
+					tt.push,
+				},
+			})
+
+			ctx := quota.WithSubject(context.Background(), "tenant-a")
+			report, err := service.Send(ctx, recipientTypeSeller, notification)
+
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPartial, report.Partial)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+			assert.Equal(t, []string{"Email"}, report.Channels)
+			assert.ElementsMatch(t, []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusDelivered, Attempts: 1},
+				{Channel: "PushNotification", Status: tt.expectedPushStatus, Attempts: 1},
+			}, report.Deliveries)
+		})
+	}
+}
+
+func TestNotificationService_SendChannel(t *testing.T) {
+	t.Run("sends on the channel with the id of the notification", func(t *testing.T) {
+		push := &fakeChannel{name: "PushNotification", attempts: []error{nil}}
+		service := NewNotificationService(NotificationServiceParams{
+			Dispatcher: newTestDispatcher(t),
+			Channels:   []Channel{&fakeChannel{name: "Email"}, push},
+		})
+
+		report, err := service.SendChannel(context.Background(), testNotificationID, recipientTypeSeller, "PushNotification", Notification{To: "device-token"})
+
+		require.NoError(t, err)
+		assert.Equal(t, testNotificationID, report.ID)
+		assert.Equal(t, []string{"PushNotification"}, report.Channels)
+		require.Len(t, push.sent, 1)
+		assert.Equal(t, testNotificationID, push.sent[0].ID)
+		assert.Equal(t, recipientTypeSeller, push.sent[0].RecipientType)
+	})
+
+	t.Run("rejects an unregistered channel", func(t *testing.T) {
+		service := NewNotificationService(NotificationServiceParams{
+			Dispatcher: newTestDispatcher(t),
+		})
+
+		report, err := service.SendChannel(context.Background(), testNotificationID, recipientTypeSeller, "SMS", Notification{To: "+66800000000"})
+
+		var channelErr *ChannelError
+		require.ErrorAs(t, err, &channelErr)
+		assert.Equal(t, "SMS", channelErr.Channel)
+		assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+// adaptPayload shapes the request for a provider kind: rich content the kind
+// cannot render is dropped and the thread key is mapped onto its threading
+func adaptPayload(req client.NotificationRequest, providerType repository.NotificationProvider) client.NotificationRequest {
+	capabilities, _ := CapabilitiesFor(providerType)
+
+	if !capabilities.SupportsHTML {
+		req.HTML = ""
+	}
+	if !capabilities.SupportsAttachments {
+		req.Attachments = nil
+	}
+	if !capabilities.SupportsActions {
+		req.DeepLink = ""
+	}
+	if !capabilities.SupportsImages {
+		req.ImageURL = ""
+	}
+
+	return applyThreading(req, providerType)
+}
+
+// providerRequest is the payload posted to providers, before it is adapted
+// to their kind
+func (n Notification) providerRequest() client.NotificationRequest {
+	return client.NotificationRequest{
+		ID:          n.ID,
+		To:          n.To,
+		Title:       n.Title,
+		Message:     n.Message,
+		ThreadKey:   n.ThreadKey,
+		HTML:        n.HTML,
+		Attachments: toClientAttachments(n.Attachments),
+		DeepLink:    n.DeepLink,
+		ImageURL:    n.ImageURL,
+		Metadata:    n.Metadata,
+	}
+}
+
+func toClientAttachments(attachments []Attachment) []client.Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	result := make([]client.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		result = append(result, client.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			URL:         attachment.URL,
+			Content:     attachment.Content,
+		})
+	}
+	return result
+}
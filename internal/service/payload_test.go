@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptPayload(t *testing.T) {
+	base := client.NotificationRequest{
+		To:          "user@example.com",
+		Title:       "Order shipped",
+		Message:     "Your order is on its way",
+		HTML:        "<p>Your order is on its way</p>",
+		Attachments: []client.Attachment{{Filename: "invoice.pdf", URL: "https://cdn.example.com/invoice.pdf"}},
+		DeepLink:    "app://orders/42",
+		ImageURL:    "https://cdn.example.com/parcel.png",
+		ThreadKey:   "order-42",
+	}
+
+	t.Run("email keeps html and attachments", func(t *testing.T) {
+		req := adaptPayload(base, repository.EmailProvider)
+
+		assert.Equal(t, base.HTML, req.HTML)
+		assert.Equal(t, base.Attachments, req.Attachments)
+		assert.Empty(t, req.DeepLink)
+		assert.Empty(t, req.ImageURL)
+		assert.NotEmpty(t, req.Headers["References"])
+	})
+
+	t.Run("push keeps deep link and image", func(t *testing.T) {
+		req := adaptPayload(base, repository.PushNotificationProvider)
+
+		assert.Empty(t, req.HTML)
+		assert.Nil(t, req.Attachments)
+		assert.Equal(t, base.DeepLink, req.DeepLink)
+		assert.Equal(t, base.ImageURL, req.ImageURL)
+		assert.Equal(t, "order-42", req.CollapseKey)
+	})
+
+	t.Run("plain text fields are untouched", func(t *testing.T) {
+		for _, providerType := range []repository.NotificationProvider{repository.EmailProvider, repository.PushNotificationProvider} {
+			req := adaptPayload(base, providerType)
+
+			assert.Equal(t, base.Title, req.Title)
+			assert.Equal(t, base.Message, req.Message)
+		}
+	})
+}
+
+func TestToClientAttachments(t *testing.T) {
+	assert.Nil(t, toClientAttachments(nil))
+	assert.Equal(t, []client.Attachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf", Content: "JVBERi0="},
+	}, toClientAttachments([]Attachment{
+		{Filename: "invoice.pdf", ContentType: "application/pdf", Content: "JVBERi0="},
+	}))
+}
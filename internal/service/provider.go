@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/mail"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/alert"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/health"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/koungkub/fw-challenge-notification-service/internal/secret"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var _ Channel = (*ProviderChannel)(nil)
+
+// ProviderChannel delivers through the HTTP providers of one provider kind
+// in the notification_preferences table, falling back through them in
+// priority order. Email and push are provider channels
+type ProviderChannel struct {
+	providerType       repository.NotificationProvider
+	cacheProvider      repository.CacheProvider
+	persistentProvider repository.PersistentProvider
+	httpclient         client.HTTPClientProvider
+	metricsCollector   *metrics.NotificationCollector
+	secrets            secret.Provider
+	health             health.Checker
+	notificationLog    repository.NotificationLogProvider
+	costs              CostConfig
+	metadata           MetadataConfig
+	budget             BudgetConfig
+	sandbox            SandboxConfig
+	region             RegionConfig
+	sticky             StickyConfig
+	warmup             WarmupConfig
+	emoji              EmojiConfig
+	alerter            alert.Alerter
+	// random returns a number in [0.0, 1.0) drawing the traffic split and
+	// warm-up
+	random func() float64
+	clock  clock.Clock
+}
+
+type ProviderChannelParams struct {
+	fx.In
+
+	CacheProvider      repository.CacheProvider
+	PersistentProvider repository.PersistentProvider
+	HTTPclient         client.HTTPClientProvider
+	MetricsCollector   *metrics.NotificationCollector
+	Secrets            secret.Provider
+	Health             health.Checker
+	NotificationLog    repository.NotificationLogProvider
+	Costs              CostConfig
+	Metadata           MetadataConfig
+	Budget             BudgetConfig
+	Sandbox            SandboxConfig
+	Region             RegionConfig
+	Sticky             StickyConfig
+	Warmup             WarmupConfig
+	Emoji              EmojiConfig
+	Alerter            alert.Alerter
+	Clock              clock.Clock
+}
+
+func NewEmailChannel(params ProviderChannelParams) *ProviderChannel {
+	return newProviderChannel(repository.EmailProvider, params)
+}
+
+func NewPushChannel(params ProviderChannelParams) *ProviderChannel {
+	return newProviderChannel(repository.PushNotificationProvider, params)
+}
+
+func newProviderChannel(providerType repository.NotificationProvider, params ProviderChannelParams) *ProviderChannel {
+	return &ProviderChannel{
+		providerType:       providerType,
+		cacheProvider:      params.CacheProvider,
+		persistentProvider: params.PersistentProvider,
+		httpclient:         params.HTTPclient,
+		metricsCollector:   params.MetricsCollector,
+		secrets:            params.Secrets,
+		health:             params.Health,
+		notificationLog:    params.NotificationLog,
+		costs:              params.Costs,
+		metadata:           params.Metadata,
+		budget:             params.Budget,
+		sandbox:            params.Sandbox,
+		region:             params.Region,
+		sticky:             params.Sticky,
+		warmup:             params.Warmup,
+		emoji:              params.Emoji,
+		alerter:            params.Alerter,
+		random:             rand.Float64,
+		clock:              params.Clock,
+	}
+}
+
+func (c *ProviderChannel) Name() string {
+	return c.providerType.String()
+}
+
+func (c *ProviderChannel) Capabilities() Capabilities {
+	capabilities, _ := CapabilitiesFor(c.providerType)
+	return capabilities
+}
+
+// Validate accepts any RFC 5322 address for email; push providers own the
+// format of their device tokens
+func (c *ProviderChannel) Validate(recipient string) error {
+	if c.providerType != repository.EmailProvider {
+		return nil
+	}
+
+	if _, err := mail.ParseAddress(recipient); err != nil {
+		return &RecipientError{Channel: c.Name(), Recipient: recipient, Reason: err.Error()}
+	}
+	return nil
+}
+
+func (c *ProviderChannel) Send(ctx context.Context, notification Notification) error {
+	if notification.Sandbox {
+		return c.sendSandbox(ctx, notification.RecipientType, notification.providerRequest())
+	}
+
+	preferences, err := c.getNotificationPreferences(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if len(preferences) == 0 {
+		return &NoProviderError{Channel: c.Name()}
+	}
+
+	return c.sendNotification(ctx, notification.RecipientType, preferences, notification.providerRequest())
+}
+
+// Preview returns the request posted to each provider of the channel, in
+// priority order with the providers of the region of the instance first
+// and, on sticky channels, the provider of the recipient ahead of them. The
+// traffic split, warm-up and health checks are drawn per send, so they are
+// left out, and secret keys are redacted
+func (c *ProviderChannel) Preview(ctx context.Context, notification Notification) ([]Payload, error) {
+	if notification.Sandbox {
+		return c.previewSandbox(notification), nil
+	}
+
+	preferences, err := c.getNotificationPreferences(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if len(preferences) == 0 {
+		return nil, &NoProviderError{Channel: c.Name()}
+	}
+
+	req := c.shapePayload(notification.providerRequest())
+
+	payloads := make([]Payload, 0, len(preferences))
+	for _, preference := range c.order(preferences, req.To) {
+		payload := Payload{
+			ProviderName: preference.ProviderName,
+			Host:         preference.Host,
+			AuthMode:     preference.AuthMode,
+			Request:      req,
+		}
+		if payload.AuthMode == "" {
+			payload.AuthMode = repository.AuthModeSecretKey
+		}
+		if payload.AuthMode == repository.AuthModeSecretKey {
+			payload.Request.SecretKey = RedactedSecretKey
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+// shapePayload is the request posted to the providers of the channel: the
+// metadata is only kept when forwarded and the content adapted to the kind
+func (c *ProviderChannel) shapePayload(req client.NotificationRequest) client.NotificationRequest {
+	if !c.metadata.Forward {
+		req.Metadata = nil
+	}
+	return c.emoji.adapt(adaptPayload(req, c.providerType), c.Name())
+}
+
+func (c *ProviderChannel) getNotificationPreferences(ctx context.Context) ([]repository.NotificationPreference, error) {
+	var (
+		preferences []repository.NotificationPreference
+		err         error
+	)
+
+	preferences, err = c.cacheProvider.Get(c.providerType)
+	if err == nil {
+		return preferences, nil
+	}
+
+	lookupCtx, cancel := c.budget.lookup(ctx)
+	defer cancel()
+
+	preferences, err = c.persistentProvider.FindByProviderType(lookupCtx, c.providerType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// A channel without providers is remembered briefly, so sends that
+		// skip it do not query the database each time
+		c.cacheProvider.Set(c.providerType, []repository.NotificationPreference{})
+		return []repository.NotificationPreference{}, err
+	}
+	if err != nil {
+		return []repository.NotificationPreference{}, err
+	}
+
+	c.cacheProvider.Set(c.providerType, preferences)
+	return preferences, nil
+}
+
+// resolveSecret looks the secret key up within its share of the attempt
+func (c *ProviderChannel) resolveSecret(ctx context.Context, key string) (string, error) {
+	lookupCtx, cancel := c.budget.lookup(ctx)
+	defer cancel()
+
+	return c.secrets.Resolve(lookupCtx, key)
+}
+
+// authorize sets the credentials of req for the provider of preference. A
+// jwt preference is sent a token the client mints, so no key is resolved;
+// otherwise nothing is sent without the key, so a failure is not an attempt
+func (c *ProviderChannel) authorize(ctx context.Context, req *client.NotificationRequest, preference repository.NotificationPreference) error {
+	req.AuthMode = preference.AuthMode
+	if preference.AuthMode == repository.AuthModeJWT {
+		req.SecretKey = ""
+		req.NextSecretKey = ""
+		return nil
+	}
+
+	secretKey, err := c.resolveSecret(ctx, preference.SecretKey)
+	if err != nil {
+		return err
+	}
+	req.SecretKey = secretKey
+	req.NextSecretKey = c.resolveNextSecret(ctx, preference.NextSecretKey)
+	return nil
+}
+
+// resolveNextSecret looks up the key a preference rotates to. The admin API
+// checks it resolves when it is staged, so a failure here is a secret store
+// hiccup; the attempt then goes ahead with the current key alone
+func (c *ProviderChannel) resolveNextSecret(ctx context.Context, key string) string {
+	if key == "" {
+		return ""
+	}
+
+	secretKey, err := c.resolveSecret(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return secretKey
+}
+
+// order puts the providers of the region of the instance first and, on
+// sticky channels, the provider of recipient ahead of them
+func (c *ProviderChannel) order(preferences []repository.NotificationPreference, recipient string) []repository.NotificationPreference {
+	preferences = preferRegion(preferences, c.region.Region)
+	if c.sticky.sticky(c.Name()) {
+		return stickToRecipient(preferences, recipient, c.region.Region)
+	}
+	return preferences
+}
+
+func (c *ProviderChannel) sendNotification(
+	ctx context.Context,
+	recipientType string,
+	preferences []repository.NotificationPreference,
+	req client.NotificationRequest,
+) error {
+	channel := c.Name()
+	var causes []error
+
+	metadata := req.Metadata
+	ctx = client.WithMetadata(ctx, metadata)
+
+	req = c.shapePayload(req)
+	preferences = c.order(preferences, req.To)
+	if !c.sticky.sticky(channel) {
+		preferences = splitTraffic(preferences, c.random())
+	}
+	preferences = c.warmup.warmUp(preferences, c.clock, c.random)
+
+	// Providers marked unhealthy are skipped, unless every one is, so the
+	// health checks never fail a channel that might still deliver
+	healthy := 0
+	for _, preference := range preferences {
+		if c.health.Healthy(preference.Host) {
+			healthy++
+		}
+	}
+	skipUnhealthy := healthy > 0
+	attemptsLeft := len(preferences)
+	if skipUnhealthy {
+		attemptsLeft = healthy
+	}
+
+	for i, preference := range preferences {
+		if skipUnhealthy && !c.health.Healthy(preference.Host) {
+			continue
+		}
+		attemptCtx, cancel := c.budget.attempt(ctx, attemptsLeft)
+		attemptsLeft--
+
+		if err := c.authorize(attemptCtx, &req, preference); err != nil {
+			cancel()
+			causes = append(causes, err)
+			continue
+		}
+
+		c.metricsCollector.RecordAttempt(ctx, recipientType, channel, preference.Host, preference.Region)
+
+		err := c.httpclient.Post(attemptCtx, preference.Host, req)
+		cancel()
+		RecordAttempt(ctx, err)
+		if err != nil {
+			c.metricsCollector.RecordFailure(ctx, recipientType, channel, preference.Host, preference.Region)
+			causes = append(causes, err)
+			continue
+		}
+
+		c.metricsCollector.RecordSuccess(ctx, recipientType, channel, preference.Host, preference.Region, i)
+		if c.region.crossRegion(preference) {
+			c.metricsCollector.RecordCrossRegion(ctx, recipientType, channel, preference.Region)
+		}
+		RecordProvider(ctx, preference.ProviderName, preference.Host)
+
+		tenant := quota.SubjectFrom(ctx)
+		cost := c.costs.Cost(preference.ProviderName)
+		c.metricsCollector.RecordCost(ctx, tenant, channel, preference.ProviderName, cost)
+
+		// The provider has accepted the notification, so a failed write,
+		// already logged by the repository, must not fail the delivery
+		_ = c.notificationLog.RecordNotification(ctx, repository.NotificationLog{
+			NotificationID: req.ID,
+			Channel:        channel,
+			RecipientType:  recipientType,
+			ProviderName:   preference.ProviderName,
+			Recipient:      req.To,
+			Status:         repository.LogStatusSent,
+			Tenant:         tenant,
+			Cost:           cost,
+			Metadata:       metadata,
+		})
+		return nil
+	}
+
+	// A caller giving up is not an outage of the channel, nor are providers
+	// refusing the request
+	if ctx.Err() == nil && outage(causes) {
+		c.metricsCollector.RecordChannelDown(ctx, recipientType, channel)
+		c.alerter.ChannelDown(ctx, alert.Outage{
+			Channel:        channel,
+			RecipientType:  recipientType,
+			NotificationID: req.ID,
+			Providers:      len(preferences),
+			Causes:         causes,
+			Metadata:       metadata,
+		})
+	}
+	return &NotificationError{Channel: channel, Causes: causes}
+}
+
+// outage tells whether the failures point at the providers rather than the
+// request: a 5xx, a timeout, an open breaker or any other error raised
+// before a provider answered. Failures that are all 4xx responses, such as
+// an invalid recipient or throttling, leave the channel up
+func outage(causes []error) bool {
+	for _, cause := range causes {
+		var providerErr *client.ProviderError
+		if !errors.As(cause, &providerErr) || providerErr.StatusCode >= http.StatusInternalServerError {
+			return true
+		}
+	}
+	return false
+}
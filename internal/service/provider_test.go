@@ -0,0 +1,575 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/alert"
+	mockalert "github.com/koungkub/fw-challenge-notification-service/internal/alert/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/sony/gobreaker/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestProviderChannel_getNotificationPreferences(t *testing.T) {
+	tests := []struct {
+		name           string
+		providerType   repository.NotificationProvider
+		setupMocks     func(*mockrepository.MockCacheProvider, *mockrepository.MockPersistentProvider)
+		expectedPrefs  []repository.NotificationPreference
+		expectedError  bool
+		expectedErrMsg string
+		verifyCacheSet bool
+	}{
+		{
+			name:         "returns preferences from cache",
+			providerType: repository.EmailProvider,
+			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				preferences := []repository.NotificationPreference{
+					{Host: "https://email-service.com", SecretKey: "secret1"},
+				}
+				cache.EXPECT().Get(repository.EmailProvider).Return(preferences, nil)
+			},
+			expectedPrefs: []repository.NotificationPreference{
+				{Host: "https://email-service.com", SecretKey: "secret1"},
+			},
+			expectedError:  false,
+			verifyCacheSet: false,
+		},
+		{
+			name:         "fetches from database on cache miss and sets cache",
+			providerType: repository.PushNotificationProvider,
+			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				preferences := []repository.NotificationPreference{
+					{Host: "https://push-service.com", SecretKey: "push-secret"},
+				}
+				cache.EXPECT().Get(repository.PushNotificationProvider).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).Return(preferences, nil)
+				cache.EXPECT().Set(repository.PushNotificationProvider, preferences).Return(nil)
+			},
+			expectedPrefs: []repository.NotificationPreference{
+				{Host: "https://push-service.com", SecretKey: "push-secret"},
+			},
+			expectedError:  false,
+			verifyCacheSet: true,
+		},
+		{
+			name:         "returns error when database fetch fails",
+			providerType: repository.EmailProvider,
+			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(nil, errors.New("database connection error"))
+			},
+			expectedPrefs:  []repository.NotificationPreference{},
+			expectedError:  true,
+			expectedErrMsg: "database connection error",
+			verifyCacheSet: false,
+		},
+		{
+			name:         "returns empty preferences from database and sets cache",
+			providerType: repository.EmailProvider,
+			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				preferences := []repository.NotificationPreference{}
+				cache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(preferences, nil)
+				cache.EXPECT().Set(repository.EmailProvider, preferences).Return(nil)
+			},
+			expectedPrefs:  []repository.NotificationPreference{},
+			expectedError:  false,
+			verifyCacheSet: true,
+		}, {
+			name:         "caches that no provider is configured",
+			providerType: repository.EmailProvider,
+			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return([]repository.NotificationPreference{}, gorm.ErrRecordNotFound)
+				cache.EXPECT().Set(repository.EmailProvider, []repository.NotificationPreference{}).Return(nil)
+			},
+			expectedPrefs:  []repository.NotificationPreference{},
+			expectedError:  true,
+			expectedErrMsg: "record not found",
+			verifyCacheSet: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			tt.setupMocks(mockCache, mockPersistent)
+
+			channel := newProviderChannel(tt.providerType, ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			})
+
+			prefs, err := channel.getNotificationPreferences(context.Background())
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedPrefs, prefs)
+			}
+		})
+	}
+}
+
+func TestProviderChannel_sendNotification(t *testing.T) {
+	tests := []struct {
+		name           string
+		preferences    []repository.NotificationPreference
+		request        client.NotificationRequest
+		setupMocks     func(*mockclient.MockHTTPClientProvider)
+		expectedError  bool
+		expectedErrMsg string
+	}{
+		{
+			name: "returns nil on first success",
+			preferences: []repository.NotificationPreference{
+				{Host: "https://service1.com", SecretKey: "secret1"},
+				{Host: "https://service2.com", SecretKey: "secret2"},
+			},
+			request: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
+				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
+					To:        "user@example.com",
+					Title:     "Test",
+					Message:   "Test message",
+					SecretKey: "secret1",
+				}).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "tries next preference on error and succeeds",
+			preferences: []repository.NotificationPreference{
+				{Host: "https://service1.com", SecretKey: "secret1"},
+				{Host: "https://service2.com", SecretKey: "secret2"},
+			},
+			request: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
+				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
+					To:        "user@example.com",
+					Title:     "Test",
+					Message:   "Test message",
+					SecretKey: "secret1",
+				}).Return(errors.New("connection failed"))
+				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", client.NotificationRequest{
+					To:        "user@example.com",
+					Title:     "Test",
+					Message:   "Test message",
+					SecretKey: "secret2",
+				}).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "returns error when all HTTP requests fail",
+			preferences: []repository.NotificationPreference{
+				{Host: "https://service1.com", SecretKey: "secret1"},
+				{Host: "https://service2.com", SecretKey: "secret2"},
+			},
+			request: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
+				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
+					To:        "user@example.com",
+					Title:     "Test",
+					Message:   "Test message",
+					SecretKey: "secret1",
+				}).Return(errors.New("connection failed"))
+				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", client.NotificationRequest{
+					To:        "user@example.com",
+					Title:     "Test",
+					Message:   "Test message",
+					SecretKey: "secret2",
+				}).Return(errors.New("connection failed"))
+			},
+			expectedError:  true,
+			expectedErrMsg: "failure to sent the notifications",
+		},
+		{
+			name:        "returns error for empty preferences",
+			preferences: []repository.NotificationPreference{},
+			request: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
+				// No HTTP calls expected
+			},
+			expectedError:  true,
+			expectedErrMsg: "failure to sent the notifications",
+		},
+		{
+			name: "tries multiple preferences until success",
+			preferences: []repository.NotificationPreference{
+				{Host: "https://service1.com", SecretKey: "secret1"},
+				{Host: "https://service2.com", SecretKey: "secret2"},
+				{Host: "https://service3.com", SecretKey: "secret3"},
+			},
+			request: client.NotificationRequest{
+				To:      "user@example.com",
+				Title:   "Test",
+				Message: "Test message",
+			},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
+				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", gomock.Any()).Return(errors.New("network error"))
+				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", gomock.Any()).Return(nil)
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			tt.setupMocks(mockHTTPClient)
+
+			channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			})
+
+			err := channel.sendNotification(context.Background(), recipientTypeBuyer, tt.preferences, tt.request)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProviderChannel_sendNotification_SecretKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	mockSecrets := mocksecret.NewMockProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	// An unresolvable key skips its preference without sending anything
+	mockSecrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email1#key").
+		Return("", errors.New("vault returned status 403"))
+	mockSecrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email2#key").
+		Return("resolved-secret", nil)
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service2.com", client.NotificationRequest{
+		To:        "user@example.com",
+		SecretKey: "resolved-secret",
+	}).Return(nil)
+
+	channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          mockSecrets,
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Alerter:          newTestAlerter(ctrl),
+	})
+
+	log := &attemptLog{}
+	ctx := context.WithValue(context.Background(), attemptLogKey{}, log)
+	err := channel.sendNotification(ctx, recipientTypeBuyer, []repository.NotificationPreference{
+		{Host: "https://service1.com", SecretKey: "vault://secret/data/email1#key"},
+		{Host: "https://service2.com", SecretKey: "vault://secret/data/email2#key"},
+	}, client.NotificationRequest{To: "user@example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, log.result.attempts)
+}
+
+func TestProviderChannel_sendNotification_NextSecretKey(t *testing.T) {
+	tests := []struct {
+		name                  string
+		setupSecrets          func(*mocksecret.MockProvider)
+		expectedNextSecretKey string
+	}{
+		{
+			name: "passes the resolved next key",
+			setupSecrets: func(secrets *mocksecret.MockProvider) {
+				secrets.EXPECT().Resolve(gomock.Any(), "old-key").Return("old-key", nil)
+				secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email#next").Return("new-key", nil)
+			},
+			expectedNextSecretKey: "new-key",
+		},
+		{
+			name: "sends with the current key alone when the next key fails to resolve",
+			setupSecrets: func(secrets *mocksecret.MockProvider) {
+				secrets.EXPECT().Resolve(gomock.Any(), "old-key").Return("old-key", nil)
+				secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/email#next").
+					Return("", errors.New("vault returned status 503"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockSecrets := mocksecret.NewMockProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			tt.setupSecrets(mockSecrets)
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
+				To:            "user@example.com",
+				SecretKey:     "old-key",
+				NextSecretKey: tt.expectedNextSecretKey,
+			}).Return(nil)
+
+			channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+				HTTPclient:       mockHTTPClient,
+				MetricsCollector: metricsCollector,
+				Secrets:          mockSecrets,
+				Health:           newTestHealth(ctrl),
+				NotificationLog:  newTestNotificationLog(ctrl),
+				Alerter:          newTestAlerter(ctrl),
+			})
+
+			err := channel.sendNotification(context.Background(), recipientTypeBuyer, []repository.NotificationPreference{
+				{Host: "https://service1.com", SecretKey: "old-key", NextSecretKey: "vault://secret/data/email#next"},
+			}, client.NotificationRequest{To: "user@example.com"})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProviderChannel_sendNotification_JWT(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	// The client mints the token, so no key is resolved or sent
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
+		To:       "user@example.com",
+		AuthMode: repository.AuthModeJWT,
+	}).Return(nil)
+
+	channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          mocksecret.NewMockProvider(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Alerter:          newTestAlerter(ctrl),
+	})
+
+	err := channel.sendNotification(context.Background(), recipientTypeBuyer, []repository.NotificationPreference{
+		{Host: "https://service1.com", SecretKey: "vault://secret/data/email#key", AuthMode: repository.AuthModeJWT},
+	}, client.NotificationRequest{To: "user@example.com"})
+
+	require.NoError(t, err)
+}
+
+func TestProviderChannel_sendNotification_ChannelDown(t *testing.T) {
+	preferences := []repository.NotificationPreference{
+		{Host: "https://service1.com", SecretKey: "secret1"},
+		{Host: "https://service2.com", SecretKey: "secret2"},
+	}
+	providerErr := &client.ProviderError{Host: "https://service2.com", StatusCode: 503}
+
+	t.Run("alerts when every provider fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		mockAlerter := mockalert.NewMockAlerter(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		gomock.InOrder(
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service1.com", gomock.Any()).Return(errors.New("connection refused")),
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service2.com", gomock.Any()).Return(providerErr),
+		)
+		mockAlerter.EXPECT().ChannelDown(gomock.Any(), alert.Outage{
+			Channel:        "Email",
+			RecipientType:  recipientTypeBuyer,
+			NotificationID: testNotificationID,
+			Providers:      2,
+			Causes:         []error{errors.New("connection refused"), providerErr},
+		})
+
+		channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+			HTTPclient:       mockHTTPClient,
+			MetricsCollector: metricsCollector,
+			Secrets:          newTestSecrets(ctrl),
+			Health:           newTestHealth(ctrl),
+			NotificationLog:  newTestNotificationLog(ctrl),
+			Alerter:          mockAlerter,
+		})
+
+		err := channel.sendNotification(context.Background(), recipientTypeBuyer, preferences, client.NotificationRequest{ID: testNotificationID, To: "user@example.com"})
+
+		var notificationErr *NotificationError
+		assert.ErrorAs(t, err, &notificationErr)
+	})
+
+	t.Run("does not alert when the caller gave up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockHTTPClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, string, client.NotificationRequest) error {
+				cancel()
+				return context.Canceled
+			}).Times(2)
+
+		channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+			HTTPclient:       mockHTTPClient,
+			MetricsCollector: metricsCollector,
+			Secrets:          newTestSecrets(ctrl),
+			Health:           newTestHealth(ctrl),
+			NotificationLog:  newTestNotificationLog(ctrl),
+			Alerter:          mockalert.NewMockAlerter(ctrl),
+		})
+
+		err := channel.sendNotification(ctx, recipientTypeBuyer, preferences, client.NotificationRequest{To: "user@example.com"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("does not alert when every provider refused the request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		gomock.InOrder(
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service1.com", gomock.Any()).
+				Return(&client.ProviderError{Host: "https://service1.com", StatusCode: 400}),
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://service2.com", gomock.Any()).
+				Return(&client.ProviderError{Host: "https://service2.com", StatusCode: 429, Throttled: true}),
+		)
+
+		channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+			HTTPclient:       mockHTTPClient,
+			MetricsCollector: metricsCollector,
+			Secrets:          newTestSecrets(ctrl),
+			Health:           newTestHealth(ctrl),
+			NotificationLog:  newTestNotificationLog(ctrl),
+			Alerter:          mockalert.NewMockAlerter(ctrl),
+		})
+
+		err := channel.sendNotification(context.Background(), recipientTypeBuyer, preferences, client.NotificationRequest{To: "user@example.com"})
+
+		var notificationErr *NotificationError
+		assert.ErrorAs(t, err, &notificationErr)
+	})
+}
+
+func TestOutage(t *testing.T) {
+	tests := []struct {
+		name     string
+		causes   []error
+		expected bool
+	}{
+		{name: "server error", causes: []error{&client.ProviderError{StatusCode: 400}, &client.ProviderError{StatusCode: 502}}, expected: true},
+		{name: "timeout", causes: []error{context.DeadlineExceeded}, expected: true},
+		{name: "open breaker", causes: []error{gobreaker.ErrOpenState}, expected: true},
+		{name: "rejected and throttled", causes: []error{&client.ProviderError{StatusCode: 400}, &client.ProviderError{StatusCode: 429}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, outage(tt.causes))
+		})
+	}
+}
+
+func TestProviderChannel_getNotificationPreferences_ContextCancellation(t *testing.T) {
+	t.Run("handles context cancellation during database fetch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockCache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
+		mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).DoAndReturn(func(ctx context.Context, provider repository.NotificationProvider) ([]repository.NotificationPreference, error) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, errors.New("context should be cancelled")
+		})
+
+		channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+			CacheProvider:      mockCache,
+			PersistentProvider: mockPersistent,
+			HTTPclient:         mockHTTPClient,
+			MetricsCollector:   metricsCollector,
+			Secrets:            newTestSecrets(ctrl),
+			Health:             newTestHealth(ctrl),
+			NotificationLog:    newTestNotificationLog(ctrl),
+			Alerter:            newTestAlerter(ctrl),
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := channel.getNotificationPreferences(ctx)
+
+		require.Error(t, err)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
@@ -0,0 +1,37 @@
+package service
+
+import "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+
+type RegionConfig struct {
+	// Region is where this instance runs, e.g. ap-southeast-1. Providers of
+	// this region are tried first, those of other regions only once they
+	// failed; empty keeps the plain priority order
+	Region string `envconfig:"APP_REGION"`
+}
+
+// preferRegion moves the preferences of region ahead of the others, keeping
+// the priority order within both, so other regions are only failed over to
+func preferRegion(preferences []repository.NotificationPreference, region string) []repository.NotificationPreference {
+	if region == "" {
+		return preferences
+	}
+
+	ordered := make([]repository.NotificationPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		if preference.Region == region {
+			ordered = append(ordered, preference)
+		}
+	}
+	for _, preference := range preferences {
+		if preference.Region != region {
+			ordered = append(ordered, preference)
+		}
+	}
+	return ordered
+}
+
+// crossRegion tells whether a preference serves from another region than
+// the instance; preferences without a region belong to none
+func (c RegionConfig) crossRegion(preference repository.NotificationPreference) bool {
+	return c.Region != "" && preference.Region != "" && preference.Region != c.Region
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPreferRegion(t *testing.T) {
+	preferences := []repository.NotificationPreference{
+		{Host: "a", Region: "us-east-1"},
+		{Host: "b"},
+		{Host: "c", Region: "ap-southeast-1"},
+		{Host: "d", Region: "ap-southeast-1"},
+	}
+
+	tests := []struct {
+		name          string
+		region        string
+		expectedHosts []string
+	}{
+		{
+			name:          "tries the providers of the region first, in priority order",
+			region:        "ap-southeast-1",
+			expectedHosts: []string{"c", "d", "a", "b"},
+		},
+		{
+			name:          "keeps priority order without a region",
+			expectedHosts: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:          "keeps priority order when no provider is in the region",
+			region:        "eu-west-1",
+			expectedHosts: []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hosts []string
+			for _, preference := range preferRegion(preferences, tt.region) {
+				hosts = append(hosts, preference.Host)
+			}
+
+			assert.Equal(t, tt.expectedHosts, hosts)
+		})
+	}
+}
+
+func TestProviderChannel_sendNotification_RegionFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	// The secondary region is only tried once the primary one failed
+	gomock.InOrder(
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://ap.email.com", gomock.Any()).
+			Return(&client.ProviderError{StatusCode: 503}),
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://us.email.com", gomock.Any()).
+			Return(nil),
+	)
+
+	notificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+	notificationLog.EXPECT().RecordNotification(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry repository.NotificationLog) error {
+			assert.Equal(t, "us-provider", entry.ProviderName)
+			return nil
+		})
+
+	channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          newTestSecrets(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  notificationLog,
+		Region:           RegionConfig{Region: "ap-southeast-1"},
+		Alerter:          newTestAlerter(ctrl),
+	})
+
+	err := channel.sendNotification(context.Background(), recipientTypeBuyer, []repository.NotificationPreference{
+		{ProviderName: "us-provider", Host: "https://us.email.com", Region: "us-east-1"},
+		{ProviderName: "ap-provider", Host: "https://ap.email.com", Region: "ap-southeast-1", Priority: 1},
+	}, client.NotificationRequest{To: "user@example.com"})
+
+	require.NoError(t, err)
+}
+
+func TestRegionConfig_crossRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   RegionConfig
+		region   string
+		expected bool
+	}{
+		{name: "another region", config: RegionConfig{Region: "ap-southeast-1"}, region: "us-east-1", expected: true},
+		{name: "the same region", config: RegionConfig{Region: "ap-southeast-1"}, region: "ap-southeast-1"},
+		{name: "a provider without region", config: RegionConfig{Region: "ap-southeast-1"}},
+		{name: "an instance without region", region: "us-east-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preference := repository.NotificationPreference{Region: tt.region}
+			assert.Equal(t, tt.expected, tt.config.crossRegion(preference))
+		})
+	}
+}
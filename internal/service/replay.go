@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MetadataReplayOf is the metadata key naming the notification a replay
+// sends again
+const MetadataReplayOf = "replay_of"
+
+type ReplayConfig struct {
+	// Enabled keeps the content of every accepted notification in the
+	// notification_contents table, so it can be replayed
+	Enabled bool `envconfig:"REPLAY_ENABLED" default:"false"`
+	// Retention is how long contents are kept before they are purged
+	Retention     time.Duration `envconfig:"REPLAY_RETENTION" default:"720h"`
+	PurgeInterval time.Duration `envconfig:"REPLAY_PURGE_INTERVAL" default:"1h"`
+	// BufferSize bounds the contents waiting to be written; contents
+	// beyond it are dropped rather than slowing down Send
+	BufferSize int `envconfig:"REPLAY_BUFFER_SIZE" default:"1000"`
+}
+
+// ReplayOptions change a replayed notification; zero fields keep it as it
+// was sent
+type ReplayOptions struct {
+	// To overrides the recipient address
+	To string
+	// Sandbox keeps the replay from real providers, e.g. for a sandbox API
+	// key; a sandbox notification is always replayed in the sandbox
+	Sandbox bool
+}
+
+// keepContent hands the notification to the content keeper for replays,
+// when enabled. It is written in the background, so a failed or dropped
+// write does not fail or slow down the notification
+func (s *NotificationService) keepContent(ctx context.Context, id string, recipientType string, notification Notification) {
+	if !s.replay.Enabled {
+		return
+	}
+
+	notification.Attachments = keptAttachments(notification.Attachments)
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+
+	s.keeper.Keep(repository.NotificationContent{
+		NotificationID: id,
+		RecipientType:  recipientType,
+		Tenant:         quota.SubjectFrom(ctx),
+		Notification:   payload,
+	})
+}
+
+// Replay sends a kept notification again as a new notification, through
+// every check of Send. Only the tenant that sent a notification may replay
+// it; the message id is dropped so the replay is not taken for a duplicate
+func (s *NotificationService) Replay(ctx context.Context, id string, options ReplayOptions) (DeliveryReport, error) {
+	content, err := s.contents.FindNotificationContent(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && content.Tenant != quota.SubjectFrom(ctx) {
+		err = &UnknownNotificationError{NotificationID: id}
+		return DeliveryReport{RetryDisposition: RetryDoNotRetry}, err
+	}
+	if err != nil {
+		return DeliveryReport{}.finish(err), err
+	}
+
+	var notification Notification
+	if err := json.Unmarshal(content.Notification, &notification); err != nil {
+		return DeliveryReport{}.finish(err), err
+	}
+	if inlineAttachmentsDropped(notification.Attachments) {
+		err = &UnreplayableError{NotificationID: id, Reason: "the content of its inline attachments was not kept"}
+		return DeliveryReport{RetryDisposition: RetryDoNotRetry}, err
+	}
+
+	notification.MessageID = ""
+	if options.To != "" {
+		notification.To = options.To
+	}
+	notification.Sandbox = notification.Sandbox || options.Sandbox
+
+	metadata := maps.Clone(notification.Metadata)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[MetadataReplayOf] = id
+	notification.Metadata = metadata
+
+	return s.Send(ctx, content.RecipientType, notification)
+}
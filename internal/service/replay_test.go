@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestNotificationService_Replay(t *testing.T) {
+	const originalID = "01JB8Z4AAAAAAAAAAAAAAAAAAA"
+
+	kept, err := json.Marshal(Notification{
+		MessageID: "order-42-shipped",
+		To:        "buyer@example.com",
+		Title:     "Order shipped",
+		Message:   "Your order is on its way",
+		Metadata:  map[string]string{"order_id": "42"},
+	})
+	require.NoError(t, err)
+
+	content := repository.NotificationContent{
+		NotificationID: originalID,
+		RecipientType:  recipientTypeBuyer,
+		Tenant:         "acme",
+		Notification:   kept,
+	}
+
+	withAttachment, err := json.Marshal(Notification{
+		To:          "buyer@example.com",
+		Title:       "Invoice",
+		Message:     "Your invoice is attached",
+		Attachments: keptAttachments([]Attachment{{Filename: "invoice.pdf", Content: "JVBERi0="}}),
+	})
+	require.NoError(t, err)
+	contentWithAttachment := content
+	contentWithAttachment.Notification = withAttachment
+
+	tests := []struct {
+		name            string
+		tenant          string
+		options         ReplayOptions
+		found           bool
+		content         repository.NotificationContent
+		findErr         error
+		expectedTo      string
+		expectedPost    bool
+		expectedErrorAs any
+	}{
+		{
+			name:         "sends the kept notification again without its message id",
+			tenant:       "acme",
+			found:        true,
+			expectedTo:   "buyer@example.com",
+			expectedPost: true,
+		},
+		{
+			name:         "sends to an overridden recipient",
+			tenant:       "acme",
+			options:      ReplayOptions{To: "other@example.com"},
+			found:        true,
+			expectedTo:   "other@example.com",
+			expectedPost: true,
+		},
+		{
+			name:    "keeps a sandbox replay from the providers",
+			tenant:  "acme",
+			options: ReplayOptions{Sandbox: true},
+			found:   true,
+		},
+		{
+			name:            "refuses a notification whose content was not kept",
+			tenant:          "acme",
+			findErr:         gorm.ErrRecordNotFound,
+			expectedErrorAs: new(*UnknownNotificationError),
+		},
+		{
+			name:            "refuses a notification whose inline attachments were not kept",
+			tenant:          "acme",
+			found:           true,
+			content:         contentWithAttachment,
+			expectedErrorAs: new(*UnreplayableError),
+		},
+		{
+			name:            "refuses a notification of another tenant",
+			tenant:          "globex",
+			found:           true,
+			expectedErrorAs: new(*UnknownNotificationError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockContents := mockrepository.NewMockNotificationContentProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			if tt.found {
+				kept := content
+				if tt.content.NotificationID != "" {
+					kept = tt.content
+				}
+				mockContents.EXPECT().FindNotificationContent(gomock.Any(), originalID).Return(kept, nil)
+			} else {
+				mockContents.EXPECT().FindNotificationContent(gomock.Any(), originalID).Return(repository.NotificationContent{}, tt.findErr)
+			}
+			if tt.expectedPost {
+				mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+					{Host: "https://email.com", SecretKey: "secret"},
+				}, nil)
+				mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.com", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, req client.NotificationRequest) error {
+						assert.Equal(t, tt.expectedTo, req.To)
+						assert.Equal(t, map[string]string{"order_id": "42", MetadataReplayOf: originalID}, req.Metadata)
+						return nil
+					})
+			}
+
+			// Replay is disabled, so the replay itself is not kept, and the
+			// message id is dropped, so no message is claimed
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Contents:         mockContents,
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Metadata:         MetadataConfig{Forward: true},
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			ctx := quota.WithSubject(context.Background(), tt.tenant)
+			report, err := service.Replay(ctx, originalID, tt.options)
+
+			if tt.expectedErrorAs != nil {
+				require.Error(t, err)
+				assert.True(t, errors.As(err, tt.expectedErrorAs))
+				assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testNotificationID, report.ID)
+		})
+	}
+}
+
+func TestNotificationService_KeepsContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ReplayConfig
+	}{
+		{name: "keeps nothing by default"},
+		{
+			name:   "keeps the accepted notification when enabled",
+			config: ReplayConfig{Enabled: true, Retention: 24 * time.Hour, PurgeInterval: time.Hour, BufferSize: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockContents := mockrepository.NewMockNotificationContentProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email.com", SecretKey: "secret"},
+			}, nil)
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.com", gomock.Any()).Return(nil)
+			keeper := NewContentKeeperWithConfig(tt.config, mockContents, clock.NewRealClock(), zap.NewNop())
+			if tt.config.Enabled {
+				keeper.Start()
+				mockContents.EXPECT().StoreNotificationContent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, content repository.NotificationContent) error {
+						assert.Equal(t, testNotificationID, content.NotificationID)
+						assert.Equal(t, recipientTypeBuyer, content.RecipientType)
+						assert.Equal(t, "acme", content.Tenant)
+
+						var notification Notification
+						require.NoError(t, json.Unmarshal(content.Notification, &notification))
+						assert.Equal(t, "buyer@example.com", notification.To)
+						assert.Equal(t, "Order shipped", notification.Title)
+						assert.Equal(t, []Attachment{{Filename: "invoice.pdf"}, {Filename: "label.pdf", URL: "https://cdn.example.com/label.pdf"}}, notification.Attachments)
+						return nil
+					})
+			}
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Contents:         mockContents,
+				Keeper:           keeper,
+				Replay:           tt.config,
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			ctx := quota.WithSubject(context.Background(), "acme")
+			_, err := service.Send(ctx, recipientTypeBuyer, Notification{
+				To:      "buyer@example.com",
+				Title:   "Order shipped",
+				Message: "Your order is on its way",
+				Attachments: []Attachment{
+					{Filename: "invoice.pdf", Content: "JVBERi0="},
+					{Filename: "label.pdf", URL: "https://cdn.example.com/label.pdf"},
+				},
+			})
+
+			require.NoError(t, err)
+			// The content is written in the background, at the latest on stop
+			require.NoError(t, keeper.Stop(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import "github.com/koungkub/fw-challenge-notification-service/internal/client"
+
+// Retry dispositions tell callers whether resending a request is safe
+const (
+	// RetryNotNeeded means the notification was delivered
+	RetryNotNeeded = "not_needed"
+	// RetrySafe means no provider accepted the notification
+	RetrySafe = "safe"
+	// RetryUnsafe means some provider may have accepted the notification, so
+	// a retry risks a duplicate
+	RetryUnsafe = "unsafe"
+	// RetryDoNotRetry means the request itself was rejected
+	RetryDoNotRetry = "do_not_retry"
+)
+
+// Delivery statuses of a single channel
+const (
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+	// DeliveryStatusQueued is a failed channel queued for retry, by partial
+	// success or the retry queue
+	DeliveryStatusQueued = "queued"
+	// DeliveryStatusSkipped is an optional channel without providers
+	DeliveryStatusSkipped = "skipped"
+)
+
+// DeliveryReport summarizes what happened to a notification request
+type DeliveryReport struct {
+	ID       string
+	Attempts int
+	// Channels lists the channels that delivered the notification
+	Channels []string
+	// Deliveries holds the outcome of every channel the notification was
+	// routed to
+	Deliveries       []ChannelDelivery
+	RetryDisposition string
+	// Duplicate is set when the report is that of an earlier notification
+	// with the same message id
+	Duplicate bool
+	// Digested is set when the notification waits for the digest of its
+	// recipient instead of being sent
+	Digested bool
+	// Partial is set when some channels failed while others delivered and
+	// partial success is enabled
+	Partial bool
+	// Retrying is set when no channel delivered and the failed channels were
+	// queued for retry by the retry queue
+	Retrying bool
+}
+
+// ChannelDelivery is the outcome of one channel. ProviderName and
+// ProviderHost name the provider that accepted the notification; they are
+// empty for failed channels and for channels delivering without a provider
+type ChannelDelivery struct {
+	Channel      string
+	Status       string
+	ProviderName string
+	ProviderHost string
+	Attempts     int
+}
+
+// channelResult is the outcome of delivering to a single channel
+type channelResult struct {
+	channel   string
+	attempts  int
+	delivered bool
+	// ambiguous is set when a failed attempt may still have reached the provider
+	ambiguous bool
+	// queued is set when the failed channel was queued for retry
+	queued bool
+	// rejected is set when a provider refused the notification for good, so
+	// a retry would be refused again
+	rejected bool
+	// skipped is set when the channel is optional and has no provider
+	skipped      bool
+	providerName string
+	providerHost string
+}
+
+// delivery is the result as reported to callers
+func (r channelResult) delivery() ChannelDelivery {
+	delivery := ChannelDelivery{
+		Channel:  r.channel,
+		Status:   DeliveryStatusFailed,
+		Attempts: r.attempts,
+	}
+	switch {
+	case r.queued:
+		delivery.Status = DeliveryStatusQueued
+	case r.skipped:
+		delivery.Status = DeliveryStatusSkipped
+	}
+	if r.delivered {
+		delivery.Status = DeliveryStatusDelivered
+		delivery.ProviderName = r.providerName
+		delivery.ProviderHost = r.providerHost
+	}
+	return delivery
+}
+
+func (r channelResult) record(err error) channelResult {
+	r.attempts++
+	if err == nil {
+		r.delivered = true
+	} else if !client.Undelivered(err) {
+		r.ambiguous = true
+	} else if client.Rejected(err) {
+		r.rejected = true
+	}
+	return r
+}
+
+// finish folds channel results into the report and derives the retry
+// disposition from the overall error
+func (r DeliveryReport) finish(err error, results ...channelResult) DeliveryReport {
+	var ambiguous, rejected, retriable bool
+	for _, result := range results {
+		r.Attempts += result.attempts
+		r.Deliveries = append(r.Deliveries, result.delivery())
+		if result.delivered {
+			r.Channels = append(r.Channels, result.channel)
+		}
+		ambiguous = ambiguous || result.ambiguous
+		rejected = rejected || result.rejected
+		retriable = retriable || !result.delivered && !result.rejected && !result.skipped
+	}
+
+	switch {
+	case err == nil:
+		r.RetryDisposition = RetryNotNeeded
+	case len(r.Channels) > 0 || ambiguous:
+		r.RetryDisposition = RetryUnsafe
+	case rejected && !retriable:
+		// Every failed channel would be refused again
+		r.RetryDisposition = RetryDoNotRetry
+	default:
+		r.RetryDisposition = RetrySafe
+	}
+
+	return r
+}
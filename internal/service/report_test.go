@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliveryReport_finish(t *testing.T) {
+	rejected := &client.ProviderError{StatusCode: http.StatusBadRequest}
+	throttled := &client.ProviderError{StatusCode: http.StatusTooManyRequests}
+	timeout := context.DeadlineExceeded
+
+	tests := []struct {
+		name                string
+		err                 error
+		results             []channelResult
+		expectedAttempts    int
+		expectedChannels    []string
+		expectedDeliveries  []ChannelDelivery
+		expectedDisposition string
+	}{
+		{
+			name: "delivered",
+			results: []channelResult{
+				channelResult{channel: "Email", providerName: "sendgrid", providerHost: "https://email.example.com"}.record(rejected).record(nil),
+			},
+			expectedAttempts: 2,
+			expectedChannels: []string{"Email"},
+			expectedDeliveries: []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusDelivered, ProviderName: "sendgrid", ProviderHost: "https://email.example.com", Attempts: 2},
+			},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name: "every provider rejected the request",
+			err:  errors.New("failure to sent the notifications"),
+			results: []channelResult{
+				channelResult{channel: "Email"}.record(rejected).record(rejected),
+			},
+			expectedAttempts:    2,
+			expectedDisposition: RetryDoNotRetry,
+		},
+		{
+			name: "a channel was throttled while another was rejected",
+			err:  errors.New("failure to sent the notifications"),
+			results: []channelResult{
+				channelResult{channel: "Email"}.record(rejected),
+				channelResult{channel: "PushNotification"}.record(throttled),
+			},
+			expectedAttempts:    2,
+			expectedDisposition: RetrySafe,
+		},
+		{
+			name:                "failed before any attempt",
+			err:                 errors.New("database unavailable"),
+			expectedDisposition: RetrySafe,
+		},
+		{
+			name: "provider may have received the request",
+			err:  errors.New("failure to sent the notifications"),
+			results: []channelResult{
+				channelResult{channel: "Email"}.record(timeout),
+			},
+			expectedAttempts:    1,
+			expectedDisposition: RetryUnsafe,
+		},
+		{
+			name: "provider failed with a server error",
+			err:  errors.New("failure to sent the notifications"),
+			results: []channelResult{
+				channelResult{channel: "Email"}.record(rejected).record(&client.ProviderError{StatusCode: http.StatusBadGateway}),
+			},
+			expectedAttempts:    2,
+			expectedDisposition: RetryUnsafe,
+		},
+		{
+			name: "one channel delivered while another failed",
+			err:  errors.New("failure to sent the notifications"),
+			results: []channelResult{
+				channelResult{channel: "Email"}.record(nil),
+				channelResult{channel: "PushNotification"}.record(rejected),
+			},
+			expectedAttempts: 2,
+			expectedChannels: []string{"Email"},
+			expectedDeliveries: []ChannelDelivery{
+				{Channel: "Email", Status: DeliveryStatusDelivered, Attempts: 1},
+				{Channel: "PushNotification", Status: DeliveryStatusFailed, Attempts: 1},
+			},
+			expectedDisposition: RetryUnsafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := DeliveryReport{ID: testNotificationID}.finish(tt.err, tt.results...)
+
+			assert.Equal(t, testNotificationID, report.ID)
+			assert.Equal(t, tt.expectedAttempts, report.Attempts)
+			assert.Equal(t, tt.expectedChannels, report.Channels)
+			if tt.expectedDeliveries != nil {
+				assert.Equal(t, tt.expectedDeliveries, report.Deliveries)
+			}
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+		})
+	}
+}
+
+func TestNotificationService_DeliveryReport(t *testing.T) {
+	t.Run("seller report covers both channels", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+			{Host: "https://email1.example.com"},
+			{Host: "https://email2.example.com", ProviderName: "email2"},
+		}, nil)
+		mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+			{Host: "https://push.example.com", ProviderName: "push"},
+		}, nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email1.example.com", gomock.Any()).
+			Return(&client.ProviderError{StatusCode: http.StatusServiceUnavailable})
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email2.example.com", gomock.Any()).Return(nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://push.example.com", gomock.Any()).Return(nil)
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockPersistent,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), recipientTypeSeller, Notification{To: "seller@example.com", Title: "Test", Message: "Test message"})
+
+		require.NoError(t, err)
+		assert.Equal(t, testNotificationID, report.ID)
+		assert.Equal(t, 3, report.Attempts)
+		assert.ElementsMatch(t, []string{"Email", "PushNotification"}, report.Channels)
+		assert.ElementsMatch(t, []ChannelDelivery{
+			{Channel: "Email", Status: DeliveryStatusDelivered, ProviderName: "email2", ProviderHost: "https://email2.example.com", Attempts: 2},
+			{Channel: "PushNotification", Status: DeliveryStatusDelivered, ProviderName: "push", ProviderHost: "https://push.example.com", Attempts: 1},
+		}, report.Deliveries)
+		assert.Equal(t, RetryNotNeeded, report.RetryDisposition)
+	})
+
+	t.Run("capability rejection must not be retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockrepository.NewMockCacheProvider(ctrl),
+				PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+				HTTPclient:         mockclient.NewMockHTTPClientProvider(ctrl),
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: strings.Repeat("a", 1000), Message: "Test message"})
+
+		require.Error(t, err)
+		assert.Equal(t, 0, report.Attempts)
+		assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+	})
+}
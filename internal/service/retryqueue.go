@@ -0,0 +1,38 @@
+package service
+
+import "context"
+
+type RetryQueueConfig struct {
+	// Enabled accepts a notification no channel delivered, queueing its
+	// channels for retry, instead of failing it. Retries follow
+	// CHANNEL_RETRY_INTERVAL and CHANNEL_RETRY_MAX_ATTEMPTS
+	Enabled bool `envconfig:"RETRY_QUEUE_ENABLED" default:"false"`
+}
+
+// queueUndelivered queues every channel of a notification no provider
+// accepted, and tells whether any was queued; those that could not be are
+// left failed, as their queued siblings already commit the notification. A
+// notification a provider may have accepted is not queued, as a retry
+// risks a duplicate, nor one refused for good by a provider, e.g. with a
+// 400 for an invalid recipient, which the caller gets back as the error
+func (s *NotificationService) queueUndelivered(
+	ctx context.Context,
+	recipientType string,
+	notification Notification,
+	results []channelResult,
+) ([]channelResult, bool) {
+	for _, result := range results {
+		if result.delivered || result.ambiguous || result.rejected {
+			return results, false
+		}
+	}
+
+	results = s.queueFailedChannels(ctx, recipientType, notification, results)
+
+	for _, result := range results {
+		if result.queued {
+			return results, true
+		}
+	}
+	return results, false
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNotificationService_RetryQueue(t *testing.T) {
+	throttled := &client.ProviderError{StatusCode: http.StatusTooManyRequests}
+	timeout := errors.New("read timeout")
+	rejected := &client.ProviderError{StatusCode: http.StatusBadRequest}
+	notification := Notification{To: "seller@example.com", Title: "New order", Message: "Order 42 is waiting"}
+
+	tests := []struct {
+		name                string
+		config              RetryQueueConfig
+		emailErr            error
+		setupMocks          func(*mockrepository.MockChannelRetryProvider)
+		expectError         bool
+		expectedRetrying    bool
+		expectedDisposition string
+		expectedStatuses    map[string]string
+	}{
+		{
+			name:     "queues every channel and accepts the notification",
+			config:   RetryQueueConfig{Enabled: true},
+			emailErr: throttled,
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider) {
+				retries.EXPECT().AddChannelRetry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, retry repository.ChannelRetry) error {
+						assert.Equal(t, testNotificationID, retry.NotificationID)
+						assert.Equal(t, recipientTypeSeller, retry.RecipientType)
+						return nil
+					}).Times(2)
+			},
+			expectedRetrying:    true,
+			expectedDisposition: RetryNotNeeded,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusQueued, "PushNotification": DeliveryStatusQueued},
+		},
+		{
+			name:     "accepts the notification with a channel whose retry could not be stored",
+			config:   RetryQueueConfig{Enabled: true},
+			emailErr: throttled,
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider) {
+				retries.EXPECT().AddChannelRetry(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, retry repository.ChannelRetry) error {
+						if retry.Channel == "Email" {
+							return errors.New("database down")
+						}
+						return nil
+					}).Times(2)
+			},
+			expectedRetrying:    true,
+			expectedDisposition: RetryNotNeeded,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusFailed, "PushNotification": DeliveryStatusQueued},
+		},
+		{
+			name:     "fails the notification when no retry could be stored",
+			config:   RetryQueueConfig{Enabled: true},
+			emailErr: throttled,
+			setupMocks: func(retries *mockrepository.MockChannelRetryProvider) {
+				retries.EXPECT().AddChannelRetry(gomock.Any(), gomock.Any()).Return(errors.New("database down")).Times(2)
+			},
+			expectError:         true,
+			expectedDisposition: RetrySafe,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusFailed, "PushNotification": DeliveryStatusFailed},
+		},
+		{
+			name:                "fails a notification a provider may have accepted",
+			config:              RetryQueueConfig{Enabled: true},
+			emailErr:            timeout,
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectError:         true,
+			expectedDisposition: RetryUnsafe,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusFailed, "PushNotification": DeliveryStatusFailed},
+		},
+		{
+			name:                "fails a notification a provider refused for good",
+			config:              RetryQueueConfig{Enabled: true},
+			emailErr:            rejected,
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectError:         true,
+			expectedDisposition: RetrySafe,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusFailed, "PushNotification": DeliveryStatusFailed},
+		},
+		{
+			name:                "fails the notification when disabled",
+			emailErr:            throttled,
+			setupMocks:          func(*mockrepository.MockChannelRetryProvider) {},
+			expectError:         true,
+			expectedDisposition: RetrySafe,
+			expectedStatuses:    map[string]string{"Email": DeliveryStatusFailed, "PushNotification": DeliveryStatusFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRetries := mockrepository.NewMockChannelRetryProvider(ctrl)
+			tt.setupMocks(mockRetries)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Retries:          mockRetries,
+				RetryQueue:       tt.config,
+				Channels: []Channel{
+					&fakeChannel{name: "Email", attempts: []error{tt.emailErr}, sendErr: tt.emailErr},
+					&fakeChannel{name: "PushNotification", attempts: []error{throttled}, sendErr: throttled},
+				},
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeSeller, notification)
+
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedRetrying, report.Retrying)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+			assert.Empty(t, report.Channels)
+			statuses := make(map[string]string, len(report.Deliveries))
+			for _, delivery := range report.Deliveries {
+				statuses[delivery.Channel] = delivery.Status
+			}
+			assert.Equal(t, tt.expectedStatuses, statuses)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+// SandboxProvider is the provider sandbox notifications are reported and
+// logged as sent by
+const SandboxProvider = "sandbox"
+
+type SandboxConfig struct {
+	// Host receives the notifications of sandbox API keys in place of the
+	// real providers, e.g. the mock provider; without it they are accepted
+	// without any request
+	Host string `envconfig:"SANDBOX_PROVIDER_HOST"`
+	// SecretKey is sent to Host, either literally or as a secret reference
+	SecretKey string `envconfig:"SANDBOX_PROVIDER_SECRET_KEY" secret:"true"`
+}
+
+// sendSandbox delivers a sandbox notification to the sandbox host, or
+// accepts it without any attempt when none is configured. It is processed
+// and logged like any notification, as sent by the sandbox provider at no
+// cost, so partners test the whole flow without reaching a recipient
+func (c *ProviderChannel) sendSandbox(ctx context.Context, recipientType string, req client.NotificationRequest) error {
+	channel := c.Name()
+
+	metadata := req.Metadata
+	ctx = client.WithMetadata(ctx, metadata)
+	req = c.shapePayload(req)
+
+	if c.sandbox.Host != "" {
+		attemptCtx, cancel := c.budget.attempt(ctx, 1)
+		defer cancel()
+
+		secretKey, err := c.resolveSecret(attemptCtx, c.sandbox.SecretKey)
+		if err != nil {
+			return &NotificationError{Channel: channel, Causes: []error{err}}
+		}
+		req.SecretKey = secretKey
+
+		c.metricsCollector.RecordAttempt(ctx, recipientType, channel, c.sandbox.Host, "")
+
+		err = c.httpclient.Post(attemptCtx, c.sandbox.Host, req)
+		RecordAttempt(ctx, err)
+		if err != nil {
+			c.metricsCollector.RecordFailure(ctx, recipientType, channel, c.sandbox.Host, "")
+			return &NotificationError{Channel: channel, Causes: []error{err}}
+		}
+
+		c.metricsCollector.RecordSuccess(ctx, recipientType, channel, c.sandbox.Host, "", 0)
+	}
+
+	RecordProvider(ctx, SandboxProvider, c.sandbox.Host)
+
+	// As with real providers, a failed write must not fail the delivery
+	_ = c.notificationLog.RecordNotification(ctx, repository.NotificationLog{
+		NotificationID: req.ID,
+		Channel:        channel,
+		RecipientType:  recipientType,
+		ProviderName:   SandboxProvider,
+		Recipient:      req.To,
+		Status:         repository.LogStatusSent,
+		Tenant:         quota.SubjectFrom(ctx),
+		Metadata:       metadata,
+	})
+	return nil
+}
+
+// previewSandbox is the payload sendSandbox would post, none without a
+// sandbox host
+func (c *ProviderChannel) previewSandbox(notification Notification) []Payload {
+	if c.sandbox.Host == "" {
+		return nil
+	}
+
+	req := c.shapePayload(notification.providerRequest())
+	req.SecretKey = RedactedSecretKey
+	return []Payload{{
+		ProviderName: SandboxProvider,
+		Host:         c.sandbox.Host,
+		AuthMode:     repository.AuthModeSecretKey,
+		Request:      req,
+	}}
+}
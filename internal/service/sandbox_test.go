@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProviderChannel_Send_Sandbox(t *testing.T) {
+	notification := Notification{
+		ID:            testNotificationID,
+		RecipientType: recipientTypeBuyer,
+		To:            "buyer@example.com",
+		Title:         "Order shipped",
+		Message:       "Your order is on its way",
+		Sandbox:       true,
+	}
+
+	tests := []struct {
+		name               string
+		config             SandboxConfig
+		setupMocks         func(*mockclient.MockHTTPClientProvider, *mocksecret.MockProvider)
+		expectedError      bool
+		expectedAttempts   int
+		expectedProvider   string
+		expectedHost       string
+		expectedLogEntries int
+	}{
+		{
+			name:   "posts to the sandbox host instead of the providers",
+			config: SandboxConfig{Host: "https://sandbox.example.com", SecretKey: "vault://secret/data/sandbox#key"},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider, secrets *mocksecret.MockProvider) {
+				secrets.EXPECT().Resolve(gomock.Any(), "vault://secret/data/sandbox#key").Return("sandbox-secret", nil)
+				httpClient.EXPECT().Post(gomock.Any(), "https://sandbox.example.com", client.NotificationRequest{
+					ID:        testNotificationID,
+					To:        "buyer@example.com",
+					Title:     "Order shipped",
+					Message:   "Your order is on its way",
+					SecretKey: "sandbox-secret",
+				}).Return(nil)
+			},
+			expectedAttempts:   1,
+			expectedProvider:   SandboxProvider,
+			expectedHost:       "https://sandbox.example.com",
+			expectedLogEntries: 1,
+		},
+		{
+			name:               "accepts the notification without a request when no host is configured",
+			setupMocks:         func(*mockclient.MockHTTPClientProvider, *mocksecret.MockProvider) {},
+			expectedProvider:   SandboxProvider,
+			expectedLogEntries: 1,
+		},
+		{
+			name:   "fails when the sandbox host fails",
+			config: SandboxConfig{Host: "https://sandbox.example.com"},
+			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider, secrets *mocksecret.MockProvider) {
+				secrets.EXPECT().Resolve(gomock.Any(), "").Return("", nil)
+				httpClient.EXPECT().Post(gomock.Any(), "https://sandbox.example.com", gomock.Any()).Return(errors.New("connection refused"))
+			},
+			expectedError:    true,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockSecrets := mocksecret.NewMockProvider(ctrl)
+			mockNotificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+			tt.setupMocks(mockHTTPClient, mockSecrets)
+
+			mockNotificationLog.EXPECT().RecordNotification(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, entry repository.NotificationLog) error {
+					assert.Equal(t, SandboxProvider, entry.ProviderName)
+					assert.Equal(t, repository.LogStatusSent, entry.Status)
+					assert.Equal(t, "buyer@example.com", entry.Recipient)
+					return nil
+				}).Times(tt.expectedLogEntries)
+
+			// The preferences are never looked up, so the cache expects no
+			// calls
+			channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+				CacheProvider:    mockrepository.NewMockCacheProvider(ctrl),
+				HTTPclient:       mockHTTPClient,
+				MetricsCollector: metricsCollector,
+				Secrets:          mockSecrets,
+				NotificationLog:  mockNotificationLog,
+				Sandbox:          tt.config,
+			})
+
+			result, err := sendToChannel(context.Background(), channel, notification)
+
+			if tt.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAttempts, result.attempts)
+			assert.Equal(t, tt.expectedProvider, result.providerName)
+			assert.Equal(t, tt.expectedHost, result.providerHost)
+		})
+	}
+}
+
+func TestProviderChannel_Preview_Sandbox(t *testing.T) {
+	notification := Notification{To: "buyer@example.com", Title: "Order shipped", Sandbox: true}
+
+	tests := []struct {
+		name             string
+		config           SandboxConfig
+		expectedPayloads []Payload
+	}{
+		{
+			name:   "lists the request to the sandbox host",
+			config: SandboxConfig{Host: "https://sandbox.example.com", SecretKey: "sandbox-secret"},
+			expectedPayloads: []Payload{{
+				ProviderName: SandboxProvider,
+				Host:         "https://sandbox.example.com",
+				AuthMode:     repository.AuthModeSecretKey,
+				Request: client.NotificationRequest{
+					To:        "buyer@example.com",
+					Title:     "Order shipped",
+					SecretKey: RedactedSecretKey,
+				},
+			}},
+		},
+		{
+			name: "lists nothing without a sandbox host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+				CacheProvider: mockrepository.NewMockCacheProvider(ctrl),
+				Sandbox:       tt.config,
+			})
+
+			payloads, err := channel.Preview(context.Background(), notification)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPayloads, payloads)
+		})
+	}
+}
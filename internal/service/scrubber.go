@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ScrubberHookName is the name of ScrubberHook in PRE_SEND_HOOKS
+const ScrubberHookName = "scrubber"
+
+type ScrubberConfig struct {
+	// Pattern matches the PII replaced by Replacement in the title, message
+	// and HTML, e.g. card or national id numbers; alternatives are joined
+	// with '|'. Empty redacts nothing
+	Pattern     string `envconfig:"SCRUBBER_PATTERN"`
+	Replacement string `envconfig:"SCRUBBER_REPLACEMENT" default:"[REDACTED]"`
+	// DenyList rejects notifications whose title, message or HTML contain
+	// any of its terms, in any case
+	DenyList []string `envconfig:"SCRUBBER_DENY_LIST"`
+}
+
+var _ Hook = (*ScrubberHook)(nil)
+
+// ScrubberHook is the pre-send hook shipped by default: it rejects content
+// with a denied term and redacts what the pattern matches
+type ScrubberHook struct {
+	pattern     *regexp.Regexp
+	replacement string
+	denyList    []string
+}
+
+func NewScrubberHook(config ScrubberConfig) (*ScrubberHook, error) {
+	hook := &ScrubberHook{replacement: config.Replacement}
+
+	if config.Pattern != "" {
+		pattern, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("scrubber pattern: %w", err)
+		}
+		hook.pattern = pattern
+	}
+	for _, term := range config.DenyList {
+		if term = strings.TrimSpace(term); term != "" {
+			hook.denyList = append(hook.denyList, strings.ToLower(term))
+		}
+	}
+	return hook, nil
+}
+
+func (h *ScrubberHook) Name() string {
+	return ScrubberHookName
+}
+
+func (h *ScrubberHook) Check(_ context.Context, notification Notification) (Notification, error) {
+	for _, content := range []string{notification.Title, notification.Message, notification.HTML} {
+		content = strings.ToLower(content)
+		for _, term := range h.denyList {
+			// The term itself is kept out of the response, which callers may
+			// show their users
+			if strings.Contains(content, term) {
+				return notification, &ContentRejectedError{Hook: h.Name(), Reason: "content contains a denied term"}
+			}
+		}
+	}
+
+	if h.pattern != nil {
+		notification.Title = h.pattern.ReplaceAllLiteralString(notification.Title, h.replacement)
+		notification.Message = h.pattern.ReplaceAllLiteralString(notification.Message, h.replacement)
+		notification.HTML = h.pattern.ReplaceAllLiteralString(notification.HTML, h.replacement)
+	}
+	return notification, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubberHook_Check(t *testing.T) {
+	notification := Notification{
+		Title:   "Card 4111 1111 1111 1111 charged",
+		Message: "Card 4111-1111-1111-1111 was charged, call 081-234-5678",
+		HTML:    "<p>Card 4111111111111111</p>",
+	}
+
+	tests := []struct {
+		name              string
+		config            ScrubberConfig
+		notification      Notification
+		expected          Notification
+		expectedRejection bool
+	}{
+		{
+			name: "redacts what the pattern matches",
+			config: ScrubberConfig{
+				Pattern:     `\b(?:\d[ -]?){15}\d\b|\b0\d{2}-\d{3}-\d{4}\b`,
+				Replacement: "[REDACTED]",
+			},
+			notification: notification,
+			expected: Notification{
+				Title:   "Card [REDACTED] charged",
+				Message: "Card [REDACTED] was charged, call [REDACTED]",
+				HTML:    "<p>Card [REDACTED]</p>",
+			},
+		},
+		{
+			name:         "lets content through without a pattern",
+			notification: notification,
+			expected:     notification,
+		},
+		{
+			name:              "rejects a denied term in any case",
+			config:            ScrubberConfig{DenyList: []string{"casino", " Free Money "}},
+			notification:      Notification{Title: "Hello", Message: "Claim your FREE MONEY now"},
+			expectedRejection: true,
+		},
+		{
+			name:              "rejects a denied term in the HTML",
+			config:            ScrubberConfig{DenyList: []string{"casino"}},
+			notification:      Notification{Title: "Hello", HTML: "<a>Casino</a>"},
+			expectedRejection: true,
+		},
+		{
+			name:         "ignores empty terms",
+			config:       ScrubberConfig{DenyList: []string{""}},
+			notification: Notification{Title: "Hello"},
+			expected:     Notification{Title: "Hello"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := NewScrubberHook(tt.config)
+			require.NoError(t, err)
+
+			checked, err := hook.Check(context.Background(), tt.notification)
+
+			if tt.expectedRejection {
+				var rejectedErr *ContentRejectedError
+				require.True(t, errors.As(err, &rejectedErr))
+				assert.Equal(t, ScrubberHookName, rejectedErr.Hook)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, checked)
+		})
+	}
+}
+
+func TestNewScrubberHook_InvalidPattern(t *testing.T) {
+	_, err := NewScrubberHook(ScrubberConfig{Pattern: `(`})
+
+	assert.ErrorContains(t, err, "scrubber pattern")
+}
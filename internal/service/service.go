@@ -3,11 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 
-	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	"github.com/koungkub/fw-challenge-notification-service/internal/event"
+	"github.com/koungkub/fw-challenge-notification-service/internal/idgen"
+	"github.com/koungkub/fw-challenge-notification-service/internal/logging"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
 	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
 )
 
 var Module = fx.Module("service",
@@ -16,131 +25,565 @@ var Module = fx.Module("service",
 			NewNotificationService,
 			fx.As(new(NotificationProvider)),
 		),
+		NewContentKeeper,
+		AsChannel(NewEmailChannel),
+		AsChannel(NewPushChannel),
+		AsChannel(NewInAppChannel),
+		NewHookChain,
+		AsHook(NewScrubberHook),
+		AsHook(NewCalloutHook),
 	),
 )
 
 //go:generate mockgen -package mockservice -destination ./mock/mockservice.go . NotificationProvider
 type NotificationProvider interface {
-	SendToSeller(ctx context.Context, to string, title string, message string) error
-	SendToBuyer(ctx context.Context, to string, title string, message string) error
+	// Send delivers the notification to a recipient type registered in the
+	// notification_routes table, e.g. buyer, seller or courier
+	Send(ctx context.Context, recipientType string, notification Notification) (DeliveryReport, error)
+	// SendChannel delivers an accepted notification again on one channel,
+	// keeping its id, e.g. a channel queued for retry by partial success
+	SendChannel(ctx context.Context, id string, recipientType string, channel string, notification Notification) (DeliveryReport, error)
+	// DryRun runs the checks of Send and returns what each channel would
+	// send, without sending, storing or counting anything
+	DryRun(ctx context.Context, recipientType string, notification Notification) (DryRunReport, error)
+	// Replay sends a notification kept by an earlier Send again, as a new
+	// notification
+	Replay(ctx context.Context, id string, options ReplayOptions) (DeliveryReport, error)
 }
 
 var _ NotificationProvider = (*NotificationService)(nil)
 
 type NotificationService struct {
-	cacheProvider      repository.CacheProvider
 	persistentProvider repository.PersistentProvider
-	httpclient         client.HTTPClientProvider
+	metricsCollector   *metrics.NotificationCollector
+	idGenerator        idgen.Generator
+	routeCache         repository.RouteCacheProvider
+	translationCache   repository.TranslationCacheProvider
+	templates          repository.TemplateProvider
+	dispatcher         dispatch.Dispatcher
+	suppressions       repository.SuppressionProvider
+	suppressionCache   repository.SuppressionCacheProvider
+	consents           repository.ConsentProvider
+	consentCache       repository.ConsentCacheProvider
+	messages           repository.MessageProvider
+	messageConfig      MessageConfig
+	digests            repository.DigestProvider
+	digestConfig       DigestConfig
+	quotas             quota.Limiter
+	retries            repository.ChannelRetryProvider
+	partialSuccess     PartialSuccessConfig
+	retryQueue         RetryQueueConfig
+	contents           repository.NotificationContentProvider
+	keeper             *ContentKeeper
+	replay             ReplayConfig
+	hooks              *HookChain
+	channelConfig      ChannelConfig
+	budget             BudgetConfig
+	events             event.Bus
+	logger             *zap.Logger
+	// channels are the registered channels by name
+	channels map[string]Channel
 }
 
 type NotificationServiceParams struct {
 	fx.In
 
-	CacheProvider      repository.CacheProvider
 	PersistentProvider repository.PersistentProvider
-	HTTPclient         client.HTTPClientProvider
+	MetricsCollector   *metrics.NotificationCollector
+	IDGenerator        idgen.Generator
+	RouteCache         repository.RouteCacheProvider
+	TranslationCache   repository.TranslationCacheProvider
+	Templates          repository.TemplateProvider
+	Dispatcher         dispatch.Dispatcher
+	Suppressions       repository.SuppressionProvider
+	SuppressionCache   repository.SuppressionCacheProvider
+	Consents           repository.ConsentProvider
+	ConsentCache       repository.ConsentCacheProvider
+	Messages           repository.MessageProvider
+	MessageConfig      MessageConfig
+	Digests            repository.DigestProvider
+	DigestConfig       DigestConfig
+	Quotas             quota.Limiter
+	Retries            repository.ChannelRetryProvider
+	PartialSuccess     PartialSuccessConfig
+	RetryQueue         RetryQueueConfig
+	Contents           repository.NotificationContentProvider
+	Keeper             *ContentKeeper
+	Replay             ReplayConfig
+	Hooks              *HookChain
+	ChannelConfig      ChannelConfig
+	Budget             BudgetConfig
+	Events             event.Bus
+	Logger             *zap.Logger
+	Channels           []Channel `group:"channels"`
 }
 
 func NewNotificationService(params NotificationServiceParams) *NotificationService {
+	channels := make(map[string]Channel, len(params.Channels))
+	for _, channel := range params.Channels {
+		channels[channel.Name()] = channel
+	}
+
 	return &NotificationService{
-		cacheProvider:      params.CacheProvider,
 		persistentProvider: params.PersistentProvider,
-		httpclient:         params.HTTPclient,
+		metricsCollector:   params.MetricsCollector,
+		idGenerator:        params.IDGenerator,
+		routeCache:         params.RouteCache,
+		translationCache:   params.TranslationCache,
+		templates:          params.Templates,
+		dispatcher:         params.Dispatcher,
+		suppressions:       params.Suppressions,
+		suppressionCache:   params.SuppressionCache,
+		consents:           params.Consents,
+		consentCache:       params.ConsentCache,
+		messages:           params.Messages,
+		messageConfig:      params.MessageConfig,
+		digests:            params.Digests,
+		digestConfig:       params.DigestConfig,
+		quotas:             params.Quotas,
+		retries:            params.Retries,
+		partialSuccess:     params.PartialSuccess,
+		retryQueue:         params.RetryQueue,
+		contents:           params.Contents,
+		keeper:             params.Keeper,
+		replay:             params.Replay,
+		hooks:              params.Hooks,
+		channelConfig:      params.ChannelConfig,
+		budget:             params.Budget,
+		events:             params.Events,
+		logger:             params.Logger,
+		channels:           channels,
 	}
 }
 
-func (s *NotificationService) SendToSeller(ctx context.Context, to string, title string, message string) error {
-	req := client.NotificationRequest{
-		To:      to,
-		Title:   title,
-		Message: message,
+// Send delivers the notification on every channel routed to the recipient
+// type; channels are delivered concurrently and each falls back through its
+// own providers. A notification with a message id is delivered at most once
+// per id. Low priority notifications wait for the digest of their recipient
+// when digests are enabled. Localized content is normalized to NFC and
+// passes through the pre-send hooks, which may redact or reject it. With
+// partial success enabled, a notification delivered on some channels is
+// sent, its failed channels queued for retry. With the retry queue enabled,
+// a notification no channel delivered is accepted, its channels queued for
+// retry. Delivery is bounded by the budget, which each lookup and provider
+// attempt only gets a share of. Each step of an accepted notification is
+// emitted on the event bus, and its content kept for replays when enabled
+func (s *NotificationService) Send(ctx context.Context, recipientType string, notification Notification) (DeliveryReport, error) {
+	ctx, cancel := s.budget.start(ctx)
+	defer cancel()
+	ctx = logging.With(ctx, zap.String(logging.FieldRecipientType, recipientType))
+
+	id, err := s.idGenerator.NewID()
+	if err != nil {
+		return DeliveryReport{}.finish(err), err
+	}
+
+	if notification.MessageID != "" {
+		return s.sendOnce(ctx, id, recipientType, notification)
 	}
-	g, ctx := errgroup.WithContext(ctx)
+	return s.send(ctx, id, recipientType, notification)
+}
 
-	g.Go(func() error {
-		preferences, err := s.getNotificationPreferences(ctx, repository.EmailProvider)
-		if err != nil {
-			return err
+func (s *NotificationService) send(
+	ctx context.Context,
+	id string,
+	recipientType string,
+	notification Notification,
+) (DeliveryReport, error) {
+	report := DeliveryReport{ID: id}
+
+	channels, err := s.getRoutedChannels(ctx, recipientType)
+	if err != nil {
+		var recipientErr *RecipientTypeError
+		if errors.As(err, &recipientErr) {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
+		}
+		return report.finish(err), err
+	}
+
+	channels, err = selectChannels(recipientType, channels, notification.Channels)
+	if err != nil {
+		report.RetryDisposition = RetryDoNotRetry
+		return report, err
+	}
+
+	notification, err = s.localize(ctx, notification)
+	if err != nil {
+		var translationErr *TranslationError
+		var templateErr *TemplateError
+		if errors.As(err, &translationErr) || errors.As(err, &templateErr) {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
 		}
+		return report.finish(err), err
+	}
+	notification = notification.normalized()
 
-		if err := s.sendNotification(ctx, preferences, req); err != nil {
-			return err
+	notification, err = s.hooks.Run(ctx, recipientType, notification)
+	if err != nil {
+		var rejectedErr *ContentRejectedError
+		if errors.As(err, &rejectedErr) {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
 		}
-		return nil
-	})
+		return report.finish(err), err
+	}
+
+	channels, err = s.skipSuppressed(ctx, id, recipientType, notification.To, channels)
+	if err != nil {
+		var suppressionErr *SuppressionError
+		if errors.As(err, &suppressionErr) {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
+		}
+		return report.finish(err), err
+	}
+
+	channels, err = s.skipOptedOut(ctx, recipientType, notification.Category, notification.To, channels)
+	if err != nil {
+		var optOutErr *OptOutError
+		if errors.As(err, &optOutErr) {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
+		}
+		return report.finish(err), err
+	}
 
-	g.Go(func() error {
-		preferences, err := s.getNotificationPreferences(ctx, repository.PushNotificationProvider)
+	for _, channel := range channels {
+		if err := channel.Validate(notification.To); err != nil {
+			report.RetryDisposition = RetryDoNotRetry
+			return report, err
+		}
+	}
+	if err := validateCapabilities(notification.providerRequest(), channels...); err != nil {
+		report.RetryDisposition = RetryDoNotRetry
+		return report, err
+	}
+
+	// A delivery waits for a dispatcher slot before it is counted, so one
+	// that never gets a slot keeps its quota; a digest needs no slot
+	digested := s.digestConfig.Digested(recipientType, notification)
+	release := func() {}
+	if !digested {
+		release, err = s.dispatcher.Acquire(ctx, notification.Priority)
 		if err != nil {
-			return err
+			return report.finish(err), err
 		}
+	}
+	defer release()
 
-		if err := s.sendNotification(ctx, preferences, req); err != nil {
-			return err
+	if err := s.quotas.Consume(ctx); err != nil {
+		var exceededErr *quota.ExceededError
+		if errors.As(err, &exceededErr) {
+			report.RetryDisposition = RetrySafe
+			return report, err
 		}
-		return nil
+		return report.finish(err), err
+	}
+
+	s.emit(ctx, event.NotificationEvent{
+		Type:           event.TypeNotificationAccepted,
+		NotificationID: id,
+		RecipientType:  recipientType,
+		Channels:       channelNames(channels),
 	})
+	s.keepContent(ctx, id, recipientType, notification)
+
+	if digested {
+		return s.digest(ctx, id, recipientType, notification)
+	}
+
+	notification.ID = id
+	notification.RecipientType = recipientType
+
+	results := make([]channelResult, len(channels))
+	g := &errgroup.Group{}
+	if !s.partialSuccess.Enabled && !s.retryQueue.Enabled {
+		// Without partial success a failed channel fails the notification,
+		// so the others are cancelled; the retry queue needs every channel
+		// to finish, as a cancelled attempt may have reached its provider
+		g, ctx = errgroup.WithContext(ctx)
+	}
+
+	for i, channel := range channels {
+		g.Go(func() error {
+			var err error
+			results[i], err = sendToChannel(ctx, channel, notification)
+			return s.skipUnconfigured(ctx, recipientType, &results[i], err)
+		})
+	}
+
+	err = g.Wait()
+	if err == nil && everySkipped(results) {
+		err = &NoProviderError{Channel: results[0].channel}
+	}
+	if err != nil && s.partialSuccess.Enabled && partiallyDelivered(results) {
+		results = s.queueFailedChannels(ctx, recipientType, notification, results)
+		report.Partial = true
+		err = nil
+	}
+	if err != nil && s.retryQueue.Enabled {
+		var queued bool
+		if results, queued = s.queueUndelivered(ctx, recipientType, notification, results); queued {
+			report.Retrying = true
+			err = nil
+		}
+	}
 
-	if err := g.Wait(); err != nil {
+	report = report.finish(err, results...)
+	s.emitOutcome(ctx, recipientType, report, err)
+	return report, err
+}
+
+// skipUnconfigured skips an optional channel none of whose providers is
+// configured, so the other channels still decide the outcome
+func (s *NotificationService) skipUnconfigured(
+	ctx context.Context,
+	recipientType string,
+	result *channelResult,
+	err error,
+) error {
+	var noProviderErr *NoProviderError
+	if !errors.As(err, &noProviderErr) || !s.channelConfig.optional(result.channel) {
 		return err
 	}
 
+	logging.From(ctx, s.logger).Warn("skipped optional channel without providers",
+		zap.String("channel", result.channel),
+	)
+	s.metricsCollector.RecordUnconfigured(ctx, recipientType, result.channel)
+	result.skipped = true
 	return nil
 }
 
-func (s *NotificationService) SendToBuyer(ctx context.Context, to string, title string, message string) error {
-	req := client.NotificationRequest{
-		To:      to,
-		Title:   title,
-		Message: message,
+// everySkipped tells whether no channel was left to deliver the
+// notification
+func everySkipped(results []channelResult) bool {
+	for _, result := range results {
+		if !result.skipped {
+			return false
+		}
 	}
+	return len(results) > 0
+}
 
-	preferences, err := s.getNotificationPreferences(ctx, repository.EmailProvider)
-	if err != nil {
-		return err
+// skipSuppressed drops the email channel when the address is suppressed;
+// the other channels are still delivered
+func (s *NotificationService) skipSuppressed(
+	ctx context.Context,
+	id string,
+	recipientType string,
+	address string,
+	channels []Channel,
+) ([]Channel, error) {
+	remaining, suppressed, err := s.withoutSuppressed(ctx, address, channels)
+	if !suppressed {
+		return remaining, err
 	}
 
-	if err := s.sendNotification(ctx, preferences, req); err != nil {
-		return err
+	s.metricsCollector.RecordSuppressed(ctx, recipientType, repository.EmailProvider.String())
+	s.emit(ctx, event.NotificationEvent{
+		Type:           event.TypeNotificationSuppressed,
+		NotificationID: id,
+		RecipientType:  recipientType,
+		Channels:       []string{repository.EmailProvider.String()},
+	})
+	return remaining, err
+}
+
+// withoutSuppressed is skipSuppressed without recording the suppression,
+// reporting whether the email channel was dropped
+func (s *NotificationService) withoutSuppressed(ctx context.Context, address string, channels []Channel) ([]Channel, bool, error) {
+	isEmail := func(channel Channel) bool {
+		return channel.Name() == repository.EmailProvider.String()
+	}
+	if !slices.ContainsFunc(channels, isEmail) {
+		return channels, false, nil
 	}
 
-	return nil
+	suppressed, err := s.isSuppressed(ctx, address)
+	if err != nil || !suppressed {
+		return channels, false, err
+	}
+
+	remaining := slices.DeleteFunc(slices.Clone(channels), isEmail)
+	if len(remaining) == 0 {
+		return nil, true, &SuppressionError{Address: address}
+	}
+	return remaining, true, nil
+}
+
+func (s *NotificationService) isSuppressed(ctx context.Context, address string) (bool, error) {
+	suppressed, err := s.suppressionCache.Get(address)
+	if err == nil {
+		return suppressed, nil
+	}
+
+	lookupCtx, cancel := s.budget.lookup(ctx)
+	defer cancel()
+
+	suppressed, err = s.suppressions.IsSuppressed(lookupCtx, address)
+	if err != nil {
+		return false, err
+	}
+
+	s.suppressionCache.Set(address, suppressed)
+	return suppressed, nil
+}
+
+// Categories lists the notification categories a recipient consents to
+var Categories = []string{repository.CategoryTransactional, repository.CategoryMarketing, repository.CategoryReminder}
+
+// DefaultOptedIn tells whether a recipient without a consent receives the
+// category: marketing needs an opt-in, the others an opt-out
+func DefaultOptedIn(category string) bool {
+	return category != repository.CategoryMarketing
 }
 
-func (s *NotificationService) getNotificationPreferences(
+// skipOptedOut drops the channels the recipient has not opted in to for the
+// category. A consent for the channel wins over one for every channel,
+// which wins over DefaultOptedIn
+func (s *NotificationService) skipOptedOut(
 	ctx context.Context,
-	providerType repository.NotificationProvider,
-) ([]repository.NotificationPreference, error) {
+	recipientType string,
+	category string,
+	address string,
+	channels []Channel,
+) ([]Channel, error) {
+	remaining, optedOut, err := s.withoutOptedOut(ctx, category, address, channels)
+	for _, channel := range optedOut {
+		s.metricsCollector.RecordOptedOut(ctx, recipientType, channel, consentCategory(category))
+	}
+	return remaining, err
+}
+
+// withoutOptedOut is skipOptedOut without recording the channels dropped,
+// which it returns instead
+func (s *NotificationService) withoutOptedOut(
+	ctx context.Context,
+	category string,
+	address string,
+	channels []Channel,
+) ([]Channel, []string, error) {
+	category = consentCategory(category)
+
+	consents, err := s.getConsents(ctx, address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	optedIn := func(channel string) bool {
+		everyChannel := DefaultOptedIn(category)
+		for _, consent := range consents {
+			if consent.Category != category {
+				continue
+			}
+			if consent.Channel == channel {
+				return consent.OptedIn
+			}
+			if consent.Channel == "" {
+				everyChannel = consent.OptedIn
+			}
+		}
+		return everyChannel
+	}
+
 	var (
-		preferences []repository.NotificationPreference
-		err         error
+		remaining = make([]Channel, 0, len(channels))
+		optedOut  []string
 	)
+	for _, channel := range channels {
+		if !optedIn(channel.Name()) {
+			optedOut = append(optedOut, channel.Name())
+			continue
+		}
+		remaining = append(remaining, channel)
+	}
+	if len(remaining) == 0 {
+		return nil, optedOut, &OptOutError{Address: address, Category: category}
+	}
+	return remaining, optedOut, nil
+}
 
-	preferences, err = s.cacheProvider.Get(providerType)
+func (s *NotificationService) getConsents(ctx context.Context, address string) ([]repository.Consent, error) {
+	consents, err := s.consentCache.Get(address)
 	if err == nil {
-		return preferences, nil
+		return consents, nil
 	}
 
-	preferences, err = s.persistentProvider.FindByProviderType(ctx, providerType)
+	lookupCtx, cancel := s.budget.lookup(ctx)
+	defer cancel()
+
+	consents, err = s.consents.ListConsents(lookupCtx, address)
 	if err != nil {
-		return []repository.NotificationPreference{}, err
+		return nil, err
 	}
 
-	s.cacheProvider.Set(providerType, preferences)
-	return preferences, nil
+	s.consentCache.Set(address, consents)
+	return consents, nil
 }
 
-func (s *NotificationService) sendNotification(
-	ctx context.Context,
-	preferences []repository.NotificationPreference,
-	req client.NotificationRequest,
-) error {
-	for _, preference := range preferences {
-		req.SecretKey = preference.SecretKey
-		if err := s.httpclient.Post(ctx, preference.Host, req); err != nil {
-			continue
+// consentCategory is the category consents are looked up by, transactional
+// when the notification has none
+func consentCategory(category string) string {
+	if category == "" {
+		return repository.CategoryTransactional
+	}
+	return category
+}
+
+// selectChannels keeps the routed channels named in selected, in routing
+// order; an empty selection keeps them all
+func selectChannels(recipientType string, channels []Channel, selected []string) ([]Channel, error) {
+	if len(selected) == 0 {
+		return channels, nil
+	}
+
+	for _, name := range selected {
+		routed := func(channel Channel) bool {
+			return channel.Name() == name
 		}
-		return nil
+		if !slices.ContainsFunc(channels, routed) {
+			return nil, &ChannelSelectionError{RecipientType: recipientType, Channel: name}
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(channels), func(channel Channel) bool {
+		return !slices.Contains(selected, channel.Name())
+	}), nil
+}
+
+// getRoutedChannels returns the channels routed to the recipient type in
+// priority order
+func (s *NotificationService) getRoutedChannels(ctx context.Context, recipientType string) ([]Channel, error) {
+	routes, err := s.routeCache.Get(recipientType)
+	if err != nil {
+		lookupCtx, cancel := s.budget.lookup(ctx)
+		defer cancel()
+
+		routes, err = s.persistentProvider.FindRoutesByRecipientType(lookupCtx, recipientType)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// An unknown recipient type is remembered briefly, so requests
+			// naming it do not query the database each time
+			routes, err = []repository.NotificationRoute{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.routeCache.Set(recipientType, routes)
 	}
-	return errors.New("failure to sent the notifications")
+	if len(routes) == 0 {
+		return nil, &RecipientTypeError{RecipientType: recipientType}
+	}
+
+	channels := make([]Channel, 0, len(routes))
+	for _, route := range routes {
+		channel, ok := s.channels[route.ProviderType]
+		if !ok {
+			return nil, fmt.Errorf("provider type: no channel registered for '%s'", route.ProviderType)
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
 }
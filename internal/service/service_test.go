@@ -3,18 +3,144 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	mockalert "github.com/koungkub/fw-challenge-notification-service/internal/alert/mock"
 	"github.com/koungkub/fw-challenge-notification-service/internal/client"
 	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/dispatch"
+	mockdispatch "github.com/koungkub/fw-challenge-notification-service/internal/dispatch/mock"
+	mockhealth "github.com/koungkub/fw-challenge-notification-service/internal/health/mock"
+	mockidgen "github.com/koungkub/fw-challenge-notification-service/internal/idgen/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/quota"
+	mockquota "github.com/koungkub/fw-challenge-notification-service/internal/quota/mock"
 	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
 	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	mocksecret "github.com/koungkub/fw-challenge-notification-service/internal/secret/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 )
 
+const testNotificationID = "01JB8Z5XK3M4N5P6Q7R8S9T0VW"
+
+const (
+	recipientTypeBuyer  = "buyer"
+	recipientTypeSeller = "seller"
+)
+
+func newTestIDGenerator(ctrl *gomock.Controller) *mockidgen.MockGenerator {
+	idGenerator := mockidgen.NewMockGenerator(ctrl)
+	idGenerator.EXPECT().NewID().Return(testNotificationID, nil).AnyTimes()
+	return idGenerator
+}
+
+func newTestDispatcher(t *testing.T) *dispatch.PriorityDispatcher {
+	metricsCollector, _ := metrics.NewDispatchCollector(nil)
+	dispatcher, err := dispatch.NewPriorityDispatcher(dispatch.PriorityDispatcherParams{
+		Config:           dispatch.DispatchConfig{MaxConcurrent: 10},
+		Clock:            clock.NewRealClock(),
+		MetricsCollector: metricsCollector,
+	})
+	require.NoError(t, err)
+	return dispatcher
+}
+
+// newTestSecrets resolves every secret key to itself
+func newTestSecrets(ctrl *gomock.Controller) *mocksecret.MockProvider {
+	secrets := mocksecret.NewMockProvider(ctrl)
+	secrets.EXPECT().Resolve(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, value string) (string, error) {
+			return value, nil
+		}).AnyTimes()
+	return secrets
+}
+
+// newTestHealth reports every provider healthy
+func newTestHealth(ctrl *gomock.Controller) *mockhealth.MockChecker {
+	checker := mockhealth.NewMockChecker(ctrl)
+	checker.EXPECT().Healthy(gomock.Any()).Return(true).AnyTimes()
+	return checker
+}
+
+// newTestNotificationLog accepts every notification log write
+func newTestNotificationLog(ctrl *gomock.Controller) *mockrepository.MockNotificationLogProvider {
+	notificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+	notificationLog.EXPECT().RecordNotification(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return notificationLog
+}
+
+// newTestAlerter accepts every channel outage
+func newTestAlerter(ctrl *gomock.Controller) *mockalert.MockAlerter {
+	alerter := mockalert.NewMockAlerter(ctrl)
+	alerter.EXPECT().ChannelDown(gomock.Any(), gomock.Any()).AnyTimes()
+	return alerter
+}
+
+// newTestSuppressions suppresses no address
+func newTestSuppressions(ctrl *gomock.Controller) *mockrepository.MockSuppressionProvider {
+	suppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+	suppressions.EXPECT().IsSuppressed(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+	return suppressions
+}
+
+// newTestConsents stores no consent, so every category but marketing is
+// opted in
+func newTestConsents(ctrl *gomock.Controller) *mockrepository.MockConsentProvider {
+	consents := mockrepository.NewMockConsentProvider(ctrl)
+	consents.EXPECT().ListConsents(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	return consents
+}
+
+// newTestSuppressionCache caches nothing, so every lookup reaches the
+// suppressions
+func newTestSuppressionCache(ctrl *gomock.Controller) *mockrepository.MockSuppressionCacheProvider {
+	cache := mockrepository.NewMockSuppressionCacheProvider(ctrl)
+	cache.EXPECT().Get(gomock.Any()).Return(false, errors.New("cache miss")).AnyTimes()
+	cache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return cache
+}
+
+// newTestConsentCache caches nothing, so every lookup reaches the consents
+func newTestConsentCache(ctrl *gomock.Controller) *mockrepository.MockConsentCacheProvider {
+	cache := mockrepository.NewMockConsentCacheProvider(ctrl)
+	cache.EXPECT().Get(gomock.Any()).Return(nil, errors.New("cache miss")).AnyTimes()
+	cache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return cache
+}
+
+// newTestQuotas limits no notification
+func newTestQuotas(ctrl *gomock.Controller) *mockquota.MockLimiter {
+	quotas := mockquota.NewMockLimiter(ctrl)
+	quotas.EXPECT().Consume(gomock.Any()).Return(nil).AnyTimes()
+	return quotas
+}
+
+// newTestChannels registers the email and push channels
+func newTestChannels(params ProviderChannelParams) []Channel {
+	return []Channel{NewEmailChannel(params), NewPushChannel(params)}
+}
+
+// newTestRouteCache serves the default routing: buyers by email, sellers by
+// email and push
+func newTestRouteCache(ctrl *gomock.Controller) *mockrepository.MockRouteCacheProvider {
+	routeCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+	routeCache.EXPECT().Get(recipientTypeBuyer).Return([]repository.NotificationRoute{
+		{RecipientType: recipientTypeBuyer, ProviderType: "Email"},
+	}, nil).AnyTimes()
+	routeCache.EXPECT().Get(recipientTypeSeller).Return([]repository.NotificationRoute{
+		{RecipientType: recipientTypeSeller, ProviderType: "Email"},
+		{RecipientType: recipientTypeSeller, ProviderType: "PushNotification", Priority: 1},
+	}, nil).AnyTimes()
+	return routeCache
+}
+
 func TestNewNotificationService(t *testing.T) {
 	t.Run("creates service with all dependencies", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -23,21 +149,46 @@ func TestNewNotificationService(t *testing.T) {
 		mockCache := mockrepository.NewMockCacheProvider(ctrl)
 		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+		idGenerator := newTestIDGenerator(ctrl)
 
 		service := NewNotificationService(NotificationServiceParams{
-			CacheProvider:      mockCache,
 			PersistentProvider: mockPersistent,
-			HTTPclient:         mockHTTPClient,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        idGenerator,
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
 		})
 
 		assert.NotNil(t, service)
-		assert.Equal(t, mockCache, service.cacheProvider)
 		assert.Equal(t, mockPersistent, service.persistentProvider)
-		assert.Equal(t, mockHTTPClient, service.httpclient)
+		assert.Equal(t, metricsCollector, service.metricsCollector)
+		assert.Equal(t, idGenerator, service.idGenerator)
+		assert.Len(t, service.channels, 2)
+
+		email, ok := service.channels["Email"].(*ProviderChannel)
+		require.True(t, ok)
+		assert.Equal(t, mockCache, email.cacheProvider)
+		assert.Equal(t, mockHTTPClient, email.httpclient)
 	})
 }
 
-func TestNotificationService_SendToBuyer(t *testing.T) {
+func TestNotificationService_Send_Buyer(t *testing.T) {
 	tests := []struct {
 		name           string
 		to             string
@@ -58,6 +209,7 @@ func TestNotificationService_SendToBuyer(t *testing.T) {
 				}
 				cache.EXPECT().Get(repository.EmailProvider).Return(preferences, nil)
 				httpClient.EXPECT().Post(gomock.Any(), "https://email-service.com", client.NotificationRequest{
+					ID:        testNotificationID,
 					To:        "buyer@example.com",
 					Title:     "Order Confirmation",
 					Message:   "Your order has been confirmed",
@@ -79,6 +231,7 @@ func TestNotificationService_SendToBuyer(t *testing.T) {
 				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(preferences, nil)
 				cache.EXPECT().Set(repository.EmailProvider, preferences).Return(nil)
 				httpClient.EXPECT().Post(gomock.Any(), "https://email-service.com", client.NotificationRequest{
+					ID:        testNotificationID,
 					To:        "buyer@example.com",
 					Title:     "Order Confirmation",
 					Message:   "Your order has been confirmed",
@@ -124,16 +277,35 @@ func TestNotificationService_SendToBuyer(t *testing.T) {
 			mockCache := mockrepository.NewMockCacheProvider(ctrl)
 			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+			idGenerator := newTestIDGenerator(ctrl)
 
 			tt.setupMocks(mockCache, mockPersistent, mockHTTPClient)
 
 			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
 				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        idGenerator,
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockCache,
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockHTTPClient,
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
 			})
 
-			err := service.SendToBuyer(context.Background(), tt.to, tt.title, tt.message)
+			_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: tt.to, Title: tt.title, Message: tt.message})
 
 			if tt.expectedError {
 				require.Error(t, err)
@@ -145,7 +317,7 @@ func TestNotificationService_SendToBuyer(t *testing.T) {
 	}
 }
 
-func TestNotificationService_SendToSeller(t *testing.T) {
+func TestNotificationService_Send_Seller(t *testing.T) {
 	tests := []struct {
 		name           string
 		to             string
@@ -257,263 +429,35 @@ func TestNotificationService_SendToSeller(t *testing.T) {
 			mockCache := mockrepository.NewMockCacheProvider(ctrl)
 			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+			idGenerator := newTestIDGenerator(ctrl)
 
 			tt.setupMocks(mockCache, mockPersistent, mockHTTPClient)
 
 			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
-				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
-			})
-
-			err := service.SendToSeller(context.Background(), tt.to, tt.title, tt.message)
-
-			if tt.expectedError {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErrMsg)
-			} else {
-				require.NoError(t, err)
-			}
-		})
-	}
-}
-
-func TestNotificationService_getNotificationPreferences(t *testing.T) {
-	tests := []struct {
-		name           string
-		providerType   repository.NotificationProvider
-		setupMocks     func(*mockrepository.MockCacheProvider, *mockrepository.MockPersistentProvider)
-		expectedPrefs  []repository.NotificationPreference
-		expectedError  bool
-		expectedErrMsg string
-		verifyCacheSet bool
-	}{
-		{
-			name:         "returns preferences from cache",
-			providerType: repository.EmailProvider,
-			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
-				preferences := []repository.NotificationPreference{
-					{Host: "https://email-service.com", SecretKey: "secret1"},
-				}
-				cache.EXPECT().Get(repository.EmailProvider).Return(preferences, nil)
-			},
-			expectedPrefs: []repository.NotificationPreference{
-				{Host: "https://email-service.com", SecretKey: "secret1"},
-			},
-			expectedError:  false,
-			verifyCacheSet: false,
-		},
-		{
-			name:         "fetches from database on cache miss and sets cache",
-			providerType: repository.PushNotificationProvider,
-			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
-				preferences := []repository.NotificationPreference{
-					{Host: "https://push-service.com", SecretKey: "push-secret"},
-				}
-				cache.EXPECT().Get(repository.PushNotificationProvider).Return(nil, errors.New("cache miss"))
-				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.PushNotificationProvider).Return(preferences, nil)
-				cache.EXPECT().Set(repository.PushNotificationProvider, preferences).Return(nil)
-			},
-			expectedPrefs: []repository.NotificationPreference{
-				{Host: "https://push-service.com", SecretKey: "push-secret"},
-			},
-			expectedError:  false,
-			verifyCacheSet: true,
-		},
-		{
-			name:         "returns error when database fetch fails",
-			providerType: repository.EmailProvider,
-			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
-				cache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
-				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(nil, errors.New("database connection error"))
-			},
-			expectedPrefs:  []repository.NotificationPreference{},
-			expectedError:  true,
-			expectedErrMsg: "database connection error",
-			verifyCacheSet: false,
-		},
-		{
-			name:         "returns empty preferences from database and sets cache",
-			providerType: repository.EmailProvider,
-			setupMocks: func(cache *mockrepository.MockCacheProvider, persistent *mockrepository.MockPersistentProvider) {
-				preferences := []repository.NotificationPreference{}
-				cache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
-				persistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(preferences, nil)
-				cache.EXPECT().Set(repository.EmailProvider, preferences).Return(nil)
-			},
-			expectedPrefs:  []repository.NotificationPreference{},
-			expectedError:  false,
-			verifyCacheSet: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockCache := mockrepository.NewMockCacheProvider(ctrl)
-			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
-			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
-
-			tt.setupMocks(mockCache, mockPersistent)
-
-			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
-				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
-			})
-
-			prefs, err := service.getNotificationPreferences(context.Background(), tt.providerType)
-
-			if tt.expectedError {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErrMsg)
-			} else {
-				require.NoError(t, err)
-				assert.Equal(t, tt.expectedPrefs, prefs)
-			}
-		})
-	}
-}
-
-func TestNotificationService_sendNotification(t *testing.T) {
-	tests := []struct {
-		name           string
-		preferences    []repository.NotificationPreference
-		request        client.NotificationRequest
-		setupMocks     func(*mockclient.MockHTTPClientProvider)
-		expectedError  bool
-		expectedErrMsg string
-	}{
-		{
-			name: "returns nil on first success",
-			preferences: []repository.NotificationPreference{
-				{Host: "https://service1.com", SecretKey: "secret1"},
-				{Host: "https://service2.com", SecretKey: "secret2"},
-			},
-			request: client.NotificationRequest{
-				To:      "user@example.com",
-				Title:   "Test",
-				Message: "Test message",
-			},
-			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
-				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
-					To:        "user@example.com",
-					Title:     "Test",
-					Message:   "Test message",
-					SecretKey: "secret1",
-				}).Return(nil)
-			},
-			expectedError: false,
-		},
-		{
-			name: "tries next preference on error and succeeds",
-			preferences: []repository.NotificationPreference{
-				{Host: "https://service1.com", SecretKey: "secret1"},
-				{Host: "https://service2.com", SecretKey: "secret2"},
-			},
-			request: client.NotificationRequest{
-				To:      "user@example.com",
-				Title:   "Test",
-				Message: "Test message",
-			},
-			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
-				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
-					To:        "user@example.com",
-					Title:     "Test",
-					Message:   "Test message",
-					SecretKey: "secret1",
-				}).Return(errors.New("connection failed"))
-				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", client.NotificationRequest{
-					To:        "user@example.com",
-					Title:     "Test",
-					Message:   "Test message",
-					SecretKey: "secret2",
-				}).Return(nil)
-			},
-			expectedError: false,
-		},
-		{
-			name: "returns error when all HTTP requests fail",
-			preferences: []repository.NotificationPreference{
-				{Host: "https://service1.com", SecretKey: "secret1"},
-				{Host: "https://service2.com", SecretKey: "secret2"},
-			},
-			request: client.NotificationRequest{
-				To:      "user@example.com",
-				Title:   "Test",
-				Message: "Test message",
-			},
-			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
-				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", client.NotificationRequest{
-					To:        "user@example.com",
-					Title:     "Test",
-					Message:   "Test message",
-					SecretKey: "secret1",
-				}).Return(errors.New("connection failed"))
-				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", client.NotificationRequest{
-					To:        "user@example.com",
-					Title:     "Test",
-					Message:   "Test message",
-					SecretKey: "secret2",
-				}).Return(errors.New("connection failed"))
-			},
-			expectedError:  true,
-			expectedErrMsg: "failure to sent the notifications",
-		},
-		{
-			name:        "returns error for empty preferences",
-			preferences: []repository.NotificationPreference{},
-			request: client.NotificationRequest{
-				To:      "user@example.com",
-				Title:   "Test",
-				Message: "Test message",
-			},
-			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
-				// No HTTP calls expected
-			},
-			expectedError:  true,
-			expectedErrMsg: "failure to sent the notifications",
-		},
-		{
-			name: "tries multiple preferences until success",
-			preferences: []repository.NotificationPreference{
-				{Host: "https://service1.com", SecretKey: "secret1"},
-				{Host: "https://service2.com", SecretKey: "secret2"},
-				{Host: "https://service3.com", SecretKey: "secret3"},
-			},
-			request: client.NotificationRequest{
-				To:      "user@example.com",
-				Title:   "Test",
-				Message: "Test message",
-			},
-			setupMocks: func(httpClient *mockclient.MockHTTPClientProvider) {
-				httpClient.EXPECT().Post(gomock.Any(), "https://service1.com", gomock.Any()).Return(errors.New("network error"))
-				httpClient.EXPECT().Post(gomock.Any(), "https://service2.com", gomock.Any()).Return(nil)
-			},
-			expectedError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockCache := mockrepository.NewMockCacheProvider(ctrl)
-			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
-			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
-
-			tt.setupMocks(mockHTTPClient)
-
-			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
 				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        idGenerator,
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockCache,
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockHTTPClient,
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
 			})
 
-			err := service.sendNotification(context.Background(), tt.preferences, tt.request)
+			_, err := service.Send(context.Background(), recipientTypeSeller, Notification{To: tt.to, Title: tt.title, Message: tt.message})
 
 			if tt.expectedError {
 				require.Error(t, err)
@@ -525,7 +469,7 @@ func TestNotificationService_sendNotification(t *testing.T) {
 	}
 }
 
-func TestNotificationService_SendToBuyer_ContextCancellation(t *testing.T) {
+func TestNotificationService_Send_Buyer_ContextCancellation(t *testing.T) {
 	tests := []struct {
 		name          string
 		setupMocks    func(*mockrepository.MockCacheProvider, *mockrepository.MockPersistentProvider, *mockclient.MockHTTPClientProvider)
@@ -575,13 +519,32 @@ func TestNotificationService_SendToBuyer_ContextCancellation(t *testing.T) {
 			mockCache := mockrepository.NewMockCacheProvider(ctrl)
 			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+			idGenerator := newTestIDGenerator(ctrl)
 
 			tt.setupMocks(mockCache, mockPersistent, mockHTTPClient)
 
 			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
 				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        idGenerator,
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockCache,
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockHTTPClient,
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
 			})
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -591,7 +554,7 @@ func TestNotificationService_SendToBuyer_ContextCancellation(t *testing.T) {
 				defer cancel()
 			}
 
-			err := service.SendToBuyer(ctx, "buyer@example.com", "Test", "Test message")
+			_, err := service.Send(ctx, recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
 
 			if tt.expectedError {
 				require.Error(t, err)
@@ -602,7 +565,7 @@ func TestNotificationService_SendToBuyer_ContextCancellation(t *testing.T) {
 	}
 }
 
-func TestNotificationService_SendToSeller_ContextCancellation(t *testing.T) {
+func TestNotificationService_Send_Seller_ContextCancellation(t *testing.T) {
 	tests := []struct {
 		name          string
 		setupMocks    func(*mockrepository.MockCacheProvider, *mockrepository.MockPersistentProvider, *mockclient.MockHTTPClientProvider)
@@ -660,13 +623,32 @@ func TestNotificationService_SendToSeller_ContextCancellation(t *testing.T) {
 			mockCache := mockrepository.NewMockCacheProvider(ctrl)
 			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+			idGenerator := newTestIDGenerator(ctrl)
 
 			tt.setupMocks(mockCache, mockPersistent, mockHTTPClient)
 
 			service := NewNotificationService(NotificationServiceParams{
-				CacheProvider:      mockCache,
 				PersistentProvider: mockPersistent,
-				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        idGenerator,
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockCache,
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockHTTPClient,
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
 			})
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -677,7 +659,7 @@ func TestNotificationService_SendToSeller_ContextCancellation(t *testing.T) {
 				defer cancel()
 			}
 
-			err := service.SendToSeller(ctx, "seller@example.com", "Test", "Test message")
+			_, err := service.Send(ctx, recipientTypeSeller, Notification{To: "seller@example.com", Title: "Test", Message: "Test message"})
 
 			if tt.expectedError {
 				require.Error(t, err)
@@ -688,65 +670,1131 @@ func TestNotificationService_SendToSeller_ContextCancellation(t *testing.T) {
 	}
 }
 
-func TestNotificationService_getNotificationPreferences_ContextCancellation(t *testing.T) {
-	t.Run("handles context cancellation during database fetch", func(t *testing.T) {
+func TestNotificationService_CacheSetError(t *testing.T) {
+	t.Run("continues even if cache.Set fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockCache := mockrepository.NewMockCacheProvider(ctrl)
 		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+		idGenerator := newTestIDGenerator(ctrl)
+
+		preferences := []repository.NotificationPreference{
+			{Host: "https://email-service.com", SecretKey: "secret1"},
+		}
 
 		mockCache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
-		mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).DoAndReturn(func(ctx context.Context, provider repository.NotificationProvider) ([]repository.NotificationPreference, error) {
-			if ctx.Err() != nil {
-				return nil, ctx.Err()
-			}
-			return nil, errors.New("context should be cancelled")
-		})
+		mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(preferences, nil)
+		mockCache.EXPECT().Set(repository.EmailProvider, preferences).Return(errors.New("redis connection error"))
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email-service.com", gomock.Any()).Return(nil)
 
 		service := NewNotificationService(NotificationServiceParams{
-			CacheProvider:      mockCache,
 			PersistentProvider: mockPersistent,
-			HTTPclient:         mockHTTPClient,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        idGenerator,
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
 		})
 
-		ctx, cancel := context.WithCancel(context.Background())
-		cancel()
+		_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
 
-		_, err := service.getNotificationPreferences(ctx, repository.EmailProvider)
-
-		require.Error(t, err)
-		assert.Equal(t, context.Canceled, err)
+		require.NoError(t, err)
 	})
 }
 
-func TestNotificationService_CacheSetError(t *testing.T) {
-	t.Run("continues even if cache.Set fails", func(t *testing.T) {
+func TestNotificationService_IDGenerationError(t *testing.T) {
+	t.Run("returns error when notification id cannot be generated", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockCache := mockrepository.NewMockCacheProvider(ctrl)
 		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
 		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+		idGenerator := mockidgen.NewMockGenerator(ctrl)
 
-		preferences := []repository.NotificationPreference{
-			{Host: "https://email-service.com", SecretKey: "secret1"},
-		}
+		idGenerator.EXPECT().NewID().Return("", errors.New("entropy source unavailable")).Times(2)
 
-		mockCache.EXPECT().Get(repository.EmailProvider).Return(nil, errors.New("cache miss"))
-		mockPersistent.EXPECT().FindByProviderType(gomock.Any(), repository.EmailProvider).Return(preferences, nil)
-		mockCache.EXPECT().Set(repository.EmailProvider, preferences).Return(errors.New("redis connection error"))
-		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email-service.com", gomock.Any()).Return(nil)
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockPersistent,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        idGenerator,
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "entropy source unavailable")
+
+		_, err = service.Send(context.Background(), recipientTypeSeller, Notification{To: "seller@example.com", Title: "Test", Message: "Test message"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "entropy source unavailable")
+	})
+}
+
+func TestNotificationService_CapabilityValidation(t *testing.T) {
+	t.Run("rejects seller message exceeding push limits before sending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+		idGenerator := newTestIDGenerator(ctrl)
 
 		service := NewNotificationService(NotificationServiceParams{
-			CacheProvider:      mockCache,
 			PersistentProvider: mockPersistent,
-			HTTPclient:         mockHTTPClient,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        idGenerator,
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
 		})
 
-		err := service.SendToBuyer(context.Background(), "buyer@example.com", "Test", "Test message")
+		_, err := service.Send(context.Background(), recipientTypeSeller, Notification{To: "seller@example.com", Title: "Test", Message: strings.Repeat("a", 3000)})
 
-		require.NoError(t, err)
+		var capabilityErr *CapabilityError
+		require.ErrorAs(t, err, &capabilityErr)
+		assert.Equal(t, "PushNotification", capabilityErr.Channel)
 	})
 }
+
+func TestNotificationService_getRoutedChannels(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(*mockrepository.MockRouteCacheProvider, *mockrepository.MockPersistentProvider)
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "returns routes from cache",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				routeCache.EXPECT().Get(recipientTypeSeller).Return([]repository.NotificationRoute{
+					{ProviderType: "PushNotification"},
+					{ProviderType: "Email", Priority: 1},
+				}, nil)
+			},
+			expected: []string{"PushNotification", "Email"},
+		},
+		{
+			name: "loads routes from database on cache miss",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				routes := []repository.NotificationRoute{{ProviderType: "Email"}}
+				routeCache.EXPECT().Get(recipientTypeSeller).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindRoutesByRecipientType(gomock.Any(), recipientTypeSeller).Return(routes, nil)
+				routeCache.EXPECT().Set(recipientTypeSeller, routes).Return(nil)
+			},
+			expected: []string{"Email"},
+		},
+		{
+			name: "returns error when no routes are configured",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				routeCache.EXPECT().Get(recipientTypeSeller).Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindRoutesByRecipientType(gomock.Any(), recipientTypeSeller).Return([]repository.NotificationRoute{}, gorm.ErrRecordNotFound)
+				routeCache.EXPECT().Set(recipientTypeSeller, []repository.NotificationRoute{}).Return(nil)
+			},
+			expectedError: "not supported recipient type 'seller'",
+		},
+		{
+			name: "returns error for a cached recipient type without routes",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				routeCache.EXPECT().Get(recipientTypeSeller).Return([]repository.NotificationRoute{}, nil)
+			},
+			expectedError: "not supported recipient type 'seller'",
+		},
+		{
+			name: "returns error for provider type without a channel",
+			setupMocks: func(routeCache *mockrepository.MockRouteCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				routeCache.EXPECT().Get(recipientTypeSeller).Return([]repository.NotificationRoute{
+					{ProviderType: "Carrier Pigeon"},
+				}, nil)
+			},
+			expectedError: "provider type: no channel registered for 'Carrier Pigeon'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+			mockRouteCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			tt.setupMocks(mockRouteCache, mockPersistent)
+
+			service := NewNotificationService(NotificationServiceParams{
+				PersistentProvider: mockPersistent,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        newTestIDGenerator(ctrl),
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         mockRouteCache,
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockrepository.NewMockCacheProvider(ctrl),
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockclient.NewMockHTTPClientProvider(ctrl),
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
+			})
+
+			channels, err := service.getRoutedChannels(context.Background(), recipientTypeSeller)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(channels))
+			for _, channel := range channels {
+				names = append(names, channel.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestNotificationService_Send_Buyer_RoutedChannels(t *testing.T) {
+	t.Run("buyer routed to push only skips email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		mockRouteCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockRouteCache.EXPECT().Get(recipientTypeBuyer).Return([]repository.NotificationRoute{
+			{RecipientType: recipientTypeBuyer, ProviderType: "PushNotification"},
+		}, nil)
+		mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+			{Host: "https://push.example.com"},
+		}, nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://push.example.com", gomock.Any()).Return(nil)
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         mockRouteCache,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PushNotification"}, report.Channels)
+	})
+}
+
+func TestNotificationService_Send_RecipientTypes(t *testing.T) {
+	t.Run("delivers to a recipient type registered in the routes table", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		mockRouteCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		routes := []repository.NotificationRoute{
+			{RecipientType: "courier", ProviderType: "PushNotification"},
+		}
+		mockRouteCache.EXPECT().Get("courier").Return(nil, errors.New("cache miss"))
+		mockPersistent.EXPECT().FindRoutesByRecipientType(gomock.Any(), "courier").Return(routes, nil)
+		mockRouteCache.EXPECT().Set("courier", routes).Return(nil)
+		mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+			{Host: "https://push.example.com"},
+		}, nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://push.example.com", gomock.Any()).Return(nil)
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockPersistent,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         mockRouteCache,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), "courier", Notification{To: "courier-1", Title: "Pickup", Message: "Parcel is ready"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PushNotification"}, report.Channels)
+		assert.Equal(t, RetryNotNeeded, report.RetryDisposition)
+	})
+
+	t.Run("rejects a recipient type without routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockRouteCache := mockrepository.NewMockRouteCacheProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockRouteCache.EXPECT().Get("unknown").Return(nil, errors.New("cache miss"))
+		mockPersistent.EXPECT().FindRoutesByRecipientType(gomock.Any(), "unknown").Return([]repository.NotificationRoute{}, gorm.ErrRecordNotFound)
+		mockRouteCache.EXPECT().Set("unknown", []repository.NotificationRoute{}).Return(nil)
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockPersistent,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         mockRouteCache,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockrepository.NewMockCacheProvider(ctrl),
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockclient.NewMockHTTPClientProvider(ctrl),
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), "unknown", Notification{To: "someone", Title: "Test", Message: "Test message"})
+
+		var recipientErr *RecipientTypeError
+		require.ErrorAs(t, err, &recipientErr)
+		assert.Equal(t, "unknown", recipientErr.RecipientType)
+		assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+		assert.Zero(t, report.Attempts)
+	})
+}
+
+func TestNotificationService_SkipsUnhealthyProviders(t *testing.T) {
+	tests := []struct {
+		name          string
+		healthy       map[string]bool
+		expectedPosts []string
+	}{
+		{
+			name:          "skips unhealthy provider",
+			healthy:       map[string]bool{"https://email-1.com": false, "https://email-2.com": true},
+			expectedPosts: []string{"https://email-2.com"},
+		},
+		{
+			name:          "tries every provider when none is healthy",
+			healthy:       map[string]bool{"https://email-1.com": false, "https://email-2.com": false},
+			expectedPosts: []string{"https://email-1.com", "https://email-2.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockHealth := mockhealth.NewMockChecker(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email-1.com", SecretKey: "secret1"},
+				{Host: "https://email-2.com", SecretKey: "secret2"},
+			}, nil)
+			mockHealth.EXPECT().Healthy(gomock.Any()).DoAndReturn(func(u string) bool {
+				return tt.healthy[u]
+			}).AnyTimes()
+
+			var posts []string
+			mockHTTPClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, u string, _ client.NotificationRequest) error {
+					posts = append(posts, u)
+					if len(posts) < len(tt.expectedPosts) {
+						return errors.New("provider down")
+					}
+					return nil
+				}).Times(len(tt.expectedPosts))
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           mockHealth,
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPosts, posts)
+		})
+	}
+}
+
+func TestNotificationService_LogsAcceptedNotifications(t *testing.T) {
+	tests := []struct {
+		name     string
+		logError error
+	}{
+		{"logs the provider that accepted", nil},
+		{"delivers when the log write fails", errors.New("database down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockNotificationLog := mockrepository.NewMockNotificationLogProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email-1.com", ProviderName: "Provider1", SecretKey: "secret1"},
+				{Host: "https://email-2.com", ProviderName: "Provider2", SecretKey: "secret2"},
+			}, nil)
+			gomock.InOrder(
+				mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email-1.com", gomock.Any()).Return(errors.New("provider down")),
+				mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email-2.com", gomock.Any()).Return(nil),
+			)
+			mockNotificationLog.EXPECT().RecordNotification(gomock.Any(), repository.NotificationLog{
+				NotificationID: testNotificationID,
+				Channel:        repository.EmailProvider.String(),
+				RecipientType:  recipientTypeBuyer,
+				ProviderName:   "Provider2",
+				Recipient:      "buyer@example.com",
+				Status:         repository.LogStatusSent,
+				Tenant:         "acme",
+				Cost:           0.0008,
+				Metadata:       map[string]string{"order_id": "42"},
+			}).Return(tt.logError)
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  mockNotificationLog,
+					Costs:            CostConfig{PerMessage: map[string]float64{"Provider1": 0.0005, "Provider2": 0.0008}},
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			ctx := quota.WithSubject(context.Background(), "acme")
+			report, err := service.Send(ctx, recipientTypeBuyer, Notification{
+				To:       "buyer@example.com",
+				Title:    "Test",
+				Message:  "Test message",
+				Metadata: map[string]string{"order_id": "42"},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, testNotificationID, report.ID)
+		})
+	}
+}
+
+func TestNotificationService_ForwardsMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   MetadataConfig
+		expected map[string]string
+	}{
+		{name: "keeps metadata from providers by default"},
+		{name: "forwards metadata when enabled", config: MetadataConfig{Forward: true}, expected: map[string]string{"order_id": "42"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email.com", SecretKey: "secret"},
+			}, nil)
+			mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.com", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, req client.NotificationRequest) error {
+					assert.Equal(t, tt.expected, req.Metadata)
+					return nil
+				})
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Metadata:         tt.config,
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+				To:       "buyer@example.com",
+				Title:    "Test",
+				Message:  "Test message",
+				Metadata: map[string]string{"order_id": "42"},
+			})
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNotificationService_SkipsSuppressedEmail(t *testing.T) {
+	tests := []struct {
+		name                string
+		recipientType       string
+		suppressed          bool
+		lookupErr           error
+		expectedPosts       []string
+		expectedChannels    []string
+		expectedDisposition string
+		expectSuppression   bool
+	}{
+		{
+			name:                "refuses when email is the only channel",
+			recipientType:       recipientTypeBuyer,
+			suppressed:          true,
+			expectedDisposition: RetryDoNotRetry,
+			expectSuppression:   true,
+		},
+		{
+			name:                "delivers the other channels",
+			recipientType:       recipientTypeSeller,
+			suppressed:          true,
+			expectedPosts:       []string{"https://push.com"},
+			expectedChannels:    []string{repository.PushNotificationProvider.String()},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "fails when the lookup fails",
+			recipientType:       recipientTypeBuyer,
+			lookupErr:           errors.New("database down"),
+			expectedDisposition: RetrySafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockSuppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockSuppressions.EXPECT().IsSuppressed(gomock.Any(), "bounced@example.com").Return(tt.suppressed, tt.lookupErr)
+			mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+				{Host: "https://push.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+
+			var posts []string
+			mockHTTPClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, u string, _ client.NotificationRequest) error {
+					posts = append(posts, u)
+					return nil
+				}).Times(len(tt.expectedPosts))
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     mockSuppressions,
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), tt.recipientType, Notification{To: "bounced@example.com", Title: "Test", Message: "Test message"})
+
+			var suppressionErr *SuppressionError
+			assert.Equal(t, tt.expectSuppression, errors.As(err, &suppressionErr))
+			if tt.expectedChannels != nil {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			assert.Equal(t, tt.expectedPosts, posts)
+			assert.Equal(t, tt.expectedChannels, report.Channels)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+		})
+	}
+}
+
+func TestNotificationService_SelectsChannels(t *testing.T) {
+	tests := []struct {
+		name                string
+		channels            []string
+		expectedPosts       []string
+		expectedChannels    []string
+		expectedDisposition string
+		expectSelectionErr  bool
+	}{
+		{
+			name:                "delivers every routed channel without a selection",
+			expectedPosts:       []string{"https://email.com", "https://push.com"},
+			expectedChannels:    []string{repository.EmailProvider.String(), repository.PushNotificationProvider.String()},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "delivers only the selected channels",
+			channels:            []string{repository.PushNotificationProvider.String()},
+			expectedPosts:       []string{"https://push.com"},
+			expectedChannels:    []string{repository.PushNotificationProvider.String()},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "refuses a channel not routed to the recipient type",
+			channels:            []string{repository.EmailProvider.String(), repository.InAppProvider.String()},
+			expectedDisposition: RetryDoNotRetry,
+			expectSelectionErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+			mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+				{Host: "https://push.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+
+			var (
+				mu    sync.Mutex
+				posts []string
+			)
+			mockHTTPClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, u string, _ client.NotificationRequest) error {
+					mu.Lock()
+					defer mu.Unlock()
+					posts = append(posts, u)
+					return nil
+				}).Times(len(tt.expectedPosts))
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         newTestConsents(ctrl),
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeSeller, Notification{
+				To:       "seller@example.com",
+				Channels: tt.channels,
+				Title:    "Test",
+				Message:  "Test message",
+			})
+
+			var selectionErr *ChannelSelectionError
+			assert.Equal(t, tt.expectSelectionErr, errors.As(err, &selectionErr))
+			if tt.expectSelectionErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedPosts, posts)
+			assert.Equal(t, tt.expectedChannels, report.Channels)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+		})
+	}
+}
+
+func TestNotificationService_SkipsOptedOutChannels(t *testing.T) {
+	email := repository.EmailProvider.String()
+	push := repository.PushNotificationProvider.String()
+
+	tests := []struct {
+		name                string
+		category            string
+		consents            []repository.Consent
+		lookupErr           error
+		expectedPosts       []string
+		expectedDisposition string
+		expectOptOut        bool
+	}{
+		{
+			name:                "refuses marketing without an opt-in",
+			category:            repository.CategoryMarketing,
+			expectedDisposition: RetryDoNotRetry,
+			expectOptOut:        true,
+		},
+		{
+			name:                "delivers marketing opted in on every channel",
+			category:            repository.CategoryMarketing,
+			consents:            []repository.Consent{{Category: repository.CategoryMarketing, OptedIn: true}},
+			expectedPosts:       []string{"https://email.com", "https://push.com"},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:     "a channel consent wins over one for every channel",
+			category: repository.CategoryMarketing,
+			consents: []repository.Consent{
+				{Category: repository.CategoryMarketing, Channel: email, OptedIn: false},
+				{Category: repository.CategoryMarketing, OptedIn: true},
+			},
+			expectedPosts:       []string{"https://push.com"},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:     "delivers reminders only on the channel opted in",
+			category: repository.CategoryReminder,
+			consents: []repository.Consent{
+				{Category: repository.CategoryReminder, OptedIn: false},
+				{Category: repository.CategoryReminder, Channel: push, OptedIn: true},
+			},
+			expectedPosts:       []string{"https://push.com"},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "treats an empty category as transactional",
+			consents:            []repository.Consent{{Category: repository.CategoryTransactional, Channel: email, OptedIn: false}},
+			expectedPosts:       []string{"https://push.com"},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "ignores consents of other categories",
+			category:            repository.CategoryTransactional,
+			consents:            []repository.Consent{{Category: repository.CategoryMarketing, OptedIn: false}},
+			expectedPosts:       []string{"https://email.com", "https://push.com"},
+			expectedDisposition: RetryNotNeeded,
+		},
+		{
+			name:                "refuses when opted out of every channel",
+			category:            repository.CategoryTransactional,
+			consents:            []repository.Consent{{Category: repository.CategoryTransactional, OptedIn: false}},
+			expectedDisposition: RetryDoNotRetry,
+			expectOptOut:        true,
+		},
+		{
+			name:                "fails when the lookup fails",
+			category:            repository.CategoryMarketing,
+			lookupErr:           errors.New("database down"),
+			expectedDisposition: RetrySafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockCacheProvider(ctrl)
+			mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+			mockConsents := mockrepository.NewMockConsentProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			mockConsents.EXPECT().ListConsents(gomock.Any(), "seller@example.com").Return(tt.consents, tt.lookupErr)
+			mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+				{Host: "https://email.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+			mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+				{Host: "https://push.com", SecretKey: "secret"},
+			}, nil).AnyTimes()
+
+			var (
+				mu    sync.Mutex
+				posts []string
+			)
+			mockHTTPClient.EXPECT().Post(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, u string, _ client.NotificationRequest) error {
+					mu.Lock()
+					defer mu.Unlock()
+					posts = append(posts, u)
+					return nil
+				}).Times(len(tt.expectedPosts))
+
+			service := NewNotificationService(NotificationServiceParams{
+				MetricsCollector: metricsCollector,
+				IDGenerator:      newTestIDGenerator(ctrl),
+				Dispatcher:       newTestDispatcher(t),
+				Suppressions:     newTestSuppressions(ctrl),
+				SuppressionCache: newTestSuppressionCache(ctrl),
+				Consents:         mockConsents,
+				ConsentCache:     newTestConsentCache(ctrl),
+				Quotas:           newTestQuotas(ctrl),
+				RouteCache:       newTestRouteCache(ctrl),
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:    mockCache,
+					HTTPclient:       mockHTTPClient,
+					MetricsCollector: metricsCollector,
+					Secrets:          newTestSecrets(ctrl),
+					Health:           newTestHealth(ctrl),
+					NotificationLog:  newTestNotificationLog(ctrl),
+					Alerter:          newTestAlerter(ctrl),
+				}),
+			})
+
+			report, err := service.Send(context.Background(), recipientTypeSeller, Notification{
+				To:       "seller@example.com",
+				Title:    "Test",
+				Message:  "Test message",
+				Category: tt.category,
+			})
+
+			var optOutErr *OptOutError
+			assert.Equal(t, tt.expectOptOut, errors.As(err, &optOutErr))
+			if tt.expectedPosts != nil {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			assert.ElementsMatch(t, tt.expectedPosts, posts)
+			assert.Equal(t, tt.expectedDisposition, report.RetryDisposition)
+		})
+	}
+}
+
+func TestNotificationService_isSuppressed(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(*mockrepository.MockSuppressionCacheProvider, *mockrepository.MockSuppressionProvider)
+		expected      bool
+		expectedError string
+	}{
+		{
+			name: "returns the cached suppression",
+			setupMocks: func(cache *mockrepository.MockSuppressionCacheProvider, suppressions *mockrepository.MockSuppressionProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(true, nil)
+			},
+			expected: true,
+		},
+		{
+			name: "looks the address up on cache miss and caches it",
+			setupMocks: func(cache *mockrepository.MockSuppressionCacheProvider, suppressions *mockrepository.MockSuppressionProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(false, errors.New("cache miss"))
+				suppressions.EXPECT().IsSuppressed(gomock.Any(), "seller@example.com").Return(false, nil)
+				cache.EXPECT().Set("seller@example.com", false).Return(nil)
+			},
+			expected: false,
+		},
+		{
+			name: "caches nothing when the lookup fails",
+			setupMocks: func(cache *mockrepository.MockSuppressionCacheProvider, suppressions *mockrepository.MockSuppressionProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(false, errors.New("cache miss"))
+				suppressions.EXPECT().IsSuppressed(gomock.Any(), "seller@example.com").Return(false, errors.New("database down"))
+			},
+			expectedError: "database down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockSuppressionCacheProvider(ctrl)
+			mockSuppressions := mockrepository.NewMockSuppressionProvider(ctrl)
+			tt.setupMocks(mockCache, mockSuppressions)
+
+			service := NewNotificationService(NotificationServiceParams{
+				Suppressions:     mockSuppressions,
+				SuppressionCache: mockCache,
+			})
+
+			suppressed, err := service.isSuppressed(context.Background(), "seller@example.com")
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, suppressed)
+		})
+	}
+}
+
+func TestNotificationService_getConsents(t *testing.T) {
+	consents := []repository.Consent{{Category: repository.CategoryMarketing, OptedIn: true}}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*mockrepository.MockConsentCacheProvider, *mockrepository.MockConsentProvider)
+		expected      []repository.Consent
+		expectedError string
+	}{
+		{
+			name: "returns the cached consents",
+			setupMocks: func(cache *mockrepository.MockConsentCacheProvider, persistent *mockrepository.MockConsentProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(consents, nil)
+			},
+			expected: consents,
+		},
+		{
+			name: "lists the consents on cache miss and caches them",
+			setupMocks: func(cache *mockrepository.MockConsentCacheProvider, persistent *mockrepository.MockConsentProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().ListConsents(gomock.Any(), "seller@example.com").Return(consents, nil)
+				cache.EXPECT().Set("seller@example.com", consents).Return(nil)
+			},
+			expected: consents,
+		},
+		{
+			name: "caches nothing when the lookup fails",
+			setupMocks: func(cache *mockrepository.MockConsentCacheProvider, persistent *mockrepository.MockConsentProvider) {
+				cache.EXPECT().Get("seller@example.com").Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().ListConsents(gomock.Any(), "seller@example.com").Return(nil, errors.New("database down"))
+			},
+			expectedError: "database down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCache := mockrepository.NewMockConsentCacheProvider(ctrl)
+			mockConsents := mockrepository.NewMockConsentProvider(ctrl)
+			tt.setupMocks(mockCache, mockConsents)
+
+			service := NewNotificationService(NotificationServiceParams{
+				Consents:     mockConsents,
+				ConsentCache: mockCache,
+			})
+
+			got, err := service.getConsents(context.Background(), "seller@example.com")
+
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestNotificationService_QuotaExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuotas := mockquota.NewMockLimiter(ctrl)
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	exceededErr := &quota.ExceededError{Subject: "acme", Period: repository.QuotaPeriodDay, Limit: 100}
+	mockQuotas.EXPECT().Consume(gomock.Any()).Return(exceededErr)
+
+	service := NewNotificationService(NotificationServiceParams{
+		MetricsCollector: metricsCollector,
+		IDGenerator:      newTestIDGenerator(ctrl),
+		Dispatcher:       newTestDispatcher(t),
+		Suppressions:     newTestSuppressions(ctrl),
+		SuppressionCache: newTestSuppressionCache(ctrl),
+		Consents:         newTestConsents(ctrl),
+		ConsentCache:     newTestConsentCache(ctrl),
+		Quotas:           mockQuotas,
+		RouteCache:       newTestRouteCache(ctrl),
+		Channels: newTestChannels(ProviderChannelParams{
+			HTTPclient:       mockHTTPClient,
+			MetricsCollector: metricsCollector,
+			Alerter:          newTestAlerter(ctrl),
+		}),
+	})
+
+	report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+
+	assert.ErrorIs(t, err, exceededErr)
+	assert.Equal(t, RetrySafe, report.RetryDisposition)
+	assert.Zero(t, report.Attempts)
+}
+
+func TestNotificationService_AcquireFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Consume has no expectation: a notification that never gets a slot
+	// keeps its quota
+	mockQuotas := mockquota.NewMockLimiter(ctrl)
+	mockDispatcher := mockdispatch.NewMockDispatcher(ctrl)
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	mockDispatcher.EXPECT().Acquire(gomock.Any(), dispatch.PriorityNormal).Return(nil, context.DeadlineExceeded)
+
+	service := NewNotificationService(NotificationServiceParams{
+		MetricsCollector: metricsCollector,
+		IDGenerator:      newTestIDGenerator(ctrl),
+		Dispatcher:       mockDispatcher,
+		Suppressions:     newTestSuppressions(ctrl),
+		SuppressionCache: newTestSuppressionCache(ctrl),
+		Consents:         newTestConsents(ctrl),
+		ConsentCache:     newTestConsentCache(ctrl),
+		Quotas:           mockQuotas,
+		RouteCache:       newTestRouteCache(ctrl),
+		Channels: newTestChannels(ProviderChannelParams{
+			HTTPclient:       mockHTTPClient,
+			MetricsCollector: metricsCollector,
+			Alerter:          newTestAlerter(ctrl),
+		}),
+	})
+
+	ctx := quota.WithSubject(context.Background(), "acme")
+	report, err := service.Send(ctx, recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message", Priority: dispatch.PriorityNormal})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, report.Attempts)
+}
@@ -0,0 +1,36 @@
+package service
+
+import "github.com/koungkub/fw-challenge-notification-service/internal/repository"
+
+// splitTraffic moves the preference drawn by the traffic split to the front,
+// keeping the others in priority order as its fallbacks. Each preference
+// with a TrafficPercent takes that share of the sends; the share left
+// unassigned keeps the plain priority order. When the percentages add up to
+// more than 100 they are used as relative weights. draw is in [0.0, 1.0)
+func splitTraffic(preferences []repository.NotificationPreference, draw float64) []repository.NotificationPreference {
+	total := 0
+	for _, preference := range preferences {
+		total += preference.TrafficPercent
+	}
+	if total == 0 {
+		return preferences
+	}
+
+	share := draw * float64(max(total, 100))
+	cumulative := 0
+	for i, preference := range preferences {
+		cumulative += preference.TrafficPercent
+		if share >= float64(cumulative) {
+			continue
+		}
+		if i == 0 {
+			return preferences
+		}
+
+		split := make([]repository.NotificationPreference, 0, len(preferences))
+		split = append(split, preference)
+		split = append(split, preferences[:i]...)
+		return append(split, preferences[i+1:]...)
+	}
+	return preferences
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSplitTraffic(t *testing.T) {
+	hosts := func(preferences []repository.NotificationPreference) []string {
+		var hosts []string
+		for _, preference := range preferences {
+			hosts = append(hosts, preference.Host)
+		}
+		return hosts
+	}
+
+	tests := []struct {
+		name          string
+		percents      []int
+		draw          float64
+		expectedHosts []string
+	}{
+		{
+			name:          "no split keeps priority order",
+			percents:      []int{0, 0, 0},
+			draw:          0.01,
+			expectedHosts: []string{"a", "b", "c"},
+		},
+		{
+			name:          "draw inside the canary share moves it first",
+			percents:      []int{0, 5, 0},
+			draw:          0.04,
+			expectedHosts: []string{"b", "a", "c"},
+		},
+		{
+			name:          "draw outside the canary share keeps priority order",
+			percents:      []int{0, 5, 0},
+			draw:          0.05,
+			expectedHosts: []string{"a", "b", "c"},
+		},
+		{
+			name:          "shares are taken in priority order",
+			percents:      []int{95, 5, 0},
+			draw:          0.97,
+			expectedHosts: []string{"b", "a", "c"},
+		},
+		{
+			name:          "first share keeps priority order",
+			percents:      []int{95, 5, 0},
+			draw:          0.5,
+			expectedHosts: []string{"a", "b", "c"},
+		},
+		{
+			name:          "percentages above 100 are relative weights",
+			percents:      []int{0, 100, 100},
+			draw:          0.75,
+			expectedHosts: []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preferences := []repository.NotificationPreference{
+				{Host: "a", TrafficPercent: tt.percents[0]},
+				{Host: "b", TrafficPercent: tt.percents[1]},
+				{Host: "c", TrafficPercent: tt.percents[2]},
+			}
+
+			split := splitTraffic(preferences, tt.draw)
+
+			assert.Equal(t, tt.expectedHosts, hosts(split))
+			assert.Equal(t, []string{"a", "b", "c"}, hosts(preferences), "input is not reordered")
+		})
+	}
+}
+
+func TestNotificationService_TrafficSplit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mockrepository.NewMockCacheProvider(ctrl)
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+		{Host: "https://incumbent.com", SecretKey: "secret1"},
+		{Host: "https://canary.com", SecretKey: "secret2", TrafficPercent: 5},
+	}, nil)
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://canary.com", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req client.NotificationRequest) error {
+			assert.Equal(t, "secret2", req.SecretKey)
+			return nil
+		})
+
+	channel := NewEmailChannel(ProviderChannelParams{
+		CacheProvider:    mockCache,
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          newTestSecrets(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Alerter:          newTestAlerter(ctrl),
+	})
+	channel.random = func() float64 { return 0.01 }
+
+	service := NewNotificationService(NotificationServiceParams{
+		MetricsCollector: metricsCollector,
+		IDGenerator:      newTestIDGenerator(ctrl),
+		Dispatcher:       newTestDispatcher(t),
+		Suppressions:     newTestSuppressions(ctrl),
+		SuppressionCache: newTestSuppressionCache(ctrl),
+		Consents:         newTestConsents(ctrl),
+		ConsentCache:     newTestConsentCache(ctrl),
+		Quotas:           newTestQuotas(ctrl),
+		RouteCache:       newTestRouteCache(ctrl),
+		Channels:         []Channel{channel},
+	})
+
+	_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{To: "buyer@example.com", Title: "Test", Message: "Test message"})
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"hash/fnv"
+	"slices"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+type StickyConfig struct {
+	// Channels lists the channels whose recipients always go through the
+	// same provider first, e.g. so the vendor threads or dedups their
+	// notifications; the traffic split is not drawn for them
+	Channels []string `envconfig:"STICKY_PROVIDER_CHANNELS"`
+}
+
+func (c StickyConfig) sticky(channel string) bool {
+	return slices.Contains(c.Channels, channel)
+}
+
+// stickToRecipient moves the provider recipient hashes to ahead of the
+// others, keeping them in order as its fallbacks. Only the preferences of
+// region compete when there are any, so stickiness never defeats the
+// region. Each provider scores the recipient on its own host, so enabling or
+// removing one only moves the recipients it wins or held
+func stickToRecipient(preferences []repository.NotificationPreference, recipient, region string) []repository.NotificationPreference {
+	if region == "" || !slices.ContainsFunc(preferences, func(preference repository.NotificationPreference) bool {
+		return preference.Region == region
+	}) {
+		region = ""
+	}
+
+	chosen, best := -1, uint64(0)
+	for i, preference := range preferences {
+		if region != "" && preference.Region != region {
+			continue
+		}
+		if score := stickyScore(recipient, preference.Host); chosen < 0 || score > best {
+			chosen, best = i, score
+		}
+	}
+	if chosen <= 0 {
+		return preferences
+	}
+
+	sticky := make([]repository.NotificationPreference, 0, len(preferences))
+	sticky = append(sticky, preferences[chosen])
+	sticky = append(sticky, preferences[:chosen]...)
+	return append(sticky, preferences[chosen+1:]...)
+}
+
+func stickyScore(recipient, host string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(recipient))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(host))
+	return hash.Sum64()
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStickToRecipient(t *testing.T) {
+	preferences := []repository.NotificationPreference{
+		{Host: "a", Region: "us-east-1"},
+		{Host: "b", Region: "us-east-1"},
+		{Host: "c", Region: "ap-southeast-1"},
+		{Host: "d", Region: "ap-southeast-1"},
+	}
+
+	hosts := func(preferences []repository.NotificationPreference) []string {
+		var hosts []string
+		for _, preference := range preferences {
+			hosts = append(hosts, preference.Host)
+		}
+		return hosts
+	}
+
+	t.Run("always leads with the same provider for a recipient", func(t *testing.T) {
+		first := stickToRecipient(preferences, "buyer@example.com", "")
+		for range 10 {
+			assert.Equal(t, hosts(first), hosts(stickToRecipient(preferences, "buyer@example.com", "")))
+		}
+	})
+
+	t.Run("keeps the others in order as fallbacks", func(t *testing.T) {
+		sticky := stickToRecipient(preferences, "buyer@example.com", "")
+
+		var rest []string
+		for _, host := range []string{"a", "b", "c", "d"} {
+			if host != sticky[0].Host {
+				rest = append(rest, host)
+			}
+		}
+		assert.Equal(t, rest, hosts(sticky[1:]))
+	})
+
+	t.Run("spreads recipients across the providers", func(t *testing.T) {
+		leaders := map[string]int{}
+		for _, recipient := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com", "f@example.com", "g@example.com", "h@example.com"} {
+			leaders[stickToRecipient(preferences, recipient, "")[0].Host]++
+		}
+		assert.Greater(t, len(leaders), 1)
+	})
+
+	t.Run("only picks among the providers of the region", func(t *testing.T) {
+		for _, recipient := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+			assert.Equal(t, "ap-southeast-1", stickToRecipient(preferences, recipient, "ap-southeast-1")[0].Region)
+		}
+	})
+
+	t.Run("picks among all providers when none is in the region", func(t *testing.T) {
+		sticky := stickToRecipient(preferences, "buyer@example.com", "eu-west-1")
+		assert.Equal(t, hosts(stickToRecipient(preferences, "buyer@example.com", "")), hosts(sticky))
+	})
+
+	t.Run("keeps the recipient on its provider when another is removed", func(t *testing.T) {
+		sticky := stickToRecipient(preferences, "buyer@example.com", "")
+		remaining := slices.DeleteFunc(slices.Clone(preferences), func(preference repository.NotificationPreference) bool {
+			return preference.Host == sticky[1].Host
+		})
+
+		assert.Equal(t, sticky[0].Host, stickToRecipient(remaining, "buyer@example.com", "")[0].Host)
+	})
+}
+
+func TestProviderChannel_sendNotification_Sticky(t *testing.T) {
+	preferences := []repository.NotificationPreference{
+		{ProviderName: "first", Host: "https://first.email.com"},
+		{ProviderName: "second", Host: "https://second.email.com", Priority: 1},
+	}
+	sticky := stickToRecipient(preferences, "buyer@example.com", "")
+	leader, fallback := sticky[0], sticky[1]
+
+	// The whole traffic split goes to the fallback
+	for i := range preferences {
+		if preferences[i].Host == fallback.Host {
+			preferences[i].TrafficPercent = 100
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	// The provider of the recipient is tried first despite the traffic
+	// split, the other only once it failed
+	gomock.InOrder(
+		mockHTTPClient.EXPECT().Post(gomock.Any(), leader.Host, gomock.Any()).
+			Return(&client.ProviderError{StatusCode: 503}),
+		mockHTTPClient.EXPECT().Post(gomock.Any(), fallback.Host, gomock.Any()).
+			Return(nil),
+	)
+
+	channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          newTestSecrets(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Sticky:           StickyConfig{Channels: []string{"Email"}},
+		Alerter:          newTestAlerter(ctrl),
+	})
+
+	err := channel.sendNotification(context.Background(), recipientTypeBuyer, preferences, client.NotificationRequest{To: "buyer@example.com"})
+
+	require.NoError(t, err)
+}
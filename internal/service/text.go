@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalized returns the notification with its title and message in NFC and
+// without control characters, which some providers reject; line breaks and
+// tabs are kept
+func (n Notification) normalized() Notification {
+	n.Title = normalizeText(n.Title)
+	n.Message = normalizeText(n.Message)
+	return n
+}
+
+func normalizeText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, norm.NFC.String(text))
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "composes decomposed characters", text: "Cafe\u0301 cre\u0300me", expected: "Caf\u00e9 cr\u00e8me"},
+		{name: "strips control characters", text: "Order\u0000 shipped\u001b\u007f\u0085", expected: "Order shipped"},
+		{name: "keeps line breaks and tabs", text: "Order shipped\n\tTrack it", expected: "Order shipped\n\tTrack it"},
+		{name: "leaves plain text untouched", text: "Your order is on its way", expected: "Your order is on its way"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeText(tt.text))
+		})
+	}
+}
+
+func TestNotification_normalized(t *testing.T) {
+	notification := Notification{
+		To:      "buyer@example.com",
+		Title:   "Cafe\u0301\u0007",
+		Message: "Order\r\nshipped",
+		HTML:    "<p>Cafe\u0301</p>",
+	}
+
+	assert.Equal(t, Notification{
+		To:      "buyer@example.com",
+		Title:   "Caf\u00e9",
+		Message: "Order\nshipped",
+		HTML:    "<p>Cafe\u0301</p>",
+	}, notification.normalized())
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+// threadMessageIDDomain is the right-hand side of the synthetic Message-ID
+// every email in a thread references
+const threadMessageIDDomain = "notification-service"
+
+// applyThreading maps the thread key onto the threading mechanism of the
+// provider kind: References/In-Reply-To headers for email and a collapse
+// key for push, so a sequence of updates shows as one conversation
+func applyThreading(req client.NotificationRequest, providerType repository.NotificationProvider) client.NotificationRequest {
+	if req.ThreadKey == "" {
+		return req
+	}
+
+	switch providerType {
+	case repository.EmailProvider:
+		messageID := threadMessageID(req.ThreadKey)
+		req.Headers = map[string]string{
+			"References":  messageID,
+			"In-Reply-To": messageID,
+		}
+	case repository.PushNotificationProvider:
+		req.CollapseKey = req.ThreadKey
+	}
+
+	return req
+}
+
+// threadMessageID derives a stable RFC 5322 msg-id from the thread key;
+// hashing keeps arbitrary keys within the allowed character set
+func threadMessageID(threadKey string) string {
+	sum := sha256.Sum256([]byte(threadKey))
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(sum[:16]), threadMessageIDDomain)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestApplyThreading(t *testing.T) {
+	base := client.NotificationRequest{To: "user@example.com", Title: "Order shipped", ThreadKey: "order-42"}
+
+	t.Run("email references a stable message id", func(t *testing.T) {
+		req := applyThreading(base, repository.EmailProvider)
+
+		messageID := threadMessageID("order-42")
+		assert.Regexp(t, `^<[0-9a-f]{32}@notification-service>$`, messageID)
+		assert.Equal(t, map[string]string{
+			"References":  messageID,
+			"In-Reply-To": messageID,
+		}, req.Headers)
+		assert.Empty(t, req.CollapseKey)
+	})
+
+	t.Run("push collapses on the thread key", func(t *testing.T) {
+		req := applyThreading(base, repository.PushNotificationProvider)
+
+		assert.Equal(t, "order-42", req.CollapseKey)
+		assert.Nil(t, req.Headers)
+	})
+
+	t.Run("no thread key leaves the request untouched", func(t *testing.T) {
+		req := client.NotificationRequest{To: "user@example.com"}
+
+		assert.Equal(t, req, applyThreading(req, repository.EmailProvider))
+		assert.Equal(t, req, applyThreading(req, repository.PushNotificationProvider))
+	})
+
+	t.Run("same key threads together, different keys do not", func(t *testing.T) {
+		assert.Equal(t, threadMessageID("order-42"), threadMessageID("order-42"))
+		assert.NotEqual(t, threadMessageID("order-42"), threadMessageID("order-43"))
+	})
+}
+
+func TestNotificationService_Send_Seller_Threading(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mockrepository.NewMockCacheProvider(ctrl)
+	mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+	mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+		{Host: "https://email.example.com", SecretKey: "email-secret"},
+	}, nil)
+	mockCache.EXPECT().Get(repository.PushNotificationProvider).Return([]repository.NotificationPreference{
+		{Host: "https://push.example.com", SecretKey: "push-secret"},
+	}, nil)
+
+	messageID := threadMessageID("order-42")
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.example.com", client.NotificationRequest{
+		ID:        testNotificationID,
+		To:        "seller@example.com",
+		Title:     "Order update",
+		Message:   "Shipped",
+		SecretKey: "email-secret",
+		ThreadKey: "order-42",
+		Headers: map[string]string{
+			"References":  messageID,
+			"In-Reply-To": messageID,
+		},
+	}).Return(nil)
+	mockHTTPClient.EXPECT().Post(gomock.Any(), "https://push.example.com", client.NotificationRequest{
+		ID:          testNotificationID,
+		To:          "seller@example.com",
+		Title:       "Order update",
+		Message:     "Shipped",
+		SecretKey:   "push-secret",
+		ThreadKey:   "order-42",
+		CollapseKey: "order-42",
+	}).Return(nil)
+
+	service := NewNotificationService(NotificationServiceParams{
+		PersistentProvider: mockPersistent,
+		MetricsCollector:   metricsCollector,
+		IDGenerator:        newTestIDGenerator(ctrl),
+		Dispatcher:         newTestDispatcher(t),
+		Suppressions:       newTestSuppressions(ctrl),
+		SuppressionCache:   newTestSuppressionCache(ctrl),
+		Consents:           newTestConsents(ctrl),
+		ConsentCache:       newTestConsentCache(ctrl),
+		Quotas:             newTestQuotas(ctrl),
+		RouteCache:         newTestRouteCache(ctrl),
+		Channels: newTestChannels(ProviderChannelParams{
+			CacheProvider:      mockCache,
+			PersistentProvider: mockPersistent,
+			HTTPclient:         mockHTTPClient,
+			MetricsCollector:   metricsCollector,
+			Secrets:            newTestSecrets(ctrl),
+			Health:             newTestHealth(ctrl),
+			NotificationLog:    newTestNotificationLog(ctrl),
+			Alerter:            newTestAlerter(ctrl),
+		}),
+	})
+
+	_, err := service.Send(context.Background(), recipientTypeSeller, Notification{
+		To:        "seller@example.com",
+		Title:     "Order update",
+		Message:   "Shipped",
+		ThreadKey: "order-42",
+	})
+	require.NoError(t, err)
+}
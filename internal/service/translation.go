@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// defaultLocale ends every fallback chain
+const defaultLocale = "en"
+
+type TranslationError struct {
+	Key    string
+	Locale string
+	Reason string
+}
+
+func (e *TranslationError) Error() string {
+	return fmt.Sprintf("translation key '%s' for locale '%s': %s", e.Key, e.Locale, e.Reason)
+}
+
+// TemplateError is a notification naming an admin template that does not
+// exist or does not render with its params
+type TemplateError struct {
+	Name   string
+	Reason string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template '%s': %s", e.Name, e.Reason)
+}
+
+// localeFallbacks returns the locales tried for a language tag, most
+// specific first, e.g. th-TH, th, en
+func localeFallbacks(locale string) []string {
+	var locales []string
+	for tag := locale; tag != ""; {
+		locales = append(locales, tag)
+
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+
+	if len(locales) == 0 || !strings.EqualFold(locales[len(locales)-1], defaultLocale) {
+		locales = append(locales, defaultLocale)
+	}
+	return locales
+}
+
+// localize replaces the title and message with the active version of the
+// named template, then with the translations of their template keys, all
+// rendered with the notification params
+func (s *NotificationService) localize(ctx context.Context, notification Notification) (Notification, error) {
+	var err error
+
+	if notification.Template != "" {
+		notification.Title, notification.Message, err = s.renderTemplate(ctx, notification.Template, notification.Params)
+		if err != nil {
+			return notification, err
+		}
+	}
+
+	if notification.TitleKey != "" {
+		notification.Title, err = s.translate(ctx, notification.TitleKey, notification.Locale, notification.Params)
+		if err != nil {
+			return notification, err
+		}
+	}
+
+	if notification.MessageKey != "" {
+		notification.Message, err = s.translate(ctx, notification.MessageKey, notification.Locale, notification.Params)
+		if err != nil {
+			return notification, err
+		}
+	}
+
+	return notification, nil
+}
+
+func (s *NotificationService) translate(ctx context.Context, key string, locale string, params map[string]string) (string, error) {
+	translations, err := s.getTranslations(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	translation, ok := selectTranslation(translations, localeFallbacks(locale))
+	if !ok {
+		return "", &TranslationError{Key: key, Locale: locale, Reason: "no translation found"}
+	}
+
+	text, err := RenderTemplate(key, translation.Text, params)
+	if err != nil {
+		return "", &TranslationError{Key: key, Locale: translation.Locale, Reason: err.Error()}
+	}
+
+	return text, nil
+}
+
+// renderTemplate renders the title and message of the active version of an
+// admin template. It is read on every send, so an activated version is used
+// at once
+func (s *NotificationService) renderTemplate(ctx context.Context, name string, params map[string]string) (string, string, error) {
+	lookupCtx, cancel := s.budget.lookup(ctx)
+	defer cancel()
+
+	content, err := s.templates.FindActiveTemplateVersion(lookupCtx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", &TemplateError{Name: name, Reason: "no such template"}
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	title, err := RenderTemplate(name+".title", content.Title, params)
+	if err != nil {
+		return "", "", &TemplateError{Name: name, Reason: err.Error()}
+	}
+	message, err := RenderTemplate(name+".message", content.Message, params)
+	if err != nil {
+		return "", "", &TemplateError{Name: name, Reason: err.Error()}
+	}
+
+	return title, message, nil
+}
+
+// ParseTemplate checks the text parses as a text/template
+func ParseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// RenderTemplate renders the text with the params the way notification
+// content is rendered; a param the text uses but params lacks fails it
+func RenderTemplate(name string, text string, params map[string]string) (string, error) {
+	tmpl, err := ParseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, params); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+func (s *NotificationService) getTranslations(ctx context.Context, key string) ([]repository.NotificationTranslation, error) {
+	translations, err := s.translationCache.Get(key)
+	if err == nil {
+		return translations, nil
+	}
+
+	lookupCtx, cancel := s.budget.lookup(ctx)
+	defer cancel()
+
+	translations, err = s.persistentProvider.FindTranslationsByKey(lookupCtx, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// A missing key is remembered briefly, so sends naming it do not
+		// query the database each time; it is reported like a missing locale
+		translations, err = []repository.NotificationTranslation{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.translationCache.Set(key, translations)
+	return translations, nil
+}
+
+// selectTranslation returns the translation of the first locale in the
+// chain that has one; locales are matched case-insensitively
+func selectTranslation(translations []repository.NotificationTranslation, locales []string) (repository.NotificationTranslation, bool) {
+	for _, locale := range locales {
+		for _, translation := range translations {
+			if strings.EqualFold(translation.Locale, locale) {
+				return translation, true
+			}
+		}
+	}
+	return repository.NotificationTranslation{}, false
+}
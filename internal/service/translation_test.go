@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	mockrepository "github.com/koungkub/fw-challenge-notification-service/internal/repository/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestLocaleFallbacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   string
+		expected []string
+	}{
+		{name: "region falls back to language then default", locale: "th-TH", expected: []string{"th-TH", "th", "en"}},
+		{name: "script and region", locale: "zh-Hant-TW", expected: []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}},
+		{name: "language only", locale: "th", expected: []string{"th", "en"}},
+		{name: "default language is not repeated", locale: "en-GB", expected: []string{"en-GB", "en"}},
+		{name: "empty locale uses default", locale: "", expected: []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, localeFallbacks(tt.locale))
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		params        map[string]string
+		expected      string
+		expectedError bool
+	}{
+		{name: "renders params", text: "Order {{.order_id}} has shipped", params: map[string]string{"order_id": "A1"}, expected: "Order A1 has shipped"},
+		{name: "renders plain text", text: "Welcome", expected: "Welcome"},
+		{name: "fails on missing param", text: "Order {{.order_id}}", params: map[string]string{}, expectedError: true},
+		{name: "fails on invalid syntax", text: "Order {{.order_id", params: map[string]string{"order_id": "A1"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := RenderTemplate("order_shipped", tt.text, tt.params)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rendered)
+		})
+	}
+}
+
+func TestNotificationService_translate(t *testing.T) {
+	translations := []repository.NotificationTranslation{
+		{Key: "order_shipped.title", Locale: "en", Text: "Order {{.order_id}} has shipped"},
+		{Key: "order_shipped.title", Locale: "th", Text: "คำสั่งซื้อ {{.order_id}} ถูกจัดส่งแล้ว"},
+		{Key: "order_shipped.title", Locale: "th-TH-x-formal", Text: "unused"},
+	}
+
+	tests := []struct {
+		name          string
+		locale        string
+		params        map[string]string
+		setupMocks    func(*mockrepository.MockTranslationCacheProvider, *mockrepository.MockPersistentProvider)
+		expected      string
+		expectedError string
+	}{
+		{
+			name:   "falls back from region to language",
+			locale: "th-TH",
+			params: map[string]string{"order_id": "42"},
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(translations, nil)
+			},
+			expected: "คำสั่งซื้อ 42 ถูกจัดส่งแล้ว",
+		},
+		{
+			name:   "falls back to default locale",
+			locale: "ja-JP",
+			params: map[string]string{"order_id": "42"},
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(translations, nil)
+			},
+			expected: "Order 42 has shipped",
+		},
+		{
+			name:   "matches locales case-insensitively",
+			locale: "TH",
+			params: map[string]string{"order_id": "42"},
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(translations, nil)
+			},
+			expected: "คำสั่งซื้อ 42 ถูกจัดส่งแล้ว",
+		},
+		{
+			name:   "loads translations from database on cache miss",
+			locale: "en",
+			params: map[string]string{"order_id": "42"},
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindTranslationsByKey(gomock.Any(), "order_shipped.title").Return(translations, nil)
+				cache.EXPECT().Set("order_shipped.title", translations).Return(nil)
+			},
+			expected: "Order 42 has shipped",
+		},
+		{
+			name:   "unknown key",
+			locale: "en",
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(nil, errors.New("cache miss"))
+				persistent.EXPECT().FindTranslationsByKey(gomock.Any(), "order_shipped.title").Return([]repository.NotificationTranslation{}, gorm.ErrRecordNotFound)
+				cache.EXPECT().Set("order_shipped.title", []repository.NotificationTranslation{}).Return(nil)
+			},
+			expectedError: "translation key 'order_shipped.title' for locale 'en': no translation found",
+		},
+		{
+			name:   "cached unknown key",
+			locale: "en",
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return([]repository.NotificationTranslation{}, nil)
+			},
+			expectedError: "translation key 'order_shipped.title' for locale 'en': no translation found",
+		},
+		{
+			name:   "missing template param",
+			locale: "en",
+			params: map[string]string{},
+			setupMocks: func(cache *mockrepository.MockTranslationCacheProvider, persistent *mockrepository.MockPersistentProvider) {
+				cache.EXPECT().Get("order_shipped.title").Return(translations, nil)
+			},
+			expectedError: "map has no entry for key \"order_id\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+			mockTranslationCache := mockrepository.NewMockTranslationCacheProvider(ctrl)
+			metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+			tt.setupMocks(mockTranslationCache, mockPersistent)
+
+			service := NewNotificationService(NotificationServiceParams{
+				PersistentProvider: mockPersistent,
+				MetricsCollector:   metricsCollector,
+				IDGenerator:        newTestIDGenerator(ctrl),
+				Dispatcher:         newTestDispatcher(t),
+				Suppressions:       newTestSuppressions(ctrl),
+				SuppressionCache:   newTestSuppressionCache(ctrl),
+				Consents:           newTestConsents(ctrl),
+				ConsentCache:       newTestConsentCache(ctrl),
+				Quotas:             newTestQuotas(ctrl),
+				RouteCache:         newTestRouteCache(ctrl),
+				TranslationCache:   mockTranslationCache,
+				Channels: newTestChannels(ProviderChannelParams{
+					CacheProvider:      mockrepository.NewMockCacheProvider(ctrl),
+					PersistentProvider: mockPersistent,
+					HTTPclient:         mockclient.NewMockHTTPClientProvider(ctrl),
+					MetricsCollector:   metricsCollector,
+					Secrets:            newTestSecrets(ctrl),
+					Health:             newTestHealth(ctrl),
+					NotificationLog:    newTestNotificationLog(ctrl),
+					Alerter:            newTestAlerter(ctrl),
+				}),
+			})
+
+			text, err := service.translate(context.Background(), "order_shipped.title", tt.locale, tt.params)
+
+			if tt.expectedError != "" {
+				var translationErr *TranslationError
+				require.ErrorAs(t, err, &translationErr)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+}
+
+func TestNotificationService_renderTemplate(t *testing.T) {
+	active := repository.TemplateVersion{
+		TemplateName: "order_shipped",
+		Version:      2,
+		Title:        "Order {{.order_id}} has shipped",
+		Message:      "Your order {{.order_id}} is on its way.",
+	}
+
+	tests := []struct {
+		name            string
+		params          map[string]string
+		setupMocks      func(*mockrepository.MockTemplateProvider)
+		expectedTitle   string
+		expectedMessage string
+		expectedError   string
+	}{
+		{
+			name:   "renders the active version",
+			params: map[string]string{"order_id": "42"},
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindActiveTemplateVersion(gomock.Any(), "order_shipped").Return(active, nil)
+			},
+			expectedTitle:   "Order 42 has shipped",
+			expectedMessage: "Your order 42 is on its way.",
+		},
+		{
+			name: "unknown template",
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindActiveTemplateVersion(gomock.Any(), "order_shipped").Return(repository.TemplateVersion{}, gorm.ErrRecordNotFound)
+			},
+			expectedError: "template 'order_shipped': no such template",
+		},
+		{
+			name:   "missing template param",
+			params: map[string]string{},
+			setupMocks: func(templates *mockrepository.MockTemplateProvider) {
+				templates.EXPECT().FindActiveTemplateVersion(gomock.Any(), "order_shipped").Return(active, nil)
+			},
+			expectedError: "map has no entry for key \"order_id\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			templates := mockrepository.NewMockTemplateProvider(ctrl)
+			tt.setupMocks(templates)
+
+			service := NewNotificationService(NotificationServiceParams{Templates: templates})
+
+			title, message, err := service.renderTemplate(context.Background(), "order_shipped", tt.params)
+
+			if tt.expectedError != "" {
+				var templateErr *TemplateError
+				require.ErrorAs(t, err, &templateErr)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTitle, title)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}
+
+func TestNotificationService_Send_Localized(t *testing.T) {
+	t.Run("renders keyed title and message before delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		mockTranslationCache := mockrepository.NewMockTranslationCacheProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockTranslationCache.EXPECT().Get("order_shipped.title").Return([]repository.NotificationTranslation{
+			{Locale: "th", Text: "คำสั่งซื้อ {{.order_id}} ถูกจัดส่งแล้ว"},
+		}, nil)
+		mockTranslationCache.EXPECT().Get("order_shipped.message").Return([]repository.NotificationTranslation{
+			{Locale: "en", Text: "Your order {{.order_id}} is on its way."},
+		}, nil)
+		mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+			{Host: "https://email.example.com"},
+		}, nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.example.com", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, req client.NotificationRequest) error {
+				assert.Equal(t, "คำสั่งซื้อ 42 ถูกจัดส่งแล้ว", req.Title)
+				assert.Equal(t, "Your order 42 is on its way.", req.Message)
+				return nil
+			})
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			TranslationCache:   mockTranslationCache,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+			To:         "buyer@example.com",
+			Locale:     "th-TH",
+			TitleKey:   "order_shipped.title",
+			MessageKey: "order_shipped.message",
+			Params:     map[string]string{"order_id": "42"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("renders an admin template before delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCache := mockrepository.NewMockCacheProvider(ctrl)
+		mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+		mockTemplates := mockrepository.NewMockTemplateProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockTemplates.EXPECT().FindActiveTemplateVersion(gomock.Any(), "order_shipped").Return(repository.TemplateVersion{
+			TemplateName: "order_shipped",
+			Version:      3,
+			Title:        "Order {{.order_id}} has shipped",
+			Message:      "Your order {{.order_id}} is on its way.",
+		}, nil)
+		mockCache.EXPECT().Get(repository.EmailProvider).Return([]repository.NotificationPreference{
+			{Host: "https://email.example.com"},
+		}, nil)
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://email.example.com", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, req client.NotificationRequest) error {
+				assert.Equal(t, "Order 42 has shipped", req.Title)
+				assert.Equal(t, "Your order 42 is on its way.", req.Message)
+				return nil
+			})
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			Templates:          mockTemplates,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockCache,
+				PersistentProvider: mockrepository.NewMockPersistentProvider(ctrl),
+				HTTPclient:         mockHTTPClient,
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		_, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+			To:       "buyer@example.com",
+			Template: "order_shipped",
+			Params:   map[string]string{"order_id": "42"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects unknown keys without calling providers", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPersistent := mockrepository.NewMockPersistentProvider(ctrl)
+		mockTranslationCache := mockrepository.NewMockTranslationCacheProvider(ctrl)
+		metricsCollector, _ := metrics.NewNotificationCollector(nil)
+
+		mockTranslationCache.EXPECT().Get("missing.title").Return(nil, errors.New("cache miss"))
+		mockPersistent.EXPECT().FindTranslationsByKey(gomock.Any(), "missing.title").Return([]repository.NotificationTranslation{}, gorm.ErrRecordNotFound)
+		mockTranslationCache.EXPECT().Set("missing.title", []repository.NotificationTranslation{}).Return(nil)
+
+		service := NewNotificationService(NotificationServiceParams{
+			PersistentProvider: mockPersistent,
+			MetricsCollector:   metricsCollector,
+			IDGenerator:        newTestIDGenerator(ctrl),
+			Dispatcher:         newTestDispatcher(t),
+			Suppressions:       newTestSuppressions(ctrl),
+			SuppressionCache:   newTestSuppressionCache(ctrl),
+			Consents:           newTestConsents(ctrl),
+			ConsentCache:       newTestConsentCache(ctrl),
+			Quotas:             newTestQuotas(ctrl),
+			RouteCache:         newTestRouteCache(ctrl),
+			TranslationCache:   mockTranslationCache,
+			Channels: newTestChannels(ProviderChannelParams{
+				CacheProvider:      mockrepository.NewMockCacheProvider(ctrl),
+				PersistentProvider: mockPersistent,
+				HTTPclient:         mockclient.NewMockHTTPClientProvider(ctrl),
+				MetricsCollector:   metricsCollector,
+				Secrets:            newTestSecrets(ctrl),
+				Health:             newTestHealth(ctrl),
+				NotificationLog:    newTestNotificationLog(ctrl),
+				Alerter:            newTestAlerter(ctrl),
+			}),
+		})
+
+		report, err := service.Send(context.Background(), recipientTypeBuyer, Notification{
+			To:       "buyer@example.com",
+			TitleKey: "missing.title",
+			Message:  "Test message",
+		})
+
+		var translationErr *TranslationError
+		require.ErrorAs(t, err, &translationErr)
+		assert.Equal(t, RetryDoNotRetry, report.RetryDisposition)
+		assert.Zero(t, report.Attempts)
+	})
+}
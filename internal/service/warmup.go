@@ -0,0 +1,53 @@
+package service
+
+import (
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/clock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+)
+
+type WarmupConfig struct {
+	// Window is how long a provider added or enabled again takes to reach
+	// its full share of the sends it is tried first for; 0 gives it the
+	// full share at once
+	Window time.Duration `envconfig:"PROVIDER_WARMUP_WINDOW" default:"0s"`
+}
+
+// warmUp moves the providers still warming up behind the others for the
+// share of the sends they have not ramped up to yet, which grows linearly
+// over the window since they were enabled, so they meanwhile stay
+// fallbacks. draw returns a number in [0.0, 1.0), drawn per warming provider
+func (c WarmupConfig) warmUp(preferences []repository.NotificationPreference, clock clock.Clock, draw func() float64) []repository.NotificationPreference {
+	if c.Window <= 0 {
+		return preferences
+	}
+
+	ordered := make([]repository.NotificationPreference, 0, len(preferences))
+	var warming []repository.NotificationPreference
+	for _, preference := range preferences {
+		if progress := c.progress(preference, clock); progress < 1 && draw() >= progress {
+			warming = append(warming, preference)
+			continue
+		}
+		ordered = append(ordered, preference)
+	}
+	if len(warming) == 0 {
+		return preferences
+	}
+	return append(ordered, warming...)
+}
+
+// progress is the share of the window elapsed since the preference was
+// enabled, 1 once warmed up; preferences enabled before the column existed
+// are warm
+func (c WarmupConfig) progress(preference repository.NotificationPreference, clock clock.Clock) float64 {
+	if preference.EnabledAt == nil {
+		return 1
+	}
+	elapsed := clock.Since(*preference.EnabledAt)
+	if elapsed >= c.Window {
+		return 1
+	}
+	return max(float64(elapsed)/float64(c.Window), 0)
+}
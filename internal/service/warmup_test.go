@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koungkub/fw-challenge-notification-service/internal/client"
+	mockclient "github.com/koungkub/fw-challenge-notification-service/internal/client/mock"
+	mockclock "github.com/koungkub/fw-challenge-notification-service/internal/clock/mock"
+	"github.com/koungkub/fw-challenge-notification-service/internal/metrics"
+	"github.com/koungkub/fw-challenge-notification-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWarmupConfig_warmUp(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	enabledAt := func(ago time.Duration) *time.Time {
+		at := now.Add(-ago)
+		return &at
+	}
+
+	tests := []struct {
+		name          string
+		config        WarmupConfig
+		enabledAt     *time.Time
+		draw          float64
+		expectedHosts []string
+	}{
+		{
+			name:          "tries a provider warming up first within its ramped share",
+			config:        WarmupConfig{Window: time.Hour},
+			enabledAt:     enabledAt(45 * time.Minute),
+			draw:          0.7,
+			expectedHosts: []string{"new", "old"},
+		},
+		{
+			name:          "moves a provider warming up behind the others beyond its ramped share",
+			config:        WarmupConfig{Window: time.Hour},
+			enabledAt:     enabledAt(15 * time.Minute),
+			draw:          0.7,
+			expectedHosts: []string{"old", "new"},
+		},
+		{
+			name:          "moves a provider just enabled behind the others",
+			config:        WarmupConfig{Window: time.Hour},
+			enabledAt:     enabledAt(0),
+			expectedHosts: []string{"old", "new"},
+		},
+		{
+			name:          "keeps a warmed up provider in priority order",
+			config:        WarmupConfig{Window: time.Hour},
+			enabledAt:     enabledAt(2 * time.Hour),
+			draw:          0.99,
+			expectedHosts: []string{"new", "old"},
+		},
+		{
+			name:          "keeps a provider predating the warm-up in priority order",
+			config:        WarmupConfig{Window: time.Hour},
+			draw:          0.99,
+			expectedHosts: []string{"new", "old"},
+		},
+		{
+			name:          "keeps priority order without a window",
+			enabledAt:     enabledAt(0),
+			draw:          0.99,
+			expectedHosts: []string{"new", "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			preferences := []repository.NotificationPreference{
+				{Host: "new", EnabledAt: tt.enabledAt},
+				{Host: "old", Priority: 1},
+			}
+
+			mockClock := mockclock.NewMockClock(ctrl)
+			mockClock.EXPECT().Since(gomock.Any()).DoAndReturn(now.Sub).AnyTimes()
+
+			var hosts []string
+			for _, preference := range tt.config.warmUp(preferences, mockClock, func() float64 { return tt.draw }) {
+				hosts = append(hosts, preference.Host)
+			}
+
+			assert.Equal(t, tt.expectedHosts, hosts)
+		})
+	}
+}
+
+func TestProviderChannel_sendNotification_Warmup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTPClient := mockclient.NewMockHTTPClientProvider(ctrl)
+	metricsCollector, _ := metrics.NewNotificationCollector(nil)
+	mockClock := mockclock.NewMockClock(ctrl)
+	mockClock.EXPECT().Since(gomock.Any()).Return(time.Duration(0))
+
+	// The provider just enabled only serves as a fallback
+	gomock.InOrder(
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://old.email.com", gomock.Any()).
+			Return(&client.ProviderError{StatusCode: 503}),
+		mockHTTPClient.EXPECT().Post(gomock.Any(), "https://new.email.com", gomock.Any()).
+			Return(nil),
+	)
+
+	channel := newProviderChannel(repository.EmailProvider, ProviderChannelParams{
+		HTTPclient:       mockHTTPClient,
+		MetricsCollector: metricsCollector,
+		Secrets:          newTestSecrets(ctrl),
+		Health:           newTestHealth(ctrl),
+		NotificationLog:  newTestNotificationLog(ctrl),
+		Warmup:           WarmupConfig{Window: time.Hour},
+		Alerter:          newTestAlerter(ctrl),
+		Clock:            mockClock,
+	})
+
+	err := channel.sendNotification(context.Background(), recipientTypeBuyer, []repository.NotificationPreference{
+		{ProviderName: "new", Host: "https://new.email.com", EnabledAt: new(time.Time)},
+		{ProviderName: "old", Host: "https://old.email.com", Priority: 1},
+	}, client.NotificationRequest{To: "user@example.com"})
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS notification_routes (
+    id BIGSERIAL PRIMARY KEY,
+    recipient_type TEXT NOT NULL,
+    provider_type notification_provider_type NOT NULL,
+    priority INT DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_notification_routes_recipient_provider_active
+ON notification_routes (recipient_type, provider_type)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DELETE FROM notification_routes
+WHERE id IN (1, 2, 3);
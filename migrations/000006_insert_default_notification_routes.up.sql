@@ -0,0 +1,6 @@
+INSERT INTO "public"."notification_routes" ("id", "recipient_type", "provider_type", "priority", "created_at", "deleted_at") VALUES
+(1, 'buyer', 'Email', 0, '2025-10-10 10:30:00+00', NULL),
+(2, 'seller', 'Email', 0, '2025-10-10 10:30:00+00', NULL),
+(3, 'seller', 'PushNotification', 1, '2025-10-10 10:30:00+00', NULL);
+
+SELECT setval('notification_routes_id_seq', (SELECT MAX(id) FROM notification_routes));
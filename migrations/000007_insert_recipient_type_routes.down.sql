@@ -0,0 +1,2 @@
+DELETE FROM notification_routes
+WHERE id IN (4, 5, 6, 7);
@@ -0,0 +1,7 @@
+INSERT INTO "public"."notification_routes" ("id", "recipient_type", "provider_type", "priority", "created_at", "deleted_at") VALUES
+(4, 'courier', 'PushNotification', 0, '2025-10-10 10:30:00+00', NULL),
+(5, 'courier', 'Email', 1, '2025-10-10 10:30:00+00', NULL),
+(6, 'admin', 'Email', 0, '2025-10-10 10:30:00+00', NULL),
+(7, 'support', 'Email', 0, '2025-10-10 10:30:00+00', NULL);
+
+SELECT setval('notification_routes_id_seq', (SELECT MAX(id) FROM notification_routes));
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS notification_translations (
+    id BIGSERIAL PRIMARY KEY,
+    key TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_notification_translations_key_locale_active
+ON notification_translations (key, locale)
+WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DELETE FROM notification_translations
+WHERE id IN (1, 2, 3, 4);
@@ -0,0 +1,7 @@
+INSERT INTO "public"."notification_translations" ("id", "key", "locale", "text", "created_at", "deleted_at") VALUES
+(1, 'order_shipped.title', 'en', 'Order {{.order_id}} has shipped', '2025-10-10 10:30:00+00', NULL),
+(2, 'order_shipped.title', 'th', 'คำสั่งซื้อ {{.order_id}} ถูกจัดส่งแล้ว', '2025-10-10 10:30:00+00', NULL),
+(3, 'order_shipped.message', 'en', 'Your order {{.order_id}} is on its way.', '2025-10-10 10:30:00+00', NULL),
+(4, 'order_shipped.message', 'th', 'คำสั่งซื้อ {{.order_id}} ของคุณกำลังจัดส่ง', '2025-10-10 10:30:00+00', NULL);
+
+SELECT setval('notification_translations_id_seq', (SELECT MAX(id) FROM notification_translations));
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_notification_prefs_provider_deleted_active;
+CREATE INDEX idx_notification_prefs_provider_deleted_active
+ON notification_preferences (provider_type, priority)
+WHERE deleted_at IS NULL;
+
+ALTER TABLE notification_preferences
+DROP COLUMN IF EXISTS enabled;
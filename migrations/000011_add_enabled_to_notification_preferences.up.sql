@@ -0,0 +1,7 @@
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+DROP INDEX IF EXISTS idx_notification_prefs_provider_deleted_active;
+CREATE INDEX idx_notification_prefs_provider_deleted_active
+ON notification_preferences (provider_type, priority)
+WHERE deleted_at IS NULL AND enabled;
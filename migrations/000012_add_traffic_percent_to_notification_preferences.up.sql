@@ -0,0 +1,3 @@
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS traffic_percent INT NOT NULL DEFAULT 0
+CHECK (traffic_percent BETWEEN 0 AND 100);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_job_items;
+DROP TABLE IF EXISTS notification_jobs;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS notification_jobs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    state TEXT NOT NULL,
+    total INT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS notification_job_items (
+    job_id TEXT NOT NULL REFERENCES notification_jobs (id) ON DELETE CASCADE,
+    item INT NOT NULL,
+    status TEXT NOT NULL,
+    notification_id TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (job_id, item)
+);
+
+CREATE INDEX idx_notification_job_items_status
+ON notification_job_items (job_id, status, item);
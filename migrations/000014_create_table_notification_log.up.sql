@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notification_log (
+    notification_id TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    provider_name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    status_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (notification_id, channel)
+);
+
+CREATE INDEX idx_notification_log_provider_name
+ON notification_log (provider_name, notification_id);
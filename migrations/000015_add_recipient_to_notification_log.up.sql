@@ -0,0 +1,2 @@
+ALTER TABLE notification_log
+ADD COLUMN recipient TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS notification_suppressions (
+    address TEXT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    provider_name TEXT NOT NULL DEFAULT '',
+    notification_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_suppressions_created_at
+ON notification_suppressions (created_at DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_messages (
+    message_id TEXT PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    state TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    channels TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,13 @@
+-- Postgres cannot drop an enum value, so the type is recreated without it
+DELETE FROM notification_routes WHERE provider_type = 'InApp';
+DELETE FROM notification_preferences WHERE provider_type = 'InApp';
+
+ALTER TYPE notification_provider_type RENAME TO notification_provider_type_old;
+CREATE TYPE notification_provider_type AS ENUM ('Email', 'PushNotification');
+
+ALTER TABLE notification_preferences
+ALTER COLUMN provider_type TYPE notification_provider_type USING provider_type::TEXT::notification_provider_type;
+ALTER TABLE notification_routes
+ALTER COLUMN provider_type TYPE notification_provider_type USING provider_type::TEXT::notification_provider_type;
+
+DROP TYPE notification_provider_type_old;
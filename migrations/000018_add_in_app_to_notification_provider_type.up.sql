@@ -0,0 +1 @@
+ALTER TYPE notification_provider_type ADD VALUE IF NOT EXISTS 'InApp';
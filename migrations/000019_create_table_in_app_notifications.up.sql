@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS in_app_notifications (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    thread_key TEXT NOT NULL DEFAULT '',
+    deep_link TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_in_app_notifications_recipient_created_at
+ON in_app_notifications (recipient, created_at DESC);
+
+CREATE INDEX idx_in_app_notifications_recipient_unread
+ON in_app_notifications (recipient)
+WHERE read_at IS NULL;
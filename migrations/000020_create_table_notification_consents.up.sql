@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_consents (
+    address TEXT NOT NULL,
+    category TEXT NOT NULL,
+    channel TEXT NOT NULL DEFAULT '',
+    opted_in BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (address, category, channel)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    deep_link TEXT NOT NULL DEFAULT '',
+    claimed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_digest_items_recipient
+ON notification_digest_items (recipient_type, recipient, category);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS notification_quota_usage (
+    subject TEXT NOT NULL,
+    period TEXT NOT NULL,
+    period_start DATE NOT NULL,
+    used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (subject, period, period_start)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_notification_log_created_at;
+
+ALTER TABLE notification_log
+DROP COLUMN IF EXISTS cost,
+DROP COLUMN IF EXISTS tenant;
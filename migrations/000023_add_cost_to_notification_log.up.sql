@@ -0,0 +1,6 @@
+ALTER TABLE notification_log
+ADD COLUMN tenant TEXT NOT NULL DEFAULT '',
+ADD COLUMN cost NUMERIC(18, 6) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_notification_log_created_at
+ON notification_log (created_at);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS notification_circuit_breaker_trips (
+    host TEXT PRIMARY KEY,
+    opened_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS notification_circuit_breaker_counts;
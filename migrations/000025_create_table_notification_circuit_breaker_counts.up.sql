@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS notification_circuit_breaker_counts (
+    host TEXT PRIMARY KEY,
+    window_start TIMESTAMPTZ NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    failures BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE notification_log
+DROP COLUMN IF EXISTS metadata;
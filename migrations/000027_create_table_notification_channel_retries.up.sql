@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_channel_retries (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    tenant TEXT NOT NULL DEFAULT '',
+    notification JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_channel_retries_next_attempt_at
+ON notification_channel_retries (next_attempt_at);
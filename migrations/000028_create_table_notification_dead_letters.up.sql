@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS notification_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    notification_id TEXT NOT NULL,
+    recipient_type TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    tenant TEXT NOT NULL DEFAULT '',
+    notification JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_dead_letters_notification_id
+ON notification_dead_letters (notification_id);
@@ -0,0 +1,2 @@
+ALTER TABLE notification_preferences
+DROP COLUMN IF EXISTS next_secret_key;
@@ -0,0 +1,2 @@
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS auth_mode TEXT NOT NULL DEFAULT 'secret_key' CHECK (auth_mode IN ('secret_key', 'jwt'));
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notification_templates (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    active_version INTEGER NOT NULL,
+    latest_version INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS notification_template_versions (
+    template_name TEXT NOT NULL REFERENCES notification_templates (name),
+    version INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (template_name, version)
+);
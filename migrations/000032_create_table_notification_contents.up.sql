@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS notification_contents (
+    notification_id TEXT PRIMARY KEY,
+    recipient_type TEXT NOT NULL,
+    tenant TEXT NOT NULL DEFAULT '',
+    notification JSONB NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
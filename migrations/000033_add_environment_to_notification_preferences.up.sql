@@ -0,0 +1,2 @@
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS environment TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+-- Existing providers are left NULL, so they are not warmed up
+ALTER TABLE notification_preferences
+ADD COLUMN IF NOT EXISTS enabled_at TIMESTAMPTZ;
+
+ALTER TABLE notification_preferences
+ALTER COLUMN enabled_at SET DEFAULT NOW();
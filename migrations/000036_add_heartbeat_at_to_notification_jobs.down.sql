@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notification_jobs_unfinished;
+
+ALTER TABLE notification_jobs
+DROP COLUMN IF EXISTS heartbeat_at;
@@ -0,0 +1,8 @@
+-- Running jobs are touched by the instance delivering them; a job left
+-- unfinished without a recent heartbeat belongs to an instance that died
+ALTER TABLE notification_jobs
+ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_notification_jobs_unfinished
+ON notification_jobs (heartbeat_at)
+WHERE finished_at IS NULL;
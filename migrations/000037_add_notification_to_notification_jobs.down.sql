@@ -0,0 +1,3 @@
+ALTER TABLE notification_jobs
+DROP COLUMN IF EXISTS subject,
+DROP COLUMN IF EXISTS notification;
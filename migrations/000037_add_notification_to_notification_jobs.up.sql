@@ -0,0 +1,5 @@
+-- A notify job keeps its notification and quota subject, so another
+-- instance resends it when the one delivering it dies
+ALTER TABLE notification_jobs
+ADD COLUMN IF NOT EXISTS notification JSONB,
+ADD COLUMN IF NOT EXISTS subject VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE notification_dead_letters
+DROP COLUMN IF EXISTS retry_disposition;
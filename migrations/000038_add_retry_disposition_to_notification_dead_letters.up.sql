@@ -0,0 +1,3 @@
+-- A dead letter a provider may have delivered is only redriven on request
+ALTER TABLE notification_dead_letters
+ADD COLUMN IF NOT EXISTS retry_disposition VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_notification_contents_created_at;
@@ -0,0 +1,3 @@
+-- Kept contents are purged by age
+CREATE INDEX IF NOT EXISTS idx_notification_contents_created_at
+ON notification_contents (created_at);
@@ -0,0 +1,3 @@
+-- The actor is the admin key; the operator it names is kept beside it
+ALTER TABLE admin_audit_log
+ADD COLUMN IF NOT EXISTS on_behalf_of TEXT NOT NULL DEFAULT '';
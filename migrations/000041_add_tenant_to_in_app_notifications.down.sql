@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_in_app_notifications_tenant_recipient_unread;
+CREATE INDEX idx_in_app_notifications_recipient_unread
+ON in_app_notifications (recipient)
+WHERE read_at IS NULL;
+
+DROP INDEX IF EXISTS idx_in_app_notifications_tenant_recipient_created_at;
+CREATE INDEX idx_in_app_notifications_recipient_created_at
+ON in_app_notifications (recipient, created_at DESC);
+
+ALTER TABLE in_app_notifications
+DROP COLUMN IF EXISTS tenant;
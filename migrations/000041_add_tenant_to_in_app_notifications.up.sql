@@ -0,0 +1,12 @@
+-- Notifications are listed and marked read within the tenant that sent them
+ALTER TABLE in_app_notifications
+ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_in_app_notifications_recipient_created_at;
+CREATE INDEX idx_in_app_notifications_tenant_recipient_created_at
+ON in_app_notifications (tenant, recipient, created_at DESC);
+
+DROP INDEX IF EXISTS idx_in_app_notifications_recipient_unread;
+CREATE INDEX idx_in_app_notifications_tenant_recipient_unread
+ON in_app_notifications (tenant, recipient)
+WHERE read_at IS NULL;
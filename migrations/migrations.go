@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the highest migration version shipped with the binary
+func LatestVersion() (uint, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, file := range files {
+		prefix, _, found := strings.Cut(file, "_")
+		if !found {
+			return 0, fmt.Errorf("migration file: '%s' has no version prefix", file)
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("migration file: '%s' has invalid version: %w", file, err)
+		}
+
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}